@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/sirupsen/logrus"
+	"github.com/target/pod-reaper/pkg/reaper"
+)
+
+func main() {
+	var command func(args []string, out io.Writer) error
+	isCommand := false
+	if len(os.Args) > 1 {
+		command, isCommand = reaper.Command(os.Args[1])
+	}
+	if !isCommand {
+		// flags set their environment variables, so they are parsed before anything reads the environment
+		if err := reaper.ParseFlags(os.Args[1:], os.Stderr); err == flag.ErrHelp {
+			return
+		} else if err != nil {
+			os.Exit(2)
+		}
+	}
+	reaper.ConfigureLogging()
+
+	if isCommand {
+		if err := command(os.Args[2:], os.Stdout); err != nil {
+			logrus.WithError(err).Fatalf("unable to run %s", os.Args[1])
+		}
+		return
+	}
+
+	options, err := reaper.LoadOptions()
+	if err != nil {
+		logrus.WithError(err).Fatal("error loading options")
+	}
+	podReaper, err := reaper.New(options)
+	if err != nil {
+		logrus.WithError(err).Fatal("unable to create pod reaper")
+	}
+	// kubernetes sends SIGTERM when it deletes the pod
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+	if err := podReaper.Run(ctx); err != nil {
+		logrus.WithError(err).Fatal("reap cycle failed")
+	}
+	logrus.Info("pod reaper is exiting")
+}
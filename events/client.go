@@ -0,0 +1,125 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// the server-sent event type of reap records
+const reapEvent = "reap"
+
+const defaultRetryDelay = 5 * time.Second
+
+// Client subscribes to the reap events pod-reaper serves at /events.
+type Client struct {
+	// URL is the base url of pod-reaper's admin address, for example https://pod-reaper:8081
+	URL string
+	// Token is pod-reaper's ADMIN_TOKEN
+	Token string
+	// HTTPClient makes the requests, http.DefaultClient when nil. Its Timeout must be zero, since a subscription is a
+	// single long lived request.
+	HTTPClient *http.Client
+	// RetryDelay is how long to wait before reconnecting after the stream ends or fails, 5 seconds when zero
+	RetryDelay time.Duration
+}
+
+// Subscribe calls handle with each reap record pod-reaper sends, in order, until the context is cancelled, handle
+// returns an error, or pod-reaper rejects the subscription. When the stream ends or fails, Subscribe reconnects and
+// resumes after the last record it received, which pod-reaper replays as long as it still keeps it. Subscribe always
+// returns a non-nil error.
+func (client *Client) Subscribe(ctx context.Context, handle func(ReapRecord) error) error {
+	delay := client.RetryDelay
+	if delay <= 0 {
+		delay = defaultRetryDelay
+	}
+	lastID := ""
+	for {
+		retry, err := client.stream(ctx, &lastID, handle)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !retry {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// stream reads a single subscription, updating lastID as records are handled, and returns whether to reconnect.
+func (client *Client) stream(ctx context.Context, lastID *string, handle func(ReapRecord) error) (bool, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(client.URL, "/")+"/events", nil)
+	if err != nil {
+		return false, err
+	}
+	request.Header.Set("Accept", "text/event-stream")
+	if client.Token != "" {
+		request.Header.Set("Authorization", "Bearer "+client.Token)
+	}
+	if *lastID != "" {
+		request.Header.Set("Last-Event-ID", *lastID)
+	}
+	httpClient := client.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return true, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		err := fmt.Errorf("subscribing to reap events: %s: %s", response.Status, strings.TrimSpace(string(body)))
+		// server errors are usually transient, while client errors such as a wrong token are not
+		return response.StatusCode >= http.StatusInternalServerError, err
+	}
+
+	reader := bufio.NewReader(response.Body)
+	var id, event string
+	var data []string
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return true, err
+		}
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+		if line == "" {
+			// a blank line dispatches the event
+			if event == reapEvent && len(data) > 0 {
+				var record ReapRecord
+				if err := json.Unmarshal([]byte(strings.Join(data, "\n")), &record); err != nil {
+					return false, fmt.Errorf("decoding reap event %s: %s", id, err)
+				}
+				if err := handle(record); err != nil {
+					return false, err
+				}
+				*lastID = id
+			}
+			event, data = "", nil
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "id":
+			id = value
+		case "event":
+			event = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+}
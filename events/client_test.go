@@ -0,0 +1,88 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testEventServer serves the events in order, ending the stream after every batch, and records the Last-Event-ID
+// of each request.
+func testEventServer(t *testing.T, batches ...string) (*httptest.Server, func() []string) {
+	var mutex sync.Mutex
+	var lastIDs []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/events" || r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		mutex.Lock()
+		request := len(lastIDs)
+		lastIDs = append(lastIDs, r.Header.Get("Last-Event-ID"))
+		mutex.Unlock()
+		if request >= len(batches) {
+			<-r.Context().Done()
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, batches[request])
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []string {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return append([]string{}, lastIDs...)
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	t.Run("records", func(t *testing.T) {
+		server, lastIDs := testEventServer(t,
+			": keep-alive\n\n"+
+				"id: 1\nevent: reap\ndata: {\"pod\":\"web-1\",\"namespace\":\"default\",\"rules\":[\"duration\"],\"action\":\"delete\"}\n\n"+
+				"id: 2\nevent: other\ndata: {}\n\n",
+			"id: 3\r\nevent: reap\r\ndata: {\"pod\":\"web-2\",\"namespace\":\"default\",\"action\":\"delete\"}\r\n\r\n",
+		)
+		client := Client{URL: server.URL + "/", Token: "token", RetryDelay: time.Millisecond}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		var pods []string
+		err := client.Subscribe(ctx, func(record ReapRecord) error {
+			pods = append(pods, record.Pod)
+			if len(pods) == 2 {
+				cancel()
+			}
+			return nil
+		})
+		assert.Equal(t, context.Canceled, err)
+		assert.Equal(t, []string{"web-1", "web-2"}, pods)
+		// the second connection resumes after the last record handled
+		assert.Equal(t, []string{"", "1"}, lastIDs()[:2])
+	})
+	t.Run("handler error", func(t *testing.T) {
+		server, _ := testEventServer(t, "id: 1\nevent: reap\ndata: {\"pod\":\"web-1\"}\n\n")
+		client := Client{URL: server.URL, Token: "token"}
+		stop := errors.New("stop")
+		err := client.Subscribe(context.Background(), func(record ReapRecord) error { return stop })
+		assert.Equal(t, stop, err)
+	})
+	t.Run("invalid record", func(t *testing.T) {
+		server, _ := testEventServer(t, "id: 1\nevent: reap\ndata: not json\n\n")
+		client := Client{URL: server.URL, Token: "token"}
+		err := client.Subscribe(context.Background(), func(record ReapRecord) error { return nil })
+		assert.Error(t, err)
+	})
+	t.Run("rejected", func(t *testing.T) {
+		server, _ := testEventServer(t)
+		client := Client{URL: server.URL, Token: "wrong"}
+		err := client.Subscribe(context.Background(), func(record ReapRecord) error { return nil })
+		assert.ErrorContains(t, err, "401")
+	})
+}
@@ -0,0 +1,93 @@
+// Package events is a client for the reap events of pod-reaper, for tools that follow what pod-reaper reaps without
+// parsing its logs. It decodes the records pod-reaper writes with REAP_RECORDS and posts to REAP_WEBHOOK_URL, and
+// subscribes to the server-sent events it serves at /events on ADMIN_ADDRESS when ADMIN_TOKEN is set.
+package events
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// Actions pod-reaper takes on the pods it reaps, configured with ACTION.
+const (
+	ActionDelete     = "delete"
+	ActionEvict      = "evict"
+	ActionAnnotate   = "annotate"
+	ActionScaleOwner = "scale-owner"
+	ActionPreview    = "preview"
+)
+
+// Rules that pod-reaper reaps pods for, the reason codes of ReapRecord.Rules. Reasons describe why each rule matched
+// in free text.
+const (
+	RuleChaos               = "chaos"
+	RuleContainerStatus     = "containerStatus"
+	RuleContainerExitCode   = "containerExitCode"
+	RuleResourcePressure    = "resourcePressure"
+	RuleLogPattern          = "logPattern"
+	RuleDuration            = "duration"
+	RuleSoftTTL             = "softTTL"
+	RuleUnready             = "unready"
+	RulePodConditions       = "podConditions"
+	RulePodStatus           = "podStatus"
+	RulePodStatusPhase      = "podStatusPhase"
+	RuleImageAge            = "imageAge"
+	RuleCompletedAge        = "completedAge"
+	RuleVulnerability       = "vulnerability"
+	RuleCertExpiry          = "certExpiry"
+	RuleServiceAccountToken = "serviceAccountToken"
+	RuleStaleEndpoint       = "staleEndpoint"
+	RuleNodeAffinity        = "nodeAffinity"
+	RuleNodeConditions      = "nodeConditions"
+	RuleResourceUsage       = "resourceUsage"
+	RulePrometheusQuery     = "prometheusQuery"
+	RuleExpectedRuntime     = "expectedRuntime"
+	RuleTerminating         = "terminating"
+	RuleExternal            = "external"
+	// RuleRequest is recorded for pods reaped through POST /admin/reap
+	RuleRequest = "request"
+	// RuleBatch is recorded for pods reaped with the batch command
+	RuleBatch = "batch"
+)
+
+// ReapRecord describes a single reaped pod, or a pod that would have been reaped in dry-run mode.
+type ReapRecord struct {
+	Pod       string   `json:"pod"`
+	Namespace string   `json:"namespace"`
+	Reasons   []string `json:"reasons"`
+	// Rules names the rules that matched the pod, empty for records of older versions of pod-reaper
+	Rules     []string  `json:"rules,omitempty"`
+	Action    string    `json:"action"`
+	DryRun    bool      `json:"dryRun"`
+	Timestamp time.Time `json:"timestamp"`
+	// CycleID identifies the reap cycle, as logged by pod-reaper
+	CycleID string `json:"cycleId,omitempty"`
+}
+
+// HasRule returns whether the rule matched the pod.
+func (record ReapRecord) HasRule(rule string) bool {
+	for _, matched := range record.Rules {
+		if matched == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// Decoder reads reap records written one JSON object per line, as with REAP_RECORDS.
+type Decoder struct {
+	decoder *json.Decoder
+}
+
+// NewDecoder returns a decoder reading records from reader.
+func NewDecoder(reader io.Reader) *Decoder {
+	return &Decoder{decoder: json.NewDecoder(reader)}
+}
+
+// Decode reads the next record, returning io.EOF once there are no more.
+func (decoder *Decoder) Decode() (ReapRecord, error) {
+	var record ReapRecord
+	err := decoder.decoder.Decode(&record)
+	return record, err
+}
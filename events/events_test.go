@@ -0,0 +1,41 @@
+package events
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecoder(t *testing.T) {
+	decoder := NewDecoder(strings.NewReader(`{"pod":"web-1","namespace":"default","reasons":["has been running for 25h3m0s"],"rules":["duration"],"action":"delete","dryRun":false,"timestamp":"2024-01-01T00:00:00Z","cycleId":"abc"}
+{"pod":"web-2","namespace":"default","reasons":["reason"],"action":"evict","dryRun":true,"timestamp":"2024-01-01T00:00:01Z"}
+`))
+	record, err := decoder.Decode()
+	assert.NoError(t, err)
+	assert.Equal(t, ReapRecord{
+		Pod:       "web-1",
+		Namespace: "default",
+		Reasons:   []string{"has been running for 25h3m0s"},
+		Rules:     []string{RuleDuration},
+		Action:    ActionDelete,
+		Timestamp: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		CycleID:   "abc",
+	}, record)
+	record, err = decoder.Decode()
+	assert.NoError(t, err)
+	assert.Equal(t, "web-2", record.Pod)
+	assert.True(t, record.DryRun)
+	assert.Empty(t, record.Rules)
+	_, err = decoder.Decode()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestHasRule(t *testing.T) {
+	record := ReapRecord{Rules: []string{RuleChaos, RuleDuration}}
+	assert.True(t, record.HasRule(RuleDuration))
+	assert.False(t, record.HasRule(RuleUnready))
+	assert.False(t, ReapRecord{}.HasRule(RuleChaos))
+}
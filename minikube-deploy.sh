@@ -7,10 +7,10 @@
 kubectl --context=minikube delete --filename deployment.yml --ignore-not-found
 
 # build the local binary
-go fmt ./reaper ./rules
-go test ./reaper ./rules
-golint ./reaper ./rules
-CGO_ENABLED=0 GOOS=linux GOARCH=amd64 go build -o pod-reaper -a -installsuffix cgo ./reaper
+go fmt ./...
+go test ./...
+golint ./...
+CGO_ENABLED=0 GOOS=linux GOARCH=amd64 go build -o pod-reaper -a -installsuffix cgo ./cmd/pod-reaper
 
 # build the docker container
 docker rmi pod-reaper
@@ -0,0 +1,208 @@
+package reaper
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/target/pod-reaper/rules"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var errNoPendingCycle = errors.New("no reap cycle is awaiting approval")
+var errNotLeading = errors.New("this replica is not leading")
+
+// admin holds state that is changed at runtime through the admin API. A nil admin has no snoozed namespaces and no
+// pending cycle.
+type admin struct {
+	mutex sync.Mutex
+	// snoozed maps namespaces to the time until which their pods are not reaped
+	snoozed map[string]time.Time
+	// pending is the id of the latest cycle that matched pods but was held for approval
+	pending  string
+	approved bool
+}
+
+func newAdmin() *admin {
+	return &admin{snoozed: map[string]time.Time{}}
+}
+
+func (admin *admin) snooze(namespace string, until time.Time) {
+	admin.mutex.Lock()
+	defer admin.mutex.Unlock()
+	admin.snoozed[namespace] = until
+}
+
+func (admin *admin) isSnoozed(namespace string, now time.Time) bool {
+	if admin == nil {
+		return false
+	}
+	admin.mutex.Lock()
+	defer admin.mutex.Unlock()
+	until, exists := admin.snoozed[namespace]
+	if exists && !now.Before(until) {
+		delete(admin.snoozed, namespace)
+		return false
+	}
+	return exists
+}
+
+// holdForApproval records a cycle whose pods will only be reaped once the cycle is approved.
+func (admin *admin) holdForApproval(cycleID string) {
+	if admin == nil {
+		return
+	}
+	admin.mutex.Lock()
+	defer admin.mutex.Unlock()
+	admin.pending = cycleID
+	admin.approved = false
+}
+
+// approve approves the pending cycle, which must be the latest cycle held for approval.
+func (admin *admin) approve(cycleID string) error {
+	admin.mutex.Lock()
+	defer admin.mutex.Unlock()
+	if admin.pending == "" {
+		return errNoPendingCycle
+	}
+	if cycleID != admin.pending {
+		return fmt.Errorf("cycle %s is not awaiting approval, the pending cycle is %s", cycleID, admin.pending)
+	}
+	admin.pending = ""
+	admin.approved = true
+	return nil
+}
+
+// takeApproval returns whether a cycle was approved since the last call, consuming the approval.
+func (admin *admin) takeApproval() bool {
+	if admin == nil {
+		return false
+	}
+	admin.mutex.Lock()
+	defer admin.mutex.Unlock()
+	approved := admin.approved
+	admin.approved = false
+	return approved
+}
+
+// podExplanation describes why pod-reaper would or would not reap a pod.
+type podExplanation struct {
+	Pod       string              `json:"pod"`
+	Namespace string              `json:"namespace"`
+	Reap      bool                `json:"reap"`
+	Skipped   string              `json:"skipped,omitempty"`
+	Rules     []rules.RuleVerdict `json:"rules"`
+}
+
+// explain evaluates a pod the same way a reap cycle would, without reaping it.
+func (reaper reaper) explain(namespace string, name string) (podExplanation, error) {
+	explanation := podExplanation{Pod: name, Namespace: namespace, Rules: []rules.RuleVerdict{}}
+	if !reaper.apiCall(operationGet) {
+		return explanation, errAPIBudgetExhausted
+	}
+	ctx, cancel := reaper.apiContext()
+	defer cancel()
+	pod, err := reaper.clientSet.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return explanation, err
+	}
+	if reaper.admin.isSnoozed(namespace, time.Now()) {
+		explanation.Skipped = "namespace is snoozed"
+	} else if len(filter(reaper, *pod)) == 0 {
+		explanation.Skipped = "pod is excluded by pod-reaper's filters"
+	} else if reaper.targetsNodes() && len(reaper.selectNodes([]v1.Pod{*pod})) == 0 {
+		explanation.Skipped = "pod is not scheduled on a selected node"
+	}
+	loadedRules, ok := reaper.newRuleResolver().rulesFor(namespace)
+	if !ok {
+		return explanation, fmt.Errorf("unable to load the rules for namespace %s", namespace)
+	}
+	explanation.Rules = loadedRules.WithContext(reaper.rulesContext()).Explain(*pod)
+	explanation.Reap = explanation.Skipped == ""
+	inScope := false
+	for _, verdict := range explanation.Rules {
+		if verdict.OutOfScope {
+			continue
+		}
+		inScope = true
+		explanation.Reap = explanation.Reap && verdict.Reap
+	}
+	// like a reap cycle, a pod outside the scope of every rule is not reaped
+	explanation.Reap = explanation.Reap && inScope
+	return explanation, nil
+}
+
+// approveCycle approves the pending cycle and immediately runs a cycle that reaps the matching pods.
+func (reaper reaper) approveCycle(cycleID string) error {
+	if !reaper.leader.isLeading() {
+		return errNotLeading
+	}
+	if err := reaper.admin.approve(cycleID); err != nil {
+		return err
+	}
+	logrus.WithField("cycleId", cycleID).Info("reap cycle approved")
+	go func() {
+		// unlike a scheduled cycle, the approved cycle waits for a running cycle rather than being skipped
+		reaper.cycles.lock()
+		defer reaper.cycles.unlock()
+		reaper.cycle(time.Now().Truncate(time.Second))
+	}()
+	return nil
+}
+
+func (reaper reaper) handleSnooze(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	namespace := r.FormValue("namespace")
+	duration, err := time.ParseDuration(r.FormValue("duration"))
+	if namespace == "" || err != nil || duration <= 0 {
+		http.Error(w, "namespace and a positive duration are required", http.StatusBadRequest)
+		return
+	}
+	until := time.Now().Add(duration)
+	reaper.admin.snooze(namespace, until)
+	logrus.WithFields(logrus.Fields{"namespace": namespace, "until": until}).Info("namespace snoozed")
+	writeJSON(w, map[string]interface{}{"namespace": namespace, "until": until.UTC()})
+}
+
+func (reaper reaper) handleApprove(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	cycleID := r.FormValue("cycle")
+	if err := reaper.approveCycle(cycleID); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	writeJSON(w, map[string]string{"cycleId": cycleID})
+}
+
+func (reaper reaper) handleExplain(w http.ResponseWriter, r *http.Request) {
+	namespace := r.FormValue("namespace")
+	name := r.FormValue("pod")
+	if namespace == "" || name == "" {
+		http.Error(w, "namespace and pod are required", http.StatusBadRequest)
+		return
+	}
+	explanation, err := reaper.explain(namespace, name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, explanation)
+}
+
+func writeJSON(w http.ResponseWriter, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		logrus.WithError(err).Warn("unable to write admin response")
+	}
+}
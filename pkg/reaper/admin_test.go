@@ -0,0 +1,180 @@
+package reaper
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAdminSnooze(t *testing.T) {
+	now := time.Now()
+	snoozing := newAdmin()
+	snoozing.snooze("default", now.Add(time.Hour))
+	assert.True(t, snoozing.isSnoozed("default", now))
+	assert.False(t, snoozing.isSnoozed("other", now))
+	assert.False(t, snoozing.isSnoozed("default", now.Add(time.Hour)))
+	assert.Empty(t, snoozing.snoozed)
+
+	var nilAdmin *admin
+	assert.False(t, nilAdmin.isSnoozed("default", now))
+}
+
+func TestAdminApproval(t *testing.T) {
+	t.Run("nothing pending", func(t *testing.T) {
+		admin := newAdmin()
+		assert.Equal(t, errNoPendingCycle, admin.approve("cycle"))
+		assert.False(t, admin.takeApproval())
+	})
+	t.Run("approve pending cycle", func(t *testing.T) {
+		admin := newAdmin()
+		admin.holdForApproval("cycle")
+		assert.NoError(t, admin.approve("cycle"))
+		assert.True(t, admin.takeApproval())
+		assert.False(t, admin.takeApproval())
+	})
+	t.Run("only the latest cycle can be approved", func(t *testing.T) {
+		admin := newAdmin()
+		admin.holdForApproval("first")
+		admin.holdForApproval("second")
+		assert.Error(t, admin.approve("first"))
+		assert.False(t, admin.takeApproval())
+	})
+}
+
+func TestScytheCycleApproval(t *testing.T) {
+	opts := minimalOptions("1.0")
+	opts.requireApproval = true
+	r := createTestReaper(opts, createTestPod("pod", "default", nil))
+	r.admin = newAdmin()
+
+	r.scytheCycle()
+	pods, _ := r.clientSet.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+	assert.Len(t, pods.Items, 1)
+	assert.NotEmpty(t, r.admin.pending)
+
+	assert.NoError(t, r.admin.approve(r.admin.pending))
+	r.scytheCycle()
+	pods, _ = r.clientSet.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+	assert.Empty(t, pods.Items)
+}
+
+func TestReaperFilterSnoozed(t *testing.T) {
+	r := createTestReaper(minimalOptions("1.0"))
+	r.admin = newAdmin()
+	r.admin.snooze("snoozed", time.Now().Add(time.Hour))
+	filtered := filter(r, createTestPod("a", "snoozed", nil), createTestPod("b", "default", nil))
+	if assert.Len(t, filtered, 1) {
+		assert.Equal(t, "b", filtered[0].Name)
+	}
+}
+
+func TestExplain(t *testing.T) {
+	t.Run("would be reaped", func(t *testing.T) {
+		r := createTestReaper(minimalOptions("1.0"), createTestPod("pod", "default", nil))
+		explanation, err := r.explain("default", "pod")
+		assert.NoError(t, err)
+		assert.True(t, explanation.Reap)
+		if assert.Len(t, explanation.Rules, 1) {
+			assert.Equal(t, "chaos", explanation.Rules[0].Rule)
+		}
+	})
+	t.Run("rules do not match", func(t *testing.T) {
+		r := createTestReaper(minimalOptions("0.0"), createTestPod("pod", "default", nil))
+		explanation, err := r.explain("default", "pod")
+		assert.NoError(t, err)
+		assert.False(t, explanation.Reap)
+	})
+	t.Run("snoozed", func(t *testing.T) {
+		r := createTestReaper(minimalOptions("1.0"), createTestPod("pod", "default", nil))
+		r.admin = newAdmin()
+		r.admin.snooze("default", time.Now().Add(time.Hour))
+		explanation, err := r.explain("default", "pod")
+		assert.NoError(t, err)
+		assert.False(t, explanation.Reap)
+		assert.Equal(t, "namespace is snoozed", explanation.Skipped)
+	})
+	t.Run("protected", func(t *testing.T) {
+		pod := createTestPod("pod", "default", nil)
+		pod.Annotations = map[string]string{annotationProtect: "true"}
+		r := createTestReaper(minimalOptions("1.0"), pod)
+		explanation, err := r.explain("default", "pod")
+		assert.NoError(t, err)
+		assert.False(t, explanation.Reap)
+		assert.NotEmpty(t, explanation.Skipped)
+	})
+	t.Run("missing pod", func(t *testing.T) {
+		r := createTestReaper(minimalOptions("1.0"))
+		_, err := r.explain("default", "pod")
+		assert.Error(t, err)
+	})
+}
+
+func TestAdminHandlers(t *testing.T) {
+	t.Run("snooze", func(t *testing.T) {
+		r := createTestReaper(minimalOptions("1.0"))
+		r.admin = newAdmin()
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodPost, "/admin/snooze", strings.NewReader(url.Values{
+			"namespace": {"default"},
+			"duration":  {"1h"},
+		}.Encode()))
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		r.handleSnooze(recorder, request)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.True(t, r.admin.isSnoozed("default", time.Now()))
+	})
+	t.Run("snooze requires post", func(t *testing.T) {
+		r := createTestReaper(minimalOptions("1.0"))
+		r.admin = newAdmin()
+		recorder := httptest.NewRecorder()
+		r.handleSnooze(recorder, httptest.NewRequest(http.MethodGet, "/admin/snooze?namespace=default&duration=1h", nil))
+		assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+	})
+	t.Run("snooze invalid duration", func(t *testing.T) {
+		r := createTestReaper(minimalOptions("1.0"))
+		r.admin = newAdmin()
+		recorder := httptest.NewRecorder()
+		r.handleSnooze(recorder, httptest.NewRequest(http.MethodPost, "/admin/snooze?namespace=default&duration=-1h", nil))
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+	t.Run("approve without pending cycle", func(t *testing.T) {
+		r := createTestReaper(minimalOptions("1.0"))
+		r.admin = newAdmin()
+		recorder := httptest.NewRecorder()
+		r.handleApprove(recorder, httptest.NewRequest(http.MethodPost, "/admin/approve?cycle=abc", nil))
+		assert.Equal(t, http.StatusConflict, recorder.Code)
+	})
+	t.Run("approve when not leading", func(t *testing.T) {
+		r := createTestReaper(minimalOptions("1.0"))
+		r.admin = newAdmin()
+		r.admin.holdForApproval("abc")
+		r.leader = &leader{}
+		recorder := httptest.NewRecorder()
+		r.handleApprove(recorder, httptest.NewRequest(http.MethodPost, "/admin/approve?cycle=abc", nil))
+		assert.Equal(t, http.StatusConflict, recorder.Code)
+		assert.Equal(t, "abc", r.admin.pending)
+	})
+	t.Run("explain", func(t *testing.T) {
+		r := createTestReaper(minimalOptions("1.0"), createTestPod("pod", "default", nil))
+		recorder := httptest.NewRecorder()
+		r.handleExplain(recorder, httptest.NewRequest(http.MethodGet, "/admin/explain?namespace=default&pod=pod", nil))
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		var explanation map[string]interface{}
+		assert.NoError(t, json.NewDecoder(recorder.Body).Decode(&explanation))
+		assert.Equal(t, true, explanation["reap"])
+	})
+	t.Run("explain requires pod", func(t *testing.T) {
+		r := createTestReaper(minimalOptions("1.0"))
+		recorder := httptest.NewRecorder()
+		r.handleExplain(recorder, httptest.NewRequest(http.MethodGet, "/admin/explain?namespace=default", nil))
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+}
@@ -0,0 +1,59 @@
+package reaper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// alertmanagerAlertName is the alertname of the alert raised for failed chaos experiments
+const alertmanagerAlertName = "PodReaperChaosExperimentFailed"
+
+// alertmanager raises alerts through the Alertmanager API, so that failures page through the routes configured
+// there.
+type alertmanager struct {
+	url        string
+	client     *http.Client
+	retries    int
+	retryDelay time.Duration
+}
+
+// alertmanagerAlert is an alert in the format of the Alertmanager v2 API.
+type alertmanagerAlert struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	StartsAt    time.Time         `json:"startsAt"`
+}
+
+func (alertmanager *alertmanager) name() string {
+	return "alertmanager"
+}
+
+// alert raises an alert for the failed experiment. Alertmanager resolves it after its resolve_timeout since the alert
+// is not sent again.
+func (alertmanager *alertmanager) alert(summary experimentSummary) error {
+	var description strings.Builder
+	fmt.Fprintf(&description, "owners disrupted during chaos window %q that did not return to full readiness:", summary.Window)
+	for _, owner := range summary.failedOwners() {
+		fmt.Fprintf(&description, "\n%s: %s", owner, owner.Message)
+	}
+	body, err := json.Marshal([]alertmanagerAlert{{
+		Labels: map[string]string{
+			"alertname": alertmanagerAlertName,
+			"severity":  "critical",
+			"window":    summary.Window,
+		},
+		Annotations: map[string]string{
+			"summary":     fmt.Sprintf("pod-reaper chaos experiment failed: %d of %d disrupted owners are not fully ready", len(summary.failedOwners()), len(summary.Owners)),
+			"description": description.String(),
+		},
+		StartsAt: summary.Verified,
+	}})
+	if err != nil {
+		return err
+	}
+	return postWithRetries(context.Background(), alertmanager.client, strings.TrimSuffix(alertmanager.url, "/")+"/api/v2/alerts", body, alertmanager.retries, alertmanager.retryDelay)
+}
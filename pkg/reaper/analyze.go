@@ -0,0 +1,273 @@
+package reaper
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// analyzeCommand is the first argument that runs the analysis of the reap history instead of reaping pods
+const analyzeCommand = "analyze"
+
+// unknownRules groups records written before audit records named their rules
+const unknownRules = "unknown"
+
+// ruleEffectiveness summarizes what happened after the pods reaped by one set of rules.
+type ruleEffectiveness struct {
+	Rules string `json:"rules"`
+	Reaps int    `json:"reaps"`
+	// Owners is the number of distinct controllers whose pods were reaped
+	Owners int `json:"owners"`
+	// ReReaped is the number of reaps followed by another reap of the same owner within the window
+	ReReaped int `json:"reReaped"`
+	// Replaced is the number of reaps whose replacement pod still exists, and Healthy how many of those are ready
+	Replaced int `json:"replaced"`
+	Healthy  int `json:"healthy"`
+	// MedianTimeToRecreateSeconds is the median time between a reap and the creation of the replacement pod
+	MedianTimeToRecreateSeconds float64 `json:"medianTimeToRecreateSeconds,omitempty"`
+}
+
+// readAuditRecords reads audit records in the format written by AUDIT_SINK, one JSON object per line.
+func readAuditRecords(reader io.Reader, source string) ([]auditRecord, error) {
+	var records []auditRecord
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(nil, 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var record auditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("%s:%d: %s", source, line, err)
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
+
+// analyze measures the effectiveness of each set of rules from the reap history and the pods that exist now. A
+// replacement is the first pod of the same owner created after the reap and before the owner's next reap, so a
+// replacement that was itself reaped no longer exists and the reap only counts towards ReReaped.
+func analyze(records []auditRecord, pods []v1.Pod, window time.Duration) []ruleEffectiveness {
+	var reaps []auditRecord
+	for _, record := range records {
+		if record.Decision == auditReaped && !record.DryRun {
+			reaps = append(reaps, record)
+		}
+	}
+	sort.SliceStable(reaps, func(i, j int) bool { return reaps[i].Time.Before(reaps[j].Time) })
+
+	podsByOwner := map[string][]v1.Pod{}
+	for _, pod := range pods {
+		if owner := metav1.GetControllerOf(&pod); owner != nil {
+			key := pod.Namespace + "/" + owner.Kind + "/" + owner.Name
+			podsByOwner[key] = append(podsByOwner[key], pod)
+		}
+	}
+
+	groups := map[string]*ruleEffectiveness{}
+	owners := map[string]map[string]bool{}
+	recreated := map[string][]time.Duration{}
+	for i, reap := range reaps {
+		rules := strings.Join(reap.Rules, ",")
+		if rules == "" {
+			rules = unknownRules
+		}
+		group, exists := groups[rules]
+		if !exists {
+			group = &ruleEffectiveness{Rules: rules}
+			groups[rules] = group
+			owners[rules] = map[string]bool{}
+		}
+		group.Reaps++
+		if reap.Owner == "" {
+			continue
+		}
+		owner := reap.Namespace + "/" + reap.Owner
+		owners[rules][owner] = true
+		var nextReap *time.Time
+		for _, later := range reaps[i+1:] {
+			if later.Namespace+"/"+later.Owner == owner {
+				nextReap = &later.Time
+				break
+			}
+		}
+		if nextReap != nil && nextReap.Sub(reap.Time) <= window {
+			group.ReReaped++
+		}
+		var replacement *v1.Pod
+		for j, pod := range podsByOwner[owner] {
+			created := pod.CreationTimestamp.Time
+			if !created.After(reap.Time) || (nextReap != nil && created.After(*nextReap)) {
+				continue
+			}
+			if replacement == nil || created.Before(replacement.CreationTimestamp.Time) {
+				replacement = &podsByOwner[owner][j]
+			}
+		}
+		if replacement == nil {
+			continue
+		}
+		group.Replaced++
+		recreated[rules] = append(recreated[rules], replacement.CreationTimestamp.Sub(reap.Time))
+		if podReady(*replacement) {
+			group.Healthy++
+		}
+	}
+
+	var effectiveness []ruleEffectiveness
+	for rules, group := range groups {
+		group.Owners = len(owners[rules])
+		group.MedianTimeToRecreateSeconds = median(recreated[rules]).Seconds()
+		effectiveness = append(effectiveness, *group)
+	}
+	sort.Slice(effectiveness, func(i, j int) bool {
+		if effectiveness[i].Reaps != effectiveness[j].Reaps {
+			return effectiveness[i].Reaps > effectiveness[j].Reaps
+		}
+		return effectiveness[i].Rules < effectiveness[j].Rules
+	})
+	return effectiveness
+}
+
+func podReady(pod v1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == v1.PodReady {
+			return condition.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func median(durations []time.Duration) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration{}, durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
+
+// listHistoryPods lists the pods of the namespaces that appear in the records, each list bounded by the timeout.
+func listHistoryPods(clientSet kubernetes.Interface, records []auditRecord, timeout time.Duration) ([]v1.Pod, error) {
+	namespaces := map[string]bool{}
+	var pods []v1.Pod
+	for _, record := range records {
+		if record.Decision != auditReaped || namespaces[record.Namespace] {
+			continue
+		}
+		namespaces[record.Namespace] = true
+		ctx, cancel := apiContext(context.Background(), timeout)
+		list, err := clientSet.CoreV1().Pods(record.Namespace).List(ctx, metav1.ListOptions{})
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+		pods = append(pods, list.Items...)
+	}
+	return pods, nil
+}
+
+// runAnalyze runs the analyze command: pod-reaper analyze [-window 1h] [-format text|json] [-cluster=true] [file...]
+// reads audit records from the files, or standard in when there are none, and writes the effectiveness of each set
+// of rules to out.
+func runAnalyze(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet(analyzeCommand, flag.ContinueOnError)
+	window := flags.Duration("window", time.Hour, "a reap followed by another reap of the same owner within the window counts as re-reaped")
+	format := flags.String("format", textFormat, "output format, text or json")
+	cluster := flags.Bool("cluster", true, "look up replacement pods in the cluster, which requires running in the cluster")
+	if err := flags.Parse(args); err == flag.ErrHelp {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if *format != textFormat && *format != jsonFormat {
+		return fmt.Errorf("invalid format %q: must be %s or %s", *format, textFormat, jsonFormat)
+	}
+
+	var records []auditRecord
+	if flags.NArg() == 0 {
+		read, err := readAuditRecords(os.Stdin, "stdin")
+		if err != nil {
+			return err
+		}
+		records = read
+	}
+	for _, path := range flags.Args() {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		read, err := readAuditRecords(file, path)
+		file.Close()
+		if err != nil {
+			return err
+		}
+		records = append(records, read...)
+	}
+
+	var pods []v1.Pod
+	if *cluster {
+		config, err := rest.InClusterConfig()
+		if err != nil {
+			return fmt.Errorf("unable to get in cluster kubernetes config, use -cluster=false to analyze without replacement pods: %s", err)
+		}
+		clientSet, err := kubernetes.NewForConfig(config)
+		if err != nil {
+			return err
+		}
+		timeout, err := apiTimeout()
+		if err != nil {
+			return err
+		}
+		if pods, err = listHistoryPods(clientSet, records, timeout); err != nil {
+			return fmt.Errorf("unable to list pods: %s", err)
+		}
+	}
+	effectiveness := analyze(records, pods, *window)
+	logrus.WithFields(logrus.Fields{"records": len(records), "pods": len(pods)}).Debug("analyzed reap history")
+
+	if *format == jsonFormat {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(effectiveness)
+	}
+	writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "RULES\tREAPS\tOWNERS\tRE-REAPED\tREPLACED\tHEALTHY\tMEDIAN TIME TO RECREATE")
+	for _, group := range effectiveness {
+		recreate := "-"
+		if group.Replaced > 0 {
+			recreate = (time.Duration(group.MedianTimeToRecreateSeconds * float64(time.Second))).Truncate(time.Second).String()
+		}
+		fmt.Fprintf(writer, "%s\t%d\t%d\t%s\t%s\t%s\t%s\n", group.Rules, group.Reaps, group.Owners,
+			percentOf(group.ReReaped, group.Reaps), percentOf(group.Replaced, group.Reaps), percentOf(group.Healthy, group.Replaced), recreate)
+	}
+	return writer.Flush()
+}
+
+func percentOf(count int, total int) string {
+	if total == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d (%d%%)", count, count*100/total)
+}
@@ -0,0 +1,121 @@
+package reaper
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var analyzeStart = time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)
+
+func testReapRecord(minute int, pod string, owner string, rules ...string) auditRecord {
+	return auditRecord{
+		Time:      analyzeStart.Add(time.Duration(minute) * time.Minute),
+		Pod:       pod,
+		Namespace: "default",
+		Owner:     owner,
+		Rules:     rules,
+		Decision:  auditReaped,
+	}
+}
+
+func testReplacementPod(name string, owner string, minute int, ready bool) v1.Pod {
+	pod := testOwnedPod(name, owner)
+	pod.CreationTimestamp = metav1.NewTime(analyzeStart.Add(time.Duration(minute) * time.Minute))
+	status := v1.ConditionFalse
+	if ready {
+		status = v1.ConditionTrue
+	}
+	pod.Status.Conditions = []v1.PodCondition{{Type: v1.PodReady, Status: status}}
+	return pod
+}
+
+func TestReadAuditRecords(t *testing.T) {
+	records, err := readAuditRecords(strings.NewReader(`{"pod":"a","namespace":"default","decision":"reaped","rules":["duration"]}
+
+{"pod":"b","namespace":"default","decision":"skipped"}
+`), "test")
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+	assert.Equal(t, []string{"duration"}, records[0].Rules)
+
+	_, err = readAuditRecords(strings.NewReader("{}\nnot json\n"), "test")
+	assert.EqualError(t, err, "test:2: invalid character 'o' in literal null (expecting 'u')")
+}
+
+func TestAnalyze(t *testing.T) {
+	records := []auditRecord{
+		testReapRecord(0, "web-1", "ReplicaSet/web", "duration"),
+		testReapRecord(10, "web-2", "ReplicaSet/web", "duration"),
+		testReapRecord(120, "api-1", "ReplicaSet/api", "duration"),
+		testReapRecord(5, "batch-1", "ReplicaSet/batch", "unready", "containerStatus"),
+		testReapRecord(6, "bare", "", "unready", "containerStatus"),
+		testReapRecord(7, "legacy", "ReplicaSet/legacy"),
+	}
+	dryRun := testReapRecord(8, "web-3", "ReplicaSet/web", "duration")
+	dryRun.DryRun = true
+	skipped := testReapRecord(9, "web-4", "ReplicaSet/web", "duration")
+	skipped.Decision = auditSkipped
+	records = append(records, dryRun, skipped)
+	pods := []v1.Pod{
+		// web-2 replaced web-1 but was reaped too, so only the replacement of web-2 still exists
+		testReplacementPod("web-3", "web", 11, true),
+		testReplacementPod("api-2", "api", 121, false),
+		testReplacementPod("api-0", "api", 60, true),
+		testReplacementPod("batch-2", "batch", 8, true),
+	}
+
+	effectiveness := analyze(records, pods, time.Hour)
+
+	assert.Equal(t, []ruleEffectiveness{
+		{Rules: "duration", Reaps: 3, Owners: 2, ReReaped: 1, Replaced: 2, Healthy: 1, MedianTimeToRecreateSeconds: 60},
+		{Rules: "unready,containerStatus", Reaps: 2, Owners: 1, Replaced: 1, Healthy: 1, MedianTimeToRecreateSeconds: 180},
+		{Rules: "unknown", Reaps: 1, Owners: 1},
+	}, effectiveness)
+}
+
+func TestMedian(t *testing.T) {
+	assert.Equal(t, time.Duration(0), median(nil))
+	assert.Equal(t, 2*time.Second, median([]time.Duration{3 * time.Second, time.Second, 2 * time.Second}))
+	assert.Equal(t, 2500*time.Millisecond, median([]time.Duration{4 * time.Second, time.Second, 2 * time.Second, 3 * time.Second}))
+}
+
+func TestRunAnalyze(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	data, err := encodeAuditRecords([]auditRecord{
+		testReapRecord(0, "web-1", "ReplicaSet/web", "duration"),
+		testReapRecord(10, "web-2", "ReplicaSet/web", "duration"),
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(path, data, 0600))
+
+	t.Run("text", func(t *testing.T) {
+		var out bytes.Buffer
+		assert.NoError(t, runAnalyze([]string{"-cluster=false", path}, &out))
+		assert.Equal(t, `RULES     REAPS  OWNERS  RE-REAPED  REPLACED  HEALTHY  MEDIAN TIME TO RECREATE
+duration  2      1       1 (50%)    0 (0%)    -        -
+`, out.String())
+	})
+	t.Run("json", func(t *testing.T) {
+		var out bytes.Buffer
+		assert.NoError(t, runAnalyze([]string{"-cluster=false", "-format", "json", "-window", "5m", path}, &out))
+		assert.JSONEq(t, `[{"rules":"duration","reaps":2,"owners":1,"reReaped":0,"replaced":0,"healthy":0}]`, out.String())
+	})
+	t.Run("invalid format", func(t *testing.T) {
+		assert.Error(t, runAnalyze([]string{"-cluster=false", "-format", "yaml", path}, &bytes.Buffer{}))
+	})
+	t.Run("missing file", func(t *testing.T) {
+		assert.Error(t, runAnalyze([]string{"-cluster=false", filepath.Join(t.TempDir(), "missing.jsonl")}, &bytes.Buffer{}))
+	})
+	t.Run("outside the cluster", func(t *testing.T) {
+		os.Unsetenv("KUBERNETES_SERVICE_HOST")
+		assert.Error(t, runAnalyze([]string{path}, &bytes.Buffer{}))
+	})
+}
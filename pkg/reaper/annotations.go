@@ -0,0 +1,117 @@
+package reaper
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const annotationVerdict = "pod-reaper/verdict"
+const annotationVerdictReasons = "pod-reaper/verdict-reasons"
+const annotationEvaluatedAt = "pod-reaper/evaluated-at"
+
+// annotationProtect shields a pod from being reaped when set to "true", regardless of any other configuration
+const annotationProtect = "pod-reaper/protect"
+
+// with ACTION=annotate, matching pods are labelled and annotated instead of being deleted
+const labelMarked = "pod-reaper/marked"
+const annotationMarkedAt = "pod-reaper/marked-at"
+const annotationMarkedReasons = "pod-reaper/marked-reasons"
+
+const verdictReap = "reap"
+const verdictKeep = "keep"
+
+// protected returns whether the pod has opted out of reaping with the protect annotation.
+func protected(pod v1.Pod) bool {
+	protect, err := strconv.ParseBool(pod.Annotations[annotationProtect])
+	return err == nil && protect
+}
+
+// annotateVerdict records the latest rule evaluation on the pod when VERDICT_ANNOTATIONS is enabled. To avoid a
+// write on every cycle, the pod is only patched when the verdict changed or the previous annotation is older than
+// VERDICT_ANNOTATION_INTERVAL.
+func (reaper reaper) annotateVerdict(pod v1.Pod, shouldReap bool, reasons []string) {
+	if !reaper.options.verdictAnnotations {
+		return
+	}
+	verdict := verdictKeep
+	if shouldReap {
+		verdict = verdictReap
+	}
+	now := time.Now()
+	if !verdictAnnotationDue(pod, verdict, now, reaper.options.verdictInterval) {
+		return
+	}
+	annotations := map[string]string{
+		annotationVerdict:        verdict,
+		annotationVerdictReasons: strings.Join(reasons, "; "),
+		annotationEvaluatedAt:    now.UTC().Format(time.RFC3339),
+	}
+	if err := reaper.patchAnnotations(pod, annotations); err != nil {
+		logrus.WithField("pod", pod.Name).WithError(err).Warn("unable to annotate pod with verdict")
+	}
+}
+
+func verdictAnnotationDue(pod v1.Pod, verdict string, now time.Time, interval time.Duration) bool {
+	if pod.Annotations[annotationVerdict] != verdict {
+		return true
+	}
+	evaluatedAt, err := time.Parse(time.RFC3339, pod.Annotations[annotationEvaluatedAt])
+	if err != nil {
+		return true
+	}
+	return !now.Before(evaluatedAt.Add(interval))
+}
+
+// marked returns whether the pod was already marked by ACTION=annotate.
+func marked(pod v1.Pod) bool {
+	return pod.Labels[labelMarked] == "true"
+}
+
+// markPod labels the pod as marked for reaping and annotates it with when and why, leaving it to downstream
+// automation or humans to act on the pod.
+func (reaper reaper) markPod(pod v1.Pod, reasons []string, now time.Time) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels": map[string]string{
+				labelMarked: "true",
+			},
+			"annotations": map[string]string{
+				annotationMarkedAt:      now.UTC().Format(time.RFC3339),
+				annotationMarkedReasons: strings.Join(reasons, "; "),
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	ctx, cancel := reaper.apiContext()
+	defer cancel()
+	_, err = reaper.clientSet.CoreV1().Pods(pod.Namespace).Patch(ctx, pod.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
+// patchAnnotations merges the annotations into the pod's existing annotations.
+func (reaper reaper) patchAnnotations(pod v1.Pod, annotations map[string]string) error {
+	if !reaper.apiCall(operationPatch) {
+		return errAPIBudgetExhausted
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": annotations,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	ctx, cancel := reaper.apiContext()
+	defer cancel()
+	_, err = reaper.clientSet.CoreV1().Pods(pod.Namespace).Patch(ctx, pod.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
@@ -0,0 +1,154 @@
+package reaper
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func getTestPod(t *testing.T, r reaper, namespace string, name string) *v1.Pod {
+	pod, err := r.clientSet.CoreV1().Pods(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	assert.NoError(t, err)
+	return pod
+}
+
+func TestProtected(t *testing.T) {
+	pod := func(annotations map[string]string) v1.Pod {
+		return v1.Pod{ObjectMeta: metav1.ObjectMeta{Annotations: annotations}}
+	}
+	assert.True(t, protected(pod(map[string]string{annotationProtect: "true"})))
+	assert.True(t, protected(pod(map[string]string{annotationProtect: "True"})))
+	assert.False(t, protected(pod(map[string]string{annotationProtect: "false"})))
+	assert.False(t, protected(pod(map[string]string{annotationProtect: "yes please"})))
+	assert.False(t, protected(pod(nil)))
+}
+
+func TestScytheCycleProtectedPods(t *testing.T) {
+	startTime := time.Now()
+	protectedPod := createTestPod("protected", "default", &startTime)
+	protectedPod.Annotations = map[string]string{annotationProtect: "true"}
+	unprotectedPod := createTestPod("unprotected", "default", &startTime)
+	r := createTestReaper(minimalOptions("1.0"), protectedPod, unprotectedPod)
+
+	r.scytheCycle()
+
+	pods, err := r.clientSet.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(pods.Items))
+	assert.Equal(t, "protected", pods.Items[0].Name)
+}
+
+func TestVerdictAnnotationDue(t *testing.T) {
+	now := time.Now()
+	annotated := func(verdict string, evaluatedAt time.Time) v1.Pod {
+		pod := v1.Pod{}
+		pod.Annotations = map[string]string{
+			annotationVerdict:     verdict,
+			annotationEvaluatedAt: evaluatedAt.UTC().Format(time.RFC3339),
+		}
+		return pod
+	}
+	t.Run("never annotated", func(t *testing.T) {
+		assert.True(t, verdictAnnotationDue(v1.Pod{}, verdictKeep, now, time.Hour))
+	})
+	t.Run("verdict changed", func(t *testing.T) {
+		assert.True(t, verdictAnnotationDue(annotated(verdictKeep, now), verdictReap, now, time.Hour))
+	})
+	t.Run("recently annotated", func(t *testing.T) {
+		assert.False(t, verdictAnnotationDue(annotated(verdictKeep, now.Add(-time.Minute)), verdictKeep, now, time.Hour))
+	})
+	t.Run("interval elapsed", func(t *testing.T) {
+		assert.True(t, verdictAnnotationDue(annotated(verdictKeep, now.Add(-2*time.Hour)), verdictKeep, now, time.Hour))
+	})
+	t.Run("invalid timestamp", func(t *testing.T) {
+		pod := annotated(verdictKeep, now)
+		pod.Annotations[annotationEvaluatedAt] = "invalid"
+		assert.True(t, verdictAnnotationDue(pod, verdictKeep, now, time.Hour))
+	})
+}
+
+func TestAnnotateVerdict(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		pod := createTestPod("test-pod", "default", nil)
+		r := createTestReaper(minimalOptions("0.0"), pod)
+
+		r.annotateVerdict(pod, false, nil)
+
+		assert.Empty(t, getTestPod(t, r, "default", "test-pod").Annotations)
+	})
+
+	t.Run("keep", func(t *testing.T) {
+		pod := createTestPod("test-pod", "default", nil)
+		pod.Annotations = map[string]string{"existing": "value"}
+		opts := minimalOptions("0.0")
+		opts.verdictAnnotations = true
+		opts.verdictInterval = time.Hour
+		r := createTestReaper(opts, pod)
+
+		r.annotateVerdict(pod, false, []string{})
+
+		annotations := getTestPod(t, r, "default", "test-pod").Annotations
+		assert.Equal(t, "value", annotations["existing"])
+		assert.Equal(t, verdictKeep, annotations[annotationVerdict])
+		assert.Equal(t, "", annotations[annotationVerdictReasons])
+		assert.NotEmpty(t, annotations[annotationEvaluatedAt])
+	})
+
+	t.Run("reap", func(t *testing.T) {
+		pod := createTestPod("test-pod", "default", nil)
+		opts := minimalOptions("0.0")
+		opts.verdictAnnotations = true
+		opts.verdictInterval = time.Hour
+		r := createTestReaper(opts, pod)
+
+		r.annotateVerdict(pod, true, []string{"reason one", "reason two"})
+
+		annotations := getTestPod(t, r, "default", "test-pod").Annotations
+		assert.Equal(t, verdictReap, annotations[annotationVerdict])
+		assert.Equal(t, "reason one; reason two", annotations[annotationVerdictReasons])
+	})
+
+	t.Run("rate limited", func(t *testing.T) {
+		pod := createTestPod("test-pod", "default", nil)
+		pod.Annotations = map[string]string{
+			annotationVerdict:     verdictKeep,
+			annotationEvaluatedAt: time.Now().UTC().Format(time.RFC3339),
+		}
+		opts := minimalOptions("0.0")
+		opts.verdictAnnotations = true
+		opts.verdictInterval = time.Hour
+		fakeClient := fake.NewSimpleClientset(&pod)
+		patches := 0
+		fakeClient.PrependReactor("patch", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			patches++
+			return false, nil, nil
+		})
+		r := reaper{clientSet: fakeClient, options: opts}
+
+		r.annotateVerdict(pod, false, nil)
+
+		assert.Equal(t, 0, patches)
+	})
+}
+
+func TestScytheCycleVerdictAnnotations(t *testing.T) {
+	startTime := time.Now()
+	opts := minimalOptions("1.0")
+	opts.dryRun = true
+	opts.verdictAnnotations = true
+	opts.verdictInterval = time.Hour
+	r := createTestReaper(opts, createTestPod("pod-1", "default", &startTime))
+
+	r.scytheCycle()
+
+	annotations := getTestPod(t, r, "default", "pod-1").Annotations
+	assert.Equal(t, verdictReap, annotations[annotationVerdict])
+	assert.Equal(t, "was flagged for chaos", annotations[annotationVerdictReasons])
+}
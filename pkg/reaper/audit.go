@@ -0,0 +1,258 @@
+package reaper
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// audit record decisions
+const auditReaped = "reaped"
+const auditSkipped = "skipped"
+const auditFailed = "failed"
+
+// auditConfigMapKey is the config map key holding audit records, one JSON object per line
+const auditConfigMapKey = "audit.jsonl"
+
+// auditRecord is the durable record of a single reap decision.
+type auditRecord struct {
+	Time       time.Time `json:"time"`
+	CycleID    string    `json:"cycleId,omitempty"`
+	Pod        string    `json:"pod"`
+	Namespace  string    `json:"namespace"`
+	Owner      string    `json:"owner,omitempty"`
+	Rules      []string  `json:"rules,omitempty"`
+	Reasons    []string  `json:"reasons"`
+	Action     string    `json:"action"`
+	Decision   string    `json:"decision"`
+	SkipReason string    `json:"skipReason,omitempty"`
+	DryRun     bool      `json:"dryRun"`
+}
+
+// auditSink durably stores audit records.
+type auditSink interface {
+	// name identifies the sink in log messages.
+	name() string
+	write(reaper reaper, records []auditRecord) error
+}
+
+// auditLog buffers the audit records of a reap cycle until they are written to the sink at the end of the cycle. A
+// nil auditLog discards records.
+type auditLog struct {
+	sink    auditSink
+	mutex   sync.Mutex
+	pending []auditRecord
+}
+
+// parseAuditSink creates the sink for an AUDIT_SINK value of the form file:///path, s3://bucket/prefix or
+// configmap://namespace/name.
+func parseAuditSink(value string, fileMaxSize int64, fileMaxBackups int, configMapMaxRecords int) (auditSink, error) {
+	sinkURL, err := url.Parse(value)
+	if err != nil {
+		return nil, err
+	}
+	switch sinkURL.Scheme {
+	case "file":
+		if sinkURL.Path == "" {
+			return nil, fmt.Errorf("file sink requires a path, for example file:///var/log/pod-reaper/audit.jsonl")
+		}
+		return &fileAuditSink{path: sinkURL.Path, maxSize: fileMaxSize, maxBackups: fileMaxBackups}, nil
+	case "s3":
+		return newS3AuditSink(sinkURL.Host, strings.Trim(sinkURL.Path, "/"))
+	case "configmap":
+		name := strings.Trim(sinkURL.Path, "/")
+		if sinkURL.Host == "" || name == "" || strings.Contains(name, "/") {
+			return nil, fmt.Errorf("config map sink must be of the form configmap://namespace/name")
+		}
+		return &configMapAuditSink{namespace: sinkURL.Host, configMap: name, maxRecords: configMapMaxRecords}, nil
+	}
+	return nil, fmt.Errorf("unknown sink %q, must be one of file, s3, or configmap", sinkURL.Scheme)
+}
+
+func (audit *auditLog) record(record auditRecord) {
+	if audit == nil {
+		return
+	}
+	audit.mutex.Lock()
+	defer audit.mutex.Unlock()
+	audit.pending = append(audit.pending, record)
+}
+
+// audit records a reap decision for the pod, where skipReason explains skipped and failed decisions.
+func (reaper reaper) audit(pod v1.Pod, reasons []string, decision string, skipReason string) {
+	record := auditRecord{
+		Time:       time.Now().UTC(),
+		CycleID:    reaper.cycleID,
+		Pod:        pod.Name,
+		Namespace:  pod.Namespace,
+		Rules:      reaper.matchedRules,
+		Reasons:    reasons,
+		Action:     reaper.options.action,
+		Decision:   decision,
+		SkipReason: skipReason,
+		DryRun:     reaper.options.dryRun,
+	}
+	if owner := metav1.GetControllerOf(&pod); owner != nil {
+		record.Owner = owner.Kind + "/" + owner.Name
+	}
+	reaper.options.audit.record(record)
+	reaper.options.warehouse.record(record)
+	reaper.result.add(record)
+}
+
+// flushAudit writes the records of the cycle to the sink. Records that cannot be written are kept and retried at the
+// end of the next cycle.
+func (reaper reaper) flushAudit() {
+	audit := reaper.options.audit
+	if audit == nil {
+		return
+	}
+	audit.mutex.Lock()
+	defer audit.mutex.Unlock()
+	if len(audit.pending) == 0 {
+		return
+	}
+	if err := audit.sink.write(reaper, audit.pending); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"sink":    audit.sink.name(),
+			"records": len(audit.pending),
+		}).WithError(err).Error("unable to write audit records")
+		reaper.result.addError(cycleError{Operation: "audit", Target: audit.sink.name(), Message: err.Error()})
+		return
+	}
+	audit.pending = nil
+}
+
+func encodeAuditRecords(records []auditRecord) ([]byte, error) {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return nil, err
+		}
+	}
+	return buffer.Bytes(), nil
+}
+
+// fileAuditSink appends records to a local file, rotating it to numbered backups (audit.jsonl.1 being the newest)
+// when it would grow beyond the maximum size.
+type fileAuditSink struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+}
+
+func (sink *fileAuditSink) name() string {
+	return "file"
+}
+
+func (sink *fileAuditSink) write(_ reaper, records []auditRecord) error {
+	data, err := encodeAuditRecords(records)
+	if err != nil {
+		return err
+	}
+	if info, err := os.Stat(sink.path); err == nil && info.Size() > 0 && info.Size()+int64(len(data)) > sink.maxSize {
+		if err := sink.rotate(); err != nil {
+			return err
+		}
+	}
+	file, err := os.OpenFile(sink.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	// the records are only durable once synced
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func (sink *fileAuditSink) rotate() error {
+	if sink.maxBackups <= 0 {
+		return os.Remove(sink.path)
+	}
+	for i := sink.maxBackups - 1; i >= 1; i-- {
+		backup := fmt.Sprintf("%s.%d", sink.path, i)
+		if _, err := os.Stat(backup); err == nil {
+			if err := os.Rename(backup, fmt.Sprintf("%s.%d", sink.path, i+1)); err != nil {
+				return err
+			}
+		}
+	}
+	return os.Rename(sink.path, sink.path+".1")
+}
+
+// configMapAuditSink appends records to a config map, keeping only the latest records so the config map stays within
+// the kubernetes size limit.
+type configMapAuditSink struct {
+	namespace  string
+	configMap  string
+	maxRecords int
+}
+
+func (sink *configMapAuditSink) name() string {
+	return "configmap"
+}
+
+func (sink *configMapAuditSink) write(reaper reaper, records []auditRecord) error {
+	if !reaper.apiCall(operationGet) {
+		return errAPIBudgetExhausted
+	}
+	ctx, cancel := reaper.apiContext()
+	defer cancel()
+	configMaps := reaper.clientSet.CoreV1().ConfigMaps(sink.namespace)
+	configMap, err := configMaps.Get(ctx, sink.configMap, metav1.GetOptions{})
+	exists := true
+	if errors.IsNotFound(err) {
+		exists = false
+		configMap = &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: sink.configMap, Namespace: sink.namespace}}
+	} else if err != nil {
+		return err
+	}
+	var lines []string
+	scanner := bufio.NewScanner(strings.NewReader(configMap.Data[auditConfigMapKey]))
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	data, err := encodeAuditRecords(records)
+	if err != nil {
+		return err
+	}
+	lines = append(lines, strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")...)
+	if len(lines) > sink.maxRecords {
+		lines = lines[len(lines)-sink.maxRecords:]
+	}
+	if configMap.Data == nil {
+		configMap.Data = map[string]string{}
+	}
+	configMap.Data[auditConfigMapKey] = strings.Join(lines, "\n") + "\n"
+	if exists {
+		if !reaper.apiCall(operationUpdate) {
+			return errAPIBudgetExhausted
+		}
+		_, err = configMaps.Update(ctx, configMap, metav1.UpdateOptions{})
+	} else {
+		if !reaper.apiCall(operationCreate) {
+			return errAPIBudgetExhausted
+		}
+		_, err = configMaps.Create(ctx, configMap, metav1.CreateOptions{})
+	}
+	return err
+}
@@ -0,0 +1,147 @@
+package reaper
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// environment variables read by the s3 audit sink, matching the names used by the aws tooling
+const envAWSRegion = "AWS_REGION"
+const envAWSAccessKeyID = "AWS_ACCESS_KEY_ID"
+const envAWSSecretAccessKey = "AWS_SECRET_ACCESS_KEY"
+const envAWSSessionToken = "AWS_SESSION_TOKEN"
+
+// envAuditS3Endpoint overrides the s3 endpoint for s3 compatible stores, addressing buckets by path
+const envAuditS3Endpoint = "AUDIT_S3_ENDPOINT"
+
+const amzDateFormat = "20060102T150405Z"
+
+// s3AuditSink writes the records of each cycle to a new object in an s3 bucket, since objects cannot be appended to.
+type s3AuditSink struct {
+	bucket       string
+	prefix       string
+	region       string
+	endpoint     string
+	accessKey    string
+	secretKey    string
+	sessionToken string
+	client       *http.Client
+}
+
+func newS3AuditSink(bucket string, prefix string) (*s3AuditSink, error) {
+	if bucket == "" {
+		return nil, errors.New("s3 sink must be of the form s3://bucket/prefix")
+	}
+	sink := &s3AuditSink{
+		bucket:       bucket,
+		prefix:       prefix,
+		region:       os.Getenv(envAWSRegion),
+		endpoint:     strings.TrimSuffix(os.Getenv(envAuditS3Endpoint), "/"),
+		accessKey:    os.Getenv(envAWSAccessKeyID),
+		secretKey:    os.Getenv(envAWSSecretAccessKey),
+		sessionToken: os.Getenv(envAWSSessionToken),
+		client:       &http.Client{Timeout: 30 * time.Second},
+	}
+	if sink.region == "" || sink.accessKey == "" || sink.secretKey == "" {
+		return nil, fmt.Errorf("s3 sink requires %s, %s, and %s", envAWSRegion, envAWSAccessKeyID, envAWSSecretAccessKey)
+	}
+	return sink, nil
+}
+
+func (sink *s3AuditSink) name() string {
+	return "s3"
+}
+
+func (sink *s3AuditSink) objectURL(key string) string {
+	if sink.endpoint != "" {
+		return sink.endpoint + "/" + sink.bucket + "/" + key
+	}
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", sink.bucket, sink.region, key)
+}
+
+func (sink *s3AuditSink) write(_ reaper, records []auditRecord) error {
+	body, err := encodeAuditRecords(records)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	key := path.Join(sink.prefix, fmt.Sprintf("%s-%s.jsonl", now.Format(amzDateFormat), records[len(records)-1].CycleID))
+	request, err := http.NewRequest(http.MethodPut, sink.objectURL(key), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/x-ndjson")
+	sink.sign(request, body, now)
+	response, err := sink.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("unexpected response status %s", response.Status)
+	}
+	return nil
+}
+
+// sign adds an AWS signature version 4 authorization header covering every header already set on the request.
+func (sink *s3AuditSink) sign(request *http.Request, body []byte, now time.Time) {
+	payloadHash := sha256.Sum256(body)
+	request.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(payloadHash[:]))
+	request.Header.Set("X-Amz-Date", now.Format(amzDateFormat))
+	if sink.sessionToken != "" {
+		request.Header.Set("X-Amz-Security-Token", sink.sessionToken)
+	}
+
+	headers := map[string]string{"host": request.URL.Host}
+	for name, values := range request.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	var names []string
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		request.URL.EscapedPath(),
+		request.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	date := now.Format("20060102")
+	scope := date + "/" + sink.region + "/s3/aws4_request"
+	canonicalHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", now.Format(amzDateFormat), scope, hex.EncodeToString(canonicalHash[:])}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+sink.secretKey), date)
+	key = hmacSHA256(key, sink.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	request.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		sink.accessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,178 @@
+package reaper
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type failingAuditSink struct{}
+
+func (sink failingAuditSink) name() string {
+	return "failing"
+}
+
+func (sink failingAuditSink) write(reaper, []auditRecord) error {
+	return errors.New("sink unavailable")
+}
+
+func TestParseAuditSink(t *testing.T) {
+	t.Run("file", func(t *testing.T) {
+		sink, err := parseAuditSink("file:///var/log/audit.jsonl", 100, 2, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, &fileAuditSink{path: "/var/log/audit.jsonl", maxSize: 100, maxBackups: 2}, sink)
+	})
+	t.Run("file without path", func(t *testing.T) {
+		_, err := parseAuditSink("file://", 100, 2, 10)
+		assert.Error(t, err)
+	})
+	t.Run("configmap", func(t *testing.T) {
+		sink, err := parseAuditSink("configmap://reaper/pod-reaper-audit", 100, 2, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, &configMapAuditSink{namespace: "reaper", configMap: "pod-reaper-audit", maxRecords: 10}, sink)
+	})
+	t.Run("configmap without name", func(t *testing.T) {
+		_, err := parseAuditSink("configmap://reaper", 100, 2, 10)
+		assert.Error(t, err)
+	})
+	t.Run("s3", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envAWSRegion, "us-east-1")
+		os.Setenv(envAWSAccessKeyID, "key")
+		os.Setenv(envAWSSecretAccessKey, "secret")
+		sink, err := parseAuditSink("s3://bucket/audit/pod-reaper", 100, 2, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, "bucket", sink.(*s3AuditSink).bucket)
+		assert.Equal(t, "audit/pod-reaper", sink.(*s3AuditSink).prefix)
+	})
+	t.Run("s3 without credentials", func(t *testing.T) {
+		os.Clearenv()
+		_, err := parseAuditSink("s3://bucket/audit", 100, 2, 10)
+		assert.Error(t, err)
+	})
+	t.Run("unknown", func(t *testing.T) {
+		_, err := parseAuditSink("kafka://broker/topic", 100, 2, 10)
+		assert.Error(t, err)
+	})
+}
+
+func TestFileAuditSink(t *testing.T) {
+	records := []auditRecord{{Pod: "pod-1", Decision: auditReaped}, {Pod: "pod-2", Decision: auditSkipped}}
+
+	t.Run("appends records", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "audit.jsonl")
+		sink := &fileAuditSink{path: path, maxSize: 1024 * 1024, maxBackups: 2}
+		assert.NoError(t, sink.write(reaper{}, records))
+		assert.NoError(t, sink.write(reaper{}, records[:1]))
+		content, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, strings.Count(string(content), "\n"))
+	})
+	t.Run("rotates", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "audit.jsonl")
+		sink := &fileAuditSink{path: path, maxSize: 100, maxBackups: 2}
+		for i := 0; i < 4; i++ {
+			assert.NoError(t, sink.write(reaper{}, records))
+		}
+		assert.FileExists(t, path)
+		assert.FileExists(t, path+".1")
+		assert.FileExists(t, path+".2")
+		assert.NoFileExists(t, path+".3")
+	})
+	t.Run("no backups", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "audit.jsonl")
+		sink := &fileAuditSink{path: path, maxSize: 100, maxBackups: 0}
+		assert.NoError(t, sink.write(reaper{}, records))
+		assert.NoError(t, sink.write(reaper{}, records))
+		assert.FileExists(t, path)
+		assert.NoFileExists(t, path+".1")
+	})
+}
+
+func TestConfigMapAuditSink(t *testing.T) {
+	r := createTestReaper(minimalOptions("1.0"))
+	sink := &configMapAuditSink{namespace: "reaper", configMap: "audit", maxRecords: 3}
+
+	assert.NoError(t, sink.write(r, []auditRecord{{Pod: "pod-1"}, {Pod: "pod-2"}}))
+	configMap, err := r.clientSet.CoreV1().ConfigMaps("reaper").Get(context.TODO(), "audit", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(configMap.Data[auditConfigMapKey], "\n"))
+
+	assert.NoError(t, sink.write(r, []auditRecord{{Pod: "pod-3"}, {Pod: "pod-4"}}))
+	configMap, err = r.clientSet.CoreV1().ConfigMaps("reaper").Get(context.TODO(), "audit", metav1.GetOptions{})
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(configMap.Data[auditConfigMapKey]), "\n")
+	if assert.Len(t, lines, 3) {
+		assert.Contains(t, lines[0], `"pod":"pod-2"`)
+		assert.Contains(t, lines[2], `"pod":"pod-4"`)
+	}
+}
+
+func TestAuditDecisions(t *testing.T) {
+	t.Run("reaped and skipped", func(t *testing.T) {
+		opts := minimalOptions("1.0")
+		opts.maxPods = 1
+		path := filepath.Join(t.TempDir(), "audit.jsonl")
+		opts.audit = &auditLog{sink: &fileAuditSink{path: path, maxSize: 1024 * 1024}}
+		r := createTestReaper(opts, createTestPod("pod-1", "default", nil), createTestPod("pod-2", "default", nil))
+
+		r.scytheCycle()
+
+		content, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+		if assert.Len(t, lines, 2) {
+			assert.Contains(t, lines[0], `"decision":"reaped"`)
+			assert.Contains(t, lines[1], `"decision":"skipped"`)
+			assert.Contains(t, lines[1], `"skipReason":"maxPods is exceeded"`)
+		}
+		assert.Empty(t, opts.audit.pending)
+	})
+	t.Run("dry run", func(t *testing.T) {
+		opts := minimalOptions("1.0")
+		opts.dryRun = true
+		opts.audit = &auditLog{sink: failingAuditSink{}}
+		r := createTestReaper(opts, createTestPod("pod-1", "default", nil))
+
+		r.reapPod(createTestPod("pod-1", "default", nil), []string{"reason"}, 0)
+
+		if assert.Len(t, opts.audit.pending, 1) {
+			assert.Equal(t, auditSkipped, opts.audit.pending[0].Decision)
+			assert.True(t, opts.audit.pending[0].DryRun)
+		}
+	})
+	t.Run("owner and rules", func(t *testing.T) {
+		opts := minimalOptions("1.0")
+		opts.dryRun = true
+		opts.audit = &auditLog{sink: failingAuditSink{}}
+		r := createTestReaper(opts)
+		r.matchedRules = []string{"duration"}
+
+		r.reapPod(testOwnedPod("web-1", "web"), []string{"reason"}, 0)
+
+		if assert.Len(t, opts.audit.pending, 1) {
+			assert.Equal(t, "ReplicaSet/web", opts.audit.pending[0].Owner)
+			assert.Equal(t, []string{"duration"}, opts.audit.pending[0].Rules)
+		}
+	})
+	t.Run("failed writes are retried", func(t *testing.T) {
+		opts := minimalOptions("1.0")
+		opts.audit = &auditLog{sink: failingAuditSink{}}
+		r := createTestReaper(opts, createTestPod("pod-1", "default", nil))
+
+		r.scytheCycle()
+		assert.Len(t, opts.audit.pending, 1)
+		r.flushAudit()
+		assert.Len(t, opts.audit.pending, 1)
+	})
+	t.Run("disabled", func(t *testing.T) {
+		r := createTestReaper(minimalOptions("1.0"), createTestPod("pod-1", "default", nil))
+		assert.NotPanics(t, func() { r.scytheCycle() })
+	})
+}
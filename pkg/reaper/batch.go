@@ -0,0 +1,210 @@
+package reaper
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// batchCommand is the first argument that reaps the pods listed on standard input or in files instead of running
+// reap cycles
+const batchCommand = "batch"
+
+// batch reaps are audited with this in place of the names of matching rules
+const batchRule = "batch"
+
+// batchTarget references a pod listed for a batch reap.
+type batchTarget struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+func (target batchTarget) String() string {
+	return target.Namespace + "/" + target.Name
+}
+
+// batchResult describes what was done with the pods listed for a batch reap, each named namespace/name.
+type batchResult struct {
+	CycleID string `json:"cycleId"`
+	// Reaped are the pods that were reaped
+	Reaped []string `json:"reaped"`
+	// Skipped are the pods that were not reaped because of dry-run, a limit, or a failure
+	Skipped []string `json:"skipped"`
+	// Excluded are the pods that pod-reaper's filters, such as the protect annotation, exclude from reaping, and the
+	// pods in namespaces that pod-reaper does not watch
+	Excluded []string `json:"excluded"`
+	// Missing are the pods that do not exist
+	Missing []string `json:"missing"`
+}
+
+// readBatchTargets reads the pods listed for a batch reap, one per line, either as namespace/name or as a JSON object
+// with namespace and name. Blank lines and lines starting with # are ignored.
+func readBatchTargets(reader io.Reader, source string) ([]batchTarget, error) {
+	var targets []batchTarget
+	scanner := bufio.NewScanner(reader)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		var target batchTarget
+		if strings.HasPrefix(text, "{") {
+			if err := json.Unmarshal([]byte(text), &target); err != nil {
+				return nil, fmt.Errorf("%s:%d: %s", source, line, err)
+			}
+		} else if parts := strings.Split(text, "/"); len(parts) == 2 {
+			target = batchTarget{Namespace: parts[0], Name: parts[1]}
+		}
+		if target.Namespace == "" || target.Name == "" {
+			return nil, fmt.Errorf("%s:%d: %q is not a pod reference of the form namespace/name", source, line, text)
+		}
+		targets = append(targets, target)
+	}
+	return targets, scanner.Err()
+}
+
+// reapBatch reaps the listed pods through the same filters, limits, and audit as a reap cycle. The rules are not
+// evaluated, being listed is the reason to reap. Pods listed more than once are only reaped once.
+func (reaper reaper) reapBatch(targets []batchTarget, reason string) batchResult {
+	result := batchResult{Reaped: []string{}, Skipped: []string{}, Excluded: []string{}, Missing: []string{}}
+	reaper.cycleID = newCycleID()
+	reaper.matchedRules = []string{batchRule}
+	reaper.jobs = newJobGuard(reaper.options.protectJobBackoff)
+	reaper.ready = newReadyGuard(reaper.options.minReadyReplicas)
+	result.CycleID = reaper.cycleID
+	reasons := []string{reason}
+	seen := map[batchTarget]bool{}
+	reapedPods := 0
+	namespacePods := map[string]int{}
+	for _, target := range targets {
+		if seen[target] {
+			continue
+		}
+		seen[target] = true
+		if !reaper.watchesNamespace(target.Namespace) {
+			result.Excluded = append(result.Excluded, target.String())
+			continue
+		}
+		pods, err := reaper.requestedPods(reapRequest{Namespace: target.Namespace, Pod: target.Name})
+		if apierrors.IsNotFound(err) {
+			result.Missing = append(result.Missing, target.String())
+			continue
+		} else if err != nil {
+			logrus.WithFields(logrus.Fields{"pod": target.Name, "namespace": target.Namespace}).WithError(err).Warn("unable to get pod listed for batch reaping")
+			result.Skipped = append(result.Skipped, target.String())
+			continue
+		}
+		selected := reaper.selectRequested(pods)
+		if len(selected) == 0 {
+			result.Excluded = append(result.Excluded, target.String())
+			continue
+		}
+		if reaper.namespaceLimited(selected[0], reasons, namespacePods[target.Namespace]) {
+			result.Skipped = append(result.Skipped, target.String())
+			continue
+		}
+		namespacePods[target.Namespace]++
+		if reaper.reapPod(selected[0], reasons, reapedPods) {
+			result.Reaped = append(result.Reaped, target.String())
+		} else {
+			result.Skipped = append(result.Skipped, target.String())
+		}
+		reapedPods++
+	}
+	reaper.flushNotifiers()
+	reaper.flushAudit()
+	return result
+}
+
+// runBatch runs the batch command: pod-reaper batch [-reason ...] [-format text|json] [file...] reaps the pods listed
+// in the files, or on standard input when no file or - is given, with the action, filters, limits, and dry-run mode
+// configured with the usual environment variables, and writes what was done to out.
+func runBatch(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet(batchCommand, flag.ContinueOnError)
+	reason := flags.String("reason", "listed for batch reaping", "reason recorded for every pod reaped")
+	format := flags.String("format", textFormat, "output format, text or json")
+	if err := flags.Parse(args); err == flag.ErrHelp {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if *format != textFormat && *format != jsonFormat {
+		return fmt.Errorf("invalid format %q: must be %s or %s", *format, textFormat, jsonFormat)
+	}
+	if strings.TrimSpace(*reason) == "" {
+		return fmt.Errorf("-reason must not be empty")
+	}
+
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths = []string{"-"}
+	}
+	var targets []batchTarget
+	for _, path := range paths {
+		var read []batchTarget
+		var err error
+		if path == "-" {
+			read, err = readBatchTargets(os.Stdin, "stdin")
+		} else {
+			file, openErr := os.Open(path)
+			if openErr != nil {
+				return openErr
+			}
+			read, err = readBatchTargets(file, path)
+			file.Close()
+		}
+		if err != nil {
+			return err
+		}
+		targets = append(targets, read...)
+	}
+
+	opts, err := loadOptionsWithoutRules()
+	if err != nil {
+		return err
+	}
+	if opts.reaperPolicies {
+		return fmt.Errorf("%s cannot be used with %s", batchCommand, envReaperPolicies)
+	}
+	// a batch is a single run over the listed pods, like RUN_ONCE: there is no cache to keep and no leader to elect
+	opts.useInformer = false
+	opts.leaderElection = false
+	reaper, err := newReaperWithOptions(opts)
+	if err != nil {
+		return err
+	}
+	result := reaper.reapBatch(targets, *reason)
+	logrus.WithFields(logrus.Fields{"pods": len(targets), "reaped": len(result.Reaped), "cycleId": result.CycleID}).Info("batch reaped")
+
+	if *format == jsonFormat {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	}
+	writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "POD\tRESULT")
+	for _, group := range []struct {
+		name string
+		pods []string
+	}{
+		{"reaped", result.Reaped},
+		{"skipped", result.Skipped},
+		{"excluded", result.Excluded},
+		{"missing", result.Missing},
+	} {
+		for _, pod := range group.pods {
+			fmt.Fprintf(writer, "%s\t%s\n", pod, group.name)
+		}
+	}
+	return writer.Flush()
+}
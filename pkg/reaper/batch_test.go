@@ -0,0 +1,101 @@
+package reaper
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestReadBatchTargets(t *testing.T) {
+	t.Run("references and json", func(t *testing.T) {
+		input := "# failed pods\ndefault/web-1\n\n  {\"namespace\": \"kube-system\", \"name\": \"dns-1\"}\n"
+		targets, err := readBatchTargets(strings.NewReader(input), "stdin")
+		assert.NoError(t, err)
+		assert.Equal(t, []batchTarget{{Namespace: "default", Name: "web-1"}, {Namespace: "kube-system", Name: "dns-1"}}, targets)
+	})
+	for name, input := range map[string]string{
+		"no namespace":  "web-1",
+		"extra part":    "default/web-1/extra",
+		"empty name":    "default/",
+		"invalid json":  "{\"namespace\": ",
+		"json no name":  "{\"namespace\": \"default\"}",
+		"later invalid": "default/web-1\nweb-2",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := readBatchTargets(strings.NewReader(input), "targets.txt")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestReapBatch(t *testing.T) {
+	protected := createTestPod("web-3", "default", nil)
+	protected.Annotations = map[string]string{annotationProtect: "true"}
+	targets := []batchTarget{
+		{Namespace: "default", Name: "web-1"},
+		{Namespace: "default", Name: "web-2"},
+		{Namespace: "default", Name: "web-1"},
+		{Namespace: "default", Name: "web-3"},
+		{Namespace: "default", Name: "gone"},
+		{Namespace: "other", Name: "api-1"},
+	}
+	newReaper := func(opts Options) reaper {
+		return createTestReaper(opts,
+			createTestPod("web-1", "default", nil),
+			createTestPod("web-2", "default", nil),
+			protected,
+			createTestPod("api-1", "other", nil))
+	}
+
+	t.Run("reaps listed pods", func(t *testing.T) {
+		r := newReaper(minimalOptions("0.0"))
+		result := r.reapBatch(targets, "cleanup")
+		assert.NotEmpty(t, result.CycleID)
+		assert.Equal(t, []string{"default/web-1", "default/web-2"}, result.Reaped)
+		assert.Empty(t, result.Skipped)
+		assert.Equal(t, []string{"default/web-3", "other/api-1"}, result.Excluded)
+		assert.Equal(t, []string{"default/gone"}, result.Missing)
+		pods, err := r.clientSet.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+		assert.NoError(t, err)
+		assert.Len(t, pods.Items, 1)
+	})
+	t.Run("max pods", func(t *testing.T) {
+		opts := minimalOptions("0.0")
+		opts.maxPods = 1
+		result := newReaper(opts).reapBatch(targets, "cleanup")
+		assert.Equal(t, []string{"default/web-1"}, result.Reaped)
+		assert.Equal(t, []string{"default/web-2"}, result.Skipped)
+	})
+	t.Run("dry run", func(t *testing.T) {
+		opts := minimalOptions("0.0")
+		opts.dryRun = true
+		r := newReaper(opts)
+		result := r.reapBatch(targets, "cleanup")
+		assert.Empty(t, result.Reaped)
+		assert.Equal(t, []string{"default/web-1", "default/web-2"}, result.Skipped)
+		pods, err := r.clientSet.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+		assert.NoError(t, err)
+		assert.Len(t, pods.Items, 3)
+	})
+	t.Run("audited", func(t *testing.T) {
+		opts := minimalOptions("0.0")
+		opts.audit = &auditLog{sink: failingAuditSink{}}
+		newReaper(opts).reapBatch(targets[:1], "cleanup")
+		if assert.Len(t, opts.audit.pending, 1) {
+			assert.Equal(t, []string{batchRule}, opts.audit.pending[0].Rules)
+			assert.Equal(t, auditReaped, opts.audit.pending[0].Decision)
+		}
+	})
+}
+
+func TestRunBatchArguments(t *testing.T) {
+	var out bytes.Buffer
+	assert.Error(t, runBatch([]string{"-format", "yaml"}, &out))
+	assert.Error(t, runBatch([]string{"-reason", " "}, &out))
+	assert.Error(t, runBatch([]string{"does-not-exist.txt"}, &out))
+	assert.Empty(t, out.String())
+}
@@ -0,0 +1,152 @@
+package reaper
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/target/pod-reaper/rules"
+)
+
+var errAPIBudgetExhausted = errors.New("api call budget exhausted for this cycle")
+
+const operationList = "list"
+const operationGet = "get"
+const operationDelete = "delete"
+const operationEvict = "evict"
+const operationCreateEvent = "create-event"
+const operationPatch = "patch"
+const operationCreate = "create"
+const operationUpdate = "update"
+
+// operationRule counts the requests of rules that look up objects other than the pods they evaluate
+const operationRule = "rule"
+
+// apiBudget limits the number of kubernetes API calls made during a single reap cycle. A nil budget is unlimited.
+type apiBudget struct {
+	limit int64
+	used  int64
+	// rejected is set once the first call of a cycle is refused
+	rejected int32
+	// carried is the number of calls already used by an interrupted cycle that the next cycle resumes
+	carried int64
+}
+
+func newAPIBudget(limit int) *apiBudget {
+	if limit <= 0 {
+		return nil
+	}
+	return &apiBudget{limit: int64(limit)}
+}
+
+// newCycle returns the budget of a new cycle, with the full limit available less any calls carried over from an
+// interrupted cycle. Every cycle gets its own budget, so that a cycle never spends the calls of another.
+func (budget *apiBudget) newCycle() *apiBudget {
+	if budget == nil {
+		return nil
+	}
+	return &apiBudget{limit: budget.limit, used: atomic.SwapInt64(&budget.carried, 0)}
+}
+
+// carryOver makes the next cycle start with calls already used, so that a cycle resumed after an interruption does
+// not get a second full budget.
+func (budget *apiBudget) carryOver(used int64) {
+	if budget == nil {
+		return
+	}
+	atomic.StoreInt64(&budget.carried, used)
+}
+
+// usedCalls returns the number of calls made during this cycle.
+func (budget *apiBudget) usedCalls() int64 {
+	if budget == nil {
+		return 0
+	}
+	used := atomic.LoadInt64(&budget.used)
+	if used > budget.limit {
+		return budget.limit
+	}
+	return used
+}
+
+// take reserves one call from the budget and returns whether the call may be made.
+func (budget *apiBudget) take() bool {
+	if budget == nil {
+		return true
+	}
+	if atomic.AddInt64(&budget.used, 1) <= budget.limit {
+		return true
+	}
+	if atomic.CompareAndSwapInt32(&budget.rejected, 0, 1) {
+		apiBudgetExhaustedTotal.Inc()
+	}
+	return false
+}
+
+// exhausted returns whether no calls remain in the budget for this cycle.
+func (budget *apiBudget) exhausted() bool {
+	if budget == nil {
+		return false
+	}
+	return atomic.LoadInt64(&budget.used) >= budget.limit
+}
+
+// apiCall accounts for a kubernetes API call that is about to be made and returns whether the cycle's budget allows
+// it. Callers must skip the call when false is returned.
+func (reaper reaper) apiCall(operation string) bool {
+	if !reaper.budget.take() {
+		return false
+	}
+	apiCallsTotal.WithLabelValues(operation).Inc()
+	return true
+}
+
+// apiContext returns the context of a kubernetes API operation, which is cancelled when pod-reaper stops waiting for
+// the cycle or when API_TIMEOUT elapses. The cancel function must be called once the operation completes.
+func (reaper reaper) apiContext() (context.Context, context.CancelFunc) {
+	parent := reaper.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	return apiContext(parent, reaper.options.apiTimeout)
+}
+
+// apiContext returns a context that is cancelled with parent or after the timeout, where a zero timeout never expires.
+func apiContext(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout == 0 {
+		return context.WithCancel(parent)
+	}
+	return context.WithTimeout(parent, timeout)
+}
+
+// budgetKey is the context key of the budget of the cycle that evaluates rules
+type budgetKey struct{}
+
+// rulesContext returns the context of the rules evaluated by the cycle. It carries the reaper's cluster, so that rules
+// look up objects in the cluster whose pods are reaped, and the cycle's budget, so that their requests count against
+// it.
+func (reaper reaper) rulesContext() context.Context {
+	ctx := reaper.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(rules.NewContext(ctx, reaper.cluster), budgetKey{}, reaper.budget)
+}
+
+// budgetTransport counts the kubernetes API requests of rules against the budget of the cycle evaluating them, and
+// refuses the requests that the budget does not allow.
+type budgetTransport struct {
+	next http.RoundTripper
+}
+
+func (transport budgetTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	// a request without a budget, like one with a nil budget, is unlimited
+	budget, _ := request.Context().Value(budgetKey{}).(*apiBudget)
+	if !budget.take() {
+		return nil, errAPIBudgetExhausted
+	}
+	apiCallsTotal.WithLabelValues(operationRule).Inc()
+	return transport.next.RoundTrip(request)
+}
@@ -0,0 +1,205 @@
+package reaper
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAPIBudget(t *testing.T) {
+	t.Run("unlimited", func(t *testing.T) {
+		assert.Nil(t, newAPIBudget(0))
+		var budget *apiBudget
+		for i := 0; i < 100; i++ {
+			assert.True(t, budget.take())
+		}
+		assert.False(t, budget.exhausted())
+		assert.Nil(t, budget.newCycle())
+	})
+
+	t.Run("limited", func(t *testing.T) {
+		budget := newAPIBudget(2)
+		assert.False(t, budget.exhausted())
+		assert.True(t, budget.take())
+		assert.True(t, budget.take())
+		assert.True(t, budget.exhausted())
+		assert.False(t, budget.take())
+	})
+
+	t.Run("new cycle", func(t *testing.T) {
+		budget := newAPIBudget(1)
+		assert.True(t, budget.take())
+		assert.False(t, budget.take())
+		cycle := budget.newCycle()
+		assert.False(t, cycle.exhausted())
+		assert.True(t, cycle.take())
+		// the calls of one cycle are not spent from another
+		assert.True(t, budget.newCycle().take())
+		assert.True(t, budget.exhausted())
+	})
+
+	t.Run("carry over", func(t *testing.T) {
+		budget := newAPIBudget(3)
+		budget.carryOver(2)
+		cycle := budget.newCycle()
+		assert.Equal(t, int64(2), cycle.usedCalls())
+		assert.True(t, cycle.take())
+		assert.False(t, cycle.take())
+		assert.Equal(t, int64(3), cycle.usedCalls())
+		assert.Equal(t, int64(0), budget.newCycle().usedCalls())
+	})
+
+	t.Run("exhaustion counted once per cycle", func(t *testing.T) {
+		before := testutil.ToFloat64(apiBudgetExhaustedTotal)
+		budget := newAPIBudget(1)
+		budget.take()
+		budget.take()
+		budget.take()
+		assert.Equal(t, before+1, testutil.ToFloat64(apiBudgetExhaustedTotal))
+		budget = budget.newCycle()
+		budget.take()
+		budget.take()
+		assert.Equal(t, before+2, testutil.ToFloat64(apiBudgetExhaustedTotal))
+	})
+}
+
+func TestAPICall(t *testing.T) {
+	before := testutil.ToFloat64(apiCallsTotal.WithLabelValues(operationGet))
+	r := reaper{budget: newAPIBudget(1)}
+	assert.True(t, r.apiCall(operationGet))
+	assert.False(t, r.apiCall(operationGet))
+	assert.Equal(t, before+1, testutil.ToFloat64(apiCallsTotal.WithLabelValues(operationGet)))
+}
+
+func TestScytheCycleAPIBudget(t *testing.T) {
+	t.Run("ends cycle early", func(t *testing.T) {
+		startTime := time.Now()
+		opts := minimalOptions("1.0")
+		opts.apiCallBudget = 3 // one list and two deletes
+		r := createTestReaper(opts,
+			createTestPod("pod-1", "default", &startTime),
+			createTestPod("pod-2", "default", &startTime),
+			createTestPod("pod-3", "default", &startTime),
+			createTestPod("pod-4", "default", &startTime))
+		r.budget = newAPIBudget(opts.apiCallBudget)
+
+		r.scytheCycle()
+
+		result, _ := r.clientSet.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+		assert.Equal(t, 2, len(result.Items))
+	})
+
+	t.Run("budget resets each cycle", func(t *testing.T) {
+		startTime := time.Now()
+		opts := minimalOptions("1.0")
+		opts.apiCallBudget = 2 // one list and one delete
+		r := createTestReaper(opts,
+			createTestPod("pod-1", "default", &startTime),
+			createTestPod("pod-2", "default", &startTime))
+		r.budget = newAPIBudget(opts.apiCallBudget)
+
+		r.runCycle(time.Now())
+		r.runCycle(time.Now())
+
+		result, _ := r.clientSet.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+		assert.Equal(t, 0, len(result.Items))
+	})
+
+	t.Run("events count against budget", func(t *testing.T) {
+		startTime := time.Now()
+		opts := minimalOptions("1.0")
+		opts.emitEvents = true
+		opts.apiCallBudget = 3 // one list, one delete, and one event
+		r := createTestReaper(opts,
+			createTestPod("pod-1", "default", &startTime),
+			createTestPod("pod-2", "default", &startTime))
+		r.budget = newAPIBudget(opts.apiCallBudget)
+
+		r.scytheCycle()
+
+		result, _ := r.clientSet.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+		assert.Equal(t, 1, len(result.Items))
+		assert.Equal(t, 1, len(listEvents(t, r, "default")))
+	})
+}
+
+func TestAPIContext(t *testing.T) {
+	t.Run("timeout", func(t *testing.T) {
+		r := reaper{options: Options{apiTimeout: time.Minute}}
+		ctx, cancel := r.apiContext()
+		defer cancel()
+		deadline, ok := ctx.Deadline()
+		assert.True(t, ok)
+		assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, time.Second)
+	})
+	t.Run("no timeout", func(t *testing.T) {
+		ctx, cancel := reaper{}.apiContext()
+		defer cancel()
+		_, ok := ctx.Deadline()
+		assert.False(t, ok)
+		assert.NoError(t, ctx.Err())
+	})
+	t.Run("expires", func(t *testing.T) {
+		r := reaper{options: Options{apiTimeout: time.Millisecond}}
+		ctx, cancel := r.apiContext()
+		defer cancel()
+		<-ctx.Done()
+		assert.Equal(t, context.DeadlineExceeded, ctx.Err())
+	})
+	t.Run("stopped", func(t *testing.T) {
+		cycles, stopCycles := context.WithCancel(context.Background())
+		r := reaper{ctx: cycles, options: Options{apiTimeout: time.Minute}}
+		ctx, cancel := r.apiContext()
+		defer cancel()
+		stopCycles()
+		<-ctx.Done()
+		assert.Equal(t, context.Canceled, ctx.Err())
+	})
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
+
+func TestBudgetTransport(t *testing.T) {
+	sent := 0
+	transport := budgetTransport{next: roundTripFunc(func(*http.Request) (*http.Response, error) {
+		sent++
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	})}
+	request := func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://kubernetes.default.svc/api/v1/nodes/node", nil)
+		assert.NoError(t, err)
+		_, err = transport.RoundTrip(req)
+		return err
+	}
+
+	t.Run("counts against the budget", func(t *testing.T) {
+		sent = 0
+		before := testutil.ToFloat64(apiCallsTotal.WithLabelValues(operationRule))
+		r := reaper{budget: newAPIBudget(1)}
+		assert.NoError(t, request(r.rulesContext()))
+		assert.Equal(t, errAPIBudgetExhausted, request(r.rulesContext()))
+		assert.Equal(t, 1, sent)
+		assert.Equal(t, before+1, testutil.ToFloat64(apiCallsTotal.WithLabelValues(operationRule)))
+	})
+	t.Run("unlimited", func(t *testing.T) {
+		sent = 0
+		assert.NoError(t, request(reaper{}.rulesContext()))
+		assert.NoError(t, request(context.Background()))
+		assert.Equal(t, 2, sent)
+	})
+	t.Run("cycle context", func(t *testing.T) {
+		cycles, stopCycles := context.WithCancel(context.Background())
+		stopCycles()
+		r := reaper{ctx: cycles}
+		assert.Equal(t, context.Canceled, r.rulesContext().Err())
+	})
+}
@@ -0,0 +1,247 @@
+package reaper
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// chaos experiment results
+const experimentPassed = "passed"
+const experimentFailed = "failed"
+
+// experimentReporter is implemented by notifiers that can deliver the summary of a chaos experiment.
+type experimentReporter interface {
+	reportExperiment(summary experimentSummary) error
+}
+
+// experimentOwner is a workload disrupted by reaping one of its pods during a chaos window.
+type experimentOwner struct {
+	Namespace string `json:"namespace"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+}
+
+func (owner experimentOwner) String() string {
+	return owner.Namespace + "/" + owner.Kind + "/" + owner.Name
+}
+
+// experimentOwnerResult is whether a disrupted workload returned to full readiness.
+type experimentOwnerResult struct {
+	experimentOwner
+	Ready   bool   `json:"ready"`
+	Message string `json:"message"`
+}
+
+// experimentSummary is the result of a chaos experiment: the reaping during a single chaos window.
+type experimentSummary struct {
+	Window   string                  `json:"window"`
+	Started  time.Time               `json:"started"`
+	Ended    time.Time               `json:"ended"`
+	Verified time.Time               `json:"verified"`
+	Result   string                  `json:"result"`
+	Owners   []experimentOwnerResult `json:"owners"`
+}
+
+// failedOwners returns the owners that did not return to full readiness.
+func (summary experimentSummary) failedOwners() []experimentOwnerResult {
+	var failed []experimentOwnerResult
+	for _, owner := range summary.Owners {
+		if !owner.Ready {
+			failed = append(failed, owner)
+		}
+	}
+	return failed
+}
+
+// chaosExperiment tracks the workloads whose pods are reaped during a window of CHAOS_WINDOW, and once the window is
+// over verifies that they returned to full readiness within CHAOS_VERIFY_TIMEOUT. A nil chaosExperiment tracks
+// nothing.
+type chaosExperiment struct {
+	verifyTimeout time.Duration
+	mutex         sync.Mutex
+	// window is the window of the experiment in progress or being verified, nil between experiments
+	window  scheduleWindow
+	started time.Time
+	// ended is zero while the window is in progress
+	ended  time.Time
+	owners map[experimentOwner]bool
+}
+
+func newChaosExperiment(windows windowSchedule, verifyTimeout time.Duration) *chaosExperiment {
+	if len(windows) == 0 {
+		return nil
+	}
+	return &chaosExperiment{verifyTimeout: verifyTimeout}
+}
+
+// disrupted records the owner of a pod reaped during the window. Only the pods of workloads with a readiness to
+// return to are recorded.
+func (experiment *chaosExperiment) disrupted(pod v1.Pod) {
+	if experiment == nil {
+		return
+	}
+	owner := metav1.GetControllerOf(&pod)
+	if owner == nil || owner.Kind != "ReplicaSet" && owner.Kind != "StatefulSet" && owner.Kind != "DaemonSet" {
+		return
+	}
+	experiment.mutex.Lock()
+	defer experiment.mutex.Unlock()
+	if experiment.window == nil || !experiment.ended.IsZero() {
+		return
+	}
+	experiment.owners[experimentOwner{Namespace: pod.Namespace, Kind: owner.Kind, Name: owner.Name}] = true
+}
+
+// runExperiment starts an experiment when a cycle starts in a chaos window, and verifies the experiment once cycles
+// start outside of its window. An experiment still being verified when the next window starts is verified for the
+// last time.
+func (reaper reaper) runExperiment(window scheduleWindow, inWindow bool, now time.Time) {
+	experiment := reaper.experiment
+	if experiment == nil {
+		return
+	}
+	experiment.mutex.Lock()
+	if experiment.window != nil && experiment.ended.IsZero() && !inWindow {
+		experiment.ended = now
+	}
+	verifying := experiment.window != nil && !experiment.ended.IsZero()
+	experiment.mutex.Unlock()
+
+	if verifying {
+		reaper.verifyExperiment(now, inWindow)
+	}
+	if inWindow {
+		experiment.mutex.Lock()
+		if experiment.window == nil {
+			logrus.WithField("window", window.String()).Info("chaos window started")
+			experiment.window = window
+			experiment.started = now
+			experiment.ended = time.Time{}
+			experiment.owners = map[experimentOwner]bool{}
+		}
+		experiment.mutex.Unlock()
+	}
+}
+
+// verifyExperiment checks whether the owners disrupted during the window returned to full readiness. The experiment
+// passes once they all have, and fails if they have not by CHAOS_VERIFY_TIMEOUT after the window or when final.
+func (reaper reaper) verifyExperiment(now time.Time, final bool) {
+	experiment := reaper.experiment
+	experiment.mutex.Lock()
+	summary := experimentSummary{
+		Window:  experiment.window.String(),
+		Started: experiment.started.UTC(),
+		Ended:   experiment.ended.UTC(),
+		Owners:  []experimentOwnerResult{},
+	}
+	owners := make([]experimentOwner, 0, len(experiment.owners))
+	for owner := range experiment.owners {
+		owners = append(owners, owner)
+	}
+	timedOut := now.Sub(experiment.ended) >= experiment.verifyTimeout
+	experiment.mutex.Unlock()
+
+	// replica sets of the same deployment are verified once, as the deployment
+	seen := map[experimentOwner]bool{}
+	ready := true
+	for _, owner := range owners {
+		result := reaper.ownerReady(owner)
+		if seen[result.experimentOwner] {
+			continue
+		}
+		seen[result.experimentOwner] = true
+		ready = ready && result.Ready
+		summary.Owners = append(summary.Owners, result)
+	}
+	if !ready && !final && !timedOut {
+		logrus.WithField("window", summary.Window).Debug("waiting for the owners disrupted by the chaos window to return to full readiness")
+		return
+	}
+	sort.Slice(summary.Owners, func(i, j int) bool {
+		return summary.Owners[i].String() < summary.Owners[j].String()
+	})
+	summary.Verified = now.UTC()
+	summary.Result = experimentPassed
+	if !ready {
+		summary.Result = experimentFailed
+	}
+	experiment.mutex.Lock()
+	experiment.window = nil
+	experiment.owners = nil
+	experiment.mutex.Unlock()
+	reaper.reportExperiment(summary)
+}
+
+// ownerReady returns whether the disrupted owner is fully ready, resolving replica sets to their deployment. Owners
+// that were deleted since count as ready, since there is nothing left to recover.
+func (reaper reaper) ownerReady(owner experimentOwner) experimentOwnerResult {
+	result := experimentOwnerResult{experimentOwner: owner}
+	ctx, cancel := reaper.apiContext()
+	defer cancel()
+	workload, err := reaper.getWorkload(ctx, owner.Namespace, owner.Kind, owner.Name)
+	if apierrors.IsNotFound(err) {
+		result.Ready = true
+		result.Message = "deleted"
+		return result
+	} else if err != nil {
+		result.Message = err.Error()
+		return result
+	}
+	var readyReplicas, desired int32
+	switch object := workload.(type) {
+	case *appsv1.Deployment:
+		result.Kind, result.Name = "Deployment", object.Name
+		readyReplicas, desired = object.Status.ReadyReplicas, replicas(object.Spec.Replicas)
+	case *appsv1.StatefulSet:
+		readyReplicas, desired = object.Status.ReadyReplicas, replicas(object.Spec.Replicas)
+	case *appsv1.ReplicaSet:
+		readyReplicas, desired = object.Status.ReadyReplicas, replicas(object.Spec.Replicas)
+	case *appsv1.DaemonSet:
+		readyReplicas, desired = object.Status.NumberReady, object.Status.DesiredNumberScheduled
+	}
+	result.Ready = readyReplicas >= desired
+	result.Message = fmt.Sprintf("%d/%d ready", readyReplicas, desired)
+	return result
+}
+
+// reportExperiment logs the summary, counts it, delivers it to the notifiers that report experiments, and alerts
+// ALERTMANAGER_URL when the experiment failed.
+func (reaper reaper) reportExperiment(summary experimentSummary) {
+	summaryLog := logrus.WithFields(logrus.Fields{
+		"window":   summary.Window,
+		"started":  summary.Started.Format(time.RFC3339),
+		"ended":    summary.Ended.Format(time.RFC3339),
+		"owners":   len(summary.Owners),
+		"notReady": len(summary.failedOwners()),
+	})
+	if summary.Result == experimentPassed {
+		summaryLog.Info("chaos experiment passed, every disrupted owner returned to full readiness")
+	} else {
+		summaryLog.WithField("failedOwners", summary.failedOwners()).Error("chaos experiment failed, disrupted owners did not return to full readiness")
+	}
+	chaosExperimentsTotal.WithLabelValues(summary.Result).Inc()
+	for _, notifier := range reaper.options.notifiers {
+		reporter, ok := notifier.(experimentReporter)
+		if !ok {
+			continue
+		}
+		if err := reporter.reportExperiment(summary); err != nil {
+			logrus.WithField("notifier", notifier.name()).WithError(err).Warn("unable to send chaos experiment summary")
+			reaper.result.addError(cycleError{Operation: "notify", Target: notifier.name(), Message: err.Error()})
+		}
+	}
+	if summary.Result == experimentFailed && reaper.options.alertmanager != nil {
+		if err := reaper.options.alertmanager.alert(summary); err != nil {
+			logrus.WithError(err).Warn("unable to alert on failed chaos experiment")
+			reaper.result.addError(cycleError{Operation: "notify", Target: reaper.options.alertmanager.name(), Message: err.Error()})
+		}
+	}
+}
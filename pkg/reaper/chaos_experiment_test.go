@@ -0,0 +1,210 @@
+package reaper
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type testExperimentReporter struct {
+	summaries []experimentSummary
+}
+
+func (reporter *testExperimentReporter) name() string {
+	return "test"
+}
+
+func (reporter *testExperimentReporter) notify(notification reapNotification) error {
+	return nil
+}
+
+func (reporter *testExperimentReporter) reportExperiment(summary experimentSummary) error {
+	reporter.summaries = append(reporter.summaries, summary)
+	return nil
+}
+
+func TestChaosWindowCycle(t *testing.T) {
+	t.Run("outside of the window", func(t *testing.T) {
+		windows, err := parseWindowSchedule("0 0 1 1 * for 1s")
+		assert.NoError(t, err)
+		opts := minimalOptions("1.0")
+		opts.chaosWindow = windows
+		reaper := createTestReaper(opts, createTestPod("pod", "default", nil))
+		reaper.experiment = newChaosExperiment(windows, time.Minute)
+
+		assert.NoError(t, reaper.scytheCycle())
+
+		pods, _ := reaper.clientSet.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+		assert.Len(t, pods.Items, 1)
+		assert.Nil(t, reaper.experiment.window)
+	})
+	t.Run("in the window", func(t *testing.T) {
+		windows, err := parseWindowSchedule("00:00-23:59; 23:59-00:00")
+		assert.NoError(t, err)
+		opts := minimalOptions("1.0")
+		opts.chaosWindow = windows
+		reaper := createTestReaper(opts, testReadyPod("web-1", "ReplicaSet", "web-rs"))
+		reaper.experiment = newChaosExperiment(windows, time.Minute)
+
+		assert.NoError(t, reaper.scytheCycle())
+
+		pods, _ := reaper.clientSet.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+		assert.Empty(t, pods.Items)
+		assert.NotNil(t, reaper.experiment.window)
+		assert.Equal(t, map[experimentOwner]bool{{Namespace: "default", Kind: "ReplicaSet", Name: "web-rs"}: true}, reaper.experiment.owners)
+	})
+}
+
+func TestChaosExperiment(t *testing.T) {
+	windows, err := parseWindowSchedule("Mon-Fri 10:00-16:00 UTC")
+	assert.NoError(t, err)
+	window := windows[0]
+	start := time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)
+	end := start.Add(6 * time.Hour)
+	newReaper := func(ready int32) (reaper, *testExperimentReporter) {
+		reporter := &testExperimentReporter{}
+		opts := minimalOptions("1.0")
+		opts.notifiers = []notifier{reporter}
+		r := createTestReaper(opts)
+		deployment, replicaSet := testReadyDeployment("web", 3, ready, nil)
+		r.clientSet.AppsV1().Deployments("default").Create(context.TODO(), deployment, metav1.CreateOptions{})
+		r.clientSet.AppsV1().ReplicaSets("default").Create(context.TODO(), replicaSet, metav1.CreateOptions{})
+		r.experiment = newChaosExperiment(windows, 10*time.Minute)
+		r.runExperiment(window, true, start)
+		r.experiment.disrupted(testReadyPod("web-1", "ReplicaSet", "web-rs"))
+		r.experiment.disrupted(testReadyPod("web-2", "ReplicaSet", "web-rs"))
+		return r, reporter
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		experiment := newChaosExperiment(nil, time.Minute)
+		assert.Nil(t, experiment)
+		experiment.disrupted(testReadyPod("web-1", "ReplicaSet", "web-rs"))
+		reaper{experiment: experiment}.runExperiment(window, true, start)
+	})
+	t.Run("in progress", func(t *testing.T) {
+		reaper, reporter := newReaper(1)
+		reaper.runExperiment(window, true, start.Add(time.Hour))
+		assert.Empty(t, reporter.summaries)
+	})
+	t.Run("passes when owners are ready", func(t *testing.T) {
+		reaper, reporter := newReaper(3)
+		reaper.runExperiment(nil, false, end)
+		assert.Equal(t, []experimentSummary{{
+			Window:   window.String(),
+			Started:  start,
+			Ended:    end,
+			Verified: end,
+			Result:   experimentPassed,
+			Owners: []experimentOwnerResult{{
+				experimentOwner: experimentOwner{Namespace: "default", Kind: "Deployment", Name: "web"},
+				Ready:           true,
+				Message:         "3/3 ready",
+			}},
+		}}, reporter.summaries)
+		assert.Nil(t, reaper.experiment.window)
+
+		// pods reaped outside of the window are not recorded
+		reaper.experiment.disrupted(testReadyPod("web-3", "ReplicaSet", "web-rs"))
+		reaper.runExperiment(nil, false, end.Add(time.Minute))
+		assert.Len(t, reporter.summaries, 1)
+	})
+	t.Run("waits for owners to be ready", func(t *testing.T) {
+		reaper, reporter := newReaper(2)
+		reaper.runExperiment(nil, false, end)
+		assert.Empty(t, reporter.summaries)
+
+		ready, _ := reaper.clientSet.AppsV1().Deployments("default").Get(context.TODO(), "web", metav1.GetOptions{})
+		ready.Status.ReadyReplicas = 3
+		reaper.clientSet.AppsV1().Deployments("default").Update(context.TODO(), ready, metav1.UpdateOptions{})
+		reaper.runExperiment(nil, false, end.Add(time.Minute))
+		if assert.Len(t, reporter.summaries, 1) {
+			assert.Equal(t, experimentPassed, reporter.summaries[0].Result)
+			assert.Equal(t, end, reporter.summaries[0].Ended)
+		}
+	})
+	t.Run("fails after the verify timeout", func(t *testing.T) {
+		reaper, reporter := newReaper(2)
+		reaper.runExperiment(nil, false, end)
+		reaper.runExperiment(nil, false, end.Add(9*time.Minute))
+		assert.Empty(t, reporter.summaries)
+
+		reaper.runExperiment(nil, false, end.Add(10*time.Minute))
+		if assert.Len(t, reporter.summaries, 1) {
+			assert.Equal(t, experimentFailed, reporter.summaries[0].Result)
+			assert.Equal(t, []experimentOwnerResult{{
+				experimentOwner: experimentOwner{Namespace: "default", Kind: "Deployment", Name: "web"},
+				Message:         "2/3 ready",
+			}}, reporter.summaries[0].failedOwners())
+		}
+	})
+	t.Run("fails when the next window starts", func(t *testing.T) {
+		reaper, reporter := newReaper(2)
+		reaper.runExperiment(nil, false, end)
+		next := start.Add(24 * time.Hour)
+		reaper.runExperiment(window, true, next)
+		if assert.Len(t, reporter.summaries, 1) {
+			assert.Equal(t, experimentFailed, reporter.summaries[0].Result)
+		}
+		assert.Equal(t, next, reaper.experiment.started)
+		assert.Empty(t, reaper.experiment.owners)
+	})
+	t.Run("deleted owners are ready", func(t *testing.T) {
+		reaper, reporter := newReaper(2)
+		reaper.experiment.disrupted(testReadyPod("db-0", "StatefulSet", "db"))
+		reaper.clientSet.AppsV1().Deployments("default").Delete(context.TODO(), "web", metav1.DeleteOptions{})
+		reaper.runExperiment(nil, false, end)
+		if assert.Len(t, reporter.summaries, 1) {
+			assert.Equal(t, experimentPassed, reporter.summaries[0].Result)
+			assert.Len(t, reporter.summaries[0].Owners, 2)
+			assert.Equal(t, "deleted", reporter.summaries[0].Owners[0].Message)
+		}
+	})
+}
+
+func TestAlertmanager(t *testing.T) {
+	var alerts []alertmanagerAlert
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "/api/v2/alerts", request.URL.Path)
+		assert.NoError(t, json.NewDecoder(request.Body).Decode(&alerts))
+	}))
+	defer server.Close()
+	verified := time.Date(2024, 3, 4, 16, 10, 0, 0, time.UTC)
+	summary := experimentSummary{
+		Window:   "Mon-Fri 10:00-16:00 UTC",
+		Verified: verified,
+		Result:   experimentFailed,
+		Owners: []experimentOwnerResult{
+			{experimentOwner: experimentOwner{Namespace: "default", Kind: "Deployment", Name: "web"}, Message: "2/3 ready"},
+			{experimentOwner: experimentOwner{Namespace: "default", Kind: "StatefulSet", Name: "db"}, Ready: true, Message: "1/1 ready"},
+		},
+	}
+	opts := minimalOptions("1.0")
+	opts.alertmanager = &alertmanager{url: server.URL + "/", client: server.Client()}
+	reaper := createTestReaper(opts)
+
+	reaper.reportExperiment(summary)
+
+	if assert.Len(t, alerts, 1) {
+		assert.Equal(t, map[string]string{
+			"alertname": "PodReaperChaosExperimentFailed",
+			"severity":  "critical",
+			"window":    "Mon-Fri 10:00-16:00 UTC",
+		}, alerts[0].Labels)
+		assert.Equal(t, "pod-reaper chaos experiment failed: 1 of 2 disrupted owners are not fully ready", alerts[0].Annotations["summary"])
+		assert.Contains(t, alerts[0].Annotations["description"], "default/Deployment/web: 2/3 ready")
+		assert.True(t, verified.Equal(alerts[0].StartsAt))
+	}
+
+	// passed experiments do not alert
+	alerts = nil
+	summary.Result = experimentPassed
+	reaper.reportExperiment(summary)
+	assert.Empty(t, alerts)
+}
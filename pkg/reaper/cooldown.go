@@ -0,0 +1,62 @@
+package reaper
+
+import (
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// ownerCooldown skips the pods of an owner for a while after one of its pods was reaped, so that consecutive cycles
+// do not take out every replica of a workload. A nil ownerCooldown never skips a pod.
+type ownerCooldown struct {
+	duration time.Duration
+	mutex    sync.Mutex
+	// reaped holds the time the latest pod of each owner was reaped, for the owners still cooling down
+	reaped map[string]time.Time
+}
+
+func newOwnerCooldown(duration time.Duration) *ownerCooldown {
+	if duration <= 0 {
+		return nil
+	}
+	return &ownerCooldown{duration: duration, reaped: map[string]time.Time{}}
+}
+
+// take returns the time the owner of the pod cools down until and false if it is still cooling down. Otherwise it
+// starts the cooldown of the owner, so that other pods of the owner reaped at the same time are skipped, and returns
+// true. Pods without an owner never cool down.
+func (cooldown *ownerCooldown) take(pod v1.Pod, now time.Time) (time.Time, bool) {
+	if cooldown == nil {
+		return time.Time{}, true
+	}
+	owner := ownerKey(pod)
+	if owner == "" {
+		return time.Time{}, true
+	}
+	cooldown.mutex.Lock()
+	defer cooldown.mutex.Unlock()
+	for key, reaped := range cooldown.reaped {
+		if now.Sub(reaped) >= cooldown.duration {
+			delete(cooldown.reaped, key)
+		}
+	}
+	if reaped, exists := cooldown.reaped[owner]; exists {
+		return reaped.Add(cooldown.duration), false
+	}
+	cooldown.reaped[owner] = now
+	return time.Time{}, true
+}
+
+// release ends the cooldown that take started for the pod when the pod was not reaped after all.
+func (cooldown *ownerCooldown) release(pod v1.Pod, taken time.Time) {
+	if cooldown == nil {
+		return
+	}
+	owner := ownerKey(pod)
+	cooldown.mutex.Lock()
+	defer cooldown.mutex.Unlock()
+	if reaped, exists := cooldown.reaped[owner]; exists && reaped.Equal(taken) {
+		delete(cooldown.reaped, owner)
+	}
+}
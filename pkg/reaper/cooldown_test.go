@@ -0,0 +1,65 @@
+package reaper
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestOwnerCooldown(t *testing.T) {
+	now := time.Now()
+
+	t.Run("disabled", func(t *testing.T) {
+		cooldown := newOwnerCooldown(0)
+		assert.Nil(t, cooldown)
+		_, ok := cooldown.take(testOwnedPod("web-1", "web"), now)
+		assert.True(t, ok)
+		cooldown.release(testOwnedPod("web-1", "web"), now)
+	})
+	t.Run("owner cools down", func(t *testing.T) {
+		cooldown := newOwnerCooldown(time.Hour)
+		_, ok := cooldown.take(testOwnedPod("web-1", "web"), now)
+		assert.True(t, ok)
+		until, ok := cooldown.take(testOwnedPod("web-2", "web"), now.Add(time.Minute))
+		assert.False(t, ok)
+		assert.Equal(t, now.Add(time.Hour), until)
+		_, ok = cooldown.take(testOwnedPod("api-1", "api"), now.Add(time.Minute))
+		assert.True(t, ok)
+		_, ok = cooldown.take(testOwnedPod("web-2", "web"), now.Add(time.Hour))
+		assert.True(t, ok)
+	})
+	t.Run("pods without an owner", func(t *testing.T) {
+		cooldown := newOwnerCooldown(time.Hour)
+		_, ok := cooldown.take(createTestPod("bare", "default", nil), now)
+		assert.True(t, ok)
+		_, ok = cooldown.take(createTestPod("bare", "default", nil), now)
+		assert.True(t, ok)
+	})
+	t.Run("release", func(t *testing.T) {
+		cooldown := newOwnerCooldown(time.Hour)
+		_, ok := cooldown.take(testOwnedPod("web-1", "web"), now)
+		assert.True(t, ok)
+		cooldown.release(testOwnedPod("web-1", "web"), now.Add(time.Second))
+		_, ok = cooldown.take(testOwnedPod("web-2", "web"), now)
+		assert.False(t, ok, "only the cooldown the pod started is released")
+		cooldown.release(testOwnedPod("web-1", "web"), now)
+		_, ok = cooldown.take(testOwnedPod("web-2", "web"), now)
+		assert.True(t, ok)
+	})
+	t.Run("reap cycles", func(t *testing.T) {
+		opts := minimalOptions("1.0")
+		r := createTestReaper(opts, testOwnedPod("web-1", "web"), testOwnedPod("web-2", "web"), testOwnedPod("web-3", "web"),
+			testOwnedPod("api-1", "api"))
+		r.cooldown = newOwnerCooldown(time.Hour)
+
+		r.scytheCycle()
+		pods, _ := r.clientSet.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+		assert.Len(t, pods.Items, 2)
+		r.scytheCycle()
+		pods, _ = r.clientSet.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+		assert.Len(t, pods.Items, 2)
+	})
+}
@@ -0,0 +1,45 @@
+package reaper
+
+import (
+	"fmt"
+	"strings"
+)
+
+// cycleError is a structured failure of part of a reap cycle, such as listing pods in a namespace, reaping a pod, or
+// sending a notification. Failures do not end the cycle, they are collected so the cycle can report all of them.
+type cycleError struct {
+	// Operation is what failed: list, the action taken on a pod, notify, request approval, audit, or cycle
+	Operation string `json:"operation"`
+	Namespace string `json:"namespace,omitempty"`
+	Pod       string `json:"pod,omitempty"`
+	// Target names the notifier or audit sink that failed
+	Target  string `json:"target,omitempty"`
+	Message string `json:"message"`
+}
+
+func (err cycleError) Error() string {
+	subject := err.Operation
+	if err.Target != "" {
+		subject += " " + err.Target
+	}
+	if err.Pod != "" {
+		subject += " " + err.Namespace + "/" + err.Pod
+	} else if err.Namespace != "" {
+		subject += " in " + err.Namespace
+	}
+	return subject + ": " + err.Message
+}
+
+// cycleErrors aggregates every failure of a reap cycle into a single error.
+type cycleErrors []cycleError
+
+func (errs cycleErrors) Error() string {
+	if len(errs) == 1 {
+		return errs[0].Error()
+	}
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("%d errors during reap cycle: %s", len(errs), strings.Join(messages, "; "))
+}
@@ -0,0 +1,68 @@
+package reaper
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+type failingNotifier struct{}
+
+func (notifier failingNotifier) name() string {
+	return "failing"
+}
+
+func (notifier failingNotifier) notify(reapNotification) error {
+	return errors.New("webhook unavailable")
+}
+
+func TestCycleErrors(t *testing.T) {
+	assert.Equal(t, "delete default/pod-1: connection refused",
+		cycleErrors{{Operation: "delete", Namespace: "default", Pod: "pod-1", Message: "connection refused"}}.Error())
+	assert.Equal(t, "2 errors during reap cycle: list in team-a: forbidden; notify slack: timeout", cycleErrors{
+		{Operation: "list", Namespace: "team-a", Message: "forbidden"},
+		{Operation: "notify", Target: "slack", Message: "timeout"},
+	}.Error())
+}
+
+func TestScytheCycleAggregatesErrors(t *testing.T) {
+	opts := minimalOptions("1.0")
+	opts.namespaces = []string{"default", "team-a"}
+	opts.notifiers = []notifier{failingNotifier{}}
+	r := createTestReaper(opts, createTestPod("pod-1", "default", nil))
+	r.health = testHealth(t, time.Now())
+	r.clientSet.(*fake.Clientset).PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetNamespace() == "team-a" {
+			return true, nil, errors.New("forbidden")
+		}
+		return false, nil, nil
+	})
+
+	err := r.scytheCycle()
+
+	assert.Equal(t, cycleErrors{
+		{Operation: "list", Namespace: "team-a", Message: "forbidden"},
+		{Operation: "notify", Namespace: "default", Pod: "pod-1", Target: "failing", Message: "webhook unavailable"},
+	}, err)
+	// the pods of the namespaces that could be listed are still reaped
+	pods, _ := r.clientSet.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+	assert.Empty(t, pods.Items)
+
+	r.runCycle(time.Now())
+	r.runCycle(time.Now())
+	ready, message := r.health.ready()
+	assert.False(t, ready)
+	assert.Contains(t, message, "list in team-a: forbidden")
+}
+
+func TestScytheCycleWithoutErrors(t *testing.T) {
+	r := createTestReaper(minimalOptions("1.0"), createTestPod("pod-1", "default", nil))
+	assert.NoError(t, r.scytheCycle())
+}
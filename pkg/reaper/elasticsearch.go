@@ -0,0 +1,228 @@
+package reaper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// elasticsearch document types, stored in the type field
+const elasticsearchTypeReap = "reap"
+const elasticsearchTypeCycle = "cycle"
+
+var _ notifier = (*elasticsearchNotifier)(nil)
+var _ flusher = (*elasticsearchNotifier)(nil)
+
+// elasticsearchNotifier indexes reap notifications, and a summary of each reap cycle, into daily Elasticsearch or
+// OpenSearch indices with the bulk API. Documents are batched during the cycle and indexed when it ends.
+type elasticsearchNotifier struct {
+	url        string
+	index      string
+	username   string
+	password   string
+	apiKey     string
+	client     *http.Client
+	retries    int
+	retryDelay time.Duration
+
+	mutex   sync.Mutex
+	pending []reapNotification
+	// templated is whether the index template was installed, which is retried on every flush until it succeeds
+	templated bool
+}
+
+// elasticsearchCycle summarizes the notifications of a reap cycle.
+type elasticsearchCycle struct {
+	Timestamp  time.Time `json:"@timestamp"`
+	Type       string    `json:"type"`
+	CycleID    string    `json:"cycleId,omitempty"`
+	Pods       int       `json:"pods"`
+	Namespaces []string  `json:"namespaces"`
+	DryRun     bool      `json:"dryRun"`
+}
+
+// elasticsearchReap is a reap notification as indexed.
+type elasticsearchReap struct {
+	reapNotification
+	Timestamp time.Time `json:"@timestamp"`
+	Type      string    `json:"type"`
+}
+
+func (elasticsearch *elasticsearchNotifier) name() string {
+	return "elasticsearch"
+}
+
+func (elasticsearch *elasticsearchNotifier) notify(notification reapNotification) error {
+	elasticsearch.mutex.Lock()
+	defer elasticsearch.mutex.Unlock()
+	elasticsearch.pending = append(elasticsearch.pending, notification)
+	return nil
+}
+
+// flush indexes the notifications received since the last flush and a summary of them. Nothing is indexed for a
+// cycle that did not reap any pods.
+func (elasticsearch *elasticsearchNotifier) flush(ctx context.Context) error {
+	elasticsearch.mutex.Lock()
+	defer elasticsearch.mutex.Unlock()
+	pending := elasticsearch.pending
+	elasticsearch.pending = nil
+	if len(pending) == 0 {
+		return nil
+	}
+	if !elasticsearch.templated {
+		if err := elasticsearch.putIndexTemplate(ctx); err != nil {
+			return fmt.Errorf("unable to install the index template: %s", err)
+		}
+		elasticsearch.templated = true
+	}
+	last := pending[len(pending)-1]
+	cycle := elasticsearchCycle{
+		Timestamp: last.Timestamp,
+		Type:      elasticsearchTypeCycle,
+		CycleID:   last.CycleID,
+		Pods:      len(pending),
+		DryRun:    last.DryRun,
+	}
+	namespaces := map[string]bool{}
+	documents := []interface{}{}
+	for _, notification := range pending {
+		documents = append(documents, elasticsearchReap{
+			reapNotification: notification,
+			Timestamp:        notification.Timestamp,
+			Type:             elasticsearchTypeReap,
+		})
+		if !namespaces[notification.Namespace] {
+			namespaces[notification.Namespace] = true
+			cycle.Namespaces = append(cycle.Namespaces, notification.Namespace)
+		}
+	}
+	documents = append(documents, cycle)
+	return elasticsearch.bulk(ctx, documents, last.Timestamp)
+}
+
+// bulk indexes the documents into the index of the day, retrying the whole request on failures and only the rejected
+// documents when some of them are rejected with a status that may succeed later.
+func (elasticsearch *elasticsearchNotifier) bulk(ctx context.Context, documents []interface{}, now time.Time) error {
+	index := elasticsearch.index + "-" + now.UTC().Format("2006.01.02")
+	var err error
+	for attempt := 0; attempt <= elasticsearch.retries; attempt++ {
+		if attempt > 0 && !waitRetry(ctx, time.Duration(attempt)*elasticsearch.retryDelay) {
+			return fmt.Errorf("gave up after %d attempts: %s", attempt, err)
+		}
+		var body bytes.Buffer
+		encoder := json.NewEncoder(&body)
+		for _, document := range documents {
+			if err := encoder.Encode(map[string]interface{}{"index": map[string]string{"_index": index}}); err != nil {
+				return err
+			}
+			if err := encoder.Encode(document); err != nil {
+				return err
+			}
+		}
+		var response []byte
+		if response, err = elasticsearch.request(ctx, http.MethodPost, "/_bulk", "application/x-ndjson", body.Bytes()); err != nil {
+			continue
+		}
+		var result struct {
+			Errors bool `json:"errors"`
+			Items  []map[string]struct {
+				Status int `json:"status"`
+				Error  struct {
+					Type   string `json:"type"`
+					Reason string `json:"reason"`
+				} `json:"error"`
+			} `json:"items"`
+		}
+		if err = json.Unmarshal(response, &result); err != nil {
+			return fmt.Errorf("invalid bulk response: %s", err)
+		}
+		if !result.Errors {
+			return nil
+		}
+		var retry []interface{}
+		var failures []string
+		for i, item := range result.Items {
+			for _, outcome := range item {
+				if outcome.Status < 300 || i >= len(documents) {
+					continue
+				}
+				if outcome.Status == http.StatusTooManyRequests || outcome.Status >= 500 {
+					retry = append(retry, documents[i])
+				} else {
+					failures = append(failures, fmt.Sprintf("%s: %s", outcome.Error.Type, outcome.Error.Reason))
+				}
+			}
+		}
+		if len(failures) > 0 {
+			return fmt.Errorf("%d documents were rejected: %s", len(failures), strings.Join(failures, "; "))
+		}
+		if len(retry) == 0 {
+			return nil
+		}
+		documents = retry
+		err = fmt.Errorf("%d documents were rejected temporarily", len(retry))
+	}
+	return fmt.Errorf("failed after %d attempts: %s", elasticsearch.retries+1, err)
+}
+
+// putIndexTemplate installs an index template mapping the fields of the documents for the notifier's indices.
+func (elasticsearch *elasticsearchNotifier) putIndexTemplate(ctx context.Context) error {
+	keyword := map[string]string{"type": "keyword"}
+	template, err := json.Marshal(map[string]interface{}{
+		"index_patterns": []string{elasticsearch.index + "-*"},
+		"template": map[string]interface{}{
+			"mappings": map[string]interface{}{
+				"properties": map[string]interface{}{
+					"@timestamp": map[string]string{"type": "date"},
+					"type":       keyword,
+					"cycleId":    keyword,
+					"pod":        keyword,
+					"namespace":  keyword,
+					"namespaces": keyword,
+					"reasons":    map[string]string{"type": "text"},
+					"action":     keyword,
+					"dryRun":     map[string]string{"type": "boolean"},
+					"pods":       map[string]string{"type": "integer"},
+					"timestamp":  map[string]string{"type": "date"},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = elasticsearch.request(ctx, http.MethodPut, "/_index_template/"+elasticsearch.index, "application/json", template)
+	return err
+}
+
+func (elasticsearch *elasticsearchNotifier) request(ctx context.Context, method string, path string, contentType string, body []byte) ([]byte, error) {
+	request, err := http.NewRequestWithContext(ctx, method, elasticsearch.url+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", contentType)
+	if elasticsearch.apiKey != "" {
+		request.Header.Set("Authorization", "ApiKey "+elasticsearch.apiKey)
+	} else if elasticsearch.username != "" {
+		request.SetBasicAuth(elasticsearch.username, elasticsearch.password)
+	}
+	response, err := elasticsearch.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	content, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return nil, fmt.Errorf("unexpected response status %s", response.Status)
+	}
+	return content, nil
+}
@@ -0,0 +1,180 @@
+package reaper
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testElasticsearch records the requests made to a fake elasticsearch, answering bulk requests with the item statuses
+// returned by status.
+type testElasticsearch struct {
+	mutex     sync.Mutex
+	templates int
+	bulks     [][]map[string]interface{}
+	headers   []http.Header
+	status    func(bulk int, item int) int
+}
+
+func (fake *testElasticsearch) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	fake.headers = append(fake.headers, request.Header.Clone())
+	if request.Method == http.MethodPut && strings.HasPrefix(request.URL.Path, "/_index_template/") {
+		fake.templates++
+		fmt.Fprint(writer, `{"acknowledged":true}`)
+		return
+	}
+	var lines []map[string]interface{}
+	scanner := bufio.NewScanner(request.Body)
+	for scanner.Scan() {
+		var line map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		lines = append(lines, line)
+	}
+	bulk := len(fake.bulks)
+	fake.bulks = append(fake.bulks, lines)
+	errors := false
+	var items []string
+	for i := 0; i < len(lines)/2; i++ {
+		status := http.StatusCreated
+		if fake.status != nil {
+			status = fake.status(bulk, i)
+		}
+		if status >= 300 {
+			errors = true
+			items = append(items, fmt.Sprintf(`{"index":{"status":%d,"error":{"type":"rejected","reason":"status %d"}}}`, status, status))
+		} else {
+			items = append(items, fmt.Sprintf(`{"index":{"status":%d}}`, status))
+		}
+	}
+	fmt.Fprintf(writer, `{"errors":%t,"items":[%s]}`, errors, strings.Join(items, ","))
+}
+
+func testElasticsearchNotifier(t *testing.T, fake *testElasticsearch) *elasticsearchNotifier {
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return &elasticsearchNotifier{
+		url:        server.URL,
+		index:      "pod-reaper",
+		client:     &http.Client{Timeout: time.Second},
+		retries:    2,
+		retryDelay: time.Millisecond,
+	}
+}
+
+func TestElasticsearchNotifier(t *testing.T) {
+	timestamp := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	notifications := []reapNotification{
+		{Pod: "pod-a", Namespace: "default", Reasons: []string{"reason"}, Action: actionDelete, Timestamp: timestamp, CycleID: "cycle"},
+		{Pod: "pod-b", Namespace: "other", Reasons: []string{"reason"}, Action: actionDelete, Timestamp: timestamp, CycleID: "cycle"},
+		{Pod: "pod-c", Namespace: "default", Reasons: []string{"reason"}, Action: actionDelete, Timestamp: timestamp, CycleID: "cycle"},
+	}
+
+	t.Run("indexes reaps and a cycle summary", func(t *testing.T) {
+		fake := &testElasticsearch{}
+		elasticsearch := testElasticsearchNotifier(t, fake)
+		for _, notification := range notifications {
+			assert.NoError(t, elasticsearch.notify(notification))
+		}
+		assert.NoError(t, elasticsearch.flush(context.Background()))
+
+		assert.Equal(t, 1, fake.templates)
+		assert.Len(t, fake.bulks, 1)
+		lines := fake.bulks[0]
+		assert.Len(t, lines, 8)
+		for i := 0; i < len(lines); i += 2 {
+			assert.Equal(t, map[string]interface{}{"index": map[string]interface{}{"_index": "pod-reaper-2026.03.04"}}, lines[i])
+		}
+		assert.Equal(t, "reap", lines[1]["type"])
+		assert.Equal(t, "pod-a", lines[1]["pod"])
+		assert.Equal(t, "2026-03-04T05:06:07Z", lines[1]["@timestamp"])
+		summary := lines[7]
+		assert.Equal(t, "cycle", summary["type"])
+		assert.Equal(t, "cycle", summary["cycleId"])
+		assert.Equal(t, float64(3), summary["pods"])
+		assert.Equal(t, []interface{}{"default", "other"}, summary["namespaces"])
+	})
+	t.Run("installs the template once", func(t *testing.T) {
+		fake := &testElasticsearch{}
+		elasticsearch := testElasticsearchNotifier(t, fake)
+		assert.NoError(t, elasticsearch.notify(notifications[0]))
+		assert.NoError(t, elasticsearch.flush(context.Background()))
+		assert.NoError(t, elasticsearch.notify(notifications[1]))
+		assert.NoError(t, elasticsearch.flush(context.Background()))
+		assert.Equal(t, 1, fake.templates)
+		assert.Len(t, fake.bulks, 2)
+	})
+	t.Run("nothing to index", func(t *testing.T) {
+		fake := &testElasticsearch{}
+		elasticsearch := testElasticsearchNotifier(t, fake)
+		assert.NoError(t, elasticsearch.flush(context.Background()))
+		assert.Empty(t, fake.headers)
+	})
+	t.Run("retries temporarily rejected documents", func(t *testing.T) {
+		fake := &testElasticsearch{status: func(bulk int, item int) int {
+			if bulk == 0 && item == 1 {
+				return http.StatusTooManyRequests
+			}
+			return http.StatusCreated
+		}}
+		elasticsearch := testElasticsearchNotifier(t, fake)
+		for _, notification := range notifications {
+			assert.NoError(t, elasticsearch.notify(notification))
+		}
+		assert.NoError(t, elasticsearch.flush(context.Background()))
+		assert.Len(t, fake.bulks, 2)
+		assert.Len(t, fake.bulks[1], 2)
+		assert.Equal(t, "pod-b", fake.bulks[1][1]["pod"])
+	})
+	t.Run("gives up after retries", func(t *testing.T) {
+		fake := &testElasticsearch{status: func(int, int) int { return http.StatusServiceUnavailable }}
+		elasticsearch := testElasticsearchNotifier(t, fake)
+		assert.NoError(t, elasticsearch.notify(notifications[0]))
+		assert.Error(t, elasticsearch.flush(context.Background()))
+		assert.Len(t, fake.bulks, 3)
+	})
+	t.Run("rejected documents", func(t *testing.T) {
+		fake := &testElasticsearch{status: func(int, int) int { return http.StatusBadRequest }}
+		elasticsearch := testElasticsearchNotifier(t, fake)
+		assert.NoError(t, elasticsearch.notify(notifications[0]))
+		err := elasticsearch.flush(context.Background())
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "rejected")
+		assert.Len(t, fake.bulks, 1)
+	})
+	t.Run("api key", func(t *testing.T) {
+		fake := &testElasticsearch{}
+		elasticsearch := testElasticsearchNotifier(t, fake)
+		elasticsearch.apiKey = "key"
+		elasticsearch.username = "user"
+		assert.NoError(t, elasticsearch.notify(notifications[0]))
+		assert.NoError(t, elasticsearch.flush(context.Background()))
+		for _, headers := range fake.headers {
+			assert.Equal(t, "ApiKey key", headers.Get("Authorization"))
+		}
+	})
+	t.Run("basic auth", func(t *testing.T) {
+		fake := &testElasticsearch{}
+		elasticsearch := testElasticsearchNotifier(t, fake)
+		elasticsearch.username = "user"
+		elasticsearch.password = "password"
+		assert.NoError(t, elasticsearch.notify(notifications[0]))
+		assert.NoError(t, elasticsearch.flush(context.Background()))
+		for _, headers := range fake.headers {
+			assert.Equal(t, "Basic dXNlcjpwYXNzd29yZA==", headers.Get("Authorization"))
+		}
+	})
+}
@@ -0,0 +1,102 @@
+package reaper
+
+import (
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// graceEscalation shortens the grace period of pods whose owner was already reaped recently, halving it for each
+// earlier reap of the owner within the window down to the floor. A nil graceEscalation never changes grace periods.
+type graceEscalation struct {
+	window time.Duration
+	floor  time.Duration
+	mutex  sync.Mutex
+	// reaps holds the times at which pods of each owner were reaped within the window
+	reaps map[string][]time.Time
+}
+
+func newGraceEscalation(window time.Duration, floor time.Duration) *graceEscalation {
+	if window <= 0 {
+		return nil
+	}
+	return &graceEscalation{
+		window: window,
+		floor:  floor,
+		reaps:  map[string][]time.Time{},
+	}
+}
+
+// ownerKey identifies the controller of the pod, or the empty string for pods without one.
+func ownerKey(pod v1.Pod) string {
+	owner := metav1.GetControllerOf(&pod)
+	if owner == nil {
+		return ""
+	}
+	return pod.Namespace + "/" + owner.Kind + "/" + owner.Name
+}
+
+// recent returns the owner's reaps within the window, forgetting older ones. The mutex must be held.
+func (escalation *graceEscalation) recent(owner string, now time.Time) []time.Time {
+	var recent []time.Time
+	for _, reaped := range escalation.reaps[owner] {
+		if now.Sub(reaped) < escalation.window {
+			recent = append(recent, reaped)
+		}
+	}
+	if len(recent) == 0 {
+		delete(escalation.reaps, owner)
+	} else {
+		escalation.reaps[owner] = recent
+	}
+	return recent
+}
+
+// gracePeriod returns the grace period in seconds to reap the pod with, given the configured grace period (nil for
+// the pod's own).
+func (escalation *graceEscalation) gracePeriod(pod v1.Pod, configured *int64, now time.Time) *int64 {
+	if escalation == nil {
+		return configured
+	}
+	owner := ownerKey(pod)
+	if owner == "" {
+		return configured
+	}
+	escalation.mutex.Lock()
+	repeats := len(escalation.recent(owner, now))
+	escalation.mutex.Unlock()
+	if repeats == 0 {
+		return configured
+	}
+	seconds := int64(v1.DefaultTerminationGracePeriodSeconds)
+	if configured != nil {
+		seconds = *configured
+	} else if pod.Spec.TerminationGracePeriodSeconds != nil {
+		seconds = *pod.Spec.TerminationGracePeriodSeconds
+	}
+	floor := int64(escalation.floor.Seconds())
+	// a grace period already below the floor is never lengthened
+	for i := 0; i < repeats && seconds > floor; i++ {
+		seconds /= 2
+		if seconds < floor {
+			seconds = floor
+		}
+	}
+	return &seconds
+}
+
+// reaped records that a pod was reaped, escalating the next reap of its owner within the window.
+func (escalation *graceEscalation) reaped(pod v1.Pod, now time.Time) {
+	if escalation == nil {
+		return
+	}
+	owner := ownerKey(pod)
+	if owner == "" {
+		return
+	}
+	escalation.mutex.Lock()
+	defer escalation.mutex.Unlock()
+	escalation.reaps[owner] = append(escalation.recent(owner, now), now)
+}
@@ -0,0 +1,75 @@
+package reaper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testOwnedPod(name string, owner string) v1.Pod {
+	controller := true
+	pod := createTestPod(name, "default", nil)
+	pod.OwnerReferences = []metav1.OwnerReference{{Kind: "ReplicaSet", Name: owner, Controller: &controller}}
+	return pod
+}
+
+func TestGraceEscalation(t *testing.T) {
+	now := time.Now()
+	sixty := int64(60)
+
+	t.Run("disabled", func(t *testing.T) {
+		escalation := newGraceEscalation(0, 0)
+		assert.Nil(t, escalation)
+		escalation.reaped(testOwnedPod("pod", "owner"), now)
+		assert.Equal(t, &sixty, escalation.gracePeriod(testOwnedPod("pod", "owner"), &sixty, now))
+	})
+	t.Run("first reap uses configured grace period", func(t *testing.T) {
+		escalation := newGraceEscalation(time.Hour, 0)
+		assert.Equal(t, &sixty, escalation.gracePeriod(testOwnedPod("pod", "owner"), &sixty, now))
+		assert.Nil(t, escalation.gracePeriod(testOwnedPod("pod", "owner"), nil, now))
+	})
+	t.Run("repeats halve the grace period", func(t *testing.T) {
+		escalation := newGraceEscalation(time.Hour, 0)
+		escalation.reaped(testOwnedPod("pod-1", "owner"), now)
+		assert.Equal(t, int64(30), *escalation.gracePeriod(testOwnedPod("pod-2", "owner"), &sixty, now))
+		escalation.reaped(testOwnedPod("pod-2", "owner"), now)
+		assert.Equal(t, int64(15), *escalation.gracePeriod(testOwnedPod("pod-3", "owner"), &sixty, now))
+	})
+	t.Run("other owners are not escalated", func(t *testing.T) {
+		escalation := newGraceEscalation(time.Hour, 0)
+		escalation.reaped(testOwnedPod("pod-1", "owner"), now)
+		assert.Equal(t, &sixty, escalation.gracePeriod(testOwnedPod("pod-2", "other"), &sixty, now))
+	})
+	t.Run("pods without owners are not escalated", func(t *testing.T) {
+		escalation := newGraceEscalation(time.Hour, 0)
+		escalation.reaped(createTestPod("pod", "default", nil), now)
+		assert.Empty(t, escalation.reaps)
+		assert.Equal(t, &sixty, escalation.gracePeriod(createTestPod("pod", "default", nil), &sixty, now))
+	})
+	t.Run("floor", func(t *testing.T) {
+		escalation := newGraceEscalation(time.Hour, 20*time.Second)
+		for i := 0; i < 5; i++ {
+			escalation.reaped(testOwnedPod("pod", "owner"), now)
+		}
+		assert.Equal(t, int64(20), *escalation.gracePeriod(testOwnedPod("pod", "owner"), &sixty, now))
+		ten := int64(10)
+		assert.Equal(t, int64(10), *escalation.gracePeriod(testOwnedPod("pod", "owner"), &ten, now))
+	})
+	t.Run("pod grace period", func(t *testing.T) {
+		escalation := newGraceEscalation(time.Hour, 0)
+		escalation.reaped(testOwnedPod("pod-1", "owner"), now)
+		pod := testOwnedPod("pod-2", "owner")
+		assert.Equal(t, int64(15), *escalation.gracePeriod(pod, nil, now))
+		pod.Spec.TerminationGracePeriodSeconds = &sixty
+		assert.Equal(t, int64(30), *escalation.gracePeriod(pod, nil, now))
+	})
+	t.Run("window expires", func(t *testing.T) {
+		escalation := newGraceEscalation(time.Hour, 0)
+		escalation.reaped(testOwnedPod("pod-1", "owner"), now)
+		assert.Equal(t, &sixty, escalation.gracePeriod(testOwnedPod("pod-2", "owner"), &sixty, now.Add(time.Hour)))
+		assert.Empty(t, escalation.reaps)
+	})
+}
@@ -0,0 +1,150 @@
+package reaper
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// eventStreamEvent is the server-sent event type of reap notifications on /events
+const eventStreamEvent = "reap"
+
+// eventStreamHistory is the number of recent notifications kept to replay to subscribers that reconnect
+const eventStreamHistory = 256
+
+// eventStreamBuffer is the number of notifications a subscriber may fall behind by before notifications are dropped
+const eventStreamBuffer = 64
+
+// eventStreamKeepAlive is how often an idle stream sends a comment, so that proxies do not close the connection
+const eventStreamKeepAlive = 30 * time.Second
+
+var _ notifier = (*eventStream)(nil)
+
+// streamedEvent is a reap notification numbered in the order it was sent, the number being the server-sent event id.
+type streamedEvent struct {
+	id   uint64
+	data []byte
+}
+
+// eventStream serves reap notifications as server-sent events at /events when ADMIN_TOKEN is set. Recent
+// notifications are kept so that subscribers reconnecting with Last-Event-ID do not miss any, and notifications are
+// dropped for subscribers that do not keep up rather than slowing down the reap cycle.
+type eventStream struct {
+	mutex       sync.Mutex
+	lastID      uint64
+	history     []streamedEvent
+	subscribers map[chan streamedEvent]bool
+	keepAlive   time.Duration
+}
+
+func newEventStream() *eventStream {
+	return &eventStream{
+		subscribers: map[chan streamedEvent]bool{},
+		keepAlive:   eventStreamKeepAlive,
+	}
+}
+
+func (stream *eventStream) name() string {
+	return "events"
+}
+
+func (stream *eventStream) notify(notification reapNotification) error {
+	data, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+	stream.mutex.Lock()
+	defer stream.mutex.Unlock()
+	stream.lastID++
+	event := streamedEvent{id: stream.lastID, data: data}
+	stream.history = append(stream.history, event)
+	if len(stream.history) > eventStreamHistory {
+		stream.history = stream.history[len(stream.history)-eventStreamHistory:]
+	}
+	for subscriber := range stream.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+			logrus.WithField("id", event.id).Warn("dropped reap event for a subscriber that is falling behind")
+		}
+	}
+	return nil
+}
+
+// subscribe registers a subscriber and returns the notifications sent after lastID that are still kept. An id that
+// was never sent is from before pod-reaper restarted, so every kept notification is new to the subscriber.
+func (stream *eventStream) subscribe(lastID uint64) (chan streamedEvent, []streamedEvent) {
+	stream.mutex.Lock()
+	defer stream.mutex.Unlock()
+	if lastID > stream.lastID {
+		lastID = 0
+	}
+	subscriber := make(chan streamedEvent, eventStreamBuffer)
+	stream.subscribers[subscriber] = true
+	var missed []streamedEvent
+	for _, event := range stream.history {
+		if event.id > lastID {
+			missed = append(missed, event)
+		}
+	}
+	return subscriber, missed
+}
+
+func (stream *eventStream) unsubscribe(subscriber chan streamedEvent) {
+	stream.mutex.Lock()
+	defer stream.mutex.Unlock()
+	delete(stream.subscribers, subscriber)
+}
+
+func (stream *eventStream) handle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	var lastID uint64
+	if value := r.Header.Get("Last-Event-ID"); value != "" {
+		parsed, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid Last-Event-ID: %s", err), http.StatusBadRequest)
+			return
+		}
+		lastID = parsed
+	}
+	subscriber, missed := stream.subscribe(lastID)
+	defer stream.unsubscribe(subscriber)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	for _, event := range missed {
+		writeStreamedEvent(w, event)
+	}
+	flusher.Flush()
+	keepAlive := time.NewTicker(stream.keepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event := <-subscriber:
+			writeStreamedEvent(w, event)
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		}
+		flusher.Flush()
+	}
+}
+
+func writeStreamedEvent(w http.ResponseWriter, event streamedEvent) {
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.id, eventStreamEvent, event.data)
+}
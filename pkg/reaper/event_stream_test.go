@@ -0,0 +1,102 @@
+package reaper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/target/pod-reaper/events"
+)
+
+func TestEventStream(t *testing.T) {
+	notification := reapNotification{
+		Pod:       "test-pod",
+		Namespace: "default",
+		Reasons:   []string{"reason"},
+		Rules:     []string{"duration"},
+		Action:    actionDelete,
+		Timestamp: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		CycleID:   "cycle",
+	}
+
+	t.Run("subscribe", func(t *testing.T) {
+		stream := newEventStream()
+		assert.NoError(t, stream.notify(notification))
+		server := httptest.NewServer(requireToken("token", stream.handle))
+		defer server.Close()
+		client := events.Client{URL: server.URL, Token: "token"}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		var received []events.ReapRecord
+		err := client.Subscribe(ctx, func(record events.ReapRecord) error {
+			received = append(received, record)
+			if len(received) == 1 {
+				// sent once the subscriber is registered, since the first event is replayed from the history
+				second := notification
+				second.Pod = "second-pod"
+				assert.NoError(t, stream.notify(second))
+			} else {
+				cancel()
+			}
+			return nil
+		})
+		assert.Equal(t, context.Canceled, err)
+		if assert.Len(t, received, 2) {
+			assert.Equal(t, events.ReapRecord{
+				Pod:       "test-pod",
+				Namespace: "default",
+				Reasons:   []string{"reason"},
+				Rules:     []string{events.RuleDuration},
+				Action:    events.ActionDelete,
+				Timestamp: notification.Timestamp,
+				CycleID:   "cycle",
+			}, received[0])
+			assert.Equal(t, "second-pod", received[1].Pod)
+		}
+	})
+	t.Run("replays after last event id", func(t *testing.T) {
+		stream := newEventStream()
+		for i := 0; i < 3; i++ {
+			assert.NoError(t, stream.notify(notification))
+		}
+		subscriber, missed := stream.subscribe(1)
+		defer stream.unsubscribe(subscriber)
+		if assert.Len(t, missed, 2) {
+			assert.Equal(t, uint64(2), missed[0].id)
+		}
+	})
+	t.Run("replays everything after a restart", func(t *testing.T) {
+		stream := newEventStream()
+		assert.NoError(t, stream.notify(notification))
+		subscriber, missed := stream.subscribe(100)
+		defer stream.unsubscribe(subscriber)
+		assert.Len(t, missed, 1)
+	})
+	t.Run("keeps recent history", func(t *testing.T) {
+		stream := newEventStream()
+		for i := 0; i < eventStreamHistory+10; i++ {
+			assert.NoError(t, stream.notify(notification))
+		}
+		assert.Len(t, stream.history, eventStreamHistory)
+		assert.Equal(t, uint64(11), stream.history[0].id)
+	})
+	t.Run("drops events for slow subscribers", func(t *testing.T) {
+		stream := newEventStream()
+		subscriber, _ := stream.subscribe(0)
+		defer stream.unsubscribe(subscriber)
+		for i := 0; i < eventStreamBuffer+10; i++ {
+			assert.NoError(t, stream.notify(notification))
+		}
+		assert.Len(t, subscriber, eventStreamBuffer)
+	})
+	t.Run("invalid last event id", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodGet, "/events", nil)
+		request.Header.Set("Last-Event-ID", "latest")
+		recorder := httptest.NewRecorder()
+		newEventStream().handle(recorder, request)
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+}
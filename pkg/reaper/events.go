@@ -0,0 +1,68 @@
+package reaper
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const eventComponent = "pod-reaper"
+const eventReasonReaped = "Reaped"
+const eventReasonMarked = "Marked"
+const eventReasonOwnerScaledDown = "OwnerScaledDown"
+const eventReasonReapSkipped = "ReapSkipped"
+
+// emitSkipEvent records a warning event against a pod that matched the rules but was not reaped, when
+// EMIT_SKIP_EVENTS is enabled.
+func (reaper reaper) emitSkipEvent(pod v1.Pod, reasons []string, skipReason string) {
+	if !reaper.options.emitSkipEvents {
+		return
+	}
+	message := fmt.Sprintf("pod would have been reaped but %s: %s", skipReason, strings.Join(reasons, ", "))
+	reaper.emitEvent(pod, v1.EventTypeWarning, eventReasonReapSkipped, message)
+}
+
+// emitEvent records a kubernetes event against the pod. Failures are logged but never interrupt the reap cycle.
+func (reaper reaper) emitEvent(pod v1.Pod, eventType string, reason string, message string) {
+	now := metav1.NewTime(time.Now())
+	event := &v1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			// matches the naming used by client-go's event recorder
+			Name:      fmt.Sprintf("%v.%x", pod.Name, now.UnixNano()),
+			Namespace: pod.Namespace,
+		},
+		InvolvedObject: v1.ObjectReference{
+			APIVersion:      "v1",
+			Kind:            "Pod",
+			Namespace:       pod.Namespace,
+			Name:            pod.Name,
+			UID:             pod.UID,
+			ResourceVersion: pod.ResourceVersion,
+		},
+		Reason:              reason,
+		Message:             message,
+		Type:                eventType,
+		Source:              v1.EventSource{Component: eventComponent},
+		FirstTimestamp:      now,
+		LastTimestamp:       now,
+		Count:               1,
+		ReportingController: eventComponent,
+	}
+	if !reaper.apiCall(operationCreateEvent) {
+		logrus.WithField("pod", pod.Name).Debug("api call budget exhausted, not creating event")
+		return
+	}
+	ctx, cancel := reaper.apiContext()
+	defer cancel()
+	_, err := reaper.clientSet.CoreV1().Events(pod.Namespace).Create(ctx, event, metav1.CreateOptions{})
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"pod":    pod.Name,
+			"reason": reason,
+		}).WithError(err).Warn("unable to create event")
+	}
+}
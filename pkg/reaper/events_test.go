@@ -0,0 +1,174 @@
+package reaper
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func listEvents(t *testing.T, r reaper, namespace string) []v1.Event {
+	events, err := r.clientSet.CoreV1().Events(namespace).List(context.TODO(), metav1.ListOptions{})
+	assert.NoError(t, err)
+	return events.Items
+}
+
+func TestEmitEvent(t *testing.T) {
+	t.Run("create", func(t *testing.T) {
+		startTime := time.Now()
+		pod := createTestPod("test-pod", "default", &startTime)
+		pod.UID = "test-uid"
+		r := createTestReaper(minimalOptions("0.0"), pod)
+
+		r.emitEvent(pod, v1.EventTypeNormal, eventReasonReaped, "message")
+
+		events := listEvents(t, r, "default")
+		if assert.Equal(t, 1, len(events)) {
+			assert.Equal(t, "Pod", events[0].InvolvedObject.Kind)
+			assert.Equal(t, "test-pod", events[0].InvolvedObject.Name)
+			assert.Equal(t, "test-uid", string(events[0].InvolvedObject.UID))
+			assert.Equal(t, eventReasonReaped, events[0].Reason)
+			assert.Equal(t, "message", events[0].Message)
+			assert.Equal(t, v1.EventTypeNormal, events[0].Type)
+			assert.Equal(t, eventComponent, events[0].Source.Component)
+		}
+	})
+
+	t.Run("create error does not panic", func(t *testing.T) {
+		startTime := time.Now()
+		pod := createTestPod("test-pod", "default", &startTime)
+		opts := minimalOptions("0.0")
+		opts.emitEvents = true
+		r := createTestReaper(opts, pod)
+		r.clientSet.(*fake.Clientset).PrependReactor("create", "events", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, errors.New("simulated event error")
+		})
+
+		assert.NotPanics(t, func() {
+			r.emitEvent(pod, v1.EventTypeNormal, eventReasonReaped, "message")
+		})
+	})
+}
+
+func TestReapPodEvents(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		startTime := time.Now()
+		pod := createTestPod("test-pod", "default", &startTime)
+		r := createTestReaper(minimalOptions("0.0"), pod)
+
+		r.reapPod(pod, []string{"reason"}, 0)
+
+		assert.Empty(t, listEvents(t, r, "default"))
+	})
+
+	t.Run("reaped pod records event", func(t *testing.T) {
+		startTime := time.Now()
+		pod := createTestPod("test-pod", "default", &startTime)
+		opts := minimalOptions("0.0")
+		opts.emitEvents = true
+		r := createTestReaper(opts, pod)
+
+		r.reapPod(pod, []string{"reason one", "reason two"}, 0)
+
+		events := listEvents(t, r, "default")
+		if assert.Equal(t, 1, len(events)) {
+			assert.Equal(t, "pod was reaped: reason one, reason two", events[0].Message)
+		}
+	})
+
+	t.Run("dry run records no event", func(t *testing.T) {
+		startTime := time.Now()
+		pod := createTestPod("test-pod", "default", &startTime)
+		opts := minimalOptions("0.0")
+		opts.emitEvents = true
+		opts.dryRun = true
+		r := createTestReaper(opts, pod)
+
+		r.reapPod(pod, []string{"reason"}, 0)
+
+		assert.Empty(t, listEvents(t, r, "default"))
+	})
+
+	t.Run("failed delete records no event", func(t *testing.T) {
+		startTime := time.Now()
+		pod := createTestPod("test-pod", "default", &startTime)
+		opts := minimalOptions("0.0")
+		opts.emitEvents = true
+		r := createTestReaper(opts, pod)
+		r.clientSet.(*fake.Clientset).PrependReactor("delete", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, errors.New("simulated delete error")
+		})
+
+		r.reapPod(pod, []string{"reason"}, 0)
+
+		assert.Empty(t, listEvents(t, r, "default"))
+	})
+}
+
+func TestReapPodSkipEvents(t *testing.T) {
+	t.Run("dry run records warning", func(t *testing.T) {
+		startTime := time.Now()
+		pod := createTestPod("test-pod", "default", &startTime)
+		opts := minimalOptions("0.0")
+		opts.emitSkipEvents = true
+		opts.dryRun = true
+		r := createTestReaper(opts, pod)
+
+		r.reapPod(pod, []string{"reason one", "reason two"}, 0)
+
+		events := listEvents(t, r, "default")
+		if assert.Equal(t, 1, len(events)) {
+			assert.Equal(t, v1.EventTypeWarning, events[0].Type)
+			assert.Equal(t, eventReasonReapSkipped, events[0].Reason)
+			assert.Equal(t, "pod would have been reaped but pod-reaper is in dry-run mode: reason one, reason two", events[0].Message)
+		}
+	})
+
+	t.Run("max pods records warning", func(t *testing.T) {
+		startTime := time.Now()
+		pod := createTestPod("test-pod", "default", &startTime)
+		opts := minimalOptions("0.0")
+		opts.emitSkipEvents = true
+		opts.maxPods = 1
+		r := createTestReaper(opts, pod)
+
+		r.reapPod(pod, []string{"reason"}, 1)
+
+		events := listEvents(t, r, "default")
+		if assert.Equal(t, 1, len(events)) {
+			assert.Equal(t, "pod would have been reaped but maxPods is exceeded: reason", events[0].Message)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		startTime := time.Now()
+		pod := createTestPod("test-pod", "default", &startTime)
+		opts := minimalOptions("0.0")
+		opts.dryRun = true
+		opts.emitEvents = true
+		r := createTestReaper(opts, pod)
+
+		r.reapPod(pod, []string{"reason"}, 0)
+
+		assert.Empty(t, listEvents(t, r, "default"))
+	})
+
+	t.Run("reaped pod records no warning", func(t *testing.T) {
+		startTime := time.Now()
+		pod := createTestPod("test-pod", "default", &startTime)
+		opts := minimalOptions("0.0")
+		opts.emitSkipEvents = true
+		r := createTestReaper(opts, pod)
+
+		r.reapPod(pod, []string{"reason"}, 0)
+
+		assert.Empty(t, listEvents(t, r, "default"))
+	})
+}
@@ -0,0 +1,92 @@
+package reaper
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes"
+)
+
+// eviction API group versions, policy/v1 being served since kubernetes 1.22
+const evictionPolicyV1 = "policy/v1"
+const evictionPolicyV1beta1 = "policy/v1beta1"
+
+// detectEvictionVersion returns the preferred group version of the eviction API served by the cluster, or the empty
+// string if the cluster does not serve evictions.
+func detectEvictionVersion(client discovery.DiscoveryInterface) (string, error) {
+	groups, err := client.ServerGroups()
+	if err != nil {
+		return "", err
+	}
+	policyVersion := ""
+	for _, group := range groups.Groups {
+		if group.Name == policyv1.GroupName {
+			policyVersion = group.PreferredVersion.GroupVersion
+		}
+	}
+	if policyVersion == "" {
+		return "", nil
+	}
+	// evictions are a subresource of pods, so the policy group alone does not mean they are served
+	resources, err := client.ServerResourcesForGroupVersion("v1")
+	if err != nil {
+		return "", err
+	}
+	for _, resource := range resources.APIResources {
+		if resource.Name == "pods/eviction" && resource.Kind == "Eviction" {
+			return policyVersion, nil
+		}
+	}
+	return "", nil
+}
+
+// evictionAPI detects the eviction API version to use and returns it with the action to reap pods with, falling back
+// to deleting pods on clusters that do not serve evictions.
+func evictionAPI(clientSet kubernetes.Interface) (string, string) {
+	version, err := detectEvictionVersion(clientSet.Discovery())
+	if err != nil {
+		logrus.WithError(err).Warnf("unable to detect the eviction api version, assuming %s", evictionPolicyV1)
+		return evictionPolicyV1, actionEvict
+	}
+	if version == "" {
+		logrus.Warn("the cluster does not serve the eviction api, pods will be deleted instead")
+		return "", actionDelete
+	}
+	logrus.WithField("evictionApi", version).Info("using the eviction api")
+	return version, actionEvict
+}
+
+// evictionGroupVersion returns the eviction API group version pods are evicted with.
+func (reaper reaper) evictionGroupVersion() string {
+	if reaper.evictionVersion == "" {
+		return evictionPolicyV1
+	}
+	return reaper.evictionVersion
+}
+
+// evict evicts the pod with the eviction API version detected at startup.
+func (reaper reaper) evict(pod v1.Pod, deleteOptions *metav1.DeleteOptions) error {
+	objectMeta := metav1.ObjectMeta{Namespace: pod.Namespace, Name: pod.Name}
+	version := reaper.evictionGroupVersion()
+	err := reaper.retryAPICall(operationEvict, func(ctx context.Context) error {
+		if version == evictionPolicyV1beta1 {
+			return reaper.clientSet.PolicyV1beta1().Evictions(pod.Namespace).Evict(ctx, &policyv1beta1.Eviction{
+				ObjectMeta:    objectMeta,
+				DeleteOptions: deleteOptions,
+			})
+		}
+		return reaper.clientSet.PolicyV1().Evictions(pod.Namespace).Evict(ctx, &policyv1.Eviction{
+			ObjectMeta:    objectMeta,
+			DeleteOptions: deleteOptions,
+		})
+	})
+	if err == nil {
+		observeEviction(version)
+	}
+	return err
+}
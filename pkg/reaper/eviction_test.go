@@ -0,0 +1,110 @@
+package reaper
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	policyv1 "k8s.io/api/policy/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func testDiscoveryClient(evictions bool, policyVersions ...string) *fake.Clientset {
+	client := fake.NewSimpleClientset()
+	core := &metav1.APIResourceList{GroupVersion: "v1", APIResources: []metav1.APIResource{{Name: "pods", Kind: "Pod"}}}
+	if evictions {
+		core.APIResources = append(core.APIResources, metav1.APIResource{Name: "pods/eviction", Kind: "Eviction", Group: "policy"})
+	}
+	client.Resources = append(client.Resources, core)
+	for _, version := range policyVersions {
+		client.Resources = append(client.Resources, &metav1.APIResourceList{
+			GroupVersion: version,
+			APIResources: []metav1.APIResource{{Name: "poddisruptionbudgets", Kind: "PodDisruptionBudget"}},
+		})
+	}
+	return client
+}
+
+func TestDetectEvictionVersion(t *testing.T) {
+	t.Run("policy/v1", func(t *testing.T) {
+		version, err := detectEvictionVersion(testDiscoveryClient(true, evictionPolicyV1, evictionPolicyV1beta1).Discovery())
+		assert.NoError(t, err)
+		assert.Equal(t, evictionPolicyV1, version)
+	})
+	t.Run("policy/v1beta1", func(t *testing.T) {
+		version, err := detectEvictionVersion(testDiscoveryClient(true, evictionPolicyV1beta1).Discovery())
+		assert.NoError(t, err)
+		assert.Equal(t, evictionPolicyV1beta1, version)
+	})
+	t.Run("no policy group", func(t *testing.T) {
+		version, err := detectEvictionVersion(testDiscoveryClient(true).Discovery())
+		assert.NoError(t, err)
+		assert.Equal(t, "", version)
+	})
+	t.Run("no eviction subresource", func(t *testing.T) {
+		version, err := detectEvictionVersion(testDiscoveryClient(false, evictionPolicyV1).Discovery())
+		assert.NoError(t, err)
+		assert.Equal(t, "", version)
+	})
+}
+
+func TestEvictionAPI(t *testing.T) {
+	t.Run("supported", func(t *testing.T) {
+		version, action := evictionAPI(testDiscoveryClient(true, evictionPolicyV1beta1))
+		assert.Equal(t, evictionPolicyV1beta1, version)
+		assert.Equal(t, actionEvict, action)
+	})
+	t.Run("unsupported falls back to delete", func(t *testing.T) {
+		_, action := evictionAPI(testDiscoveryClient(false))
+		assert.Equal(t, actionDelete, action)
+	})
+}
+
+func TestEvict(t *testing.T) {
+	startTime := time.Now()
+	pod := createTestPod("test-pod", "default", &startTime)
+	for _, version := range []string{"", evictionPolicyV1, evictionPolicyV1beta1} {
+		t.Run("version "+version, func(t *testing.T) {
+			client := fake.NewSimpleClientset(&pod)
+			var evicted runtime.Object
+			client.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				if action.GetSubresource() != "eviction" {
+					return false, nil, nil
+				}
+				evicted = action.(k8stesting.CreateAction).GetObject()
+				return true, nil, nil
+			})
+			r := reaper{clientSet: client, options: minimalOptions("1.0"), evictionVersion: version}
+
+			assert.NoError(t, r.evict(pod, &metav1.DeleteOptions{}))
+
+			if version == evictionPolicyV1beta1 {
+				assert.IsType(t, &policyv1beta1.Eviction{}, evicted)
+			} else {
+				assert.IsType(t, &policyv1.Eviction{}, evicted)
+			}
+		})
+	}
+}
+
+func TestReapPodEvictionFallback(t *testing.T) {
+	startTime := time.Now()
+	pod := createTestPod("test-pod", "default", &startTime)
+	opts := minimalOptions("1.0")
+	opts.action = actionEvict
+	r := createTestReaper(opts, pod)
+	r.evictionVersion, r.options.action = evictionAPI(testDiscoveryClient(false))
+
+	assert.True(t, r.reapPod(pod, []string{"reason"}, 0))
+
+	for _, action := range r.clientSet.(*fake.Clientset).Actions() {
+		assert.NotEqual(t, "eviction", action.GetSubresource())
+	}
+	_, err := r.clientSet.CoreV1().Pods("default").Get(context.TODO(), "test-pod", metav1.GetOptions{})
+	assert.Error(t, err)
+}
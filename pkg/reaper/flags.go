@@ -0,0 +1,234 @@
+package reaper
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/target/pod-reaper/rules"
+)
+
+// secrets are only accepted from files on the command line, since arguments are visible to every process on the node
+const secretFileSuffix = "_FILE"
+
+// optionFlags are the options of pod-reaper that can also be passed as flags, in the order they are listed in help.
+var optionFlags = []rules.Option{
+	{Name: envNamespace, Usage: "the kubernetes namespace where pod-reaper should look for pods"},
+	{Name: envNamespaces, Usage: "comma-separated list of kubernetes namespaces where pod-reaper should look for pods"},
+	{Name: envScheduleCron, Usage: "schedule for when pod-reaper should look for pods to reap (default: @every 1m)"},
+	{Name: envScheduleTimezone, Usage: "timezone the schedule runs in, instead of the container's local time (example: America/Chicago)"},
+	{Name: envScheduleJitter, Usage: "delay the start of each scheduled reap cycle by a random duration up to this bound (example: 30s)"},
+	{Name: envBlackoutSchedule, Usage: "semicolon-separated windows during which cycles reap nothing, as [days] HH:MM-HH:MM [timezone] or <cron schedule> for <duration> (example: Fri 17:00-23:59 America/Chicago)"},
+	{Name: envChaosWindow, Usage: "semicolon-separated windows outside of which cycles reap nothing, in the format of BLACKOUT_SCHEDULE"},
+	{Name: envChaosVerifyTimeout, Usage: "how long the owners disrupted during a chaos window have to return to full readiness after it (default: 10m)"},
+	{Name: envRunDuration, Usage: "how long pod-reaper should run before exiting (default: 0s, indefinitely)"},
+	{Name: envRunOnce, Usage: "run a single reap cycle and exit, for running pod-reaper as a job", Boolean: true},
+	{Name: envShutdownTimeout, Usage: "how long a running reap cycle may take to finish when pod-reaper is stopped (default: 25s)"},
+	{Name: envReaperPolicies, Usage: "read schedules and rules from ReaperPolicy custom resources instead of the environment", Boolean: true},
+	{Name: envReaperPolicySyncInterval, Usage: "how often ReaperPolicy resources are reloaded"},
+	{Name: envReapers, Usage: "comma-separated names of reapers to run side by side, each configured by the environment variables prefixed with REAPER_<NAME>_"},
+	{Name: envProfile, Usage: "preset a group of options for a kind of cluster"},
+	{Name: envDryRun, Usage: "log pod-reaper's actions but don't actually kill any pods", Boolean: true},
+	{Name: envAction, Usage: "how to reap matching pods: delete, evict, annotate, scale-owner, or preview"},
+	{Name: envEvict, Usage: "try to evict pods instead of deleting them", Boolean: true},
+	{Name: envForceDeleteStuck, Usage: "force delete reaped pods that are stuck terminating", Boolean: true},
+	{Name: envGracePeriod, Usage: "duration that pods should be given to shut down before hard killing the pod"},
+	{Name: envGraceEscalationWindow, Usage: "shorten the grace period of pods whose owner was reaped within this window"},
+	{Name: envGracePeriodFloor, Usage: "shortest grace period that the grace escalation window escalates to"},
+	{Name: envMaxPods, Usage: "kill a maximum number of pods on each run"},
+	{Name: envMaxPodsPerNamespace, Usage: "kill a maximum number of pods in each namespace on each run"},
+	{Name: envWorkloadCooldown, Usage: "skip the other pods of an owner for this long after reaping one of its pods (example: 30m)"},
+	{Name: envMaxPodsPercent, Usage: "kill at most this percentage of the pods on each run"},
+	{Name: envMaxPodsPercentOf, Usage: "listed or matched, the pods MAX_PODS_PERCENT is a percentage of (default: listed)"},
+	{Name: envReapInterval, Usage: "minimum time between reaping pods within a run"},
+	{Name: envReapConcurrency, Usage: "number of pods reaped at once (default: 1)"},
+	{Name: envMarkGrace, Usage: "only reap pods that still match the rules this long after they first matched"},
+	{Name: envAPITimeout, Usage: "timeout of each kubernetes API operation (default: 30s)"},
+	{Name: envAPIRetries, Usage: "times a list, delete, or eviction failing with a transient error is retried (default: 3)"},
+	{Name: envAPIRetryBackoff, Usage: "backoff before the first retry of a failed API call, doubling with each retry (default: 500ms)"},
+	{Name: envAPICallBudget, Usage: "maximum number of kubernetes API calls made in each reap cycle"},
+	{Name: envListPageSize, Usage: "number of pods listed per kubernetes API call, 0 to list every pod at once (default: 500)"},
+	{Name: envListConsistency, Usage: "strong to list pods with a quorum read, cached to list them from the API server's watch cache (default: strong)"},
+	{Name: envPodSortingStrategy, Usage: "sorts pods before killing them (most useful with max pods)"},
+	{Name: envRandomSeed, Usage: "seed for the random pod sorting strategy"},
+	{Name: envRespectTopologySpread, Usage: "prefer reaping the pods of an owner that keep its topology spread balanced", Boolean: true},
+	{Name: envExcludeLabelKey, Usage: "pod label key that pod-reaper should exclude"},
+	{Name: envExcludeLabelValues, Usage: "comma-separated list of label values that pod-reaper should exclude"},
+	{Name: envRequireLabelKey, Usage: "pod label key that pod-reaper should require"},
+	{Name: envRequireLabelValues, Usage: "comma-separated list of label values that pod-reaper should require"},
+	{Name: envRequireLabels, Usage: "label selector that pods must match for pod-reaper to reap them (example: tier in (web,api),environment!=production)"},
+	{Name: envExcludeLabels, Usage: "label selector of the pods that pod-reaper should never reap (example: app=database)"},
+	{Name: envRequireAnnotationKey, Usage: "pod annotation key that pod-reaper should require"},
+	{Name: envRequireAnnotationValues, Usage: "comma-separated list of annotation values that pod-reaper should require"},
+	{Name: envAnnotationSelector, Usage: "selector of the pod annotations that pod-reaper should require, in label selector syntax (example: team in (a,b),!do-not-reap)"},
+	{Name: envFieldSelector, Usage: "field selector of the pods to list, applied by the API server (example: status.phase!=Running)"},
+	{Name: envOwnerKinds, Usage: "comma-separated list of owner kinds that pod-reaper should only reap pods of"},
+	{Name: envExcludeOwnerKinds, Usage: "comma-separated list of owner kinds that pod-reaper should never reap pods of"},
+	{Name: envIgnoreDaemonSetPods, Usage: "never reap pods owned by a daemon set, set to false to include them (default: true)", Boolean: true},
+	{Name: envMinPodAge, Usage: "never reap pods created less than this duration ago, whatever rules they match (example: 5m)"},
+	{Name: envNodeName, Usage: "comma-separated list of nodes that pod-reaper should only reap pods on"},
+	{Name: envNodeSelector, Usage: "label selector of the nodes that pod-reaper should only reap pods on"},
+	{Name: envNamespaceRules, Usage: "let each namespace override rules with a pod-reaper-rules config map", Boolean: true},
+	{Name: envNamespaceReports, Usage: "write a pod-reaper-report config map summarizing the latest reaps in each namespace", Boolean: true},
+	{Name: envUseInformer, Usage: "watch pods into a local cache instead of listing them on every cycle", Boolean: true},
+	{Name: envClientQPS, Usage: "maximum sustained rate of kubernetes API requests per second"},
+	{Name: envClientBurst, Usage: "maximum burst of kubernetes API requests"},
+	{Name: envEmitEvents, Usage: "create a kubernetes event on each reaped pod", Boolean: true},
+	{Name: envEmitSkipEvents, Usage: "create a warning event on pods that matched the rules but were not reaped", Boolean: true},
+	{Name: envVerdictAnnotations, Usage: "annotate evaluated pods with pod-reaper's latest verdict", Boolean: true},
+	{Name: envVerdictAnnotationInterval, Usage: "minimum time between verdict annotation updates on a pod"},
+	{Name: envProtectJobBackoff, Usage: "skip job pods whose reap would push their job past its backoffLimit", Boolean: true},
+	{Name: envMinReadyReplicas, Usage: "skip ready pods whose reap would leave their deployment or stateful set with fewer ready replicas than this, or than it keeps available during rolling updates (example: 2)"},
+	{Name: envAnnotateOwners, Usage: "record the last reap time, reap count, and last reason on the workload of each reaped pod", Boolean: true},
+	{Name: envDryRunReport, Usage: "write a report of each dry-run cycle to stdout or a file"},
+	{Name: envDryRunReportFormat, Usage: "write dry-run reports as json or diff"},
+	{Name: envReapWebhookURL, Usage: "POST a JSON notification to an HTTP endpoint for each reaped pod"},
+	{Name: envReapWebhookTimeout, Usage: "timeout for each webhook request"},
+	{Name: envReapWebhookRetries, Usage: "number of times a failed webhook request is retried"},
+	{Name: envReapRecords, Usage: "write a JSON line for each reaped pod to stdout or a file"},
+	{Name: envAuditSink, Usage: "durably record every reap decision to a file://, s3://, or configmap:// sink"},
+	{Name: envAuditFileMaxSize, Usage: "size in bytes at which the audit file is rotated"},
+	{Name: envAuditFileMaxBackups, Usage: "number of rotated audit files kept"},
+	{Name: envAuditConfigMapMaxRecords, Usage: "number of records kept in the audit config map"},
+	{Name: envWarehouseExport, Usage: "export every reap decision to a BigQuery table"},
+	{Name: envWarehouseBatchSize, Usage: "number of decisions exported together"},
+	{Name: envWarehouseFlushInterval, Usage: "maximum time decisions wait for a full batch"},
+	{Name: envSlackWebhookURL, Usage: "post reaped pods to a slack channel"},
+	{Name: envSlackChannel, Usage: "override the slack webhook's default channel"},
+	{Name: envSlackTemplate, Usage: "go template used to describe each reaped pod in slack"},
+	{Name: envSlackSummary, Usage: "post one slack message per reap cycle instead of one per pod", Boolean: true},
+	{Name: envAlertmanagerURL, Usage: "alertmanager to alert when a chaos experiment fails"},
+	{Name: envNotificationDedupeWindow, Usage: "send the notification of a pod matching the same rules at most once within this duration, across restarts"},
+	{Name: envElasticsearchURL, Usage: "index reaped pods and cycle summaries into Elasticsearch or OpenSearch"},
+	{Name: envElasticsearchIndex, Usage: "prefix of the daily indices pod-reaper writes to"},
+	{Name: envElasticsearchUsername, Usage: "basic authentication username for Elasticsearch"},
+	{Name: envElasticsearchPassword + secretFileSuffix, Usage: "file holding the basic authentication password for Elasticsearch"},
+	{Name: envElasticsearchAPIKey + secretFileSuffix, Usage: "file holding the API key for Elasticsearch"},
+	{Name: envMetricsAddress, Usage: "address to serve prometheus metrics on"},
+	{Name: envMetricsToken + secretFileSuffix, Usage: "file holding the bearer token required by the metrics endpoint"},
+	{Name: envHealthAddress, Usage: "address to serve /healthz and /readyz probe endpoints on"},
+	{Name: envLivenessGracePeriod, Usage: "how overdue a scheduled reap cycle may be before /healthz fails"},
+	{Name: envReadinessFailureThreshold, Usage: "number of consecutive failed reap cycles before /readyz fails"},
+	{Name: envAdminAddress, Usage: "address to serve the admin API and slack commands on"},
+	{Name: envAdminToken + secretFileSuffix, Usage: "file holding the bearer token required by the admin API"},
+	{Name: envReapRequestLimit, Usage: "maximum number of pods reaped through /admin/reap per hour"},
+	{Name: envRequireApproval, Usage: "hold reap cycles until they are approved through the admin API or slack", Boolean: true},
+	{Name: envSlackSigningSecret + secretFileSuffix, Usage: "file holding the signing secret of the slack app whose commands pod-reaper accepts"},
+	{Name: envTLSCertFile, Usage: "certificate to serve the admin and metrics addresses over TLS with"},
+	{Name: envTLSKeyFile, Usage: "private key of the TLS certificate"},
+	{Name: envTLSClientCAFile, Usage: "CA that clients of the admin and metrics addresses must present certificates signed by"},
+	{Name: envLeaderElection, Usage: "run multiple replicas with one active reaper and warm standbys", Boolean: true},
+	{Name: envLeaderElectionID, Usage: "name of the lease used for leader election"},
+	{Name: envLeaderElectionNamespace, Usage: "namespace of the lease used for leader election"},
+	{Name: envLeaseDuration, Usage: "how long a standby waits before taking over the lease"},
+	{Name: envLeaseRenewDeadline, Usage: "how long the leader retries renewing the lease before giving it up"},
+	{Name: envLeaseRetryPeriod, Usage: "how often leader election actions are retried"},
+	{Name: envIUnderstandTheRisk, Usage: "start even when the rules would reap every pod in the cluster", Boolean: true},
+	{Name: envLogLevel, Usage: "control verbosity level of log messages"},
+	{Name: envLogFormat, Usage: "choose between several formats of logging"},
+}
+
+// flagName is the flag of an environment variable, for example --max-duration for MAX_DURATION.
+func flagName(env string) string {
+	return strings.ReplaceAll(strings.ToLower(env), "_", "-")
+}
+
+// envFlag sets an environment variable, so that flags and environment variables are read the same way and the flag
+// takes precedence.
+type envFlag struct {
+	env     string
+	boolean bool
+}
+
+func (value envFlag) String() string {
+	return ""
+}
+
+func (value envFlag) Set(flagValue string) error {
+	if strings.HasSuffix(value.env, secretFileSuffix) {
+		// a secret file flag takes precedence over the secret's environment variable
+		os.Unsetenv(strings.TrimSuffix(value.env, secretFileSuffix))
+	}
+	return os.Setenv(value.env, flagValue)
+}
+
+func (value envFlag) IsBoolFlag() bool {
+	return value.boolean
+}
+
+// Command returns the pod-reaper command with the name, such as analyze, which runs with its arguments and writes its
+// results to out instead of reaping pods on the schedule.
+func Command(name string) (func(args []string, out io.Writer) error, bool) {
+	switch name {
+	case analyzeCommand:
+		return runAnalyze, true
+	case simulateCommand:
+		return runSimulate, true
+	case batchCommand:
+		return runBatch, true
+	}
+	return nil, false
+}
+
+// onceFlag runs a single reap cycle, like RUN_ONCE
+const onceFlag = "--once"
+
+// ParseFlags sets the environment variables of the flags in args. Every option and rule has a flag, and --once is
+// short for --run-once. Errors and help are written to output.
+func ParseFlags(args []string, output io.Writer) error {
+	flags := flag.NewFlagSet("pod-reaper", flag.ContinueOnError)
+	flags.SetOutput(output)
+	for _, option := range optionFlags {
+		flags.Var(envFlag{env: option.Name, boolean: option.Boolean}, flagName(option.Name), option.Usage)
+	}
+	for _, option := range rules.Options() {
+		flags.Var(envFlag{env: option.Name, boolean: option.Boolean}, flagName(option.Name), option.Usage)
+	}
+	flags.Var(envFlag{env: envRunOnce, boolean: true}, strings.TrimPrefix(onceFlag, "--"), "short for --"+flagName(envRunOnce))
+	flags.Usage = func() { usage(output) }
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		err := fmt.Errorf("unexpected argument %q", flags.Arg(0))
+		fmt.Fprintln(output, err)
+		flags.Usage()
+		return err
+	}
+	return nil
+}
+
+// usage writes the help of pod-reaper, listing every option and rule with its environment variable.
+func usage(output io.Writer) {
+	fmt.Fprintf(output, `Usage:
+  pod-reaper [flags]
+  pod-reaper %s [-window 1h] [-format text|json] [-cluster=true] [file...]
+  pod-reaper %s [-pods 10000] [-namespaces 10] [-ages ...] [-phases ...] [-statuses ...] [-seed 1] [-format text|json]
+  pod-reaper %s [-reason ...] [-format text|json] [file...]
+
+Every flag can instead be set with the environment variable in parentheses. Flags take precedence over environment
+variables. Secrets are only accepted as files on the command line.
+
+Options:
+`, analyzeCommand, simulateCommand, batchCommand)
+	writer := tabwriter.NewWriter(output, 0, 0, 2, ' ', 0)
+	writeFlags(writer, optionFlags)
+	fmt.Fprintf(writer, "  --%s\tshort for --%s\n", strings.TrimPrefix(onceFlag, "--"), flagName(envRunOnce))
+	writer.Flush()
+	fmt.Fprintln(output, "\nRules (at least one must be enabled, and pods are reaped when every enabled rule matches):")
+	writeFlags(writer, rules.Options())
+	writer.Flush()
+}
+
+func writeFlags(writer io.Writer, options []rules.Option) {
+	for _, option := range options {
+		name := "--" + flagName(option.Name)
+		if !option.Boolean {
+			name += " value"
+		}
+		fmt.Fprintf(writer, "  %s\t%s (%s)\n", name, option.Usage, option.Name)
+	}
+}
@@ -0,0 +1,77 @@
+package reaper
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlagName(t *testing.T) {
+	assert.Equal(t, "max-duration", flagName("MAX_DURATION"))
+	assert.Equal(t, "namespace", flagName(envNamespace))
+}
+
+func TestParseFlags(t *testing.T) {
+	t.Run("sets environment", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envScheduleCron, "@every 1h")
+		os.Setenv(envGracePeriod, "1m")
+		err := ParseFlags([]string{"--namespace", "default", "--schedule=@every 5m", "--dry-run", "--max-duration", "2h"}, &bytes.Buffer{})
+		assert.NoError(t, err)
+		assert.Equal(t, "default", os.Getenv(envNamespace))
+		assert.Equal(t, "@every 5m", os.Getenv(envScheduleCron))
+		assert.Equal(t, "true", os.Getenv(envDryRun))
+		assert.Equal(t, "2h", os.Getenv("MAX_DURATION"))
+		assert.Equal(t, "1m", os.Getenv(envGracePeriod))
+	})
+	t.Run("once", func(t *testing.T) {
+		os.Clearenv()
+		assert.NoError(t, ParseFlags([]string{onceFlag}, &bytes.Buffer{}))
+		assert.Equal(t, "true", os.Getenv(envRunOnce))
+	})
+	t.Run("boolean value", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envDryRun, "true")
+		assert.NoError(t, ParseFlags([]string{"--dry-run=false"}, &bytes.Buffer{}))
+		assert.Equal(t, "false", os.Getenv(envDryRun))
+	})
+	t.Run("secret file", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envAdminToken, "token")
+		assert.NoError(t, ParseFlags([]string{"--admin-token-file", "/etc/pod-reaper/token"}, &bytes.Buffer{}))
+		assert.Equal(t, "/etc/pod-reaper/token", os.Getenv(envAdminToken+secretFileSuffix))
+		_, exists := os.LookupEnv(envAdminToken)
+		assert.False(t, exists)
+	})
+	t.Run("secret", func(t *testing.T) {
+		os.Clearenv()
+		assert.Error(t, ParseFlags([]string{"--admin-token", "token"}, &bytes.Buffer{}))
+	})
+	t.Run("unknown flag", func(t *testing.T) {
+		os.Clearenv()
+		var output bytes.Buffer
+		assert.Error(t, ParseFlags([]string{"--reap-everything"}, &output))
+		assert.Contains(t, output.String(), "flag provided but not defined: -reap-everything")
+	})
+	t.Run("argument", func(t *testing.T) {
+		os.Clearenv()
+		var output bytes.Buffer
+		assert.EqualError(t, ParseFlags([]string{"--dry-run", "default"}, &output), `unexpected argument "default"`)
+		assert.Contains(t, output.String(), "Usage:")
+	})
+	t.Run("help", func(t *testing.T) {
+		var output bytes.Buffer
+		assert.Equal(t, flag.ErrHelp, ParseFlags([]string{"--help"}, &output))
+		help := output.String()
+		assert.Contains(t, help, "--namespace value")
+		assert.Contains(t, help, "(NAMESPACE)")
+		assert.Contains(t, help, "--once")
+		assert.Contains(t, help, "Rules (")
+		assert.Contains(t, help, "--max-duration value")
+		assert.Contains(t, help, "--chaos-chance value")
+		assert.NotContains(t, help, "--admin-token value")
+	})
+}
@@ -0,0 +1,43 @@
+package reaper
+
+import (
+	"context"
+	"encoding/json"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// stuck returns whether FORCE_DELETE_STUCK applies to the pod: it is already terminating, so deleting or evicting it
+// again would not change anything.
+func (reaper reaper) stuck(pod v1.Pod) bool {
+	return reaper.options.forceDeleteStuck && pod.DeletionTimestamp != nil
+}
+
+// forceDelete removes the pod's finalizers and deletes it without a grace period, so the api server removes it right
+// away even if its kubelet never confirms that the containers stopped.
+func (reaper reaper) forceDelete(pod v1.Pod) error {
+	if len(pod.Finalizers) > 0 {
+		if !reaper.apiCall(operationPatch) {
+			return errAPIBudgetExhausted
+		}
+		// a null value removes the finalizers in a merge patch
+		patch, err := json.Marshal(map[string]interface{}{
+			"metadata": map[string]interface{}{"finalizers": nil},
+		})
+		if err != nil {
+			return err
+		}
+		ctx, cancel := reaper.apiContext()
+		defer cancel()
+		_, err = reaper.clientSet.CoreV1().Pods(pod.Namespace).Patch(ctx, pod.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+		if err != nil {
+			return err
+		}
+	}
+	gracePeriod := int64(0)
+	return reaper.retryAPICall(operationDelete, func(ctx context.Context) error {
+		return reaper.clientSet.CoreV1().Pods(pod.Namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{GracePeriodSeconds: &gracePeriod})
+	})
+}
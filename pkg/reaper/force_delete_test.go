@@ -0,0 +1,63 @@
+package reaper
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestForceDeleteStuck(t *testing.T) {
+	stuckPod := createTestPod("stuck", "default", nil)
+	deletion := metav1.NewTime(time.Now().Add(-time.Hour))
+	stuckPod.DeletionTimestamp = &deletion
+	stuckPod.Finalizers = []string{"example.com/never-cleared"}
+
+	t.Run("force deletes", func(t *testing.T) {
+		opts := minimalOptions("1.0")
+		opts.action = actionEvict
+		opts.forceDeleteStuck = true
+		client := fake.NewSimpleClientset(&stuckPod)
+		var gracePeriod *int64
+		client.PrependReactor("delete", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			gracePeriod = action.(k8stesting.DeleteAction).GetDeleteOptions().GracePeriodSeconds
+			return false, nil, nil
+		})
+		r := reaper{clientSet: client, options: opts}
+
+		assert.True(t, r.reapPod(stuckPod, []string{"reason"}, 0))
+
+		if assert.NotNil(t, gracePeriod) {
+			assert.Equal(t, int64(0), *gracePeriod)
+		}
+		assert.Equal(t, "patch", client.Actions()[0].GetVerb())
+		_, err := client.CoreV1().Pods("default").Get(context.TODO(), "stuck", metav1.GetOptions{})
+		assert.Error(t, err)
+	})
+	t.Run("without finalizers", func(t *testing.T) {
+		pod := stuckPod
+		pod.Finalizers = nil
+		opts := minimalOptions("1.0")
+		opts.forceDeleteStuck = true
+		r := createTestReaper(opts, pod)
+
+		assert.True(t, r.reapPod(pod, []string{"reason"}, 0))
+
+		assert.Len(t, r.clientSet.(*fake.Clientset).Actions(), 1)
+	})
+	t.Run("disabled", func(t *testing.T) {
+		r := createTestReaper(minimalOptions("1.0"), stuckPod)
+		assert.False(t, r.stuck(stuckPod))
+	})
+	t.Run("not terminating", func(t *testing.T) {
+		opts := minimalOptions("1.0")
+		opts.forceDeleteStuck = true
+		r := createTestReaper(opts)
+		assert.False(t, r.stuck(createTestPod("pod", "default", nil)))
+	})
+}
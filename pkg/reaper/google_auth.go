@@ -0,0 +1,200 @@
+package reaper
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// envGoogleApplicationCredentials names a service account key file, matching the name used by the google tooling
+const envGoogleApplicationCredentials = "GOOGLE_APPLICATION_CREDENTIALS"
+
+const googleMetadataTokenURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+const bigQueryScope = "https://www.googleapis.com/auth/bigquery.insertdata"
+
+// tokens are refreshed a minute before they expire, so that a token does not expire during a request
+const tokenExpiryMargin = time.Minute
+
+// tokenSource provides oauth access tokens for google APIs.
+type tokenSource interface {
+	token() (string, error)
+}
+
+// cachedToken holds the latest access token and when it expires.
+type cachedToken struct {
+	mutex   sync.Mutex
+	value   string
+	expires time.Time
+}
+
+// get returns the cached token, refreshing it when it is about to expire.
+func (cached *cachedToken) get(refresh func() (string, time.Duration, error)) (string, error) {
+	cached.mutex.Lock()
+	defer cached.mutex.Unlock()
+	if cached.value != "" && time.Now().Add(tokenExpiryMargin).Before(cached.expires) {
+		return cached.value, nil
+	}
+	value, expiresIn, err := refresh()
+	if err != nil {
+		return "", err
+	}
+	cached.value = value
+	cached.expires = time.Now().Add(expiresIn)
+	return value, nil
+}
+
+// tokenResponse is the response of both the metadata server and the oauth token endpoint.
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+func fetchToken(client *http.Client, request *http.Request) (string, time.Duration, error) {
+	response, err := client.Do(request)
+	if err != nil {
+		return "", 0, err
+	}
+	defer response.Body.Close()
+	content, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return "", 0, err
+	}
+	if response.StatusCode != http.StatusOK {
+		return "", 0, fmt.Errorf("unexpected response status %s", response.Status)
+	}
+	var token tokenResponse
+	if err := json.Unmarshal(content, &token); err != nil {
+		return "", 0, err
+	}
+	if token.AccessToken == "" {
+		return "", 0, errors.New("response has no access token")
+	}
+	return token.AccessToken, time.Duration(token.ExpiresIn) * time.Second, nil
+}
+
+// metadataTokenSource gets tokens for the service account of the node, or of the kubernetes service account through
+// workload identity, from the metadata server.
+type metadataTokenSource struct {
+	url    string
+	client *http.Client
+	cached cachedToken
+}
+
+func (source *metadataTokenSource) token() (string, error) {
+	return source.cached.get(func() (string, time.Duration, error) {
+		request, err := http.NewRequest(http.MethodGet, source.url, nil)
+		if err != nil {
+			return "", 0, err
+		}
+		request.Header.Set("Metadata-Flavor", "Google")
+		return fetchToken(source.client, request)
+	})
+}
+
+// serviceAccountTokenSource exchanges a JWT signed with a service account key for tokens.
+type serviceAccountTokenSource struct {
+	email    string
+	key      *rsa.PrivateKey
+	keyID    string
+	tokenURL string
+	scope    string
+	client   *http.Client
+	cached   cachedToken
+}
+
+// newServiceAccountTokenSource reads a service account key file as downloaded from the google cloud console.
+func newServiceAccountTokenSource(path string, scope string, client *http.Client) (*serviceAccountTokenSource, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Type         string `json:"type"`
+		ClientEmail  string `json:"client_email"`
+		PrivateKey   string `json:"private_key"`
+		PrivateKeyID string `json:"private_key_id"`
+		TokenURI     string `json:"token_uri"`
+	}
+	if err := json.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("invalid service account key file: %s", err)
+	}
+	if file.Type != "service_account" || file.ClientEmail == "" || file.TokenURI == "" {
+		return nil, errors.New("invalid service account key file: not a service account key")
+	}
+	block, _ := pem.Decode([]byte(file.PrivateKey))
+	if block == nil {
+		return nil, errors.New("invalid service account key file: private key is not PEM encoded")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid service account key file: %s", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("invalid service account key file: private key is not an RSA key")
+	}
+	return &serviceAccountTokenSource{
+		email:    file.ClientEmail,
+		key:      key,
+		keyID:    file.PrivateKeyID,
+		tokenURL: file.TokenURI,
+		scope:    scope,
+		client:   client,
+	}, nil
+}
+
+func (source *serviceAccountTokenSource) token() (string, error) {
+	return source.cached.get(func() (string, time.Duration, error) {
+		assertion, err := source.assertion(time.Now())
+		if err != nil {
+			return "", 0, err
+		}
+		form := url.Values{
+			"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+			"assertion":  {assertion},
+		}
+		request, err := http.NewRequest(http.MethodPost, source.tokenURL, strings.NewReader(form.Encode()))
+		if err != nil {
+			return "", 0, err
+		}
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return fetchToken(source.client, request)
+	})
+}
+
+// assertion creates the JWT, signed with RS256, that is exchanged for a token.
+func (source *serviceAccountTokenSource) assertion(now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": source.keyID})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"iss":   source.email,
+		"scope": source.scope,
+		"aud":   source.tokenURL,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	hash := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(nil, source.key, crypto.SHA256, hash[:])
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
@@ -0,0 +1,105 @@
+package reaper
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetadataTokenSource(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		requests++
+		assert.Equal(t, "Google", request.Header.Get("Metadata-Flavor"))
+		fmt.Fprintf(writer, `{"access_token":"token-%d","expires_in":3600,"token_type":"Bearer"}`, requests)
+	}))
+	defer server.Close()
+	source := &metadataTokenSource{url: server.URL, client: server.Client()}
+
+	token, err := source.token()
+	assert.NoError(t, err)
+	assert.Equal(t, "token-1", token)
+	token, err = source.token()
+	assert.NoError(t, err)
+	assert.Equal(t, "token-1", token)
+	assert.Equal(t, 1, requests)
+
+	// an expiring token is refreshed
+	source.cached.expires = time.Now().Add(30 * time.Second)
+	token, err = source.token()
+	assert.NoError(t, err)
+	assert.Equal(t, "token-2", token)
+}
+
+func testServiceAccountKey(t *testing.T, tokenURI string) (string, *rsa.PrivateKey) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.NoError(t, err)
+	content, err := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"client_email":   "reaper@project.iam.gserviceaccount.com",
+		"private_key_id": "key-id",
+		"private_key":    string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"token_uri":      tokenURI,
+	})
+	assert.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "key.json")
+	assert.NoError(t, os.WriteFile(path, content, 0600))
+	return path, key
+}
+
+func TestServiceAccountTokenSource(t *testing.T) {
+	var key *rsa.PrivateKey
+	var tokenURL string
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		assert.NoError(t, request.ParseForm())
+		assert.Equal(t, "urn:ietf:params:oauth:grant-type:jwt-bearer", request.Form.Get("grant_type"))
+		parts := strings.Split(request.Form.Get("assertion"), ".")
+		assert.Len(t, parts, 3)
+		signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+		assert.NoError(t, err)
+		hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		assert.NoError(t, rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, hash[:], signature))
+		claims, err := base64.RawURLEncoding.DecodeString(parts[1])
+		assert.NoError(t, err)
+		var decoded map[string]interface{}
+		assert.NoError(t, json.Unmarshal(claims, &decoded))
+		assert.Equal(t, "reaper@project.iam.gserviceaccount.com", decoded["iss"])
+		assert.Equal(t, bigQueryScope, decoded["scope"])
+		assert.Equal(t, tokenURL, decoded["aud"])
+		fmt.Fprint(writer, `{"access_token":"token","expires_in":3600}`)
+	}))
+	defer server.Close()
+	tokenURL = server.URL + "/token"
+	var path string
+	path, key = testServiceAccountKey(t, tokenURL)
+
+	source, err := newServiceAccountTokenSource(path, bigQueryScope, server.Client())
+	assert.NoError(t, err)
+	token, err := source.token()
+	assert.NoError(t, err)
+	assert.Equal(t, "token", token)
+
+	t.Run("invalid key file", func(t *testing.T) {
+		invalid := filepath.Join(t.TempDir(), "key.json")
+		assert.NoError(t, os.WriteFile(invalid, []byte(`{"type":"authorized_user"}`), 0600))
+		_, err := newServiceAccountTokenSource(invalid, bigQueryScope, server.Client())
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,102 @@
+package reaper
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// health tracks reap cycles to answer liveness and readiness probes. A nil health is always live and ready.
+type health struct {
+	mutex    sync.Mutex
+	schedule cron.Schedule
+	// gracePeriod is how overdue a scheduled cycle may be before the reaper is considered wedged
+	gracePeriod time.Duration
+	// failureThreshold is the number of consecutive failed cycles after which the reaper is not ready
+	failureThreshold    int
+	lastStarted         time.Time
+	consecutiveFailures int
+	lastError           string
+}
+
+func newHealth(schedule cron.Schedule, gracePeriod time.Duration, failureThreshold int, now time.Time) *health {
+	return &health{
+		schedule:         schedule,
+		gracePeriod:      gracePeriod,
+		failureThreshold: failureThreshold,
+		lastStarted:      now,
+	}
+}
+
+func (health *health) cycleStarted(now time.Time) {
+	if health == nil {
+		return
+	}
+	health.mutex.Lock()
+	defer health.mutex.Unlock()
+	health.lastStarted = now
+}
+
+// cycleFinished records the result of a cycle, where a nil error is a successful cycle.
+func (health *health) cycleFinished(err error) {
+	if health == nil {
+		return
+	}
+	health.mutex.Lock()
+	defer health.mutex.Unlock()
+	if err == nil {
+		health.consecutiveFailures = 0
+		health.lastError = ""
+		return
+	}
+	health.consecutiveFailures++
+	health.lastError = err.Error()
+}
+
+// live returns whether the scheduler is still starting cycles, with a message describing the state.
+func (health *health) live(now time.Time) (bool, string) {
+	if health == nil {
+		return true, "ok"
+	}
+	health.mutex.Lock()
+	defer health.mutex.Unlock()
+	due := health.schedule.Next(health.lastStarted)
+	if now.After(due.Add(health.gracePeriod)) {
+		return false, fmt.Sprintf("reap cycle due at %s has not started", due.Format(time.RFC3339))
+	}
+	return true, "ok"
+}
+
+// ready returns whether fewer than the threshold of consecutive cycles failed, with a message describing the state.
+func (health *health) ready() (bool, string) {
+	if health == nil {
+		return true, "ok"
+	}
+	health.mutex.Lock()
+	defer health.mutex.Unlock()
+	if health.consecutiveFailures >= health.failureThreshold {
+		return false, fmt.Sprintf("%d consecutive reap cycles failed, last error: %s", health.consecutiveFailures, health.lastError)
+	}
+	return true, "ok"
+}
+
+func (health *health) handleLive(w http.ResponseWriter, r *http.Request) {
+	live, message := health.live(time.Now())
+	writeProbe(w, live, message)
+}
+
+func (health *health) handleReady(w http.ResponseWriter, r *http.Request) {
+	ready, message := health.ready()
+	writeProbe(w, ready, message)
+}
+
+func writeProbe(w http.ResponseWriter, ok bool, message string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if !ok {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	fmt.Fprintln(w, message)
+}
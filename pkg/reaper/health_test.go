@@ -0,0 +1,144 @@
+package reaper
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func testHealth(t *testing.T, now time.Time) *health {
+	schedule, err := scheduleParser.Parse("@every 1m")
+	assert.NoError(t, err)
+	return newHealth(schedule, time.Minute, 2, now)
+}
+
+func TestHealthLive(t *testing.T) {
+	now := time.Now()
+	t.Run("nil", func(t *testing.T) {
+		var health *health
+		live, _ := health.live(now)
+		assert.True(t, live)
+	})
+	t.Run("cycle on schedule", func(t *testing.T) {
+		health := testHealth(t, now)
+		live, _ := health.live(now.Add(90 * time.Second))
+		assert.True(t, live)
+	})
+	t.Run("cycle overdue", func(t *testing.T) {
+		health := testHealth(t, now)
+		live, message := health.live(now.Add(3 * time.Minute))
+		assert.False(t, live)
+		assert.Contains(t, message, "has not started")
+		health.cycleStarted(now.Add(3 * time.Minute))
+		live, _ = health.live(now.Add(3 * time.Minute))
+		assert.True(t, live)
+	})
+}
+
+func TestHealthReady(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		var health *health
+		ready, _ := health.ready()
+		assert.True(t, ready)
+	})
+	t.Run("failures", func(t *testing.T) {
+		health := testHealth(t, time.Now())
+		health.cycleFinished(errors.New("api unreachable"))
+		ready, _ := health.ready()
+		assert.True(t, ready)
+		health.cycleFinished(errors.New("api unreachable"))
+		ready, message := health.ready()
+		assert.False(t, ready)
+		assert.Equal(t, "2 consecutive reap cycles failed, last error: api unreachable", message)
+		health.cycleFinished(nil)
+		ready, _ = health.ready()
+		assert.True(t, ready)
+	})
+}
+
+func TestHealthHandlers(t *testing.T) {
+	health := testHealth(t, time.Now())
+	recorder := httptest.NewRecorder()
+	health.handleReady(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "ok\n", recorder.Body.String())
+
+	health.lastStarted = time.Now().Add(-time.Hour)
+	recorder = httptest.NewRecorder()
+	health.handleLive(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+}
+
+func TestRunCycle(t *testing.T) {
+	t.Run("records failures", func(t *testing.T) {
+		r := createTestReaper(minimalOptions("1.0"))
+		r.health = testHealth(t, time.Now())
+		r.clientSet.(*fake.Clientset).PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, errors.New("api unreachable")
+		})
+		assert.NotPanics(t, func() { r.runCycle(time.Now()) })
+		assert.NotPanics(t, func() { r.runCycle(time.Now()) })
+		ready, message := r.health.ready()
+		assert.False(t, ready)
+		assert.Contains(t, message, "list in default: api unreachable")
+	})
+	t.Run("without health", func(t *testing.T) {
+		r := createTestReaper(minimalOptions("1.0"))
+		r.clientSet.(*fake.Clientset).PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, errors.New("api unreachable")
+		})
+		assert.NotPanics(t, func() { r.runCycle(time.Now()) })
+	})
+	t.Run("skipped while the previous cycle runs", func(t *testing.T) {
+		startTime := time.Now()
+		r := createTestReaper(minimalOptions("1.0"), createTestPod("pod-1", "default", &startTime))
+		r.cycles = newCycleLock()
+		r.cycles.lock()
+		r.runCycle(time.Now())
+		pods, _ := r.clientSet.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+		assert.Len(t, pods.Items, 1)
+
+		r.cycles.unlock()
+		r.runCycle(time.Now())
+		pods, _ = r.clientSet.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+		assert.Empty(t, pods.Items)
+	})
+}
+
+func TestScheduledCycleLiveness(t *testing.T) {
+	t.Run("standby", func(t *testing.T) {
+		startTime := time.Now().Add(-time.Hour)
+		r := createTestReaper(minimalOptions("1.0"), createTestPod("pod-1", "default", &startTime))
+		r.health = testHealth(t, time.Now().Add(-time.Hour))
+		r.leader = testLeader(t, r)
+		r.leader.leading = 0
+		live, _ := r.health.live(time.Now())
+		assert.False(t, live)
+
+		r.scheduledCycle()
+
+		live, message := r.health.live(time.Now())
+		assert.True(t, live, message)
+		pods, err := r.clientSet.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(pods.Items))
+	})
+	t.Run("leading", func(t *testing.T) {
+		r := createTestReaper(minimalOptions("0.0"))
+		r.health = testHealth(t, time.Now().Add(-time.Hour))
+
+		r.scheduledCycle()
+
+		live, message := r.health.live(time.Now())
+		assert.True(t, live, message)
+	})
+}
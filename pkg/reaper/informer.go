@@ -0,0 +1,55 @@
+package reaper
+
+import (
+	"errors"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// startPodInformers starts a pod informer for each listed namespace and waits for the caches to sync. Once started,
+// getPods reads from the caches rather than listing pods from the API server on every cycle.
+func (reaper *reaper) startPodInformers(stop <-chan struct{}) error {
+	listOptions := reaper.listOptions()
+	var listers []corelisters.PodLister
+	var synced []cache.InformerSynced
+	for _, namespace := range reaper.listNamespaces() {
+		factory := informers.NewSharedInformerFactoryWithOptions(reaper.clientSet, 0,
+			informers.WithNamespace(namespace),
+			informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+				options.LabelSelector = listOptions.LabelSelector
+				options.FieldSelector = listOptions.FieldSelector
+			}))
+		podInformer := factory.Core().V1().Pods()
+		listers = append(listers, podInformer.Lister())
+		synced = append(synced, podInformer.Informer().HasSynced)
+		factory.Start(stop)
+	}
+	logrus.Info("waiting for pod informer caches to sync")
+	if !cache.WaitForCacheSync(stop, synced...) {
+		return errors.New("pod informer caches did not sync")
+	}
+	reaper.podListers = listers
+	return nil
+}
+
+// getCachedPods returns copies of the pods in the informer caches, so callers are free to reorder or modify them.
+func (reaper reaper) getCachedPods() []v1.Pod {
+	var pods []v1.Pod
+	for _, lister := range reaper.podListers {
+		// the label selector was already applied by the informer's list options
+		cached, err := lister.List(labels.Everything())
+		if err != nil {
+			logrus.WithError(err).Panic("unable to get pods from the informer cache")
+		}
+		for _, pod := range cached {
+			pods = append(pods, *pod.DeepCopy())
+		}
+	}
+	return pods
+}
@@ -0,0 +1,109 @@
+package reaper
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+func TestPodInformers(t *testing.T) {
+	t.Run("get pods from cache", func(t *testing.T) {
+		startTime := time.Now()
+		opts := minimalOptions("0.0")
+		opts.namespaces = []string{"default", "team-a"}
+		r := createTestReaper(opts,
+			createTestPod("pod-1", "default", &startTime),
+			createTestPod("pod-2", "kube-system", &startTime),
+			createTestPod("pod-3", "team-a", &startTime))
+		stop := make(chan struct{})
+		defer close(stop)
+
+		assert.NoError(t, r.startPodInformers(stop))
+		assert.Equal(t, 2, len(r.podListers))
+
+		podList, err := r.getPods()
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(podList.Items))
+		for _, pod := range podList.Items {
+			assert.NotEqual(t, "kube-system", pod.Namespace)
+		}
+	})
+
+	t.Run("label selector applied", func(t *testing.T) {
+		startTime := time.Now()
+		matchingPod := createTestPod("matching-pod", "default", &startTime)
+		matchingPod.Labels = map[string]string{"app": "target"}
+		nonMatchingPod := createTestPod("non-matching-pod", "default", &startTime)
+		nonMatchingPod.Labels = map[string]string{"app": "other"}
+		opts := minimalOptions("0.0")
+		opts.labelRequirement, _ = labels.NewRequirement("app", selection.In, []string{"target"})
+		r := createTestReaper(opts, matchingPod, nonMatchingPod)
+		stop := make(chan struct{})
+		defer close(stop)
+
+		assert.NoError(t, r.startPodInformers(stop))
+
+		podList, err := r.getPods()
+		assert.NoError(t, err)
+		if assert.Equal(t, 1, len(podList.Items)) {
+			assert.Equal(t, "matching-pod", podList.Items[0].Name)
+		}
+	})
+
+	t.Run("cache follows changes", func(t *testing.T) {
+		startTime := time.Now()
+		r := createTestReaper(minimalOptions("0.0"), createTestPod("pod-1", "default", &startTime))
+		stop := make(chan struct{})
+		defer close(stop)
+		assert.NoError(t, r.startPodInformers(stop))
+
+		added := createTestPod("pod-2", "default", &startTime)
+		_, err := r.clientSet.CoreV1().Pods("default").Create(context.TODO(), &added, metav1.CreateOptions{})
+		assert.NoError(t, err)
+
+		assert.Eventually(t, func() bool {
+			pods, err := r.getPods()
+			return err == nil && len(pods.Items) == 2
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("cached pods are copies", func(t *testing.T) {
+		startTime := time.Now()
+		r := createTestReaper(minimalOptions("0.0"), createTestPod("pod-1", "default", &startTime))
+		stop := make(chan struct{})
+		defer close(stop)
+		assert.NoError(t, r.startPodInformers(stop))
+
+		pods := r.getCachedPods()
+		pods[0].Labels = map[string]string{"modified": "true"}
+
+		assert.Nil(t, r.getCachedPods()[0].Labels)
+	})
+
+	t.Run("sync aborted", func(t *testing.T) {
+		r := createTestReaper(minimalOptions("0.0"))
+		stop := make(chan struct{})
+		close(stop)
+		assert.Error(t, r.startPodInformers(stop))
+		assert.Nil(t, r.podListers)
+	})
+}
+
+func TestScytheCycleInformer(t *testing.T) {
+	startTime := time.Now()
+	pod := createTestPod("pod-1", "default", &startTime)
+	r := createTestReaper(minimalOptions("1.0"), pod)
+	stop := make(chan struct{})
+	defer close(stop)
+	assert.NoError(t, r.startPodInformers(stop))
+
+	r.scytheCycle()
+
+	_, err := r.clientSet.CoreV1().Pods("default").Get(context.TODO(), "pod-1", metav1.GetOptions{})
+	assert.Error(t, err)
+}
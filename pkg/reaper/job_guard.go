@@ -0,0 +1,83 @@
+package reaper
+
+import (
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// the job controller retries failed pods up to 6 times when a job does not set a backoffLimit
+const defaultJobBackoffLimit = 6
+
+// jobGuard keeps PROTECT_JOB_BACKOFF from failing jobs: the job controller counts a running pod that is deleted as a
+// failure, and fails the job once its failures exceed the backoffLimit. The guard counts the pods of each job reaped
+// in the cycle in progress, since the job's status only counts them once the job controller catches up. A nil
+// jobGuard protects no jobs.
+type jobGuard struct {
+	mutex sync.Mutex
+	// reaped counts the pods of each job reaped in the cycle, by job uid
+	reaped map[types.UID]int
+}
+
+func newJobGuard(enabled bool) *jobGuard {
+	if !enabled {
+		return nil
+	}
+	return &jobGuard{reaped: map[types.UID]int{}}
+}
+
+// reapable returns whether the pod can be reaped without pushing the job controlling it past its backoffLimit, and
+// counts it against the job if so. Only running and pending pods count as failures when deleted, so finished pods
+// and pods that are not controlled by a job are always reapable.
+func (reaper reaper) reapable(pod v1.Pod, podLog *logrus.Entry) (bool, error) {
+	if reaper.jobs == nil || pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+		return true, nil
+	}
+	owner := metav1.GetControllerOf(&pod)
+	if owner == nil || owner.Kind != "Job" {
+		return true, nil
+	}
+	if !reaper.apiCall(operationGet) {
+		return false, errAPIBudgetExhausted
+	}
+	ctx, cancel := reaper.apiContext()
+	defer cancel()
+	job, err := reaper.clientSet.BatchV1().Jobs(pod.Namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+	if err != nil {
+		return false, err
+	}
+	return reaper.jobs.take(job, podLog), nil
+}
+
+func (guard *jobGuard) take(job *batchv1.Job, podLog *logrus.Entry) bool {
+	backoffLimit := int32(defaultJobBackoffLimit)
+	if job.Spec.BackoffLimit != nil {
+		backoffLimit = *job.Spec.BackoffLimit
+	}
+	guard.mutex.Lock()
+	defer guard.mutex.Unlock()
+	failures := int(job.Status.Failed) + guard.reaped[job.UID] + 1
+	fields := logrus.Fields{
+		"job":          job.Name,
+		"failed":       job.Status.Failed,
+		"backoffLimit": backoffLimit,
+		"active":       job.Status.Active,
+		"succeeded":    job.Status.Succeeded,
+	}
+	if job.Spec.Completions != nil {
+		fields["completions"] = *job.Spec.Completions
+	}
+	if job.Spec.Parallelism != nil {
+		fields["parallelism"] = *job.Spec.Parallelism
+	}
+	if failures > int(backoffLimit) {
+		podLog.WithFields(fields).Debug("reaping the pod would exceed the backoffLimit of its job")
+		return false
+	}
+	guard.reaped[job.UID]++
+	return true
+}
@@ -0,0 +1,84 @@
+package reaper
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func testJobPod(name string, job string) v1.Pod {
+	controller := true
+	pod := createTestPod(name, "default", nil)
+	pod.OwnerReferences = []metav1.OwnerReference{{Kind: "Job", Name: job, Controller: &controller}}
+	pod.Status.Phase = v1.PodRunning
+	return pod
+}
+
+func testJob(name string, backoffLimit *int32, failed int32) *batchv1.Job {
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID("uid-" + name)},
+		Spec:       batchv1.JobSpec{BackoffLimit: backoffLimit},
+		Status:     batchv1.JobStatus{Failed: failed, Active: 3},
+	}
+}
+
+func TestJobGuard(t *testing.T) {
+	two := int32(2)
+	zero := int32(0)
+	newReaper := func(protect bool, pods ...v1.Pod) reaper {
+		opts := minimalOptions("1.0")
+		opts.protectJobBackoff = protect
+		r := createTestReaper(opts, pods...)
+		r.clientSet.BatchV1().Jobs("default").Create(context.TODO(), testJob("almost-failed", &two, 1), metav1.CreateOptions{})
+		r.clientSet.BatchV1().Jobs("default").Create(context.TODO(), testJob("strict", &zero, 0), metav1.CreateOptions{})
+		r.clientSet.BatchV1().Jobs("default").Create(context.TODO(), testJob("default-limit", nil, 5), metav1.CreateOptions{})
+		return r
+	}
+	remaining := func(r reaper) []string {
+		pods, _ := r.clientSet.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+		var names []string
+		for _, pod := range pods.Items {
+			names = append(names, pod.Name)
+		}
+		return names
+	}
+
+	t.Run("pods within the backoff limit are reaped once per failure left", func(t *testing.T) {
+		r := newReaper(true, testJobPod("almost-1", "almost-failed"), testJobPod("almost-2", "almost-failed"),
+			testJobPod("default-1", "default-limit"), testJobPod("default-2", "default-limit"))
+		r.scytheCycle()
+		assert.Len(t, remaining(r), 2)
+	})
+	t.Run("backoff limit of zero", func(t *testing.T) {
+		r := newReaper(true, testJobPod("strict-1", "strict"))
+		r.scytheCycle()
+		assert.Equal(t, []string{"strict-1"}, remaining(r))
+	})
+	t.Run("finished pods are reaped", func(t *testing.T) {
+		pod := testJobPod("strict-1", "strict")
+		pod.Status.Phase = v1.PodSucceeded
+		r := newReaper(true, pod)
+		r.scytheCycle()
+		assert.Empty(t, remaining(r))
+	})
+	t.Run("missing job skips the pod", func(t *testing.T) {
+		r := newReaper(true, testJobPod("orphan-1", "gone"))
+		r.scytheCycle()
+		assert.Equal(t, []string{"orphan-1"}, remaining(r))
+	})
+	t.Run("other owners", func(t *testing.T) {
+		r := newReaper(true, testOwnedPod("web-1", "web"), createTestPod("bare", "default", nil))
+		r.scytheCycle()
+		assert.Empty(t, remaining(r))
+	})
+	t.Run("disabled", func(t *testing.T) {
+		r := newReaper(false, testJobPod("strict-1", "strict"))
+		r.scytheCycle()
+		assert.Empty(t, remaining(r))
+	})
+}
@@ -0,0 +1,128 @@
+package reaper
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cycleResult is the structured result of a reap cycle, served at /last-cycle once the cycle completes.
+type cycleResult struct {
+	CycleID   string        `json:"cycleId"`
+	Started   time.Time     `json:"started"`
+	Finished  time.Time     `json:"finished"`
+	DryRun    bool          `json:"dryRun"`
+	Evaluated int           `json:"evaluated"`
+	Matched   int           `json:"matched"`
+	Reaped    []auditRecord `json:"reaped"`
+	Skipped   []auditRecord `json:"skipped"`
+	Failed    []auditRecord `json:"failed"`
+	// Errors describes every failure of the cycle, including why it ended early
+	Errors cycleErrors `json:"errors"`
+
+	mutex sync.Mutex
+}
+
+func newCycleResult(cycleID string, started time.Time, dryRun bool) *cycleResult {
+	return &cycleResult{
+		CycleID: cycleID,
+		Started: started.UTC(),
+		DryRun:  dryRun,
+		Reaped:  []auditRecord{},
+		Skipped: []auditRecord{},
+		Failed:  []auditRecord{},
+		Errors:  cycleErrors{},
+	}
+}
+
+// add records the decision for a pod matched by the rules. A nil cycleResult discards decisions.
+func (result *cycleResult) add(record auditRecord) {
+	if result == nil {
+		return
+	}
+	result.mutex.Lock()
+	defer result.mutex.Unlock()
+	switch record.Decision {
+	case auditReaped:
+		result.Reaped = append(result.Reaped, record)
+	case auditSkipped:
+		result.Skipped = append(result.Skipped, record)
+	case auditFailed:
+		result.Failed = append(result.Failed, record)
+		result.Errors = append(result.Errors, cycleError{
+			Operation: record.Action,
+			Namespace: record.Namespace,
+			Pod:       record.Pod,
+			Message:   record.SkipReason,
+		})
+	}
+}
+
+// addError records a failure of the cycle. A nil cycleResult discards failures.
+func (result *cycleResult) addError(err cycleError) {
+	if result == nil {
+		return
+	}
+	result.mutex.Lock()
+	defer result.mutex.Unlock()
+	result.Errors = append(result.Errors, err)
+}
+
+// err returns the failures of the cycle aggregated into a single error, or nil if the cycle had none.
+func (result *cycleResult) err() error {
+	if result == nil {
+		return nil
+	}
+	result.mutex.Lock()
+	defer result.mutex.Unlock()
+	if len(result.Errors) == 0 {
+		return nil
+	}
+	return append(cycleErrors{}, result.Errors...)
+}
+
+// lastCycle holds the result of the latest completed reap cycle. A nil lastCycle keeps no results.
+type lastCycle struct {
+	mutex  sync.Mutex
+	result *cycleResult
+}
+
+func (last *lastCycle) finish(result *cycleResult, finished time.Time) {
+	if last == nil || result == nil {
+		return
+	}
+	result.mutex.Lock()
+	result.Finished = finished.UTC()
+	result.mutex.Unlock()
+	last.mutex.Lock()
+	defer last.mutex.Unlock()
+	last.result = result
+}
+
+func (last *lastCycle) handle(w http.ResponseWriter, r *http.Request) {
+	last.mutex.Lock()
+	result := last.result
+	last.mutex.Unlock()
+	if result == nil {
+		http.Error(w, "no reap cycle has completed yet", http.StatusNotFound)
+		return
+	}
+	result.mutex.Lock()
+	defer result.mutex.Unlock()
+	writeJSON(w, result)
+}
+
+// requireToken rejects requests that do not present the token as a bearer token.
+func requireToken(token string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		presented, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(presented), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		handler(w, r)
+	}
+}
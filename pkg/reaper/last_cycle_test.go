@@ -0,0 +1,95 @@
+package reaper
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestScytheCycleRecordsLastCycle(t *testing.T) {
+	opts := minimalOptions("1.0")
+	opts.maxPods = 1
+	r := createTestReaper(opts,
+		createTestPod("pod-1", "default", nil),
+		createTestPod("pod-2", "default", nil),
+		createTestPod("pod-3", "default", nil))
+	r.lastCycle = &lastCycle{}
+	calls := 0
+	r.clientSet.(*fake.Clientset).PrependReactor("delete", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		calls++
+		if calls == 1 {
+			return true, nil, errors.New("connection refused")
+		}
+		return false, nil, nil
+	})
+
+	r.scytheCycle()
+
+	result := r.lastCycle.result
+	if assert.NotNil(t, result) {
+		assert.NotEmpty(t, result.CycleID)
+		assert.False(t, result.Finished.Before(result.Started))
+		assert.Equal(t, 3, result.Evaluated)
+		assert.Equal(t, 3, result.Matched)
+		assert.Len(t, result.Failed, 1)
+		assert.Len(t, result.Reaped, 0)
+		assert.Len(t, result.Skipped, 2)
+		assert.Equal(t, cycleErrors{{Operation: "delete", Namespace: "default", Pod: "pod-1", Message: "connection refused"}}, result.Errors)
+	}
+}
+
+func TestLastCycleHandler(t *testing.T) {
+	last := &lastCycle{}
+	handler := requireToken("secret", last.handle)
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		handler(recorder, httptest.NewRequest(http.MethodGet, "/last-cycle", nil))
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	})
+	t.Run("wrong token", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, "/last-cycle", nil)
+		request.Header.Set("Authorization", "Bearer guess")
+		handler(recorder, request)
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	})
+	t.Run("token without bearer scheme", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, "/last-cycle", nil)
+		request.Header.Set("Authorization", "secret")
+		handler(recorder, request)
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	})
+	t.Run("no completed cycle", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, "/last-cycle", nil)
+		request.Header.Set("Authorization", "Bearer secret")
+		handler(recorder, request)
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+	t.Run("completed cycle", func(t *testing.T) {
+		result := newCycleResult("abc", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), true)
+		result.add(auditRecord{Pod: "pod-1", Namespace: "default", Decision: auditSkipped, SkipReason: "pod-reaper is in dry-run mode"})
+		last.finish(result, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, "/last-cycle", nil)
+		request.Header.Set("Authorization", "Bearer secret")
+		handler(recorder, request)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		var body map[string]interface{}
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+		assert.Equal(t, "abc", body["cycleId"])
+		assert.Equal(t, true, body["dryRun"])
+		assert.Len(t, body["skipped"], 1)
+		assert.Len(t, body["reaped"], 0)
+	})
+}
@@ -0,0 +1,215 @@
+package reaper
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+// keys of the cycle checkpoint config map, which lets a new leader resume or run a cycle its predecessor did not finish
+const checkpointSlot = "slot"
+const checkpointStarted = "started"
+const checkpointFinished = "finished"
+const checkpointBudgetUsed = "budgetUsed"
+
+// leader coordinates active/standby replicas. Every replica keeps its schedule and informers running, but only the
+// leader reaps. A nil leader always leads.
+type leader struct {
+	client        kubernetes.Interface
+	namespace     string
+	name          string
+	identity      string
+	leaseDuration time.Duration
+	renewDeadline time.Duration
+	retryPeriod   time.Duration
+	schedule      cron.Schedule
+	// apiTimeout bounds each read and write of the cycle checkpoint
+	apiTimeout time.Duration
+	leading    int32
+	mutex      sync.Mutex
+	// lastSlot is the scheduled time of the latest cycle started by this replica
+	lastSlot time.Time
+	// current is the checkpoint of the cycle in progress
+	current cycleCheckpoint
+}
+
+// cycleCheckpoint is the leader's record of its latest cycle.
+type cycleCheckpoint struct {
+	slot       time.Time
+	started    time.Time
+	finished   time.Time
+	budgetUsed int64
+}
+
+func (leader *leader) isLeading() bool {
+	return leader == nil || atomic.LoadInt32(&leader.leading) == 1
+}
+
+// claimSlot returns whether a cycle for the scheduled time should be started, so that the cycle a new leader runs on
+// takeover is not run again when the schedule fires.
+func (leader *leader) claimSlot(slot time.Time) bool {
+	if leader == nil {
+		return true
+	}
+	leader.mutex.Lock()
+	defer leader.mutex.Unlock()
+	if !leader.isLeading() || !slot.After(leader.lastSlot) {
+		return false
+	}
+	leader.lastSlot = slot
+	return true
+}
+
+func (leader *leader) checkpointName() string {
+	return leader.name + "-cycle"
+}
+
+func (leader *leader) readCheckpoint() (cycleCheckpoint, error) {
+	checkpoint := cycleCheckpoint{}
+	ctx, cancel := apiContext(context.Background(), leader.apiTimeout)
+	defer cancel()
+	configMap, err := leader.client.CoreV1().ConfigMaps(leader.namespace).Get(ctx, leader.checkpointName(), metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return checkpoint, nil
+	}
+	if err != nil {
+		return checkpoint, err
+	}
+	checkpoint.slot, _ = time.Parse(time.RFC3339Nano, configMap.Data[checkpointSlot])
+	checkpoint.started, _ = time.Parse(time.RFC3339Nano, configMap.Data[checkpointStarted])
+	checkpoint.finished, _ = time.Parse(time.RFC3339Nano, configMap.Data[checkpointFinished])
+	checkpoint.budgetUsed, _ = strconv.ParseInt(configMap.Data[checkpointBudgetUsed], 10, 64)
+	return checkpoint, nil
+}
+
+func (leader *leader) writeCheckpoint(checkpoint cycleCheckpoint) {
+	if leader == nil {
+		return
+	}
+	data := map[string]string{
+		checkpointSlot:       checkpoint.slot.Format(time.RFC3339Nano),
+		checkpointStarted:    checkpoint.started.Format(time.RFC3339Nano),
+		checkpointBudgetUsed: strconv.FormatInt(checkpoint.budgetUsed, 10),
+	}
+	if !checkpoint.finished.IsZero() {
+		data[checkpointFinished] = checkpoint.finished.Format(time.RFC3339Nano)
+	}
+	ctx, cancel := apiContext(context.Background(), leader.apiTimeout)
+	defer cancel()
+	configMaps := leader.client.CoreV1().ConfigMaps(leader.namespace)
+	configMap, err := configMaps.Get(ctx, leader.checkpointName(), metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		configMap = &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: leader.checkpointName(), Namespace: leader.namespace}, Data: data}
+		_, err = configMaps.Create(ctx, configMap, metav1.CreateOptions{})
+	} else if err == nil {
+		configMap.Data = data
+		_, err = configMaps.Update(ctx, configMap, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		logrus.WithField("configMap", leader.checkpointName()).WithError(err).Warn("unable to write cycle checkpoint")
+	}
+}
+
+func (leader *leader) cycleStarted(slot time.Time, now time.Time) {
+	if leader == nil {
+		return
+	}
+	leader.mutex.Lock()
+	leader.current = cycleCheckpoint{slot: slot, started: now}
+	checkpoint := leader.current
+	leader.mutex.Unlock()
+	leader.writeCheckpoint(checkpoint)
+}
+
+// cycleProgress records the api calls used so far, so that a successor resuming the cycle gets only what is left.
+func (leader *leader) cycleProgress(budgetUsed int64) {
+	if leader == nil {
+		return
+	}
+	leader.mutex.Lock()
+	leader.current.budgetUsed = budgetUsed
+	checkpoint := leader.current
+	leader.mutex.Unlock()
+	leader.writeCheckpoint(checkpoint)
+}
+
+func (leader *leader) cycleFinished(budgetUsed int64, now time.Time) {
+	if leader == nil {
+		return
+	}
+	leader.mutex.Lock()
+	leader.current.budgetUsed = budgetUsed
+	leader.current.finished = now
+	checkpoint := leader.current
+	leader.mutex.Unlock()
+	leader.writeCheckpoint(checkpoint)
+}
+
+// takeover resumes the predecessor's interrupted cycle with the budget it had left, or runs a scheduled cycle that was
+// missed while no replica was leading.
+func (leader *leader) takeover(reaper reaper, now time.Time) {
+	checkpoint, err := leader.readCheckpoint()
+	if err != nil {
+		logrus.WithError(err).Warn("unable to read cycle checkpoint, waiting for the next scheduled cycle")
+		return
+	}
+	if checkpoint.started.IsZero() {
+		return
+	}
+	if checkpoint.finished.Before(checkpoint.started) {
+		logrus.WithFields(logrus.Fields{
+			"slot":       checkpoint.slot,
+			"budgetUsed": checkpoint.budgetUsed,
+		}).Info("resuming interrupted reap cycle")
+		if leader.claimSlot(checkpoint.slot) {
+			reaper.budget.carryOver(checkpoint.budgetUsed)
+			reaper.runCycle(checkpoint.slot)
+		}
+		return
+	}
+	if due := leader.schedule.Next(checkpoint.slot); !due.After(now) {
+		logrus.WithField("slot", due).Info("running missed reap cycle")
+		if leader.claimSlot(due) {
+			reaper.runCycle(due)
+		}
+	}
+}
+
+// run participates in leader election until the context is cancelled, releasing the lease if it is leading so that a
+// standby takes over without waiting for the lease to expire.
+func (leader *leader) run(ctx context.Context, reaper reaper) {
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta:  metav1.ObjectMeta{Name: leader.name, Namespace: leader.namespace},
+		Client:     leader.client.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{Identity: leader.identity},
+	}
+	leaderelection.RunOrDie(ctx, leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		LeaseDuration:   leader.leaseDuration,
+		RenewDeadline:   leader.renewDeadline,
+		RetryPeriod:     leader.retryPeriod,
+		ReleaseOnCancel: true,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				logrus.WithField("identity", leader.identity).Info("started leading")
+				atomic.StoreInt32(&leader.leading, 1)
+				leader.takeover(reaper, time.Now())
+			},
+			OnStoppedLeading: func() {
+				logrus.WithField("identity", leader.identity).Info("stopped leading")
+				atomic.StoreInt32(&leader.leading, 0)
+			},
+		},
+	})
+}
@@ -0,0 +1,141 @@
+package reaper
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testLeader(t *testing.T, r reaper) *leader {
+	schedule, err := scheduleParser.Parse("@every 1m")
+	assert.NoError(t, err)
+	return &leader{
+		client:    r.clientSet,
+		namespace: "pod-reaper",
+		name:      "pod-reaper",
+		identity:  "test",
+		schedule:  schedule,
+		leading:   1,
+	}
+}
+
+func TestLeaderClaimSlot(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		var leader *leader
+		assert.True(t, leader.isLeading())
+		assert.True(t, leader.claimSlot(time.Now()))
+	})
+	t.Run("standby", func(t *testing.T) {
+		leader := testLeader(t, createTestReaper(minimalOptions("0.0")))
+		leader.leading = 0
+		assert.False(t, leader.claimSlot(time.Now()))
+	})
+	t.Run("each slot once", func(t *testing.T) {
+		leader := testLeader(t, createTestReaper(minimalOptions("0.0")))
+		slot := time.Now()
+		assert.True(t, leader.claimSlot(slot))
+		assert.False(t, leader.claimSlot(slot))
+		assert.False(t, leader.claimSlot(slot.Add(-time.Minute)))
+		assert.True(t, leader.claimSlot(slot.Add(time.Minute)))
+	})
+}
+
+func TestLeaderCheckpoint(t *testing.T) {
+	leader := testLeader(t, createTestReaper(minimalOptions("0.0")))
+	checkpoint, err := leader.readCheckpoint()
+	assert.NoError(t, err)
+	assert.True(t, checkpoint.started.IsZero())
+
+	slot := time.Now().Truncate(time.Second)
+	leader.cycleStarted(slot, slot.Add(time.Second))
+	leader.cycleProgress(7)
+	checkpoint, err = leader.readCheckpoint()
+	assert.NoError(t, err)
+	assert.True(t, slot.Equal(checkpoint.slot))
+	assert.True(t, checkpoint.finished.IsZero())
+	assert.Equal(t, int64(7), checkpoint.budgetUsed)
+
+	leader.cycleFinished(9, slot.Add(time.Minute))
+	checkpoint, err = leader.readCheckpoint()
+	assert.NoError(t, err)
+	assert.True(t, slot.Add(time.Minute).Equal(checkpoint.finished))
+	assert.Equal(t, int64(9), checkpoint.budgetUsed)
+}
+
+func TestLeaderTakeover(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	startTime := now.Add(-time.Hour)
+
+	t.Run("resumes interrupted cycle", func(t *testing.T) {
+		opts := minimalOptions("1.0")
+		opts.apiCallBudget = 4
+		r := createTestReaper(opts,
+			createTestPod("pod-1", "default", &startTime),
+			createTestPod("pod-2", "default", &startTime),
+			createTestPod("pod-3", "default", &startTime))
+		r.budget = newAPIBudget(opts.apiCallBudget)
+		r.leader = testLeader(t, r)
+		// the previous leader listed pods and reaped one pod before it was interrupted
+		r.leader.writeCheckpoint(cycleCheckpoint{slot: now.Add(-30 * time.Second), started: now.Add(-30 * time.Second), budgetUsed: 2})
+
+		r.leader.takeover(r, now)
+
+		// one list and one delete are left in the budget
+		pods, err := r.clientSet.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(pods.Items))
+		checkpoint, err := r.leader.readCheckpoint()
+		assert.NoError(t, err)
+		assert.False(t, checkpoint.finished.IsZero())
+		assert.Equal(t, int64(4), checkpoint.budgetUsed)
+		assert.False(t, r.leader.claimSlot(now.Add(-30*time.Second)))
+	})
+	t.Run("runs missed cycle", func(t *testing.T) {
+		r := createTestReaper(minimalOptions("1.0"), createTestPod("pod-1", "default", &startTime))
+		r.leader = testLeader(t, r)
+		r.leader.writeCheckpoint(cycleCheckpoint{slot: now.Add(-2 * time.Minute), started: now.Add(-2 * time.Minute), finished: now.Add(-119 * time.Second)})
+
+		r.leader.takeover(r, now)
+
+		pods, err := r.clientSet.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, 0, len(pods.Items))
+	})
+	t.Run("waits for next cycle", func(t *testing.T) {
+		r := createTestReaper(minimalOptions("1.0"), createTestPod("pod-1", "default", &startTime))
+		r.leader = testLeader(t, r)
+		r.leader.writeCheckpoint(cycleCheckpoint{slot: now.Add(-30 * time.Second), started: now.Add(-30 * time.Second), finished: now.Add(-29 * time.Second)})
+
+		r.leader.takeover(r, now)
+
+		pods, err := r.clientSet.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(pods.Items))
+	})
+	t.Run("first leader", func(t *testing.T) {
+		r := createTestReaper(minimalOptions("1.0"), createTestPod("pod-1", "default", &startTime))
+		r.leader = testLeader(t, r)
+
+		r.leader.takeover(r, now)
+
+		pods, err := r.clientSet.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(pods.Items))
+	})
+}
+
+func TestScytheCycleStandby(t *testing.T) {
+	startTime := time.Now().Add(-time.Hour)
+	r := createTestReaper(minimalOptions("1.0"), createTestPod("pod-1", "default", &startTime))
+	r.leader = testLeader(t, r)
+	r.leader.leading = 0
+
+	r.scytheCycle()
+
+	pods, err := r.clientSet.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(pods.Items))
+}
@@ -1,4 +1,4 @@
-package main
+package reaper
 
 import (
 	"os"
@@ -11,17 +11,16 @@ const envLogLevel = "LOG_LEVEL"
 const envLogFormat = "LOG_FORMAT"
 const fluentdFormat = "Fluentd"
 const logrusFormat = "Logrus"
+const jsonFormat = "json"
+const textFormat = "text"
 const defaultLogLevel = logrus.InfoLevel
 
-func main() {
-	logLevel := getLogLevel()
-	logrus.SetLevel(logLevel)
-	logFormat := getLogFormat()
-	logrus.SetFormatter(logFormat)
-
-	reaper := newReaper()
-	reaper.harvest()
-	logrus.Info("pod reaper is exiting")
+// ConfigureLogging configures the standard logrus logger with LOG_LEVEL and LOG_FORMAT. Human readable logs always go
+// to standard error, leaving standard out for machine readable output.
+func ConfigureLogging() {
+	logrus.SetOutput(os.Stderr)
+	logrus.SetLevel(getLogLevel())
+	logrus.SetFormatter(getLogFormat())
 }
 
 func getLogLevel() logrus.Level {
@@ -41,8 +40,10 @@ func getLogLevel() logrus.Level {
 
 func getLogFormat() logrus.Formatter {
 	formatString, exists := os.LookupEnv(envLogFormat)
-	if !exists || formatString == logrusFormat {
+	if !exists || formatString == logrusFormat || formatString == jsonFormat {
 		return &logrus.JSONFormatter{}
+	} else if formatString == textFormat {
+		return &logrus.TextFormatter{DisableColors: true, FullTimestamp: true}
 	} else if formatString == fluentdFormat {
 		return joonix.NewFormatter()
 	} else {
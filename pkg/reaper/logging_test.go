@@ -1,4 +1,4 @@
-package main
+package reaper
 
 import (
 	"os"
@@ -62,6 +62,18 @@ func TestGetLogFormat(t *testing.T) {
 		format := getLogFormat()
 		assert.Equal(t, reflect.TypeOf(format), reflect.TypeOf(&logrus.JSONFormatter{}))
 	})
+	t.Run("json", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envLogFormat, "json")
+		format := getLogFormat()
+		assert.Equal(t, reflect.TypeOf(format), reflect.TypeOf(&logrus.JSONFormatter{}))
+	})
+	t.Run("text", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envLogFormat, "text")
+		format := getLogFormat()
+		assert.Equal(t, reflect.TypeOf(format), reflect.TypeOf(&logrus.TextFormatter{}))
+	})
 	t.Run("fluentd", func(t *testing.T) {
 		os.Clearenv()
 		os.Setenv(envLogFormat, "Fluentd")
@@ -0,0 +1,61 @@
+package reaper
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// annotationMatchedAt records when a pod first matched the rules, starting its MARK_GRACE window
+const annotationMatchedAt = "pod-reaper/matched-at"
+
+// markGraceElapsed returns whether the pod has matched the rules for at least MARK_GRACE. A pod matching for the first
+// time is annotated with the time and reaped on a later cycle, once the window has elapsed and it still matches.
+func (reaper reaper) markGraceElapsed(pod v1.Pod, now time.Time) bool {
+	if reaper.options.markGrace <= 0 {
+		return true
+	}
+	matchedAt, err := time.Parse(time.RFC3339, pod.Annotations[annotationMatchedAt])
+	if err != nil {
+		podLog := logrus.WithFields(logrus.Fields{"pod": pod.Name, "namespace": pod.Namespace})
+		if err := reaper.patchAnnotations(pod, map[string]string{annotationMatchedAt: now.UTC().Format(time.RFC3339)}); err != nil {
+			podLog.WithError(err).Warn("unable to mark pod")
+			return false
+		}
+		podLog.WithField("markGrace", reaper.options.markGrace).Info("pod marked, reaping once the mark grace elapses")
+		return false
+	}
+	return !now.Before(matchedAt.Add(reaper.options.markGrace))
+}
+
+// clearMark removes the MARK_GRACE annotation from a pod that no longer matches the rules, so that the window starts
+// over if it matches again.
+func (reaper reaper) clearMark(pod v1.Pod) {
+	if reaper.options.markGrace <= 0 {
+		return
+	}
+	if _, marked := pod.Annotations[annotationMatchedAt]; !marked {
+		return
+	}
+	if !reaper.apiCall(operationPatch) {
+		return
+	}
+	// a null value removes the annotation in a merge patch
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{annotationMatchedAt: nil},
+		},
+	})
+	if err == nil {
+		ctx, cancel := reaper.apiContext()
+		defer cancel()
+		_, err = reaper.clientSet.CoreV1().Pods(pod.Namespace).Patch(ctx, pod.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	}
+	if err != nil {
+		logrus.WithField("pod", pod.Name).WithError(err).Warn("unable to clear pod mark")
+	}
+}
@@ -0,0 +1,82 @@
+package reaper
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testMarkedPod(name string, matchedAt time.Time) v1.Pod {
+	pod := createTestPod(name, "default", nil)
+	pod.Annotations = map[string]string{annotationMatchedAt: matchedAt.UTC().Format(time.RFC3339)}
+	return pod
+}
+
+func TestMarkGrace(t *testing.T) {
+	t.Run("first match marks the pod", func(t *testing.T) {
+		opts := minimalOptions("1.0")
+		opts.markGrace = time.Hour
+		r := createTestReaper(opts, createTestPod("pod", "default", nil))
+
+		r.scytheCycle()
+
+		pod, err := r.clientSet.CoreV1().Pods("default").Get(context.TODO(), "pod", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, pod.Annotations[annotationMatchedAt])
+	})
+	t.Run("grace not elapsed", func(t *testing.T) {
+		opts := minimalOptions("1.0")
+		opts.markGrace = time.Hour
+		r := createTestReaper(opts, testMarkedPod("pod", time.Now().Add(-time.Minute)))
+
+		r.scytheCycle()
+
+		_, err := r.clientSet.CoreV1().Pods("default").Get(context.TODO(), "pod", metav1.GetOptions{})
+		assert.NoError(t, err)
+	})
+	t.Run("grace elapsed", func(t *testing.T) {
+		opts := minimalOptions("1.0")
+		opts.markGrace = time.Hour
+		r := createTestReaper(opts, testMarkedPod("pod", time.Now().Add(-2*time.Hour)))
+
+		r.scytheCycle()
+
+		_, err := r.clientSet.CoreV1().Pods("default").Get(context.TODO(), "pod", metav1.GetOptions{})
+		assert.Error(t, err)
+	})
+	t.Run("no longer matching clears the mark", func(t *testing.T) {
+		opts := minimalOptions("0.0")
+		opts.markGrace = time.Hour
+		r := createTestReaper(opts, testMarkedPod("pod", time.Now().Add(-2*time.Hour)))
+
+		r.scytheCycle()
+
+		pod, err := r.clientSet.CoreV1().Pods("default").Get(context.TODO(), "pod", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.NotContains(t, pod.Annotations, annotationMatchedAt)
+	})
+	t.Run("dry run does not mark", func(t *testing.T) {
+		opts := minimalOptions("1.0")
+		opts.markGrace = time.Hour
+		opts.dryRun = true
+		r := createTestReaper(opts, createTestPod("pod", "default", nil))
+
+		r.scytheCycle()
+
+		pod, err := r.clientSet.CoreV1().Pods("default").Get(context.TODO(), "pod", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.NotContains(t, pod.Annotations, annotationMatchedAt)
+	})
+	t.Run("disabled", func(t *testing.T) {
+		r := createTestReaper(minimalOptions("1.0"), createTestPod("pod", "default", nil))
+
+		r.scytheCycle()
+
+		_, err := r.clientSet.CoreV1().Pods("default").Get(context.TODO(), "pod", metav1.GetOptions{})
+		assert.Error(t, err)
+	})
+}
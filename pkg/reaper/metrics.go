@@ -0,0 +1,95 @@
+package reaper
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// exemplarCycleID is the exemplar label linking a metric sample to the reap records and logs of the cycle it was
+// observed in
+const exemplarCycleID = "cycle_id"
+
+const metricsNamespace = "pod_reaper"
+
+var apiCallsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: metricsNamespace,
+	Name:      "api_calls_total",
+	Help:      "Kubernetes API calls made by pod-reaper, by operation.",
+}, []string{"operation"})
+
+var apiBudgetExhaustedTotal = promauto.NewCounter(prometheus.CounterOpts{
+	Namespace: metricsNamespace,
+	Name:      "api_budget_exhausted_total",
+	Help:      "Reap cycles that were ended early because the API call budget was exhausted.",
+})
+
+var apiRetriesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: metricsNamespace,
+	Name:      "api_retries_total",
+	Help:      "Kubernetes API calls retried by pod-reaper after a transient error, by operation.",
+}, []string{"operation"})
+
+var podsReapedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: metricsNamespace,
+	Name:      "pods_reaped_total",
+	Help:      "Pods reaped by pod-reaper, by action.",
+}, []string{"action"})
+
+var evictionsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: metricsNamespace,
+	Name:      "evictions_total",
+	Help:      "Pods evicted by pod-reaper, by eviction API version.",
+}, []string{"api_version"})
+
+var chaosExperimentsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: metricsNamespace,
+	Name:      "chaos_experiments_total",
+	Help:      "Chaos windows verified by pod-reaper, by result.",
+}, []string{"result"})
+
+var podsWithoutStartTime = promauto.NewGauge(prometheus.GaugeOpts{
+	Namespace: metricsNamespace,
+	Name:      "pods_without_start_time",
+	Help:      "Pods without a start time, which never started running, evaluated in the last reap cycle.",
+})
+
+var cycleDurationSeconds = promauto.NewHistogram(prometheus.HistogramOpts{
+	Namespace: metricsNamespace,
+	Name:      "cycle_duration_seconds",
+	Help:      "Duration of reap cycles.",
+	Buckets:   prometheus.ExponentialBuckets(0.05, 2, 14),
+})
+
+// newCycleID returns a random identifier for a reap cycle, formatted like a trace id so it can double as one.
+func newCycleID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(id)
+}
+
+func cycleExemplar(cycleID string) prometheus.Labels {
+	if cycleID == "" {
+		return nil
+	}
+	return prometheus.Labels{exemplarCycleID: cycleID}
+}
+
+// observePodReaped counts a reaped pod with the cycle id as exemplar.
+func observePodReaped(action string, cycleID string) {
+	podsReapedTotal.WithLabelValues(action).(prometheus.ExemplarAdder).AddWithExemplar(1, cycleExemplar(cycleID))
+}
+
+func observeEviction(version string) {
+	evictionsTotal.WithLabelValues(version).Inc()
+}
+
+// observeCycleDuration records the duration of a cycle with the cycle id as exemplar.
+func observeCycleDuration(duration time.Duration, cycleID string) {
+	cycleDurationSeconds.(prometheus.ExemplarObserver).ObserveWithExemplar(duration.Seconds(), cycleExemplar(cycleID))
+}
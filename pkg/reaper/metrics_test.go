@@ -0,0 +1,75 @@
+package reaper
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+// exemplarCycleIDs returns the cycle ids of the exemplars of the named metric family.
+func exemplarCycleIDs(t *testing.T, name string) []string {
+	families, err := prometheus.DefaultGatherer.Gather()
+	assert.NoError(t, err)
+	var ids []string
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			var exemplars []*dto.Exemplar
+			if metric.GetCounter() != nil {
+				exemplars = append(exemplars, metric.GetCounter().GetExemplar())
+			}
+			for _, bucket := range metric.GetHistogram().GetBucket() {
+				exemplars = append(exemplars, bucket.GetExemplar())
+			}
+			for _, exemplar := range exemplars {
+				for _, label := range exemplar.GetLabel() {
+					if label.GetName() == exemplarCycleID {
+						ids = append(ids, label.GetValue())
+					}
+				}
+			}
+		}
+	}
+	return ids
+}
+
+func TestNewCycleID(t *testing.T) {
+	id := newCycleID()
+	assert.Regexp(t, "^[0-9a-f]{32}$", id)
+	assert.NotEqual(t, id, newCycleID())
+}
+
+func TestScytheCycleExemplars(t *testing.T) {
+	var buffer bytes.Buffer
+	startTime := time.Now()
+	opts := minimalOptions("1.0")
+	opts.notifiers = []notifier{&recordNotifier{writer: &buffer}}
+	r := createTestReaper(opts, createTestPod("pod-1", "default", &startTime))
+	before := testutil.ToFloat64(podsReapedTotal.WithLabelValues(actionDelete))
+
+	r.scytheCycle()
+
+	assert.Equal(t, before+1, testutil.ToFloat64(podsReapedTotal.WithLabelValues(actionDelete)))
+	var record reapNotification
+	assert.NoError(t, json.Unmarshal(buffer.Bytes(), &record))
+	assert.NotEmpty(t, record.CycleID)
+	assert.Contains(t, exemplarCycleIDs(t, "pod_reaper_pods_reaped_total"), record.CycleID)
+	assert.Contains(t, exemplarCycleIDs(t, "pod_reaper_cycle_duration_seconds"), record.CycleID)
+}
+
+func TestPodsWithoutStartTime(t *testing.T) {
+	startTime := time.Now()
+	r := createTestReaper(minimalOptions("0.0"), createTestPod("started", "default", &startTime), createTestPod("pending", "default", nil))
+
+	r.scytheCycle()
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(podsWithoutStartTime))
+}
@@ -0,0 +1,95 @@
+package reaper
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// namespaceReportConfigMap is the name of the config map that, when NAMESPACE_REPORTS is enabled, summarizes the
+// latest reaps in each namespace for the namespace's tenants.
+const namespaceReportConfigMap = "pod-reaper-report"
+
+// keys of the namespace report config map
+const namespaceReportJSON = "report.json"
+const namespaceReportSummary = "summary"
+
+// writeNamespaceReports writes a report config map to each namespace with reaped pods, replacing the report of any
+// earlier cycle. Failures are logged but never interrupt the reaper.
+func (reaper reaper) writeNamespaceReports(reaped *reapReport) {
+	byNamespace := map[string]*reapReport{}
+	var namespaces []string
+	for _, pod := range reaped.Pods {
+		report, ok := byNamespace[pod.Namespace]
+		if !ok {
+			report = &reapReport{Time: reaped.Time, CycleID: reaped.CycleID}
+			byNamespace[pod.Namespace] = report
+			namespaces = append(namespaces, pod.Namespace)
+		}
+		report.Pods = append(report.Pods, pod)
+	}
+	for _, namespace := range namespaces {
+		if err := reaper.writeNamespaceReport(namespace, byNamespace[namespace]); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"namespace": namespace,
+				"configMap": namespaceReportConfigMap,
+			}).WithError(err).Warn("unable to write namespace report")
+		}
+	}
+}
+
+func (reaper reaper) writeNamespaceReport(namespace string, report *reapReport) error {
+	var encoded strings.Builder
+	if err := report.write(&encoded); err != nil {
+		return err
+	}
+	data := map[string]string{
+		namespaceReportJSON:    encoded.String(),
+		namespaceReportSummary: namespaceReportText(report),
+	}
+	if !reaper.apiCall(operationGet) {
+		return errAPIBudgetExhausted
+	}
+	ctx, cancel := reaper.apiContext()
+	defer cancel()
+	configMaps := reaper.clientSet.CoreV1().ConfigMaps(namespace)
+	configMap, err := configMaps.Get(ctx, namespaceReportConfigMap, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		if !reaper.apiCall(operationCreate) {
+			return errAPIBudgetExhausted
+		}
+		_, err = configMaps.Create(ctx, &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: namespaceReportConfigMap, Namespace: namespace},
+			Data:       data,
+		}, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if !reaper.apiCall(operationUpdate) {
+		return errAPIBudgetExhausted
+	}
+	configMap.Data = data
+	_, err = configMaps.Update(ctx, configMap, metav1.UpdateOptions{})
+	return err
+}
+
+// namespaceReportText describes the report in plain text for tenants reading the config map with kubectl.
+func namespaceReportText(report *reapReport) string {
+	var text strings.Builder
+	fmt.Fprintf(&text, "%d pods reaped by pod-reaper at %s", len(report.Pods), report.Time.Format(time.RFC3339))
+	if report.CycleID != "" {
+		fmt.Fprintf(&text, " (cycle %s)", report.CycleID)
+	}
+	text.WriteString(":\n")
+	for _, pod := range report.Pods {
+		fmt.Fprintf(&text, "- pod/%s (%s): %s\n", pod.Name, pod.Action, strings.Join(pod.Reasons, "; "))
+	}
+	return text.String()
+}
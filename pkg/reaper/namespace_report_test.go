@@ -0,0 +1,84 @@
+package reaper
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func getNamespaceReport(t *testing.T, r reaper, namespace string) (*v1.ConfigMap, reapReport) {
+	configMap, err := r.clientSet.CoreV1().ConfigMaps(namespace).Get(context.TODO(), namespaceReportConfigMap, metav1.GetOptions{})
+	if !assert.NoError(t, err) {
+		return nil, reapReport{}
+	}
+	var report reapReport
+	assert.NoError(t, json.Unmarshal([]byte(configMap.Data[namespaceReportJSON]), &report))
+	return configMap, report
+}
+
+func TestNamespaceReportText(t *testing.T) {
+	report := &reapReport{
+		Time:    time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		CycleID: "abc",
+		Pods: []reapReportPod{
+			{Name: "web-1", Namespace: "web", Reasons: []string{"one", "two"}, Action: actionEvict},
+		},
+	}
+	assert.Equal(t, "1 pods reaped by pod-reaper at 2024-01-02T03:04:05Z (cycle abc):\n- pod/web-1 (evict): one; two\n", namespaceReportText(report))
+}
+
+func TestScytheCycleNamespaceReports(t *testing.T) {
+	t.Run("written per affected namespace", func(t *testing.T) {
+		startTime := time.Now()
+		opts := minimalOptions("1.0")
+		opts.namespace = ""
+		opts.namespaceReports = true
+		r := createTestReaper(opts,
+			createTestPod("pod-1", "team-a", &startTime),
+			createTestPod("pod-2", "team-a", &startTime),
+			createTestPod("pod-3", "team-b", &startTime))
+
+		r.scytheCycle()
+
+		configMap, report := getNamespaceReport(t, r, "team-a")
+		assert.Equal(t, 2, len(report.Pods))
+		assert.Contains(t, configMap.Data[namespaceReportSummary], "2 pods reaped by pod-reaper")
+		_, report = getNamespaceReport(t, r, "team-b")
+		assert.Equal(t, 1, len(report.Pods))
+		assert.Equal(t, "pod-3", report.Pods[0].Name)
+	})
+	t.Run("replaces earlier report", func(t *testing.T) {
+		startTime := time.Now()
+		opts := minimalOptions("1.0")
+		opts.namespaceReports = true
+		r := createTestReaper(opts, createTestPod("pod-1", "default", &startTime))
+		r.scytheCycle()
+		_, err := r.clientSet.CoreV1().Pods("default").Create(context.TODO(), &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod-2", Namespace: "default"},
+		}, metav1.CreateOptions{})
+		assert.NoError(t, err)
+
+		r.scytheCycle()
+
+		_, report := getNamespaceReport(t, r, "default")
+		assert.Equal(t, 1, len(report.Pods))
+		assert.Equal(t, "pod-2", report.Pods[0].Name)
+	})
+	t.Run("not written in dry run", func(t *testing.T) {
+		startTime := time.Now()
+		opts := minimalOptions("1.0")
+		opts.dryRun = true
+		opts.namespaceReports = true
+		r := createTestReaper(opts, createTestPod("pod-1", "default", &startTime))
+
+		r.scytheCycle()
+
+		_, err := r.clientSet.CoreV1().ConfigMaps("default").Get(context.TODO(), namespaceReportConfigMap, metav1.GetOptions{})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,66 @@
+package reaper
+
+import (
+	"github.com/sirupsen/logrus"
+	"github.com/target/pod-reaper/rules"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// namespaceRulesConfigMap is the name of the config map that, when NAMESPACE_RULES is enabled, overrides the
+// global rule configuration for pods in the config map's namespace.
+const namespaceRulesConfigMap = "pod-reaper-rules"
+
+// ruleResolver determines which rules apply to a namespace. Namespace config maps are fetched at most once per
+// resolver, so a new resolver should be created for each reap cycle.
+type ruleResolver struct {
+	reaper reaper
+	cache  map[string]*rules.Rules
+}
+
+func (reaper reaper) newRuleResolver() ruleResolver {
+	return ruleResolver{
+		reaper: reaper,
+		cache:  map[string]*rules.Rules{},
+	}
+}
+
+// rulesFor returns the rules for pods in the namespace and whether pods in the namespace should be evaluated at all.
+// A namespace whose config map cannot be read or loaded is skipped rather than falling back to the global rules.
+func (resolver ruleResolver) rulesFor(namespace string) (rules.Rules, bool) {
+	if !resolver.reaper.options.namespaceRules {
+		return resolver.reaper.options.rules, true
+	}
+	if loaded, cached := resolver.cache[namespace]; cached {
+		if loaded == nil {
+			return rules.Rules{}, false
+		}
+		return *loaded, true
+	}
+	loaded, err := resolver.reaper.loadNamespaceRules(namespace)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"namespace": namespace,
+			"configMap": namespaceRulesConfigMap,
+		}).WithError(err).Error("unable to load namespace rules, skipping namespace")
+		resolver.cache[namespace] = nil
+		return rules.Rules{}, false
+	}
+	resolver.cache[namespace] = &loaded
+	return loaded, true
+}
+
+func (reaper reaper) loadNamespaceRules(namespace string) (rules.Rules, error) {
+	if !reaper.apiCall(operationGet) {
+		return rules.Rules{}, errAPIBudgetExhausted
+	}
+	ctx, cancel := reaper.apiContext()
+	defer cancel()
+	configMap, err := reaper.clientSet.CoreV1().ConfigMaps(namespace).Get(ctx, namespaceRulesConfigMap, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return reaper.options.rules, nil
+	} else if err != nil {
+		return rules.Rules{}, err
+	}
+	return rules.LoadRulesWithOverrides(configMap.Data)
+}
@@ -0,0 +1,107 @@
+package reaper
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func testRulesConfigMap(namespace string, data map[string]string) *v1.ConfigMap {
+	return &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      namespaceRulesConfigMap,
+			Namespace: namespace,
+		},
+		Data: data,
+	}
+}
+
+func TestRuleResolver(t *testing.T) {
+	t.Run("disabled uses global rules", func(t *testing.T) {
+		opts := minimalOptions("1.0")
+		r := createTestReaper(opts)
+		loaded, ok := r.newRuleResolver().rulesFor("default")
+		assert.True(t, ok)
+		assert.Equal(t, opts.rules, loaded)
+	})
+
+	t.Run("missing config map uses global rules", func(t *testing.T) {
+		opts := minimalOptions("1.0")
+		opts.namespaceRules = true
+		r := createTestReaper(opts)
+		loaded, ok := r.newRuleResolver().rulesFor("default")
+		assert.True(t, ok)
+		assert.Equal(t, opts.rules, loaded)
+	})
+
+	t.Run("config map overrides global rules", func(t *testing.T) {
+		opts := minimalOptions("1.0")
+		opts.namespaceRules = true
+		r := reaper{
+			clientSet: fake.NewSimpleClientset(testRulesConfigMap("default", map[string]string{"CHAOS_CHANCE": "0.0"})),
+			options:   opts,
+		}
+		loaded, ok := r.newRuleResolver().rulesFor("default")
+		assert.True(t, ok)
+		shouldReap, _ := loaded.ShouldReap(v1.Pod{})
+		assert.False(t, shouldReap)
+	})
+
+	t.Run("invalid config map skips namespace", func(t *testing.T) {
+		opts := minimalOptions("1.0")
+		opts.namespaceRules = true
+		r := reaper{
+			clientSet: fake.NewSimpleClientset(testRulesConfigMap("default", map[string]string{"CHAOS_CHANCE": "invalid"})),
+			options:   opts,
+		}
+		_, ok := r.newRuleResolver().rulesFor("default")
+		assert.False(t, ok)
+	})
+
+	t.Run("config map fetched once per resolver", func(t *testing.T) {
+		opts := minimalOptions("1.0")
+		opts.namespaceRules = true
+		fakeClient := fake.NewSimpleClientset()
+		gets := 0
+		fakeClient.PrependReactor("get", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			gets++
+			return true, nil, errors.New("simulated get error")
+		})
+		r := reaper{clientSet: fakeClient, options: opts}
+		resolver := r.newRuleResolver()
+		_, ok := resolver.rulesFor("default")
+		assert.False(t, ok)
+		_, ok = resolver.rulesFor("default")
+		assert.False(t, ok)
+		assert.Equal(t, 1, gets)
+	})
+}
+
+func TestScytheCycleNamespaceRules(t *testing.T) {
+	startTime := time.Now()
+	protected := createTestPod("protected", "protected", &startTime)
+	unprotected := createTestPod("unprotected", "default", &startTime)
+	opts := minimalOptions("1.0")
+	opts.namespace = ""
+	opts.namespaceRules = true
+	r := reaper{
+		clientSet: fake.NewSimpleClientset(&protected, &unprotected,
+			testRulesConfigMap("protected", map[string]string{"CHAOS_CHANCE": "0.0"})),
+		options: opts,
+	}
+
+	r.scytheCycle()
+
+	_, err := r.clientSet.CoreV1().Pods("protected").Get(context.TODO(), "protected", metav1.GetOptions{})
+	assert.NoError(t, err)
+	_, err = r.clientSet.CoreV1().Pods("default").Get(context.TODO(), "unprotected", metav1.GetOptions{})
+	assert.Error(t, err)
+}
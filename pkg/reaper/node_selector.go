@@ -0,0 +1,51 @@
+package reaper
+
+import (
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// targetsNodes returns whether NODE_NAME or NODE_SELECTOR limit pod-reaper to pods on particular nodes.
+func (reaper reaper) targetsNodes() bool {
+	return reaper.options.nodeNames != nil || reaper.options.nodeSelector != nil
+}
+
+// selectNodes keeps the pods scheduled on the nodes named by NODE_NAME and selected by NODE_SELECTOR. The selected
+// nodes are listed once per call, so each cycle sees the node labels as they are when it starts.
+func (reaper reaper) selectNodes(pods []v1.Pod) []v1.Pod {
+	var selected map[string]bool
+	if reaper.options.nodeSelector != nil {
+		if !reaper.apiCall(operationList) {
+			logrus.Warn("api call budget exhausted, not listing nodes")
+			return nil
+		}
+		ctx, cancel := reaper.apiContext()
+		defer cancel()
+		nodes, err := reaper.clientSet.CoreV1().Nodes().List(ctx, metav1.ListOptions{
+			LabelSelector: reaper.options.nodeSelector.String(),
+		})
+		if err != nil {
+			logrus.WithError(err).Panic("unable to get nodes from the cluster")
+		}
+		selected = map[string]bool{}
+		for _, node := range nodes.Items {
+			selected[node.Name] = true
+		}
+	}
+	var filtered []v1.Pod
+	for _, pod := range pods {
+		// pods that are not scheduled are on no node, so they are never selected
+		if pod.Spec.NodeName == "" {
+			continue
+		}
+		if reaper.options.nodeNames != nil && !reaper.options.nodeNames[pod.Spec.NodeName] {
+			continue
+		}
+		if selected != nil && !selected[pod.Spec.NodeName] {
+			continue
+		}
+		filtered = append(filtered, pod)
+	}
+	return filtered
+}
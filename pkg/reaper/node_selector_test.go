@@ -0,0 +1,85 @@
+package reaper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func testNodePod(name string, node string) v1.Pod {
+	pod := createTestPod(name, "default", nil)
+	pod.Spec.NodeName = node
+	return pod
+}
+
+func podNames(pods []v1.Pod) []string {
+	var names []string
+	for _, pod := range pods {
+		names = append(names, pod.Name)
+	}
+	return names
+}
+
+func TestSelectNodes(t *testing.T) {
+	pods := []v1.Pod{
+		testNodePod("canary-pod", "canary"),
+		testNodePod("stable-pod", "stable"),
+		testNodePod("other-canary-pod", "other-canary"),
+		testNodePod("pending-pod", ""),
+	}
+	nodes := []runtime.Object{
+		testZoneNode("canary", "zone-a"),
+		testZoneNode("stable", "zone-a"),
+		testZoneNode("other-canary", "zone-b"),
+	}
+	nodes[0].(*v1.Node).Labels["pool"] = "canary"
+	nodes[2].(*v1.Node).Labels["pool"] = "canary"
+
+	t.Run("not targeted", func(t *testing.T) {
+		r := reaper{options: minimalOptions("1.0")}
+		assert.False(t, r.targetsNodes())
+	})
+	t.Run("node names", func(t *testing.T) {
+		opts := minimalOptions("1.0")
+		opts.nodeNames = map[string]bool{"canary": true, "stable": true}
+		r := reaper{clientSet: fake.NewSimpleClientset(nodes...), options: opts}
+		assert.True(t, r.targetsNodes())
+		assert.Equal(t, []string{"canary-pod", "stable-pod"}, podNames(r.selectNodes(pods)))
+	})
+	t.Run("node selector", func(t *testing.T) {
+		opts := minimalOptions("1.0")
+		opts.nodeSelector = labels.SelectorFromSet(labels.Set{"pool": "canary"})
+		r := reaper{clientSet: fake.NewSimpleClientset(nodes...), options: opts}
+		assert.Equal(t, []string{"canary-pod", "other-canary-pod"}, podNames(r.selectNodes(pods)))
+	})
+	t.Run("node names and selector", func(t *testing.T) {
+		opts := minimalOptions("1.0")
+		opts.nodeNames = map[string]bool{"canary": true, "stable": true}
+		opts.nodeSelector = labels.SelectorFromSet(labels.Set{"pool": "canary"})
+		r := reaper{clientSet: fake.NewSimpleClientset(nodes...), options: opts}
+		assert.Equal(t, []string{"canary-pod"}, podNames(r.selectNodes(pods)))
+	})
+	t.Run("budget exhausted", func(t *testing.T) {
+		opts := minimalOptions("1.0")
+		opts.nodeSelector = labels.SelectorFromSet(labels.Set{"pool": "canary"})
+		r := reaper{clientSet: fake.NewSimpleClientset(nodes...), options: opts, budget: newAPIBudget(1)}
+		assert.True(t, r.apiCall(operationList))
+		assert.Empty(t, r.selectNodes(pods))
+	})
+	t.Run("get pods", func(t *testing.T) {
+		opts := minimalOptions("1.0")
+		opts.nodeNames = map[string]bool{"canary": true}
+		objects := append([]runtime.Object{}, nodes...)
+		for i := range pods {
+			objects = append(objects, &pods[i])
+		}
+		r := reaper{clientSet: fake.NewSimpleClientset(objects...), options: opts}
+		podList, err := r.getPods()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"canary-pod"}, podNames(podList.Items))
+	})
+}
@@ -0,0 +1,232 @@
+package reaper
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// notificationDedupeKey is the config map key holding the time each notification was sent, by fingerprint
+const notificationDedupeKey = "sent.json"
+
+// the fingerprints of the latest notifications are kept, so that the config map stays within the kubernetes size limit
+const maxNotificationFingerprints = 5000
+
+// notificationDedupe suppresses notifications identical to one sent within NOTIFICATION_DEDUPE_WINDOW. The
+// fingerprints of sent notifications are persisted in a config map, so that neither a restart nor another replica
+// taking over the lead sends them again. A nil notificationDedupe sends every notification.
+type notificationDedupe struct {
+	window    time.Duration
+	namespace string
+	configMap string
+
+	mutex sync.Mutex
+	sent  map[string]time.Time
+	// loadedCycle is the cycle the fingerprints were last read from the config map in
+	loadedCycle string
+	// changed is set when notifications were sent since the fingerprints were last written
+	changed bool
+	// queued holds, by notifier, the fingerprints of the notifications batched until the notifier is flushed
+	queued map[string][]string
+}
+
+// notificationFingerprint identifies a notification by the pod, the rules it matched, and what was done to it, since
+// the reasons themselves change from cycle to cycle, for example with the pod's age.
+func notificationFingerprint(pod v1.Pod, rules []string, action string, dryRun bool) string {
+	sorted := append([]string(nil), rules...)
+	sort.Strings(sorted)
+	return strings.Join([]string{string(pod.UID), action, strconv.FormatBool(dryRun), strings.Join(sorted, ",")}, "/")
+}
+
+// suppress returns whether a notification with the fingerprint was already sent within the window, reading the
+// fingerprints persisted by other replicas at the start of each cycle.
+func (dedupe *notificationDedupe) suppress(reaper reaper, fingerprint string, now time.Time) bool {
+	if dedupe == nil {
+		return false
+	}
+	dedupe.mutex.Lock()
+	defer dedupe.mutex.Unlock()
+	if dedupe.loadedCycle != reaper.cycleID {
+		dedupe.loadedCycle = reaper.cycleID
+		if err := dedupe.load(reaper); err != nil {
+			logrus.WithField("configMap", dedupe.configMap).WithError(err).Warn("unable to read sent notifications")
+		}
+	}
+	sent, exists := dedupe.sent[fingerprint]
+	return exists && now.Sub(sent) < dedupe.window
+}
+
+// markSent records that a notification with the fingerprint was sent.
+func (dedupe *notificationDedupe) markSent(fingerprint string, now time.Time) {
+	if dedupe == nil {
+		return
+	}
+	dedupe.mutex.Lock()
+	defer dedupe.mutex.Unlock()
+	if dedupe.sent == nil {
+		dedupe.sent = map[string]time.Time{}
+	}
+	dedupe.sent[fingerprint] = now
+	dedupe.changed = true
+}
+
+// markQueued records that the notifier batched a notification with the fingerprint, which is only marked as sent once
+// the notifier is flushed successfully.
+func (dedupe *notificationDedupe) markQueued(notifier string, fingerprint string) {
+	if dedupe == nil {
+		return
+	}
+	dedupe.mutex.Lock()
+	defer dedupe.mutex.Unlock()
+	if dedupe.queued == nil {
+		dedupe.queued = map[string][]string{}
+	}
+	dedupe.queued[notifier] = append(dedupe.queued[notifier], fingerprint)
+}
+
+// markFlushed marks the fingerprints queued for the notifier as sent when its flush delivered them, and forgets them
+// either way, so that notifications that were not delivered are sent again by a later cycle.
+func (dedupe *notificationDedupe) markFlushed(notifier string, delivered bool, now time.Time) {
+	if dedupe == nil {
+		return
+	}
+	dedupe.mutex.Lock()
+	defer dedupe.mutex.Unlock()
+	queued := dedupe.queued[notifier]
+	delete(dedupe.queued, notifier)
+	if !delivered || len(queued) == 0 {
+		return
+	}
+	if dedupe.sent == nil {
+		dedupe.sent = map[string]time.Time{}
+	}
+	for _, fingerprint := range queued {
+		dedupe.sent[fingerprint] = now
+	}
+	dedupe.changed = true
+}
+
+// load merges the fingerprints persisted in the config map into the ones in memory.
+func (dedupe *notificationDedupe) load(reaper reaper) error {
+	if dedupe.sent == nil {
+		dedupe.sent = map[string]time.Time{}
+	}
+	if !reaper.apiCall(operationGet) {
+		return errAPIBudgetExhausted
+	}
+	ctx, cancel := reaper.apiContext()
+	defer cancel()
+	configMap, err := reaper.clientSet.CoreV1().ConfigMaps(dedupe.namespace).Get(ctx, dedupe.configMap, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	persisted, err := decodeFingerprints(configMap.Data[notificationDedupeKey])
+	if err != nil {
+		return err
+	}
+	for fingerprint, sent := range persisted {
+		if sent.After(dedupe.sent[fingerprint]) {
+			dedupe.sent[fingerprint] = sent
+		}
+	}
+	return nil
+}
+
+// save writes the fingerprints of the notifications sent within the window to the config map, when any were sent
+// during the cycle. Fingerprints written by other replicas in the meantime are kept.
+func (dedupe *notificationDedupe) save(reaper reaper, now time.Time) error {
+	if dedupe == nil {
+		return nil
+	}
+	dedupe.mutex.Lock()
+	defer dedupe.mutex.Unlock()
+	if !dedupe.changed {
+		return nil
+	}
+	if !reaper.apiCall(operationGet) {
+		return errAPIBudgetExhausted
+	}
+	ctx, cancel := reaper.apiContext()
+	defer cancel()
+	configMaps := reaper.clientSet.CoreV1().ConfigMaps(dedupe.namespace)
+	configMap, err := configMaps.Get(ctx, dedupe.configMap, metav1.GetOptions{})
+	exists := true
+	if errors.IsNotFound(err) {
+		exists = false
+		configMap = &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: dedupe.configMap, Namespace: dedupe.namespace}}
+	} else if err != nil {
+		return err
+	}
+	if persisted, err := decodeFingerprints(configMap.Data[notificationDedupeKey]); err == nil {
+		for fingerprint, sent := range persisted {
+			if sent.After(dedupe.sent[fingerprint]) {
+				dedupe.sent[fingerprint] = sent
+			}
+		}
+	}
+	dedupe.prune(now)
+	data, err := json.Marshal(dedupe.sent)
+	if err != nil {
+		return err
+	}
+	if configMap.Data == nil {
+		configMap.Data = map[string]string{}
+	}
+	configMap.Data[notificationDedupeKey] = string(data)
+	if exists {
+		if !reaper.apiCall(operationUpdate) {
+			return errAPIBudgetExhausted
+		}
+		_, err = configMaps.Update(ctx, configMap, metav1.UpdateOptions{})
+	} else {
+		if !reaper.apiCall(operationCreate) {
+			return errAPIBudgetExhausted
+		}
+		_, err = configMaps.Create(ctx, configMap, metav1.CreateOptions{})
+	}
+	if err == nil {
+		dedupe.changed = false
+	}
+	return err
+}
+
+// prune forgets the fingerprints of notifications sent before the window, and the oldest fingerprints beyond
+// maxNotificationFingerprints.
+func (dedupe *notificationDedupe) prune(now time.Time) {
+	var fingerprints []string
+	for fingerprint, sent := range dedupe.sent {
+		if now.Sub(sent) >= dedupe.window {
+			delete(dedupe.sent, fingerprint)
+			continue
+		}
+		fingerprints = append(fingerprints, fingerprint)
+	}
+	if len(fingerprints) <= maxNotificationFingerprints {
+		return
+	}
+	sort.Slice(fingerprints, func(i, j int) bool {
+		return dedupe.sent[fingerprints[i]].After(dedupe.sent[fingerprints[j]])
+	})
+	for _, fingerprint := range fingerprints[maxNotificationFingerprints:] {
+		delete(dedupe.sent, fingerprint)
+	}
+}
+
+func decodeFingerprints(data string) (map[string]time.Time, error) {
+	fingerprints := map[string]time.Time{}
+	if data == "" {
+		return fingerprints, nil
+	}
+	err := json.Unmarshal([]byte(data), &fingerprints)
+	return fingerprints, err
+}
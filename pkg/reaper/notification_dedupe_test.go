@@ -0,0 +1,126 @@
+package reaper
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func testDedupe() *notificationDedupe {
+	return &notificationDedupe{window: time.Hour, namespace: "reaper", configMap: "pod-reaper-notifications"}
+}
+
+func TestNotificationFingerprint(t *testing.T) {
+	pod := createTestPod("pod-1", "default", nil)
+	pod.UID = types.UID("uid-1")
+	assert.Equal(t, "uid-1/delete/false/chaos,duration", notificationFingerprint(pod, []string{"duration", "chaos"}, actionDelete, false))
+	assert.NotEqual(t,
+		notificationFingerprint(pod, []string{"chaos"}, actionDelete, true),
+		notificationFingerprint(pod, []string{"chaos"}, actionDelete, false))
+}
+
+func TestNotificationDedupe(t *testing.T) {
+	pod := createTestPod("pod-1", "default", nil)
+	pod.UID = types.UID("uid-1")
+
+	t.Run("suppresses repeated notifications", func(t *testing.T) {
+		var output bytes.Buffer
+		opts := minimalOptions("1.0")
+		opts.notifiers = []notifier{&recordNotifier{writer: &output}}
+		opts.notificationDedupe = testDedupe()
+		r := createTestReaper(opts)
+		r.cycleID = "cycle-1"
+		r.matchedRules = []string{"chaos"}
+
+		r.notify(pod, []string{"was flagged for chaos"})
+		r.notify(pod, []string{"was flagged for chaos"})
+		assert.Equal(t, 1, strings.Count(output.String(), "\n"))
+
+		r.matchedRules = []string{"duration"}
+		r.notify(pod, []string{"has been running for 2h"})
+		assert.Equal(t, 2, strings.Count(output.String(), "\n"))
+	})
+	t.Run("persists across restarts", func(t *testing.T) {
+		var output bytes.Buffer
+		opts := minimalOptions("1.0")
+		opts.notifiers = []notifier{&recordNotifier{writer: &output}}
+		opts.notificationDedupe = testDedupe()
+		r := createTestReaper(opts)
+		r.cycleID = "cycle-1"
+		r.notify(pod, []string{"reason"})
+		r.flushNotifiers()
+
+		configMap, err := r.clientSet.CoreV1().ConfigMaps("reaper").Get(context.TODO(), "pod-reaper-notifications", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Contains(t, configMap.Data[notificationDedupeKey], "uid-1")
+
+		// a restarted reaper starts with an empty dedupe
+		r.options.notificationDedupe = testDedupe()
+		r.cycleID = "cycle-2"
+		r.notify(pod, []string{"reason"})
+		assert.Equal(t, 1, strings.Count(output.String(), "\n"))
+	})
+	t.Run("failed notifications are sent again", func(t *testing.T) {
+		opts := minimalOptions("1.0")
+		opts.notifiers = []notifier{failingNotifier{}}
+		opts.notificationDedupe = testDedupe()
+		r := createTestReaper(opts)
+		r.cycleID = "cycle-1"
+		r.notify(pod, []string{"reason"})
+		assert.False(t, opts.notificationDedupe.suppress(r, notificationFingerprint(pod, nil, actionDelete, false), time.Now()))
+	})
+	t.Run("batched notifications count once flushed", func(t *testing.T) {
+		received := make(chan reapNotification, 1)
+		server := testWebhookServer(t, 0, received)
+		opts := minimalOptions("1.0")
+		opts.notifiers = []notifier{newWebhookNotifier(server.URL, time.Second, 0)}
+		opts.notificationDedupe = testDedupe()
+		r := createTestReaper(opts)
+		r.cycleID = "cycle-1"
+		fingerprint := notificationFingerprint(pod, nil, actionDelete, false)
+		r.notify(pod, []string{"reason"})
+		assert.False(t, opts.notificationDedupe.suppress(r, fingerprint, time.Now()))
+
+		r.flushNotifiers()
+		<-received
+		assert.True(t, opts.notificationDedupe.suppress(r, fingerprint, time.Now()))
+		configMap, err := r.clientSet.CoreV1().ConfigMaps("reaper").Get(context.TODO(), "pod-reaper-notifications", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Contains(t, configMap.Data[notificationDedupeKey], "uid-1")
+	})
+	t.Run("failed batches are sent again", func(t *testing.T) {
+		server := testWebhookServer(t, 1, nil)
+		opts := minimalOptions("1.0")
+		opts.notifiers = []notifier{newWebhookNotifier(server.URL, time.Second, 0)}
+		opts.notificationDedupe = testDedupe()
+		r := createTestReaper(opts)
+		r.cycleID = "cycle-1"
+		r.notify(pod, []string{"reason"})
+		r.flushNotifiers()
+
+		assert.False(t, opts.notificationDedupe.suppress(r, notificationFingerprint(pod, nil, actionDelete, false), time.Now()))
+		_, err := r.clientSet.CoreV1().ConfigMaps("reaper").Get(context.TODO(), "pod-reaper-notifications", metav1.GetOptions{})
+		assert.Error(t, err)
+	})
+	t.Run("window", func(t *testing.T) {
+		dedupe := testDedupe()
+		r := createTestReaper(minimalOptions("1.0"))
+		now := time.Now()
+		dedupe.markSent("fingerprint", now)
+		assert.True(t, dedupe.suppress(r, "fingerprint", now.Add(time.Minute)))
+		assert.False(t, dedupe.suppress(r, "fingerprint", now.Add(2*time.Hour)))
+		dedupe.prune(now.Add(2 * time.Hour))
+		assert.Empty(t, dedupe.sent)
+	})
+	t.Run("disabled", func(t *testing.T) {
+		var dedupe *notificationDedupe
+		assert.False(t, dedupe.suppress(reaper{}, "fingerprint", time.Now()))
+		assert.NoError(t, dedupe.save(reaper{}, time.Now()))
+	})
+}
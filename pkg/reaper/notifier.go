@@ -0,0 +1,133 @@
+package reaper
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+)
+
+// reapNotification describes a single reaped (or, in dry-run mode, would-be reaped) pod.
+type reapNotification struct {
+	Pod       string   `json:"pod"`
+	Namespace string   `json:"namespace"`
+	Reasons   []string `json:"reasons"`
+	// Rules names the rules that matched the pod
+	Rules     []string  `json:"rules,omitempty"`
+	Action    string    `json:"action"`
+	DryRun    bool      `json:"dryRun"`
+	Timestamp time.Time `json:"timestamp"`
+	CycleID   string    `json:"cycleId,omitempty"`
+}
+
+// notifier delivers reap notifications to an external system.
+type notifier interface {
+	// name identifies the notifier in log messages.
+	name() string
+	notify(notification reapNotification) error
+}
+
+// approvalRequester is implemented by notifiers that can ask for a cycle held by REQUIRE_APPROVAL to be approved.
+type approvalRequester interface {
+	requestApproval(cycleID string, pods int) error
+}
+
+// flusher is implemented by notifiers that batch notifications and deliver them at the end of each reap cycle. Delivery
+// is abandoned when ctx is cancelled.
+type flusher interface {
+	flush(ctx context.Context) error
+}
+
+func newReapNotification(pod v1.Pod, reasons []string, action string, dryRun bool) reapNotification {
+	return reapNotification{
+		Pod:       pod.Name,
+		Namespace: pod.Namespace,
+		Reasons:   reasons,
+		Action:    action,
+		DryRun:    dryRun,
+		Timestamp: time.Now().UTC(),
+	}
+}
+
+// notify sends the notification to every configured notifier. Failures are logged but never interrupt the reap cycle.
+func (reaper reaper) notify(pod v1.Pod, reasons []string) {
+	if len(reaper.options.notifiers) == 0 {
+		return
+	}
+	dedupe := reaper.options.notificationDedupe
+	fingerprint := notificationFingerprint(pod, reaper.matchedRules, reaper.options.action, reaper.options.dryRun)
+	if dedupe.suppress(reaper, fingerprint, time.Now()) {
+		logrus.WithFields(reaper.decisionFields(pod, reasons)).Debug("notification was already sent")
+		return
+	}
+	notification := newReapNotification(pod, reasons, reaper.options.action, reaper.options.dryRun)
+	notification.CycleID = reaper.cycleID
+	notification.Rules = reaper.matchedRules
+	sent := false
+	for _, notifier := range reaper.options.notifiers {
+		if err := notifier.notify(notification); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"pod":      pod.Name,
+				"notifier": notifier.name(),
+			}).WithError(err).Warn("unable to send reap notification")
+			reaper.result.addError(cycleError{
+				Operation: "notify",
+				Namespace: pod.Namespace,
+				Pod:       pod.Name,
+				Target:    notifier.name(),
+				Message:   err.Error(),
+			})
+			continue
+		}
+		if _, batches := notifier.(flusher); batches {
+			// batched notifications are only sent once the notifier is flushed
+			dedupe.markQueued(notifier.name(), fingerprint)
+			continue
+		}
+		sent = true
+	}
+	if sent {
+		dedupe.markSent(fingerprint, time.Now())
+	}
+}
+
+// requestApproval asks every notifier that supports it to approve the cycle.
+func (reaper reaper) requestApproval(pods int) {
+	for _, notifier := range reaper.options.notifiers {
+		requester, ok := notifier.(approvalRequester)
+		if !ok {
+			continue
+		}
+		if err := requester.requestApproval(reaper.cycleID, pods); err != nil {
+			logrus.WithField("notifier", notifier.name()).WithError(err).Warn("unable to request cycle approval")
+			reaper.result.addError(cycleError{Operation: "request approval", Target: notifier.name(), Message: err.Error()})
+		}
+	}
+}
+
+// flushNotifiers delivers any notifications batched during the reap cycle, and then persists the notifications sent
+// for NOTIFICATION_DEDUPE_WINDOW. Batched notifications only count as sent when their notifier flushed successfully.
+func (reaper reaper) flushNotifiers() {
+	dedupe := reaper.options.notificationDedupe
+	ctx := reaper.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	for _, notifier := range reaper.options.notifiers {
+		flusher, ok := notifier.(flusher)
+		if !ok {
+			continue
+		}
+		err := flusher.flush(ctx)
+		dedupe.markFlushed(notifier.name(), err == nil, time.Now())
+		if err != nil {
+			logrus.WithField("notifier", notifier.name()).WithError(err).Warn("unable to send reap notifications")
+			reaper.result.addError(cycleError{Operation: "notify", Target: notifier.name(), Message: err.Error()})
+		}
+	}
+	if err := dedupe.save(reaper, time.Now()); err != nil {
+		logrus.WithField("configMap", dedupe.configMap).WithError(err).Warn("unable to persist sent notifications")
+		reaper.result.addError(cycleError{Operation: "notify", Target: "dedupe", Message: err.Error()})
+	}
+}
@@ -0,0 +1,1912 @@
+package reaper
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	v1 "k8s.io/api/core/v1"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+
+	"github.com/target/pod-reaper/rules"
+)
+
+// environment variable names
+const envNamespace = "NAMESPACE"
+const envNamespaces = "NAMESPACES"
+const envGracePeriod = "GRACE_PERIOD"
+const envGraceEscalationWindow = "GRACE_ESCALATION_WINDOW"
+const envGracePeriodFloor = "GRACE_PERIOD_FLOOR"
+const envScheduleCron = "SCHEDULE"
+const envScheduleTimezone = "SCHEDULE_TIMEZONE"
+const envScheduleJitter = "SCHEDULE_JITTER"
+const envBlackoutSchedule = "BLACKOUT_SCHEDULE"
+const envChaosWindow = "CHAOS_WINDOW"
+const envChaosVerifyTimeout = "CHAOS_VERIFY_TIMEOUT"
+const envAlertmanagerURL = "ALERTMANAGER_URL"
+const envRunDuration = "RUN_DURATION"
+const envRunOnce = "RUN_ONCE"
+const envShutdownTimeout = "SHUTDOWN_TIMEOUT"
+const envAPITimeout = "API_TIMEOUT"
+const envAPIRetries = "API_RETRIES"
+const envAPIRetryBackoff = "API_RETRY_BACKOFF"
+const envExcludeLabelKey = "EXCLUDE_LABEL_KEY"
+const envExcludeLabelValues = "EXCLUDE_LABEL_VALUES"
+const envRequireLabelKey = "REQUIRE_LABEL_KEY"
+const envRequireLabelValues = "REQUIRE_LABEL_VALUES"
+const envRequireLabels = "REQUIRE_LABELS"
+const envExcludeLabels = "EXCLUDE_LABELS"
+const envRequireAnnotationKey = "REQUIRE_ANNOTATION_KEY"
+const envRequireAnnotationValues = "REQUIRE_ANNOTATION_VALUES"
+const envAnnotationSelector = "ANNOTATION_SELECTOR"
+const envFieldSelector = "FIELD_SELECTOR"
+const envDryRun = "DRY_RUN"
+const envMaxPods = "MAX_PODS"
+const envMaxPodsPercent = "MAX_PODS_PERCENT"
+const envMaxPodsPerNamespace = "MAX_PODS_PER_NAMESPACE"
+const envWorkloadCooldown = "WORKLOAD_COOLDOWN"
+const envMaxPodsPercentOf = "MAX_PODS_PERCENT_OF"
+const envReapInterval = "REAP_INTERVAL"
+const envReapConcurrency = "REAP_CONCURRENCY"
+const envMarkGrace = "MARK_GRACE"
+const envAPICallBudget = "API_CALL_BUDGET"
+const envListPageSize = "LIST_PAGE_SIZE"
+const envListConsistency = "LIST_CONSISTENCY"
+const envMetricsAddress = "METRICS_ADDRESS"
+const envPodSortingStrategy = "POD_SORTING_STRATEGY"
+const envRandomSeed = "RANDOM_SEED"
+const envEvict = "EVICT"
+const envAction = "ACTION"
+const envForceDeleteStuck = "FORCE_DELETE_STUCK"
+const envRespectTopologySpread = "RESPECT_TOPOLOGY_SPREAD"
+const envUseInformer = "USE_INFORMER"
+const envEmitEvents = "EMIT_EVENTS"
+const envEmitSkipEvents = "EMIT_SKIP_EVENTS"
+const envNamespaceRules = "NAMESPACE_RULES"
+const envNamespaceReports = "NAMESPACE_REPORTS"
+const envDryRunReport = "DRY_RUN_REPORT"
+const envDryRunReportFormat = "DRY_RUN_REPORT_FORMAT"
+const envReapWebhookURL = "REAP_WEBHOOK_URL"
+const envReapWebhookTimeout = "REAP_WEBHOOK_TIMEOUT"
+const envReapWebhookRetries = "REAP_WEBHOOK_RETRIES"
+const envReapRecords = "REAP_RECORDS"
+const envAuditSink = "AUDIT_SINK"
+const envAuditFileMaxSize = "AUDIT_FILE_MAX_SIZE"
+const envAuditFileMaxBackups = "AUDIT_FILE_MAX_BACKUPS"
+const envAuditConfigMapMaxRecords = "AUDIT_CONFIGMAP_MAX_RECORDS"
+const envWarehouseExport = "WAREHOUSE_EXPORT"
+const envWarehouseBatchSize = "WAREHOUSE_BATCH_SIZE"
+const envWarehouseFlushInterval = "WAREHOUSE_FLUSH_INTERVAL"
+const envElasticsearchURL = "ELASTICSEARCH_URL"
+const envElasticsearchIndex = "ELASTICSEARCH_INDEX"
+const envElasticsearchUsername = "ELASTICSEARCH_USERNAME"
+const envElasticsearchPassword = "ELASTICSEARCH_PASSWORD"
+const envElasticsearchAPIKey = "ELASTICSEARCH_API_KEY"
+const envSlackWebhookURL = "SLACK_WEBHOOK_URL"
+const envSlackChannel = "SLACK_CHANNEL"
+const envSlackTemplate = "SLACK_TEMPLATE"
+const envSlackSummary = "SLACK_SUMMARY"
+const envNotificationDedupeWindow = "NOTIFICATION_DEDUPE_WINDOW"
+const envVerdictAnnotations = "VERDICT_ANNOTATIONS"
+const envAnnotateOwners = "ANNOTATE_OWNERS"
+const envProtectJobBackoff = "PROTECT_JOB_BACKOFF"
+const envMinReadyReplicas = "MIN_READY_REPLICAS"
+const envVerdictAnnotationInterval = "VERDICT_ANNOTATION_INTERVAL"
+const envProfile = "PROFILE"
+const envOwnerKinds = "OWNER_KINDS"
+const envHealthAddress = "HEALTH_ADDRESS"
+const envLeaderElection = "LEADER_ELECTION"
+const envLeaderElectionID = "LEADER_ELECTION_ID"
+const envLeaderElectionNamespace = "LEADER_ELECTION_NAMESPACE"
+const envLeaseDuration = "LEASE_DURATION"
+const envLeaseRenewDeadline = "LEASE_RENEW_DEADLINE"
+const envLeaseRetryPeriod = "LEASE_RETRY_PERIOD"
+
+// the namespace of the pod-reaper pod, used for the lease when LEADER_ELECTION_NAMESPACE is not set
+const serviceAccountNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+const envLivenessGracePeriod = "LIVENESS_GRACE_PERIOD"
+const envReadinessFailureThreshold = "READINESS_FAILURE_THRESHOLD"
+const envExcludeOwnerKinds = "EXCLUDE_OWNER_KINDS"
+const envMinPodAge = "MIN_POD_AGE"
+const envIgnoreDaemonSetPods = "IGNORE_DAEMONSET_PODS"
+const envNodeName = "NODE_NAME"
+const envNodeSelector = "NODE_SELECTOR"
+const envClientQPS = "CLIENT_QPS"
+const envClientBurst = "CLIENT_BURST"
+const envAdminAddress = "ADMIN_ADDRESS"
+const envRequireApproval = "REQUIRE_APPROVAL"
+const envSlackSigningSecret = "SLACK_SIGNING_SECRET"
+const envAdminToken = "ADMIN_TOKEN"
+const envEventStream = "EVENT_STREAM"
+const envReapRequestLimit = "REAP_REQUEST_LIMIT"
+const envMetricsToken = "METRICS_TOKEN"
+const envTLSCertFile = "TLS_CERT_FILE"
+const envTLSKeyFile = "TLS_KEY_FILE"
+const envTLSClientCAFile = "TLS_CLIENT_CA_FILE"
+const envReaperPolicies = "REAPER_POLICIES"
+const envReaperPolicySyncInterval = "REAPER_POLICY_SYNC_INTERVAL"
+const envIUnderstandTheRisk = "I_UNDERSTAND_THE_RISK"
+
+// Options configure a Reaper. They are loaded from the environment variables documented in the README by LoadOptions.
+type Options struct {
+	namespace             string
+	namespaces            []string
+	gracePeriod           *int64
+	graceEscalationWindow time.Duration
+	gracePeriodFloor      time.Duration
+	schedule              string
+	scheduleLocation      *time.Location
+	scheduleJitter        time.Duration
+	blackout              windowSchedule
+	chaosWindow           windowSchedule
+	chaosVerifyTimeout    time.Duration
+	alertmanager          *alertmanager
+	runDuration           time.Duration
+	runOnce               bool
+	shutdownTimeout       time.Duration
+	apiTimeout            time.Duration
+	apiRetries            int
+	apiRetryBackoff       time.Duration
+	labelExclusion        *labels.Requirement
+	labelRequirement      *labels.Requirement
+	requireLabels         labels.Selector
+	excludeLabels         labels.Selector
+	annotationSelector    labels.Selector
+	fieldSelector         fields.Selector
+	ownerKinds            map[string]bool
+	nodeNames             map[string]bool
+	nodeSelector          labels.Selector
+	excludeOwnerKinds     map[string]bool
+	minPodAge             time.Duration
+	ignoreDaemonSetPods   bool
+	dryRun                bool
+	maxPods               int
+	maxPodsPercent        float64
+	maxPodsPerNamespace   int
+	workloadCooldown      time.Duration
+	maxPodsPercentOf      string
+	reapInterval          time.Duration
+	reapConcurrency       int
+	listPageSize          int
+	listConsistency       string
+	markGrace             time.Duration
+	apiCallBudget         int
+	metricsAddress        string
+	healthAddress         string
+	livenessGracePeriod   time.Duration
+	readinessThreshold    int
+	leaderElection        bool
+	leaderElectionID      string
+	leaderElectionNS      string
+	leaseDuration         time.Duration
+	leaseRenewDeadline    time.Duration
+	leaseRetryPeriod      time.Duration
+	podSortingStrategy    func([]v1.Pod)
+	rules                 rules.Rules
+	action                string
+	forceDeleteStuck      bool
+	respectTopologySpread bool
+	useInformer           bool
+	emitEvents            bool
+	emitSkipEvents        bool
+	namespaceRules        bool
+	namespaceReports      bool
+	dryRunReport          string
+	dryRunReportFormat    string
+	verdictAnnotations    bool
+	protectJobBackoff     bool
+	minReadyReplicas      *int32
+	annotateOwners        bool
+	verdictInterval       time.Duration
+	notifiers             []notifier
+	notificationDedupe    *notificationDedupe
+	audit                 *auditLog
+	warehouse             *warehouseExport
+	clientQPS             float32
+	clientBurst           int
+	adminAddress          string
+	requireApproval       bool
+	slackSigningSecret    string
+	adminToken            string
+	eventStream           bool
+	reapRequestLimit      int
+	metricsToken          string
+	tlsConfig             *tls.Config
+	reaperPolicies        bool
+	policySyncInterval    time.Duration
+	reapers               []reaperPolicy
+}
+
+// Option changes one of the Options, so that programs using pod-reaper as a library can configure it without
+// environment variables. Options given to LoadOptions override the values loaded from the environment.
+type Option func(*Options)
+
+// WithNamespaces reaps only the pods of the namespaces, or of every namespace when none are given.
+func WithNamespaces(namespaces ...string) Option {
+	return func(options *Options) {
+		options.namespace = ""
+		options.namespaces = splitNamespaces(strings.Join(namespaces, ","))
+	}
+}
+
+// WithDryRun logs the pods that would be reaped instead of reaping them.
+func WithDryRun(dryRun bool) Option {
+	return func(options *Options) {
+		options.dryRun = dryRun
+	}
+}
+
+// WithMaxPods limits the number of pods reaped in each cycle, 0 for no limit.
+func WithMaxPods(maxPods int) Option {
+	return func(options *Options) {
+		options.maxPods = max(maxPods, 0)
+	}
+}
+
+// WithAPICallBudget limits the number of kubernetes API calls made in each cycle, 0 for no limit.
+func WithAPICallBudget(budget int) Option {
+	return func(options *Options) {
+		options.apiCallBudget = max(budget, 0)
+	}
+}
+
+// WithClientRateLimit limits the rate of requests to the kubernetes API server, 0 to keep the client-go defaults.
+func WithClientRateLimit(qps float32, burst int) Option {
+	return func(options *Options) {
+		options.clientQPS = max(qps, 0)
+		options.clientBurst = max(burst, 0)
+	}
+}
+
+// WithListPageSize lists the pods of a namespace in pages of at most size pods, 0 to list them all at once.
+func WithListPageSize(size int) Option {
+	return func(options *Options) {
+		options.listPageSize = max(size, 0)
+	}
+}
+
+func namespace() string {
+	return os.Getenv(envNamespace)
+}
+
+func namespaces() ([]string, error) {
+	value, exists := os.LookupEnv(envNamespaces)
+	if !exists {
+		return nil, nil
+	}
+	if _, namespaceExists := os.LookupEnv(envNamespace); namespaceExists {
+		return nil, fmt.Errorf("specify only one of %s and %s", envNamespace, envNamespaces)
+	}
+	namespaces := splitNamespaces(value)
+	if len(namespaces) == 0 {
+		return nil, fmt.Errorf("%s must contain at least one namespace", envNamespaces)
+	}
+	return namespaces, nil
+}
+
+// splitNamespaces splits comma-separated namespaces, ignoring whitespace, empty values, and duplicates.
+func splitNamespaces(value string) []string {
+	var namespaces []string
+	seen := map[string]bool{}
+	for _, namespace := range strings.Split(value, ",") {
+		namespace = strings.TrimSpace(namespace)
+		if namespace == "" || seen[namespace] {
+			continue
+		}
+		seen[namespace] = true
+		namespaces = append(namespaces, namespace)
+	}
+	return namespaces
+}
+
+func gracePeriod() (*int64, error) {
+	envGraceDuration, exists := os.LookupEnv(envGracePeriod)
+	if !exists {
+		return nil, nil
+	}
+	duration, err := time.ParseDuration(envGraceDuration)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %s", envGracePeriod, err)
+	}
+	seconds := int64(duration.Seconds())
+	return &seconds, nil
+}
+
+func graceEscalationOptions() (window time.Duration, floor time.Duration, err error) {
+	if window, err = envDuration(envGraceEscalationWindow, "0s"); err != nil {
+		return window, floor, err
+	}
+	if window < 0 {
+		return window, floor, fmt.Errorf("invalid %s: must not be negative", envGraceEscalationWindow)
+	}
+	if floor, err = envDuration(envGracePeriodFloor, "0s"); err != nil {
+		return window, floor, err
+	}
+	if floor < 0 {
+		return window, floor, fmt.Errorf("invalid %s: must not be negative", envGracePeriodFloor)
+	}
+	return window, floor, nil
+}
+
+func envDuration(key string, defValue string) (time.Duration, error) {
+	envDuration, exists := os.LookupEnv(key)
+	if !exists {
+		envDuration = defValue
+	}
+	duration, err := time.ParseDuration(envDuration)
+	if err != nil {
+		return duration, fmt.Errorf("invalid %s: %s", key, err)
+	}
+	return duration, nil
+}
+
+func schedule() string {
+	schedule, exists := os.LookupEnv(envScheduleCron)
+	if !exists {
+		schedule = "@every 1m"
+	}
+	return schedule
+}
+
+func scheduleTimezone() (*time.Location, error) {
+	value, exists := os.LookupEnv(envScheduleTimezone)
+	if !exists {
+		return nil, nil
+	}
+	location, err := time.LoadLocation(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %s", envScheduleTimezone, err)
+	}
+	return location, nil
+}
+
+func scheduleJitter() (time.Duration, error) {
+	jitter, err := envDuration(envScheduleJitter, "0s")
+	if err == nil && jitter < 0 {
+		err = fmt.Errorf("invalid %s: must not be negative", envScheduleJitter)
+	}
+	return jitter, err
+}
+
+func blackout() (windowSchedule, error) {
+	value, exists := os.LookupEnv(envBlackoutSchedule)
+	if !exists {
+		return nil, nil
+	}
+	schedule, err := parseWindowSchedule(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %s", envBlackoutSchedule, err)
+	}
+	return schedule, nil
+}
+
+func chaosWindow() (windowSchedule, time.Duration, error) {
+	value, exists := os.LookupEnv(envChaosWindow)
+	if !exists {
+		return nil, 0, nil
+	}
+	windows, err := parseWindowSchedule(value)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid %s: %s", envChaosWindow, err)
+	}
+	timeout, err := envDuration(envChaosVerifyTimeout, "10m")
+	if err != nil {
+		return nil, 0, err
+	}
+	if timeout <= 0 {
+		return nil, 0, fmt.Errorf("invalid %s: must be positive", envChaosVerifyTimeout)
+	}
+	return windows, timeout, nil
+}
+
+func alertmanagerURL() (*alertmanager, error) {
+	value, exists := os.LookupEnv(envAlertmanagerURL)
+	if !exists {
+		return nil, nil
+	}
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %s", envAlertmanagerURL, err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return nil, fmt.Errorf("invalid %s: must be an http or https url", envAlertmanagerURL)
+	}
+	timeout, err := time.ParseDuration(defaultNotifyTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return &alertmanager{
+		url:        value,
+		client:     &http.Client{Timeout: timeout},
+		retries:    defaultNotifyRetries,
+		retryDelay: time.Second,
+	}, nil
+}
+
+func runDuration() (time.Duration, error) {
+	return envDuration(envRunDuration, "0s")
+}
+
+func runOnce() (bool, error) {
+	value, exists := os.LookupEnv(envRunOnce)
+	if !exists {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+func shutdownTimeout() (time.Duration, error) {
+	// kubernetes kills pods 30 seconds after asking them to terminate by default
+	timeout, err := envDuration(envShutdownTimeout, "25s")
+	if err == nil && timeout < 0 {
+		err = fmt.Errorf("invalid %s: must not be negative", envShutdownTimeout)
+	}
+	return timeout, err
+}
+
+func apiTimeout() (time.Duration, error) {
+	timeout, err := envDuration(envAPITimeout, "30s")
+	if err == nil && timeout < 0 {
+		err = fmt.Errorf("invalid %s: must not be negative", envAPITimeout)
+	}
+	return timeout, err
+}
+
+func apiRetries() (int, error) {
+	value, exists := os.LookupEnv(envAPIRetries)
+	if !exists {
+		return 3, nil
+	}
+	retries, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %s", envAPIRetries, err)
+	}
+	if retries < 0 {
+		return 0, fmt.Errorf("invalid %s: must not be negative", envAPIRetries)
+	}
+	return retries, nil
+}
+
+func apiRetryBackoff() (time.Duration, error) {
+	backoff, err := envDuration(envAPIRetryBackoff, "500ms")
+	if err == nil && backoff < 0 {
+		err = fmt.Errorf("invalid %s: must not be negative", envAPIRetryBackoff)
+	}
+	return backoff, err
+}
+
+func labelExclusion() (*labels.Requirement, error) {
+	labelKey, labelKeyExists := os.LookupEnv(envExcludeLabelKey)
+	labelValue, labelValuesExist := os.LookupEnv(envExcludeLabelValues)
+	if labelKeyExists && !labelValuesExist {
+		return nil, fmt.Errorf("specified %s but not %s", envExcludeLabelKey, envExcludeLabelValues)
+	} else if !labelKeyExists && labelValuesExist {
+		return nil, fmt.Errorf("did not specify %s but did specify %s", envExcludeLabelKey, envExcludeLabelValues)
+	} else if !labelKeyExists && !labelValuesExist {
+		return nil, nil
+	}
+	labelValues := strings.Split(labelValue, ",")
+	labelExclusion, err := labels.NewRequirement(labelKey, selection.NotIn, labelValues)
+	if err != nil {
+		return nil, fmt.Errorf("could not create exclusion label: %s", err)
+	}
+	return labelExclusion, nil
+}
+
+func labelRequirement() (*labels.Requirement, error) {
+	labelKey, labelKeyExists := os.LookupEnv(envRequireLabelKey)
+	labelValue, labelValuesExist := os.LookupEnv(envRequireLabelValues)
+	if labelKeyExists && !labelValuesExist {
+		return nil, fmt.Errorf("specified %s but not %s", envRequireLabelKey, envRequireLabelValues)
+	} else if !labelKeyExists && labelValuesExist {
+		return nil, fmt.Errorf("did not specify %s but did specify %s", envRequireLabelKey, envRequireLabelValues)
+	} else if !labelKeyExists && !labelValuesExist {
+		return nil, nil
+	}
+	labelValues := strings.Split(labelValue, ",")
+	labelRequirement, err := labels.NewRequirement(labelKey, selection.In, labelValues)
+	if err != nil {
+		return nil, fmt.Errorf("could not create requirement label: %s", err)
+	}
+	return labelRequirement, nil
+}
+
+// requireLabels parses REQUIRE_LABELS, a label selector that pods must match in order to be reaped.
+func requireLabels() (labels.Selector, error) {
+	return podLabelSelector(envRequireLabels)
+}
+
+// excludeLabels parses EXCLUDE_LABELS, a label selector of the pods that are never reaped.
+func excludeLabels() (labels.Selector, error) {
+	return podLabelSelector(envExcludeLabels)
+}
+
+func podLabelSelector(env string) (labels.Selector, error) {
+	value, exists := os.LookupEnv(env)
+	if !exists {
+		return nil, nil
+	}
+	selector, err := labels.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %s", env, err)
+	}
+	if selector.Empty() {
+		return nil, fmt.Errorf("%s must select pods by at least one label", env)
+	}
+	return selector, nil
+}
+
+func annotationRequirement() (*labels.Requirement, error) {
+	annotationKey, annotationKeyExists := os.LookupEnv(envRequireAnnotationKey)
+	annotationValue, annotationValuesExist := os.LookupEnv(envRequireAnnotationValues)
+	if annotationKeyExists && !annotationValuesExist {
+		return nil, fmt.Errorf("specified %s but not %s", envRequireAnnotationKey, envRequireAnnotationValues)
+	} else if !annotationKeyExists && annotationValuesExist {
+		return nil, fmt.Errorf("did not specify %s but did specify %s", envRequireAnnotationKey, envRequireAnnotationValues)
+	} else if !annotationKeyExists && !annotationValuesExist {
+		return nil, nil
+	}
+	annotationValues := strings.Split(annotationValue, ",")
+	annotationRequirement, err := labels.NewRequirement(annotationKey, selection.In, annotationValues)
+	if err != nil {
+		return nil, fmt.Errorf("could not create annotation requirement: %s", err)
+	}
+	return annotationRequirement, nil
+}
+
+// annotationSelector combines the ANNOTATION_SELECTOR, parsed with the label selector syntax, with the
+// REQUIRE_ANNOTATION_KEY requirement. Pods are only reaped if their annotations match every requirement.
+func annotationSelector() (labels.Selector, error) {
+	requirement, err := annotationRequirement()
+	if err != nil {
+		return nil, err
+	}
+	selector := labels.NewSelector()
+	if value, exists := os.LookupEnv(envAnnotationSelector); exists {
+		if selector, err = labels.Parse(value); err != nil {
+			return nil, fmt.Errorf("invalid %s: %s", envAnnotationSelector, err)
+		}
+		if selector.Empty() {
+			return nil, fmt.Errorf("%s must select pods by at least one annotation", envAnnotationSelector)
+		}
+	}
+	if requirement != nil {
+		selector = selector.Add(*requirement)
+	}
+	if selector.Empty() {
+		return nil, nil
+	}
+	return selector, nil
+}
+
+func fieldSelector() (fields.Selector, error) {
+	value, exists := os.LookupEnv(envFieldSelector)
+	if !exists {
+		return nil, nil
+	}
+	selector, err := fields.ParseSelector(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %s", envFieldSelector, err)
+	}
+	if selector.Empty() {
+		return nil, fmt.Errorf("%s must select pods by at least one field", envFieldSelector)
+	}
+	return selector, nil
+}
+
+// ignoreDaemonSetPods defaults to true, unless OWNER_KINDS asks for the pods of daemon sets.
+func ignoreDaemonSetPods(ownerKinds map[string]bool) (bool, error) {
+	value, exists := os.LookupEnv(envIgnoreDaemonSetPods)
+	if !exists {
+		return !ownerKinds[daemonSetKind], nil
+	}
+	ignore, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %s", envIgnoreDaemonSetPods, err)
+	}
+	if ignore && ownerKinds[daemonSetKind] {
+		return false, fmt.Errorf("%s cannot include %s when %s is true", envOwnerKinds, daemonSetKind, envIgnoreDaemonSetPods)
+	}
+	return ignore, nil
+}
+
+func minPodAge() (time.Duration, error) {
+	age, err := envDuration(envMinPodAge, "0s")
+	if err == nil && age < 0 {
+		err = fmt.Errorf("invalid %s: must not be negative", envMinPodAge)
+	}
+	return age, err
+}
+
+func ownerKinds() (map[string]bool, map[string]bool, error) {
+	value, exists := os.LookupEnv(envOwnerKinds)
+	excludeValue, excludeExists := os.LookupEnv(envExcludeOwnerKinds)
+	if exists && excludeExists {
+		return nil, nil, fmt.Errorf("specify only one of %s and %s", envOwnerKinds, envExcludeOwnerKinds)
+	}
+	if exists {
+		kinds, err := kindSet(envOwnerKinds, value)
+		return kinds, nil, err
+	}
+	if excludeExists {
+		kinds, err := kindSet(envExcludeOwnerKinds, excludeValue)
+		return nil, kinds, err
+	}
+	return nil, nil, nil
+}
+
+func kindSet(key string, value string) (map[string]bool, error) {
+	kinds := map[string]bool{}
+	for _, kind := range strings.Split(value, ",") {
+		if kind = strings.TrimSpace(kind); kind != "" {
+			kinds[kind] = true
+		}
+	}
+	if len(kinds) == 0 {
+		return nil, fmt.Errorf("%s must contain at least one kind", key)
+	}
+	return kinds, nil
+}
+
+func nodeNames() (map[string]bool, error) {
+	value, exists := os.LookupEnv(envNodeName)
+	if !exists {
+		return nil, nil
+	}
+	names := map[string]bool{}
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names[name] = true
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("%s must contain at least one node name", envNodeName)
+	}
+	return names, nil
+}
+
+func nodeSelector() (labels.Selector, error) {
+	value, exists := os.LookupEnv(envNodeSelector)
+	if !exists {
+		return nil, nil
+	}
+	selector, err := labels.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %s", envNodeSelector, err)
+	}
+	if selector.Empty() {
+		return nil, fmt.Errorf("%s must select nodes by at least one label", envNodeSelector)
+	}
+	return selector, nil
+}
+
+func dryRun() (bool, error) {
+	value, exists := os.LookupEnv(envDryRun)
+	if !exists {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+func namespaceReports() (bool, error) {
+	value, exists := os.LookupEnv(envNamespaceReports)
+	if !exists {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+func dryRunReport() string {
+	return os.Getenv(envDryRunReport)
+}
+
+func dryRunReportFormat() (string, error) {
+	format, exists := os.LookupEnv(envDryRunReportFormat)
+	if !exists {
+		return reportFormatJSON, nil
+	}
+	switch format {
+	case reportFormatJSON, reportFormatDiff:
+		return format, nil
+	default:
+		return "", fmt.Errorf("invalid %s %q, must be %s or %s", envDryRunReportFormat, format, reportFormatJSON, reportFormatDiff)
+	}
+}
+
+func maxPods() (int, error) {
+	value, exists := os.LookupEnv(envMaxPods)
+	if !exists {
+		return 0, nil
+	}
+
+	v, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, err
+	}
+
+	if v < 0 {
+		return 0, nil
+	}
+
+	return v, nil
+}
+
+func maxPodsPerNamespace() (int, error) {
+	value, exists := os.LookupEnv(envMaxPodsPerNamespace)
+	if !exists {
+		return 0, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %s", envMaxPodsPerNamespace, err)
+	}
+	if limit < 0 {
+		return 0, fmt.Errorf("invalid %s: must not be negative", envMaxPodsPerNamespace)
+	}
+	return limit, nil
+}
+
+func workloadCooldown() (time.Duration, error) {
+	cooldown, err := envDuration(envWorkloadCooldown, "0s")
+	if err == nil && cooldown < 0 {
+		err = fmt.Errorf("invalid %s: must not be negative", envWorkloadCooldown)
+	}
+	return cooldown, err
+}
+
+// MAX_PODS_PERCENT is a percentage of the pods listed in a cycle, or of those matching every rule
+const maxPodsPercentOfListed = "listed"
+const maxPodsPercentOfMatched = "matched"
+
+func maxPodsPercent() (float64, string, error) {
+	value, exists := os.LookupEnv(envMaxPodsPercent)
+	if !exists {
+		if _, exists := os.LookupEnv(envMaxPodsPercentOf); exists {
+			return 0, "", fmt.Errorf("specified %s but not %s", envMaxPodsPercentOf, envMaxPodsPercent)
+		}
+		return 0, "", nil
+	}
+	percent, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid %s: %s", envMaxPodsPercent, err)
+	}
+	if percent <= 0 || percent > 100 {
+		return 0, "", fmt.Errorf("invalid %s: must be above 0 and at most 100", envMaxPodsPercent)
+	}
+	of, exists := os.LookupEnv(envMaxPodsPercentOf)
+	if !exists {
+		of = maxPodsPercentOfListed
+	}
+	if of != maxPodsPercentOfListed && of != maxPodsPercentOfMatched {
+		return 0, "", fmt.Errorf("invalid %s: must be %s or %s", envMaxPodsPercentOf, maxPodsPercentOfListed, maxPodsPercentOfMatched)
+	}
+	return percent, of, nil
+}
+
+func reapInterval() (time.Duration, error) {
+	interval, err := envDuration(envReapInterval, "0s")
+	if err != nil {
+		return 0, err
+	}
+	if interval < 0 {
+		return 0, fmt.Errorf("invalid %s: must not be negative", envReapInterval)
+	}
+	return interval, nil
+}
+
+func listPageSize() (int, error) {
+	value, exists := os.LookupEnv(envListPageSize)
+	if !exists {
+		return 500, nil
+	}
+	size, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %s", envListPageSize, err)
+	}
+	if size < 0 {
+		return 0, fmt.Errorf("invalid %s: must not be negative", envListPageSize)
+	}
+	return size, nil
+}
+
+// with LIST_CONSISTENCY=strong, pods are listed with a quorum read from etcd; with cached, they are served from the API
+// server's watch cache, which may be slightly stale but is much cheaper in large clusters
+const listConsistencyStrong = "strong"
+const listConsistencyCached = "cached"
+
+func listConsistency() (string, error) {
+	value, exists := os.LookupEnv(envListConsistency)
+	if !exists {
+		return listConsistencyStrong, nil
+	}
+	switch value {
+	case listConsistencyStrong, listConsistencyCached:
+		return value, nil
+	}
+	return "", fmt.Errorf("invalid %s: must be %s or %s", envListConsistency, listConsistencyStrong, listConsistencyCached)
+}
+
+func reapConcurrency(action string) (int, error) {
+	value, exists := os.LookupEnv(envReapConcurrency)
+	if !exists {
+		return 1, nil
+	}
+	concurrency, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %s", envReapConcurrency, err)
+	}
+	if concurrency < 1 {
+		return 0, fmt.Errorf("invalid %s: must be at least 1", envReapConcurrency)
+	}
+	if concurrency > 1 && action == actionScaleOwner {
+		// scaling the same owner down from several goroutines would race
+		return 0, fmt.Errorf("%s cannot be used with %s=%s", envReapConcurrency, envAction, action)
+	}
+	return concurrency, nil
+}
+
+func markGrace(action string) (time.Duration, error) {
+	grace, err := envDuration(envMarkGrace, "0s")
+	if err != nil {
+		return 0, err
+	}
+	if grace < 0 {
+		return 0, fmt.Errorf("invalid %s: must not be negative", envMarkGrace)
+	}
+	if grace > 0 && (action == actionAnnotate || action == actionPreview) {
+		return 0, fmt.Errorf("%s cannot be used with %s=%s, which never reaps pods", envMarkGrace, envAction, action)
+	}
+	return grace, nil
+}
+
+func apiCallBudget() (int, error) {
+	value, exists := os.LookupEnv(envAPICallBudget)
+	if !exists {
+		return 0, nil
+	}
+	v, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %s", envAPICallBudget, err)
+	}
+	if v < 0 {
+		return 0, nil
+	}
+	return v, nil
+}
+
+func clientQPS() (float32, error) {
+	value, exists := os.LookupEnv(envClientQPS)
+	if !exists {
+		return 0, nil
+	}
+	v, err := strconv.ParseFloat(value, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %s", envClientQPS, err)
+	}
+	if v < 0 {
+		return 0, fmt.Errorf("invalid %s: must not be negative", envClientQPS)
+	}
+	return float32(v), nil
+}
+
+func clientBurst() (int, error) {
+	value, exists := os.LookupEnv(envClientBurst)
+	if !exists {
+		return 0, nil
+	}
+	v, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %s", envClientBurst, err)
+	}
+	if v < 0 {
+		return 0, fmt.Errorf("invalid %s: must not be negative", envClientBurst)
+	}
+	return v, nil
+}
+
+func metricsAddress() string {
+	return os.Getenv(envMetricsAddress)
+}
+
+func healthAddress() string {
+	return os.Getenv(envHealthAddress)
+}
+
+func adminAddress() string {
+	return os.Getenv(envAdminAddress)
+}
+
+func requireApproval(adminAddress string) (bool, error) {
+	value, exists := os.LookupEnv(envRequireApproval)
+	if !exists {
+		return false, nil
+	}
+	required, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, err
+	}
+	if required && adminAddress == "" {
+		return false, fmt.Errorf("%s requires %s, which serves the approval endpoints", envRequireApproval, envAdminAddress)
+	}
+	return required, nil
+}
+
+// secret returns the value of the environment variable, or the trimmed content of the file named by the variable with
+// a _FILE suffix, which is how kubernetes secrets are usually mounted.
+func secret(key string) (string, error) {
+	value := os.Getenv(key)
+	path := os.Getenv(key + "_FILE")
+	if path == "" {
+		return value, nil
+	}
+	if value != "" {
+		return "", fmt.Errorf("only one of %s and %s_FILE can be set", key, key)
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s_FILE: %s", key, err)
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+func slackSigningSecret(adminAddress string) (string, error) {
+	secret, err := secret(envSlackSigningSecret)
+	if err != nil {
+		return "", err
+	}
+	if secret != "" && adminAddress == "" {
+		return "", fmt.Errorf("%s requires %s, which serves the slack endpoints", envSlackSigningSecret, envAdminAddress)
+	}
+	return secret, nil
+}
+
+func adminToken(adminAddress string) (string, error) {
+	token, err := secret(envAdminToken)
+	if err != nil {
+		return "", err
+	}
+	if token != "" && adminAddress == "" {
+		return "", fmt.Errorf("%s requires %s, which serves the endpoints it protects", envAdminToken, envAdminAddress)
+	}
+	return token, nil
+}
+
+// adminAuthenticated reports whether clients of the admin address are authenticated, by ADMIN_TOKEN or by the client
+// certificates of TLS_CLIENT_CA_FILE.
+func (options Options) adminAuthenticated() bool {
+	return options.adminToken != "" || (options.tlsConfig != nil && options.tlsConfig.ClientAuth == tls.RequireAndVerifyClientCert)
+}
+
+// eventStreamEnabled is whether the admin address streams reap notifications at /events, which it does unless disabled.
+func eventStreamEnabled() (bool, error) {
+	value, exists := os.LookupEnv(envEventStream)
+	if !exists {
+		return true, nil
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %s", envEventStream, err)
+	}
+	return enabled, nil
+}
+
+// reapRequestLimit is the number of pods that can be reaped through reap requests per hour, where 0 disables reap
+// requests.
+func reapRequestLimit() (int, error) {
+	value, exists := os.LookupEnv(envReapRequestLimit)
+	if !exists {
+		return 10, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %s", envReapRequestLimit, err)
+	}
+	if limit < 0 {
+		return 0, fmt.Errorf("invalid %s: must not be negative", envReapRequestLimit)
+	}
+	return limit, nil
+}
+
+func metricsToken(metricsAddress string) (string, error) {
+	token, err := secret(envMetricsToken)
+	if err != nil {
+		return "", err
+	}
+	if token != "" && metricsAddress == "" {
+		return "", fmt.Errorf("%s requires %s, which serves the endpoint it protects", envMetricsToken, envMetricsAddress)
+	}
+	return token, nil
+}
+
+// serverTLS loads the certificate that the admin and metrics addresses are served with and, when a client CA is set,
+// requires clients to present a certificate signed by it.
+func serverTLS(metricsAddress string, adminAddress string, healthAddress string) (*tls.Config, error) {
+	certFile := os.Getenv(envTLSCertFile)
+	keyFile := os.Getenv(envTLSKeyFile)
+	clientCAFile := os.Getenv(envTLSClientCAFile)
+	if certFile == "" && keyFile == "" {
+		if clientCAFile != "" {
+			return nil, fmt.Errorf("%s requires %s and %s", envTLSClientCAFile, envTLSCertFile, envTLSKeyFile)
+		}
+		return nil, nil
+	}
+	if certFile == "" || keyFile == "" {
+		return nil, fmt.Errorf("%s and %s must be set together", envTLSCertFile, envTLSKeyFile)
+	}
+	if metricsAddress == "" && adminAddress == "" {
+		return nil, fmt.Errorf("%s requires %s or %s", envTLSCertFile, envMetricsAddress, envAdminAddress)
+	}
+	// kubelet probes cannot present client certificates, so health endpoints are always served without TLS
+	if healthAddress != "" && (healthAddress == metricsAddress || healthAddress == adminAddress) {
+		return nil, fmt.Errorf("%s must differ from %s and %s when serving TLS", envHealthAddress, envMetricsAddress, envAdminAddress)
+	}
+	return loadServerTLS(certFile, keyFile, clientCAFile)
+}
+
+func livenessGracePeriod() (time.Duration, error) {
+	return envDuration(envLivenessGracePeriod, "5m")
+}
+
+func readinessThreshold() (int, error) {
+	value, exists := os.LookupEnv(envReadinessFailureThreshold)
+	if !exists {
+		return 3, nil
+	}
+	v, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %s", envReadinessFailureThreshold, err)
+	}
+	if v < 1 {
+		return 0, fmt.Errorf("invalid %s: must be at least 1", envReadinessFailureThreshold)
+	}
+	return v, nil
+}
+
+func reaperPolicies() (bool, error) {
+	value, exists := os.LookupEnv(envReaperPolicies)
+	if !exists {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+func policySyncInterval() (time.Duration, error) {
+	interval, err := envDuration(envReaperPolicySyncInterval, "1m")
+	if err != nil {
+		return interval, err
+	}
+	if interval <= 0 {
+		return interval, fmt.Errorf("invalid %s: must be positive", envReaperPolicySyncInterval)
+	}
+	return interval, nil
+}
+
+func leaderElection() (bool, error) {
+	value, exists := os.LookupEnv(envLeaderElection)
+	if !exists {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+func leaderElectionID() string {
+	id, exists := os.LookupEnv(envLeaderElectionID)
+	if !exists || id == "" {
+		return "pod-reaper"
+	}
+	return id
+}
+
+func leaderElectionNamespace() string {
+	if namespace := os.Getenv(envLeaderElectionNamespace); namespace != "" {
+		return namespace
+	}
+	if namespace, err := ioutil.ReadFile(serviceAccountNamespaceFile); err == nil {
+		return strings.TrimSpace(string(namespace))
+	}
+	return "default"
+}
+
+func leaseTimings() (leaseDuration time.Duration, renewDeadline time.Duration, retryPeriod time.Duration, err error) {
+	if leaseDuration, err = envDuration(envLeaseDuration, "15s"); err != nil {
+		return
+	}
+	if renewDeadline, err = envDuration(envLeaseRenewDeadline, "10s"); err != nil {
+		return
+	}
+	if retryPeriod, err = envDuration(envLeaseRetryPeriod, "2s"); err != nil {
+		return
+	}
+	if leaseDuration <= renewDeadline || renewDeadline <= retryPeriod || retryPeriod <= 0 {
+		err = fmt.Errorf("%s must be greater than %s, which must be greater than %s, which must be positive",
+			envLeaseDuration, envLeaseRenewDeadline, envLeaseRetryPeriod)
+	}
+	return
+}
+
+func getPodDeletionCost(pod v1.Pod) int32 {
+	// https://kubernetes.io/docs/concepts/workloads/controllers/replicaset/#pod-deletion-cost
+	costString, present := pod.ObjectMeta.Annotations["controller.kubernetes.io/pod-deletion-cost"]
+	if !present {
+		return 0
+	}
+	// per k8s doc: invalid values should be rejected by the API server
+	cost, _ := strconv.ParseInt(costString, 10, 32)
+	return int32(cost)
+}
+
+func defaultSort([]v1.Pod) {}
+
+func randomSort(pods []v1.Pod) {
+	rand.Shuffle(len(pods), func(i, j int) { pods[i], pods[j] = pods[j], pods[i] })
+}
+
+// seededRandomSort returns a random sort that shuffles pods in the same order on every run with the same seed. Pods
+// are put in namespace and name order before shuffling, since the order pods are listed in is not guaranteed.
+func seededRandomSort(seed int64) func([]v1.Pod) {
+	var mutex sync.Mutex
+	random := rand.New(rand.NewSource(seed))
+	return func(pods []v1.Pod) {
+		sort.Slice(pods, func(i, j int) bool {
+			if pods[i].Namespace != pods[j].Namespace {
+				return pods[i].Namespace < pods[j].Namespace
+			}
+			return pods[i].Name < pods[j].Name
+		})
+		mutex.Lock()
+		defer mutex.Unlock()
+		random.Shuffle(len(pods), func(i, j int) { pods[i], pods[j] = pods[j], pods[i] })
+	}
+}
+
+func oldestFirstSort(pods []v1.Pod) {
+	sort.Slice(pods, func(i, j int) bool {
+		if pods[i].Status.StartTime == nil {
+			return false
+		}
+		if pods[j].Status.StartTime == nil {
+			return true
+		}
+		return pods[i].Status.StartTime.Unix() < pods[j].Status.StartTime.Unix()
+	})
+}
+
+func youngestFirstSort(pods []v1.Pod) {
+	sort.Slice(pods, func(i, j int) bool {
+		if pods[i].Status.StartTime == nil {
+			return false
+		}
+		if pods[j].Status.StartTime == nil {
+			return true
+		}
+		return pods[j].Status.StartTime.Unix() < pods[i].Status.StartTime.Unix()
+	})
+}
+
+func podDeletionCostSort(pods []v1.Pod) {
+	sort.Slice(pods, func(i, j int) bool {
+		return getPodDeletionCost(pods[i]) < getPodDeletionCost(pods[j])
+	})
+}
+
+// restartCount returns the total number of restarts of the pod's containers and init containers.
+func restartCount(pod v1.Pod) int32 {
+	var restarts int32
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		restarts += containerStatus.RestartCount
+	}
+	for _, containerStatus := range pod.Status.InitContainerStatuses {
+		restarts += containerStatus.RestartCount
+	}
+	return restarts
+}
+
+func mostRestartsFirstSort(pods []v1.Pod) {
+	sort.SliceStable(pods, func(i, j int) bool {
+		return restartCount(pods[i]) > restartCount(pods[j])
+	})
+}
+
+func podSortingStrategy() (func([]v1.Pod), error) {
+	sortingStrategy, present := os.LookupEnv(envPodSortingStrategy)
+	if !present {
+		return defaultSort, nil
+	}
+	switch sortingStrategy {
+	case "random":
+		seed, seeded := os.LookupEnv(envRandomSeed)
+		if !seeded {
+			return randomSort, nil
+		}
+		v, err := strconv.ParseInt(seed, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %s", envRandomSeed, err)
+		}
+		return seededRandomSort(v), nil
+	case "oldest-first":
+		return oldestFirstSort, nil
+	case "youngest-first":
+		return youngestFirstSort, nil
+	case "pod-deletion-cost":
+		return podDeletionCostSort, nil
+	case "most-restarts-first":
+		return mostRestartsFirstSort, nil
+	default:
+		return nil, errors.New("unknown pod sorting strategy")
+	}
+}
+
+func evict() (bool, error) {
+	value, exists := os.LookupEnv(envEvict)
+	if !exists {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+// action returns how matching pods are reaped. EVICT is still accepted as a shorthand for ACTION=evict.
+func action() (string, error) {
+	evict, err := evict()
+	if err != nil {
+		return "", err
+	}
+	value, exists := os.LookupEnv(envAction)
+	if !exists {
+		if evict {
+			return actionEvict, nil
+		}
+		return actionDelete, nil
+	}
+	switch value {
+	case actionDelete, actionEvict, actionAnnotate, actionScaleOwner, actionPreview:
+	default:
+		return "", fmt.Errorf("invalid %s: must be one of %s, %s, %s, %s, or %s", envAction, actionDelete, actionEvict, actionAnnotate, actionScaleOwner, actionPreview)
+	}
+	if evict && value != actionEvict {
+		return "", fmt.Errorf("%s conflicts with %s=%s", envEvict, envAction, value)
+	}
+	return value, nil
+}
+
+func forceDeleteStuck(action string) (bool, error) {
+	value, exists := os.LookupEnv(envForceDeleteStuck)
+	if !exists {
+		return false, nil
+	}
+	force, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %s", envForceDeleteStuck, err)
+	}
+	if force && action != actionDelete && action != actionEvict {
+		return false, fmt.Errorf("%s cannot be used with %s=%s, which never deletes pods", envForceDeleteStuck, envAction, action)
+	}
+	return force, nil
+}
+
+func respectTopologySpread() (bool, error) {
+	value, exists := os.LookupEnv(envRespectTopologySpread)
+	if !exists {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+func useInformer() (bool, error) {
+	value, exists := os.LookupEnv(envUseInformer)
+	if !exists {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+func emitEvents() (bool, error) {
+	value, exists := os.LookupEnv(envEmitEvents)
+	if !exists {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+func emitSkipEvents() (bool, error) {
+	value, exists := os.LookupEnv(envEmitSkipEvents)
+	if !exists {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+func namespaceRules() (bool, error) {
+	value, exists := os.LookupEnv(envNamespaceRules)
+	if !exists {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+func verdictAnnotations() (bool, error) {
+	value, exists := os.LookupEnv(envVerdictAnnotations)
+	if !exists {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+func annotateOwners(action string) (bool, error) {
+	value, exists := os.LookupEnv(envAnnotateOwners)
+	if !exists {
+		return false, nil
+	}
+	annotate, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %s", envAnnotateOwners, err)
+	}
+	if annotate && (action == actionAnnotate || action == actionPreview) {
+		return false, fmt.Errorf("%s cannot be used with %s=%s, which never reaps pods", envAnnotateOwners, envAction, action)
+	}
+	return annotate, nil
+}
+
+func protectJobBackoff(action string) (bool, error) {
+	value, exists := os.LookupEnv(envProtectJobBackoff)
+	if !exists {
+		return false, nil
+	}
+	protect, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %s", envProtectJobBackoff, err)
+	}
+	if protect && action != actionDelete && action != actionEvict {
+		return false, fmt.Errorf("%s can only be used with %s=%s or %s=%s, the other actions do not fail job pods", envProtectJobBackoff, envAction, actionDelete, envAction, actionEvict)
+	}
+	return protect, nil
+}
+
+func minReadyReplicas(action string) (*int32, error) {
+	value, exists := os.LookupEnv(envMinReadyReplicas)
+	if !exists {
+		return nil, nil
+	}
+	minReady, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %s", envMinReadyReplicas, err)
+	}
+	if minReady < 0 {
+		return nil, fmt.Errorf("invalid %s: must not be negative", envMinReadyReplicas)
+	}
+	if action != actionDelete && action != actionEvict {
+		return nil, fmt.Errorf("%s can only be used with %s=%s or %s=%s, the other actions do not remove ready pods", envMinReadyReplicas, envAction, actionDelete, envAction, actionEvict)
+	}
+	minReadyReplicas := int32(minReady)
+	return &minReadyReplicas, nil
+}
+
+func verdictInterval() (time.Duration, error) {
+	return envDuration(envVerdictAnnotationInterval, "1h")
+}
+
+func reapWebhook() (notifier, error) {
+	url, exists := os.LookupEnv(envReapWebhookURL)
+	if !exists {
+		return nil, nil
+	}
+	timeout, err := envDuration(envReapWebhookTimeout, defaultNotifyTimeout)
+	if err != nil {
+		return nil, err
+	}
+	retries := defaultNotifyRetries
+	if value, exists := os.LookupEnv(envReapWebhookRetries); exists {
+		if retries, err = strconv.Atoi(value); err != nil {
+			return nil, fmt.Errorf("invalid %s: %s", envReapWebhookRetries, err)
+		}
+		if retries < 0 {
+			return nil, fmt.Errorf("invalid %s: must not be negative", envReapWebhookRetries)
+		}
+	}
+	return newWebhookNotifier(url, timeout, retries), nil
+}
+
+func reapRecords() (notifier, error) {
+	destination, exists := os.LookupEnv(envReapRecords)
+	if !exists {
+		return nil, nil
+	}
+	records, err := openRecordNotifier(destination)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %s", envReapRecords, err)
+	}
+	return records, nil
+}
+
+// envPositiveInt returns the integer value of the environment variable, which must be positive, or the default.
+func envPositiveInt(key string, defValue int) (int, error) {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defValue, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %s", key, err)
+	}
+	if parsed <= 0 {
+		return 0, fmt.Errorf("invalid %s: must be positive", key)
+	}
+	return parsed, nil
+}
+
+func audit() (*auditLog, error) {
+	value, exists := os.LookupEnv(envAuditSink)
+	if !exists {
+		return nil, nil
+	}
+	maxSize, err := envPositiveInt(envAuditFileMaxSize, 10*1024*1024)
+	if err != nil {
+		return nil, err
+	}
+	maxBackups, err := strconv.Atoi(envDefault(envAuditFileMaxBackups, "3"))
+	if err != nil || maxBackups < 0 {
+		return nil, fmt.Errorf("invalid %s: must be a non-negative integer", envAuditFileMaxBackups)
+	}
+	maxRecords, err := envPositiveInt(envAuditConfigMapMaxRecords, 1000)
+	if err != nil {
+		return nil, err
+	}
+	sink, err := parseAuditSink(value, int64(maxSize), maxBackups, maxRecords)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %s", envAuditSink, err)
+	}
+	return &auditLog{sink: sink}, nil
+}
+
+func warehouse() (*warehouseExport, error) {
+	value, exists := os.LookupEnv(envWarehouseExport)
+	if !exists {
+		return nil, nil
+	}
+	table, err := parseWarehouse(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %s", envWarehouseExport, err)
+	}
+	batchSize, err := envPositiveInt(envWarehouseBatchSize, bigQueryMaxRows)
+	if err != nil {
+		return nil, err
+	}
+	if batchSize > bigQueryMaxRows {
+		return nil, fmt.Errorf("invalid %s: must be at most %d", envWarehouseBatchSize, bigQueryMaxRows)
+	}
+	interval, err := envDuration(envWarehouseFlushInterval, "1h")
+	if err != nil {
+		return nil, err
+	}
+	if interval < 0 {
+		return nil, fmt.Errorf("invalid %s: must not be negative", envWarehouseFlushInterval)
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	if path, exists := os.LookupEnv(envGoogleApplicationCredentials); exists {
+		table.tokens, err = newServiceAccountTokenSource(path, bigQueryScope, client)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %s", envGoogleApplicationCredentials, err)
+		}
+	} else {
+		table.tokens = &metadataTokenSource{url: googleMetadataTokenURL, client: client}
+	}
+	return newWarehouseExport(table, batchSize, interval, time.Now()), nil
+}
+
+func envDefault(key string, defValue string) string {
+	if value, exists := os.LookupEnv(key); exists {
+		return value
+	}
+	return defValue
+}
+
+func slack() (notifier, error) {
+	url, exists := os.LookupEnv(envSlackWebhookURL)
+	if !exists {
+		return nil, nil
+	}
+	templateText, exists := os.LookupEnv(envSlackTemplate)
+	if !exists {
+		templateText = defaultSlackTemplate
+	}
+	template, err := parseSlackTemplate(templateText)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %s", envSlackTemplate, err)
+	}
+	summary := false
+	if value, exists := os.LookupEnv(envSlackSummary); exists {
+		if summary, err = strconv.ParseBool(value); err != nil {
+			return nil, fmt.Errorf("invalid %s: %s", envSlackSummary, err)
+		}
+	}
+	timeout, err := time.ParseDuration(defaultNotifyTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return &slackNotifier{
+		url:        url,
+		channel:    os.Getenv(envSlackChannel),
+		template:   template,
+		summary:    summary,
+		client:     &http.Client{Timeout: timeout},
+		retries:    defaultNotifyRetries,
+		retryDelay: time.Second,
+	}, nil
+}
+
+func elasticsearch() (notifier, error) {
+	url, exists := os.LookupEnv(envElasticsearchURL)
+	if !exists {
+		return nil, nil
+	}
+	password, err := secret(envElasticsearchPassword)
+	if err != nil {
+		return nil, err
+	}
+	apiKey, err := secret(envElasticsearchAPIKey)
+	if err != nil {
+		return nil, err
+	}
+	timeout, err := time.ParseDuration(defaultNotifyTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return &elasticsearchNotifier{
+		url:        strings.TrimSuffix(url, "/"),
+		index:      envDefault(envElasticsearchIndex, "pod-reaper"),
+		username:   os.Getenv(envElasticsearchUsername),
+		password:   password,
+		apiKey:     apiKey,
+		client:     &http.Client{Timeout: timeout},
+		retries:    defaultNotifyRetries,
+		retryDelay: time.Second,
+	}, nil
+}
+
+func notificationDedupeWindow() (*notificationDedupe, error) {
+	window, err := envDuration(envNotificationDedupeWindow, "0s")
+	if err != nil {
+		return nil, err
+	}
+	if window < 0 {
+		return nil, fmt.Errorf("invalid %s: must not be negative", envNotificationDedupeWindow)
+	}
+	if window == 0 {
+		return nil, nil
+	}
+	// replicas sharing a leader election id share the notifications they sent
+	return &notificationDedupe{
+		window:    window,
+		namespace: leaderElectionNamespace(),
+		configMap: leaderElectionID() + "-notifications",
+	}, nil
+}
+
+func notifiers() ([]notifier, error) {
+	var notifiers []notifier
+	for _, load := range []func() (notifier, error){reapWebhook, reapRecords, slack, elasticsearch} {
+		notifier, err := load()
+		if err != nil {
+			return nil, err
+		} else if notifier != nil {
+			notifiers = append(notifiers, notifier)
+		}
+	}
+	return notifiers, nil
+}
+
+// LoadOptions loads the options and rules of a Reaper from the environment variables and then applies opts, refusing
+// options that would reap nearly every pod in the cluster unless I_UNDERSTAND_THE_RISK is set.
+func LoadOptions(opts ...Option) (options Options, err error) {
+	if options, err = loadOptionsWithoutRules(); err != nil {
+		return options, err
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.reapers, err = reapers(options.scheduleLocation); err != nil {
+		return options, err
+	}
+	if options.reapers != nil && options.reaperPolicies {
+		return options, fmt.Errorf("specify only one of %s and %s", envReapers, envReaperPolicies)
+	}
+	if options.reaperPolicies {
+		// with reaper policies, rules and schedules come from the policies
+		return options, nil
+	}
+	if options.reapers != nil {
+		// with reapers, rules and schedules come from each reaper
+		for _, reaper := range options.reapers {
+			if err = failsafe(options.withPolicy(reaper)); err != nil {
+				return options, fmt.Errorf("reaper %s: %s", reaper.name, err)
+			}
+		}
+		return options, nil
+	}
+	if options.rules, err = rules.LoadRules(); err != nil {
+		return options, err
+	}
+	if err = failsafe(options); err != nil {
+		return options, err
+	}
+	return options, nil
+}
+
+// loadOptionsWithoutRules loads every option except the rules, for the commands that reap pods chosen some other way.
+func loadOptionsWithoutRules() (options Options, err error) {
+	options.namespace = namespace()
+	if options.namespaces, err = namespaces(); err != nil {
+		return options, err
+	}
+	if options.gracePeriod, err = gracePeriod(); err != nil {
+		return options, err
+	}
+	if options.graceEscalationWindow, options.gracePeriodFloor, err = graceEscalationOptions(); err != nil {
+		return options, err
+	}
+	options.schedule = schedule()
+	if options.scheduleLocation, err = scheduleTimezone(); err != nil {
+		return options, err
+	}
+	if options.scheduleJitter, err = scheduleJitter(); err != nil {
+		return options, err
+	}
+	if options.blackout, err = blackout(); err != nil {
+		return options, err
+	}
+	if options.chaosWindow, options.chaosVerifyTimeout, err = chaosWindow(); err != nil {
+		return options, err
+	}
+	if options.alertmanager, err = alertmanagerURL(); err != nil {
+		return options, err
+	}
+	if options.runDuration, err = runDuration(); err != nil {
+		return options, err
+	}
+	if options.runOnce, err = runOnce(); err != nil {
+		return options, err
+	}
+	if options.shutdownTimeout, err = shutdownTimeout(); err != nil {
+		return options, err
+	}
+	if options.apiTimeout, err = apiTimeout(); err != nil {
+		return options, err
+	}
+	if options.apiRetries, err = apiRetries(); err != nil {
+		return options, err
+	}
+	if options.apiRetryBackoff, err = apiRetryBackoff(); err != nil {
+		return options, err
+	}
+	if options.labelExclusion, err = labelExclusion(); err != nil {
+		return options, err
+	}
+	if options.labelRequirement, err = labelRequirement(); err != nil {
+		return options, err
+	}
+	if options.requireLabels, err = requireLabels(); err != nil {
+		return options, err
+	}
+	if options.excludeLabels, err = excludeLabels(); err != nil {
+		return options, err
+	}
+	if options.annotationSelector, err = annotationSelector(); err != nil {
+		return options, err
+	}
+	if options.fieldSelector, err = fieldSelector(); err != nil {
+		return options, err
+	}
+	if options.ownerKinds, options.excludeOwnerKinds, err = ownerKinds(); err != nil {
+		return options, err
+	}
+	if options.ignoreDaemonSetPods, err = ignoreDaemonSetPods(options.ownerKinds); err != nil {
+		return options, err
+	}
+	if options.minPodAge, err = minPodAge(); err != nil {
+		return options, err
+	}
+	if options.nodeNames, err = nodeNames(); err != nil {
+		return options, err
+	}
+	if options.nodeSelector, err = nodeSelector(); err != nil {
+		return options, err
+	}
+	if options.dryRun, err = dryRun(); err != nil {
+		return options, err
+	}
+	options.dryRunReport = dryRunReport()
+	if options.dryRunReportFormat, err = dryRunReportFormat(); err != nil {
+		return options, err
+	}
+	if options.maxPods, err = maxPods(); err != nil {
+		return options, err
+	}
+	if options.maxPodsPercent, options.maxPodsPercentOf, err = maxPodsPercent(); err != nil {
+		return options, err
+	}
+	if options.maxPodsPerNamespace, err = maxPodsPerNamespace(); err != nil {
+		return options, err
+	}
+	if options.workloadCooldown, err = workloadCooldown(); err != nil {
+		return options, err
+	}
+	if options.reapInterval, err = reapInterval(); err != nil {
+		return options, err
+	}
+	if options.apiCallBudget, err = apiCallBudget(); err != nil {
+		return options, err
+	}
+	if options.listPageSize, err = listPageSize(); err != nil {
+		return options, err
+	}
+	if options.listConsistency, err = listConsistency(); err != nil {
+		return options, err
+	}
+	options.metricsAddress = metricsAddress()
+	options.healthAddress = healthAddress()
+	options.adminAddress = adminAddress()
+	if options.requireApproval, err = requireApproval(options.adminAddress); err != nil {
+		return options, err
+	}
+	if options.slackSigningSecret, err = slackSigningSecret(options.adminAddress); err != nil {
+		return options, err
+	}
+	if options.adminToken, err = adminToken(options.adminAddress); err != nil {
+		return options, err
+	}
+	if options.eventStream, err = eventStreamEnabled(); err != nil {
+		return options, err
+	}
+	if options.reapRequestLimit, err = reapRequestLimit(); err != nil {
+		return options, err
+	}
+	if options.metricsToken, err = metricsToken(options.metricsAddress); err != nil {
+		return options, err
+	}
+	if options.tlsConfig, err = serverTLS(options.metricsAddress, options.adminAddress, options.healthAddress); err != nil {
+		return options, err
+	}
+	if options.adminAddress != "" && !options.adminAuthenticated() {
+		return options, fmt.Errorf("%s requires %s or %s, which authenticate the admin endpoints", envAdminAddress, envAdminToken, envTLSClientCAFile)
+	}
+	if options.livenessGracePeriod, err = livenessGracePeriod(); err != nil {
+		return options, err
+	}
+	if options.readinessThreshold, err = readinessThreshold(); err != nil {
+		return options, err
+	}
+	if options.leaderElection, err = leaderElection(); err != nil {
+		return options, err
+	}
+	if options.leaderElection {
+		options.leaderElectionID = leaderElectionID()
+		options.leaderElectionNS = leaderElectionNamespace()
+		if options.leaseDuration, options.leaseRenewDeadline, options.leaseRetryPeriod, err = leaseTimings(); err != nil {
+			return options, err
+		}
+	}
+	if options.podSortingStrategy, err = podSortingStrategy(); err != nil {
+		return options, err
+	}
+	if options.action, err = action(); err != nil {
+		return options, err
+	}
+	if options.reapConcurrency, err = reapConcurrency(options.action); err != nil {
+		return options, err
+	}
+	if options.markGrace, err = markGrace(options.action); err != nil {
+		return options, err
+	}
+	if options.forceDeleteStuck, err = forceDeleteStuck(options.action); err != nil {
+		return options, err
+	}
+	if options.respectTopologySpread, err = respectTopologySpread(); err != nil {
+		return options, err
+	}
+	if options.useInformer, err = useInformer(); err != nil {
+		return options, err
+	}
+	if options.emitEvents, err = emitEvents(); err != nil {
+		return options, err
+	}
+	if options.emitSkipEvents, err = emitSkipEvents(); err != nil {
+		return options, err
+	}
+	if options.namespaceRules, err = namespaceRules(); err != nil {
+		return options, err
+	}
+	if options.namespaceReports, err = namespaceReports(); err != nil {
+		return options, err
+	}
+	if options.verdictAnnotations, err = verdictAnnotations(); err != nil {
+		return options, err
+	}
+	if options.annotateOwners, err = annotateOwners(options.action); err != nil {
+		return options, err
+	}
+	if options.protectJobBackoff, err = protectJobBackoff(options.action); err != nil {
+		return options, err
+	}
+	if options.minReadyReplicas, err = minReadyReplicas(options.action); err != nil {
+		return options, err
+	}
+	if options.verdictInterval, err = verdictInterval(); err != nil {
+		return options, err
+	}
+	if options.notifiers, err = notifiers(); err != nil {
+		return options, err
+	}
+	if options.notificationDedupe, err = notificationDedupeWindow(); err != nil {
+		return options, err
+	}
+	if options.audit, err = audit(); err != nil {
+		return options, err
+	}
+	if options.warehouse, err = warehouse(); err != nil {
+		return options, err
+	}
+	if options.clientQPS, err = clientQPS(); err != nil {
+		return options, err
+	}
+	if options.clientBurst, err = clientBurst(); err != nil {
+		return options, err
+	}
+	if options.reaperPolicies, err = reaperPolicies(); err != nil {
+		return options, err
+	}
+	if options.reaperPolicies {
+		if options.policySyncInterval, err = policySyncInterval(); err != nil {
+			return options, err
+		}
+	}
+	if err = applyProfile(&options); err != nil {
+		return options, err
+	}
+	return options, nil
+}
+
+// failsafe refuses configurations that would reap nearly every pod in the cluster: pods are removed (not in dry-run
+// mode, and with an action that deletes pods), no namespace, label, annotation, field, owner, or node filter narrows
+// the pods, and every rule flags nearly every pod. I_UNDERSTAND_THE_RISK=true turns the check into a warning.
+func failsafe(options Options) error {
+	if options.dryRun || options.action == actionAnnotate || options.action == actionPreview {
+		return nil
+	}
+	filtered := options.namespace != "" || options.namespaces != nil ||
+		options.labelExclusion != nil || options.labelRequirement != nil || options.requireLabels != nil ||
+		options.excludeLabels != nil || options.annotationSelector != nil ||
+		options.fieldSelector != nil || options.ownerKinds != nil || options.excludeOwnerKinds != nil ||
+		options.nodeNames != nil || options.nodeSelector != nil
+	if filtered {
+		return nil
+	}
+	sweeping := options.rules.Sweeping()
+	if sweeping == nil {
+		return nil
+	}
+	value, exists := os.LookupEnv(envIUnderstandTheRisk)
+	if exists {
+		understood, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %s", envIUnderstandTheRisk, err)
+		}
+		if understood {
+			logrus.WithField("rules", sweeping).Warn("pod-reaper will reap nearly every pod in every namespace")
+			return nil
+		}
+	}
+	return fmt.Errorf("refusing to reap nearly every pod in every namespace (%s): set a namespace, label, annotation, owner, or node filter, or set %s=true",
+		strings.Join(sweeping, ", "), envIUnderstandTheRisk)
+}
@@ -0,0 +1,2366 @@
+package reaper
+
+import (
+	"crypto/tls"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+
+	"github.com/target/pod-reaper/rules"
+)
+
+func init() {
+	logrus.SetOutput(ioutil.Discard)
+}
+
+func epocPlus(duration time.Duration) *metav1.Time {
+	t := metav1.NewTime(time.Unix(0, 0).Add(duration))
+	return &t
+}
+func testPodList() []v1.Pod {
+	return []v1.Pod{
+		{
+			Status: v1.PodStatus{
+				StartTime: epocPlus(2 * time.Minute),
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "bearded-dragon",
+				Annotations: map[string]string{"example/key": "lizard", "controller.kubernetes.io/pod-deletion-cost": "invalid"},
+			},
+		},
+		{
+			Status: v1.PodStatus{},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "nil-start-time",
+				Annotations: map[string]string{"example/key": "lizard", "controller.kubernetes.io/pod-deletion-cost": "-100"},
+			},
+		},
+		{
+			Status: v1.PodStatus{
+				StartTime: epocPlus(5 * time.Minute),
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "expensive",
+				Annotations: map[string]string{"example/key": "not-lizard", "controller.kubernetes.io/pod-deletion-cost": "500"},
+			},
+		},
+		{
+			Status: v1.PodStatus{
+				StartTime: epocPlus(1 * time.Minute),
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "corgi",
+				Annotations: map[string]string{"example/key": "not-lizard"},
+			},
+		},
+	}
+}
+
+func TestOptions(t *testing.T) {
+	t.Run("namespace", func(t *testing.T) {
+		t.Run("default", func(t *testing.T) {
+			os.Clearenv()
+			namespace := namespace()
+			assert.Equal(t, "", namespace)
+		})
+		t.Run("valid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envNamespace, "test-namespace")
+			namespace := namespace()
+			assert.Equal(t, "test-namespace", namespace)
+		})
+	})
+	t.Run("namespaces", func(t *testing.T) {
+		t.Run("default", func(t *testing.T) {
+			os.Clearenv()
+			namespaces, err := namespaces()
+			assert.NoError(t, err)
+			assert.Nil(t, namespaces)
+		})
+		t.Run("valid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envNamespaces, "team-a,team-b")
+			namespaces, err := namespaces()
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"team-a", "team-b"}, namespaces)
+		})
+		t.Run("trimmed and de-duplicated", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envNamespaces, " team-a, team-b,,team-a ")
+			namespaces, err := namespaces()
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"team-a", "team-b"}, namespaces)
+		})
+		t.Run("empty", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envNamespaces, " , ")
+			_, err := namespaces()
+			assert.Error(t, err)
+		})
+		t.Run("with namespace", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envNamespace, "team-a")
+			os.Setenv(envNamespaces, "team-b")
+			_, err := namespaces()
+			assert.Error(t, err)
+		})
+	})
+	t.Run("grace period", func(t *testing.T) {
+		t.Run("default", func(t *testing.T) {
+			os.Clearenv()
+			gracePeriod, err := gracePeriod()
+			assert.NoError(t, err)
+			assert.Nil(t, gracePeriod)
+		})
+		t.Run("valid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envGracePeriod, "1m53s999ms")
+			gracePeriod, err := gracePeriod()
+			assert.NoError(t, err)
+			assert.Equal(t, int64(113), *gracePeriod)
+		})
+		t.Run("invalid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envGracePeriod, "invalid")
+			_, err := gracePeriod()
+			assert.Error(t, err)
+		})
+	})
+	t.Run("grace escalation", func(t *testing.T) {
+		t.Run("default", func(t *testing.T) {
+			os.Clearenv()
+			window, floor, err := graceEscalationOptions()
+			assert.NoError(t, err)
+			assert.Equal(t, time.Duration(0), window)
+			assert.Equal(t, time.Duration(0), floor)
+		})
+		t.Run("valid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envGraceEscalationWindow, "1h")
+			os.Setenv(envGracePeriodFloor, "5s")
+			window, floor, err := graceEscalationOptions()
+			assert.NoError(t, err)
+			assert.Equal(t, time.Hour, window)
+			assert.Equal(t, 5*time.Second, floor)
+		})
+		t.Run("negative window", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envGraceEscalationWindow, "-1h")
+			_, _, err := graceEscalationOptions()
+			assert.Error(t, err)
+		})
+		t.Run("invalid floor", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envGracePeriodFloor, "soon")
+			_, _, err := graceEscalationOptions()
+			assert.Error(t, err)
+		})
+	})
+	t.Run("schedule", func(t *testing.T) {
+		t.Run("default", func(t *testing.T) {
+			os.Clearenv()
+			schedule := schedule()
+			assert.Equal(t, "@every 1m", schedule)
+		})
+	})
+	t.Run("schedule timezone", func(t *testing.T) {
+		t.Run("default", func(t *testing.T) {
+			os.Clearenv()
+			location, err := scheduleTimezone()
+			assert.NoError(t, err)
+			assert.Nil(t, location)
+		})
+		t.Run("valid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envScheduleTimezone, "America/Chicago")
+			location, err := scheduleTimezone()
+			assert.NoError(t, err)
+			assert.Equal(t, "America/Chicago", location.String())
+		})
+		t.Run("invalid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envScheduleTimezone, "Middle/Earth")
+			_, err := scheduleTimezone()
+			assert.Error(t, err)
+		})
+	})
+	t.Run("schedule jitter", func(t *testing.T) {
+		t.Run("default", func(t *testing.T) {
+			os.Clearenv()
+			jitter, err := scheduleJitter()
+			assert.NoError(t, err)
+			assert.Zero(t, jitter)
+		})
+		t.Run("valid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envScheduleJitter, "30s")
+			jitter, err := scheduleJitter()
+			assert.NoError(t, err)
+			assert.Equal(t, 30*time.Second, jitter)
+		})
+		t.Run("negative", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envScheduleJitter, "-30s")
+			_, err := scheduleJitter()
+			assert.Error(t, err)
+		})
+	})
+	t.Run("blackout schedule", func(t *testing.T) {
+		t.Run("default", func(t *testing.T) {
+			os.Clearenv()
+			schedule, err := blackout()
+			assert.NoError(t, err)
+			assert.Empty(t, schedule)
+		})
+		t.Run("valid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envBlackoutSchedule, "Mon-Fri 22:00-06:00 UTC; 0 18 * * FRI for 62h")
+			schedule, err := blackout()
+			assert.NoError(t, err)
+			assert.Len(t, schedule, 2)
+		})
+		t.Run("invalid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envBlackoutSchedule, "during the freeze")
+			_, err := blackout()
+			assert.Error(t, err)
+		})
+	})
+	t.Run("chaos window", func(t *testing.T) {
+		t.Run("default", func(t *testing.T) {
+			os.Clearenv()
+			windows, timeout, err := chaosWindow()
+			assert.NoError(t, err)
+			assert.Empty(t, windows)
+			assert.Zero(t, timeout)
+		})
+		t.Run("valid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envChaosWindow, "Mon-Fri 10:00-16:00 America/Chicago")
+			windows, timeout, err := chaosWindow()
+			assert.NoError(t, err)
+			assert.Len(t, windows, 1)
+			assert.Equal(t, 10*time.Minute, timeout)
+		})
+		t.Run("verify timeout", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envChaosWindow, "Mon-Fri 10:00-16:00")
+			os.Setenv(envChaosVerifyTimeout, "15m")
+			_, timeout, err := chaosWindow()
+			assert.NoError(t, err)
+			assert.Equal(t, 15*time.Minute, timeout)
+		})
+		t.Run("invalid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envChaosWindow, "business hours")
+			_, _, err := chaosWindow()
+			assert.Error(t, err)
+		})
+		t.Run("invalid verify timeout", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envChaosWindow, "Mon-Fri 10:00-16:00")
+			os.Setenv(envChaosVerifyTimeout, "0s")
+			_, _, err := chaosWindow()
+			assert.Error(t, err)
+		})
+	})
+	t.Run("alertmanager url", func(t *testing.T) {
+		t.Run("default", func(t *testing.T) {
+			os.Clearenv()
+			alertmanager, err := alertmanagerURL()
+			assert.NoError(t, err)
+			assert.Nil(t, alertmanager)
+		})
+		t.Run("valid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envAlertmanagerURL, "http://alertmanager.monitoring:9093")
+			alertmanager, err := alertmanagerURL()
+			assert.NoError(t, err)
+			assert.Equal(t, "http://alertmanager.monitoring:9093", alertmanager.url)
+		})
+		t.Run("invalid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envAlertmanagerURL, "alertmanager:9093")
+			_, err := alertmanagerURL()
+			assert.Error(t, err)
+		})
+	})
+	t.Run("run duration", func(t *testing.T) {
+		t.Run("default", func(t *testing.T) {
+			os.Clearenv()
+			duration, err := runDuration()
+			assert.NoError(t, err)
+			assert.Equal(t, 0*time.Second, duration)
+		})
+		t.Run("invalid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envRunDuration, "not-a-duration")
+			_, err := runDuration()
+			assert.Error(t, err)
+		})
+		t.Run("valid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envRunDuration, "1m58s")
+			duration, err := runDuration()
+			assert.NoError(t, err)
+			assert.Equal(t, 2*time.Minute-2*time.Second, duration)
+		})
+	})
+	t.Run("shutdown timeout", func(t *testing.T) {
+		t.Run("default", func(t *testing.T) {
+			os.Clearenv()
+			timeout, err := shutdownTimeout()
+			assert.NoError(t, err)
+			assert.Equal(t, 25*time.Second, timeout)
+		})
+		t.Run("valid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envShutdownTimeout, "2m")
+			timeout, err := shutdownTimeout()
+			assert.NoError(t, err)
+			assert.Equal(t, 2*time.Minute, timeout)
+		})
+		t.Run("invalid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envShutdownTimeout, "soon")
+			_, err := shutdownTimeout()
+			assert.Error(t, err)
+		})
+		t.Run("negative", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envShutdownTimeout, "-1s")
+			_, err := shutdownTimeout()
+			assert.Error(t, err)
+		})
+	})
+	t.Run("api timeout", func(t *testing.T) {
+		t.Run("default", func(t *testing.T) {
+			os.Clearenv()
+			timeout, err := apiTimeout()
+			assert.NoError(t, err)
+			assert.Equal(t, 30*time.Second, timeout)
+		})
+		t.Run("disabled", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envAPITimeout, "0s")
+			timeout, err := apiTimeout()
+			assert.NoError(t, err)
+			assert.Equal(t, time.Duration(0), timeout)
+		})
+		t.Run("invalid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envAPITimeout, "-5s")
+			_, err := apiTimeout()
+			assert.Error(t, err)
+		})
+	})
+	t.Run("field selector", func(t *testing.T) {
+		t.Run("default", func(t *testing.T) {
+			os.Clearenv()
+			selector, err := fieldSelector()
+			assert.NoError(t, err)
+			assert.Nil(t, selector)
+		})
+		t.Run("valid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envFieldSelector, "status.phase!=Running,spec.nodeName=node-1")
+			selector, err := fieldSelector()
+			assert.NoError(t, err)
+			assert.Equal(t, "spec.nodeName=node-1,status.phase!=Running", selector.String())
+		})
+		t.Run("invalid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envFieldSelector, "status.phase")
+			_, err := fieldSelector()
+			assert.Error(t, err)
+		})
+		t.Run("empty", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envFieldSelector, "")
+			_, err := fieldSelector()
+			assert.Error(t, err)
+		})
+	})
+	t.Run("notification dedupe window", func(t *testing.T) {
+		t.Run("default", func(t *testing.T) {
+			os.Clearenv()
+			dedupe, err := notificationDedupeWindow()
+			assert.NoError(t, err)
+			assert.Nil(t, dedupe)
+		})
+		t.Run("valid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envNotificationDedupeWindow, "24h")
+			os.Setenv(envLeaderElectionNamespace, "reaper")
+			os.Setenv(envLeaderElectionID, "chaos-reaper")
+			dedupe, err := notificationDedupeWindow()
+			assert.NoError(t, err)
+			assert.Equal(t, &notificationDedupe{window: 24 * time.Hour, namespace: "reaper", configMap: "chaos-reaper-notifications"}, dedupe)
+		})
+		t.Run("invalid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envNotificationDedupeWindow, "-1h")
+			_, err := notificationDedupeWindow()
+			assert.Error(t, err)
+		})
+	})
+	t.Run("list page size", func(t *testing.T) {
+		t.Run("default", func(t *testing.T) {
+			os.Clearenv()
+			size, err := listPageSize()
+			assert.NoError(t, err)
+			assert.Equal(t, 500, size)
+		})
+		t.Run("unpaginated", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envListPageSize, "0")
+			size, err := listPageSize()
+			assert.NoError(t, err)
+			assert.Equal(t, 0, size)
+		})
+		t.Run("invalid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envListPageSize, "-1")
+			_, err := listPageSize()
+			assert.Error(t, err)
+			os.Setenv(envListPageSize, "big")
+			_, err = listPageSize()
+			assert.Error(t, err)
+		})
+	})
+	t.Run("list consistency", func(t *testing.T) {
+		t.Run("default", func(t *testing.T) {
+			os.Clearenv()
+			consistency, err := listConsistency()
+			assert.NoError(t, err)
+			assert.Equal(t, listConsistencyStrong, consistency)
+		})
+		t.Run("cached", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envListConsistency, "cached")
+			consistency, err := listConsistency()
+			assert.NoError(t, err)
+			assert.Equal(t, listConsistencyCached, consistency)
+		})
+		t.Run("invalid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envListConsistency, "eventual")
+			_, err := listConsistency()
+			assert.Error(t, err)
+		})
+	})
+	t.Run("reap concurrency", func(t *testing.T) {
+		t.Run("default", func(t *testing.T) {
+			os.Clearenv()
+			concurrency, err := reapConcurrency(actionDelete)
+			assert.NoError(t, err)
+			assert.Equal(t, 1, concurrency)
+		})
+		t.Run("valid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envReapConcurrency, "8")
+			concurrency, err := reapConcurrency(actionEvict)
+			assert.NoError(t, err)
+			assert.Equal(t, 8, concurrency)
+		})
+		t.Run("invalid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envReapConcurrency, "0")
+			_, err := reapConcurrency(actionDelete)
+			assert.Error(t, err)
+			os.Setenv(envReapConcurrency, "lots")
+			_, err = reapConcurrency(actionDelete)
+			assert.Error(t, err)
+		})
+		t.Run("scale owner", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envReapConcurrency, "4")
+			_, err := reapConcurrency(actionScaleOwner)
+			assert.Error(t, err)
+		})
+	})
+	t.Run("api retries", func(t *testing.T) {
+		t.Run("default", func(t *testing.T) {
+			os.Clearenv()
+			retries, err := apiRetries()
+			assert.NoError(t, err)
+			assert.Equal(t, 3, retries)
+			backoff, err := apiRetryBackoff()
+			assert.NoError(t, err)
+			assert.Equal(t, 500*time.Millisecond, backoff)
+		})
+		t.Run("valid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envAPIRetries, "0")
+			os.Setenv(envAPIRetryBackoff, "2s")
+			retries, err := apiRetries()
+			assert.NoError(t, err)
+			assert.Equal(t, 0, retries)
+			backoff, err := apiRetryBackoff()
+			assert.NoError(t, err)
+			assert.Equal(t, 2*time.Second, backoff)
+		})
+		t.Run("invalid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envAPIRetries, "many")
+			os.Setenv(envAPIRetryBackoff, "-1s")
+			_, err := apiRetries()
+			assert.Error(t, err)
+			_, err = apiRetryBackoff()
+			assert.Error(t, err)
+		})
+		t.Run("negative", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envAPIRetries, "-1")
+			_, err := apiRetries()
+			assert.Error(t, err)
+		})
+	})
+	t.Run("label exclusion", func(t *testing.T) {
+		t.Run("default", func(t *testing.T) {
+			os.Clearenv()
+			exclusion, err := labelExclusion()
+			assert.NoError(t, err)
+			assert.Nil(t, exclusion)
+		})
+		t.Run("only key", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envExcludeLabelKey, "test-key")
+			_, err := labelExclusion()
+			assert.Error(t, err)
+		})
+		t.Run("only values", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envExcludeLabelValues, "test-value1,test-value2")
+			_, err := labelExclusion()
+			assert.Error(t, err)
+		})
+		t.Run("invalid key", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envExcludeLabelKey, "keys cannot have spaces")
+			os.Setenv(envExcludeLabelValues, "test-value1,test-value2")
+			_, err := labelExclusion()
+			assert.Error(t, err)
+		})
+		t.Run("valid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envExcludeLabelKey, "test-key")
+			os.Setenv(envExcludeLabelValues, "test-value1,test-value2")
+			exclusion, err := labelExclusion()
+			assert.NoError(t, err)
+			assert.NotNil(t, exclusion)
+			assert.Equal(t, "test-key notin (test-value1,test-value2)", labels.NewSelector().Add(*exclusion).String())
+		})
+	})
+	t.Run("label requirement", func(t *testing.T) {
+		t.Run("default", func(t *testing.T) {
+			os.Clearenv()
+			requirement, err := labelRequirement()
+			assert.NoError(t, err)
+			assert.Nil(t, requirement)
+		})
+		t.Run("only key", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envRequireLabelKey, "test-key")
+			_, err := labelRequirement()
+			assert.Error(t, err)
+		})
+		t.Run("only values", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envRequireLabelValues, "test-value1,test-value2")
+			_, err := labelRequirement()
+			assert.Error(t, err)
+		})
+		t.Run("invalid key", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envRequireLabelKey, "keys cannot have spaces")
+			os.Setenv(envRequireLabelValues, "test-value1,test-value2")
+			_, err := labelRequirement()
+			assert.Error(t, err)
+		})
+		t.Run("valid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envRequireLabelKey, "test-key")
+			os.Setenv(envRequireLabelValues, "test-value1,test-value2")
+			requirement, err := labelRequirement()
+			assert.NoError(t, err)
+			assert.NotNil(t, requirement)
+			assert.Equal(t, "test-key in (test-value1,test-value2)", labels.NewSelector().Add(*requirement).String())
+		})
+	})
+	t.Run("label selectors", func(t *testing.T) {
+		t.Run("default", func(t *testing.T) {
+			os.Clearenv()
+			require, err := requireLabels()
+			assert.NoError(t, err)
+			assert.Nil(t, require)
+			exclude, err := excludeLabels()
+			assert.NoError(t, err)
+			assert.Nil(t, exclude)
+		})
+		t.Run("valid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envRequireLabels, "tier in (web,api),environment!=production")
+			os.Setenv(envExcludeLabels, "app=database,!disposable")
+			require, err := requireLabels()
+			assert.NoError(t, err)
+			assert.True(t, require.Matches(labels.Set{"tier": "web", "environment": "staging"}))
+			assert.False(t, require.Matches(labels.Set{"tier": "web", "environment": "production"}))
+			exclude, err := excludeLabels()
+			assert.NoError(t, err)
+			assert.True(t, exclude.Matches(labels.Set{"app": "database"}))
+			assert.False(t, exclude.Matches(labels.Set{"app": "database", "disposable": "true"}))
+		})
+		t.Run("invalid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envRequireLabels, "tier in (web")
+			_, err := requireLabels()
+			assert.Error(t, err)
+			os.Setenv(envExcludeLabels, "keys cannot have spaces")
+			_, err = excludeLabels()
+			assert.Error(t, err)
+		})
+		t.Run("empty", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envExcludeLabels, "")
+			_, err := excludeLabels()
+			assert.Error(t, err)
+		})
+	})
+	t.Run("annotation requirement", func(t *testing.T) {
+		t.Run("default", func(t *testing.T) {
+			os.Clearenv()
+			requirement, err := annotationRequirement()
+			assert.NoError(t, err)
+			assert.Nil(t, requirement)
+		})
+		t.Run("only key", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envRequireAnnotationKey, "test-key")
+			_, err := annotationRequirement()
+			assert.Error(t, err)
+		})
+		t.Run("only values", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envRequireAnnotationValues, "test-value1,test-value2")
+			_, err := annotationRequirement()
+			assert.Error(t, err)
+		})
+		t.Run("invalid key", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envRequireAnnotationKey, "keys cannot have spaces")
+			os.Setenv(envRequireAnnotationValues, "test-value1,test-value2")
+			_, err := annotationRequirement()
+			assert.Error(t, err)
+		})
+		t.Run("valid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envRequireAnnotationKey, "test-key")
+			os.Setenv(envRequireAnnotationValues, "test-value1,test-value2")
+			requirement, err := annotationRequirement()
+			assert.NoError(t, err)
+			assert.NotNil(t, requirement)
+			assert.Equal(t, "test-key in (test-value1,test-value2)", labels.NewSelector().Add(*requirement).String())
+		})
+	})
+	t.Run("annotation selector", func(t *testing.T) {
+		t.Run("default", func(t *testing.T) {
+			os.Clearenv()
+			selector, err := annotationSelector()
+			assert.NoError(t, err)
+			assert.Nil(t, selector)
+		})
+		t.Run("valid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envAnnotationSelector, "team in (a,b),!do-not-reap")
+			selector, err := annotationSelector()
+			assert.NoError(t, err)
+			assert.True(t, selector.Matches(labels.Set{"team": "a"}))
+			assert.False(t, selector.Matches(labels.Set{"team": "c"}))
+			assert.False(t, selector.Matches(labels.Set{"team": "b", "do-not-reap": "true"}))
+			assert.False(t, selector.Matches(labels.Set{}))
+		})
+		t.Run("with annotation requirement", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envAnnotationSelector, "!do-not-reap")
+			os.Setenv(envRequireAnnotationKey, "team")
+			os.Setenv(envRequireAnnotationValues, "a")
+			selector, err := annotationSelector()
+			assert.NoError(t, err)
+			assert.True(t, selector.Matches(labels.Set{"team": "a"}))
+			assert.False(t, selector.Matches(labels.Set{"team": "b"}))
+			assert.False(t, selector.Matches(labels.Set{"team": "a", "do-not-reap": "true"}))
+		})
+		t.Run("only annotation requirement", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envRequireAnnotationKey, "team")
+			os.Setenv(envRequireAnnotationValues, "a,b")
+			selector, err := annotationSelector()
+			assert.NoError(t, err)
+			assert.Equal(t, "team in (a,b)", selector.String())
+		})
+		t.Run("invalid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envAnnotationSelector, "team in (a")
+			_, err := annotationSelector()
+			assert.Error(t, err)
+		})
+		t.Run("empty", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envAnnotationSelector, "")
+			_, err := annotationSelector()
+			assert.Error(t, err)
+		})
+	})
+	t.Run("namespace-reports", func(t *testing.T) {
+		os.Clearenv()
+		enabled, err := namespaceReports()
+		assert.NoError(t, err)
+		assert.False(t, enabled)
+		os.Setenv(envNamespaceReports, "true")
+		enabled, err = namespaceReports()
+		assert.NoError(t, err)
+		assert.True(t, enabled)
+		os.Setenv(envNamespaceReports, "sometimes")
+		_, err = namespaceReports()
+		assert.Error(t, err)
+	})
+	t.Run("dry-run-report-format", func(t *testing.T) {
+		os.Clearenv()
+		format, err := dryRunReportFormat()
+		assert.NoError(t, err)
+		assert.Equal(t, reportFormatJSON, format)
+		os.Setenv(envDryRunReportFormat, "diff")
+		format, err = dryRunReportFormat()
+		assert.NoError(t, err)
+		assert.Equal(t, reportFormatDiff, format)
+		os.Setenv(envDryRunReportFormat, "yaml")
+		_, err = dryRunReportFormat()
+		assert.Error(t, err)
+	})
+	t.Run("node-name", func(t *testing.T) {
+		t.Run("not set", func(t *testing.T) {
+			os.Clearenv()
+			names, err := nodeNames()
+			assert.NoError(t, err)
+			assert.Nil(t, names)
+		})
+		t.Run("valid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envNodeName, "node-a, node-b,")
+			names, err := nodeNames()
+			assert.NoError(t, err)
+			assert.Equal(t, map[string]bool{"node-a": true, "node-b": true}, names)
+		})
+		t.Run("empty", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envNodeName, " , ")
+			_, err := nodeNames()
+			assert.Error(t, err)
+		})
+	})
+	t.Run("node-selector", func(t *testing.T) {
+		t.Run("not set", func(t *testing.T) {
+			os.Clearenv()
+			selector, err := nodeSelector()
+			assert.NoError(t, err)
+			assert.Nil(t, selector)
+		})
+		t.Run("valid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envNodeSelector, "pool=canary,zone in (a,b)")
+			selector, err := nodeSelector()
+			assert.NoError(t, err)
+			assert.True(t, selector.Matches(labels.Set{"pool": "canary", "zone": "a"}))
+			assert.False(t, selector.Matches(labels.Set{"pool": "stable", "zone": "a"}))
+		})
+		t.Run("invalid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envNodeSelector, "pool=(")
+			_, err := nodeSelector()
+			assert.Error(t, err)
+		})
+		t.Run("empty", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envNodeSelector, "")
+			_, err := nodeSelector()
+			assert.Error(t, err)
+		})
+	})
+	t.Run("owner-kinds", func(t *testing.T) {
+		t.Run("not set", func(t *testing.T) {
+			os.Clearenv()
+			kinds, excludeKinds, err := ownerKinds()
+			assert.NoError(t, err)
+			assert.Nil(t, kinds)
+			assert.Nil(t, excludeKinds)
+		})
+		t.Run("owner kinds", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envOwnerKinds, "ReplicaSet, Job")
+			kinds, excludeKinds, err := ownerKinds()
+			assert.NoError(t, err)
+			assert.Equal(t, map[string]bool{"ReplicaSet": true, "Job": true}, kinds)
+			assert.Nil(t, excludeKinds)
+		})
+		t.Run("exclude owner kinds", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envExcludeOwnerKinds, "StatefulSet,DaemonSet")
+			kinds, excludeKinds, err := ownerKinds()
+			assert.NoError(t, err)
+			assert.Nil(t, kinds)
+			assert.Equal(t, map[string]bool{"StatefulSet": true, "DaemonSet": true}, excludeKinds)
+		})
+		t.Run("both", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envOwnerKinds, "ReplicaSet")
+			os.Setenv(envExcludeOwnerKinds, "DaemonSet")
+			_, _, err := ownerKinds()
+			assert.Error(t, err)
+		})
+		t.Run("empty", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envOwnerKinds, " , ")
+			_, _, err := ownerKinds()
+			assert.Error(t, err)
+		})
+	})
+	t.Run("ignore daemon set pods", func(t *testing.T) {
+		t.Run("default", func(t *testing.T) {
+			os.Clearenv()
+			ignore, err := ignoreDaemonSetPods(nil)
+			assert.NoError(t, err)
+			assert.True(t, ignore)
+		})
+		t.Run("default with daemon set owner kind", func(t *testing.T) {
+			os.Clearenv()
+			ignore, err := ignoreDaemonSetPods(map[string]bool{"DaemonSet": true})
+			assert.NoError(t, err)
+			assert.False(t, ignore)
+		})
+		t.Run("false", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envIgnoreDaemonSetPods, "false")
+			ignore, err := ignoreDaemonSetPods(nil)
+			assert.NoError(t, err)
+			assert.False(t, ignore)
+		})
+		t.Run("invalid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envIgnoreDaemonSetPods, "sometimes")
+			_, err := ignoreDaemonSetPods(nil)
+			assert.Error(t, err)
+		})
+		t.Run("true with daemon set owner kind", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envIgnoreDaemonSetPods, "true")
+			_, err := ignoreDaemonSetPods(map[string]bool{"DaemonSet": true})
+			assert.Error(t, err)
+		})
+	})
+	t.Run("min pod age", func(t *testing.T) {
+		t.Run("default", func(t *testing.T) {
+			os.Clearenv()
+			age, err := minPodAge()
+			assert.NoError(t, err)
+			assert.Zero(t, age)
+		})
+		t.Run("valid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envMinPodAge, "5m")
+			age, err := minPodAge()
+			assert.NoError(t, err)
+			assert.Equal(t, 5*time.Minute, age)
+		})
+		t.Run("invalid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envMinPodAge, "young")
+			_, err := minPodAge()
+			assert.Error(t, err)
+		})
+		t.Run("negative", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envMinPodAge, "-5m")
+			_, err := minPodAge()
+			assert.Error(t, err)
+		})
+	})
+	t.Run("dry-run", func(t *testing.T) {
+		t.Run("false", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envDryRun, "false")
+			dryRun, err := dryRun()
+			assert.NoError(t, err)
+			assert.False(t, dryRun)
+		})
+		t.Run("true", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envDryRun, "true")
+			dryRun, err := dryRun()
+			assert.NoError(t, err)
+			assert.True(t, dryRun)
+		})
+		t.Run("true by number", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envDryRun, "1")
+			dryRun, err := dryRun()
+			assert.NoError(t, err)
+			assert.True(t, dryRun)
+		})
+		t.Run("invalid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envDryRun, "outside expected values")
+			_, err := dryRun()
+			assert.Error(t, err)
+		})
+		t.Run("not set", func(t *testing.T) {
+			os.Clearenv()
+			dryRun, err := dryRun()
+			assert.NoError(t, err)
+			assert.False(t, dryRun)
+		})
+	})
+	t.Run("dry-run-report", func(t *testing.T) {
+		t.Run("not set", func(t *testing.T) {
+			os.Clearenv()
+			assert.Equal(t, "", dryRunReport())
+		})
+		t.Run("set", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envDryRunReport, "stdout")
+			assert.Equal(t, "stdout", dryRunReport())
+		})
+	})
+	t.Run("max-pods", func(t *testing.T) {
+		t.Run("invalid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envMaxPods, "not a number")
+			_, err := maxPods()
+			assert.Error(t, err)
+		})
+		t.Run("negative", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envMaxPods, "-123")
+			maxPods, err := maxPods()
+			assert.NoError(t, err)
+			assert.Equal(t, 0, maxPods)
+		})
+		t.Run("positive", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envMaxPods, "123")
+			maxPods, err := maxPods()
+			assert.NoError(t, err)
+			assert.Equal(t, 123, maxPods)
+		})
+		t.Run("not set", func(t *testing.T) {
+			os.Clearenv()
+			maxPods, err := maxPods()
+			assert.NoError(t, err)
+			assert.Equal(t, 0, maxPods)
+		})
+	})
+	t.Run("max-pods-per-namespace", func(t *testing.T) {
+		t.Run("not set", func(t *testing.T) {
+			os.Clearenv()
+			limit, err := maxPodsPerNamespace()
+			assert.NoError(t, err)
+			assert.Equal(t, 0, limit)
+		})
+		t.Run("positive", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envMaxPodsPerNamespace, "5")
+			limit, err := maxPodsPerNamespace()
+			assert.NoError(t, err)
+			assert.Equal(t, 5, limit)
+		})
+		t.Run("invalid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envMaxPodsPerNamespace, "-1")
+			_, err := maxPodsPerNamespace()
+			assert.Error(t, err)
+			os.Setenv(envMaxPodsPerNamespace, "some")
+			_, err = maxPodsPerNamespace()
+			assert.Error(t, err)
+		})
+	})
+	t.Run("workload-cooldown", func(t *testing.T) {
+		t.Run("not set", func(t *testing.T) {
+			os.Clearenv()
+			cooldown, err := workloadCooldown()
+			assert.NoError(t, err)
+			assert.Equal(t, time.Duration(0), cooldown)
+		})
+		t.Run("valid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envWorkloadCooldown, "30m")
+			cooldown, err := workloadCooldown()
+			assert.NoError(t, err)
+			assert.Equal(t, 30*time.Minute, cooldown)
+		})
+		t.Run("invalid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envWorkloadCooldown, "-1m")
+			_, err := workloadCooldown()
+			assert.Error(t, err)
+			os.Setenv(envWorkloadCooldown, "a while")
+			_, err = workloadCooldown()
+			assert.Error(t, err)
+		})
+	})
+	t.Run("max-pods-percent", func(t *testing.T) {
+		t.Run("not set", func(t *testing.T) {
+			os.Clearenv()
+			percent, of, err := maxPodsPercent()
+			assert.NoError(t, err)
+			assert.Equal(t, 0.0, percent)
+			assert.Equal(t, "", of)
+		})
+		t.Run("listed by default", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envMaxPodsPercent, "2.5%")
+			percent, of, err := maxPodsPercent()
+			assert.NoError(t, err)
+			assert.Equal(t, 2.5, percent)
+			assert.Equal(t, maxPodsPercentOfListed, of)
+		})
+		t.Run("matched", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envMaxPodsPercent, "50")
+			os.Setenv(envMaxPodsPercentOf, "matched")
+			percent, of, err := maxPodsPercent()
+			assert.NoError(t, err)
+			assert.Equal(t, 50.0, percent)
+			assert.Equal(t, maxPodsPercentOfMatched, of)
+		})
+		t.Run("invalid", func(t *testing.T) {
+			for _, value := range []string{"many", "0", "-5", "101"} {
+				os.Clearenv()
+				os.Setenv(envMaxPodsPercent, value)
+				_, _, err := maxPodsPercent()
+				assert.Error(t, err, value)
+			}
+			os.Setenv(envMaxPodsPercent, "10")
+			os.Setenv(envMaxPodsPercentOf, "total")
+			_, _, err := maxPodsPercent()
+			assert.Error(t, err)
+		})
+		t.Run("only of", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envMaxPodsPercentOf, "matched")
+			_, _, err := maxPodsPercent()
+			assert.Error(t, err)
+		})
+	})
+	t.Run("reap-interval", func(t *testing.T) {
+		t.Run("not set", func(t *testing.T) {
+			os.Clearenv()
+			interval, err := reapInterval()
+			assert.NoError(t, err)
+			assert.Equal(t, time.Duration(0), interval)
+		})
+		t.Run("valid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envReapInterval, "5s")
+			interval, err := reapInterval()
+			assert.NoError(t, err)
+			assert.Equal(t, 5*time.Second, interval)
+		})
+		t.Run("negative", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envReapInterval, "-5s")
+			_, err := reapInterval()
+			assert.Error(t, err)
+		})
+		t.Run("invalid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envReapInterval, "often")
+			_, err := reapInterval()
+			assert.Error(t, err)
+		})
+	})
+	t.Run("api-call-budget", func(t *testing.T) {
+		t.Run("not set", func(t *testing.T) {
+			os.Clearenv()
+			budget, err := apiCallBudget()
+			assert.NoError(t, err)
+			assert.Equal(t, 0, budget)
+		})
+		t.Run("positive", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envAPICallBudget, "50")
+			budget, err := apiCallBudget()
+			assert.NoError(t, err)
+			assert.Equal(t, 50, budget)
+		})
+		t.Run("negative", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envAPICallBudget, "-5")
+			budget, err := apiCallBudget()
+			assert.NoError(t, err)
+			assert.Equal(t, 0, budget)
+		})
+		t.Run("invalid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envAPICallBudget, "not a number")
+			_, err := apiCallBudget()
+			assert.Error(t, err)
+		})
+	})
+	t.Run("client-qps", func(t *testing.T) {
+		t.Run("not set", func(t *testing.T) {
+			os.Clearenv()
+			qps, err := clientQPS()
+			assert.NoError(t, err)
+			assert.Equal(t, float32(0), qps)
+		})
+		t.Run("valid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envClientQPS, "2.5")
+			qps, err := clientQPS()
+			assert.NoError(t, err)
+			assert.Equal(t, float32(2.5), qps)
+		})
+		t.Run("negative", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envClientQPS, "-1")
+			_, err := clientQPS()
+			assert.Error(t, err)
+		})
+		t.Run("invalid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envClientQPS, "fast")
+			_, err := clientQPS()
+			assert.Error(t, err)
+		})
+	})
+	t.Run("client-burst", func(t *testing.T) {
+		t.Run("not set", func(t *testing.T) {
+			os.Clearenv()
+			burst, err := clientBurst()
+			assert.NoError(t, err)
+			assert.Equal(t, 0, burst)
+		})
+		t.Run("valid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envClientBurst, "20")
+			burst, err := clientBurst()
+			assert.NoError(t, err)
+			assert.Equal(t, 20, burst)
+		})
+		t.Run("negative", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envClientBurst, "-1")
+			_, err := clientBurst()
+			assert.Error(t, err)
+		})
+	})
+	t.Run("admin", func(t *testing.T) {
+		t.Run("defaults", func(t *testing.T) {
+			os.Clearenv()
+			assert.Equal(t, "", adminAddress())
+			required, err := requireApproval("")
+			assert.NoError(t, err)
+			assert.False(t, required)
+			secret, err := slackSigningSecret("")
+			assert.NoError(t, err)
+			assert.Equal(t, "", secret)
+		})
+		t.Run("approval requires admin address", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envRequireApproval, "true")
+			_, err := requireApproval("")
+			assert.Error(t, err)
+			required, err := requireApproval(":8081")
+			assert.NoError(t, err)
+			assert.True(t, required)
+		})
+		t.Run("invalid approval", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envRequireApproval, "maybe")
+			_, err := requireApproval(":8081")
+			assert.Error(t, err)
+		})
+		t.Run("slack signing secret requires admin address", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envSlackSigningSecret, "secret")
+			_, err := slackSigningSecret("")
+			assert.Error(t, err)
+			secret, err := slackSigningSecret(":8081")
+			assert.NoError(t, err)
+			assert.Equal(t, "secret", secret)
+		})
+		t.Run("admin token from file", func(t *testing.T) {
+			os.Clearenv()
+			path := filepath.Join(t.TempDir(), "token")
+			assert.NoError(t, os.WriteFile(path, []byte("token\n"), 0600))
+			os.Setenv(envAdminToken+"_FILE", path)
+			token, err := adminToken(":8081")
+			assert.NoError(t, err)
+			assert.Equal(t, "token", token)
+		})
+		t.Run("admin token and file", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envAdminToken, "token")
+			os.Setenv(envAdminToken+"_FILE", "/var/run/secrets/token")
+			_, err := adminToken(":8081")
+			assert.Error(t, err)
+		})
+		t.Run("missing admin token file", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envAdminToken+"_FILE", filepath.Join(t.TempDir(), "missing"))
+			_, err := adminToken(":8081")
+			assert.Error(t, err)
+		})
+		t.Run("metrics token requires metrics address", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envMetricsToken, "token")
+			_, err := metricsToken("")
+			assert.Error(t, err)
+			token, err := metricsToken(":9090")
+			assert.NoError(t, err)
+			assert.Equal(t, "token", token)
+		})
+		t.Run("admin token requires admin address", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envAdminToken, "token")
+			_, err := adminToken("")
+			assert.Error(t, err)
+			token, err := adminToken(":8081")
+			assert.NoError(t, err)
+			assert.Equal(t, "token", token)
+		})
+		t.Run("admin address requires authentication", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envNamespace, "default")
+			os.Setenv("MAX_DURATION", "1h")
+			os.Setenv(envAdminAddress, ":8081")
+			_, err := LoadOptions()
+			assert.Error(t, err)
+			os.Setenv(envAdminToken, "token")
+			options, err := LoadOptions()
+			assert.NoError(t, err)
+			assert.True(t, options.adminAuthenticated())
+		})
+		t.Run("admin authenticated", func(t *testing.T) {
+			assert.False(t, Options{}.adminAuthenticated())
+			assert.False(t, Options{tlsConfig: &tls.Config{}}.adminAuthenticated())
+			assert.True(t, Options{adminToken: "token"}.adminAuthenticated())
+			assert.True(t, Options{tlsConfig: &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert}}.adminAuthenticated())
+		})
+	})
+	t.Run("tls", func(t *testing.T) {
+		dir := t.TempDir()
+		certFile, keyFile := testCertificate(t, dir, "server")
+		t.Run("not set", func(t *testing.T) {
+			os.Clearenv()
+			config, err := serverTLS(":9090", ":8081", ":8080")
+			assert.NoError(t, err)
+			assert.Nil(t, config)
+		})
+		t.Run("valid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envTLSCertFile, certFile)
+			os.Setenv(envTLSKeyFile, keyFile)
+			os.Setenv(envTLSClientCAFile, certFile)
+			config, err := serverTLS(":9090", ":8081", ":8080")
+			assert.NoError(t, err)
+			assert.Equal(t, tls.RequireAndVerifyClientCert, config.ClientAuth)
+		})
+		t.Run("certificate without key", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envTLSCertFile, certFile)
+			_, err := serverTLS(":9090", "", "")
+			assert.Error(t, err)
+		})
+		t.Run("client CA without certificate", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envTLSClientCAFile, certFile)
+			_, err := serverTLS(":9090", "", "")
+			assert.Error(t, err)
+		})
+		t.Run("nothing to serve", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envTLSCertFile, certFile)
+			os.Setenv(envTLSKeyFile, keyFile)
+			_, err := serverTLS("", "", ":8080")
+			assert.Error(t, err)
+		})
+		t.Run("health shares an address", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envTLSCertFile, certFile)
+			os.Setenv(envTLSKeyFile, keyFile)
+			_, err := serverTLS(":9090", "", ":9090")
+			assert.Error(t, err)
+		})
+	})
+	t.Run("reaper-policies", func(t *testing.T) {
+		t.Run("not set", func(t *testing.T) {
+			os.Clearenv()
+			enabled, err := reaperPolicies()
+			assert.NoError(t, err)
+			assert.False(t, enabled)
+		})
+		t.Run("sync interval default", func(t *testing.T) {
+			os.Clearenv()
+			interval, err := policySyncInterval()
+			assert.NoError(t, err)
+			assert.Equal(t, time.Minute, interval)
+		})
+		t.Run("invalid sync interval", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envReaperPolicySyncInterval, "0s")
+			_, err := policySyncInterval()
+			assert.Error(t, err)
+		})
+		t.Run("rules are not required", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envReaperPolicies, "true")
+			options, err := LoadOptions()
+			assert.NoError(t, err)
+			assert.True(t, options.reaperPolicies)
+			assert.Empty(t, options.rules.LoadedRules)
+		})
+	})
+	t.Run("health", func(t *testing.T) {
+		t.Run("defaults", func(t *testing.T) {
+			os.Clearenv()
+			assert.Equal(t, "", healthAddress())
+			grace, err := livenessGracePeriod()
+			assert.NoError(t, err)
+			assert.Equal(t, 5*time.Minute, grace)
+			threshold, err := readinessThreshold()
+			assert.NoError(t, err)
+			assert.Equal(t, 3, threshold)
+		})
+		t.Run("valid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envHealthAddress, ":8080")
+			os.Setenv(envLivenessGracePeriod, "10m")
+			os.Setenv(envReadinessFailureThreshold, "5")
+			assert.Equal(t, ":8080", healthAddress())
+			grace, err := livenessGracePeriod()
+			assert.NoError(t, err)
+			assert.Equal(t, 10*time.Minute, grace)
+			threshold, err := readinessThreshold()
+			assert.NoError(t, err)
+			assert.Equal(t, 5, threshold)
+		})
+		t.Run("invalid threshold", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envReadinessFailureThreshold, "0")
+			_, err := readinessThreshold()
+			assert.Error(t, err)
+			os.Setenv(envReadinessFailureThreshold, "three")
+			_, err = readinessThreshold()
+			assert.Error(t, err)
+		})
+	})
+	t.Run("leader-election", func(t *testing.T) {
+		t.Run("defaults", func(t *testing.T) {
+			os.Clearenv()
+			enabled, err := leaderElection()
+			assert.NoError(t, err)
+			assert.False(t, enabled)
+			assert.Equal(t, "pod-reaper", leaderElectionID())
+			leaseDuration, renewDeadline, retryPeriod, err := leaseTimings()
+			assert.NoError(t, err)
+			assert.Equal(t, 15*time.Second, leaseDuration)
+			assert.Equal(t, 10*time.Second, renewDeadline)
+			assert.Equal(t, 2*time.Second, retryPeriod)
+		})
+		t.Run("configured", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envLeaderElection, "true")
+			os.Setenv(envLeaderElectionID, "chaos-reaper")
+			os.Setenv(envLeaderElectionNamespace, "reapers")
+			os.Setenv(envLeaseDuration, "6s")
+			os.Setenv(envLeaseRenewDeadline, "4s")
+			os.Setenv(envLeaseRetryPeriod, "1s")
+			enabled, err := leaderElection()
+			assert.NoError(t, err)
+			assert.True(t, enabled)
+			assert.Equal(t, "chaos-reaper", leaderElectionID())
+			assert.Equal(t, "reapers", leaderElectionNamespace())
+			leaseDuration, renewDeadline, retryPeriod, err := leaseTimings()
+			assert.NoError(t, err)
+			assert.Equal(t, 6*time.Second, leaseDuration)
+			assert.Equal(t, 4*time.Second, renewDeadline)
+			assert.Equal(t, time.Second, retryPeriod)
+		})
+		t.Run("invalid timings", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envLeaseDuration, "5s")
+			os.Setenv(envLeaseRenewDeadline, "10s")
+			_, _, _, err := leaseTimings()
+			assert.Error(t, err)
+		})
+	})
+	t.Run("metrics-address", func(t *testing.T) {
+		os.Clearenv()
+		assert.Equal(t, "", metricsAddress())
+		os.Setenv(envMetricsAddress, ":9090")
+		assert.Equal(t, ":9090", metricsAddress())
+	})
+	t.Run("pod-sorting", func(t *testing.T) {
+		t.Run("default", func(t *testing.T) {
+			os.Clearenv()
+			sorter, err := podSortingStrategy()
+			assert.NotNil(t, sorter)
+			assert.NoError(t, err)
+			subject := testPodList()
+			sorter(subject)
+			assert.Equal(t, testPodList(), subject)
+		})
+		t.Run("invalid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envPodSortingStrategy, "not a valid sorting strategy")
+			_, err := podSortingStrategy()
+			assert.Error(t, err)
+		})
+		t.Run("random", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envPodSortingStrategy, "random")
+			sorter, err := podSortingStrategy()
+			assert.NotNil(t, sorter)
+			assert.NoError(t, err)
+
+			// Run multiple shuffles to verify randomness
+			// (single shuffle has 1/24 chance of returning original order)
+			original := testPodList()
+			shuffled := false
+			for i := 0; i < 10; i++ {
+				subject := testPodList()
+				sorter(subject)
+				assert.ElementsMatch(t, original, subject)
+				if !assert.ObjectsAreEqual(original, subject) {
+					shuffled = true
+				}
+			}
+			assert.True(t, shuffled, "random sorter should produce different orderings")
+		})
+		t.Run("random with seed", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envPodSortingStrategy, "random")
+			os.Setenv(envRandomSeed, "42")
+			first, err := podSortingStrategy()
+			assert.NoError(t, err)
+			second, err := podSortingStrategy()
+			assert.NoError(t, err)
+
+			// the same seed gives the same sequence of orders, regardless of the listed order
+			for i := 0; i < 5; i++ {
+				subject := testPodList()
+				first(subject)
+				reversed := testPodList()
+				for left, right := 0, len(reversed)-1; left < right; left, right = left+1, right-1 {
+					reversed[left], reversed[right] = reversed[right], reversed[left]
+				}
+				second(reversed)
+				assert.ElementsMatch(t, testPodList(), subject)
+				assert.Equal(t, subject, reversed)
+			}
+		})
+		t.Run("random with invalid seed", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envPodSortingStrategy, "random")
+			os.Setenv(envRandomSeed, "not a number")
+			_, err := podSortingStrategy()
+			assert.Error(t, err)
+		})
+		t.Run("oldest-first", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envPodSortingStrategy, "oldest-first")
+			sorter, err := podSortingStrategy()
+			assert.NotNil(t, sorter)
+			assert.NoError(t, err)
+			subject := testPodList()
+			sorter(subject)
+			assert.Equal(t, "corgi", subject[0].ObjectMeta.Name)
+			assert.Equal(t, "bearded-dragon", subject[1].ObjectMeta.Name)
+			assert.Equal(t, "expensive", subject[2].ObjectMeta.Name)
+			assert.Equal(t, "nil-start-time", subject[3].ObjectMeta.Name)
+			assert.ElementsMatch(t, testPodList(), subject)
+		})
+		t.Run("youngest-first", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envPodSortingStrategy, "youngest-first")
+			sorter, err := podSortingStrategy()
+			assert.NotNil(t, sorter)
+			assert.NoError(t, err)
+			subject := testPodList()
+			sorter(subject)
+			assert.Equal(t, "expensive", subject[0].ObjectMeta.Name)
+			assert.Equal(t, "bearded-dragon", subject[1].ObjectMeta.Name)
+			assert.Equal(t, "corgi", subject[2].ObjectMeta.Name)
+			assert.Equal(t, "nil-start-time", subject[3].ObjectMeta.Name)
+			assert.ElementsMatch(t, testPodList(), subject)
+		})
+		t.Run("most-restarts-first", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envPodSortingStrategy, "most-restarts-first")
+			sorter, err := podSortingStrategy()
+			assert.NotNil(t, sorter)
+			assert.NoError(t, err)
+			subject := testPodList()
+			subject[2].Status.ContainerStatuses = []v1.ContainerStatus{{RestartCount: 2}, {RestartCount: 3}}
+			subject[3].Status.InitContainerStatuses = []v1.ContainerStatus{{RestartCount: 7}}
+			sorter(subject)
+			assert.Equal(t, "corgi", subject[0].ObjectMeta.Name)
+			assert.Equal(t, "expensive", subject[1].ObjectMeta.Name)
+			// pods without restarts keep their order
+			assert.Equal(t, "bearded-dragon", subject[2].ObjectMeta.Name)
+			assert.Equal(t, "nil-start-time", subject[3].ObjectMeta.Name)
+		})
+		t.Run("pod-deletion-cost", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envPodSortingStrategy, "pod-deletion-cost")
+			sorter, err := podSortingStrategy()
+			assert.NotNil(t, sorter)
+			assert.NoError(t, err)
+			subject := testPodList()
+			sorter(subject)
+			assert.Equal(t, "nil-start-time", subject[0].ObjectMeta.Name)
+			assert.Equal(t, "expensive", subject[3].ObjectMeta.Name)
+			assert.ElementsMatch(t, testPodList(), subject)
+		})
+	})
+	t.Run("reap-request-limit", func(t *testing.T) {
+		t.Run("default", func(t *testing.T) {
+			os.Clearenv()
+			limit, err := reapRequestLimit()
+			assert.NoError(t, err)
+			assert.Equal(t, 10, limit)
+		})
+		t.Run("disabled", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envReapRequestLimit, "0")
+			limit, err := reapRequestLimit()
+			assert.NoError(t, err)
+			assert.Equal(t, 0, limit)
+		})
+		t.Run("invalid", func(t *testing.T) {
+			for _, value := range []string{"many", "-1"} {
+				os.Clearenv()
+				os.Setenv(envReapRequestLimit, value)
+				_, err := reapRequestLimit()
+				assert.Error(t, err, value)
+			}
+		})
+	})
+	t.Run("event-stream", func(t *testing.T) {
+		t.Run("default", func(t *testing.T) {
+			os.Clearenv()
+			enabled, err := eventStreamEnabled()
+			assert.NoError(t, err)
+			assert.True(t, enabled)
+		})
+		t.Run("disabled", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envEventStream, "false")
+			enabled, err := eventStreamEnabled()
+			assert.NoError(t, err)
+			assert.False(t, enabled)
+		})
+		t.Run("invalid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envEventStream, "sometimes")
+			_, err := eventStreamEnabled()
+			assert.Error(t, err)
+		})
+	})
+	t.Run("run-once", func(t *testing.T) {
+		t.Run("not set", func(t *testing.T) {
+			os.Clearenv()
+			once, err := runOnce()
+			assert.NoError(t, err)
+			assert.False(t, once)
+		})
+		t.Run("true", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envRunOnce, "true")
+			once, err := runOnce()
+			assert.NoError(t, err)
+			assert.True(t, once)
+		})
+		t.Run("invalid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envRunOnce, "sometimes")
+			_, err := runOnce()
+			assert.Error(t, err)
+		})
+	})
+	t.Run("use-informer", func(t *testing.T) {
+		t.Run("not set", func(t *testing.T) {
+			os.Clearenv()
+			useInformer, err := useInformer()
+			assert.NoError(t, err)
+			assert.False(t, useInformer)
+		})
+		t.Run("true", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envUseInformer, "true")
+			useInformer, err := useInformer()
+			assert.NoError(t, err)
+			assert.True(t, useInformer)
+		})
+		t.Run("invalid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envUseInformer, "outside expected values")
+			_, err := useInformer()
+			assert.Error(t, err)
+		})
+	})
+	t.Run("action", func(t *testing.T) {
+		t.Run("default", func(t *testing.T) {
+			os.Clearenv()
+			action, err := action()
+			assert.NoError(t, err)
+			assert.Equal(t, actionDelete, action)
+		})
+		t.Run("evict", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envEvict, "true")
+			action, err := action()
+			assert.NoError(t, err)
+			assert.Equal(t, actionEvict, action)
+		})
+		t.Run("annotate", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envAction, "annotate")
+			action, err := action()
+			assert.NoError(t, err)
+			assert.Equal(t, actionAnnotate, action)
+		})
+		t.Run("scale owner", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envAction, "scale-owner")
+			action, err := action()
+			assert.NoError(t, err)
+			assert.Equal(t, actionScaleOwner, action)
+		})
+		t.Run("preview", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envAction, "preview")
+			action, err := action()
+			assert.NoError(t, err)
+			assert.Equal(t, actionPreview, action)
+		})
+		t.Run("evict and action evict", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envEvict, "true")
+			os.Setenv(envAction, "evict")
+			action, err := action()
+			assert.NoError(t, err)
+			assert.Equal(t, actionEvict, action)
+		})
+		t.Run("evict conflicts", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envEvict, "true")
+			os.Setenv(envAction, "annotate")
+			_, err := action()
+			assert.Error(t, err)
+		})
+		t.Run("invalid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envAction, "shred")
+			_, err := action()
+			assert.Error(t, err)
+		})
+		t.Run("invalid evict", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envEvict, "sometimes")
+			_, err := action()
+			assert.Error(t, err)
+		})
+	})
+	t.Run("mark-grace", func(t *testing.T) {
+		t.Run("default", func(t *testing.T) {
+			os.Clearenv()
+			grace, err := markGrace(actionDelete)
+			assert.NoError(t, err)
+			assert.Equal(t, time.Duration(0), grace)
+		})
+		t.Run("valid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envMarkGrace, "15m")
+			grace, err := markGrace(actionEvict)
+			assert.NoError(t, err)
+			assert.Equal(t, 15*time.Minute, grace)
+		})
+		t.Run("negative", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envMarkGrace, "-1m")
+			_, err := markGrace(actionDelete)
+			assert.Error(t, err)
+		})
+		t.Run("annotate", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envMarkGrace, "15m")
+			_, err := markGrace(actionAnnotate)
+			assert.Error(t, err)
+		})
+		t.Run("preview", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envMarkGrace, "15m")
+			_, err := markGrace(actionPreview)
+			assert.Error(t, err)
+		})
+	})
+	t.Run("force-delete-stuck", func(t *testing.T) {
+		t.Run("not set", func(t *testing.T) {
+			os.Clearenv()
+			force, err := forceDeleteStuck(actionDelete)
+			assert.NoError(t, err)
+			assert.False(t, force)
+		})
+		t.Run("evict", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envForceDeleteStuck, "true")
+			force, err := forceDeleteStuck(actionEvict)
+			assert.NoError(t, err)
+			assert.True(t, force)
+		})
+		t.Run("annotate", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envForceDeleteStuck, "true")
+			_, err := forceDeleteStuck(actionAnnotate)
+			assert.Error(t, err)
+		})
+		t.Run("invalid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envForceDeleteStuck, "maybe")
+			_, err := forceDeleteStuck(actionDelete)
+			assert.Error(t, err)
+		})
+	})
+	t.Run("respect-topology-spread", func(t *testing.T) {
+		t.Run("not set", func(t *testing.T) {
+			os.Clearenv()
+			respect, err := respectTopologySpread()
+			assert.NoError(t, err)
+			assert.False(t, respect)
+		})
+		t.Run("true", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envRespectTopologySpread, "true")
+			respect, err := respectTopologySpread()
+			assert.NoError(t, err)
+			assert.True(t, respect)
+		})
+		t.Run("invalid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envRespectTopologySpread, "outside expected values")
+			_, err := respectTopologySpread()
+			assert.Error(t, err)
+		})
+	})
+	t.Run("emit-events", func(t *testing.T) {
+		t.Run("not set", func(t *testing.T) {
+			os.Clearenv()
+			emitEvents, err := emitEvents()
+			assert.NoError(t, err)
+			assert.False(t, emitEvents)
+		})
+		t.Run("true", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envEmitEvents, "true")
+			emitEvents, err := emitEvents()
+			assert.NoError(t, err)
+			assert.True(t, emitEvents)
+		})
+		t.Run("invalid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envEmitEvents, "outside expected values")
+			_, err := emitEvents()
+			assert.Error(t, err)
+		})
+	})
+	t.Run("emit-skip-events", func(t *testing.T) {
+		t.Run("not set", func(t *testing.T) {
+			os.Clearenv()
+			emitSkipEvents, err := emitSkipEvents()
+			assert.NoError(t, err)
+			assert.False(t, emitSkipEvents)
+		})
+		t.Run("true", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envEmitSkipEvents, "true")
+			emitSkipEvents, err := emitSkipEvents()
+			assert.NoError(t, err)
+			assert.True(t, emitSkipEvents)
+		})
+		t.Run("invalid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envEmitSkipEvents, "outside expected values")
+			_, err := emitSkipEvents()
+			assert.Error(t, err)
+		})
+	})
+	t.Run("namespace-rules", func(t *testing.T) {
+		t.Run("not set", func(t *testing.T) {
+			os.Clearenv()
+			namespaceRules, err := namespaceRules()
+			assert.NoError(t, err)
+			assert.False(t, namespaceRules)
+		})
+		t.Run("true", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envNamespaceRules, "true")
+			namespaceRules, err := namespaceRules()
+			assert.NoError(t, err)
+			assert.True(t, namespaceRules)
+		})
+		t.Run("invalid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envNamespaceRules, "outside expected values")
+			_, err := namespaceRules()
+			assert.Error(t, err)
+		})
+	})
+	t.Run("verdict-annotations", func(t *testing.T) {
+		t.Run("not set", func(t *testing.T) {
+			os.Clearenv()
+			verdictAnnotations, err := verdictAnnotations()
+			assert.NoError(t, err)
+			assert.False(t, verdictAnnotations)
+		})
+		t.Run("true", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envVerdictAnnotations, "true")
+			verdictAnnotations, err := verdictAnnotations()
+			assert.NoError(t, err)
+			assert.True(t, verdictAnnotations)
+		})
+		t.Run("invalid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envVerdictAnnotations, "outside expected values")
+			_, err := verdictAnnotations()
+			assert.Error(t, err)
+		})
+	})
+	t.Run("annotate-owners", func(t *testing.T) {
+		t.Run("not set", func(t *testing.T) {
+			os.Clearenv()
+			annotate, err := annotateOwners(actionDelete)
+			assert.NoError(t, err)
+			assert.False(t, annotate)
+		})
+		t.Run("true", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envAnnotateOwners, "true")
+			annotate, err := annotateOwners(actionDelete)
+			assert.NoError(t, err)
+			assert.True(t, annotate)
+		})
+		t.Run("invalid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envAnnotateOwners, "outside expected values")
+			_, err := annotateOwners(actionDelete)
+			assert.Error(t, err)
+		})
+		t.Run("never reaps", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envAnnotateOwners, "true")
+			_, err := annotateOwners(actionAnnotate)
+			assert.Error(t, err)
+		})
+	})
+	t.Run("protect-job-backoff", func(t *testing.T) {
+		t.Run("not set", func(t *testing.T) {
+			os.Clearenv()
+			protect, err := protectJobBackoff(actionDelete)
+			assert.NoError(t, err)
+			assert.False(t, protect)
+		})
+		t.Run("true", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envProtectJobBackoff, "true")
+			protect, err := protectJobBackoff(actionEvict)
+			assert.NoError(t, err)
+			assert.True(t, protect)
+		})
+		t.Run("invalid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envProtectJobBackoff, "outside expected values")
+			_, err := protectJobBackoff(actionDelete)
+			assert.Error(t, err)
+		})
+		t.Run("action without job failures", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envProtectJobBackoff, "true")
+			_, err := protectJobBackoff(actionAnnotate)
+			assert.Error(t, err)
+		})
+	})
+	t.Run("min-ready-replicas", func(t *testing.T) {
+		t.Run("not set", func(t *testing.T) {
+			os.Clearenv()
+			minReady, err := minReadyReplicas(actionDelete)
+			assert.NoError(t, err)
+			assert.Nil(t, minReady)
+		})
+		t.Run("valid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envMinReadyReplicas, "2")
+			minReady, err := minReadyReplicas(actionEvict)
+			assert.NoError(t, err)
+			if assert.NotNil(t, minReady) {
+				assert.Equal(t, int32(2), *minReady)
+			}
+		})
+		t.Run("zero", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envMinReadyReplicas, "0")
+			minReady, err := minReadyReplicas(actionDelete)
+			assert.NoError(t, err)
+			assert.NotNil(t, minReady)
+		})
+		t.Run("invalid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envMinReadyReplicas, "some")
+			_, err := minReadyReplicas(actionDelete)
+			assert.Error(t, err)
+		})
+		t.Run("negative", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envMinReadyReplicas, "-1")
+			_, err := minReadyReplicas(actionDelete)
+			assert.Error(t, err)
+		})
+		t.Run("action without ready pods removed", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envMinReadyReplicas, "1")
+			_, err := minReadyReplicas(actionScaleOwner)
+			assert.Error(t, err)
+		})
+	})
+	t.Run("verdict-annotation-interval", func(t *testing.T) {
+		t.Run("default", func(t *testing.T) {
+			os.Clearenv()
+			interval, err := verdictInterval()
+			assert.NoError(t, err)
+			assert.Equal(t, time.Hour, interval)
+		})
+		t.Run("valid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envVerdictAnnotationInterval, "10m")
+			interval, err := verdictInterval()
+			assert.NoError(t, err)
+			assert.Equal(t, 10*time.Minute, interval)
+		})
+		t.Run("invalid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envVerdictAnnotationInterval, "not-a-duration")
+			_, err := verdictInterval()
+			assert.Error(t, err)
+		})
+	})
+	t.Run("reap-webhook", func(t *testing.T) {
+		t.Run("not set", func(t *testing.T) {
+			os.Clearenv()
+			webhook, err := reapWebhook()
+			assert.NoError(t, err)
+			assert.Nil(t, webhook)
+		})
+		t.Run("defaults", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envReapWebhookURL, "http://example.com/hook")
+			webhook, err := reapWebhook()
+			assert.NoError(t, err)
+			assert.Equal(t, "http://example.com/hook", webhook.(*webhookNotifier).url)
+			assert.Equal(t, 5*time.Second, webhook.(*webhookNotifier).client.Timeout)
+			assert.Equal(t, 2, webhook.(*webhookNotifier).retries)
+		})
+		t.Run("configured", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envReapWebhookURL, "http://example.com/hook")
+			os.Setenv(envReapWebhookTimeout, "1s")
+			os.Setenv(envReapWebhookRetries, "0")
+			webhook, err := reapWebhook()
+			assert.NoError(t, err)
+			assert.Equal(t, time.Second, webhook.(*webhookNotifier).client.Timeout)
+			assert.Equal(t, 0, webhook.(*webhookNotifier).retries)
+		})
+		t.Run("invalid timeout", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envReapWebhookURL, "http://example.com/hook")
+			os.Setenv(envReapWebhookTimeout, "not-a-duration")
+			_, err := reapWebhook()
+			assert.Error(t, err)
+		})
+		t.Run("invalid retries", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envReapWebhookURL, "http://example.com/hook")
+			for _, retries := range []string{"not-a-number", "-1"} {
+				os.Setenv(envReapWebhookRetries, retries)
+				_, err := reapWebhook()
+				assert.Error(t, err)
+			}
+		})
+		t.Run("notifiers", func(t *testing.T) {
+			os.Clearenv()
+			loaded, err := notifiers()
+			assert.NoError(t, err)
+			assert.Empty(t, loaded)
+			os.Setenv(envReapWebhookURL, "http://example.com/hook")
+			loaded, err = notifiers()
+			assert.NoError(t, err)
+			assert.Equal(t, 1, len(loaded))
+		})
+	})
+	t.Run("reap-records", func(t *testing.T) {
+		t.Run("not set", func(t *testing.T) {
+			os.Clearenv()
+			records, err := reapRecords()
+			assert.NoError(t, err)
+			assert.Nil(t, records)
+		})
+		t.Run("stdout", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envReapRecords, "stdout")
+			records, err := reapRecords()
+			assert.NoError(t, err)
+			assert.Equal(t, os.Stdout, records.(*recordNotifier).writer)
+		})
+		t.Run("invalid", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envReapRecords, "/missing/directory/records.ndjson")
+			_, err := reapRecords()
+			assert.Error(t, err)
+		})
+	})
+	t.Run("audit", func(t *testing.T) {
+		t.Run("not set", func(t *testing.T) {
+			os.Clearenv()
+			audit, err := audit()
+			assert.NoError(t, err)
+			assert.Nil(t, audit)
+		})
+		t.Run("defaults", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envAuditSink, "file:///var/log/pod-reaper/audit.jsonl")
+			audit, err := audit()
+			assert.NoError(t, err)
+			assert.Equal(t, &fileAuditSink{path: "/var/log/pod-reaper/audit.jsonl", maxSize: 10 * 1024 * 1024, maxBackups: 3}, audit.sink)
+		})
+		t.Run("configmap", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envAuditSink, "configmap://reaper/audit")
+			os.Setenv(envAuditConfigMapMaxRecords, "50")
+			audit, err := audit()
+			assert.NoError(t, err)
+			assert.Equal(t, &configMapAuditSink{namespace: "reaper", configMap: "audit", maxRecords: 50}, audit.sink)
+		})
+		t.Run("invalid sink", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envAuditSink, "ftp://example.com/audit")
+			_, err := audit()
+			assert.Error(t, err)
+		})
+		t.Run("invalid max size", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envAuditSink, "file:///tmp/audit.jsonl")
+			os.Setenv(envAuditFileMaxSize, "0")
+			_, err := audit()
+			assert.Error(t, err)
+		})
+		t.Run("invalid max backups", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envAuditSink, "file:///tmp/audit.jsonl")
+			os.Setenv(envAuditFileMaxBackups, "-1")
+			_, err := audit()
+			assert.Error(t, err)
+		})
+	})
+	t.Run("slack", func(t *testing.T) {
+		t.Run("not set", func(t *testing.T) {
+			os.Clearenv()
+			slack, err := slack()
+			assert.NoError(t, err)
+			assert.Nil(t, slack)
+		})
+		t.Run("defaults", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envSlackWebhookURL, "https://hooks.slack.com/services/example")
+			loaded, err := slack()
+			assert.NoError(t, err)
+			slack := loaded.(*slackNotifier)
+			assert.Equal(t, "https://hooks.slack.com/services/example", slack.url)
+			assert.Equal(t, "", slack.channel)
+			assert.False(t, slack.summary)
+			assert.Equal(t, defaultNotifyRetries, slack.retries)
+		})
+		t.Run("configured", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envSlackWebhookURL, "https://hooks.slack.com/services/example")
+			os.Setenv(envSlackChannel, "#alerts")
+			os.Setenv(envSlackTemplate, "{{.Pod}}")
+			os.Setenv(envSlackSummary, "true")
+			loaded, err := slack()
+			assert.NoError(t, err)
+			slack := loaded.(*slackNotifier)
+			assert.Equal(t, "#alerts", slack.channel)
+			assert.True(t, slack.summary)
+		})
+		t.Run("invalid template", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envSlackWebhookURL, "https://hooks.slack.com/services/example")
+			os.Setenv(envSlackTemplate, "{{")
+			_, err := slack()
+			assert.Error(t, err)
+		})
+		t.Run("invalid summary", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envSlackWebhookURL, "https://hooks.slack.com/services/example")
+			os.Setenv(envSlackSummary, "outside expected values")
+			_, err := slack()
+			assert.Error(t, err)
+		})
+	})
+	t.Run("warehouse", func(t *testing.T) {
+		t.Run("not set", func(t *testing.T) {
+			os.Clearenv()
+			warehouse, err := warehouse()
+			assert.NoError(t, err)
+			assert.Nil(t, warehouse)
+		})
+		t.Run("defaults", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envWarehouseExport, "bigquery://project/dataset/table")
+			warehouse, err := warehouse()
+			assert.NoError(t, err)
+			assert.Equal(t, "project.dataset.table", warehouse.table.name())
+			assert.Equal(t, 500, warehouse.batchSize)
+			assert.Equal(t, time.Hour, warehouse.interval)
+			assert.IsType(t, &metadataTokenSource{}, warehouse.table.tokens)
+		})
+		t.Run("service account key", func(t *testing.T) {
+			os.Clearenv()
+			path, _ := testServiceAccountKey(t, "https://oauth2.googleapis.com/token")
+			os.Setenv(envWarehouseExport, "bigquery://project/dataset/table")
+			os.Setenv(envWarehouseBatchSize, "100")
+			os.Setenv(envWarehouseFlushInterval, "15m")
+			os.Setenv(envGoogleApplicationCredentials, path)
+			warehouse, err := warehouse()
+			assert.NoError(t, err)
+			assert.Equal(t, 100, warehouse.batchSize)
+			assert.Equal(t, 15*time.Minute, warehouse.interval)
+			assert.IsType(t, &serviceAccountTokenSource{}, warehouse.table.tokens)
+		})
+		t.Run("invalid table", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envWarehouseExport, "bigquery://project")
+			_, err := warehouse()
+			assert.Error(t, err)
+		})
+		t.Run("batch size too large", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envWarehouseExport, "bigquery://project/dataset/table")
+			os.Setenv(envWarehouseBatchSize, "501")
+			_, err := warehouse()
+			assert.Error(t, err)
+		})
+		t.Run("missing key file", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envWarehouseExport, "bigquery://project/dataset/table")
+			os.Setenv(envGoogleApplicationCredentials, "/does/not/exist.json")
+			_, err := warehouse()
+			assert.Error(t, err)
+		})
+	})
+	t.Run("elasticsearch", func(t *testing.T) {
+		t.Run("not set", func(t *testing.T) {
+			os.Clearenv()
+			elasticsearch, err := elasticsearch()
+			assert.NoError(t, err)
+			assert.Nil(t, elasticsearch)
+		})
+		t.Run("defaults", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envElasticsearchURL, "https://elasticsearch:9200/")
+			loaded, err := elasticsearch()
+			assert.NoError(t, err)
+			elasticsearch := loaded.(*elasticsearchNotifier)
+			assert.Equal(t, "https://elasticsearch:9200", elasticsearch.url)
+			assert.Equal(t, "pod-reaper", elasticsearch.index)
+			assert.Equal(t, "", elasticsearch.apiKey)
+			assert.Equal(t, defaultNotifyRetries, elasticsearch.retries)
+		})
+		t.Run("configured", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envElasticsearchURL, "https://elasticsearch:9200")
+			os.Setenv(envElasticsearchIndex, "reaps")
+			os.Setenv(envElasticsearchUsername, "user")
+			os.Setenv(envElasticsearchPassword, "password")
+			os.Setenv(envElasticsearchAPIKey, "key")
+			loaded, err := elasticsearch()
+			assert.NoError(t, err)
+			elasticsearch := loaded.(*elasticsearchNotifier)
+			assert.Equal(t, "reaps", elasticsearch.index)
+			assert.Equal(t, "user", elasticsearch.username)
+			assert.Equal(t, "password", elasticsearch.password)
+			assert.Equal(t, "key", elasticsearch.apiKey)
+		})
+		t.Run("password file", func(t *testing.T) {
+			os.Clearenv()
+			path := filepath.Join(t.TempDir(), "password")
+			assert.NoError(t, os.WriteFile(path, []byte("password\n"), 0600))
+			os.Setenv(envElasticsearchURL, "https://elasticsearch:9200")
+			os.Setenv(envElasticsearchPassword+"_FILE", path)
+			loaded, err := elasticsearch()
+			assert.NoError(t, err)
+			assert.Equal(t, "password", loaded.(*elasticsearchNotifier).password)
+		})
+		t.Run("api key and api key file", func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envElasticsearchURL, "https://elasticsearch:9200")
+			os.Setenv(envElasticsearchAPIKey, "key")
+			os.Setenv(envElasticsearchAPIKey+"_FILE", "/tmp/key")
+			_, err := elasticsearch()
+			assert.Error(t, err)
+		})
+	})
+}
+
+func TestOptionsLoad(t *testing.T) {
+	t.Run("invalid options", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envRunDuration, "invalid")
+		_, err := LoadOptions()
+		assert.Error(t, err)
+	})
+	t.Run("no rules", func(t *testing.T) {
+		os.Clearenv()
+		_, err := LoadOptions()
+		assert.Error(t, err)
+	})
+	t.Run("valid", func(t *testing.T) {
+		os.Clearenv()
+		// ensure at least one rule loads
+		os.Setenv("CHAOS_CHANCE", "0.5")
+		options, err := LoadOptions()
+		assert.NoError(t, err)
+		assert.Equal(t, "@every 1m", options.schedule)
+		assert.Equal(t, 0*time.Second, options.runDuration)
+		assert.Nil(t, options.labelExclusion)
+		assert.Nil(t, options.labelRequirement)
+	})
+	t.Run("failsafe", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv("CHAOS_CHANCE", "1.0")
+		_, err := LoadOptions()
+		assert.EqualError(t, err, "refusing to reap nearly every pod in every namespace (chaos chance 1 flags every pod): set a namespace, label, annotation, owner, or node filter, or set I_UNDERSTAND_THE_RISK=true")
+		os.Setenv(envIUnderstandTheRisk, "true")
+		_, err = LoadOptions()
+		assert.NoError(t, err)
+	})
+}
+
+func TestFailsafe(t *testing.T) {
+	sweeping := func() Options {
+		os.Clearenv()
+		opts := minimalOptions("1.0")
+		opts.namespace = ""
+		return opts
+	}
+	t.Run("sweeping", func(t *testing.T) {
+		assert.Error(t, failsafe(sweeping()))
+	})
+	t.Run("short max duration", func(t *testing.T) {
+		opts := sweeping()
+		loaded, err := rules.LoadRulesFromMap(map[string]string{"MAX_DURATION": "10m"})
+		assert.NoError(t, err)
+		opts.rules = loaded
+		assert.Error(t, failsafe(opts))
+	})
+	t.Run("restrictive rule", func(t *testing.T) {
+		opts := sweeping()
+		loaded, err := rules.LoadRulesFromMap(map[string]string{"CHAOS_CHANCE": "1.0", "MAX_DURATION": "24h"})
+		assert.NoError(t, err)
+		opts.rules = loaded
+		assert.NoError(t, failsafe(opts))
+	})
+	t.Run("namespace", func(t *testing.T) {
+		opts := sweeping()
+		opts.namespace = "default"
+		assert.NoError(t, failsafe(opts))
+	})
+	t.Run("label filter", func(t *testing.T) {
+		opts := sweeping()
+		opts.labelRequirement, _ = labels.NewRequirement("app", selection.Exists, nil)
+		assert.NoError(t, failsafe(opts))
+	})
+	t.Run("field selector", func(t *testing.T) {
+		opts := sweeping()
+		opts.fieldSelector = fields.OneTermNotEqualSelector("status.phase", "Running")
+		assert.NoError(t, failsafe(opts))
+	})
+	t.Run("node selector", func(t *testing.T) {
+		opts := sweeping()
+		opts.nodeSelector = labels.Everything()
+		assert.NoError(t, failsafe(opts))
+	})
+	t.Run("dry run", func(t *testing.T) {
+		opts := sweeping()
+		opts.dryRun = true
+		assert.NoError(t, failsafe(opts))
+	})
+	t.Run("preview", func(t *testing.T) {
+		opts := sweeping()
+		opts.action = actionPreview
+		assert.NoError(t, failsafe(opts))
+	})
+	t.Run("understood", func(t *testing.T) {
+		opts := sweeping()
+		os.Setenv(envIUnderstandTheRisk, "true")
+		assert.NoError(t, failsafe(opts))
+	})
+	t.Run("not understood", func(t *testing.T) {
+		opts := sweeping()
+		os.Setenv(envIUnderstandTheRisk, "false")
+		assert.Error(t, failsafe(opts))
+	})
+	t.Run("invalid", func(t *testing.T) {
+		opts := sweeping()
+		os.Setenv(envIUnderstandTheRisk, "yes please")
+		assert.Error(t, failsafe(opts))
+	})
+}
+
+func TestLoadOptionsWithOptions(t *testing.T) {
+	t.Run("override environment", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envNamespace, "default")
+		os.Setenv(envMaxPods, "5")
+		os.Setenv("MAX_DURATION", "1h")
+		options, err := LoadOptions(WithNamespaces("one", " two", "one"), WithDryRun(true), WithMaxPods(2),
+			WithAPICallBudget(50), WithClientRateLimit(1.5, 3), WithListPageSize(-1))
+		assert.NoError(t, err)
+		assert.Equal(t, "", options.namespace)
+		assert.Equal(t, []string{"one", "two"}, options.namespaces)
+		assert.True(t, options.dryRun)
+		assert.Equal(t, 2, options.maxPods)
+		assert.Equal(t, 50, options.apiCallBudget)
+		assert.Equal(t, float32(1.5), options.clientQPS)
+		assert.Equal(t, 3, options.clientBurst)
+		assert.Equal(t, 0, options.listPageSize)
+	})
+	t.Run("checked by the failsafe", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv("MAX_DURATION", "10m")
+		_, err := LoadOptions()
+		assert.Error(t, err)
+		_, err = LoadOptions(WithNamespaces("default"))
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,125 @@
+package reaper
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+)
+
+// with ANNOTATE_OWNERS, the workloads of reaped pods are labelled and annotated with their reap history
+const labelOwnerReaped = "pod-reaper/reaped"
+const annotationLastReapedAt = "pod-reaper/last-reaped-at"
+const annotationReapCount = "pod-reaper/reap-count"
+const annotationLastReapReason = "pod-reaper/last-reap-reason"
+
+// annotateOwner records the reap of the pod on the workload controlling it: the deployment of its replica set, the
+// cron job of its job, or its stateful set, daemon set, replica set, or job. Failures are logged, they never fail the
+// reap. Pods of other owners are left alone.
+func (reaper reaper) annotateOwner(pod v1.Pod, reasons []string, now time.Time) {
+	if !reaper.options.annotateOwners {
+		return
+	}
+	owner := metav1.GetControllerOf(&pod)
+	if owner == nil {
+		return
+	}
+	reason := strings.Join(reaper.matchedRules, ",")
+	if reason == "" {
+		reason = strings.Join(reasons, "; ")
+	}
+	ownerLog := logrus.WithFields(logrus.Fields{"pod": pod.Name, "namespace": pod.Namespace, "owner": owner.Kind + "/" + owner.Name})
+	// the reap count is read and written back, so concurrent reaps of the same workload retry on conflict
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		ctx, cancel := reaper.apiContext()
+		defer cancel()
+		target, err := reaper.getWorkload(ctx, pod.Namespace, owner.Kind, owner.Name)
+		if err != nil || target == nil {
+			return err
+		}
+		labels := target.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[labelOwnerReaped] = "true"
+		target.SetLabels(labels)
+		annotations := target.GetAnnotations()
+		count, _ := strconv.Atoi(annotations[annotationReapCount])
+		target.SetAnnotations(mergeAnnotations(annotations, map[string]string{
+			annotationLastReapedAt:   now.UTC().Format(time.RFC3339),
+			annotationReapCount:      strconv.Itoa(count + 1),
+			annotationLastReapReason: reason,
+		}))
+		if !reaper.apiCall(operationUpdate) {
+			return errAPIBudgetExhausted
+		}
+		return reaper.updateWorkload(ctx, target)
+	})
+	if err != nil {
+		ownerLog.WithError(err).Warn("unable to annotate owner of reaped pod")
+	}
+}
+
+// getWorkload returns the workload an owner of a reaped pod belongs to, following replica sets to their deployment
+// and jobs to their cron job, or nil if the owner is not a known workload kind.
+func (reaper reaper) getWorkload(ctx context.Context, namespace string, kind string, name string) (metav1.Object, error) {
+	switch kind {
+	case "ReplicaSet", "Job", "StatefulSet", "DaemonSet", "Deployment", "CronJob":
+	default:
+		return nil, nil
+	}
+	if !reaper.apiCall(operationGet) {
+		return nil, errAPIBudgetExhausted
+	}
+	var object metav1.Object
+	var err error
+	switch kind {
+	case "ReplicaSet":
+		object, err = reaper.clientSet.AppsV1().ReplicaSets(namespace).Get(ctx, name, metav1.GetOptions{})
+	case "Job":
+		object, err = reaper.clientSet.BatchV1().Jobs(namespace).Get(ctx, name, metav1.GetOptions{})
+	case "StatefulSet":
+		object, err = reaper.clientSet.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+	case "DaemonSet":
+		object, err = reaper.clientSet.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+	case "Deployment":
+		object, err = reaper.clientSet.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	case "CronJob":
+		object, err = reaper.clientSet.BatchV1().CronJobs(namespace).Get(ctx, name, metav1.GetOptions{})
+	}
+	if err != nil {
+		return nil, err
+	}
+	if owner := metav1.GetControllerOf(object); owner != nil &&
+		(kind == "ReplicaSet" && owner.Kind == "Deployment" || kind == "Job" && owner.Kind == "CronJob") {
+		return reaper.getWorkload(ctx, namespace, owner.Kind, owner.Name)
+	}
+	return object, nil
+}
+
+func (reaper reaper) updateWorkload(ctx context.Context, target metav1.Object) error {
+	namespace := target.GetNamespace()
+	var err error
+	switch object := target.(type) {
+	case *appsv1.ReplicaSet:
+		_, err = reaper.clientSet.AppsV1().ReplicaSets(namespace).Update(ctx, object, metav1.UpdateOptions{})
+	case *batchv1.Job:
+		_, err = reaper.clientSet.BatchV1().Jobs(namespace).Update(ctx, object, metav1.UpdateOptions{})
+	case *appsv1.StatefulSet:
+		_, err = reaper.clientSet.AppsV1().StatefulSets(namespace).Update(ctx, object, metav1.UpdateOptions{})
+	case *appsv1.DaemonSet:
+		_, err = reaper.clientSet.AppsV1().DaemonSets(namespace).Update(ctx, object, metav1.UpdateOptions{})
+	case *appsv1.Deployment:
+		_, err = reaper.clientSet.AppsV1().Deployments(namespace).Update(ctx, object, metav1.UpdateOptions{})
+	case *batchv1.CronJob:
+		_, err = reaper.clientSet.BatchV1().CronJobs(namespace).Update(ctx, object, metav1.UpdateOptions{})
+	}
+	return err
+}
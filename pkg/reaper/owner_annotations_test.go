@@ -0,0 +1,81 @@
+package reaper
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestAnnotateOwner(t *testing.T) {
+	controller := true
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}}
+	replicaSet := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "web-abc", Namespace: "default",
+		OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: "web", Controller: &controller}}}}
+
+	t.Run("deployment", func(t *testing.T) {
+		opts := minimalOptions("1.0")
+		opts.annotateOwners = true
+		r := createTestReaper(opts)
+		r.clientSet.AppsV1().Deployments("default").Create(context.TODO(), deployment.DeepCopy(), metav1.CreateOptions{})
+		r.clientSet.AppsV1().ReplicaSets("default").Create(context.TODO(), replicaSet.DeepCopy(), metav1.CreateOptions{})
+		r.matchedRules = []string{"chaos", "duration"}
+
+		r.annotateOwner(testOwnedPod("web-abc-1", "web-abc"), []string{"was flagged for chaos"}, now)
+		r.annotateOwner(testOwnedPod("web-abc-2", "web-abc"), []string{"was flagged for chaos"}, now)
+
+		updated, err := r.clientSet.AppsV1().Deployments("default").Get(context.TODO(), "web", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, "true", updated.Labels[labelOwnerReaped])
+		assert.Equal(t, "2", updated.Annotations[annotationReapCount])
+		assert.Equal(t, "2020-01-02T03:04:05Z", updated.Annotations[annotationLastReapedAt])
+		assert.Equal(t, "chaos,duration", updated.Annotations[annotationLastReapReason])
+		unchanged, err := r.clientSet.AppsV1().ReplicaSets("default").Get(context.TODO(), "web-abc", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Empty(t, unchanged.Annotations)
+	})
+	t.Run("reasons without matched rules", func(t *testing.T) {
+		opts := minimalOptions("1.0")
+		opts.annotateOwners = true
+		r := createTestReaper(opts)
+		r.clientSet.AppsV1().ReplicaSets("default").Create(context.TODO(), &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "orphan", Namespace: "default"}}, metav1.CreateOptions{})
+
+		r.annotateOwner(testOwnedPod("orphan-1", "orphan"), []string{"was flagged for chaos"}, now)
+
+		updated, err := r.clientSet.AppsV1().ReplicaSets("default").Get(context.TODO(), "orphan", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, "1", updated.Annotations[annotationReapCount])
+		assert.Equal(t, "was flagged for chaos", updated.Annotations[annotationLastReapReason])
+	})
+	t.Run("missing owner", func(t *testing.T) {
+		opts := minimalOptions("1.0")
+		opts.annotateOwners = true
+		r := createTestReaper(opts)
+		assert.NotPanics(t, func() { r.annotateOwner(testOwnedPod("gone-1", "gone"), nil, now) })
+	})
+	t.Run("unknown owner kind", func(t *testing.T) {
+		opts := minimalOptions("1.0")
+		opts.annotateOwners = true
+		r := createTestReaper(opts)
+		pod := createTestPod("custom-1", "default", nil)
+		pod.OwnerReferences = []metav1.OwnerReference{{Kind: "Rollout", Name: "custom", Controller: &controller}}
+		r.annotateOwner(pod, nil, now)
+		assert.Empty(t, r.clientSet.(*fake.Clientset).Actions())
+	})
+	t.Run("disabled", func(t *testing.T) {
+		r := createTestReaper(minimalOptions("1.0"))
+		r.clientSet.AppsV1().Deployments("default").Create(context.TODO(), deployment.DeepCopy(), metav1.CreateOptions{})
+		r.clientSet.AppsV1().ReplicaSets("default").Create(context.TODO(), replicaSet.DeepCopy(), metav1.CreateOptions{})
+
+		r.annotateOwner(testOwnedPod("web-abc-1", "web-abc"), nil, now)
+
+		updated, err := r.clientSet.AppsV1().Deployments("default").Get(context.TODO(), "web", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Empty(t, updated.Annotations)
+	})
+}
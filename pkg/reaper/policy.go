@@ -0,0 +1,232 @@
+package reaper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/sirupsen/logrus"
+	"github.com/target/pod-reaper/rules"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var reaperPolicyResource = schema.GroupVersionResource{
+	Group:    "pod-reaper.target.com",
+	Version:  "v1alpha1",
+	Resource: "reaperpolicies",
+}
+
+// reaperPolicySpec is the spec of a ReaperPolicy custom resource.
+type reaperPolicySpec struct {
+	Schedule          string                `json:"schedule"`
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+	MaxPods           int                   `json:"maxPods,omitempty"`
+	// Rules uses the same keys as the rule environment variables, for example MAX_DURATION
+	Rules map[string]string `json:"rules"`
+}
+
+// reaperPolicy is a parsed and loaded ReaperPolicy.
+type reaperPolicy struct {
+	name string
+	// resourceVersion identifies the version of the policy that was loaded, so unchanged policies are not reloaded
+	resourceVersion string
+	schedule        cron.Schedule
+	// namespaceSelector is nil when the policy applies to every namespace pod-reaper lists
+	namespaceSelector labels.Selector
+	// namespaces replace the namespaces pod-reaper lists pods from, nil to keep them
+	namespaces          []string
+	maxPods             int
+	maxPodsPerNamespace int
+	rules               rules.Rules
+}
+
+func parseReaperPolicy(object unstructured.Unstructured) (reaperPolicy, error) {
+	policy := reaperPolicy{
+		name:            object.GetName(),
+		resourceVersion: object.GetResourceVersion(),
+	}
+	content, found, err := unstructured.NestedMap(object.Object, "spec")
+	if err != nil {
+		return policy, err
+	} else if !found {
+		return policy, errors.New("policy has no spec")
+	}
+	spec := reaperPolicySpec{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(content, &spec); err != nil {
+		return policy, err
+	}
+	if policy.schedule, err = scheduleParser.Parse(spec.Schedule); err != nil {
+		return policy, fmt.Errorf("invalid schedule %q: %s", spec.Schedule, err)
+	}
+	if spec.NamespaceSelector != nil {
+		if policy.namespaceSelector, err = metav1.LabelSelectorAsSelector(spec.NamespaceSelector); err != nil {
+			return policy, fmt.Errorf("invalid namespaceSelector: %s", err)
+		}
+	}
+	if spec.MaxPods < 0 {
+		return policy, fmt.Errorf("invalid maxPods %d, must be non-negative", spec.MaxPods)
+	}
+	policy.maxPods = spec.MaxPods
+	if policy.rules, err = rules.LoadRulesFromMap(spec.Rules); err != nil {
+		return policy, err
+	}
+	return policy, nil
+}
+
+// policyController keeps a scheduled reap cycle for each ReaperPolicy in the cluster, adding, replacing and removing
+// cycles as policies are created, updated and deleted.
+type policyController struct {
+	client  dynamic.Interface
+	mutex   sync.Mutex
+	entries map[string]policyEntry
+}
+
+type policyEntry struct {
+	resourceVersion string
+	id              cron.EntryID
+}
+
+func newPolicyController(client dynamic.Interface) *policyController {
+	return &policyController{
+		client:  client,
+		entries: map[string]policyEntry{},
+	}
+}
+
+// start syncs policies immediately and then every interval, scheduling policy cycles on schedule.
+func (controller *policyController) start(reaper reaper, schedule *cron.Cron, interval time.Duration) {
+	sync := func() {
+		reaper.health.cycleStarted(time.Now())
+		if err := controller.sync(reaper, schedule); err != nil {
+			logrus.WithError(err).Error("unable to sync reaper policies")
+		}
+	}
+	sync()
+	schedule.Schedule(cron.Every(interval), cron.FuncJob(sync))
+}
+
+// sync lists the policies in the cluster and updates the scheduled cycles to match. A policy that fails to load keeps
+// the cycle of its previous version, if any, so that a bad edit does not stop reaping.
+func (controller *policyController) sync(reaper reaper, schedule *cron.Cron) error {
+	ctx, cancel := reaper.apiContext()
+	defer cancel()
+	list, err := controller.client.Resource(reaperPolicyResource).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	controller.mutex.Lock()
+	defer controller.mutex.Unlock()
+	listed := map[string]bool{}
+	for _, object := range list.Items {
+		listed[object.GetName()] = true
+		entry, exists := controller.entries[object.GetName()]
+		if exists && entry.resourceVersion == object.GetResourceVersion() {
+			continue
+		}
+		policy, err := parseReaperPolicy(object)
+		if err != nil {
+			logrus.WithField("policy", object.GetName()).WithError(err).Error("unable to load reaper policy")
+			continue
+		}
+		if exists {
+			schedule.Remove(entry.id)
+		}
+		// each policy gets its own budget and cycle lock since the cycles of different policies may run concurrently
+		policyReaper := reaper
+		policyReaper.budget = newAPIBudget(reaper.options.apiCallBudget)
+		policyReaper.cycles = newCycleLock()
+		id := schedule.Schedule(policy.schedule, cron.FuncJob(func() { policyReaper.runPolicyCycle(policy) }))
+		controller.entries[policy.name] = policyEntry{resourceVersion: policy.resourceVersion, id: id}
+		logrus.WithField("policy", policy.name).Info("scheduled reaper policy")
+	}
+	for name, entry := range controller.entries {
+		if !listed[name] {
+			schedule.Remove(entry.id)
+			delete(controller.entries, name)
+			logrus.WithField("policy", name).Info("removed reaper policy")
+		}
+	}
+	return nil
+}
+
+// runPolicyCycle runs a reap cycle with the policy's rules, namespaces and pod limit in place of the global ones.
+func (reaper reaper) runPolicyCycle(policy reaperPolicy) {
+	if !reaper.leader.isLeading() {
+		logrus.WithField("policy", policy.name).Debug("not leading, skipping reaper policy cycle")
+		return
+	}
+	if !reaper.waitJitter() {
+		return
+	}
+	if !reaper.cycles.tryLock() {
+		logrus.WithField("policy", policy.name).Warn("previous reaper policy cycle is still running, skipping reaper policy cycle")
+		return
+	}
+	defer reaper.cycles.unlock()
+	reaper.budget = reaper.budget.newCycle()
+	reaper.options = reaper.options.withPolicy(policy)
+	reaper.namespaceSelector = policy.namespaceSelector
+	logrus.WithField("policy", policy.name).Info("running reaper policy")
+	if reaper.health != nil {
+		defer reaper.recoverCycle()
+	}
+	reaper.health.cycleFinished(reaper.scytheCycle())
+}
+
+// withPolicy returns the options with the policy's rules, namespaces and pod limits in place of the global ones.
+func (options Options) withPolicy(policy reaperPolicy) Options {
+	options.rules = policy.rules
+	// the policy's rules replace namespace rule config maps, which would otherwise fall back to the environment
+	options.namespaceRules = false
+	if policy.namespaces != nil {
+		options.namespace = ""
+		options.namespaces = policy.namespaces
+	}
+	if policy.maxPods > 0 {
+		options.maxPods = policy.maxPods
+	}
+	if policy.maxPodsPerNamespace > 0 {
+		options.maxPodsPerNamespace = policy.maxPodsPerNamespace
+	}
+	return options
+}
+
+// selectNamespaces filters pods to those in namespaces matching the reaper's namespace selector. When the namespaces
+// cannot be listed, the failure is added to the cycle's result and returned.
+func (reaper reaper) selectNamespaces(pods []v1.Pod) ([]v1.Pod, error) {
+	if !reaper.apiCall(operationList) {
+		logrus.Warn("api call budget exhausted, not listing namespaces")
+		return nil, nil
+	}
+	var namespaces *v1.NamespaceList
+	err := reaper.retryAPICall(operationList, func(ctx context.Context) (err error) {
+		namespaces, err = reaper.clientSet.CoreV1().Namespaces().List(ctx, metav1.ListOptions{
+			LabelSelector: reaper.namespaceSelector.String(),
+		})
+		return err
+	})
+	if err != nil {
+		reaper.result.addError(cycleError{Operation: "list", Target: "namespaces", Message: err.Error()})
+		return nil, fmt.Errorf("unable to get namespaces from the cluster: %s", err)
+	}
+	selected := map[string]bool{}
+	for _, namespace := range namespaces.Items {
+		selected[namespace.Name] = true
+	}
+	var filtered []v1.Pod
+	for _, pod := range pods {
+		if selected[pod.Namespace] {
+			filtered = append(filtered, pod)
+		}
+	}
+	return filtered, nil
+}
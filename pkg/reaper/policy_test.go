@@ -0,0 +1,219 @@
+package reaper
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/robfig/cron/v3"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func testReaperPolicy(name string, resourceVersion string, spec map[string]interface{}) *unstructured.Unstructured {
+	policy := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	policy.SetAPIVersion("pod-reaper.target.com/v1alpha1")
+	policy.SetKind("ReaperPolicy")
+	policy.SetName(name)
+	policy.SetResourceVersion(resourceVersion)
+	return policy
+}
+
+func testPolicyClient(objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{reaperPolicyResource: "ReaperPolicyList"}, objects...)
+}
+
+func TestParseReaperPolicy(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		policy, err := parseReaperPolicy(*testReaperPolicy("chaos", "1", map[string]interface{}{
+			"schedule": "@every 5m",
+			"namespaceSelector": map[string]interface{}{
+				"matchLabels": map[string]interface{}{"team": "a"},
+			},
+			"maxPods": int64(3),
+			"rules":   map[string]interface{}{"CHAOS_CHANCE": "1.0"},
+		}))
+		assert.NoError(t, err)
+		assert.Equal(t, "chaos", policy.name)
+		assert.Equal(t, "1", policy.resourceVersion)
+		assert.Equal(t, 3, policy.maxPods)
+		assert.Equal(t, "team=a", policy.namespaceSelector.String())
+		assert.Len(t, policy.rules.LoadedRules, 1)
+	})
+	t.Run("no namespace selector", func(t *testing.T) {
+		policy, err := parseReaperPolicy(*testReaperPolicy("chaos", "1", map[string]interface{}{
+			"schedule": "@every 5m",
+			"rules":    map[string]interface{}{"CHAOS_CHANCE": "1.0"},
+		}))
+		assert.NoError(t, err)
+		assert.Nil(t, policy.namespaceSelector)
+	})
+	t.Run("no spec", func(t *testing.T) {
+		policy := &unstructured.Unstructured{Object: map[string]interface{}{}}
+		policy.SetName("empty")
+		_, err := parseReaperPolicy(*policy)
+		assert.Error(t, err)
+	})
+	t.Run("invalid schedule", func(t *testing.T) {
+		_, err := parseReaperPolicy(*testReaperPolicy("chaos", "1", map[string]interface{}{
+			"schedule": "not a schedule",
+			"rules":    map[string]interface{}{"CHAOS_CHANCE": "1.0"},
+		}))
+		assert.Error(t, err)
+	})
+	t.Run("negative max pods", func(t *testing.T) {
+		_, err := parseReaperPolicy(*testReaperPolicy("chaos", "1", map[string]interface{}{
+			"schedule": "@every 5m",
+			"maxPods":  int64(-1),
+			"rules":    map[string]interface{}{"CHAOS_CHANCE": "1.0"},
+		}))
+		assert.Error(t, err)
+	})
+	t.Run("no rules", func(t *testing.T) {
+		_, err := parseReaperPolicy(*testReaperPolicy("chaos", "1", map[string]interface{}{
+			"schedule": "@every 5m",
+		}))
+		assert.Error(t, err)
+	})
+	t.Run("rules ignore the environment", func(t *testing.T) {
+		loadRulesForTest("1.0")
+		_, err := parseReaperPolicy(*testReaperPolicy("chaos", "1", map[string]interface{}{
+			"schedule": "@every 5m",
+			"rules":    map[string]interface{}{},
+		}))
+		assert.Error(t, err)
+	})
+}
+
+func TestPolicyControllerSync(t *testing.T) {
+	spec := map[string]interface{}{
+		"schedule": "@every 5m",
+		"rules":    map[string]interface{}{"CHAOS_CHANCE": "1.0"},
+	}
+	r := createTestReaper(minimalOptions("0.0"))
+
+	t.Run("schedules policies", func(t *testing.T) {
+		schedule := cron.New()
+		controller := newPolicyController(testPolicyClient(testReaperPolicy("a", "1", spec), testReaperPolicy("b", "1", spec)))
+		assert.NoError(t, controller.sync(r, schedule))
+		assert.Len(t, schedule.Entries(), 2)
+		assert.Len(t, controller.entries, 2)
+	})
+	t.Run("unchanged policies keep their entry", func(t *testing.T) {
+		schedule := cron.New()
+		controller := newPolicyController(testPolicyClient(testReaperPolicy("a", "1", spec)))
+		assert.NoError(t, controller.sync(r, schedule))
+		id := controller.entries["a"].id
+		assert.NoError(t, controller.sync(r, schedule))
+		assert.Equal(t, id, controller.entries["a"].id)
+		assert.Len(t, schedule.Entries(), 1)
+	})
+	t.Run("updated policies are replaced", func(t *testing.T) {
+		schedule := cron.New()
+		client := testPolicyClient(testReaperPolicy("a", "1", spec))
+		controller := newPolicyController(client)
+		assert.NoError(t, controller.sync(r, schedule))
+		id := controller.entries["a"].id
+		_, err := client.Resource(reaperPolicyResource).Update(context.TODO(), testReaperPolicy("a", "2", spec), metav1.UpdateOptions{})
+		assert.NoError(t, err)
+		assert.NoError(t, controller.sync(r, schedule))
+		assert.NotEqual(t, id, controller.entries["a"].id)
+		assert.Equal(t, "2", controller.entries["a"].resourceVersion)
+		assert.Len(t, schedule.Entries(), 1)
+	})
+	t.Run("invalid update keeps the previous version", func(t *testing.T) {
+		schedule := cron.New()
+		client := testPolicyClient(testReaperPolicy("a", "1", spec))
+		controller := newPolicyController(client)
+		assert.NoError(t, controller.sync(r, schedule))
+		id := controller.entries["a"].id
+		invalid := testReaperPolicy("a", "2", map[string]interface{}{"schedule": "@every 5m"})
+		_, err := client.Resource(reaperPolicyResource).Update(context.TODO(), invalid, metav1.UpdateOptions{})
+		assert.NoError(t, err)
+		assert.NoError(t, controller.sync(r, schedule))
+		assert.Equal(t, id, controller.entries["a"].id)
+		assert.Len(t, schedule.Entries(), 1)
+	})
+	t.Run("deleted policies are removed", func(t *testing.T) {
+		schedule := cron.New()
+		client := testPolicyClient(testReaperPolicy("a", "1", spec))
+		controller := newPolicyController(client)
+		assert.NoError(t, controller.sync(r, schedule))
+		err := client.Resource(reaperPolicyResource).Delete(context.TODO(), "a", metav1.DeleteOptions{})
+		assert.NoError(t, err)
+		assert.NoError(t, controller.sync(r, schedule))
+		assert.Empty(t, controller.entries)
+		assert.Empty(t, schedule.Entries())
+	})
+}
+
+func TestRunPolicyCycle(t *testing.T) {
+	testNamespace := func(name string, team string) *v1.Namespace {
+		return &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"team": team}}}
+	}
+	policyRules := loadRulesForTest("1.0")
+
+	t.Run("reaps pods in selected namespaces", func(t *testing.T) {
+		podA := createTestPod("pod-a", "team-a", nil)
+		podB := createTestPod("pod-b", "team-b", nil)
+		opts := minimalOptions("0.0")
+		opts.namespace = ""
+		r := reaper{
+			clientSet: fake.NewSimpleClientset(&podA, &podB, testNamespace("team-a", "a"), testNamespace("team-b", "b")),
+			options:   opts,
+		}
+		r.runPolicyCycle(reaperPolicy{
+			name:              "team-a",
+			namespaceSelector: labels.SelectorFromSet(labels.Set{"team": "a"}),
+			rules:             policyRules,
+		})
+		pods, _ := r.clientSet.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{})
+		assert.Len(t, pods.Items, 1)
+		assert.Equal(t, "pod-b", pods.Items[0].Name)
+	})
+	t.Run("max pods overrides the global limit", func(t *testing.T) {
+		r := createTestReaper(minimalOptions("0.0"),
+			createTestPod("pod-1", "default", nil), createTestPod("pod-2", "default", nil))
+		r.runPolicyCycle(reaperPolicy{name: "limited", maxPods: 1, rules: policyRules})
+		pods, _ := r.clientSet.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+		assert.Len(t, pods.Items, 1)
+	})
+	t.Run("namespaces replace the global namespaces", func(t *testing.T) {
+		podA := createTestPod("pod-a", "team-a", nil)
+		podB := createTestPod("pod-b", "team-b", nil)
+		r := createTestReaper(minimalOptions("0.0"), podA, podB)
+		r.runPolicyCycle(reaperPolicy{name: "team-b", namespaces: []string{"team-b"}, rules: policyRules})
+		pods, _ := r.clientSet.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{})
+		assert.Len(t, pods.Items, 1)
+		assert.Equal(t, "pod-a", pods.Items[0].Name)
+	})
+	t.Run("namespaces cannot be listed", func(t *testing.T) {
+		r := createTestReaper(minimalOptions("0.0"), createTestPod("pod-1", "default", nil))
+		r.clientSet.(*fake.Clientset).PrependReactor("list", "namespaces", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, errors.New("forbidden")
+		})
+		r.namespaceSelector = labels.SelectorFromSet(labels.Set{"team": "a"})
+		r.options.rules = policyRules
+		var err error
+		assert.NotPanics(t, func() { err = r.scytheCycle() })
+		assert.Equal(t, cycleErrors{{Operation: "list", Target: "namespaces", Message: "forbidden"}}, err)
+		pods, _ := r.clientSet.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+		assert.Len(t, pods.Items, 1)
+	})
+	t.Run("not leading", func(t *testing.T) {
+		r := createTestReaper(minimalOptions("0.0"), createTestPod("pod-1", "default", nil))
+		r.leader = &leader{}
+		r.runPolicyCycle(reaperPolicy{name: "standby", rules: policyRules})
+		pods, _ := r.clientSet.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+		assert.Len(t, pods.Items, 1)
+	})
+}
@@ -0,0 +1,63 @@
+package reaper
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// with ACTION=preview, every matching pod is labelled as eligible for reaping instead of being acted on
+const labelEligible = "pod-reaper/eligible"
+const annotationEligibleSince = "pod-reaper/eligible-since"
+const annotationEligibleReasons = "pod-reaper/eligible-reasons"
+
+// eligible returns whether the pod is labelled as eligible by ACTION=preview.
+func eligible(pod v1.Pod) bool {
+	return pod.Labels[labelEligible] == "true"
+}
+
+// previewPod keeps the eligible label of the pod in line with the rules: a pod that starts matching is labelled with
+// the time it became eligible, and a pod that stops matching has the label removed. Pods whose label is already
+// correct are not patched, so the eligible time is the earliest time the pod was seen matching.
+func (reaper reaper) previewPod(pod v1.Pod, shouldReap bool, reasons []string, now time.Time) {
+	if shouldReap == eligible(pod) {
+		return
+	}
+	podLog := logrus.WithFields(reaper.decisionFields(pod, reasons))
+	if !reaper.apiCall(operationPatch) {
+		podLog.Warn("pod eligibility changed but the api call budget is exhausted")
+		return
+	}
+	// null values remove the label and annotations in a merge patch
+	metadata := map[string]interface{}{
+		"labels":      map[string]interface{}{labelEligible: nil},
+		"annotations": map[string]interface{}{annotationEligibleSince: nil, annotationEligibleReasons: nil},
+	}
+	if shouldReap {
+		metadata = map[string]interface{}{
+			"labels": map[string]string{labelEligible: "true"},
+			"annotations": map[string]string{
+				annotationEligibleSince:   now.UTC().Format(time.RFC3339),
+				annotationEligibleReasons: strings.Join(reasons, "; "),
+			},
+		}
+	}
+	patch, err := json.Marshal(map[string]interface{}{"metadata": metadata})
+	if err == nil {
+		ctx, cancel := reaper.apiContext()
+		defer cancel()
+		_, err = reaper.clientSet.CoreV1().Pods(pod.Namespace).Patch(ctx, pod.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	}
+	if err != nil {
+		podLog.WithError(err).Warn("unable to update pod eligibility")
+	} else if shouldReap {
+		podLog.Info("pod is eligible for reaping")
+	} else {
+		podLog.Debug("pod is no longer eligible for reaping")
+	}
+}
@@ -0,0 +1,71 @@
+package reaper
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPreview(t *testing.T) {
+	t.Run("labels eligible pods", func(t *testing.T) {
+		opts := minimalOptions("1.0")
+		opts.action = actionPreview
+		opts.maxPods = 1
+		r := createTestReaper(opts, createTestPod("pod-1", "default", nil), createTestPod("pod-2", "default", nil))
+
+		r.scytheCycle()
+
+		pods, err := r.clientSet.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{LabelSelector: labelEligible + "=true"})
+		assert.NoError(t, err)
+		assert.Len(t, pods.Items, 2)
+		for _, pod := range pods.Items {
+			assert.NotEmpty(t, pod.Annotations[annotationEligibleSince])
+			assert.NotEmpty(t, pod.Annotations[annotationEligibleReasons])
+		}
+	})
+	t.Run("keeps the earliest eligible time", func(t *testing.T) {
+		pod := createTestPod("pod-1", "default", nil)
+		pod.Labels = map[string]string{labelEligible: "true"}
+		pod.Annotations = map[string]string{annotationEligibleSince: "2024-01-01T00:00:00Z"}
+		opts := minimalOptions("1.0")
+		opts.action = actionPreview
+		r := createTestReaper(opts, pod)
+
+		r.scytheCycle()
+
+		result, err := r.clientSet.CoreV1().Pods("default").Get(context.TODO(), "pod-1", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, "2024-01-01T00:00:00Z", result.Annotations[annotationEligibleSince])
+	})
+	t.Run("removes the label from pods no longer eligible", func(t *testing.T) {
+		pod := createTestPod("pod-1", "default", nil)
+		pod.Labels = map[string]string{labelEligible: "true", "app": "test"}
+		pod.Annotations = map[string]string{annotationEligibleSince: "2024-01-01T00:00:00Z", annotationEligibleReasons: "reason"}
+		opts := minimalOptions("0.0")
+		opts.action = actionPreview
+		r := createTestReaper(opts, pod)
+
+		r.scytheCycle()
+
+		result, err := r.clientSet.CoreV1().Pods("default").Get(context.TODO(), "pod-1", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"app": "test"}, result.Labels)
+		assert.Empty(t, result.Annotations)
+	})
+	t.Run("budget exhausted", func(t *testing.T) {
+		opts := minimalOptions("1.0")
+		opts.action = actionPreview
+		r := createTestReaper(opts, createTestPod("pod-1", "default", nil))
+		r.budget = newAPIBudget(1)
+		assert.True(t, r.apiCall(operationList))
+
+		r.previewPod(createTestPod("pod-1", "default", nil), true, []string{"reason"}, time.Now())
+
+		result, err := r.clientSet.CoreV1().Pods("default").Get(context.TODO(), "pod-1", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.False(t, eligible(*result))
+	})
+}
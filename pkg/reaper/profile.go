@@ -0,0 +1,80 @@
+package reaper
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const profileEdge = "edge"
+
+// profiles maps a PROFILE name to the options it presets, keyed by the environment variable of each option. Presets
+// only apply to options whose environment variable is not set, so any single option can still be overridden.
+var profiles = map[string]map[string]Option{
+	// edge tunes pod-reaper for small, resource constrained clusters (k3s and similar): a low client request rate, a
+	// bounded number of api calls per cycle, small list responses, one pod reaped at a time, notifications that do not
+	// hold up a cycle, and no event stream buffering notifications in memory
+	profileEdge: {
+		envClientQPS:          func(options *Options) { options.clientQPS = 2 },
+		envClientBurst:        func(options *Options) { options.clientBurst = 4 },
+		envAPICallBudget:      WithAPICallBudget(100),
+		envListPageSize:       WithListPageSize(100),
+		envReapConcurrency:    func(options *Options) { options.reapConcurrency = 1 },
+		envSlackSummary:       presetSlack(func(slack *slackNotifier) { slack.summary = true }),
+		envReapWebhookRetries: presetWebhook(func(webhook *webhookNotifier) { webhook.retries = 0 }),
+		envReapWebhookTimeout: presetWebhook(func(webhook *webhookNotifier) { webhook.client.Timeout = 2 * time.Second }),
+		envEventStream:        func(options *Options) { options.eventStream = false },
+	},
+}
+
+// applyProfile presets the options of the profile selected with PROFILE whose environment variables are not set.
+func applyProfile(options *Options) error {
+	name, exists := os.LookupEnv(envProfile)
+	if !exists || name == "" {
+		return nil
+	}
+	presets, ok := profiles[strings.ToLower(name)]
+	if !ok {
+		return fmt.Errorf("unknown %s %q, must be one of: %s", envProfile, name, strings.Join(profileNames(), ", "))
+	}
+	for key, preset := range presets {
+		if _, set := os.LookupEnv(key); set {
+			continue
+		}
+		preset(options)
+	}
+	return nil
+}
+
+// presetSlack presets the slack notifier, if SLACK_WEBHOOK_URL configures one.
+func presetSlack(preset func(*slackNotifier)) Option {
+	return func(options *Options) {
+		for _, notifier := range options.notifiers {
+			if slack, ok := notifier.(*slackNotifier); ok {
+				preset(slack)
+			}
+		}
+	}
+}
+
+// presetWebhook presets the webhook notifier, if REAP_WEBHOOK_URL configures one.
+func presetWebhook(preset func(*webhookNotifier)) Option {
+	return func(options *Options) {
+		for _, notifier := range options.notifiers {
+			if webhook, ok := notifier.(*webhookNotifier); ok {
+				preset(webhook)
+			}
+		}
+	}
+}
+
+func profileNames() []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,79 @@
+package reaper
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyProfile(t *testing.T) {
+	t.Run("not set", func(t *testing.T) {
+		os.Clearenv()
+		options := Options{}
+		assert.NoError(t, applyProfile(&options))
+		assert.Equal(t, Options{}, options)
+	})
+	t.Run("unknown", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envProfile, "tiny")
+		assert.Error(t, applyProfile(&Options{}))
+	})
+	t.Run("edge", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envProfile, "edge")
+		options := Options{}
+		assert.NoError(t, applyProfile(&options))
+		assert.Equal(t, float32(2), options.clientQPS)
+		assert.Equal(t, 100, options.apiCallBudget)
+		assert.Equal(t, 100, options.listPageSize)
+		assert.False(t, options.eventStream)
+		_, exists := os.LookupEnv(envClientQPS)
+		assert.False(t, exists)
+	})
+	t.Run("explicit options win", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envProfile, "EDGE")
+		os.Setenv(envAPICallBudget, "500")
+		os.Setenv(envListPageSize, "0")
+		os.Setenv(envEventStream, "true")
+		options := Options{apiCallBudget: 500, eventStream: true}
+		assert.NoError(t, applyProfile(&options))
+		assert.True(t, options.eventStream)
+		assert.Equal(t, 500, options.apiCallBudget)
+		assert.Equal(t, 0, options.listPageSize)
+		assert.Equal(t, 4, options.clientBurst)
+	})
+	t.Run("notifiers", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envProfile, "edge")
+		slack := &slackNotifier{}
+		webhook := newWebhookNotifier("http://localhost", time.Minute, 3)
+		options := Options{notifiers: []notifier{slack, webhook}}
+		assert.NoError(t, applyProfile(&options))
+		assert.True(t, slack.summary)
+		assert.Equal(t, 0, webhook.retries)
+		assert.Equal(t, 2*time.Second, webhook.client.Timeout)
+	})
+	t.Run("load options", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envProfile, "edge")
+		os.Setenv("MAX_DURATION", "1h")
+		os.Setenv(envReapWebhookURL, "http://localhost")
+		os.Setenv(envReapWebhookRetries, "5")
+		options, err := LoadOptions()
+		assert.NoError(t, err)
+		assert.Equal(t, float32(2), options.clientQPS)
+		assert.Equal(t, 4, options.clientBurst)
+		assert.Equal(t, 100, options.apiCallBudget)
+		assert.Equal(t, 100, options.listPageSize)
+		assert.Equal(t, 1, options.reapConcurrency)
+		assert.Len(t, options.notifiers, 1)
+		webhook := options.notifiers[0].(*webhookNotifier)
+		assert.Equal(t, 5, webhook.retries)
+		assert.Equal(t, 2*time.Second, webhook.client.Timeout)
+		_, exists := os.LookupEnv(envAPICallBudget)
+		assert.False(t, exists)
+	})
+}
@@ -0,0 +1,131 @@
+package reaper
+
+import (
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// deployments that do not set a maxUnavailable may have a quarter of their replicas unavailable during a rollout
+var defaultDeploymentMaxUnavailable = intstr.FromString("25%")
+
+// readyGuard keeps MIN_READY_REPLICAS from reaping the ready pods of a deployment or stateful set below a minimum:
+// MIN_READY_REPLICAS itself, or the replicas the workload keeps available during its own rolling updates if that is
+// more. The guard counts the ready pods of each workload reaped in the cycle in progress, since the workload's status
+// only counts them once its controller catches up. A nil readyGuard protects no workloads.
+type readyGuard struct {
+	minReady int32
+	mutex    sync.Mutex
+	// reaped counts the ready pods of each workload reaped in the cycle, by workload uid
+	reaped map[types.UID]int32
+}
+
+// newReadyGuard returns a guard keeping at least minReady replicas ready, or nil if minReady is nil.
+func newReadyGuard(minReady *int32) *readyGuard {
+	if minReady == nil {
+		return nil
+	}
+	return &readyGuard{minReady: *minReady, reaped: map[types.UID]int32{}}
+}
+
+// keepsReady returns whether the pod can be reaped while its workload keeps enough ready replicas, and counts it
+// against the workload if so. Pods that are not ready do not lower the ready replicas, so they and pods that are not
+// controlled by a deployment or stateful set are always allowed.
+func (reaper reaper) keepsReady(pod v1.Pod, podLog *logrus.Entry) (bool, error) {
+	if reaper.ready == nil || !podReady(pod) {
+		return true, nil
+	}
+	owner := metav1.GetControllerOf(&pod)
+	if owner == nil || owner.Kind != "ReplicaSet" && owner.Kind != "StatefulSet" {
+		return true, nil
+	}
+	ctx, cancel := reaper.apiContext()
+	defer cancel()
+	workload, err := reaper.getWorkload(ctx, pod.Namespace, owner.Kind, owner.Name)
+	if err != nil || workload == nil {
+		return false, err
+	}
+	return reaper.ready.take(workload, podLog), nil
+}
+
+func (guard *readyGuard) take(workload metav1.Object, podLog *logrus.Entry) bool {
+	var ready, minAvailable int32
+	switch object := workload.(type) {
+	case *appsv1.Deployment:
+		ready = object.Status.ReadyReplicas
+		minAvailable = deploymentMinAvailable(object)
+	case *appsv1.StatefulSet:
+		ready = object.Status.ReadyReplicas
+		minAvailable = statefulSetMinAvailable(object)
+	case *appsv1.ReplicaSet:
+		// a replica set without a deployment has no rolling updates to take a minimum from
+		ready = object.Status.ReadyReplicas
+	default:
+		return true
+	}
+	minReady := guard.minReady
+	if minAvailable > minReady {
+		minReady = minAvailable
+	}
+	guard.mutex.Lock()
+	defer guard.mutex.Unlock()
+	remaining := ready - guard.reaped[workload.GetUID()] - 1
+	if remaining < minReady {
+		podLog.WithFields(logrus.Fields{
+			"workload":         workload.GetName(),
+			"readyReplicas":    ready,
+			"minReadyReplicas": minReady,
+		}).Debug("reaping the pod would leave its workload with too few ready replicas")
+		return false
+	}
+	guard.reaped[workload.GetUID()]++
+	return true
+}
+
+// deploymentMinAvailable returns the replicas the deployment keeps available during a rolling update.
+func deploymentMinAvailable(deployment *appsv1.Deployment) int32 {
+	if deployment.Spec.Strategy.Type == appsv1.RecreateDeploymentStrategyType {
+		return 0
+	}
+	maxUnavailable := &defaultDeploymentMaxUnavailable
+	if deployment.Spec.Strategy.RollingUpdate != nil && deployment.Spec.Strategy.RollingUpdate.MaxUnavailable != nil {
+		maxUnavailable = deployment.Spec.Strategy.RollingUpdate.MaxUnavailable
+	}
+	return minAvailable(replicas(deployment.Spec.Replicas), maxUnavailable)
+}
+
+// statefulSetMinAvailable returns the replicas the stateful set keeps available during a rolling update, which
+// replaces a single pod at a time unless maxUnavailable is set.
+func statefulSetMinAvailable(statefulSet *appsv1.StatefulSet) int32 {
+	maxUnavailable := intstr.FromInt32(1)
+	if statefulSet.Spec.UpdateStrategy.RollingUpdate != nil && statefulSet.Spec.UpdateStrategy.RollingUpdate.MaxUnavailable != nil {
+		maxUnavailable = *statefulSet.Spec.UpdateStrategy.RollingUpdate.MaxUnavailable
+	}
+	return minAvailable(replicas(statefulSet.Spec.Replicas), &maxUnavailable)
+}
+
+// minAvailable resolves maxUnavailable against the replicas, rounding percentages down like the workload
+// controllers do, and returns the replicas that stay available.
+func minAvailable(replicas int32, maxUnavailable *intstr.IntOrString) int32 {
+	unavailable, err := intstr.GetScaledValueFromIntOrPercent(maxUnavailable, int(replicas), false)
+	if err != nil {
+		return replicas
+	}
+	if int32(unavailable) >= replicas {
+		return 0
+	}
+	return replicas - int32(unavailable)
+}
+
+// replicas returns the desired replicas of a workload, which default to 1.
+func replicas(desired *int32) int32 {
+	if desired == nil {
+		return 1
+	}
+	return *desired
+}
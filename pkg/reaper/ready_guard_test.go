@@ -0,0 +1,142 @@
+package reaper
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func testReadyPod(name string, kind string, owner string) v1.Pod {
+	controller := true
+	pod := createTestPod(name, "default", nil)
+	pod.OwnerReferences = []metav1.OwnerReference{{Kind: kind, Name: owner, Controller: &controller}}
+	pod.Status.Phase = v1.PodRunning
+	pod.Status.Conditions = []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}}
+	return pod
+}
+
+func testReadyDeployment(name string, replicas int32, ready int32, maxUnavailable *intstr.IntOrString) (*appsv1.Deployment, *appsv1.ReplicaSet) {
+	controller := true
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID("uid-" + name)},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status:     appsv1.DeploymentStatus{ReadyReplicas: ready},
+	}
+	if maxUnavailable != nil {
+		deployment.Spec.Strategy.RollingUpdate = &appsv1.RollingUpdateDeployment{MaxUnavailable: maxUnavailable}
+	}
+	replicaSet := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name + "-rs",
+			Namespace:       "default",
+			UID:             types.UID("uid-" + name + "-rs"),
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: name, Controller: &controller}},
+		},
+		Status: appsv1.ReplicaSetStatus{ReadyReplicas: ready},
+	}
+	return deployment, replicaSet
+}
+
+func TestReadyGuard(t *testing.T) {
+	two := intstr.FromInt32(2)
+	newReaper := func(minReady *int32, pods ...v1.Pod) reaper {
+		opts := minimalOptions("1.0")
+		opts.minReadyReplicas = minReady
+		r := createTestReaper(opts, pods...)
+		for _, workload := range []struct {
+			replicas       int32
+			ready          int32
+			maxUnavailable *intstr.IntOrString
+			name           string
+		}{
+			{name: "web", replicas: 5, ready: 5, maxUnavailable: &two},
+			{name: "small", replicas: 3, ready: 3},
+		} {
+			deployment, replicaSet := testReadyDeployment(workload.name, workload.replicas, workload.ready, workload.maxUnavailable)
+			r.clientSet.AppsV1().Deployments("default").Create(context.TODO(), deployment, metav1.CreateOptions{})
+			r.clientSet.AppsV1().ReplicaSets("default").Create(context.TODO(), replicaSet, metav1.CreateOptions{})
+		}
+		replicas := int32(4)
+		r.clientSet.AppsV1().StatefulSets("default").Create(context.TODO(), &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default", UID: "uid-db"},
+			Spec:       appsv1.StatefulSetSpec{Replicas: &replicas},
+			Status:     appsv1.StatefulSetStatus{ReadyReplicas: 4},
+		}, metav1.CreateOptions{})
+		return r
+	}
+	remaining := func(r reaper) int {
+		pods, _ := r.clientSet.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+		return len(pods.Items)
+	}
+	webPods := func() []v1.Pod {
+		var pods []v1.Pod
+		for _, name := range []string{"web-1", "web-2", "web-3", "web-4", "web-5"} {
+			pods = append(pods, testReadyPod(name, "ReplicaSet", "web-rs"))
+		}
+		return pods
+	}
+	zero := int32(0)
+	four := int32(4)
+
+	t.Run("workload minimum", func(t *testing.T) {
+		// a maxUnavailable of 2 keeps 3 of the 5 replicas ready
+		r := newReaper(&zero, webPods()...)
+		r.scytheCycle()
+		assert.Equal(t, 3, remaining(r))
+	})
+	t.Run("min ready replicas above the workload minimum", func(t *testing.T) {
+		r := newReaper(&four, webPods()...)
+		r.scytheCycle()
+		assert.Equal(t, 4, remaining(r))
+	})
+	t.Run("default max unavailable rounds down", func(t *testing.T) {
+		r := newReaper(&zero, testReadyPod("small-1", "ReplicaSet", "small-rs"))
+		r.scytheCycle()
+		assert.Equal(t, 1, remaining(r))
+	})
+	t.Run("stateful set replaces one pod at a time", func(t *testing.T) {
+		r := newReaper(&zero, testReadyPod("db-0", "StatefulSet", "db"), testReadyPod("db-1", "StatefulSet", "db"))
+		r.scytheCycle()
+		assert.Equal(t, 1, remaining(r))
+	})
+	t.Run("unready pods are reaped", func(t *testing.T) {
+		pod := testReadyPod("small-1", "ReplicaSet", "small-rs")
+		pod.Status.Conditions = nil
+		r := newReaper(&four, pod)
+		r.scytheCycle()
+		assert.Equal(t, 0, remaining(r))
+	})
+	t.Run("missing workload skips the pod", func(t *testing.T) {
+		r := newReaper(&zero, testReadyPod("orphan-1", "ReplicaSet", "gone"))
+		r.scytheCycle()
+		assert.Equal(t, 1, remaining(r))
+	})
+	t.Run("other owners", func(t *testing.T) {
+		r := newReaper(&four, testReadyPod("node-agent", "DaemonSet", "agent"), createTestPod("bare", "default", nil))
+		r.scytheCycle()
+		assert.Equal(t, 0, remaining(r))
+	})
+	t.Run("disabled", func(t *testing.T) {
+		r := newReaper(nil, webPods()...)
+		r.scytheCycle()
+		assert.Equal(t, 0, remaining(r))
+	})
+}
+
+func TestMinAvailable(t *testing.T) {
+	percent := intstr.FromString("50%")
+	three := intstr.FromInt32(3)
+	assert.Equal(t, int32(3), minAvailable(5, &percent))
+	assert.Equal(t, int32(0), minAvailable(2, &three))
+	assert.Equal(t, int32(0), deploymentMinAvailable(&appsv1.Deployment{
+		Spec: appsv1.DeploymentSpec{Strategy: appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType}},
+	}))
+	// a deployment without replicas has a single replica
+	assert.Equal(t, int32(1), deploymentMinAvailable(&appsv1.Deployment{}))
+}
@@ -0,0 +1,42 @@
+package reaper
+
+import "sync"
+
+// reapPool reaps pods on up to REAP_CONCURRENCY goroutines at once. A nil reapPool reaps each pod on the calling
+// goroutine, one at a time.
+type reapPool struct {
+	slots   chan struct{}
+	running sync.WaitGroup
+}
+
+func newReapPool(concurrency int) *reapPool {
+	if concurrency <= 1 {
+		return nil
+	}
+	return &reapPool{slots: make(chan struct{}, concurrency)}
+}
+
+// run reaps on a free goroutine, waiting for one to become free first.
+func (pool *reapPool) run(reap func()) {
+	if pool == nil {
+		reap()
+		return
+	}
+	pool.slots <- struct{}{}
+	pool.running.Add(1)
+	go func() {
+		defer func() {
+			<-pool.slots
+			pool.running.Done()
+		}()
+		reap()
+	}()
+}
+
+// wait waits until every reap started with run has finished.
+func (pool *reapPool) wait() {
+	if pool == nil {
+		return
+	}
+	pool.running.Wait()
+}
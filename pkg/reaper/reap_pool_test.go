@@ -0,0 +1,71 @@
+package reaper
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestReapPool(t *testing.T) {
+	t.Run("bounded", func(t *testing.T) {
+		pool := newReapPool(3)
+		var running, peak int32
+		for i := 0; i < 20; i++ {
+			pool.run(func() {
+				now := atomic.AddInt32(&running, 1)
+				for {
+					seen := atomic.LoadInt32(&peak)
+					if now <= seen || atomic.CompareAndSwapInt32(&peak, seen, now) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond)
+				atomic.AddInt32(&running, -1)
+			})
+		}
+		pool.wait()
+		assert.Equal(t, int32(0), running)
+		assert.LessOrEqual(t, peak, int32(3))
+	})
+	t.Run("serial", func(t *testing.T) {
+		assert.Nil(t, newReapPool(1))
+		ran := false
+		(*reapPool)(nil).run(func() { ran = true })
+		assert.True(t, ran)
+	})
+}
+
+func TestScytheCycleConcurrency(t *testing.T) {
+	var pods []v1.Pod
+	for _, name := range []string{"pod-1", "pod-2", "pod-3", "pod-4", "pod-5", "pod-6"} {
+		pods = append(pods, createTestPod(name, "default", nil))
+	}
+	opts := minimalOptions("1.0")
+	opts.reapConcurrency = 3
+	opts.maxPods = 4
+	r := createTestReaper(opts, pods...)
+	var mutex sync.Mutex
+	var deleted []string
+	r.clientSet.(*fake.Clientset).PrependReactor("delete", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		deleted = append(deleted, action.(k8stesting.DeleteAction).GetName())
+		return false, nil, nil
+	})
+
+	assert.NoError(t, r.scytheCycle())
+
+	assert.Len(t, deleted, 4)
+	remaining, err := r.clientSet.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, remaining.Items, 2)
+}
@@ -0,0 +1,245 @@
+package reaper
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// reap requests are audited with this in place of the names of matching rules
+const reapRequestRule = "request"
+
+// reap request limits apply to the pods reaped through requests in a sliding window
+const reapRequestWindow = time.Hour
+
+var errReapRequestLimit = errors.New("the reap request limit is reached, try again later")
+var errNamespaceNotWatched = errors.New("pod-reaper does not reap pods in this namespace")
+
+// reapRequest asks pod-reaper to reap a pod, or the pods matching a label selector, in a namespace.
+type reapRequest struct {
+	Namespace string `json:"namespace"`
+	Pod       string `json:"pod,omitempty"`
+	Selector  string `json:"selector,omitempty"`
+	Reason    string `json:"reason"`
+	// Requester names the system that sent the request, for the reasons recorded with the reap
+	Requester string `json:"requester,omitempty"`
+}
+
+// reapRequestResult describes what was done with the pods of a reap request.
+type reapRequestResult struct {
+	CycleID string `json:"cycleId"`
+	// Reaped are the pods that were reaped
+	Reaped []string `json:"reaped"`
+	// Skipped are the pods that were not reaped because of dry-run, a limit, or a failure
+	Skipped []string `json:"skipped"`
+	// Excluded are the pods that pod-reaper's filters, such as the protect annotation, exclude from reaping
+	Excluded []string `json:"excluded"`
+}
+
+func (request reapRequest) validate() error {
+	if request.Namespace == "" || request.Reason == "" {
+		return errors.New("namespace and reason are required")
+	}
+	if (request.Pod == "") == (request.Selector == "") {
+		return errors.New("exactly one of pod and selector is required")
+	}
+	if request.Selector != "" {
+		if _, err := labels.Parse(request.Selector); err != nil {
+			return fmt.Errorf("invalid selector: %s", err)
+		}
+	}
+	return nil
+}
+
+// reapRequestLimiter limits the number of pods reaped through reap requests in a sliding window. A nil
+// reapRequestLimiter accepts no requests.
+type reapRequestLimiter struct {
+	mutex  sync.Mutex
+	limit  int
+	reaped []time.Time
+}
+
+func newReapRequestLimiter(limit int) *reapRequestLimiter {
+	return &reapRequestLimiter{limit: limit}
+}
+
+// allowed returns whether another pod can be reaped through a request.
+func (limiter *reapRequestLimiter) allowed(now time.Time) bool {
+	if limiter == nil {
+		return false
+	}
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+	recent := limiter.reaped[:0]
+	for _, reaped := range limiter.reaped {
+		if now.Sub(reaped) < reapRequestWindow {
+			recent = append(recent, reaped)
+		}
+	}
+	limiter.reaped = recent
+	return len(limiter.reaped) < limiter.limit
+}
+
+func (limiter *reapRequestLimiter) record(now time.Time) {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+	limiter.reaped = append(limiter.reaped, now)
+}
+
+// reapRequested reaps the pods of a reap request through the same filters, limits, and audit as a reap cycle. The
+// rules are not evaluated, the request is the reason to reap.
+func (reaper reaper) reapRequested(request reapRequest, now time.Time) (reapRequestResult, error) {
+	result := reapRequestResult{Reaped: []string{}, Skipped: []string{}, Excluded: []string{}}
+	if !reaper.leader.isLeading() {
+		return result, errNotLeading
+	}
+	if !reaper.watchesNamespace(request.Namespace) {
+		return result, errNamespaceNotWatched
+	}
+	if !reaper.requests.allowed(now) {
+		return result, errReapRequestLimit
+	}
+	pods, err := reaper.requestedPods(request)
+	if err != nil {
+		return result, err
+	}
+	reaper.cycleID = newCycleID()
+	reaper.matchedRules = []string{reapRequestRule}
+	reaper.jobs = newJobGuard(reaper.options.protectJobBackoff)
+	reaper.ready = newReadyGuard(reaper.options.minReadyReplicas)
+	result.CycleID = reaper.cycleID
+	requester := request.Requester
+	if requester == "" {
+		requester = "an external system"
+	}
+	reasons := []string{fmt.Sprintf("requested by %s: %s", requester, request.Reason)}
+	selected := map[string]bool{}
+	for _, pod := range reaper.selectRequested(pods) {
+		selected[pod.Name] = true
+	}
+	reapedPods := 0
+	for _, pod := range pods {
+		switch {
+		case !selected[pod.Name]:
+			result.Excluded = append(result.Excluded, pod.Name)
+		case !reaper.requests.allowed(now):
+			logrus.WithFields(reaper.decisionFields(pod, reasons)).Info("pod would be reaped but the reap request limit is reached")
+			result.Skipped = append(result.Skipped, pod.Name)
+		case reaper.reapPod(pod, reasons, reapedPods):
+			reapedPods++
+			reaper.requests.record(now)
+			result.Reaped = append(result.Reaped, pod.Name)
+		default:
+			reapedPods++
+			result.Skipped = append(result.Skipped, pod.Name)
+		}
+	}
+	reaper.flushNotifiers()
+	reaper.flushAudit()
+	return result, nil
+}
+
+// watchesNamespace returns whether pod-reaper looks for pods in the namespace.
+func (reaper reaper) watchesNamespace(namespace string) bool {
+	for _, watched := range reaper.listNamespaces() {
+		if watched == "" || watched == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// requestedPods gets the pod named by the request, or lists the pods matching its selector.
+func (reaper reaper) requestedPods(request reapRequest) ([]v1.Pod, error) {
+	pods := reaper.clientSet.CoreV1().Pods(request.Namespace)
+	if request.Pod != "" {
+		if !reaper.apiCall(operationGet) {
+			return nil, errAPIBudgetExhausted
+		}
+		ctx, cancel := reaper.apiContext()
+		defer cancel()
+		pod, err := pods.Get(ctx, request.Pod, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return []v1.Pod{*pod}, nil
+	}
+	if !reaper.apiCall(operationList) {
+		return nil, errAPIBudgetExhausted
+	}
+	ctx, cancel := reaper.apiContext()
+	defer cancel()
+	list, err := pods.List(ctx, metav1.ListOptions{LabelSelector: request.Selector})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// selectRequested returns the requested pods that a reap cycle would consider: those matching the label exclusion and
+// requirement, on a selected node, and not excluded by the filters.
+func (reaper reaper) selectRequested(pods []v1.Pod) []v1.Pod {
+	var selected []v1.Pod
+	for _, pod := range pods {
+		if reaper.options.labelExclusion != nil && !reaper.options.labelExclusion.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		if reaper.options.labelRequirement != nil && !reaper.options.labelRequirement.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		if reaper.options.requireLabels != nil && !reaper.options.requireLabels.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		selected = append(selected, pod)
+	}
+	if reaper.targetsNodes() {
+		selected = reaper.selectNodes(selected)
+	}
+	return filter(reaper, selected...)
+}
+
+func (reaper reaper) handleReapRequest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var request reapRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64*1024)).Decode(&request); err != nil {
+		http.Error(w, "invalid reap request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := request.validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	result, err := reaper.reapRequested(request, time.Now())
+	switch {
+	case err == errNotLeading:
+		http.Error(w, err.Error(), http.StatusConflict)
+	case err == errReapRequestLimit || err == errAPIBudgetExhausted:
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+	case apierrors.IsNotFound(err):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case err == errNamespaceNotWatched:
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	default:
+		logrus.WithFields(logrus.Fields{
+			"namespace": request.Namespace,
+			"requester": request.Requester,
+			"cycleId":   result.CycleID,
+			"reaped":    len(result.Reaped),
+		}).Info("reap request handled")
+		writeJSON(w, result)
+	}
+}
@@ -0,0 +1,148 @@
+package reaper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+func testLabelledPod(name string, labels map[string]string) v1.Pod {
+	pod := createTestPod(name, "default", nil)
+	pod.Labels = labels
+	return pod
+}
+
+func TestReapRequestValidate(t *testing.T) {
+	assert.NoError(t, reapRequest{Namespace: "default", Pod: "web-1", Reason: "stuck"}.validate())
+	assert.NoError(t, reapRequest{Namespace: "default", Selector: "app=web", Reason: "stuck"}.validate())
+	for name, request := range map[string]reapRequest{
+		"no namespace":       {Pod: "web-1", Reason: "stuck"},
+		"no reason":          {Namespace: "default", Pod: "web-1"},
+		"no pod or selector": {Namespace: "default", Reason: "stuck"},
+		"pod and selector":   {Namespace: "default", Pod: "web-1", Selector: "app=web", Reason: "stuck"},
+		"invalid selector":   {Namespace: "default", Selector: "app in (", Reason: "stuck"},
+	} {
+		assert.Error(t, request.validate(), name)
+	}
+}
+
+func TestReapRequestLimiter(t *testing.T) {
+	now := time.Now()
+	limiter := newReapRequestLimiter(2)
+	assert.True(t, limiter.allowed(now))
+	limiter.record(now.Add(-2 * time.Hour))
+	limiter.record(now.Add(-time.Minute))
+	assert.True(t, limiter.allowed(now))
+	limiter.record(now)
+	assert.False(t, limiter.allowed(now))
+	assert.True(t, limiter.allowed(now.Add(reapRequestWindow)))
+	assert.False(t, (*reapRequestLimiter)(nil).allowed(now))
+}
+
+func TestReapRequested(t *testing.T) {
+	protected := testLabelledPod("web-3", map[string]string{"app": "web"})
+	protected.Annotations = map[string]string{annotationProtect: "true"}
+	newReaper := func(limit int) reaper {
+		r := createTestReaper(minimalOptions("0.0"),
+			testLabelledPod("web-1", map[string]string{"app": "web"}),
+			testLabelledPod("web-2", map[string]string{"app": "web"}),
+			protected,
+			testLabelledPod("api-1", map[string]string{"app": "api"}))
+		r.requests = newReapRequestLimiter(limit)
+		return r
+	}
+	remaining := func(r reaper) int {
+		pods, _ := r.clientSet.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+		return len(pods.Items)
+	}
+
+	t.Run("pod", func(t *testing.T) {
+		r := newReaper(10)
+		result, err := r.reapRequested(reapRequest{Namespace: "default", Pod: "api-1", Reason: "stuck"}, time.Now())
+		assert.NoError(t, err)
+		assert.NotEmpty(t, result.CycleID)
+		assert.Equal(t, []string{"api-1"}, result.Reaped)
+		assert.Equal(t, 3, remaining(r))
+	})
+	t.Run("selector", func(t *testing.T) {
+		r := newReaper(10)
+		result, err := r.reapRequested(reapRequest{Namespace: "default", Selector: "app=web", Reason: "bad deploy"}, time.Now())
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []string{"web-1", "web-2"}, result.Reaped)
+		assert.Equal(t, []string{"web-3"}, result.Excluded)
+		assert.Equal(t, 2, remaining(r))
+	})
+	t.Run("max pods", func(t *testing.T) {
+		r := newReaper(10)
+		r.options.maxPods = 1
+		result, err := r.reapRequested(reapRequest{Namespace: "default", Selector: "app=web", Reason: "bad deploy"}, time.Now())
+		assert.NoError(t, err)
+		assert.Len(t, result.Reaped, 1)
+		assert.Len(t, result.Skipped, 1)
+	})
+	t.Run("dry run", func(t *testing.T) {
+		r := newReaper(10)
+		r.options.dryRun = true
+		result, err := r.reapRequested(reapRequest{Namespace: "default", Pod: "api-1", Reason: "stuck"}, time.Now())
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"api-1"}, result.Skipped)
+		assert.Equal(t, 4, remaining(r))
+	})
+	t.Run("limit", func(t *testing.T) {
+		r := newReaper(1)
+		result, err := r.reapRequested(reapRequest{Namespace: "default", Selector: "app=web", Reason: "bad deploy"}, time.Now())
+		assert.NoError(t, err)
+		assert.Len(t, result.Reaped, 1)
+		assert.Len(t, result.Skipped, 1)
+		_, err = r.reapRequested(reapRequest{Namespace: "default", Pod: "api-1", Reason: "stuck"}, time.Now())
+		assert.Equal(t, errReapRequestLimit, err)
+	})
+	t.Run("label exclusion", func(t *testing.T) {
+		r := newReaper(10)
+		exclusion, err := labels.NewRequirement("app", selection.NotIn, []string{"api"})
+		assert.NoError(t, err)
+		r.options.labelExclusion = exclusion
+		result, err := r.reapRequested(reapRequest{Namespace: "default", Pod: "api-1", Reason: "stuck"}, time.Now())
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"api-1"}, result.Excluded)
+	})
+	t.Run("other namespace", func(t *testing.T) {
+		r := newReaper(10)
+		_, err := r.reapRequested(reapRequest{Namespace: "kube-system", Pod: "coredns", Reason: "stuck"}, time.Now())
+		assert.Equal(t, errNamespaceNotWatched, err)
+	})
+	t.Run("not leading", func(t *testing.T) {
+		r := newReaper(10)
+		r.leader = &leader{}
+		_, err := r.reapRequested(reapRequest{Namespace: "default", Pod: "api-1", Reason: "stuck"}, time.Now())
+		assert.Equal(t, errNotLeading, err)
+	})
+}
+
+func TestHandleReapRequest(t *testing.T) {
+	r := createTestReaper(minimalOptions("0.0"), createTestPod("web-1", "default", nil))
+	r.requests = newReapRequestLimiter(10)
+	request := func(method string, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		r.handleReapRequest(recorder, httptest.NewRequest(method, "/admin/reap", strings.NewReader(body)))
+		return recorder
+	}
+
+	assert.Equal(t, http.StatusMethodNotAllowed, request(http.MethodGet, "").Code)
+	assert.Equal(t, http.StatusBadRequest, request(http.MethodPost, "not json").Code)
+	assert.Equal(t, http.StatusBadRequest, request(http.MethodPost, `{"namespace":"default","pod":"web-1"}`).Code)
+	assert.Equal(t, http.StatusNotFound, request(http.MethodPost, `{"namespace":"default","pod":"web-2","reason":"stuck"}`).Code)
+	assert.Equal(t, http.StatusForbidden, request(http.MethodPost, `{"namespace":"kube-system","pod":"web-1","reason":"stuck"}`).Code)
+	recorder := request(http.MethodPost, `{"namespace":"default","pod":"web-1","reason":"stuck","requester":"ticket-bot"}`)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"reaped":["web-1"]`)
+}
@@ -0,0 +1,984 @@
+package reaper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/sirupsen/logrus"
+	"github.com/target/pod-reaper/rules"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+type reaper struct {
+	clientSet kubernetes.Interface
+	options   Options
+	// podListers serve pods from informer caches when USE_INFORMER is enabled, one per listed namespace
+	podListers []corelisters.PodLister
+	// budget limits the api calls of the cycle in progress, each cycle gets its own budget from the reaper's
+	budget *apiBudget
+	// cycles is held by the cycle in progress, so that cycles of the same reaper never overlap
+	cycles *cycleLock
+	// cluster holds the clients and caches of the rules, which look up objects in the cluster whose pods are reaped
+	cluster    *rules.Cluster
+	health     *health
+	leader     *leader
+	admin      *admin
+	escalation *graceEscalation
+	cooldown   *ownerCooldown
+	// experiment tracks the owners disrupted during a window of CHAOS_WINDOW
+	experiment *chaosExperiment
+	// policies schedules cycles from ReaperPolicy resources when REAPER_POLICIES is enabled
+	policies *policyController
+	// namespaceSelector limits a policy cycle to the namespaces selected by the policy
+	namespaceSelector labels.Selector
+	// cycleID identifies the reap cycle in progress, set on the reaper copy used by each cycle
+	cycleID string
+	// result collects the result of the reap cycle in progress, set on the reaper copy used by each cycle
+	result *cycleResult
+	// lastCycle serves the result of the latest completed cycle when ADMIN_TOKEN is set
+	lastCycle *lastCycle
+	// events streams reap notifications when ADMIN_TOKEN is set
+	events *eventStream
+	// evictionVersion is the eviction API group version detected at startup, policy/v1 when empty
+	evictionVersion string
+	// requests limits the pods reaped through reap requests, which are only accepted when set
+	requests *reapRequestLimiter
+	// matchedRules names the rules that matched the pod being reaped, set on the reaper copy used by each cycle
+	matchedRules []string
+	// ctx is cancelled when pod-reaper stops waiting for running cycles at shutdown, a nil ctx is never cancelled
+	ctx context.Context
+	// jobs counts the job pods reaped in the cycle in progress when PROTECT_JOB_BACKOFF is set, set on the reaper
+	// copy used by each cycle
+	jobs *jobGuard
+	// ready counts the ready pods of each workload reaped in the cycle in progress when MIN_READY_REPLICAS is set, set
+	// on the reaper copy used by each cycle
+	ready *readyGuard
+}
+
+// Reaper reaps the pods of a kubernetes cluster that every enabled rule flags, on its schedule.
+type Reaper struct {
+	reaper reaper
+}
+
+// New creates a Reaper with the options that uses the in cluster kubernetes configuration.
+func New(options Options) (*Reaper, error) {
+	reaper, err := newReaperWithOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	return &Reaper{reaper: reaper}, nil
+}
+
+// NewForConfig creates a Reaper with the options that uses the kubernetes configuration, for example to reap the pods
+// of another cluster.
+func NewForConfig(config *rest.Config, options Options) (*Reaper, error) {
+	reaper, err := newReaperForConfig(config, options)
+	if err != nil {
+		return nil, err
+	}
+	return &Reaper{reaper: reaper}, nil
+}
+
+// Run serves the metrics, health, and admin endpoints, and runs reap cycles on the schedule until RUN_DURATION elapses
+// or the context is cancelled, and then shuts down gracefully. With RUN_ONCE it runs a single reap cycle instead and
+// returns its error. The endpoints are served until the process exits.
+func (podReaper *Reaper) Run(ctx context.Context) error {
+	reaper := podReaper.reaper
+	if reaper.options.runOnce {
+		reaper.ctx = ctx
+		return reaper.runOnce()
+	}
+	reaper.serveHTTP()
+	reaper.harvest(ctx)
+	return nil
+}
+
+// newReaperWithOptions creates a reaper with the in cluster configuration.
+func newReaperWithOptions(options Options) (reaper, error) {
+	config, err := rest.InClusterConfig()
+	if err != nil {
+		return reaper{}, fmt.Errorf("unable to get in cluster kubernetes config: %s", err)
+	}
+	return newReaperForConfig(config, options)
+}
+
+// newReaperForConfig creates a reaper with the kubernetes configuration.
+func newReaperForConfig(config *rest.Config, options Options) (reaper, error) {
+	// the client limits are set on a copy, leaving the configuration of the caller untouched
+	config = rest.CopyConfig(config)
+	// zero values keep the client-go defaults
+	if options.clientQPS > 0 {
+		config.QPS = options.clientQPS
+	}
+	if options.clientBurst > 0 {
+		config.Burst = options.clientBurst
+	}
+	if config.QPS == 0 {
+		config.QPS = rest.DefaultQPS
+	}
+	if config.Burst == 0 {
+		config.Burst = rest.DefaultBurst
+	}
+	// every client shares one rate limiter, so that the limits bound the requests of rules too
+	config.RateLimiter = flowcontrol.NewTokenBucketRateLimiter(config.QPS, config.Burst)
+	clientSet, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return reaper{}, fmt.Errorf("unable to get client set for kubernetes config: %s", err)
+	}
+	// rules look up nodes, services, jobs, and logs in the cluster whose pods are reaped, within the budget of the
+	// cycle evaluating them
+	rulesConfig := rest.CopyConfig(config)
+	rulesConfig.Wrap(func(next http.RoundTripper) http.RoundTripper {
+		return budgetTransport{next: next}
+	})
+	cluster, err := rules.NewCluster(rulesConfig, options.apiTimeout)
+	if err != nil {
+		return reaper{}, fmt.Errorf("unable to get rule clients for kubernetes config: %s", err)
+	}
+	reaper := reaper{
+		clientSet:  clientSet,
+		options:    options,
+		budget:     newAPIBudget(options.apiCallBudget),
+		cycles:     newCycleLock(),
+		cluster:    cluster,
+		escalation: newGraceEscalation(options.graceEscalationWindow, options.gracePeriodFloor),
+		cooldown:   newOwnerCooldown(options.workloadCooldown),
+		experiment: newChaosExperiment(options.chaosWindow, options.chaosVerifyTimeout),
+	}
+	if options.action == actionEvict {
+		reaper.evictionVersion, reaper.options.action = evictionAPI(clientSet)
+	}
+	schedule, err := parseSchedule(options.schedule, options.scheduleLocation)
+	if err != nil {
+		return reaper, fmt.Errorf("unable to parse cron schedule %s: %s", options.schedule, err)
+	}
+	if options.reaperPolicies {
+		dynamicClient, err := dynamic.NewForConfig(config)
+		if err != nil {
+			return reaper, fmt.Errorf("unable to get dynamic client for kubernetes config: %s", err)
+		}
+		reaper.policies = newPolicyController(dynamicClient)
+		// liveness tracks the policy sync loop since every policy has its own schedule
+		schedule = cron.Every(options.policySyncInterval)
+	}
+	if options.reapers != nil {
+		schedules := reaperSchedules{}
+		for _, policy := range options.reapers {
+			schedules = append(schedules, policy.schedule)
+		}
+		schedule = schedules
+	}
+	if options.adminAddress != "" {
+		reaper.admin = newAdmin()
+	}
+	if options.adminAuthenticated() {
+		reaper.lastCycle = &lastCycle{}
+	}
+	if options.adminAuthenticated() && options.eventStream {
+		reaper.events = newEventStream()
+		reaper.options.notifiers = append(reaper.options.notifiers, reaper.events)
+	}
+	if options.adminAuthenticated() && options.reapRequestLimit > 0 {
+		reaper.requests = newReapRequestLimiter(options.reapRequestLimit)
+	}
+	if options.healthAddress != "" {
+		reaper.health = newHealth(schedule, options.livenessGracePeriod, options.readinessThreshold, time.Now())
+	}
+	if options.leaderElection {
+		identity, err := os.Hostname()
+		if err != nil {
+			return reaper, fmt.Errorf("unable to determine leader election identity: %s", err)
+		}
+		reaper.leader = &leader{
+			client:        clientSet,
+			namespace:     options.leaderElectionNS,
+			name:          options.leaderElectionID,
+			identity:      identity,
+			leaseDuration: options.leaseDuration,
+			renewDeadline: options.leaseRenewDeadline,
+			retryPeriod:   options.leaseRetryPeriod,
+			schedule:      schedule,
+			apiTimeout:    options.apiTimeout,
+		}
+	}
+	if options.useInformer {
+		// informers run for the life of the process
+		if err := reaper.startPodInformers(make(chan struct{})); err != nil {
+			return reaper, fmt.Errorf("unable to start pod informers: %s", err)
+		}
+	}
+	return reaper, nil
+}
+
+// listNamespaces returns the namespaces to list pods from, where the empty string means all namespaces.
+func (reaper reaper) listNamespaces() []string {
+	if len(reaper.options.namespaces) > 0 {
+		return reaper.options.namespaces
+	}
+	return []string{reaper.options.namespace}
+}
+
+// listOptions returns the options used to list pods, including the label selector built from the label exclusion
+// and requirements, and the field selector. EXCLUDE_LABELS is not part of the label selector since the API server
+// cannot select the pods that do not match a whole selector, the pods it excludes are filtered out afterwards.
+func (reaper reaper) listOptions() metav1.ListOptions {
+	listOptions := metav1.ListOptions{}
+	if reaper.options.labelExclusion != nil || reaper.options.labelRequirement != nil || reaper.options.requireLabels != nil {
+		selector := labels.NewSelector()
+		if reaper.options.labelExclusion != nil {
+			selector = selector.Add(*reaper.options.labelExclusion)
+		}
+		if reaper.options.labelRequirement != nil {
+			selector = selector.Add(*reaper.options.labelRequirement)
+		}
+		if reaper.options.requireLabels != nil {
+			requirements, _ := reaper.options.requireLabels.Requirements()
+			selector = selector.Add(requirements...)
+		}
+		listOptions.LabelSelector = selector.String()
+	}
+	if reaper.options.fieldSelector != nil {
+		listOptions.FieldSelector = reaper.options.fieldSelector.String()
+	}
+	return listOptions
+}
+
+// listPods lists pods a page of LIST_PAGE_SIZE pods at a time, so that neither the API server nor pod-reaper holds
+// every pod of a large namespace in a single response. Each page counts against the cycle's API call budget; when
+// the budget runs out, the pods of the pages already listed are returned with errAPIBudgetExhausted.
+func (reaper reaper) listPods(client corev1.PodInterface, listOptions metav1.ListOptions) ([]v1.Pod, error) {
+	var pods []v1.Pod
+	first := listOptions
+	restarted := false
+	for {
+		if !reaper.apiCall(operationList) {
+			return pods, errAPIBudgetExhausted
+		}
+		var page *v1.PodList
+		err := reaper.retryAPICall(operationList, func(ctx context.Context) (err error) {
+			page, err = client.List(ctx, listOptions)
+			return err
+		})
+		if apierrors.IsResourceExpired(err) && listOptions.Continue != "" && !restarted {
+			// the continue token expired while listing, which only happens when listing takes minutes
+			logrus.WithError(err).Warn("pod listing expired, listing from the start again")
+			pods, listOptions, restarted = nil, first, true
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		pods = append(pods, page.Items...)
+		if page.Continue == "" {
+			return pods, nil
+		}
+		// the continue token carries the resource version of the first page, which may not be given again
+		listOptions.Continue = page.Continue
+		listOptions.ResourceVersion, listOptions.ResourceVersionMatch = "", ""
+	}
+}
+
+// getPods lists the pods to evaluate. Namespaces that fail to list are added to the cycle's result and the pods of the
+// other namespaces are still returned, but when every namespace fails the cycle has nothing to evaluate and the last
+// failure is returned.
+func (reaper reaper) getPods() (*v1.PodList, error) {
+	podList := &v1.PodList{}
+	if reaper.podListers != nil {
+		podList.Items = reaper.getCachedPods()
+	} else {
+		coreClient := reaper.clientSet.CoreV1()
+		listOptions := reaper.listOptions()
+		listOptions.Limit = int64(reaper.options.listPageSize)
+		if reaper.options.listConsistency == listConsistencyCached {
+			// any resource version not older than 0 lets the API server answer from its watch cache
+			listOptions.ResourceVersion = "0"
+			listOptions.ResourceVersionMatch = metav1.ResourceVersionMatchNotOlderThan
+		}
+		namespaces := reaper.listNamespaces()
+		failed := 0
+		var listErr error
+		for _, namespace := range namespaces {
+			pods, err := reaper.listPods(coreClient.Pods(namespace), listOptions)
+			if err == errAPIBudgetExhausted {
+				// the pods listed before the budget ran out are still reaped
+				logrus.WithField("namespace", namespace).Warn("api call budget exhausted, not listing pods")
+				podList.Items = append(podList.Items, pods...)
+				break
+			}
+			if err != nil {
+				// the other namespaces are still reaped, the failure is reported with the rest of the cycle's
+				failed++
+				listErr = err
+				reaper.result.addError(cycleError{Operation: "list", Namespace: namespace, Message: err.Error()})
+				continue
+			}
+			podList.Items = append(podList.Items, pods...)
+		}
+		if failed == len(namespaces) {
+			return nil, fmt.Errorf("unable to get pods from the cluster: %s", listErr)
+		}
+	}
+	if reaper.namespaceSelector != nil {
+		var err error
+		if podList.Items, err = reaper.selectNamespaces(podList.Items); err != nil {
+			return nil, err
+		}
+	}
+	if reaper.targetsNodes() {
+		podList.Items = reaper.selectNodes(podList.Items)
+	}
+	reaper.options.podSortingStrategy(podList.Items)
+	podList.Items = filter(reaper, podList.Items...)
+	return podList, nil
+}
+
+func filter(reaper reaper, pods ...v1.Pod) []v1.Pod {
+	var filtered []v1.Pod
+	now := time.Now()
+	for _, pod := range pods {
+		if protected(pod) {
+			logrus.WithFields(logrus.Fields{
+				"pod":       pod.Name,
+				"namespace": pod.Namespace,
+			}).Debug("pod is protected by the " + annotationProtect + " annotation")
+			continue
+		}
+		if reaper.admin.isSnoozed(pod.Namespace, time.Now()) {
+			logrus.WithFields(logrus.Fields{
+				"pod":       pod.Name,
+				"namespace": pod.Namespace,
+			}).Debug("namespace is snoozed")
+			continue
+		}
+		if reaper.options.excludeLabels != nil && reaper.options.excludeLabels.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		if reaper.options.annotationSelector != nil && !reaper.options.annotationSelector.Matches(labels.Set(pod.Annotations)) {
+			continue
+		}
+		if reaper.options.ownerKinds != nil && !ownedByKind(pod, reaper.options.ownerKinds) {
+			continue
+		}
+		if reaper.options.excludeOwnerKinds != nil && ownedByKind(pod, reaper.options.excludeOwnerKinds) {
+			continue
+		}
+		if reaper.options.ignoreDaemonSetPods && ownedByKind(pod, daemonSetKinds) {
+			continue
+		}
+		if youngerThan(pod, reaper.options.minPodAge, now) {
+			logrus.WithFields(logrus.Fields{
+				"pod":       pod.Name,
+				"namespace": pod.Namespace,
+			}).Debug("pod is younger than " + envMinPodAge)
+			continue
+		}
+		filtered = append(filtered, pod)
+	}
+	return filtered
+}
+
+// youngerThan returns whether the pod was created less than the age ago.
+func youngerThan(pod v1.Pod, age time.Duration, now time.Time) bool {
+	return age > 0 && !pod.CreationTimestamp.IsZero() && now.Sub(pod.CreationTimestamp.Time) < age
+}
+
+// daemonSetKind is the owner kind of the pods of daemon sets, which are recreated on the same node when reaped
+const daemonSetKind = "DaemonSet"
+
+var daemonSetKinds = map[string]bool{daemonSetKind: true}
+
+// ownedByKind returns whether any of the pod's owner references is of one of the kinds.
+func ownedByKind(pod v1.Pod, kinds map[string]bool) bool {
+	for _, owner := range pod.OwnerReferences {
+		if kinds[owner.Kind] {
+			return true
+		}
+	}
+	return false
+}
+
+// decisionFields are the structured fields logged with every reap decision, so that logs can be parsed without
+// matching on messages.
+func (reaper reaper) decisionFields(pod v1.Pod, reasons []string) logrus.Fields {
+	return logrus.Fields{
+		"pod":       pod.Name,
+		"namespace": pod.Namespace,
+		"reasons":   reasons,
+		"reason":    strings.Join(reasons, ", "),
+		"dry_run":   reaper.options.dryRun,
+		"cycleId":   reaper.cycleID,
+	}
+}
+
+// paced returns whether REAP_INTERVAL is waited before dispatching the pod numbered reapedPods. Only pods that may be
+// reaped are paced: the first pod, dry runs, and pods over MAX_PODS are dispatched at once.
+func (reaper reaper) paced(reapedPods int) bool {
+	if reaper.options.reapInterval <= 0 || reapedPods == 0 || reaper.options.dryRun {
+		return false
+	}
+	return reaper.options.maxPods == 0 || reapedPods < reaper.options.maxPods
+}
+
+// reapPod deletes or evicts the pod unless a limit prevents it, and returns whether the pod was reaped.
+func (reaper reaper) reapPod(pod v1.Pod, reasons []string, reapedPods int) bool {
+	deleteOptions := &metav1.DeleteOptions{
+		GracePeriodSeconds: reaper.escalation.gracePeriod(pod, reaper.options.gracePeriod, time.Now()),
+	}
+
+	podLog := logrus.WithFields(reaper.decisionFields(pod, reasons))
+	if deleteOptions.GracePeriodSeconds != reaper.options.gracePeriod {
+		podLog = podLog.WithField("gracePeriodSeconds", *deleteOptions.GracePeriodSeconds)
+	}
+
+	if reaper.options.dryRun {
+		podLog.Info("pod would be reaped but pod-reaper is in dry-run mode")
+		reaper.emitSkipEvent(pod, reasons, "pod-reaper is in dry-run mode")
+		reaper.audit(pod, reasons, auditSkipped, "pod-reaper is in dry-run mode")
+		reaper.notify(pod, reasons)
+		return false
+	}
+
+	if reaper.options.maxPods > 0 && reapedPods >= reaper.options.maxPods {
+		podLog.WithFields(logrus.Fields{
+			"reapedPods": reapedPods,
+			"maxPods":    reaper.options.maxPods,
+		}).Info("pod would be reaped but maxPods is exceeded")
+		reaper.emitSkipEvent(pod, reasons, "maxPods is exceeded")
+		reaper.audit(pod, reasons, auditSkipped, "maxPods is exceeded")
+		return false
+	}
+
+	if reaper.options.action == actionAnnotate && marked(pod) {
+		podLog.Debug("pod is already marked")
+		return false
+	}
+	if reaper.options.action == actionScaleOwner && ownerScaled(pod) {
+		podLog.Debug("owner of the pod was already scaled down")
+		return false
+	}
+	if reaper.options.action == actionScaleOwner && !scalableOwner(pod) {
+		podLog.Info("pod would be reaped but is not controlled by a replica set")
+		reaper.emitSkipEvent(pod, reasons, "pod is not controlled by a replica set")
+		reaper.audit(pod, reasons, auditSkipped, "pod is not controlled by a replica set")
+		return false
+	}
+	if reaper.options.action == actionDelete || reaper.options.action == actionEvict || reaper.stuck(pod) {
+		reapable, err := reaper.reapable(pod, podLog)
+		if err == errAPIBudgetExhausted {
+			podLog.Warn("pod would be reaped but the api call budget is exhausted")
+			reaper.audit(pod, reasons, auditSkipped, "the api call budget is exhausted")
+			return false
+		} else if err != nil {
+			podLog.WithError(err).Warn("pod would be reaped but its job could not be checked")
+			reaper.audit(pod, reasons, auditSkipped, "unable to get the job of the pod")
+			return false
+		} else if !reapable {
+			podLog.Info("pod would be reaped but its job would exceed its backoffLimit")
+			reaper.emitSkipEvent(pod, reasons, "job would exceed its backoffLimit")
+			reaper.audit(pod, reasons, auditSkipped, "job would exceed its backoffLimit")
+			return false
+		}
+		keepsReady, err := reaper.keepsReady(pod, podLog)
+		if err == errAPIBudgetExhausted {
+			podLog.Warn("pod would be reaped but the api call budget is exhausted")
+			reaper.audit(pod, reasons, auditSkipped, "the api call budget is exhausted")
+			return false
+		} else if err != nil {
+			podLog.WithError(err).Warn("pod would be reaped but its workload could not be checked")
+			reaper.audit(pod, reasons, auditSkipped, "unable to get the workload of the pod")
+			return false
+		} else if !keepsReady {
+			podLog.Info("pod would be reaped but its workload would have too few ready replicas")
+			reaper.emitSkipEvent(pod, reasons, "workload would have too few ready replicas")
+			reaper.audit(pod, reasons, auditSkipped, "workload would have too few ready replicas")
+			return false
+		}
+	}
+
+	cooldownTaken := time.Now()
+	if until, ok := reaper.cooldown.take(pod, cooldownTaken); !ok {
+		podLog.WithField("cooldownUntil", until.UTC().Format(time.RFC3339)).Info("pod would be reaped but its owner is cooling down")
+		reaper.emitSkipEvent(pod, reasons, "owner is cooling down")
+		reaper.audit(pod, reasons, auditSkipped, "owner is cooling down")
+		return false
+	}
+
+	operation := operationDelete
+	switch {
+	case reaper.stuck(pod):
+		// stuck pods are force deleted, whichever of delete and evict is the action
+	case reaper.options.action == actionEvict:
+		operation = operationEvict
+	case reaper.options.action == actionAnnotate:
+		operation = operationPatch
+	case reaper.options.action == actionScaleOwner:
+		operation = operationGet
+	}
+	if !reaper.apiCall(operation) {
+		reaper.cooldown.release(pod, cooldownTaken)
+		podLog.Warn("pod would be reaped but the api call budget is exhausted")
+		reaper.audit(pod, reasons, auditSkipped, "the api call budget is exhausted")
+		return false
+	}
+
+	var err error
+	switch {
+	case reaper.stuck(pod):
+		podLog.Info("force deleting stuck pod")
+		err = reaper.forceDelete(pod)
+	case reaper.options.action == actionEvict:
+		podLog.WithField("evictionApi", reaper.evictionGroupVersion()).Info("reaping pod")
+		err = reaper.evict(pod, deleteOptions)
+	case reaper.options.action == actionAnnotate:
+		podLog.Info("marking pod")
+		err = reaper.markPod(pod, reasons, time.Now())
+	case reaper.options.action == actionScaleOwner:
+		podLog.Info("scaling down owner of pod")
+		err = reaper.scaleOwner(pod, reasons, time.Now())
+	default:
+		podLog.Info("reaping pod")
+		err = reaper.retryAPICall(operationDelete, func(ctx context.Context) error {
+			return reaper.clientSet.CoreV1().Pods(pod.Namespace).Delete(ctx, pod.Name, *deleteOptions)
+		})
+	}
+	if err != nil {
+		reaper.cooldown.release(pod, cooldownTaken)
+	}
+	if err == errOwnerAtMinimum {
+		podLog.Info("pod would be reaped but its owner has a single replica")
+		reaper.emitSkipEvent(pod, reasons, "owner has a single replica")
+		reaper.audit(pod, reasons, auditSkipped, "owner has a single replica")
+		return false
+	}
+	if err != nil {
+		// log the error, but continue on
+		logrus.WithFields(logrus.Fields{
+			"pod": pod.Name,
+		}).WithError(err).Warn("unable to " + reaper.options.action + " pod")
+		reaper.audit(pod, reasons, auditFailed, err.Error())
+		return false
+	}
+	observePodReaped(reaper.options.action, reaper.cycleID)
+	reaper.escalation.reaped(pod, time.Now())
+	reaper.experiment.disrupted(pod)
+	reaper.annotateOwner(pod, reasons, time.Now())
+	reaper.audit(pod, reasons, auditReaped, "")
+	if reaper.options.emitEvents && reaper.options.action == actionAnnotate {
+		reaper.emitEvent(pod, v1.EventTypeNormal, eventReasonMarked, "pod was marked for reaping: "+strings.Join(reasons, ", "))
+	} else if reaper.options.emitEvents && reaper.options.action == actionScaleOwner {
+		reaper.emitEvent(pod, v1.EventTypeNormal, eventReasonOwnerScaledDown, "owner was scaled down to remove pod: "+strings.Join(reasons, ", "))
+	} else if reaper.options.emitEvents {
+		reaper.emitEvent(pod, v1.EventTypeNormal, eventReasonReaped, "pod was reaped: "+strings.Join(reasons, ", "))
+	}
+	reaper.notify(pod, reasons)
+	return true
+}
+
+// cycleMaxPods returns the maximum number of pods reaped in a cycle that evaluated and matched the numbers of pods:
+// the lower of MAX_PODS and MAX_PODS_PERCENT of the pods, where 0 is no maximum. The percentage is rounded up, like
+// the maxUnavailable of a pod disruption budget, so that it allows at least one pod of a few.
+func (reaper reaper) cycleMaxPods(evaluated int, matched int) int {
+	if reaper.options.maxPodsPercent <= 0 {
+		return reaper.options.maxPods
+	}
+	pods := evaluated
+	if reaper.options.maxPodsPercentOf == maxPodsPercentOfMatched {
+		pods = matched
+	}
+	// the small tolerance keeps floating point error from rounding a whole number of pods up
+	limit := int(math.Ceil(float64(pods)*reaper.options.maxPodsPercent/100 - 1e-9))
+	if limit == 0 {
+		// no pods means nothing to reap, though a maximum of 0 would not limit the cycle
+		return reaper.options.maxPods
+	}
+	if reaper.options.maxPods > 0 && reaper.options.maxPods < limit {
+		return reaper.options.maxPods
+	}
+	logrus.WithFields(logrus.Fields{"maxPods": limit, "percent": reaper.options.maxPodsPercent, "of": pods}).Debug("limiting reap cycle to a percentage of pods")
+	return limit
+}
+
+// namespaceLimited returns whether the pod is skipped since MAX_PODS_PER_NAMESPACE pods of its namespace were already
+// reaped in the cycle. Like MAX_PODS, the limit does not apply in dry-run mode, where no pod is reaped.
+func (reaper reaper) namespaceLimited(pod v1.Pod, reasons []string, namespacePods int) bool {
+	if reaper.options.maxPodsPerNamespace <= 0 || reaper.options.dryRun || namespacePods < reaper.options.maxPodsPerNamespace {
+		return false
+	}
+	logrus.WithFields(reaper.decisionFields(pod, reasons)).WithFields(logrus.Fields{
+		"namespacePods":       namespacePods,
+		"maxPodsPerNamespace": reaper.options.maxPodsPerNamespace,
+	}).Info("pod would be reaped but maxPodsPerNamespace is exceeded")
+	reaper.emitSkipEvent(pod, reasons, "maxPodsPerNamespace is exceeded")
+	reaper.audit(pod, reasons, auditSkipped, "maxPodsPerNamespace is exceeded")
+	return true
+}
+
+// scytheCycle runs a reap cycle and returns its failures aggregated into a single error, or nil if it had none.
+func (reaper reaper) scytheCycle() error {
+	reaper.cycleID = newCycleID()
+	start := time.Now()
+	defer func() { observeCycleDuration(time.Since(start), reaper.cycleID) }()
+	logrus.WithField("cycleId", reaper.cycleID).Debug("starting reap cycle")
+	window, blackout := reaper.options.blackout.active(start)
+	if blackout {
+		// like a cycle awaiting approval, a cycle in a blackout window only previews the pods it would reap
+		logrus.WithFields(logrus.Fields{"cycleId": reaper.cycleID, "window": window.String()}).Info("reap cycle is in a blackout window, no pods will be reaped")
+		reaper.options.dryRun = true
+	}
+	chaosWindow, inChaosWindow := reaper.options.chaosWindow.active(start)
+	outsideChaosWindow := len(reaper.options.chaosWindow) > 0 && !inChaosWindow
+	if outsideChaosWindow {
+		logrus.WithField("cycleId", reaper.cycleID).Debug("reap cycle is outside of the chaos windows, no pods will be reaped")
+		reaper.options.dryRun = true
+	}
+	// an approval is kept for the first cycle that may reap pods
+	awaitingApproval := !blackout && !outsideChaosWindow && reaper.options.requireApproval && !reaper.admin.takeApproval()
+	if awaitingApproval {
+		// without approval the cycle only previews the pods it would reap
+		reaper.options.dryRun = true
+	}
+	reaper.result = newCycleResult(reaper.cycleID, start, reaper.options.dryRun)
+	reaper.jobs = newJobGuard(reaper.options.protectJobBackoff)
+	reaper.ready = newReadyGuard(reaper.options.minReadyReplicas)
+	reaper.runExperiment(chaosWindow, inChaosWindow, start)
+	pods, err := reaper.getPods()
+	if err != nil {
+		// the failures that ended the cycle are already in its result
+		logrus.WithField("cycleId", reaper.cycleID).WithError(err).Error("reap cycle ended early")
+		reaper.lastCycle.finish(reaper.result, time.Now())
+		return reaper.result.err()
+	}
+	podRules := reaper.newRuleResolver()
+	report := newReapReport()
+	report.CycleID = reaper.cycleID
+	// reapedReport only holds pods that were actually reaped, for namespace reports
+	reapedReport := newReapReport()
+	reapedReport.CycleID = reaper.cycleID
+	var evaluations []podEvaluation
+	// notStarted counts the evaluated pods without a start time, which the run duration rules only flag with
+	// MISSING_START_TIME
+	notStarted := 0
+	for _, pod := range pods.Items {
+		if reaper.budget.exhausted() {
+			break
+		}
+		loadedRules, ok := podRules.rulesFor(pod.Namespace)
+		if !ok {
+			continue
+		}
+		if pod.Status.StartTime == nil {
+			notStarted++
+		}
+		shouldReap, reasons := loadedRules.WithContext(reaper.rulesContext()).ShouldReap(pod)
+		logrus.WithFields(reaper.decisionFields(pod, reasons)).WithFields(logrus.Fields{
+			"rule": loadedRules.Names(),
+			"reap": shouldReap,
+		}).Debug("pod evaluated")
+		evaluation := podEvaluation{pod: pod, shouldReap: shouldReap, reasons: reasons, rules: loadedRules.Names()}
+		if shouldReap {
+			evaluation.priority = loadedRules.Priority(pod)
+		}
+		evaluations = append(evaluations, evaluation)
+	}
+	// pods flagged by higher priority rules are reaped first, within the order of the sorting strategy
+	sort.SliceStable(evaluations, func(i, j int) bool {
+		return evaluations[i].priority > evaluations[j].priority
+	})
+	podsWithoutStartTime.Set(float64(notStarted))
+	reaper.result.Evaluated = len(evaluations)
+	for _, evaluation := range evaluations {
+		if evaluation.shouldReap {
+			reaper.result.Matched++
+		}
+	}
+	if reaper.options.respectTopologySpread {
+		reaper.spreadVictims(evaluations, pods.Items)
+	}
+	reaper.options.maxPods = reaper.cycleMaxPods(len(evaluations), reaper.result.Matched)
+	reapedPods := 0
+	// namespacePods counts the pods dispatched in each namespace for MAX_PODS_PER_NAMESPACE
+	namespacePods := map[string]int{}
+	pool := newReapPool(reaper.options.reapConcurrency)
+	// reports is held while a reap adds to the reports
+	var reports sync.Mutex
+	for _, evaluation := range evaluations {
+		if !reaper.leader.isLeading() {
+			logrus.Warn("lost leadership, ending reap cycle early")
+			reaper.result.addError(cycleError{Operation: "cycle", Message: "lost leadership, ending reap cycle early"})
+			break
+		}
+		if reaper.budget.exhausted() {
+			logrus.WithField("limit", reaper.options.apiCallBudget).Warn("api call budget exhausted, ending reap cycle early")
+			reaper.result.addError(cycleError{Operation: "cycle", Message: "api call budget exhausted, ending reap cycle early"})
+			break
+		}
+		pod, shouldReap, reasons := evaluation.pod, evaluation.shouldReap, evaluation.reasons
+		reaper.matchedRules = evaluation.rules
+		if reaper.options.action == actionPreview {
+			// previews label the whole backlog, so neither MAX_PODS nor the other reap limits apply
+			reaper.previewPod(pod, shouldReap, reasons, time.Now())
+			reaper.annotateVerdict(pod, shouldReap, reasons)
+			continue
+		}
+		if !shouldReap {
+			reaper.clearMark(pod)
+		} else if !reaper.options.dryRun && !reaper.markGraceElapsed(pod, time.Now()) {
+			continue
+		}
+		if !shouldReap {
+			reaper.annotateVerdict(pod, shouldReap, reasons)
+			continue
+		}
+		if reaper.namespaceLimited(pod, reasons, namespacePods[pod.Namespace]) {
+			reaper.annotateVerdict(pod, shouldReap, reasons)
+			continue
+		}
+		namespacePods[pod.Namespace]++
+		// pods are numbered as they are dispatched, so MAX_PODS is exact however many are reaped at once
+		reapedPods++
+		worker, index := reaper, reapedPods-1
+		// pace reaps so that large batches do not all need rescheduling at the same time, however many are reaped at once
+		if reaper.paced(index) && !reaper.sleep(reaper.options.reapInterval) {
+			logrus.Warn("pod-reaper is stopping, ending reap cycle early")
+			reaper.result.addError(cycleError{Operation: "cycle", Message: "pod-reaper is stopping, ending reap cycle early"})
+			break
+		}
+		pool.run(func() {
+			reaped := worker.reapPod(pod, reasons, index)
+			reports.Lock()
+			if reaped {
+				reapedReport.add(pod, reasons, worker.options.action)
+			}
+			if worker.options.dryRun {
+				report.add(pod, reasons, worker.options.action)
+			}
+			reports.Unlock()
+			if reaped && worker.budget != nil {
+				worker.leader.cycleProgress(worker.budget.usedCalls())
+			}
+			if !reaped {
+				worker.annotateVerdict(pod, shouldReap, reasons)
+			}
+		})
+	}
+	pool.wait()
+	if awaitingApproval && len(report.Pods) > 0 {
+		reaper.admin.holdForApproval(reaper.cycleID)
+		reaper.requestApproval(len(report.Pods))
+	}
+	if reaper.options.dryRun && reaper.options.dryRunReport != "" {
+		reaper.writeDryRunReport(report)
+	}
+	if reaper.options.namespaceReports {
+		reaper.writeNamespaceReports(reapedReport)
+	}
+	reaper.flushNotifiers()
+	reaper.flushAudit()
+	reaper.options.warehouse.flush(time.Now())
+	if err = reaper.result.err(); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"cycleId": reaper.cycleID,
+			"errors":  []cycleError(err.(cycleErrors)),
+		}).WithError(err).Error("reap cycle finished with errors")
+	}
+	reaper.lastCycle.finish(reaper.result, time.Now())
+	return err
+}
+
+// include optional seconds
+var scheduleParser = cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// parseSchedule parses a cron schedule in the location of SCHEDULE_TIMEZONE, unless the schedule sets its own with a
+// CRON_TZ= prefix. Schedules are in the container's local time when location is nil.
+func parseSchedule(spec string, location *time.Location) (cron.Schedule, error) {
+	if location != nil && !strings.HasPrefix(spec, "CRON_TZ=") && !strings.HasPrefix(spec, "TZ=") {
+		spec = "CRON_TZ=" + location.String() + " " + spec
+	}
+	return scheduleParser.Parse(spec)
+}
+
+func cronWithOptionalSeconds() *cron.Cron {
+	return cron.New(cron.WithParser(scheduleParser))
+}
+
+// scheduledCycle runs the reap cycle of a tick of SCHEDULE when leading. Every tick counts as started for the liveness
+// probe, leading or not, since the scheduler of a standby replica is just as alive.
+func (reaper reaper) scheduledCycle() {
+	now := time.Now()
+	reaper.health.cycleStarted(now)
+	slot := now.Truncate(time.Second)
+	if !reaper.leader.claimSlot(slot) {
+		logrus.Debug("not leading, skipping reap cycle")
+		return
+	}
+	if !reaper.waitJitter() {
+		return
+	}
+	reaper.runCycle(slot)
+}
+
+// runCycle runs a reap cycle unless the previous one is still running, in which case the cycle is skipped rather than
+// reaping the same pods twice.
+func (reaper reaper) runCycle(slot time.Time) {
+	if !reaper.cycles.tryLock() {
+		logrus.WithField("slot", slot).Warn("previous reap cycle is still running, skipping reap cycle")
+		return
+	}
+	defer reaper.cycles.unlock()
+	reaper.cycle(slot)
+}
+
+// cycle runs a reap cycle with its own budget and records its result for the health endpoints, where a cycle with any
+// failure counts as failed. When health endpoints are enabled, a cycle that cannot continue is recovered so that the
+// readiness probe can report it; otherwise it crashes pod-reaper so that kubernetes restarts it. The caller must hold
+// the cycle lock.
+func (reaper reaper) cycle(slot time.Time) {
+	reaper.budget = reaper.budget.newCycle()
+	reaper.leader.cycleStarted(slot, time.Now())
+	if reaper.health != nil {
+		defer reaper.recoverCycle()
+	}
+	reaper.health.cycleFinished(reaper.scytheCycle())
+	reaper.leader.cycleFinished(reaper.budget.usedCalls(), time.Now())
+}
+
+// cycleLock keeps the reap cycles of a reaper from running at the same time. A nil lock never blocks.
+type cycleLock struct {
+	mutex sync.Mutex
+}
+
+func newCycleLock() *cycleLock {
+	return &cycleLock{}
+}
+
+// lock waits for the running cycle to finish before taking the lock.
+func (lock *cycleLock) lock() {
+	if lock != nil {
+		lock.mutex.Lock()
+	}
+}
+
+// tryLock takes the lock and returns true, or returns false if a cycle is running.
+func (lock *cycleLock) tryLock() bool {
+	return lock == nil || lock.mutex.TryLock()
+}
+
+func (lock *cycleLock) unlock() {
+	if lock != nil {
+		lock.mutex.Unlock()
+	}
+}
+
+// waitJitter delays a scheduled cycle by a random duration up to SCHEDULE_JITTER, so that reapers deployed from the
+// same configuration do not all call their API servers at the same moment. It returns false if pod-reaper is stopped
+// while waiting.
+func (reaper reaper) waitJitter() bool {
+	if reaper.options.scheduleJitter <= 0 {
+		return true
+	}
+	delay := time.Duration(rand.Int63n(int64(reaper.options.scheduleJitter)))
+	logrus.WithField("delay", delay.String()).Debug("delaying reap cycle by the schedule jitter")
+	return reaper.sleep(delay)
+}
+
+// recoverCycle recovers a failed cycle and records the failure for the readiness probe. It must be deferred.
+func (reaper reaper) recoverCycle() {
+	if r := recover(); r != nil {
+		err := panicError(r)
+		logrus.WithError(err).Error("reap cycle failed")
+		reaper.health.cycleFinished(err)
+	}
+}
+
+// panicError converts a recovered panic to an error, including the message and error of logrus panics.
+func panicError(r interface{}) error {
+	entry, ok := r.(*logrus.Entry)
+	if !ok {
+		return fmt.Errorf("%v", r)
+	}
+	if err, ok := entry.Data[logrus.ErrorKey].(error); ok {
+		return fmt.Errorf("%s: %s", entry.Message, err)
+	}
+	return errors.New(entry.Message)
+}
+
+// runOnce runs a single reap cycle for RUN_ONCE, returning its failures, including a failure that ended the cycle, as
+// an error.
+func (reaper reaper) runOnce() (err error) {
+	if reaper.policies != nil {
+		return fmt.Errorf("%s cannot be used with %s, since every policy has its own schedule", envRunOnce, envReaperPolicies)
+	}
+	if reaper.options.reapers != nil {
+		return fmt.Errorf("%s cannot be used with %s, since every reaper has its own schedule", envRunOnce, envReapers)
+	}
+	// there is nothing to hand over to another replica, the job running pod-reaper decides when a cycle runs
+	reaper.leader = nil
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicError(r)
+		}
+	}()
+	return reaper.scytheCycle()
+}
+
+// harvest runs reap cycles on the schedule until RUN_DURATION elapses or the context is cancelled, and then shuts down
+// gracefully.
+func (reaper reaper) harvest(ctx context.Context) {
+	cycles, stopCycles := context.WithCancel(context.Background())
+	reaper.ctx = cycles
+	schedule := cronWithOptionalSeconds()
+	if reaper.policies != nil {
+		reaper.policies.start(reaper, schedule, reaper.options.policySyncInterval)
+	} else if reaper.options.reapers != nil {
+		reaper.scheduleReapers(schedule)
+	} else {
+		cycleSchedule, err := parseSchedule(reaper.options.schedule, reaper.options.scheduleLocation)
+		if err != nil {
+			logrus.WithError(err).Panic("unable to create cron schedule: " + reaper.options.schedule)
+		}
+		schedule.Schedule(cycleSchedule, cron.FuncJob(reaper.scheduledCycle))
+	}
+
+	schedule.Start()
+	leading, stopLeading := context.WithCancel(context.Background())
+	leaderStopped := make(chan struct{})
+	if reaper.leader != nil {
+		go func() {
+			reaper.leader.run(leading, reaper)
+			close(leaderStopped)
+		}()
+	} else {
+		close(leaderStopped)
+	}
+
+	// receiving from a nil channel blocks forever, so without a run duration only the context stops pod-reaper
+	var runDuration <-chan time.Time
+	if reaper.options.runDuration != 0 {
+		runDuration = time.After(reaper.options.runDuration)
+	}
+	select {
+	case <-ctx.Done():
+		logrus.Info("shutting down pod reaper")
+	case <-runDuration:
+	}
+	reaper.shutdown(schedule, stopCycles, stopLeading, leaderStopped)
+}
@@ -0,0 +1,1075 @@
+package reaper
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/target/pod-reaper/rules"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func init() {
+	logrus.SetOutput(ioutil.Discard) // disable logging during tests
+}
+
+// === Test Helpers ===
+
+// loadRulesForTest loads rules using chaos chance to control reaping behavior
+// chaosChance "1.0" = always reap, "0.0" = never reap
+func loadRulesForTest(chaosChance string) rules.Rules {
+	os.Clearenv()
+	os.Setenv("CHAOS_CHANCE", chaosChance)
+	r, _ := rules.LoadRules()
+	return r
+}
+
+// createTestPod creates a pod with the given name and namespace
+func createTestPod(name, namespace string, startTime *time.Time) v1.Pod {
+	pod := v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+	}
+	if startTime != nil {
+		setTime := metav1.NewTime(*startTime)
+		pod.Status.StartTime = &setTime
+	}
+	return pod
+}
+
+// createTestReaper creates a reaper with a fake clientset containing the provided pods
+func createTestReaper(opts Options, pods ...v1.Pod) reaper {
+	objects := make([]runtime.Object, len(pods))
+	for i := range pods {
+		objects[i] = &pods[i]
+	}
+	return reaper{
+		clientSet: fake.NewSimpleClientset(objects...),
+		options:   opts,
+	}
+}
+
+// minimalOptions creates minimal valid options for testing
+// Pass chaosChance "0.0" for no reaping, "1.0" for always reap
+func minimalOptions(chaosChance string) Options {
+	return Options{
+		namespace:          "default",
+		schedule:           "@every 1m",
+		podSortingStrategy: defaultSort,
+		action:             actionDelete,
+		rules:              loadRulesForTest(chaosChance),
+	}
+}
+
+func TestReaperFilter(t *testing.T) {
+	pods := []v1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "bearded-dragon",
+				Annotations: map[string]string{"example/key": "lizard"},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "corgi",
+				Annotations: map[string]string{"example/key": "not-lizard"},
+			},
+		},
+	}
+	annotationRequirement, _ := labels.NewRequirement("example/key", selection.In, []string{"lizard"})
+	reaper := reaper{
+		options: Options{
+			annotationSelector: labels.NewSelector().Add(*annotationRequirement),
+		},
+	}
+	filteredPods := filter(reaper, pods...)
+	assert.Equal(t, 1, len(filteredPods))
+	assert.Equal(t, "bearded-dragon", filteredPods[0].ObjectMeta.Name)
+}
+
+func TestReaperFilterOwnerKinds(t *testing.T) {
+	owned := func(name string, kind string) v1.Pod {
+		return v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            name,
+				OwnerReferences: []metav1.OwnerReference{{Kind: kind, Name: "owner"}},
+			},
+		}
+	}
+	pods := []v1.Pod{
+		owned("web", "ReplicaSet"),
+		owned("db", "StatefulSet"),
+		owned("agent", "DaemonSet"),
+		{ObjectMeta: metav1.ObjectMeta{Name: "bare"}},
+	}
+	t.Run("owner kinds", func(t *testing.T) {
+		reaper := reaper{options: Options{ownerKinds: map[string]bool{"ReplicaSet": true}}}
+		filteredPods := filter(reaper, pods...)
+		assert.Equal(t, 1, len(filteredPods))
+		assert.Equal(t, "web", filteredPods[0].Name)
+	})
+	t.Run("exclude owner kinds", func(t *testing.T) {
+		reaper := reaper{options: Options{excludeOwnerKinds: map[string]bool{"StatefulSet": true, "DaemonSet": true}}}
+		filteredPods := filter(reaper, pods...)
+		assert.Equal(t, 2, len(filteredPods))
+		assert.Equal(t, "web", filteredPods[0].Name)
+		assert.Equal(t, "bare", filteredPods[1].Name)
+	})
+	t.Run("ignore daemon set pods", func(t *testing.T) {
+		reaper := reaper{options: Options{ignoreDaemonSetPods: true}}
+		filteredPods := filter(reaper, pods...)
+		assert.Equal(t, 3, len(filteredPods))
+		assert.Equal(t, "web", filteredPods[0].Name)
+		assert.Equal(t, "db", filteredPods[1].Name)
+		assert.Equal(t, "bare", filteredPods[2].Name)
+	})
+	t.Run("get pods", func(t *testing.T) {
+		opts := minimalOptions("0.0")
+		opts.ownerKinds = map[string]bool{"ReplicaSet": true}
+		web := owned("web", "ReplicaSet")
+		web.Namespace = "default"
+		db := owned("db", "StatefulSet")
+		db.Namespace = "default"
+		r := createTestReaper(opts, web, db)
+		podList, err := r.getPods()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(podList.Items))
+		assert.Equal(t, "web", podList.Items[0].Name)
+	})
+}
+
+// === getPods Tests ===
+
+func TestReaperFilterMinPodAge(t *testing.T) {
+	created := func(name string, age time.Duration) v1.Pod {
+		pod := createTestPod(name, "default", nil)
+		pod.CreationTimestamp = metav1.NewTime(time.Now().Add(-age))
+		return pod
+	}
+	pods := []v1.Pod{
+		created("old", time.Hour),
+		created("young", time.Minute),
+		createTestPod("unknown", "default", nil),
+	}
+	t.Run("disabled", func(t *testing.T) {
+		assert.Len(t, filter(reaper{}, pods...), 3)
+	})
+	t.Run("min pod age", func(t *testing.T) {
+		filteredPods := filter(reaper{options: Options{minPodAge: 5 * time.Minute}}, pods...)
+		assert.Equal(t, 2, len(filteredPods))
+		assert.Equal(t, "old", filteredPods[0].Name)
+		assert.Equal(t, "unknown", filteredPods[1].Name)
+	})
+	t.Run("scythe cycle", func(t *testing.T) {
+		opts := minimalOptions("1.0")
+		opts.minPodAge = 5 * time.Minute
+		r := createTestReaper(opts, created("old", time.Hour), created("young", time.Minute))
+		assert.NoError(t, r.scytheCycle())
+		remaining, _ := r.clientSet.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+		assert.Len(t, remaining.Items, 1)
+		assert.Equal(t, "young", remaining.Items[0].Name)
+	})
+}
+
+func TestGetPods(t *testing.T) {
+	t.Run("basic list", func(t *testing.T) {
+		startTime := time.Now()
+		pods := []v1.Pod{
+			createTestPod("pod-1", "default", &startTime),
+			createTestPod("pod-2", "default", &startTime),
+			createTestPod("pod-3", "default", &startTime),
+		}
+		opts := minimalOptions("0.0")
+		r := createTestReaper(opts, pods...)
+
+		podList, err := r.getPods()
+		assert.NoError(t, err)
+		assert.Equal(t, 3, len(podList.Items))
+	})
+
+	t.Run("namespace filtering", func(t *testing.T) {
+		startTime := time.Now()
+		pods := []v1.Pod{
+			createTestPod("pod-1", "default", &startTime),
+			createTestPod("pod-2", "kube-system", &startTime),
+			createTestPod("pod-3", "default", &startTime),
+		}
+		opts := minimalOptions("0.0")
+		opts.namespace = "default"
+		r := createTestReaper(opts, pods...)
+
+		podList, err := r.getPods()
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(podList.Items))
+		for _, pod := range podList.Items {
+			assert.Equal(t, "default", pod.Namespace)
+		}
+	})
+
+	t.Run("all namespaces", func(t *testing.T) {
+		startTime := time.Now()
+		pods := []v1.Pod{
+			createTestPod("pod-1", "default", &startTime),
+			createTestPod("pod-2", "kube-system", &startTime),
+		}
+		opts := minimalOptions("0.0")
+		opts.namespace = "" // empty = all namespaces
+		r := createTestReaper(opts, pods...)
+
+		podList, err := r.getPods()
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(podList.Items))
+	})
+
+	t.Run("multiple namespaces", func(t *testing.T) {
+		startTime := time.Now()
+		pods := []v1.Pod{
+			createTestPod("pod-1", "default", &startTime),
+			createTestPod("pod-2", "kube-system", &startTime),
+			createTestPod("pod-3", "team-a", &startTime),
+		}
+		opts := minimalOptions("0.0")
+		opts.namespace = ""
+		opts.namespaces = []string{"default", "team-a"}
+		r := createTestReaper(opts, pods...)
+
+		podList, err := r.getPods()
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(podList.Items))
+		for _, pod := range podList.Items {
+			assert.NotEqual(t, "kube-system", pod.Namespace)
+		}
+	})
+
+	t.Run("label exclusion", func(t *testing.T) {
+		startTime := time.Now()
+		excludedPod := createTestPod("excluded-pod", "default", &startTime)
+		excludedPod.Labels = map[string]string{"exclude": "true"}
+		includedPod := createTestPod("included-pod", "default", &startTime)
+		includedPod.Labels = map[string]string{"exclude": "false"}
+
+		opts := minimalOptions("0.0")
+		exclusion, _ := labels.NewRequirement("exclude", selection.NotIn, []string{"true"})
+		opts.labelExclusion = exclusion
+		r := createTestReaper(opts, excludedPod, includedPod)
+
+		podList, err := r.getPods()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(podList.Items))
+		assert.Equal(t, "included-pod", podList.Items[0].Name)
+	})
+
+	t.Run("label requirement", func(t *testing.T) {
+		startTime := time.Now()
+		matchingPod := createTestPod("matching-pod", "default", &startTime)
+		matchingPod.Labels = map[string]string{"app": "target"}
+		nonMatchingPod := createTestPod("non-matching-pod", "default", &startTime)
+		nonMatchingPod.Labels = map[string]string{"app": "other"}
+
+		opts := minimalOptions("0.0")
+		requirement, _ := labels.NewRequirement("app", selection.In, []string{"target"})
+		opts.labelRequirement = requirement
+		r := createTestReaper(opts, matchingPod, nonMatchingPod)
+
+		podList, err := r.getPods()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(podList.Items))
+		assert.Equal(t, "matching-pod", podList.Items[0].Name)
+	})
+
+	t.Run("label selectors", func(t *testing.T) {
+		startTime := time.Now()
+		web := createTestPod("web", "default", &startTime)
+		web.Labels = map[string]string{"tier": "web", "disposable": "true"}
+		database := createTestPod("database", "default", &startTime)
+		database.Labels = map[string]string{"tier": "web", "app": "database", "disposable": "true"}
+		kept := createTestPod("kept", "default", &startTime)
+		kept.Labels = map[string]string{"tier": "api"}
+		batch := createTestPod("batch", "default", &startTime)
+		batch.Labels = map[string]string{"tier": "batch", "disposable": "true"}
+
+		opts := minimalOptions("0.0")
+		opts.requireLabels, _ = labels.Parse("tier in (web,api)")
+		opts.excludeLabels, _ = labels.Parse("app=database")
+		r := createTestReaper(opts, web, database, kept, batch)
+		assert.Equal(t, "tier in (api,web)", r.listOptions().LabelSelector)
+
+		podList, err := r.getPods()
+		assert.NoError(t, err)
+		var names []string
+		for _, pod := range podList.Items {
+			names = append(names, pod.Name)
+		}
+		assert.ElementsMatch(t, []string{"web", "kept"}, names)
+
+		r.options.excludeLabels, _ = labels.Parse("app=database,!disposable")
+		podList, err = r.getPods()
+		assert.NoError(t, err)
+		names = nil
+		for _, pod := range podList.Items {
+			names = append(names, pod.Name)
+		}
+		assert.ElementsMatch(t, []string{"web", "database", "kept"}, names)
+	})
+
+	t.Run("annotation filter", func(t *testing.T) {
+		startTime := time.Now()
+		matchingPod := createTestPod("matching-pod", "default", &startTime)
+		matchingPod.Annotations = map[string]string{"reap": "true"}
+		nonMatchingPod := createTestPod("non-matching-pod", "default", &startTime)
+		nonMatchingPod.Annotations = map[string]string{"reap": "false"}
+
+		opts := minimalOptions("0.0")
+		requirement, _ := labels.NewRequirement("reap", selection.In, []string{"true"})
+		opts.annotationSelector = labels.NewSelector().Add(*requirement)
+		r := createTestReaper(opts, matchingPod, nonMatchingPod)
+
+		podList, err := r.getPods()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(podList.Items))
+		assert.Equal(t, "matching-pod", podList.Items[0].Name)
+	})
+
+	t.Run("sorting applied - oldest first", func(t *testing.T) {
+		now := time.Now()
+		oldTime := now.Add(-10 * time.Minute)
+		newTime := now.Add(-1 * time.Minute)
+
+		oldPod := createTestPod("old-pod", "default", &oldTime)
+		newPod := createTestPod("new-pod", "default", &newTime)
+
+		opts := minimalOptions("0.0")
+		opts.podSortingStrategy = oldestFirstSort
+		r := createTestReaper(opts, newPod, oldPod) // insert in wrong order
+
+		podList, err := r.getPods()
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(podList.Items))
+		assert.Equal(t, "old-pod", podList.Items[0].Name) // oldest should be first
+	})
+
+	t.Run("list error", func(t *testing.T) {
+		fakeClient := fake.NewSimpleClientset()
+		fakeClient.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, errors.New("simulated API error")
+		})
+
+		r := reaper{
+			clientSet: fakeClient,
+			options:   minimalOptions("0.0"),
+			result:    newCycleResult("cycle", time.Now(), false),
+		}
+
+		_, err := r.getPods()
+		assert.Error(t, err)
+		assert.Equal(t, cycleErrors{{Operation: "list", Namespace: "default", Message: "simulated API error"}}, r.result.Errors)
+	})
+}
+
+// === reapPod Tests ===
+
+func TestReapPod(t *testing.T) {
+	t.Run("dry run skips deletion", func(t *testing.T) {
+		startTime := time.Now()
+		pod := createTestPod("test-pod", "default", &startTime)
+		opts := minimalOptions("0.0")
+		opts.dryRun = true
+		r := createTestReaper(opts, pod)
+
+		r.reapPod(pod, []string{"test reason"}, 0)
+
+		// Verify pod still exists (not deleted)
+		result, err := r.clientSet.CoreV1().Pods("default").Get(context.TODO(), "test-pod", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, "test-pod", result.Name)
+	})
+
+	t.Run("maxPods exceeded skips deletion", func(t *testing.T) {
+		startTime := time.Now()
+		pod := createTestPod("test-pod", "default", &startTime)
+		opts := minimalOptions("0.0")
+		opts.maxPods = 2
+		r := createTestReaper(opts, pod)
+
+		r.reapPod(pod, []string{"test reason"}, 2) // reapedPods >= maxPods
+
+		// Verify pod still exists (not deleted)
+		result, err := r.clientSet.CoreV1().Pods("default").Get(context.TODO(), "test-pod", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, "test-pod", result.Name)
+	})
+
+	t.Run("delete path", func(t *testing.T) {
+		startTime := time.Now()
+		pod := createTestPod("test-pod", "default", &startTime)
+		opts := minimalOptions("0.0")
+		opts.action = actionDelete
+		r := createTestReaper(opts, pod)
+
+		r.reapPod(pod, []string{"test reason"}, 0)
+
+		// Verify pod was deleted
+		_, err := r.clientSet.CoreV1().Pods("default").Get(context.TODO(), "test-pod", metav1.GetOptions{})
+		assert.Error(t, err) // should be NotFound
+	})
+
+	t.Run("evict path", func(t *testing.T) {
+		startTime := time.Now()
+		pod := createTestPod("test-pod", "default", &startTime)
+		opts := minimalOptions("0.0")
+		opts.action = actionEvict
+		r := createTestReaper(opts, pod)
+
+		// Note: fake client may not fully support eviction, but we can verify no panic
+		r.reapPod(pod, []string{"test reason"}, 0)
+		// Just verifying it doesn't panic is sufficient for eviction path
+	})
+
+	t.Run("annotate path", func(t *testing.T) {
+		startTime := time.Now()
+		pod := createTestPod("test-pod", "default", &startTime)
+		opts := minimalOptions("0.0")
+		opts.action = actionAnnotate
+		r := createTestReaper(opts, pod)
+
+		assert.True(t, r.reapPod(pod, []string{"reason one", "reason two"}, 0))
+
+		result, err := r.clientSet.CoreV1().Pods("default").Get(context.TODO(), "test-pod", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, "true", result.Labels[labelMarked])
+		assert.Equal(t, "reason one; reason two", result.Annotations[annotationMarkedReasons])
+		assert.NotEmpty(t, result.Annotations[annotationMarkedAt])
+
+		// a marked pod keeps the time it was first marked
+		assert.False(t, r.reapPod(*result, []string{"reason one"}, 0))
+	})
+
+	t.Run("grace period used", func(t *testing.T) {
+		startTime := time.Now()
+		pod := createTestPod("test-pod", "default", &startTime)
+		gracePeriod := int64(30)
+		opts := minimalOptions("0.0")
+		opts.gracePeriod = &gracePeriod
+
+		fakeClient := fake.NewSimpleClientset(&pod)
+		var capturedOptions metav1.DeleteOptions
+		fakeClient.PrependReactor("delete", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			deleteAction := action.(k8stesting.DeleteAction)
+			capturedOptions = deleteAction.GetDeleteOptions()
+			return false, nil, nil // let the fake client handle it
+		})
+
+		r := reaper{
+			clientSet: fakeClient,
+			options:   opts,
+		}
+
+		r.reapPod(pod, []string{"test reason"}, 0)
+
+		assert.NotNil(t, capturedOptions.GracePeriodSeconds)
+		assert.Equal(t, int64(30), *capturedOptions.GracePeriodSeconds)
+	})
+
+	t.Run("grace period escalated for repeat owners", func(t *testing.T) {
+		first := testOwnedPod("pod-1", "owner")
+		second := testOwnedPod("pod-2", "owner")
+		gracePeriod := int64(30)
+		opts := minimalOptions("0.0")
+		opts.gracePeriod = &gracePeriod
+
+		fakeClient := fake.NewSimpleClientset(&first, &second)
+		var capturedOptions []metav1.DeleteOptions
+		fakeClient.PrependReactor("delete", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			capturedOptions = append(capturedOptions, action.(k8stesting.DeleteAction).GetDeleteOptions())
+			return false, nil, nil
+		})
+
+		r := reaper{
+			clientSet:  fakeClient,
+			options:    opts,
+			escalation: newGraceEscalation(time.Hour, 0),
+		}
+
+		assert.True(t, r.reapPod(first, []string{"test reason"}, 0))
+		assert.True(t, r.reapPod(second, []string{"test reason"}, 1))
+
+		if assert.Len(t, capturedOptions, 2) {
+			assert.Equal(t, int64(30), *capturedOptions[0].GracePeriodSeconds)
+			assert.Equal(t, int64(15), *capturedOptions[1].GracePeriodSeconds)
+		}
+	})
+
+	t.Run("delete error is logged but does not panic", func(t *testing.T) {
+		startTime := time.Now()
+		pod := createTestPod("test-pod", "default", &startTime)
+
+		fakeClient := fake.NewSimpleClientset(&pod)
+		fakeClient.PrependReactor("delete", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, errors.New("simulated delete error")
+		})
+
+		opts := minimalOptions("0.0")
+		r := reaper{
+			clientSet: fakeClient,
+			options:   opts,
+		}
+
+		// Should not panic, just log the error
+		assert.NotPanics(t, func() {
+			r.reapPod(pod, []string{"test reason"}, 0)
+		})
+	})
+}
+
+// === scytheCycle Tests ===
+
+func TestScytheCycle(t *testing.T) {
+	t.Run("no pods", func(t *testing.T) {
+		opts := minimalOptions("0.0")
+		r := createTestReaper(opts)
+
+		assert.NotPanics(t, func() {
+			r.scytheCycle()
+		})
+	})
+
+	t.Run("no matching rules - no deletions", func(t *testing.T) {
+		startTime := time.Now()
+		pod1 := createTestPod("pod-1", "default", &startTime)
+		pod2 := createTestPod("pod-2", "default", &startTime)
+
+		opts := minimalOptions("0.0") // chaos chance 0.0 = never reap
+		r := createTestReaper(opts, pod1, pod2)
+
+		r.scytheCycle()
+
+		// Verify pods still exist
+		result, _ := r.clientSet.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+		assert.Equal(t, 2, len(result.Items))
+	})
+
+	t.Run("all pods match - all deleted", func(t *testing.T) {
+		startTime := time.Now()
+		pod1 := createTestPod("pod-1", "default", &startTime)
+		pod2 := createTestPod("pod-2", "default", &startTime)
+
+		opts := minimalOptions("1.0") // chaos chance 1.0 = always reap
+		r := createTestReaper(opts, pod1, pod2)
+
+		r.scytheCycle()
+
+		// Verify pods were deleted
+		result, _ := r.clientSet.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+		assert.Equal(t, 0, len(result.Items))
+	})
+
+	t.Run("maxPods limits deletions", func(t *testing.T) {
+		startTime := time.Now()
+		pod1 := createTestPod("pod-1", "default", &startTime)
+		pod2 := createTestPod("pod-2", "default", &startTime)
+		pod3 := createTestPod("pod-3", "default", &startTime)
+
+		opts := minimalOptions("1.0") // chaos chance 1.0 = always reap
+		opts.maxPods = 2
+		r := createTestReaper(opts, pod1, pod2, pod3)
+
+		r.scytheCycle()
+
+		// Only 2 should be deleted, 1 should remain
+		result, _ := r.clientSet.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+		assert.Equal(t, 1, len(result.Items))
+	})
+
+	t.Run("maxPodsPerNamespace limits deletions in each namespace", func(t *testing.T) {
+		startTime := time.Now()
+		opts := minimalOptions("1.0")
+		opts.namespace = ""
+		opts.maxPods = 3
+		opts.maxPodsPerNamespace = 2
+		r := createTestReaper(opts,
+			createTestPod("noisy-1", "noisy", &startTime),
+			createTestPod("noisy-2", "noisy", &startTime),
+			createTestPod("noisy-3", "noisy", &startTime),
+			createTestPod("quiet-1", "quiet", &startTime))
+
+		r.scytheCycle()
+
+		noisy, _ := r.clientSet.CoreV1().Pods("noisy").List(context.TODO(), metav1.ListOptions{})
+		quiet, _ := r.clientSet.CoreV1().Pods("quiet").List(context.TODO(), metav1.ListOptions{})
+		assert.Equal(t, 1, len(noisy.Items))
+		assert.Equal(t, 0, len(quiet.Items))
+	})
+
+	t.Run("maxPodsPercent limits deletions", func(t *testing.T) {
+		startTime := time.Now()
+		var pods []v1.Pod
+		for i := 0; i < 10; i++ {
+			pods = append(pods, createTestPod("pod-"+strconv.Itoa(i), "default", &startTime))
+		}
+
+		opts := minimalOptions("1.0")
+		opts.maxPodsPercent = 25
+		opts.maxPodsPercentOf = maxPodsPercentOfListed
+		r := createTestReaper(opts, pods...)
+
+		r.scytheCycle()
+
+		// 25% of 10 pods is rounded up to 3 pods
+		result, _ := r.clientSet.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+		assert.Equal(t, 7, len(result.Items))
+	})
+
+	t.Run("higher priority rules reap first", func(t *testing.T) {
+		startTime := time.Now().Add(-time.Hour)
+		pod1 := createTestPod("pod-1", "default", &startTime)
+		pod2 := createTestPod("pod-2", "default", &startTime)
+		pod2.Labels = map[string]string{"urgent": "true"}
+
+		opts := minimalOptions("1.0")
+		opts.rules, _ = rules.LoadRulesFromMap(map[string]string{
+			"CHAOS_CHANCE":    "1.0",
+			"MAX_DURATION":    "1m",
+			"RULE_SELECTORS":  "chaos:urgent=true",
+			"RULE_PRIORITIES": "chaos:10",
+		})
+		opts.maxPods = 1
+		r := createTestReaper(opts, pod1, pod2)
+
+		r.scytheCycle()
+
+		result, _ := r.clientSet.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+		if assert.Len(t, result.Items, 1) {
+			assert.Equal(t, "pod-1", result.Items[0].Name)
+		}
+	})
+
+	t.Run("reapInterval paces deletions", func(t *testing.T) {
+		startTime := time.Now()
+		pod1 := createTestPod("pod-1", "default", &startTime)
+		pod2 := createTestPod("pod-2", "default", &startTime)
+		pod3 := createTestPod("pod-3", "default", &startTime)
+
+		opts := minimalOptions("1.0") // chaos chance 1.0 = always reap
+		opts.reapInterval = 50 * time.Millisecond
+		r := createTestReaper(opts, pod1, pod2, pod3)
+
+		start := time.Now()
+		r.scytheCycle()
+
+		// no wait before the first deletion, one interval before each of the others
+		assert.True(t, time.Since(start) >= 100*time.Millisecond)
+		result, _ := r.clientSet.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+		assert.Equal(t, 0, len(result.Items))
+	})
+
+	t.Run("reapInterval paces concurrent deletions", func(t *testing.T) {
+		startTime := time.Now()
+		pod1 := createTestPod("pod-1", "default", &startTime)
+		pod2 := createTestPod("pod-2", "default", &startTime)
+		pod3 := createTestPod("pod-3", "default", &startTime)
+
+		opts := minimalOptions("1.0")
+		opts.reapInterval = 50 * time.Millisecond
+		opts.reapConcurrency = 3
+		r := createTestReaper(opts, pod1, pod2, pod3)
+
+		start := time.Now()
+		r.scytheCycle()
+
+		assert.True(t, time.Since(start) >= 100*time.Millisecond)
+		result, _ := r.clientSet.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+		assert.Equal(t, 0, len(result.Items))
+	})
+}
+
+func TestScytheCycleStoppedWhilePacing(t *testing.T) {
+	startTime := time.Now()
+	opts := minimalOptions("1.0")
+	opts.reapInterval = time.Hour
+	r := createTestReaper(opts, createTestPod("pod-1", "default", &startTime), createTestPod("pod-2", "default", &startTime))
+	cycles, stopCycles := context.WithCancel(context.Background())
+	stopCycles()
+	r.ctx = cycles
+
+	start := time.Now()
+	assert.Error(t, r.scytheCycle())
+
+	assert.True(t, time.Since(start) < time.Minute)
+	result, _ := r.clientSet.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+	assert.Equal(t, 1, len(result.Items))
+}
+
+func TestPaced(t *testing.T) {
+	opts := minimalOptions("1.0")
+	opts.reapInterval = time.Second
+	opts.maxPods = 2
+	r := reaper{options: opts}
+	assert.False(t, r.paced(0))
+	assert.True(t, r.paced(1))
+	assert.False(t, r.paced(2))
+
+	r.options.dryRun = true
+	assert.False(t, r.paced(1))
+
+	r.options.dryRun, r.options.reapInterval = false, 0
+	assert.False(t, r.paced(1))
+}
+
+// === harvest Tests ===
+
+func TestHarvest(t *testing.T) {
+	t.Run("runs for duration", func(t *testing.T) {
+		opts := minimalOptions("0.0")
+		opts.schedule = "@every 10ms"
+		opts.runDuration = 50 * time.Millisecond
+		r := createTestReaper(opts)
+
+		start := time.Now()
+		r.harvest(context.Background())
+		elapsed := time.Since(start)
+
+		assert.True(t, elapsed >= 50*time.Millisecond, "should run at least 50ms")
+		assert.True(t, elapsed < 200*time.Millisecond, "should not run too long")
+	})
+
+	t.Run("invalid schedule panics", func(t *testing.T) {
+		opts := minimalOptions("0.0")
+		opts.schedule = "invalid-cron-expression"
+		opts.runDuration = 50 * time.Millisecond
+		r := createTestReaper(opts)
+
+		assert.Panics(t, func() {
+			r.harvest(context.Background())
+		})
+	})
+}
+
+func TestRunOnce(t *testing.T) {
+	t.Run("reaps", func(t *testing.T) {
+		r := createTestReaper(minimalOptions("1.0"), createTestPod("pod-1", "default", nil))
+		assert.NoError(t, r.runOnce())
+		pods, _ := r.clientSet.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+		assert.Empty(t, pods.Items)
+	})
+	t.Run("without leadership", func(t *testing.T) {
+		r := createTestReaper(minimalOptions("1.0"), createTestPod("pod-1", "default", nil))
+		r.leader = &leader{}
+		assert.NoError(t, r.runOnce())
+	})
+	t.Run("cycle errors", func(t *testing.T) {
+		r := createTestReaper(minimalOptions("1.0"), createTestPod("pod-1", "default", nil))
+		r.clientSet.(*fake.Clientset).PrependReactor("delete", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, errors.New("connection refused")
+		})
+		assert.EqualError(t, r.runOnce(), "delete default/pod-1: connection refused")
+	})
+	t.Run("failed cycle", func(t *testing.T) {
+		r := createTestReaper(minimalOptions("1.0"))
+		r.clientSet.(*fake.Clientset).PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, errors.New("api unreachable")
+		})
+		assert.EqualError(t, r.runOnce(), "list in default: api unreachable")
+	})
+	t.Run("reaper policies", func(t *testing.T) {
+		r := createTestReaper(minimalOptions("1.0"))
+		r.policies = &policyController{}
+		assert.Error(t, r.runOnce())
+	})
+}
+
+func TestRun(t *testing.T) {
+	t.Run("run once", func(t *testing.T) {
+		opts := minimalOptions("1.0")
+		opts.runOnce = true
+		r := createTestReaper(opts, createTestPod("pod-1", "default", nil))
+		assert.NoError(t, (&Reaper{reaper: r}).Run(context.Background()))
+		pods, _ := r.clientSet.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+		assert.Empty(t, pods.Items)
+	})
+	t.Run("until cancelled", func(t *testing.T) {
+		opts := minimalOptions("1.0")
+		opts.schedule = "@every 1h"
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		assert.NoError(t, (&Reaper{reaper: createTestReaper(opts)}).Run(ctx))
+	})
+}
+
+func TestDecisionFields(t *testing.T) {
+	opts := minimalOptions("1.0")
+	opts.dryRun = true
+	r := createTestReaper(opts)
+	r.cycleID = "cycle"
+	fields := r.decisionFields(createTestPod("pod", "default", nil), []string{"reason one", "reason two"})
+	assert.Equal(t, "pod", fields["pod"])
+	assert.Equal(t, "default", fields["namespace"])
+	assert.Equal(t, "reason one, reason two", fields["reason"])
+	assert.Equal(t, []string{"reason one", "reason two"}, fields["reasons"])
+	assert.Equal(t, true, fields["dry_run"])
+	assert.Equal(t, "cycle", fields["cycleId"])
+}
+
+func TestScytheCycleLogsDecisions(t *testing.T) {
+	hook := logtest.NewGlobal()
+	defer hook.Reset()
+	logrus.SetLevel(logrus.DebugLevel)
+	defer logrus.SetLevel(logrus.InfoLevel)
+
+	opts := minimalOptions("1.0")
+	opts.dryRun = true
+	r := createTestReaper(opts, createTestPod("pod", "default", nil))
+	r.scytheCycle()
+
+	var evaluated *logrus.Entry
+	for _, entry := range hook.AllEntries() {
+		if entry.Message == "pod evaluated" {
+			evaluated = entry
+		}
+	}
+	if assert.NotNil(t, evaluated) {
+		assert.Equal(t, "pod", evaluated.Data["pod"])
+		assert.Equal(t, "default", evaluated.Data["namespace"])
+		assert.Equal(t, []string{"chaos"}, evaluated.Data["rule"])
+		assert.Equal(t, true, evaluated.Data["reap"])
+		assert.Equal(t, true, evaluated.Data["dry_run"])
+	}
+}
+
+// pagedPods serves the pods a page of listOptions.Limit pods at a time, with the index of the next pod as continue
+// token, counting the pages listed.
+func pagedPods(pods []v1.Pod, pages *int) k8stesting.ReactionFunc {
+	return func(action k8stesting.Action) (bool, runtime.Object, error) {
+		*pages++
+		restrictions := action.(k8stesting.ListActionImpl).ListOptions
+		start := 0
+		if restrictions.Continue != "" {
+			start, _ = strconv.Atoi(restrictions.Continue)
+		}
+		end := len(pods)
+		if restrictions.Limit > 0 && start+int(restrictions.Limit) < end {
+			end = start + int(restrictions.Limit)
+		}
+		list := &v1.PodList{Items: pods[start:end]}
+		if end < len(pods) {
+			list.Continue = strconv.Itoa(end)
+		}
+		return true, list, nil
+	}
+}
+
+func TestCycleMaxPods(t *testing.T) {
+	for _, test := range []struct {
+		name      string
+		maxPods   int
+		percent   float64
+		of        string
+		evaluated int
+		matched   int
+		expected  int
+	}{
+		{"no percentage", 5, 0, "", 100, 10, 5},
+		{"listed", 0, 10, maxPodsPercentOfListed, 100, 10, 10},
+		{"matched", 0, 10, maxPodsPercentOfMatched, 100, 10, 1},
+		{"rounded up", 0, 10, maxPodsPercentOfListed, 11, 11, 2},
+		{"whole number", 0, 1.1, maxPodsPercentOfListed, 1000, 0, 11},
+		{"lower max pods", 3, 10, maxPodsPercentOfListed, 100, 10, 3},
+		{"lower percentage", 30, 10, maxPodsPercentOfListed, 100, 10, 10},
+		{"no pods", 0, 10, maxPodsPercentOfMatched, 100, 0, 0},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			r := reaper{options: Options{maxPods: test.maxPods, maxPodsPercent: test.percent, maxPodsPercentOf: test.of}}
+			assert.Equal(t, test.expected, r.cycleMaxPods(test.evaluated, test.matched))
+		})
+	}
+}
+
+func TestListPods(t *testing.T) {
+	var pods []v1.Pod
+	for i := 0; i < 5; i++ {
+		pods = append(pods, createTestPod("pod-"+strconv.Itoa(i), "default", nil))
+	}
+	t.Run("pages", func(t *testing.T) {
+		opts := minimalOptions("0.0")
+		opts.listPageSize = 2
+		r := createTestReaper(opts)
+		pages := 0
+		r.clientSet.(*fake.Clientset).PrependReactor("list", "pods", pagedPods(pods, &pages))
+
+		listed, err := r.getPods()
+		assert.NoError(t, err)
+
+		assert.Len(t, listed.Items, 5)
+		assert.Equal(t, 3, pages)
+	})
+	t.Run("budget exhausted part way", func(t *testing.T) {
+		opts := minimalOptions("0.0")
+		opts.listPageSize = 2
+		r := createTestReaper(opts)
+		r.budget = newAPIBudget(2)
+		pages := 0
+		r.clientSet.(*fake.Clientset).PrependReactor("list", "pods", pagedPods(pods, &pages))
+
+		listed, err := r.listPods(r.clientSet.CoreV1().Pods("default"), metav1.ListOptions{Limit: 2})
+
+		assert.Equal(t, errAPIBudgetExhausted, err)
+		assert.Len(t, listed, 4)
+	})
+	t.Run("expired continue token", func(t *testing.T) {
+		r := createTestReaper(minimalOptions("0.0"))
+		pages := 0
+		serve := pagedPods(pods, &pages)
+		expired := false
+		r.clientSet.(*fake.Clientset).PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			if action.(k8stesting.ListActionImpl).ListOptions.Continue != "" && !expired {
+				expired = true
+				return true, nil, apierrors.NewResourceExpired("continue token expired")
+			}
+			return serve(action)
+		})
+
+		listed, err := r.listPods(r.clientSet.CoreV1().Pods("default"), metav1.ListOptions{Limit: 3})
+
+		assert.NoError(t, err)
+		assert.Len(t, listed, 5)
+	})
+	t.Run("cached consistency", func(t *testing.T) {
+		opts := minimalOptions("0.0")
+		opts.listPageSize = 2
+		opts.listConsistency = listConsistencyCached
+		r := createTestReaper(opts)
+		pages := 0
+		serve := pagedPods(pods, &pages)
+		var requested []metav1.ListOptions
+		r.clientSet.(*fake.Clientset).PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			requested = append(requested, action.(k8stesting.ListActionImpl).ListOptions)
+			return serve(action)
+		})
+
+		listed, err := r.getPods()
+		assert.NoError(t, err)
+
+		assert.Len(t, listed.Items, 5)
+		if assert.Len(t, requested, 3) {
+			assert.Equal(t, "0", requested[0].ResourceVersion)
+			assert.Equal(t, metav1.ResourceVersionMatchNotOlderThan, requested[0].ResourceVersionMatch)
+			for _, page := range requested[1:] {
+				assert.Empty(t, page.ResourceVersion)
+				assert.Empty(t, page.ResourceVersionMatch)
+				assert.NotEmpty(t, page.Continue)
+			}
+		}
+	})
+	t.Run("strong consistency", func(t *testing.T) {
+		opts := minimalOptions("0.0")
+		opts.listConsistency = listConsistencyStrong
+		r := createTestReaper(opts)
+		var requested metav1.ListOptions
+		r.clientSet.(*fake.Clientset).PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			requested = action.(k8stesting.ListActionImpl).ListOptions
+			return false, nil, nil
+		})
+
+		_, err := r.getPods()
+		assert.NoError(t, err)
+
+		assert.Empty(t, requested.ResourceVersion)
+		assert.Empty(t, requested.ResourceVersionMatch)
+	})
+	t.Run("label selectors are sent to the api server", func(t *testing.T) {
+		opts := minimalOptions("0.0")
+		opts.labelExclusion, _ = labels.NewRequirement("app", selection.NotIn, []string{"critical"})
+		r := createTestReaper(opts)
+		var selector string
+		r.clientSet.(*fake.Clientset).PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			selector = action.(k8stesting.ListActionImpl).ListOptions.LabelSelector
+			return false, nil, nil
+		})
+
+		_, err := r.getPods()
+		assert.NoError(t, err)
+
+		assert.Equal(t, "app notin (critical)", selector)
+	})
+	t.Run("field selector is sent to the api server", func(t *testing.T) {
+		opts := minimalOptions("0.0")
+		opts.fieldSelector = fields.ParseSelectorOrDie("status.phase!=Running")
+		r := createTestReaper(opts)
+		var selector string
+		r.clientSet.(*fake.Clientset).PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			selector = action.(k8stesting.ListActionImpl).ListOptions.FieldSelector
+			return false, nil, nil
+		})
+
+		_, err := r.getPods()
+		assert.NoError(t, err)
+
+		assert.Equal(t, "status.phase!=Running", selector)
+	})
+}
+
+func TestParseSchedule(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	assert.NoError(t, err)
+	// 08:30 in Chicago during daylight saving time
+	now := time.Date(2024, 7, 1, 13, 30, 0, 0, time.UTC)
+
+	t.Run("local time", func(t *testing.T) {
+		schedule, err := parseSchedule("0 9 * * *", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 9, schedule.Next(now.In(time.Local)).Hour())
+	})
+	t.Run("timezone", func(t *testing.T) {
+		schedule, err := parseSchedule("0 9 * * *", chicago)
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2024, 7, 1, 14, 0, 0, 0, time.UTC), schedule.Next(now).UTC())
+	})
+	t.Run("schedule timezone takes precedence", func(t *testing.T) {
+		schedule, err := parseSchedule("CRON_TZ=UTC 0 9 * * *", chicago)
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2024, 7, 2, 9, 0, 0, 0, time.UTC), schedule.Next(now).UTC())
+	})
+	t.Run("interval", func(t *testing.T) {
+		schedule, err := parseSchedule("@every 1m", chicago)
+		assert.NoError(t, err)
+		assert.Equal(t, now.Add(time.Minute), schedule.Next(now))
+	})
+	t.Run("invalid", func(t *testing.T) {
+		_, err := parseSchedule("every minute", chicago)
+		assert.Error(t, err)
+	})
+}
+
+func TestWaitJitter(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		assert.True(t, reaper{}.waitJitter())
+	})
+	t.Run("bounded", func(t *testing.T) {
+		r := reaper{options: Options{scheduleJitter: 20 * time.Millisecond}}
+		start := time.Now()
+		assert.True(t, r.waitJitter())
+		assert.Less(t, time.Since(start), time.Second)
+	})
+	t.Run("stopped", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		r := reaper{ctx: ctx, options: Options{scheduleJitter: time.Hour}}
+		assert.False(t, r.waitJitter())
+	})
+}
@@ -0,0 +1,141 @@
+package reaper
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/sirupsen/logrus"
+	"github.com/target/pod-reaper/rules"
+)
+
+const envReapers = "REAPERS"
+
+// reaperNamePattern matches the names of REAPERS, which must be usable in environment variable names
+var reaperNamePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// reaperEnvPrefix returns the prefix of the environment variables configuring the reaper of REAPERS with the name,
+// for example REAPER_TEAM_A_ for the reaper team-a.
+func reaperEnvPrefix(name string) string {
+	return "REAPER_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+}
+
+// reapers loads the reapers of REAPERS. Each reaper runs its own cycles, like a ReaperPolicy configured by the
+// environment variables prefixed with its name instead of a custom resource.
+func reapers(location *time.Location) ([]reaperPolicy, error) {
+	value, exists := os.LookupEnv(envReapers)
+	if !exists {
+		return nil, nil
+	}
+	var loaded []reaperPolicy
+	seen := map[string]bool{}
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !reaperNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid %s: %q must consist of lower case letters, digits, and dashes", envReapers, name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("invalid %s: %q is listed more than once", envReapers, name)
+		}
+		seen[name] = true
+		reaper, err := loadReaper(name, reaperConfig(reaperEnvPrefix(name)), location)
+		if err != nil {
+			return nil, err
+		}
+		loaded = append(loaded, reaper)
+	}
+	if len(loaded) == 0 {
+		return nil, fmt.Errorf("%s must contain at least one reaper", envReapers)
+	}
+	return loaded, nil
+}
+
+// reaperConfig returns the environment variables with the prefix, keyed by their name without it.
+func reaperConfig(prefix string) map[string]string {
+	config := map[string]string{}
+	for _, variable := range os.Environ() {
+		key, value, _ := strings.Cut(variable, "=")
+		if strings.HasPrefix(key, prefix) {
+			config[strings.TrimPrefix(key, prefix)] = value
+		}
+	}
+	return config
+}
+
+// loadReaper loads a reaper from its configuration: its schedule, namespaces, pod limits, and rules, keyed by the
+// environment variable names of the global options.
+func loadReaper(name string, config map[string]string, location *time.Location) (reaperPolicy, error) {
+	prefix := reaperEnvPrefix(name)
+	policy := reaperPolicy{name: name}
+	spec, exists := config[envScheduleCron]
+	if !exists {
+		spec = "@every 1m"
+	}
+	var err error
+	if policy.schedule, err = parseSchedule(spec, location); err != nil {
+		return policy, fmt.Errorf("invalid %s%s: %s", prefix, envScheduleCron, err)
+	}
+	namespace, namespaceExists := config[envNamespace]
+	value, namespacesExist := config[envNamespaces]
+	if namespaceExists && namespacesExist {
+		return policy, fmt.Errorf("specify only one of %s%s and %s%s", prefix, envNamespace, prefix, envNamespaces)
+	} else if namespaceExists {
+		policy.namespaces = []string{namespace}
+	} else if namespacesExist {
+		if policy.namespaces = splitNamespaces(value); len(policy.namespaces) == 0 {
+			return policy, fmt.Errorf("%s%s must contain at least one namespace", prefix, envNamespaces)
+		}
+	}
+	for key, limit := range map[string]*int{envMaxPods: &policy.maxPods, envMaxPodsPerNamespace: &policy.maxPodsPerNamespace} {
+		value, exists := config[key]
+		if !exists {
+			continue
+		}
+		if *limit, err = strconv.Atoi(value); err != nil {
+			return policy, fmt.Errorf("invalid %s%s: %s", prefix, key, err)
+		} else if *limit < 0 {
+			return policy, fmt.Errorf("invalid %s%s: must not be negative", prefix, key)
+		}
+	}
+	if policy.rules, err = rules.LoadRulesFromMap(config); err != nil {
+		return policy, fmt.Errorf("invalid rules of reaper %s: %s", name, err)
+	}
+	return policy, nil
+}
+
+// scheduleReapers schedules the cycles of every reaper of REAPERS.
+func (reaper reaper) scheduleReapers(schedule *cron.Cron) {
+	for _, policy := range reaper.options.reapers {
+		// each reaper gets its own budget and cycle lock since their cycles may run concurrently
+		policyReaper := reaper
+		policyReaper.budget = newAPIBudget(reaper.options.apiCallBudget)
+		policyReaper.cycles = newCycleLock()
+		schedule.Schedule(policy.schedule, cron.FuncJob(func() {
+			policyReaper.health.cycleStarted(time.Now())
+			policyReaper.runPolicyCycle(policy)
+		}))
+		logrus.WithField("policy", policy.name).Info("scheduled reaper")
+	}
+}
+
+// reaperSchedules combines the schedules of REAPERS into one, due whenever one of them is, to tell when the next
+// cycle is overdue.
+type reaperSchedules []cron.Schedule
+
+func (schedules reaperSchedules) Next(t time.Time) time.Time {
+	var next time.Time
+	for _, schedule := range schedules {
+		// a schedule that is never due again returns the zero time
+		if due := schedule.Next(t); !due.IsZero() && (next.IsZero() || due.Before(next)) {
+			next = due
+		}
+	}
+	return next
+}
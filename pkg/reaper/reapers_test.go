@@ -0,0 +1,117 @@
+package reaper
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReapers(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		os.Clearenv()
+		loaded, err := reapers(nil)
+		assert.NoError(t, err)
+		assert.Nil(t, loaded)
+	})
+	t.Run("valid", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envReapers, "nightly, team-a")
+		os.Setenv("REAPER_NIGHTLY_SCHEDULE", "0 2 * * *")
+		os.Setenv("REAPER_NIGHTLY_MAX_DURATION", "7d")
+		os.Setenv("REAPER_NIGHTLY_MAX_PODS", "10")
+		os.Setenv("REAPER_TEAM_A_NAMESPACES", "team-a, team-a-staging")
+		os.Setenv("REAPER_TEAM_A_CHAOS_CHANCE", "0.01")
+		os.Setenv("REAPER_TEAM_A_MAX_PODS_PER_NAMESPACE", "2")
+		// global rules are not used by reapers
+		os.Setenv("MAX_UNREADY", "10m")
+		loaded, err := reapers(time.UTC)
+		assert.NoError(t, err)
+		if assert.Len(t, loaded, 2) {
+			now := time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC)
+			assert.Equal(t, "nightly", loaded[0].name)
+			assert.Equal(t, time.Date(2024, 3, 5, 2, 0, 0, 0, time.UTC), loaded[0].schedule.Next(now))
+			assert.Nil(t, loaded[0].namespaces)
+			assert.Equal(t, 10, loaded[0].maxPods)
+			assert.Equal(t, []string{"duration"}, loaded[0].rules.Names())
+
+			assert.Equal(t, "team-a", loaded[1].name)
+			assert.Equal(t, now.Add(time.Minute), loaded[1].schedule.Next(now))
+			assert.Equal(t, []string{"team-a", "team-a-staging"}, loaded[1].namespaces)
+			assert.Equal(t, 2, loaded[1].maxPodsPerNamespace)
+			assert.Equal(t, []string{"chaos"}, loaded[1].rules.Names())
+		}
+	})
+	t.Run("single namespace", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envReapers, "nightly")
+		os.Setenv("REAPER_NIGHTLY_NAMESPACE", "batch")
+		os.Setenv("REAPER_NIGHTLY_MAX_DURATION", "7d")
+		loaded, err := reapers(nil)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"batch"}, loaded[0].namespaces)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		for name, env := range map[string]map[string]string{
+			"empty":              {envReapers: " , "},
+			"invalid name":       {envReapers: "Team_A"},
+			"duplicate name":     {envReapers: "a,a", "REAPER_A_MAX_DURATION": "1h"},
+			"no rules":           {envReapers: "a"},
+			"invalid rule":       {envReapers: "a", "REAPER_A_MAX_DURATION": "forever"},
+			"invalid schedule":   {envReapers: "a", "REAPER_A_MAX_DURATION": "1h", "REAPER_A_SCHEDULE": "nightly"},
+			"both namespaces":    {envReapers: "a", "REAPER_A_MAX_DURATION": "1h", "REAPER_A_NAMESPACE": "a", "REAPER_A_NAMESPACES": "a,b"},
+			"no namespaces":      {envReapers: "a", "REAPER_A_MAX_DURATION": "1h", "REAPER_A_NAMESPACES": ","},
+			"invalid max pods":   {envReapers: "a", "REAPER_A_MAX_DURATION": "1h", "REAPER_A_MAX_PODS": "ten"},
+			"negative max pods":  {envReapers: "a", "REAPER_A_MAX_DURATION": "1h", "REAPER_A_MAX_PODS_PER_NAMESPACE": "-1"},
+			"other reaper rules": {envReapers: "a", "REAPER_B_MAX_DURATION": "1h"},
+		} {
+			os.Clearenv()
+			for key, value := range env {
+				os.Setenv(key, value)
+			}
+			_, err := reapers(nil)
+			assert.Error(t, err, name)
+		}
+	})
+}
+
+func TestReaperOptions(t *testing.T) {
+	t.Run("failsafe", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envReapers, "everything,scoped")
+		os.Setenv("REAPER_EVERYTHING_CHAOS_CHANCE", "1")
+		os.Setenv("REAPER_SCOPED_CHAOS_CHANCE", "1")
+		os.Setenv("REAPER_SCOPED_NAMESPACE", "sandbox")
+		_, err := LoadOptions()
+		assert.EqualError(t, err, "reaper everything: refusing to reap nearly every pod in every namespace (chaos chance 1 flags every pod): set a namespace, label, annotation, owner, or node filter, or set I_UNDERSTAND_THE_RISK=true")
+	})
+	t.Run("with reaper policies", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envReapers, "nightly")
+		os.Setenv("REAPER_NIGHTLY_MAX_DURATION", "7d")
+		os.Setenv(envReaperPolicies, "true")
+		_, err := LoadOptions()
+		assert.Error(t, err)
+	})
+	t.Run("global rules are not loaded", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envReapers, "nightly")
+		os.Setenv("REAPER_NIGHTLY_MAX_DURATION", "7d")
+		options, err := LoadOptions()
+		assert.NoError(t, err)
+		assert.Len(t, options.reapers, 1)
+		assert.Empty(t, options.rules.LoadedRules)
+	})
+}
+
+func TestReaperSchedules(t *testing.T) {
+	hourly, err := parseSchedule("0 * * * *", time.UTC)
+	assert.NoError(t, err)
+	daily, err := parseSchedule("0 2 * * *", time.UTC)
+	assert.NoError(t, err)
+	now := time.Date(2024, 3, 4, 1, 30, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2024, 3, 4, 2, 0, 0, 0, time.UTC), reaperSchedules{daily, hourly}.Next(now))
+	assert.Equal(t, time.Date(2024, 3, 4, 2, 0, 0, 0, time.UTC), reaperSchedules{daily}.Next(now))
+	assert.True(t, reaperSchedules{}.Next(now).IsZero())
+}
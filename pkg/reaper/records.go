@@ -0,0 +1,43 @@
+package reaper
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+)
+
+// reapRecordsStdout is the REAP_RECORDS value that writes records to standard out rather than a file.
+const reapRecordsStdout = "stdout"
+
+var _ notifier = (*recordNotifier)(nil)
+
+// recordNotifier writes each reap notification as a line of JSON (NDJSON), giving log shippers a machine readable
+// stream that is separate from the human readable log on standard error.
+type recordNotifier struct {
+	mutex  sync.Mutex
+	writer io.Writer
+}
+
+// openRecordNotifier opens the destination for appending. The destination may be a regular file or a named pipe,
+// in which case opening blocks until the pipe has a reader.
+func openRecordNotifier(destination string) (*recordNotifier, error) {
+	if destination == reapRecordsStdout {
+		return &recordNotifier{writer: os.Stdout}, nil
+	}
+	file, err := os.OpenFile(destination, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &recordNotifier{writer: file}, nil
+}
+
+func (records *recordNotifier) name() string {
+	return "records"
+}
+
+func (records *recordNotifier) notify(notification reapNotification) error {
+	records.mutex.Lock()
+	defer records.mutex.Unlock()
+	return json.NewEncoder(records.writer).Encode(notification)
+}
@@ -0,0 +1,68 @@
+package reaper
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordNotifier(t *testing.T) {
+	t.Run("writes one line per record", func(t *testing.T) {
+		var buffer bytes.Buffer
+		records := &recordNotifier{writer: &buffer}
+		notification := reapNotification{Pod: "test-pod", Namespace: "default", Reasons: []string{"reason"}, Action: actionEvict}
+
+		assert.NoError(t, records.notify(notification))
+		assert.NoError(t, records.notify(notification))
+
+		scanner := bufio.NewScanner(&buffer)
+		lines := 0
+		for scanner.Scan() {
+			var decoded reapNotification
+			assert.NoError(t, json.Unmarshal(scanner.Bytes(), &decoded))
+			assert.Equal(t, notification, decoded)
+			lines++
+		}
+		assert.Equal(t, 2, lines)
+	})
+
+	t.Run("stdout", func(t *testing.T) {
+		records, err := openRecordNotifier(reapRecordsStdout)
+		assert.NoError(t, err)
+		assert.Equal(t, os.Stdout, records.writer)
+	})
+
+	t.Run("file", func(t *testing.T) {
+		destination := filepath.Join(t.TempDir(), "records.ndjson")
+		records, err := openRecordNotifier(destination)
+		assert.NoError(t, err)
+		assert.NoError(t, records.notify(reapNotification{Pod: "test-pod"}))
+
+		contents, err := os.ReadFile(destination)
+		assert.NoError(t, err)
+		assert.Contains(t, string(contents), `"pod":"test-pod"`)
+	})
+
+	t.Run("invalid destination", func(t *testing.T) {
+		_, err := openRecordNotifier(filepath.Join(t.TempDir(), "missing", "records.ndjson"))
+		assert.Error(t, err)
+	})
+}
+
+func TestScytheCycleRecords(t *testing.T) {
+	var buffer bytes.Buffer
+	startTime := time.Now()
+	opts := minimalOptions("1.0")
+	opts.notifiers = []notifier{&recordNotifier{writer: &buffer}}
+	r := createTestReaper(opts, createTestPod("pod-1", "default", &startTime), createTestPod("pod-2", "default", &startTime))
+
+	r.scytheCycle()
+
+	assert.Equal(t, 2, bytes.Count(buffer.Bytes(), []byte("\n")))
+}
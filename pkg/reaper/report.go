@@ -0,0 +1,152 @@
+package reaper
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// dryRunReportStdout is the DRY_RUN_REPORT value that writes reports to standard out rather than a file.
+const dryRunReportStdout = "stdout"
+
+// DRY_RUN_REPORT_FORMAT values
+const reportFormatJSON = "json"
+const reportFormatDiff = "diff"
+
+const actionDelete = "delete"
+const actionEvict = "evict"
+const actionAnnotate = "annotate"
+const actionScaleOwner = "scale-owner"
+const actionPreview = "preview"
+
+// reapReport is the structured summary of a single reap cycle.
+type reapReport struct {
+	Time    time.Time       `json:"time"`
+	CycleID string          `json:"cycleId,omitempty"`
+	Pods    []reapReportPod `json:"pods"`
+}
+
+// reapReportPod describes a single pod selected for reaping.
+type reapReportPod struct {
+	Name      string     `json:"name"`
+	Namespace string     `json:"namespace"`
+	Reasons   []string   `json:"reasons"`
+	Action    string     `json:"action"`
+	Owner     string     `json:"owner,omitempty"`
+	StartTime *time.Time `json:"startTime,omitempty"`
+}
+
+func newReapReport() *reapReport {
+	return &reapReport{
+		Time: time.Now().UTC(),
+		Pods: []reapReportPod{},
+	}
+}
+
+func (report *reapReport) add(pod v1.Pod, reasons []string, action string) {
+	reportPod := reapReportPod{
+		Name:      pod.Name,
+		Namespace: pod.Namespace,
+		Reasons:   reasons,
+		Action:    action,
+	}
+	if owner := metav1.GetControllerOf(&pod); owner != nil {
+		reportPod.Owner = owner.Kind + "/" + owner.Name
+	}
+	if pod.Status.StartTime != nil {
+		startTime := pod.Status.StartTime.Time.UTC()
+		reportPod.StartTime = &startTime
+	}
+	report.Pods = append(report.Pods, reportPod)
+}
+
+// write encodes the report as a single line of JSON.
+func (report *reapReport) write(writer io.Writer) error {
+	return json.NewEncoder(writer).Encode(report)
+}
+
+// writeDiff renders the report like kubectl diff output, with one section per namespace in which every pod that
+// would be reaped is removed. The output is meant to be pasted into change review tickets.
+func (report *reapReport) writeDiff(writer io.Writer) error {
+	var namespaces []string
+	byNamespace := map[string][]reapReportPod{}
+	for _, pod := range report.Pods {
+		if _, seen := byNamespace[pod.Namespace]; !seen {
+			namespaces = append(namespaces, pod.Namespace)
+		}
+		byNamespace[pod.Namespace] = append(byNamespace[pod.Namespace], pod)
+	}
+	sort.Strings(namespaces)
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "# pod-reaper dry run at %s", report.Time.Format(time.RFC3339))
+	if report.CycleID != "" {
+		fmt.Fprintf(&out, " (cycle %s)", report.CycleID)
+	}
+	fmt.Fprintf(&out, ": %d pods would be reaped\n", len(report.Pods))
+	for _, namespace := range namespaces {
+		var lines []string
+		for _, pod := range byNamespace[namespace] {
+			age := "unknown"
+			if pod.StartTime != nil {
+				age = report.Time.Sub(*pod.StartTime).Truncate(time.Second).String()
+			}
+			owner := pod.Owner
+			if owner == "" {
+				owner = "none"
+			}
+			lines = append(lines,
+				fmt.Sprintf("-pod/%s (%s)", pod.Name, pod.Action),
+				fmt.Sprintf("-  age: %s", age),
+				fmt.Sprintf("-  owner: %s", owner),
+				fmt.Sprintf("-  reasons: %s", strings.Join(pod.Reasons, "; ")))
+		}
+		fmt.Fprintf(&out, "diff -u -N live/%[1]s reaped/%[1]s\n", namespace)
+		fmt.Fprintf(&out, "--- live/%s\n", namespace)
+		fmt.Fprintf(&out, "+++ reaped/%s\n", namespace)
+		fmt.Fprintf(&out, "@@ -1,%d +0,0 @@\n", len(lines))
+		for _, line := range lines {
+			out.WriteString(line + "\n")
+		}
+	}
+	_, err := io.WriteString(writer, out.String())
+	return err
+}
+
+// writeFormatted writes the report in the format configured by DRY_RUN_REPORT_FORMAT.
+func (reaper reaper) writeFormatted(report *reapReport, writer io.Writer) error {
+	if reaper.options.dryRunReportFormat == reportFormatDiff {
+		return report.writeDiff(writer)
+	}
+	return report.write(writer)
+}
+
+// writeDryRunReport appends the report to the destination configured by DRY_RUN_REPORT. Failures are logged but
+// never interrupt the reaper.
+func (reaper reaper) writeDryRunReport(report *reapReport) {
+	destination := reaper.options.dryRunReport
+	var err error
+	if destination == dryRunReportStdout {
+		err = reaper.writeFormatted(report, os.Stdout)
+	} else {
+		var file *os.File
+		file, err = os.OpenFile(destination, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err == nil {
+			err = reaper.writeFormatted(report, file)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+		}
+	}
+	if err != nil {
+		logrus.WithField("destination", destination).WithError(err).Warn("unable to write dry-run report")
+	}
+}
@@ -0,0 +1,144 @@
+package reaper
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDryRunReport(t *testing.T) {
+	t.Run("add", func(t *testing.T) {
+		report := newReapReport()
+		report.add(createTestPod("deleted", "default", nil), []string{"reason"}, actionDelete)
+		report.add(createTestPod("evicted", "other", nil), []string{"reason one", "reason two"}, actionEvict)
+		if assert.Equal(t, 2, len(report.Pods)) {
+			assert.Equal(t, reapReportPod{Name: "deleted", Namespace: "default", Reasons: []string{"reason"}, Action: actionDelete}, report.Pods[0])
+			assert.Equal(t, actionEvict, report.Pods[1].Action)
+		}
+	})
+
+	t.Run("write", func(t *testing.T) {
+		report := newReapReport()
+		report.add(createTestPod("deleted", "default", nil), []string{"reason"}, actionDelete)
+		var buffer bytes.Buffer
+		assert.NoError(t, report.write(&buffer))
+
+		var decoded map[string]interface{}
+		assert.NoError(t, json.Unmarshal(buffer.Bytes(), &decoded))
+		assert.Contains(t, decoded, "time")
+		pods := decoded["pods"].([]interface{})
+		assert.Equal(t, map[string]interface{}{
+			"name":      "deleted",
+			"namespace": "default",
+			"reasons":   []interface{}{"reason"},
+			"action":    "delete",
+		}, pods[0])
+	})
+
+	t.Run("owner and start time", func(t *testing.T) {
+		startTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		pod := createTestPod("owned", "default", &startTime)
+		controller := true
+		pod.OwnerReferences = []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "web-abc", Controller: &controller}}
+		report := newReapReport()
+		report.add(pod, []string{"reason"}, actionDelete)
+		assert.Equal(t, "ReplicaSet/web-abc", report.Pods[0].Owner)
+		assert.Equal(t, startTime, *report.Pods[0].StartTime)
+	})
+
+	t.Run("write diff", func(t *testing.T) {
+		report := &reapReport{
+			Time:    time.Date(2024, 1, 2, 5, 0, 0, 0, time.UTC),
+			CycleID: "abc123",
+		}
+		startTime := time.Date(2024, 1, 2, 3, 0, 0, 0, time.UTC)
+		report.Pods = []reapReportPod{
+			{Name: "web-1", Namespace: "web", Reasons: []string{"was flagged for chaos", "has been running for 2h0m0s"}, Action: actionDelete, Owner: "ReplicaSet/web-abc", StartTime: &startTime},
+			{Name: "batch", Namespace: "default", Reasons: []string{"was flagged for chaos"}, Action: actionEvict},
+		}
+		var buffer bytes.Buffer
+		assert.NoError(t, report.writeDiff(&buffer))
+		assert.Equal(t, `# pod-reaper dry run at 2024-01-02T05:00:00Z (cycle abc123): 2 pods would be reaped
+diff -u -N live/default reaped/default
+--- live/default
++++ reaped/default
+@@ -1,4 +0,0 @@
+-pod/batch (evict)
+-  age: unknown
+-  owner: none
+-  reasons: was flagged for chaos
+diff -u -N live/web reaped/web
+--- live/web
++++ reaped/web
+@@ -1,4 +0,0 @@
+-pod/web-1 (delete)
+-  age: 2h0m0s
+-  owner: ReplicaSet/web-abc
+-  reasons: was flagged for chaos; has been running for 2h0m0s
+`, buffer.String())
+	})
+
+	t.Run("empty report has empty pod list", func(t *testing.T) {
+		var buffer bytes.Buffer
+		assert.NoError(t, newReapReport().write(&buffer))
+		assert.Contains(t, buffer.String(), `"pods":[]`)
+	})
+}
+
+func TestScytheCycleDryRunReport(t *testing.T) {
+	t.Run("appends one line per cycle to file", func(t *testing.T) {
+		startTime := time.Now()
+		destination := filepath.Join(t.TempDir(), "report.json")
+		opts := minimalOptions("1.0")
+		opts.dryRun = true
+		opts.dryRunReport = destination
+		r := createTestReaper(opts, createTestPod("pod-1", "default", &startTime), createTestPod("pod-2", "default", &startTime))
+
+		r.scytheCycle()
+		r.scytheCycle()
+
+		file, err := os.Open(destination)
+		assert.NoError(t, err)
+		defer file.Close()
+		lines := 0
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			var report reapReport
+			assert.NoError(t, json.Unmarshal(scanner.Bytes(), &report))
+			assert.Equal(t, 2, len(report.Pods))
+			lines++
+		}
+		assert.Equal(t, 2, lines)
+	})
+
+	t.Run("not written outside of dry run", func(t *testing.T) {
+		startTime := time.Now()
+		destination := filepath.Join(t.TempDir(), "report.json")
+		opts := minimalOptions("1.0")
+		opts.dryRunReport = destination
+		r := createTestReaper(opts, createTestPod("pod-1", "default", &startTime))
+
+		r.scytheCycle()
+
+		_, err := os.Stat(destination)
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("unwritable destination does not panic", func(t *testing.T) {
+		opts := minimalOptions("1.0")
+		opts.dryRun = true
+		opts.dryRunReport = filepath.Join(t.TempDir(), "missing", "report.json")
+		r := createTestReaper(opts)
+
+		assert.NotPanics(t, func() {
+			r.scytheCycle()
+		})
+	})
+}
@@ -0,0 +1,93 @@
+package reaper
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// the backoff between retries of an API operation grows up to this duration
+const maxRetryBackoff = 30 * time.Second
+
+// retryAPICall makes a kubernetes API call that was already accounted for with apiCall, retrying it while it fails
+// with a transient error, up to API_RETRIES times. Each retry waits an exponentially growing, jittered backoff and
+// counts against the cycle's API call budget. The error of the last attempt is returned.
+func (reaper reaper) retryAPICall(operation string, call func(ctx context.Context) error) error {
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := reaper.apiContext()
+		err := call(ctx)
+		cancel()
+		if err == nil || attempt >= reaper.options.apiRetries || !transient(err) {
+			return err
+		}
+		backoff := retryBackoff(reaper.options.apiRetryBackoff, attempt)
+		if seconds, delayed := apierrors.SuggestsClientDelay(err); delayed && time.Duration(seconds)*time.Second > backoff {
+			backoff = time.Duration(seconds) * time.Second
+		}
+		logrus.WithFields(logrus.Fields{
+			"operation": operation,
+			"attempt":   attempt + 1,
+			"backoff":   backoff.String(),
+		}).WithError(err).Debug("retrying api call")
+		if !reaper.sleep(backoff) || !reaper.apiCall(operation) {
+			return err
+		}
+		apiRetriesTotal.WithLabelValues(operation).Inc()
+	}
+}
+
+// retryBackoff returns the backoff before the retry following the attempt, doubling from the initial backoff with
+// each attempt and jittered between half and one and a half times that, so that replicas retry at different times.
+func retryBackoff(initial time.Duration, attempt int) time.Duration {
+	backoff := initial
+	for i := 0; i < attempt && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxRetryBackoff {
+		backoff = maxRetryBackoff
+	}
+	if backoff <= 0 {
+		return 0
+	}
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff)))
+}
+
+// sleep waits for the duration and returns true, or returns false early if the cycle is cancelled.
+func (reaper reaper) sleep(duration time.Duration) bool {
+	if reaper.ctx == nil {
+		time.Sleep(duration)
+		return true
+	}
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-reaper.ctx.Done():
+		return false
+	}
+}
+
+// transient returns whether an API call failed in a way that may succeed when retried: the API server was
+// overloaded, unavailable, or timed out, or the connection to it failed. Evictions refused by a pod disruption budget
+// are not transient, the pod is reaped on a later cycle instead.
+func transient(err error) bool {
+	if apierrors.HasStatusCause(err, policyv1.DisruptionBudgetCause) {
+		return false
+	}
+	if apierrors.IsTooManyRequests(err) || apierrors.IsServerTimeout(err) || apierrors.IsTimeout(err) ||
+		apierrors.IsServiceUnavailable(err) || apierrors.IsInternalError(err) || apierrors.IsUnexpectedServerError(err) {
+		return true
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
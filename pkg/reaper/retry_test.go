@@ -0,0 +1,147 @@
+package reaper
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// failingReactor fails the first calls with the error and then lets them through, counting every call.
+func failingReactor(failures int, err error, calls *int) k8stesting.ReactionFunc {
+	return func(action k8stesting.Action) (bool, runtime.Object, error) {
+		*calls++
+		if *calls <= failures {
+			return true, nil, err
+		}
+		return false, nil, nil
+	}
+}
+
+func TestTransient(t *testing.T) {
+	pods := schema.GroupResource{Resource: "pods"}
+	disruptionBudget := apierrors.NewTooManyRequests("would violate the disruption budget", 0)
+	disruptionBudget.ErrStatus.Details.Causes = []metav1.StatusCause{{Type: policyv1.DisruptionBudgetCause}}
+	for name, test := range map[string]struct {
+		err       error
+		transient bool
+	}{
+		"too many requests":   {apierrors.NewTooManyRequests("slow down", 1), true},
+		"service unavailable": {apierrors.NewServiceUnavailable("unavailable"), true},
+		"internal error":      {apierrors.NewInternalError(errors.New("etcd")), true},
+		"server timeout":      {apierrors.NewServerTimeout(pods, "list", 1), true},
+		"deadline exceeded":   {context.DeadlineExceeded, true},
+		"disruption budget":   {disruptionBudget, false},
+		"not found":           {apierrors.NewNotFound(pods, "pod-1"), false},
+		"forbidden":           {apierrors.NewForbidden(pods, "pod-1", errors.New("rbac")), false},
+		"cancelled":           {context.Canceled, false},
+	} {
+		assert.Equal(t, test.transient, transient(test.err), name)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	for attempt, expected := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+		backoff := retryBackoff(time.Second, attempt)
+		assert.GreaterOrEqual(t, backoff, expected/2)
+		assert.Less(t, backoff, expected*3/2)
+	}
+	assert.Less(t, retryBackoff(time.Second, 100), maxRetryBackoff*3/2)
+	assert.Equal(t, time.Duration(0), retryBackoff(0, 2))
+}
+
+func TestRetryAPICall(t *testing.T) {
+	unavailable := apierrors.NewServiceUnavailable("unavailable")
+	t.Run("list succeeds after transient errors", func(t *testing.T) {
+		opts := minimalOptions("0.0")
+		opts.apiRetries = 3
+		r := createTestReaper(opts, createTestPod("pod-1", "default", nil))
+		calls := 0
+		r.clientSet.(*fake.Clientset).PrependReactor("list", "pods", failingReactor(2, unavailable, &calls))
+
+		pods, err := r.getPods()
+		assert.NoError(t, err)
+
+		assert.Len(t, pods.Items, 1)
+		assert.Equal(t, 3, calls)
+	})
+	t.Run("delete fails the cycle once retries are exhausted", func(t *testing.T) {
+		opts := minimalOptions("1.0")
+		opts.apiRetries = 2
+		r := createTestReaper(opts, createTestPod("pod-1", "default", nil))
+		calls := 0
+		r.clientSet.(*fake.Clientset).PrependReactor("delete", "pods", failingReactor(10, unavailable, &calls))
+
+		err := r.scytheCycle()
+
+		assert.Equal(t, 3, calls)
+		assert.Equal(t, cycleErrors{{Operation: actionDelete, Namespace: "default", Pod: "pod-1", Message: unavailable.Error()}}, err)
+	})
+	t.Run("permanent errors are not retried", func(t *testing.T) {
+		opts := minimalOptions("1.0")
+		opts.apiRetries = 3
+		r := createTestReaper(opts, createTestPod("pod-1", "default", nil))
+		calls := 0
+		r.clientSet.(*fake.Clientset).PrependReactor("delete", "pods", failingReactor(10, errors.New("forbidden"), &calls))
+
+		assert.False(t, r.reapPod(createTestPod("pod-1", "default", nil), []string{"reason"}, 0))
+		assert.Equal(t, 1, calls)
+	})
+	t.Run("evictions are retried", func(t *testing.T) {
+		opts := minimalOptions("1.0")
+		opts.action = actionEvict
+		opts.apiRetries = 1
+		r := createTestReaper(opts, createTestPod("pod-1", "default", nil))
+		calls := 0
+		r.clientSet.(*fake.Clientset).PrependReactor("create", "pods", failingReactor(1, apierrors.NewTooManyRequests("slow down", 0), &calls))
+
+		assert.True(t, r.reapPod(createTestPod("pod-1", "default", nil), []string{"reason"}, 0))
+		assert.Equal(t, 2, calls)
+	})
+	t.Run("retries count against the budget", func(t *testing.T) {
+		opts := minimalOptions("1.0")
+		opts.apiRetries = 5
+		r := createTestReaper(opts, createTestPod("pod-1", "default", nil))
+		r.budget = newAPIBudget(2)
+		calls := 0
+		r.clientSet.(*fake.Clientset).PrependReactor("delete", "pods", failingReactor(10, unavailable, &calls))
+
+		assert.True(t, r.apiCall(operationDelete))
+		err := r.retryAPICall(operationDelete, func(ctx context.Context) error {
+			return r.clientSet.CoreV1().Pods("default").Delete(ctx, "pod-1", metav1.DeleteOptions{})
+		})
+
+		assert.Error(t, err)
+		assert.Equal(t, 2, calls)
+	})
+	t.Run("shutdown stops waiting", func(t *testing.T) {
+		opts := minimalOptions("1.0")
+		opts.apiRetries = 3
+		opts.apiRetryBackoff = time.Hour
+		r := createTestReaper(opts)
+		ctx, cancel := context.WithCancel(context.Background())
+		r.ctx = ctx
+		calls := 0
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			cancel()
+		}()
+
+		err := r.retryAPICall(operationList, func(context.Context) error {
+			calls++
+			return unavailable
+		})
+
+		assert.Equal(t, unavailable, err)
+		assert.Equal(t, 1, calls)
+	})
+}
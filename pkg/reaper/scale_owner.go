@@ -0,0 +1,109 @@
+package reaper
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var errOwnerAtMinimum = errors.New("owner has a single replica")
+
+// with ACTION=scale-owner, the pod is annotated so that its replica set removes it first when scaled down
+const annotationPodDeletionCost = "controller.kubernetes.io/pod-deletion-cost"
+const annotationOwnerScaledAt = "pod-reaper/owner-scaled-at"
+
+// the owner records when and why pod-reaper last scaled it down
+const annotationScaledDownAt = "pod-reaper/scaled-down-at"
+const annotationScaledDownReasons = "pod-reaper/scaled-down-reasons"
+
+// lowestDeletionCost makes the pod the first one its replica set deletes
+const lowestDeletionCost = "-2147483648"
+
+// scalableOwner returns whether the pod is controlled by a replica set, which pod-reaper can scale down in place of
+// deleting the pod.
+func scalableOwner(pod v1.Pod) bool {
+	owner := metav1.GetControllerOf(&pod)
+	return owner != nil && owner.Kind == "ReplicaSet" && strings.HasPrefix(owner.APIVersion, appsv1.GroupName+"/")
+}
+
+// ownerScaled returns whether pod-reaper already scaled down the owner of the pod, which removes the pod shortly
+// after.
+func ownerScaled(pod v1.Pod) bool {
+	_, scaled := pod.Annotations[annotationOwnerScaledAt]
+	return scaled
+}
+
+// scaleOwner scales down the deployment controlling the pod's replica set, or the replica set itself when no
+// deployment controls it, by one replica. The pod is first given the lowest deletion cost so that it is the pod the
+// replica set removes, rather than a healthy one, and the controller does not recreate it. The caller counts the first
+// get against the api call budget.
+func (reaper reaper) scaleOwner(pod v1.Pod, reasons []string, now time.Time) error {
+	ctx, cancel := reaper.apiContext()
+	defer cancel()
+	replicaSets := reaper.clientSet.AppsV1().ReplicaSets(pod.Namespace)
+	replicaSet, err := replicaSets.Get(ctx, metav1.GetControllerOf(&pod).Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	var deployment *appsv1.Deployment
+	replicas := replicaSet.Spec.Replicas
+	if owner := metav1.GetControllerOf(replicaSet); owner != nil && owner.Kind == "Deployment" {
+		// the deployment would scale the replica set straight back up
+		if !reaper.apiCall(operationGet) {
+			return errAPIBudgetExhausted
+		}
+		deployment, err = reaper.clientSet.AppsV1().Deployments(pod.Namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		replicas = deployment.Spec.Replicas
+	}
+	current := int32(1)
+	if replicas != nil {
+		current = *replicas
+	}
+	if current <= 1 {
+		return errOwnerAtMinimum
+	}
+
+	if err := reaper.patchAnnotations(pod, map[string]string{annotationPodDeletionCost: lowestDeletionCost}); err != nil {
+		return err
+	}
+	// updates fail on conflict rather than overwriting a concurrent change to the replica count
+	scaled := current - 1
+	annotations := map[string]string{
+		annotationScaledDownAt:      now.UTC().Format(time.RFC3339),
+		annotationScaledDownReasons: pod.Name + ": " + strings.Join(reasons, "; "),
+	}
+	if !reaper.apiCall(operationUpdate) {
+		return errAPIBudgetExhausted
+	}
+	if deployment != nil {
+		deployment.Spec.Replicas = &scaled
+		deployment.Annotations = mergeAnnotations(deployment.Annotations, annotations)
+		_, err = reaper.clientSet.AppsV1().Deployments(pod.Namespace).Update(ctx, deployment, metav1.UpdateOptions{})
+	} else {
+		replicaSet.Spec.Replicas = &scaled
+		replicaSet.Annotations = mergeAnnotations(replicaSet.Annotations, annotations)
+		_, err = replicaSets.Update(ctx, replicaSet, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return err
+	}
+	// the pod is only recorded as handled once its owner was scaled, so a failed update is retried next cycle
+	return reaper.patchAnnotations(pod, map[string]string{annotationOwnerScaledAt: now.UTC().Format(time.RFC3339)})
+}
+
+func mergeAnnotations(annotations map[string]string, added map[string]string) map[string]string {
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	for key, value := range added {
+		annotations[key] = value
+	}
+	return annotations
+}
@@ -0,0 +1,150 @@
+package reaper
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func testReplicaSetPod(name string, replicaSet string) v1.Pod {
+	pod := testOwnedPod(name, replicaSet)
+	pod.OwnerReferences[0].APIVersion = "apps/v1"
+	return pod
+}
+
+func testReplicaSet(name string, replicas int32, deployment string) *appsv1.ReplicaSet {
+	replicaSet := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       appsv1.ReplicaSetSpec{Replicas: &replicas},
+	}
+	if deployment != "" {
+		controller := true
+		replicaSet.OwnerReferences = []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "Deployment", Name: deployment, Controller: &controller}}
+	}
+	return replicaSet
+}
+
+func testDeployment(name string, replicas int32) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+	}
+}
+
+func testScaleOwnerReaper(pod v1.Pod, objects ...runtime.Object) reaper {
+	opts := minimalOptions("0.0")
+	opts.action = actionScaleOwner
+	return reaper{
+		clientSet: fake.NewSimpleClientset(append(objects, &pod)...),
+		options:   opts,
+	}
+}
+
+func TestScalableOwner(t *testing.T) {
+	assert.True(t, scalableOwner(testReplicaSetPod("pod", "owner")))
+	assert.False(t, scalableOwner(createTestPod("pod", "default", nil)))
+	statefulSetPod := testReplicaSetPod("pod", "owner")
+	statefulSetPod.OwnerReferences[0].Kind = "StatefulSet"
+	assert.False(t, scalableOwner(statefulSetPod))
+}
+
+func TestScaleOwner(t *testing.T) {
+	now := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+
+	t.Run("scales the deployment", func(t *testing.T) {
+		pod := testReplicaSetPod("pod", "web-abc")
+		r := testScaleOwnerReaper(pod, testReplicaSet("web-abc", 3, "web"), testDeployment("web", 3))
+
+		assert.NoError(t, r.scaleOwner(pod, []string{"reason one", "reason two"}, now))
+
+		deployment, err := r.clientSet.AppsV1().Deployments("default").Get(context.TODO(), "web", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, int32(2), *deployment.Spec.Replicas)
+		assert.Equal(t, "2026-03-04T05:06:07Z", deployment.Annotations[annotationScaledDownAt])
+		assert.Equal(t, "pod: reason one; reason two", deployment.Annotations[annotationScaledDownReasons])
+		replicaSet, err := r.clientSet.AppsV1().ReplicaSets("default").Get(context.TODO(), "web-abc", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, int32(3), *replicaSet.Spec.Replicas)
+		result, err := r.clientSet.CoreV1().Pods("default").Get(context.TODO(), "pod", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, lowestDeletionCost, result.Annotations[annotationPodDeletionCost])
+		assert.True(t, ownerScaled(*result))
+	})
+	t.Run("scales a replica set without a deployment", func(t *testing.T) {
+		pod := testReplicaSetPod("pod", "web-abc")
+		r := testScaleOwnerReaper(pod, testReplicaSet("web-abc", 2, ""))
+
+		assert.NoError(t, r.scaleOwner(pod, []string{"reason"}, now))
+
+		replicaSet, err := r.clientSet.AppsV1().ReplicaSets("default").Get(context.TODO(), "web-abc", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, int32(1), *replicaSet.Spec.Replicas)
+		assert.Equal(t, "pod: reason", replicaSet.Annotations[annotationScaledDownReasons])
+	})
+	t.Run("never scales to zero", func(t *testing.T) {
+		pod := testReplicaSetPod("pod", "web-abc")
+		r := testScaleOwnerReaper(pod, testReplicaSet("web-abc", 1, "web"), testDeployment("web", 1))
+
+		assert.Equal(t, errOwnerAtMinimum, r.scaleOwner(pod, []string{"reason"}, now))
+
+		deployment, err := r.clientSet.AppsV1().Deployments("default").Get(context.TODO(), "web", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, int32(1), *deployment.Spec.Replicas)
+		result, err := r.clientSet.CoreV1().Pods("default").Get(context.TODO(), "pod", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Empty(t, result.Annotations)
+	})
+	t.Run("failed update is retried", func(t *testing.T) {
+		pod := testReplicaSetPod("pod", "web-abc")
+		r := testScaleOwnerReaper(pod, testReplicaSet("web-abc", 3, "web"), testDeployment("web", 3))
+		r.clientSet.(*fake.Clientset).PrependReactor("update", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, errors.New("conflict")
+		})
+
+		assert.Error(t, r.scaleOwner(pod, []string{"reason"}, now))
+
+		result, err := r.clientSet.CoreV1().Pods("default").Get(context.TODO(), "pod", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.False(t, ownerScaled(*result))
+	})
+}
+
+func TestReapPodScaleOwner(t *testing.T) {
+	t.Run("scales the owner once", func(t *testing.T) {
+		pod := testReplicaSetPod("pod", "web-abc")
+		r := testScaleOwnerReaper(pod, testReplicaSet("web-abc", 3, "web"), testDeployment("web", 3))
+
+		assert.True(t, r.reapPod(pod, []string{"reason"}, 0))
+
+		result, err := r.clientSet.CoreV1().Pods("default").Get(context.TODO(), "pod", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.False(t, r.reapPod(*result, []string{"reason"}, 0))
+		deployment, err := r.clientSet.AppsV1().Deployments("default").Get(context.TODO(), "web", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, int32(2), *deployment.Spec.Replicas)
+	})
+	t.Run("pod without a replica set is skipped", func(t *testing.T) {
+		pod := createTestPod("pod", "default", nil)
+		r := testScaleOwnerReaper(pod)
+
+		assert.False(t, r.reapPod(pod, []string{"reason"}, 0))
+
+		_, err := r.clientSet.CoreV1().Pods("default").Get(context.TODO(), "pod", metav1.GetOptions{})
+		assert.NoError(t, err)
+	})
+	t.Run("owner with a single replica is skipped", func(t *testing.T) {
+		pod := testReplicaSetPod("pod", "web-abc")
+		r := testScaleOwnerReaper(pod, testReplicaSet("web-abc", 1, ""))
+
+		assert.False(t, r.reapPod(pod, []string{"reason"}, 0))
+	})
+}
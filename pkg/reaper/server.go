@@ -0,0 +1,122 @@
+package reaper
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sirupsen/logrus"
+)
+
+// serveHTTP serves the metrics, health, and admin endpoints on their configured addresses until the process exits.
+// Endpoints configured with the same address share a server.
+func (reaper reaper) serveHTTP() {
+	muxes := map[string]*http.ServeMux{}
+	mux := func(address string) *http.ServeMux {
+		if muxes[address] == nil {
+			muxes[address] = http.NewServeMux()
+		}
+		return muxes[address]
+	}
+	if reaper.options.metricsAddress != "" {
+		// exemplars are only exposed in the OpenMetrics format, which scrapers request through content negotiation
+		var metrics http.Handler = promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{
+			EnableOpenMetrics: true,
+		})
+		if reaper.options.metricsToken != "" {
+			metrics = requireToken(reaper.options.metricsToken, metrics.ServeHTTP)
+		}
+		mux(reaper.options.metricsAddress).Handle("/metrics", metrics)
+	}
+	if reaper.options.healthAddress != "" {
+		mux(reaper.options.healthAddress).HandleFunc("/healthz", reaper.health.handleLive)
+		mux(reaper.options.healthAddress).HandleFunc("/readyz", reaper.health.handleReady)
+	}
+	if reaper.options.adminAddress != "" {
+		// options refuse an admin address that is not authenticated by ADMIN_TOKEN or by client certificates
+		adminHandler := func(handler http.HandlerFunc) http.HandlerFunc {
+			if reaper.options.adminToken == "" {
+				return handler
+			}
+			return requireToken(reaper.options.adminToken, handler)
+		}
+		mux(reaper.options.adminAddress).HandleFunc("/admin/snooze", adminHandler(reaper.handleSnooze))
+		mux(reaper.options.adminAddress).HandleFunc("/admin/approve", adminHandler(reaper.handleApprove))
+		mux(reaper.options.adminAddress).HandleFunc("/admin/explain", adminHandler(reaper.handleExplain))
+		// the last cycle names pods across namespaces, so it is only served to authenticated clients
+		if reaper.lastCycle != nil {
+			mux(reaper.options.adminAddress).HandleFunc("/last-cycle", adminHandler(reaper.lastCycle.handle))
+		}
+		if reaper.events != nil {
+			mux(reaper.options.adminAddress).HandleFunc("/events", adminHandler(reaper.events.handle))
+		}
+		// reap requests delete pods outside of the rules, so they are only accepted from authenticated clients
+		if reaper.requests != nil {
+			mux(reaper.options.adminAddress).HandleFunc("/admin/reap", adminHandler(reaper.handleReapRequest))
+		}
+		if reaper.options.slackSigningSecret != "" {
+			commands := slackCommands{
+				reaper: reaper,
+				secret: reaper.options.slackSigningSecret,
+				client: &http.Client{Timeout: 5 * time.Second},
+			}
+			mux(reaper.options.adminAddress).HandleFunc("/slack/commands", commands.handleCommand)
+			mux(reaper.options.adminAddress).HandleFunc("/slack/actions", commands.handleAction)
+		}
+	}
+	for address, handler := range muxes {
+		// health endpoints never share an address with TLS endpoints, which options enforce
+		var tlsConfig *tls.Config
+		if address != reaper.options.healthAddress {
+			tlsConfig = reaper.options.tlsConfig
+		}
+		go serve(address, handler, tlsConfig)
+	}
+}
+
+func serve(address string, handler http.Handler, tlsConfig *tls.Config) {
+	server := &http.Server{Addr: address, Handler: handler, TLSConfig: tlsConfig}
+	var err error
+	if tlsConfig != nil {
+		logrus.WithField("address", address).Info("serving https")
+		// the certificate is already loaded into the tls config
+		err = server.ListenAndServeTLS("", "")
+	} else {
+		logrus.WithField("address", address).Info("serving http")
+		err = server.ListenAndServe()
+	}
+	if err != nil {
+		logrus.WithError(err).WithField("address", address).Error("http server stopped")
+	}
+}
+
+// loadServerTLS creates the tls config for serving with the certificate and key, requiring client certificates signed
+// by the client CA unless it is empty.
+func loadServerTLS(certFile string, keyFile string, clientCAFile string) (*tls.Config, error) {
+	certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load the tls certificate: %s", err)
+	}
+	config := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if clientCAFile == "" {
+		return config, nil
+	}
+	clientCA, err := ioutil.ReadFile(clientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load the tls client CA: %s", err)
+	}
+	config.ClientCAs = x509.NewCertPool()
+	if !config.ClientCAs.AppendCertsFromPEM(clientCA) {
+		return nil, fmt.Errorf("no certificates found in %s", clientCAFile)
+	}
+	config.ClientAuth = tls.RequireAndVerifyClientCert
+	return config, nil
+}
@@ -0,0 +1,97 @@
+package reaper
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testCertificate writes a self signed certificate, valid for localhost, and its key to the directory.
+func testCertificate(t *testing.T, dir string, name string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+	certFile := filepath.Join(dir, name+".crt")
+	keyFile := filepath.Join(dir, name+".key")
+	assert.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	assert.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	return certFile, keyFile
+}
+
+func TestLoadServerTLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := testCertificate(t, dir, "server")
+	clientCert, clientKey := testCertificate(t, dir, "client")
+
+	t.Run("tls", func(t *testing.T) {
+		config, err := loadServerTLS(certFile, keyFile, "")
+		assert.NoError(t, err)
+		assert.Len(t, config.Certificates, 1)
+		assert.Equal(t, tls.NoClientCert, config.ClientAuth)
+	})
+	t.Run("missing certificate", func(t *testing.T) {
+		_, err := loadServerTLS(filepath.Join(dir, "missing.crt"), keyFile, "")
+		assert.Error(t, err)
+	})
+	t.Run("invalid client CA", func(t *testing.T) {
+		_, err := loadServerTLS(certFile, keyFile, keyFile)
+		assert.Error(t, err)
+	})
+	t.Run("mutual tls", func(t *testing.T) {
+		config, err := loadServerTLS(certFile, keyFile, clientCert)
+		assert.NoError(t, err)
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		server.TLS = config
+		server.StartTLS()
+		defer server.Close()
+
+		serverCA, err := os.ReadFile(certFile)
+		assert.NoError(t, err)
+		roots := x509.NewCertPool()
+		roots.AppendCertsFromPEM(serverCA)
+
+		anonymous := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}}
+		_, err = anonymous.Get(server.URL)
+		assert.Error(t, err)
+
+		certificate, err := tls.LoadX509KeyPair(clientCert, clientKey)
+		assert.NoError(t, err)
+		authenticated := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			RootCAs:      roots,
+			Certificates: []tls.Certificate{certificate},
+		}}}
+		response, err := authenticated.Get(server.URL)
+		if assert.NoError(t, err) {
+			response.Body.Close()
+			assert.Equal(t, http.StatusOK, response.StatusCode)
+		}
+	})
+}
@@ -0,0 +1,34 @@
+package reaper
+
+import (
+	"context"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/sirupsen/logrus"
+)
+
+// shutdown stops scheduling reap cycles and waits up to SHUTDOWN_TIMEOUT for a running cycle to finish, so that pods
+// are not left half reaped. A cycle still running at the timeout is abandoned by cancelling its API calls. It then
+// delivers the notifications and records that are still pending and gives up leadership.
+func (reaper reaper) shutdown(schedule *cron.Cron, stopCycles context.CancelFunc, stopLeading context.CancelFunc, leaderStopped <-chan struct{}) {
+	ctx, cancel := context.WithTimeout(context.Background(), reaper.options.shutdownTimeout)
+	defer cancel()
+	select {
+	case <-schedule.Stop().Done():
+	case <-ctx.Done():
+		logrus.WithField("timeout", reaper.options.shutdownTimeout).Warn("reap cycle did not finish before the shutdown timeout")
+	}
+	stopCycles()
+	// pending records are delivered within what is left of the shutdown timeout
+	reaper.ctx = ctx
+	reaper.flushNotifiers()
+	reaper.flushAudit()
+	reaper.options.warehouse.close(time.Now())
+	// leadership is only released after the cycle, since a cycle ends early once it stops leading
+	stopLeading()
+	select {
+	case <-leaderStopped:
+	case <-ctx.Done():
+	}
+}
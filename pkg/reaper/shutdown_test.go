@@ -0,0 +1,126 @@
+package reaper
+
+import (
+	"context"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+// testScheduleOnce runs a job once, at the given time
+type testScheduleOnce struct {
+	at time.Time
+}
+
+func (schedule testScheduleOnce) Next(t time.Time) time.Time {
+	if t.Before(schedule.at) {
+		return schedule.at
+	}
+	return time.Time{}
+}
+
+// testRunningCycle starts a schedule whose only job runs until release is closed, returning once the job is running.
+func testRunningCycle(release <-chan struct{}, finished *int32) *cron.Cron {
+	schedule := cronWithOptionalSeconds()
+	started := make(chan struct{})
+	schedule.Schedule(testScheduleOnce{at: time.Now().Add(10 * time.Millisecond)}, cron.FuncJob(func() {
+		close(started)
+		<-release
+		atomic.StoreInt32(finished, 1)
+	}))
+	schedule.Start()
+	<-started
+	return schedule
+}
+
+func TestShutdown(t *testing.T) {
+	t.Run("waits for the running cycle", func(t *testing.T) {
+		opts := minimalOptions("1.0")
+		opts.shutdownTimeout = time.Minute
+		path := filepath.Join(t.TempDir(), "audit.jsonl")
+		opts.audit = &auditLog{sink: &fileAuditSink{path: path, maxSize: 1024 * 1024}, pending: []auditRecord{{Pod: "pod-1"}}}
+		r := createTestReaper(opts)
+		release := make(chan struct{})
+		var finished int32
+		schedule := testRunningCycle(release, &finished)
+		stoppedLeading := false
+		leaderStopped := make(chan struct{})
+		close(leaderStopped)
+
+		time.AfterFunc(50*time.Millisecond, func() { close(release) })
+		r.shutdown(schedule, func() {}, func() { stoppedLeading = true }, leaderStopped)
+
+		assert.Equal(t, int32(1), atomic.LoadInt32(&finished))
+		assert.True(t, stoppedLeading)
+		assert.Empty(t, opts.audit.pending)
+		assert.FileExists(t, path)
+	})
+	t.Run("timeout", func(t *testing.T) {
+		opts := minimalOptions("1.0")
+		opts.shutdownTimeout = 20 * time.Millisecond
+		r := createTestReaper(opts)
+		release := make(chan struct{})
+		defer close(release)
+		var finished int32
+		schedule := testRunningCycle(release, &finished)
+		// a leader that never stops does not hold up the shutdown either
+		leaderStopped := make(chan struct{})
+
+		start := time.Now()
+		r.shutdown(schedule, func() {}, func() {}, leaderStopped)
+
+		assert.Less(t, time.Since(start), 5*time.Second)
+		assert.Equal(t, int32(0), atomic.LoadInt32(&finished))
+	})
+	t.Run("no running cycle", func(t *testing.T) {
+		opts := minimalOptions("1.0")
+		opts.shutdownTimeout = time.Minute
+		r := createTestReaper(opts)
+		schedule := cronWithOptionalSeconds()
+		schedule.Start()
+		leaderStopped := make(chan struct{})
+		close(leaderStopped)
+		assert.NotPanics(t, func() { r.shutdown(schedule, func() {}, func() {}, leaderStopped) })
+	})
+}
+
+func TestHarvestRunDuration(t *testing.T) {
+	opts := minimalOptions("1.0")
+	opts.schedule = "@every 1h"
+	opts.runDuration = 20 * time.Millisecond
+	opts.shutdownTimeout = time.Second
+	r := createTestReaper(opts)
+	stopped := make(chan struct{})
+	go func() {
+		r.harvest(context.Background())
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("harvest did not stop after the run duration")
+	}
+}
+
+func TestHarvestContext(t *testing.T) {
+	opts := minimalOptions("1.0")
+	opts.schedule = "@every 1h"
+	opts.shutdownTimeout = time.Second
+	r := createTestReaper(opts)
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		r.harvest(ctx)
+		close(stopped)
+	}()
+	cancel()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("harvest did not stop after the context was cancelled")
+	}
+}
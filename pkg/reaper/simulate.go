@@ -0,0 +1,323 @@
+package reaper
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// simulateCommand is the first argument that runs a reap cycle against synthetic pods instead of reaping pods
+const simulateCommand = "simulate"
+
+// runningStatus is the container status of simulated pods whose containers are running; any other status is the
+// reason the containers are waiting
+const runningStatus = "Running"
+
+// simulatedPodsPerOwner is the number of simulated pods controlled by each replica set
+const simulatedPodsPerOwner = 10
+
+// weightedChoice picks values at random in proportion to their weights.
+type weightedChoice struct {
+	values  []string
+	weights []int
+	total   int
+}
+
+// parseWeightedChoice parses comma separated value=weight entries, for example "Running=90,Pending=10". parse
+// validates each value.
+func parseWeightedChoice(name string, value string, parse func(string) error) (weightedChoice, error) {
+	choice := weightedChoice{}
+	for _, entry := range strings.Split(value, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			return choice, fmt.Errorf("invalid -%s: %q is not of the form value=weight", name, entry)
+		}
+		value := strings.TrimSpace(parts[0])
+		if err := parse(value); err != nil {
+			return choice, fmt.Errorf("invalid -%s: %s", name, err)
+		}
+		weight, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil || weight < 0 {
+			return choice, fmt.Errorf("invalid -%s: weight of %s must be a non-negative integer", name, value)
+		}
+		choice.values = append(choice.values, value)
+		choice.weights = append(choice.weights, weight)
+		choice.total += weight
+	}
+	if choice.total == 0 {
+		return choice, fmt.Errorf("invalid -%s: at least one value needs a positive weight", name)
+	}
+	return choice, nil
+}
+
+func (choice weightedChoice) pick(random *rand.Rand) string {
+	n := random.Intn(choice.total)
+	for i, weight := range choice.weights {
+		if n < weight {
+			return choice.values[i]
+		}
+		n -= weight
+	}
+	return choice.values[len(choice.values)-1]
+}
+
+// simulation describes the synthetic pods of a simulation.
+type simulation struct {
+	pods       int
+	namespaces []string
+	// ages picks the maximum age of each pod, whose age is then uniformly random up to it
+	ages     weightedChoice
+	phases   weightedChoice
+	statuses weightedChoice
+	seed     int64
+}
+
+// generatePods creates the synthetic pods, spread over the namespaces and controlled by replica sets of
+// simulatedPodsPerOwner pods each.
+func (simulation simulation) generatePods(now time.Time) []v1.Pod {
+	random := rand.New(rand.NewSource(simulation.seed))
+	pods := make([]v1.Pod, simulation.pods)
+	controller := true
+	for i := range pods {
+		maxAge, _ := time.ParseDuration(simulation.ages.pick(random))
+		started := metav1.NewTime(now.Add(-time.Duration(random.Int63n(int64(maxAge) + 1))))
+		owner := fmt.Sprintf("simulated-%d", i/simulatedPodsPerOwner)
+		container := v1.ContainerStatus{Name: "app", Ready: true, State: v1.ContainerState{Running: &v1.ContainerStateRunning{StartedAt: started}}}
+		if status := simulation.statuses.pick(random); status != runningStatus {
+			container.Ready = false
+			container.State = v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: status}}
+		}
+		pods[i] = v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              fmt.Sprintf("%s-%d", owner, i%simulatedPodsPerOwner),
+				Namespace:         simulation.namespaces[(i/simulatedPodsPerOwner)%len(simulation.namespaces)],
+				UID:               types.UID(fmt.Sprintf("simulated-%d", i)),
+				Labels:            map[string]string{"app": owner},
+				CreationTimestamp: started,
+				OwnerReferences:   []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: owner, Controller: &controller}},
+			},
+			Spec: v1.PodSpec{NodeName: fmt.Sprintf("node-%d", i%100)},
+			Status: v1.PodStatus{
+				Phase:             v1.PodPhase(simulation.phases.pick(random)),
+				StartTime:         &started,
+				ContainerStatuses: []v1.ContainerStatus{container},
+			},
+		}
+	}
+	return pods
+}
+
+// simulationReport is the predicted cost of a reap cycle over the synthetic pods.
+type simulationReport struct {
+	Pods       int      `json:"pods"`
+	Namespaces int      `json:"namespaces"`
+	Rules      []string `json:"rules"`
+	Evaluated  int      `json:"evaluated"`
+	Matched    int      `json:"matched"`
+	Reaped     int      `json:"reaped"`
+	Skipped    int      `json:"skipped"`
+	Failed     int      `json:"failed"`
+	// APICalls counts the calls made to the in-memory API server by verb, for example list or delete
+	APICalls     map[string]int `json:"apiCalls"`
+	CycleSeconds float64        `json:"cycleSeconds"`
+	// AllocatedBytes and Allocations are the memory allocated during the cycle, and HeapBytes the heap in use after it
+	AllocatedBytes uint64   `json:"allocatedBytes"`
+	Allocations    uint64   `json:"allocations"`
+	HeapBytes      uint64   `json:"heapBytes"`
+	Errors         []string `json:"errors,omitempty"`
+}
+
+// simulate runs a reap cycle with the options over the synthetic pods, served by an in-memory API server so that no
+// pod in any cluster is touched, and measures it.
+func (simulation simulation) simulate(opts Options) simulationReport {
+	pods := simulation.generatePods(time.Now())
+	objects := make([]k8sruntime.Object, len(pods))
+	for i := range pods {
+		objects[i] = &pods[i]
+	}
+	clientSet := fake.NewSimpleClientset(objects...)
+	// nothing leaves the simulation: notifications, audit records, and exports would describe pods that do not exist
+	opts.notifiers = nil
+	opts.audit = nil
+	opts.warehouse = nil
+	opts.dryRunReport = ""
+	opts.useInformer = false
+	// pacing only adds waiting to the cycle, not cost
+	opts.reapInterval = 0
+	reaper := reaper{
+		clientSet:  clientSet,
+		options:    opts,
+		budget:     newAPIBudget(opts.apiCallBudget),
+		escalation: newGraceEscalation(opts.graceEscalationWindow, opts.gracePeriodFloor),
+		cooldown:   newOwnerCooldown(opts.workloadCooldown),
+		lastCycle:  &lastCycle{},
+	}
+	clientSet.ClearActions()
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = panicError(r)
+			}
+		}()
+		return reaper.scytheCycle()
+	}()
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	report := simulationReport{
+		Pods:           len(pods),
+		Namespaces:     len(simulation.namespaces),
+		Rules:          opts.rules.Names(),
+		APICalls:       map[string]int{},
+		CycleSeconds:   elapsed.Seconds(),
+		AllocatedBytes: after.TotalAlloc - before.TotalAlloc,
+		Allocations:    after.Mallocs - before.Mallocs,
+		HeapBytes:      after.HeapInuse,
+	}
+	for _, action := range clientSet.Actions() {
+		report.APICalls[apiCallName(action)]++
+	}
+	if result := reaper.lastCycle.result; result != nil {
+		report.Evaluated = result.Evaluated
+		report.Matched = result.Matched
+		report.Reaped = len(result.Reaped)
+		report.Skipped = len(result.Skipped)
+		report.Failed = len(result.Failed)
+	}
+	if cycleErrors, ok := err.(cycleErrors); ok {
+		for _, cycleError := range cycleErrors {
+			report.Errors = append(report.Errors, cycleError.Error())
+		}
+	} else if err != nil {
+		report.Errors = append(report.Errors, err.Error())
+	}
+	return report
+}
+
+// apiCallName names an API call by its verb and, for calls on a subresource such as evictions, the subresource.
+func apiCallName(action k8stesting.Action) string {
+	if action.GetSubresource() != "" {
+		return action.GetVerb() + " " + action.GetSubresource()
+	}
+	return action.GetVerb()
+}
+
+// simulationNamespaces names the namespaces of the synthetic pods: the namespaces pod-reaper is configured to watch,
+// or count generated namespaces when it watches every namespace.
+func simulationNamespaces(opts Options, count int) []string {
+	if len(opts.namespaces) > 0 {
+		return opts.namespaces
+	}
+	if opts.namespace != "" {
+		return []string{opts.namespace}
+	}
+	namespaces := make([]string, count)
+	for i := range namespaces {
+		namespaces[i] = fmt.Sprintf("simulated-%d", i)
+	}
+	return namespaces
+}
+
+// runSimulate runs the simulate command: pod-reaper simulate [-pods 10000] [-namespaces 10] [-ages ...]
+// [-phases ...] [-statuses ...] [-seed 1] [-format text|json] runs a reap cycle, configured with the usual
+// environment variables, over synthetic pods and writes its cost to out.
+func runSimulate(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet(simulateCommand, flag.ContinueOnError)
+	pods := flags.Int("pods", 10000, "number of synthetic pods")
+	namespaces := flags.Int("namespaces", 10, "number of namespaces the pods are spread over, unless NAMESPACE or NAMESPACES is set")
+	ages := flags.String("ages", "1h=30,24h=40,720h=30", "maximum pod ages and their weights")
+	phases := flags.String("phases", "Running=90,Pending=5,Succeeded=3,Failed=2", "pod phases and their weights")
+	statuses := flags.String("statuses", "Running=95,CrashLoopBackOff=3,ImagePullBackOff=2", "container statuses and their weights, where any status other than Running is the reason the container is waiting")
+	seed := flags.Int64("seed", 1, "seed of the synthetic pods, the same seed generates the same pods")
+	format := flags.String("format", textFormat, "output format, text or json")
+	if err := flags.Parse(args); err == flag.ErrHelp {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if *format != textFormat && *format != jsonFormat {
+		return fmt.Errorf("invalid format %q: must be %s or %s", *format, textFormat, jsonFormat)
+	}
+	if *pods <= 0 || *namespaces <= 0 {
+		return fmt.Errorf("-pods and -namespaces must be positive")
+	}
+	simulation := simulation{pods: *pods, seed: *seed}
+	var err error
+	parseDuration := func(value string) error {
+		duration, err := time.ParseDuration(value)
+		if err == nil && duration < 0 {
+			err = fmt.Errorf("%s is negative", value)
+		}
+		return err
+	}
+	if simulation.ages, err = parseWeightedChoice("ages", *ages, parseDuration); err != nil {
+		return err
+	}
+	anything := func(string) error { return nil }
+	if simulation.phases, err = parseWeightedChoice("phases", *phases, anything); err != nil {
+		return err
+	}
+	if simulation.statuses, err = parseWeightedChoice("statuses", *statuses, anything); err != nil {
+		return err
+	}
+
+	// the synthetic pods are in memory, so the failsafe against reaping every pod does not apply
+	os.Setenv(envIUnderstandTheRisk, "true")
+	opts, err := LoadOptions()
+	if err != nil {
+		return err
+	}
+	simulation.namespaces = simulationNamespaces(opts, *namespaces)
+	report := simulation.simulate(opts)
+	logrus.WithFields(logrus.Fields{"pods": report.Pods, "seconds": report.CycleSeconds}).Debug("simulated reap cycle")
+
+	if *format == jsonFormat {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+	var calls []string
+	for call, count := range report.APICalls {
+		calls = append(calls, fmt.Sprintf("%s %d", call, count))
+	}
+	sort.Strings(calls)
+	writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "pods\t%d in %d namespaces\n", report.Pods, report.Namespaces)
+	fmt.Fprintf(writer, "rules\t%s\n", strings.Join(report.Rules, ", "))
+	fmt.Fprintf(writer, "evaluated\t%d\n", report.Evaluated)
+	fmt.Fprintf(writer, "matched\t%d\n", report.Matched)
+	fmt.Fprintf(writer, "reaped\t%d (%d skipped, %d failed)\n", report.Reaped, report.Skipped, report.Failed)
+	fmt.Fprintf(writer, "api calls\t%s\n", strings.Join(calls, ", "))
+	fmt.Fprintf(writer, "cycle duration\t%s\n", time.Duration(report.CycleSeconds*float64(time.Second)).Round(time.Millisecond))
+	fmt.Fprintf(writer, "memory allocated\t%d MiB in %d allocations\n", report.AllocatedBytes/(1024*1024), report.Allocations)
+	fmt.Fprintf(writer, "heap in use\t%d MiB\n", report.HeapBytes/(1024*1024))
+	for _, err := range report.Errors {
+		fmt.Fprintf(writer, "error\t%s\n", err)
+	}
+	return writer.Flush()
+}
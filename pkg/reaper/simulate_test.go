@@ -0,0 +1,133 @@
+package reaper
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/rand"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+)
+
+func testSimulation(t *testing.T, pods int) simulation {
+	anything := func(string) error { return nil }
+	ages, err := parseWeightedChoice("ages", "1h=1", anything)
+	assert.NoError(t, err)
+	phases, err := parseWeightedChoice("phases", "Running=3,Failed=1", anything)
+	assert.NoError(t, err)
+	statuses, err := parseWeightedChoice("statuses", "Running=1,CrashLoopBackOff=1", anything)
+	assert.NoError(t, err)
+	return simulation{pods: pods, namespaces: []string{"a", "b"}, ages: ages, phases: phases, statuses: statuses, seed: 1}
+}
+
+func TestParseWeightedChoice(t *testing.T) {
+	anything := func(string) error { return nil }
+	t.Run("valid", func(t *testing.T) {
+		choice, err := parseWeightedChoice("phases", "Running=90, Pending=10,", anything)
+		assert.NoError(t, err)
+		assert.Equal(t, weightedChoice{values: []string{"Running", "Pending"}, weights: []int{90, 10}, total: 100}, choice)
+	})
+	t.Run("missing weight", func(t *testing.T) {
+		_, err := parseWeightedChoice("phases", "Running", anything)
+		assert.EqualError(t, err, `invalid -phases: "Running" is not of the form value=weight`)
+	})
+	t.Run("negative weight", func(t *testing.T) {
+		_, err := parseWeightedChoice("phases", "Running=-1", anything)
+		assert.Error(t, err)
+	})
+	t.Run("no weight", func(t *testing.T) {
+		_, err := parseWeightedChoice("phases", "Running=0", anything)
+		assert.Error(t, err)
+	})
+	t.Run("invalid value", func(t *testing.T) {
+		_, err := parseWeightedChoice("ages", "soon=1", func(value string) error {
+			_, err := time.ParseDuration(value)
+			return err
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestWeightedChoicePick(t *testing.T) {
+	choice := weightedChoice{values: []string{"never", "always"}, weights: []int{0, 5}, total: 5}
+	random := rand.New(rand.NewSource(1))
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, "always", choice.pick(random))
+	}
+}
+
+func TestGeneratePods(t *testing.T) {
+	now := time.Now()
+	pods := testSimulation(t, 40).generatePods(now)
+	assert.Len(t, pods, 40)
+	assert.Equal(t, pods, testSimulation(t, 40).generatePods(now), "the same seed generates the same pods")
+
+	names := map[string]bool{}
+	phases := map[v1.PodPhase]int{}
+	for i, pod := range pods {
+		names[pod.Namespace+"/"+pod.Name] = true
+		phases[pod.Status.Phase]++
+		assert.True(t, pod.Status.StartTime.Time.After(now.Add(-time.Hour-time.Second)))
+		assert.Equal(t, pods[i-i%simulatedPodsPerOwner].Namespace, pod.Namespace, "pods of an owner share a namespace")
+	}
+	assert.Len(t, names, 40)
+	assert.Len(t, phases, 2)
+}
+
+func TestSimulate(t *testing.T) {
+	opts := minimalOptions("1.0")
+	opts.namespace = ""
+	opts.maxPods = 5
+	opts.reapInterval = time.Hour
+
+	report := testSimulation(t, 50).simulate(opts)
+
+	assert.Equal(t, 50, report.Pods)
+	assert.Equal(t, 2, report.Namespaces)
+	assert.Equal(t, []string{"chaos"}, report.Rules)
+	assert.Equal(t, 50, report.Evaluated)
+	assert.Equal(t, 50, report.Matched)
+	assert.Equal(t, 5, report.Reaped)
+	assert.Equal(t, 45, report.Skipped)
+	assert.Equal(t, map[string]int{"list": 1, "delete": 5}, report.APICalls)
+	assert.NotZero(t, report.AllocatedBytes)
+	assert.Empty(t, report.Errors)
+}
+
+func TestRunSimulate(t *testing.T) {
+	t.Run("json", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv("CHAOS_CHANCE", "0")
+		var out bytes.Buffer
+		assert.NoError(t, runSimulate([]string{"-pods", "20", "-format", "json"}, &out))
+		var report simulationReport
+		assert.NoError(t, json.Unmarshal(out.Bytes(), &report))
+		assert.Equal(t, 20, report.Evaluated)
+		assert.Equal(t, 0, report.Matched)
+		assert.Equal(t, 10, report.Namespaces)
+	})
+	t.Run("text", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv("CHAOS_CHANCE", "1")
+		os.Setenv(envNamespace, "default")
+		var out bytes.Buffer
+		assert.NoError(t, runSimulate([]string{"-pods", "20"}, &out))
+		assert.Contains(t, out.String(), "pods              20 in 1 namespaces\n")
+		assert.Contains(t, out.String(), "reaped            20 (0 skipped, 0 failed)\n")
+	})
+	t.Run("invalid distribution", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv("CHAOS_CHANCE", "1")
+		assert.Error(t, runSimulate([]string{"-ages", "1h=1,forever=1"}, &bytes.Buffer{}))
+	})
+	t.Run("invalid options", func(t *testing.T) {
+		os.Clearenv()
+		assert.EqualError(t, runSimulate([]string{"-pods", "20"}, &bytes.Buffer{}), "no rules were loaded")
+	})
+	t.Run("invalid pods", func(t *testing.T) {
+		assert.Error(t, runSimulate([]string{"-pods", "0"}, &bytes.Buffer{}))
+	})
+}
@@ -0,0 +1,139 @@
+package reaper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// defaultSlackTemplate renders a single reap notification as a line of slack message text.
+const defaultSlackTemplate = `{{if .DryRun}}would reap{{else}}reaped{{end}} pod {{.Namespace}}/{{.Pod}}: {{join .Reasons ", "}}`
+
+var _ notifier = (*slackNotifier)(nil)
+var _ flusher = (*slackNotifier)(nil)
+var _ approvalRequester = (*slackNotifier)(nil)
+var _ experimentReporter = (*slackNotifier)(nil)
+
+// slackNotifier posts reap notifications to a slack incoming webhook, either one message per reaped pod or, in
+// summary mode, one message per reap cycle.
+type slackNotifier struct {
+	url        string
+	channel    string
+	template   *template.Template
+	summary    bool
+	client     *http.Client
+	retries    int
+	retryDelay time.Duration
+
+	mutex   sync.Mutex
+	pending []reapNotification
+}
+
+func parseSlackTemplate(text string) (*template.Template, error) {
+	return template.New("slack").Funcs(template.FuncMap{"join": strings.Join}).Parse(text)
+}
+
+func (slack *slackNotifier) name() string {
+	return "slack"
+}
+
+func (slack *slackNotifier) notify(notification reapNotification) error {
+	if slack.summary {
+		slack.mutex.Lock()
+		defer slack.mutex.Unlock()
+		slack.pending = append(slack.pending, notification)
+		return nil
+	}
+	text, err := slack.render(notification)
+	if err != nil {
+		return err
+	}
+	return slack.post(context.Background(), "pod-reaper "+text)
+}
+
+// flush posts the summary of the notifications received since the last flush. Nothing is posted for a cycle that
+// did not reap any pods.
+func (slack *slackNotifier) flush(ctx context.Context) error {
+	slack.mutex.Lock()
+	pending := slack.pending
+	slack.pending = nil
+	slack.mutex.Unlock()
+	if len(pending) == 0 {
+		return nil
+	}
+	var text strings.Builder
+	fmt.Fprintf(&text, "pod-reaper cycle summary (%d pods):", len(pending))
+	for _, notification := range pending {
+		line, err := slack.render(notification)
+		if err != nil {
+			return err
+		}
+		text.WriteString("\n• " + line)
+	}
+	return slack.post(ctx, text.String())
+}
+
+func (slack *slackNotifier) render(notification reapNotification) (string, error) {
+	var text bytes.Buffer
+	if err := slack.template.Execute(&text, notification); err != nil {
+		return "", err
+	}
+	return text.String(), nil
+}
+
+// requestApproval posts a message with a button that approves the cycle through the slack interactivity endpoint.
+func (slack *slackNotifier) requestApproval(cycleID string, pods int) error {
+	text := fmt.Sprintf("pod-reaper cycle %s would reap %d pods and is awaiting approval", cycleID, pods)
+	return slack.postMessage(context.Background(), map[string]interface{}{
+		"text": text,
+		"blocks": []interface{}{
+			map[string]interface{}{
+				"type": "section",
+				"text": map[string]string{"type": "mrkdwn", "text": text},
+			},
+			map[string]interface{}{
+				"type": "actions",
+				"elements": []interface{}{
+					map[string]interface{}{
+						"type":      "button",
+						"action_id": slackApproveAction,
+						"value":     cycleID,
+						"style":     "danger",
+						"text":      map[string]string{"type": "plain_text", "text": "Approve"},
+					},
+				},
+			},
+		},
+	})
+}
+
+// reportExperiment posts the result of a chaos experiment, listing the owners that did not return to full readiness.
+func (slack *slackNotifier) reportExperiment(summary experimentSummary) error {
+	var text strings.Builder
+	fmt.Fprintf(&text, "pod-reaper chaos experiment %s (window %s): %d disrupted owners", summary.Result, summary.Window, len(summary.Owners))
+	for _, owner := range summary.failedOwners() {
+		fmt.Fprintf(&text, "\n• %s is not fully ready: %s", owner, owner.Message)
+	}
+	return slack.post(context.Background(), text.String())
+}
+
+func (slack *slackNotifier) post(ctx context.Context, text string) error {
+	return slack.postMessage(ctx, map[string]interface{}{"text": text})
+}
+
+func (slack *slackNotifier) postMessage(ctx context.Context, message map[string]interface{}) error {
+	if slack.channel != "" {
+		message["channel"] = slack.channel
+	}
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	return postWithRetries(ctx, slack.client, slack.url, body, slack.retries, slack.retryDelay)
+}
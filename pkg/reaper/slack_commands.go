@@ -0,0 +1,192 @@
+package reaper
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// slackApproveAction is the action id of the button posted with cycles that are awaiting approval.
+const slackApproveAction = "approve_cycle"
+
+// slack rejects requests older than this to prevent replays, and so does pod-reaper
+const slackSignatureMaxAge = 5 * time.Minute
+
+// slackCommands serves slack slash commands and interactive components, verifying that each request was signed by
+// slack with the app's signing secret.
+type slackCommands struct {
+	reaper reaper
+	secret string
+	client *http.Client
+}
+
+// verifySlackSignature checks the request signature described at
+// https://api.slack.com/authentication/verifying-requests-from-slack
+func verifySlackSignature(secret string, header http.Header, body []byte, now time.Time) error {
+	timestamp := header.Get("X-Slack-Request-Timestamp")
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errors.New("missing or invalid request timestamp")
+	}
+	age := now.Sub(time.Unix(seconds, 0))
+	if age > slackSignatureMaxAge || age < -slackSignatureMaxAge {
+		return errors.New("request timestamp is too old")
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "v0:%s:%s", timestamp, body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(header.Get("X-Slack-Signature"))) {
+		return errors.New("invalid request signature")
+	}
+	return nil
+}
+
+// verify reads and verifies the request body, restoring it so the form can be parsed.
+func (commands slackCommands) verify(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return false
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "unable to read request", http.StatusBadRequest)
+		return false
+	}
+	if err := verifySlackSignature(commands.secret, r.Header, body, time.Now()); err != nil {
+		logrus.WithError(err).Warn("rejected slack request")
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return false
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return true
+}
+
+// run executes a command of the form "snooze <namespace> <hours>", "explain <namespace>/<pod>" or
+// "approve <cycle id>" and returns the text to reply with.
+func (commands slackCommands) run(text string, user string) string {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return slackUsage
+	}
+	log := logrus.WithFields(logrus.Fields{"user": user, "command": text})
+	switch {
+	case fields[0] == "snooze" && len(fields) == 3:
+		hours, err := strconv.ParseFloat(fields[2], 64)
+		if err != nil || hours <= 0 {
+			return "invalid number of hours: " + fields[2]
+		}
+		until := time.Now().Add(time.Duration(hours * float64(time.Hour)))
+		commands.reaper.admin.snooze(fields[1], until)
+		log.Info("namespace snoozed from slack")
+		return fmt.Sprintf("pods in %s will not be reaped until %s", fields[1], until.UTC().Format(time.RFC3339))
+	case fields[0] == "explain" && len(fields) == 2:
+		parts := strings.SplitN(fields[1], "/", 2)
+		if len(parts) != 2 {
+			return "expected <namespace>/<pod>, got " + fields[1]
+		}
+		explanation, err := commands.reaper.explain(parts[0], parts[1])
+		if err != nil {
+			return fmt.Sprintf("unable to explain %s: %s", fields[1], err)
+		}
+		return slackExplanation(explanation)
+	case fields[0] == "approve" && len(fields) == 2:
+		if err := commands.reaper.approveCycle(fields[1]); err != nil {
+			return fmt.Sprintf("unable to approve cycle %s: %s", fields[1], err)
+		}
+		log.Info("reap cycle approved from slack")
+		return fmt.Sprintf("cycle %s approved, reaping now", fields[1])
+	}
+	return slackUsage
+}
+
+const slackUsage = "usage: `snooze <namespace> <hours>`, `explain <namespace>/<pod>`, or `approve <cycle id>`"
+
+func slackExplanation(explanation podExplanation) string {
+	var text strings.Builder
+	verdict := "would not be reaped"
+	if explanation.Reap {
+		verdict = "would be reaped"
+	}
+	fmt.Fprintf(&text, "%s/%s %s", explanation.Namespace, explanation.Pod, verdict)
+	if explanation.Skipped != "" {
+		text.WriteString(": " + explanation.Skipped)
+	}
+	for _, rule := range explanation.Rules {
+		mark := "✗"
+		if rule.Reap {
+			mark = "✓"
+		} else if rule.OutOfScope {
+			mark = "–"
+		}
+		fmt.Fprintf(&text, "\n%s %s", mark, rule.Rule)
+		if rule.Reason != "" {
+			text.WriteString(": " + rule.Reason)
+		}
+	}
+	return text.String()
+}
+
+func (commands slackCommands) handleCommand(w http.ResponseWriter, r *http.Request) {
+	if !commands.verify(w, r) {
+		return
+	}
+	reply := commands.run(r.FormValue("text"), r.FormValue("user_name"))
+	writeJSON(w, map[string]string{"response_type": "ephemeral", "text": reply})
+}
+
+// slackInteraction is the part of an interactive component payload that pod-reaper uses.
+type slackInteraction struct {
+	Type    string `json:"type"`
+	Actions []struct {
+		ActionID string `json:"action_id"`
+		Value    string `json:"value"`
+	} `json:"actions"`
+	User struct {
+		Username string `json:"username"`
+	} `json:"user"`
+	ResponseURL string `json:"response_url"`
+}
+
+func (commands slackCommands) handleAction(w http.ResponseWriter, r *http.Request) {
+	if !commands.verify(w, r) {
+		return
+	}
+	var interaction slackInteraction
+	if err := json.Unmarshal([]byte(r.FormValue("payload")), &interaction); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	for _, action := range interaction.Actions {
+		if action.ActionID != slackApproveAction {
+			continue
+		}
+		reply := commands.run("approve "+action.Value, interaction.User.Username)
+		// slack ignores the response body of interactive components, replies go to the response url
+		if interaction.ResponseURL != "" {
+			go commands.respond(interaction.ResponseURL, reply)
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (commands slackCommands) respond(url string, text string) {
+	body, err := json.Marshal(map[string]interface{}{"text": text, "replace_original": false})
+	if err == nil {
+		err = post(context.Background(), commands.client, url, body)
+	}
+	if err != nil {
+		logrus.WithError(err).Warn("unable to respond to slack")
+	}
+}
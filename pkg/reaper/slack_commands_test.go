@@ -0,0 +1,140 @@
+package reaper
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testSigningSecret = "test-secret"
+
+func signedSlackRequest(path string, body string, timestamp time.Time) *http.Request {
+	request := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	seconds := strconv.FormatInt(timestamp.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(testSigningSecret))
+	mac.Write([]byte("v0:" + seconds + ":" + body))
+	request.Header.Set("X-Slack-Request-Timestamp", seconds)
+	request.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	return request
+}
+
+func TestVerifySlackSignature(t *testing.T) {
+	now := time.Now()
+	body := []byte("text=hello")
+	t.Run("valid", func(t *testing.T) {
+		request := signedSlackRequest("/", string(body), now)
+		assert.NoError(t, verifySlackSignature(testSigningSecret, request.Header, body, now))
+	})
+	t.Run("wrong secret", func(t *testing.T) {
+		request := signedSlackRequest("/", string(body), now)
+		assert.Error(t, verifySlackSignature("other-secret", request.Header, body, now))
+	})
+	t.Run("modified body", func(t *testing.T) {
+		request := signedSlackRequest("/", string(body), now)
+		assert.Error(t, verifySlackSignature(testSigningSecret, request.Header, []byte("text=goodbye"), now))
+	})
+	t.Run("replayed", func(t *testing.T) {
+		request := signedSlackRequest("/", string(body), now.Add(-10*time.Minute))
+		assert.Error(t, verifySlackSignature(testSigningSecret, request.Header, body, now))
+	})
+	t.Run("unsigned", func(t *testing.T) {
+		assert.Error(t, verifySlackSignature(testSigningSecret, http.Header{}, body, now))
+	})
+}
+
+func testSlackCommands(r reaper) slackCommands {
+	if r.admin == nil {
+		r.admin = newAdmin()
+	}
+	return slackCommands{reaper: r, secret: testSigningSecret, client: &http.Client{Timeout: time.Second}}
+}
+
+func TestSlackCommandsRun(t *testing.T) {
+	t.Run("snooze", func(t *testing.T) {
+		commands := testSlackCommands(createTestReaper(minimalOptions("1.0")))
+		reply := commands.run("snooze default 2", "user")
+		assert.Contains(t, reply, "pods in default will not be reaped until")
+		assert.True(t, commands.reaper.admin.isSnoozed("default", time.Now().Add(time.Hour)))
+		assert.False(t, commands.reaper.admin.isSnoozed("default", time.Now().Add(3*time.Hour)))
+	})
+	t.Run("snooze invalid hours", func(t *testing.T) {
+		commands := testSlackCommands(createTestReaper(minimalOptions("1.0")))
+		assert.Contains(t, commands.run("snooze default soon", "user"), "invalid number of hours")
+	})
+	t.Run("explain", func(t *testing.T) {
+		commands := testSlackCommands(createTestReaper(minimalOptions("1.0"), createTestPod("pod", "default", nil)))
+		reply := commands.run("explain default/pod", "user")
+		assert.Contains(t, reply, "default/pod would be reaped")
+		assert.Contains(t, reply, "✓ chaos")
+	})
+	t.Run("explain invalid pod", func(t *testing.T) {
+		commands := testSlackCommands(createTestReaper(minimalOptions("1.0")))
+		assert.Contains(t, commands.run("explain pod", "user"), "expected <namespace>/<pod>")
+	})
+	t.Run("approve without pending cycle", func(t *testing.T) {
+		commands := testSlackCommands(createTestReaper(minimalOptions("1.0")))
+		assert.Contains(t, commands.run("approve abc", "user"), "unable to approve cycle abc")
+	})
+	t.Run("usage", func(t *testing.T) {
+		commands := testSlackCommands(createTestReaper(minimalOptions("1.0")))
+		assert.Equal(t, slackUsage, commands.run("", "user"))
+		assert.Equal(t, slackUsage, commands.run("reap everything", "user"))
+	})
+}
+
+func TestSlackCommandsHandlers(t *testing.T) {
+	t.Run("signed command", func(t *testing.T) {
+		commands := testSlackCommands(createTestReaper(minimalOptions("1.0")))
+		recorder := httptest.NewRecorder()
+		body := url.Values{"text": {"snooze default 1"}, "user_name": {"user"}}.Encode()
+		commands.handleCommand(recorder, signedSlackRequest("/slack/commands", body, time.Now()))
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		var reply map[string]string
+		assert.NoError(t, json.NewDecoder(recorder.Body).Decode(&reply))
+		assert.Equal(t, "ephemeral", reply["response_type"])
+		assert.True(t, commands.reaper.admin.isSnoozed("default", time.Now()))
+	})
+	t.Run("unsigned command", func(t *testing.T) {
+		commands := testSlackCommands(createTestReaper(minimalOptions("1.0")))
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodPost, "/slack/commands", strings.NewReader("text=snooze+default+1"))
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		commands.handleCommand(recorder, request)
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+		assert.False(t, commands.reaper.admin.isSnoozed("default", time.Now()))
+	})
+	t.Run("approve action", func(t *testing.T) {
+		responses := make(chan map[string]interface{}, 1)
+		server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			var message map[string]interface{}
+			assert.NoError(t, json.NewDecoder(request.Body).Decode(&message))
+			responses <- message
+		}))
+		defer server.Close()
+		commands := testSlackCommands(createTestReaper(minimalOptions("0.0")))
+		commands.reaper.admin.holdForApproval("abc")
+		payload, _ := json.Marshal(map[string]interface{}{
+			"type":         "block_actions",
+			"actions":      []map[string]string{{"action_id": slackApproveAction, "value": "abc"}},
+			"user":         map[string]string{"username": "user"},
+			"response_url": server.URL,
+		})
+		recorder := httptest.NewRecorder()
+		body := url.Values{"payload": {string(payload)}}.Encode()
+		commands.handleAction(recorder, signedSlackRequest("/slack/actions", body, time.Now()))
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "cycle abc approved, reaping now", (<-responses)["text"])
+		assert.Empty(t, commands.reaper.admin.pending)
+	})
+}
@@ -0,0 +1,132 @@
+package reaper
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testSlackServer(t *testing.T, received chan<- map[string]string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		var message map[string]string
+		assert.NoError(t, json.NewDecoder(request.Body).Decode(&message))
+		received <- message
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func testSlackNotifier(t *testing.T, url string, templateText string, summary bool) *slackNotifier {
+	template, err := parseSlackTemplate(templateText)
+	assert.NoError(t, err)
+	return &slackNotifier{
+		url:        url,
+		template:   template,
+		summary:    summary,
+		client:     &http.Client{Timeout: time.Second},
+		retryDelay: time.Millisecond,
+	}
+}
+
+func TestSlackNotifier(t *testing.T) {
+	notification := reapNotification{Pod: "test-pod", Namespace: "default", Reasons: []string{"reason one", "reason two"}}
+
+	t.Run("per pod", func(t *testing.T) {
+		received := make(chan map[string]string, 1)
+		slack := testSlackNotifier(t, testSlackServer(t, received).URL, defaultSlackTemplate, false)
+
+		assert.NoError(t, slack.notify(notification))
+
+		message := <-received
+		assert.Equal(t, "pod-reaper reaped pod default/test-pod: reason one, reason two", message["text"])
+		assert.NotContains(t, message, "channel")
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		received := make(chan map[string]string, 1)
+		slack := testSlackNotifier(t, testSlackServer(t, received).URL, defaultSlackTemplate, false)
+		dryRun := notification
+		dryRun.DryRun = true
+
+		assert.NoError(t, slack.notify(dryRun))
+
+		assert.Equal(t, "pod-reaper would reap pod default/test-pod: reason one, reason two", (<-received)["text"])
+	})
+
+	t.Run("channel and template", func(t *testing.T) {
+		received := make(chan map[string]string, 1)
+		slack := testSlackNotifier(t, testSlackServer(t, received).URL, "{{.Pod}}", false)
+		slack.channel = "#alerts"
+
+		assert.NoError(t, slack.notify(notification))
+
+		message := <-received
+		assert.Equal(t, "pod-reaper test-pod", message["text"])
+		assert.Equal(t, "#alerts", message["channel"])
+	})
+
+	t.Run("summary", func(t *testing.T) {
+		received := make(chan map[string]string, 1)
+		slack := testSlackNotifier(t, testSlackServer(t, received).URL, "{{.Pod}}", true)
+
+		assert.NoError(t, slack.notify(notification))
+		assert.NoError(t, slack.notify(notification))
+		assert.Empty(t, received)
+		assert.NoError(t, slack.flush(context.Background()))
+
+		assert.Equal(t, "pod-reaper cycle summary (2 pods):\n• test-pod\n• test-pod", (<-received)["text"])
+		assert.Empty(t, slack.pending)
+	})
+
+	t.Run("empty summary not posted", func(t *testing.T) {
+		received := make(chan map[string]string, 1)
+		slack := testSlackNotifier(t, testSlackServer(t, received).URL, "{{.Pod}}", true)
+
+		assert.NoError(t, slack.flush(context.Background()))
+		assert.Empty(t, received)
+	})
+
+	t.Run("template error", func(t *testing.T) {
+		slack := testSlackNotifier(t, "http://127.0.0.1:0", "{{.Missing}}", false)
+		assert.Error(t, slack.notify(notification))
+	})
+}
+
+func TestScytheCycleSlackSummary(t *testing.T) {
+	received := make(chan map[string]string, 1)
+	slack := testSlackNotifier(t, testSlackServer(t, received).URL, "{{.Pod}}", true)
+	startTime := time.Now()
+	opts := minimalOptions("1.0")
+	opts.notifiers = []notifier{slack}
+	r := createTestReaper(opts, createTestPod("pod-1", "default", &startTime))
+
+	r.scytheCycle()
+
+	assert.Equal(t, "pod-reaper cycle summary (1 pods):\n• pod-1", (<-received)["text"])
+}
+
+func TestSlackRequestApproval(t *testing.T) {
+	received := make(chan map[string]interface{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		var message map[string]interface{}
+		assert.NoError(t, json.NewDecoder(request.Body).Decode(&message))
+		received <- message
+	}))
+	defer server.Close()
+	slack := testSlackNotifier(t, server.URL, defaultSlackTemplate, false)
+	slack.channel = "#reaper"
+
+	assert.NoError(t, slack.requestApproval("abc", 3))
+
+	message := <-received
+	assert.Equal(t, "pod-reaper cycle abc would reap 3 pods and is awaiting approval", message["text"])
+	assert.Equal(t, "#reaper", message["channel"])
+	encoded, _ := json.Marshal(message["blocks"])
+	assert.Contains(t, string(encoded), `"action_id":"approve_cycle"`)
+	assert.Contains(t, string(encoded), `"value":"abc"`)
+}
@@ -0,0 +1,134 @@
+package reaper
+
+import (
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// podEvaluation is the verdict of the rules on a pod in a reap cycle.
+type podEvaluation struct {
+	pod        v1.Pod
+	shouldReap bool
+	reasons    []string
+	// rules names the rules the pod was evaluated with
+	rules []string
+	// priority is the highest priority of the rules that flagged the pod
+	priority int
+}
+
+// spreadVictims reorders the pods to reap of each owner with topology spread constraints so that pods in the most
+// crowded topology domains are reaped first, reducing (or at least not increasing) the owner's skew. Reordered pods
+// take the positions of the owner's pods to reap, so the sorting strategy still decides the order between owners.
+func (reaper reaper) spreadVictims(evaluations []podEvaluation, pods []v1.Pod) {
+	owners := map[string][]int{}
+	for i, evaluation := range evaluations {
+		if !evaluation.shouldReap || len(evaluation.pod.Spec.TopologySpreadConstraints) == 0 {
+			continue
+		}
+		if owner := ownerKey(evaluation.pod); owner != "" {
+			owners[owner] = append(owners[owner], i)
+		}
+	}
+	var nodeLabels map[string]labels.Set
+	for _, indexes := range owners {
+		if len(indexes) < 2 {
+			continue
+		}
+		if nodeLabels == nil {
+			var err error
+			if nodeLabels, err = reaper.listNodeLabels(); err != nil {
+				logrus.WithError(err).Warn("unable to list nodes, reaping pods without respecting topology spread")
+				return
+			}
+		}
+		ordered := spreadOrder(evaluations, indexes, pods, nodeLabels)
+		for i, index := range indexes {
+			evaluations[index] = ordered[i]
+		}
+	}
+}
+
+func (reaper reaper) listNodeLabels() (map[string]labels.Set, error) {
+	if !reaper.apiCall(operationList) {
+		return nil, errAPIBudgetExhausted
+	}
+	ctx, cancel := reaper.apiContext()
+	defer cancel()
+	nodes, err := reaper.clientSet.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	nodeLabels := map[string]labels.Set{}
+	for _, node := range nodes.Items {
+		nodeLabels[node.Name] = node.Labels
+	}
+	return nodeLabels, nil
+}
+
+// spreadConstraint counts the pods selected by a topology spread constraint in each of its topology domains.
+type spreadConstraint struct {
+	topologyKey string
+	counts      map[string]int
+}
+
+// spreadOrder orders the evaluations at the indexes, all of pods of the same owner, by greedily picking the pod in the
+// most crowded domains given the pods picked before it. Ties keep the original order.
+func spreadOrder(evaluations []podEvaluation, indexes []int, pods []v1.Pod, nodeLabels map[string]labels.Set) []podEvaluation {
+	// pods of an owner share a template, so they share constraints
+	first := evaluations[indexes[0]].pod
+	var constraints []spreadConstraint
+	for _, constraint := range first.Spec.TopologySpreadConstraints {
+		selector, err := metav1.LabelSelectorAsSelector(constraint.LabelSelector)
+		if err != nil || constraint.LabelSelector == nil {
+			continue
+		}
+		counts := map[string]int{}
+		for _, pod := range pods {
+			if pod.Namespace != first.Namespace || !selector.Matches(labels.Set(pod.Labels)) {
+				continue
+			}
+			if domain, ok := topologyDomain(pod, constraint.TopologyKey, nodeLabels); ok {
+				counts[domain]++
+			}
+		}
+		constraints = append(constraints, spreadConstraint{topologyKey: constraint.TopologyKey, counts: counts})
+	}
+
+	remaining := append([]int{}, indexes...)
+	var ordered []podEvaluation
+	for len(remaining) > 0 {
+		best, bestScore := 0, -1
+		for i, index := range remaining {
+			score := 0
+			for _, constraint := range constraints {
+				if domain, ok := topologyDomain(evaluations[index].pod, constraint.topologyKey, nodeLabels); ok {
+					score += constraint.counts[domain]
+				}
+			}
+			if score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+		picked := evaluations[remaining[best]]
+		for _, constraint := range constraints {
+			if domain, ok := topologyDomain(picked.pod, constraint.topologyKey, nodeLabels); ok {
+				constraint.counts[domain]--
+			}
+		}
+		ordered = append(ordered, picked)
+		remaining = append(remaining[:best], remaining[best+1:]...)
+	}
+	return ordered
+}
+
+// topologyDomain returns the value of the topology key on the pod's node, which pods that are not scheduled or run on
+// nodes without the key do not have.
+func topologyDomain(pod v1.Pod, topologyKey string, nodeLabels map[string]labels.Set) (string, bool) {
+	if pod.Spec.NodeName == "" {
+		return "", false
+	}
+	domain, ok := nodeLabels[pod.Spec.NodeName][topologyKey]
+	return domain, ok
+}
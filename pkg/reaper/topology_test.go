@@ -0,0 +1,104 @@
+package reaper
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func testSpreadPod(name string, owner string, node string) v1.Pod {
+	pod := testOwnedPod(name, owner)
+	pod.Labels = map[string]string{"app": owner}
+	pod.Spec.NodeName = node
+	pod.Spec.TopologySpreadConstraints = []v1.TopologySpreadConstraint{{
+		MaxSkew:       1,
+		TopologyKey:   v1.LabelTopologyZone,
+		LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": owner}},
+	}}
+	return pod
+}
+
+func testZoneNode(name string, zone string) *v1.Node {
+	return &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{v1.LabelTopologyZone: zone}}}
+}
+
+func evaluatedPods(evaluations []podEvaluation) []string {
+	var names []string
+	for _, evaluation := range evaluations {
+		names = append(names, evaluation.pod.Name)
+	}
+	return names
+}
+
+func TestSpreadVictims(t *testing.T) {
+	client := fake.NewSimpleClientset(testZoneNode("node-a", "a"), testZoneNode("node-b", "b"))
+	r := reaper{clientSet: client, options: minimalOptions("1.0")}
+
+	t.Run("most crowded domain first", func(t *testing.T) {
+		pods := []v1.Pod{
+			testSpreadPod("web-a1", "web", "node-a"),
+			testSpreadPod("web-b1", "web", "node-b"),
+			testSpreadPod("web-b2", "web", "node-b"),
+			testSpreadPod("web-b3", "web", "node-b"),
+		}
+		var evaluations []podEvaluation
+		for _, pod := range pods {
+			evaluations = append(evaluations, podEvaluation{pod: pod, shouldReap: true})
+		}
+		r.spreadVictims(evaluations, pods)
+		assert.Equal(t, []string{"web-b1", "web-b2", "web-a1", "web-b3"}, evaluatedPods(evaluations))
+	})
+	t.Run("only pods to reap are reordered", func(t *testing.T) {
+		pods := []v1.Pod{
+			testSpreadPod("web-a1", "web", "node-a"),
+			testSpreadPod("other", "other", "node-a"),
+			testSpreadPod("web-b1", "web", "node-b"),
+			testSpreadPod("web-b2", "web", "node-b"),
+		}
+		evaluations := []podEvaluation{
+			{pod: pods[0], shouldReap: true},
+			{pod: pods[1], shouldReap: true},
+			{pod: pods[2], shouldReap: false},
+			{pod: pods[3], shouldReap: true},
+		}
+		r.spreadVictims(evaluations, pods)
+		assert.Equal(t, []string{"web-b2", "other", "web-b1", "web-a1"}, evaluatedPods(evaluations))
+	})
+	t.Run("without constraints", func(t *testing.T) {
+		pods := []v1.Pod{testOwnedPod("web-1", "web"), testOwnedPod("web-2", "web")}
+		evaluations := []podEvaluation{{pod: pods[0], shouldReap: true}, {pod: pods[1], shouldReap: true}}
+		r.spreadVictims(evaluations, pods)
+		assert.Equal(t, []string{"web-1", "web-2"}, evaluatedPods(evaluations))
+	})
+}
+
+func TestScytheCycleRespectsTopologySpread(t *testing.T) {
+	pods := []v1.Pod{
+		testSpreadPod("web-a1", "web", "node-a"),
+		testSpreadPod("web-b1", "web", "node-b"),
+		testSpreadPod("web-b2", "web", "node-b"),
+	}
+	objects := []runtime.Object{testZoneNode("node-a", "a"), testZoneNode("node-b", "b")}
+	for i := range pods {
+		objects = append(objects, &pods[i])
+	}
+	opts := minimalOptions("1.0")
+	opts.maxPods = 1
+	opts.respectTopologySpread = true
+	r := reaper{clientSet: fake.NewSimpleClientset(objects...), options: opts}
+
+	r.scytheCycle()
+
+	remaining, err := r.clientSet.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+	assert.NoError(t, err)
+	var names []string
+	for _, pod := range remaining.Items {
+		names = append(names, pod.Name)
+	}
+	assert.ElementsMatch(t, []string{"web-a1", "web-b2"}, names)
+}
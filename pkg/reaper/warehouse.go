@@ -0,0 +1,238 @@
+package reaper
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const bigQueryEndpoint = "https://bigquery.googleapis.com"
+
+// bigQueryMaxRows is the number of rows bigquery recommends inserting per request
+const bigQueryMaxRows = 500
+
+// warehouseExport batches audit records across reap cycles and exports them to a warehouse table for long term
+// analysis, once enough records are pending or the flush interval elapsed. A nil warehouseExport discards records.
+type warehouseExport struct {
+	table     *bigQueryTable
+	batchSize int
+	interval  time.Duration
+	// maxPending bounds the records held while the warehouse is unavailable, dropping the oldest
+	maxPending int
+
+	mutex      sync.Mutex
+	pending    []auditRecord
+	lastExport time.Time
+}
+
+func newWarehouseExport(table *bigQueryTable, batchSize int, interval time.Duration, now time.Time) *warehouseExport {
+	return &warehouseExport{
+		table:      table,
+		batchSize:  batchSize,
+		interval:   interval,
+		maxPending: 10 * batchSize,
+		lastExport: now,
+	}
+}
+
+// parseWarehouse creates the table for a WAREHOUSE_EXPORT value of the form bigquery://project/dataset/table.
+func parseWarehouse(value string) (*bigQueryTable, error) {
+	tableURL, err := url.Parse(value)
+	if err != nil {
+		return nil, err
+	}
+	if tableURL.Scheme != "bigquery" {
+		return nil, fmt.Errorf("unknown warehouse %q, must be bigquery", tableURL.Scheme)
+	}
+	parts := strings.Split(strings.Trim(tableURL.Path, "/"), "/")
+	if tableURL.Host == "" || len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("warehouse must be of the form bigquery://project/dataset/table")
+	}
+	return &bigQueryTable{
+		endpoint: bigQueryEndpoint,
+		project:  tableURL.Host,
+		dataset:  parts[0],
+		table:    parts[1],
+		client:   &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+func (warehouse *warehouseExport) record(record auditRecord) {
+	if warehouse == nil {
+		return
+	}
+	warehouse.mutex.Lock()
+	defer warehouse.mutex.Unlock()
+	warehouse.pending = append(warehouse.pending, record)
+	if dropped := len(warehouse.pending) - warehouse.maxPending; dropped > 0 {
+		logrus.WithField("records", dropped).Warn("dropping warehouse records that could not be exported")
+		warehouse.pending = warehouse.pending[dropped:]
+	}
+}
+
+// flush exports the pending records in batches when a full batch is pending or the interval elapsed since the last
+// export. Records that cannot be exported are kept and retried at the next flush.
+func (warehouse *warehouseExport) flush(now time.Time) {
+	if warehouse == nil {
+		return
+	}
+	warehouse.mutex.Lock()
+	defer warehouse.mutex.Unlock()
+	if len(warehouse.pending) == 0 || (len(warehouse.pending) < warehouse.batchSize && now.Sub(warehouse.lastExport) < warehouse.interval) {
+		return
+	}
+	for len(warehouse.pending) > 0 {
+		batch := warehouse.pending
+		if len(batch) > warehouse.batchSize {
+			batch = batch[:warehouse.batchSize]
+		}
+		retry, err := warehouse.table.insert(batch)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"table":   warehouse.table.name(),
+				"records": len(warehouse.pending),
+			}).WithError(err).Error("unable to export records to the warehouse")
+			return
+		}
+		warehouse.pending = append(retry, warehouse.pending[len(batch):]...)
+		if len(retry) > 0 {
+			// the rejected rows were only stopped by other invalid rows, so they are retried at the next flush
+			break
+		}
+	}
+	warehouse.lastExport = now
+}
+
+// close exports every pending record regardless of the batch size and interval, since pod-reaper is stopping.
+func (warehouse *warehouseExport) close(now time.Time) {
+	if warehouse == nil {
+		return
+	}
+	warehouse.mutex.Lock()
+	warehouse.lastExport = time.Time{}
+	warehouse.mutex.Unlock()
+	warehouse.flush(now)
+}
+
+// bigQueryTable inserts rows into a bigquery table with the streaming insertAll API.
+type bigQueryTable struct {
+	endpoint string
+	project  string
+	dataset  string
+	table    string
+	client   *http.Client
+	tokens   tokenSource
+}
+
+// bigQueryRow is an audit record as inserted, matching the table schema documented in the README.
+type bigQueryRow struct {
+	Time       string   `json:"time"`
+	CycleID    string   `json:"cycle_id"`
+	Pod        string   `json:"pod"`
+	Namespace  string   `json:"namespace"`
+	Reasons    []string `json:"reasons"`
+	Action     string   `json:"action"`
+	Decision   string   `json:"decision"`
+	SkipReason string   `json:"skip_reason"`
+	DryRun     bool     `json:"dry_run"`
+}
+
+func (table *bigQueryTable) name() string {
+	return table.project + "." + table.dataset + "." + table.table
+}
+
+// insert inserts the records, returning the records that were rejected only because other rows in the request were
+// invalid. Invalid records are logged and dropped, since retrying them can never succeed.
+func (table *bigQueryTable) insert(records []auditRecord) ([]auditRecord, error) {
+	type row struct {
+		InsertID string      `json:"insertId"`
+		JSON     bigQueryRow `json:"json"`
+	}
+	rows := make([]row, len(records))
+	for i, record := range records {
+		rows[i] = row{
+			// retried inserts of the same decision are deduplicated by bigquery
+			InsertID: fmt.Sprintf("%s/%s/%s/%s", record.CycleID, record.Namespace, record.Pod, record.Decision),
+			JSON: bigQueryRow{
+				Time:       record.Time.UTC().Format(time.RFC3339Nano),
+				CycleID:    record.CycleID,
+				Pod:        record.Pod,
+				Namespace:  record.Namespace,
+				Reasons:    record.Reasons,
+				Action:     record.Action,
+				Decision:   record.Decision,
+				SkipReason: record.SkipReason,
+				DryRun:     record.DryRun,
+			},
+		}
+	}
+	body, err := json.Marshal(map[string]interface{}{"rows": rows})
+	if err != nil {
+		return nil, err
+	}
+	token, err := table.tokens.token()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get an access token: %s", err)
+	}
+	insertURL := fmt.Sprintf("%s/bigquery/v2/projects/%s/datasets/%s/tables/%s/insertAll",
+		table.endpoint, url.PathEscape(table.project), url.PathEscape(table.dataset), url.PathEscape(table.table))
+	request, err := http.NewRequest(http.MethodPost, insertURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "Bearer "+token)
+	response, err := table.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	content, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return nil, fmt.Errorf("unexpected response status %s", response.Status)
+	}
+	var result struct {
+		InsertErrors []struct {
+			Index  int `json:"index"`
+			Errors []struct {
+				Reason  string `json:"reason"`
+				Message string `json:"message"`
+			} `json:"errors"`
+		} `json:"insertErrors"`
+	}
+	if err := json.Unmarshal(content, &result); err != nil {
+		return nil, fmt.Errorf("invalid insertAll response: %s", err)
+	}
+	var retry []auditRecord
+	for _, insertError := range result.InsertErrors {
+		if insertError.Index < 0 || insertError.Index >= len(records) {
+			continue
+		}
+		invalid := false
+		for _, rowError := range insertError.Errors {
+			if rowError.Reason != "stopped" {
+				invalid = true
+				logrus.WithFields(logrus.Fields{
+					"table":  table.name(),
+					"pod":    records[insertError.Index].Pod,
+					"reason": rowError.Reason,
+				}).Error("warehouse rejected record: " + rowError.Message)
+			}
+		}
+		if !invalid {
+			retry = append(retry, records[insertError.Index])
+		}
+	}
+	return retry, nil
+}
@@ -0,0 +1,163 @@
+package reaper
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type staticToken string
+
+func (token staticToken) token() (string, error) {
+	return string(token), nil
+}
+
+// testBigQuery answers insertAll requests with the response returned by respond, recording the rows of each request.
+type testBigQuery struct {
+	requests [][]map[string]interface{}
+	respond  func(request int, rows int) (int, string)
+}
+
+func (fake *testBigQuery) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	var body struct {
+		Rows []map[string]interface{} `json:"rows"`
+	}
+	if request.Header.Get("Authorization") != "Bearer token" || json.NewDecoder(request.Body).Decode(&body) != nil {
+		http.Error(writer, "bad request", http.StatusBadRequest)
+		return
+	}
+	fake.requests = append(fake.requests, body.Rows)
+	status, response := http.StatusOK, `{"kind":"bigquery#tableDataInsertAllResponse"}`
+	if fake.respond != nil {
+		status, response = fake.respond(len(fake.requests)-1, len(body.Rows))
+	}
+	writer.WriteHeader(status)
+	fmt.Fprint(writer, response)
+}
+
+func testWarehouse(t *testing.T, fake *testBigQuery, batchSize int, now time.Time) *warehouseExport {
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	table := &bigQueryTable{
+		endpoint: server.URL,
+		project:  "project",
+		dataset:  "dataset",
+		table:    "table",
+		client:   &http.Client{Timeout: time.Second},
+		tokens:   staticToken("token"),
+	}
+	return newWarehouseExport(table, batchSize, time.Hour, now)
+}
+
+func testWarehouseRecord(pod string) auditRecord {
+	return auditRecord{
+		Time:      time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC),
+		CycleID:   "cycle",
+		Pod:       pod,
+		Namespace: "default",
+		Reasons:   []string{"reason"},
+		Action:    actionDelete,
+		Decision:  auditReaped,
+	}
+}
+
+func TestParseWarehouse(t *testing.T) {
+	table, err := parseWarehouse("bigquery://project/dataset/table")
+	assert.NoError(t, err)
+	assert.Equal(t, "project.dataset.table", table.name())
+	for _, invalid := range []string{"bigquery://project/dataset", "bigquery://project/dataset/table/extra", "bigquery:///dataset/table", "s3://bucket/prefix"} {
+		_, err := parseWarehouse(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestWarehouseExport(t *testing.T) {
+	now := time.Now()
+
+	t.Run("nil", func(t *testing.T) {
+		var warehouse *warehouseExport
+		warehouse.record(testWarehouseRecord("pod"))
+		warehouse.flush(now)
+	})
+	t.Run("waits for a full batch", func(t *testing.T) {
+		fake := &testBigQuery{}
+		warehouse := testWarehouse(t, fake, 2, now)
+		warehouse.record(testWarehouseRecord("pod-a"))
+		warehouse.flush(now)
+		assert.Empty(t, fake.requests)
+
+		warehouse.record(testWarehouseRecord("pod-b"))
+		warehouse.record(testWarehouseRecord("pod-c"))
+		warehouse.flush(now)
+		assert.Len(t, fake.requests, 2)
+		assert.Len(t, fake.requests[0], 2)
+		assert.Len(t, fake.requests[1], 1)
+		assert.Empty(t, warehouse.pending)
+
+		row := fake.requests[0][0]
+		assert.Equal(t, "cycle/default/pod-a/reaped", row["insertId"])
+		assert.Equal(t, map[string]interface{}{
+			"time":        "2026-03-04T05:06:07Z",
+			"cycle_id":    "cycle",
+			"pod":         "pod-a",
+			"namespace":   "default",
+			"reasons":     []interface{}{"reason"},
+			"action":      "delete",
+			"decision":    "reaped",
+			"skip_reason": "",
+			"dry_run":     false,
+		}, row["json"])
+	})
+	t.Run("exports a partial batch after the interval", func(t *testing.T) {
+		fake := &testBigQuery{}
+		warehouse := testWarehouse(t, fake, 10, now)
+		warehouse.record(testWarehouseRecord("pod"))
+		warehouse.flush(now.Add(59 * time.Minute))
+		assert.Empty(t, fake.requests)
+		warehouse.flush(now.Add(time.Hour))
+		assert.Len(t, fake.requests, 1)
+	})
+	t.Run("keeps records when the export fails", func(t *testing.T) {
+		fake := &testBigQuery{respond: func(request int, _ int) (int, string) {
+			if request == 0 {
+				return http.StatusServiceUnavailable, `{}`
+			}
+			return http.StatusOK, `{}`
+		}}
+		warehouse := testWarehouse(t, fake, 1, now)
+		warehouse.record(testWarehouseRecord("pod"))
+		warehouse.flush(now)
+		assert.Len(t, warehouse.pending, 1)
+		warehouse.flush(now)
+		assert.Empty(t, warehouse.pending)
+		assert.Len(t, fake.requests, 2)
+	})
+	t.Run("drops invalid rows and retries stopped rows", func(t *testing.T) {
+		fake := &testBigQuery{respond: func(request int, _ int) (int, string) {
+			if request == 0 {
+				return http.StatusOK, `{"insertErrors":[{"index":0,"errors":[{"reason":"invalid","message":"no such field"}]},{"index":1,"errors":[{"reason":"stopped"}]}]}`
+			}
+			return http.StatusOK, `{}`
+		}}
+		warehouse := testWarehouse(t, fake, 2, now)
+		warehouse.record(testWarehouseRecord("pod-a"))
+		warehouse.record(testWarehouseRecord("pod-b"))
+		warehouse.flush(now)
+		assert.Len(t, fake.requests, 1)
+		assert.Len(t, warehouse.pending, 1)
+		assert.Equal(t, "pod-b", warehouse.pending[0].Pod)
+	})
+	t.Run("bounds pending records", func(t *testing.T) {
+		warehouse := testWarehouse(t, &testBigQuery{}, 1, now)
+		for i := 0; i < 12; i++ {
+			warehouse.record(testWarehouseRecord(fmt.Sprintf("pod-%d", i)))
+		}
+		assert.Len(t, warehouse.pending, 10)
+		assert.Equal(t, "pod-2", warehouse.pending[0].Pod)
+	})
+}
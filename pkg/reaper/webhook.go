@@ -0,0 +1,123 @@
+package reaper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const defaultNotifyTimeout = "5s"
+const defaultNotifyRetries = 2
+
+var _ notifier = (*webhookNotifier)(nil)
+var _ flusher = (*webhookNotifier)(nil)
+
+// webhookNotifier POSTs each reap notification as JSON to an HTTP endpoint. Notifications are queued while pods are
+// reaped and posted when the reap cycle ends, so that a slow or failing endpoint does not hold up reaping.
+type webhookNotifier struct {
+	url        string
+	client     *http.Client
+	retries    int
+	retryDelay time.Duration
+
+	mutex   sync.Mutex
+	pending []reapNotification
+}
+
+func newWebhookNotifier(url string, timeout time.Duration, retries int) *webhookNotifier {
+	return &webhookNotifier{
+		url:        url,
+		client:     &http.Client{Timeout: timeout},
+		retries:    retries,
+		retryDelay: time.Second,
+	}
+}
+
+func (webhook *webhookNotifier) name() string {
+	return "webhook"
+}
+
+func (webhook *webhookNotifier) notify(notification reapNotification) error {
+	webhook.mutex.Lock()
+	defer webhook.mutex.Unlock()
+	webhook.pending = append(webhook.pending, notification)
+	return nil
+}
+
+// flush posts the notifications queued since the last flush, one request each. Notifications that still fail once
+// their retries are exhausted, or that are not sent before ctx is cancelled, are dropped.
+func (webhook *webhookNotifier) flush(ctx context.Context) error {
+	webhook.mutex.Lock()
+	pending := webhook.pending
+	webhook.pending = nil
+	webhook.mutex.Unlock()
+	failed := 0
+	var lastErr error
+	for _, notification := range pending {
+		if err := webhook.send(ctx, notification); err != nil {
+			failed++
+			lastErr = err
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d notifications were not sent: %s", failed, len(pending), lastErr)
+	}
+	return nil
+}
+
+func (webhook *webhookNotifier) send(ctx context.Context, notification reapNotification) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+	return postWithRetries(ctx, webhook.client, webhook.url, body, webhook.retries, webhook.retryDelay)
+}
+
+// postWithRetries POSTs the JSON body, retrying failed requests with a linearly increasing delay. It gives up as soon
+// as ctx is cancelled, including while waiting to retry.
+func postWithRetries(ctx context.Context, client *http.Client, url string, body []byte, retries int, retryDelay time.Duration) error {
+	var err error
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 && !waitRetry(ctx, time.Duration(attempt)*retryDelay) {
+			return fmt.Errorf("gave up after %d attempts: %s", attempt, err)
+		}
+		if err = post(ctx, client, url, body); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("failed after %d attempts: %s", retries+1, err)
+}
+
+// waitRetry waits for the delay before retrying a request and returns true, or returns false early if ctx is
+// cancelled.
+func waitRetry(ctx context.Context, delay time.Duration) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func post(ctx context.Context, client *http.Client, url string, body []byte) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("unexpected response status %s", response.Status)
+	}
+	return nil
+}
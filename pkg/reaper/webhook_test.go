@@ -0,0 +1,163 @@
+package reaper
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testWebhookServer(t *testing.T, failures int32, received chan<- reapNotification) *httptest.Server {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, http.MethodPost, request.Method)
+		assert.Equal(t, "application/json", request.Header.Get("Content-Type"))
+		if atomic.AddInt32(&attempts, 1) <= failures {
+			writer.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		var notification reapNotification
+		assert.NoError(t, json.NewDecoder(request.Body).Decode(&notification))
+		received <- notification
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	notification := reapNotification{
+		Pod:       "test-pod",
+		Namespace: "default",
+		Reasons:   []string{"reason"},
+		Action:    actionDelete,
+		DryRun:    true,
+		Timestamp: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("success", func(t *testing.T) {
+		received := make(chan reapNotification, 1)
+		server := testWebhookServer(t, 0, received)
+		webhook := newWebhookNotifier(server.URL, time.Second, 0)
+
+		assert.NoError(t, webhook.notify(notification))
+		assert.NoError(t, webhook.flush(context.Background()))
+		assert.Equal(t, notification, <-received)
+	})
+
+	t.Run("retries", func(t *testing.T) {
+		received := make(chan reapNotification, 1)
+		server := testWebhookServer(t, 2, received)
+		webhook := newWebhookNotifier(server.URL, time.Second, 2)
+		webhook.retryDelay = time.Millisecond
+
+		assert.NoError(t, webhook.notify(notification))
+		assert.NoError(t, webhook.flush(context.Background()))
+		assert.Equal(t, notification, <-received)
+	})
+
+	t.Run("retries exhausted", func(t *testing.T) {
+		server := testWebhookServer(t, 3, nil)
+		webhook := newWebhookNotifier(server.URL, time.Second, 2)
+		webhook.retryDelay = time.Millisecond
+
+		assert.NoError(t, webhook.notify(notification))
+		assert.Error(t, webhook.flush(context.Background()))
+	})
+
+	t.Run("queued until flushed", func(t *testing.T) {
+		received := make(chan reapNotification, 2)
+		server := testWebhookServer(t, 0, received)
+		webhook := newWebhookNotifier(server.URL, time.Second, 0)
+
+		assert.NoError(t, webhook.notify(notification))
+		assert.NoError(t, webhook.notify(notification))
+		assert.Empty(t, received)
+		assert.NoError(t, webhook.flush(context.Background()))
+		assert.Len(t, received, 2)
+		assert.NoError(t, webhook.flush(context.Background()))
+		assert.Len(t, received, 2)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			time.Sleep(100 * time.Millisecond)
+		}))
+		t.Cleanup(server.Close)
+		webhook := newWebhookNotifier(server.URL, 10*time.Millisecond, 0)
+
+		assert.NoError(t, webhook.notify(notification))
+		assert.Error(t, webhook.flush(context.Background()))
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		server := testWebhookServer(t, 100, nil)
+		webhook := newWebhookNotifier(server.URL, time.Second, 2)
+		webhook.retryDelay = time.Hour
+
+		assert.NoError(t, webhook.notify(notification))
+		assert.NoError(t, webhook.notify(notification))
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		assert.Error(t, webhook.flush(ctx))
+		// the delay between retries is abandoned with the context
+		assert.Less(t, time.Since(start), time.Second)
+	})
+}
+
+func TestReapPodWebhook(t *testing.T) {
+	t.Run("reaped", func(t *testing.T) {
+		received := make(chan reapNotification, 1)
+		server := testWebhookServer(t, 0, received)
+		startTime := time.Now()
+		pod := createTestPod("test-pod", "default", &startTime)
+		opts := minimalOptions("0.0")
+		opts.notifiers = []notifier{newWebhookNotifier(server.URL, time.Second, 0)}
+		r := createTestReaper(opts, pod)
+
+		r.reapPod(pod, []string{"reason"}, 0)
+		r.flushNotifiers()
+
+		notification := <-received
+		assert.Equal(t, "test-pod", notification.Pod)
+		assert.Equal(t, "default", notification.Namespace)
+		assert.Equal(t, []string{"reason"}, notification.Reasons)
+		assert.Equal(t, actionDelete, notification.Action)
+		assert.False(t, notification.DryRun)
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		received := make(chan reapNotification, 1)
+		server := testWebhookServer(t, 0, received)
+		startTime := time.Now()
+		pod := createTestPod("test-pod", "default", &startTime)
+		opts := minimalOptions("0.0")
+		opts.dryRun = true
+		opts.notifiers = []notifier{newWebhookNotifier(server.URL, time.Second, 0)}
+		r := createTestReaper(opts, pod)
+
+		r.reapPod(pod, []string{"reason"}, 0)
+		r.flushNotifiers()
+
+		assert.True(t, (<-received).DryRun)
+	})
+
+	t.Run("failure does not panic", func(t *testing.T) {
+		server := testWebhookServer(t, 1, nil)
+		startTime := time.Now()
+		pod := createTestPod("test-pod", "default", &startTime)
+		opts := minimalOptions("0.0")
+		opts.notifiers = []notifier{newWebhookNotifier(server.URL, time.Second, 0)}
+		r := createTestReaper(opts, pod)
+
+		assert.NotPanics(t, func() {
+			r.reapPod(pod, []string{"reason"}, 0)
+			r.flushNotifiers()
+		})
+	})
+}
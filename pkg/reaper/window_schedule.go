@@ -0,0 +1,208 @@
+package reaper
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	// the image is built from scratch, without a timezone database for the timezones of windows
+	_ "time/tzdata"
+
+	"github.com/robfig/cron/v3"
+)
+
+// scheduleWindow is a recurring period of time, such as a window of BLACKOUT_SCHEDULE during which reap cycles do not
+// reap pods.
+type scheduleWindow interface {
+	contains(t time.Time) bool
+	String() string
+}
+
+// windowSchedule holds recurring windows of time. An empty schedule contains no time.
+type windowSchedule []scheduleWindow
+
+// parseWindowSchedule parses semicolon-separated windows, each either a cron schedule the window starts on and how
+// long it lasts, as in "0 18 * * FRI for 62h", or a daily time range with optional days and timezone, as in
+// "Mon-Fri 22:00-06:00 Europe/Berlin".
+func parseWindowSchedule(value string) (windowSchedule, error) {
+	var schedule windowSchedule
+	for _, text := range strings.Split(value, ";") {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+		var window scheduleWindow
+		var err error
+		if strings.Contains(text, " for ") {
+			window, err = parseCronWindow(text)
+		} else {
+			window, err = parseRangeWindow(text)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("window %q: %s", text, err)
+		}
+		schedule = append(schedule, window)
+	}
+	return schedule, nil
+}
+
+// active returns the window the time is in, if any.
+func (schedule windowSchedule) active(t time.Time) (scheduleWindow, bool) {
+	for _, window := range schedule {
+		if window.contains(t) {
+			return window, true
+		}
+	}
+	return nil, false
+}
+
+// cronWindow starts on every activation of a cron schedule and lasts a duration. The schedule may set its timezone
+// with a CRON_TZ= prefix.
+type cronWindow struct {
+	text     string
+	schedule cron.Schedule
+	duration time.Duration
+}
+
+func parseCronWindow(text string) (cronWindow, error) {
+	index := strings.LastIndex(text, " for ")
+	expression, durationText := strings.TrimSpace(text[:index]), strings.TrimSpace(text[index+len(" for "):])
+	schedule, err := scheduleParser.Parse(expression)
+	if err != nil {
+		return cronWindow{}, err
+	}
+	duration, err := time.ParseDuration(durationText)
+	if err != nil {
+		return cronWindow{}, err
+	}
+	if duration <= 0 {
+		return cronWindow{}, fmt.Errorf("duration must be positive")
+	}
+	return cronWindow{text: text, schedule: schedule, duration: duration}, nil
+}
+
+// contains returns whether the schedule activated within the window's duration before the time, which is the case
+// when its first activation after the start of that duration is not after the time.
+func (window cronWindow) contains(t time.Time) bool {
+	return !window.schedule.Next(t.Add(-window.duration)).After(t)
+}
+
+func (window cronWindow) String() string {
+	return window.text
+}
+
+// rangeWindow is a time of day range on some days of the week, in a timezone. A range that ends before it starts
+// runs overnight, and its days are the days it starts on.
+type rangeWindow struct {
+	text     string
+	days     [7]bool
+	start    int
+	end      int
+	location *time.Location
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+func parseRangeWindow(text string) (rangeWindow, error) {
+	window := rangeWindow{text: text, location: time.Local}
+	fields := strings.Fields(text)
+	rangeIndex := -1
+	for i, field := range fields {
+		if strings.Contains(field, ":") {
+			rangeIndex = i
+			break
+		}
+	}
+	if rangeIndex < 0 || rangeIndex > 1 || len(fields) > rangeIndex+2 {
+		return window, fmt.Errorf("must be of the form [days] HH:MM-HH:MM [timezone] or <cron schedule> for <duration>")
+	}
+	if rangeIndex == 1 {
+		days, err := parseWeekdays(fields[0])
+		if err != nil {
+			return window, err
+		}
+		window.days = days
+	} else {
+		for day := range window.days {
+			window.days[day] = true
+		}
+	}
+	startText, endText, found := strings.Cut(fields[rangeIndex], "-")
+	if !found {
+		return window, fmt.Errorf("time range must be of the form HH:MM-HH:MM")
+	}
+	var err error
+	if window.start, err = parseTimeOfDay(startText); err != nil {
+		return window, err
+	}
+	if window.end, err = parseTimeOfDay(endText); err != nil {
+		return window, err
+	}
+	if window.start == window.end {
+		return window, fmt.Errorf("time range must not be empty")
+	}
+	if len(fields) > rangeIndex+1 {
+		if window.location, err = time.LoadLocation(fields[rangeIndex+1]); err != nil {
+			return window, err
+		}
+	}
+	return window, nil
+}
+
+// parseWeekdays parses comma-separated days or day ranges, such as "Mon-Fri" or "Sat,Sun".
+func parseWeekdays(text string) ([7]bool, error) {
+	var days [7]bool
+	for _, part := range strings.Split(text, ",") {
+		firstText, lastText, isRange := strings.Cut(part, "-")
+		first, ok := weekdays[strings.ToLower(firstText)]
+		if !ok {
+			return days, fmt.Errorf("unknown day %q", firstText)
+		}
+		last := first
+		if isRange {
+			if last, ok = weekdays[strings.ToLower(lastText)]; !ok {
+				return days, fmt.Errorf("unknown day %q", lastText)
+			}
+		}
+		// ranges wrap around the week, as in Fri-Mon
+		for day := first; ; day = (day + 1) % 7 {
+			days[day] = true
+			if day == last {
+				break
+			}
+		}
+	}
+	return days, nil
+}
+
+// parseTimeOfDay parses HH:MM into minutes since midnight.
+func parseTimeOfDay(text string) (int, error) {
+	parsed, err := time.Parse("15:04", text)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q", text)
+	}
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
+
+func (window rangeWindow) contains(t time.Time) bool {
+	local := t.In(window.location)
+	minute := local.Hour()*60 + local.Minute()
+	day := local.Weekday()
+	if window.start < window.end {
+		return window.days[day] && minute >= window.start && minute < window.end
+	}
+	// overnight ranges continue into the morning after the days they start on
+	previous := (day + 6) % 7
+	return window.days[day] && minute >= window.start || window.days[previous] && minute < window.end
+}
+
+func (window rangeWindow) String() string {
+	return window.text
+}
@@ -0,0 +1,114 @@
+package reaper
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestWindowSchedule(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	assert.NoError(t, err)
+	// Friday, January 5th 2024
+	friday := func(hour int, minute int) time.Time {
+		return time.Date(2024, 1, 5, hour, minute, 0, 0, time.UTC)
+	}
+
+	t.Run("range", func(t *testing.T) {
+		schedule, err := parseWindowSchedule("09:00-17:00 UTC")
+		assert.NoError(t, err)
+		_, active := schedule.active(friday(9, 0))
+		assert.True(t, active)
+		_, active = schedule.active(friday(16, 59))
+		assert.True(t, active)
+		_, active = schedule.active(friday(17, 0))
+		assert.False(t, active)
+	})
+	t.Run("overnight range continues on the next day", func(t *testing.T) {
+		schedule, err := parseWindowSchedule("Fri 22:00-06:00 UTC")
+		assert.NoError(t, err)
+		_, active := schedule.active(friday(23, 0))
+		assert.True(t, active)
+		_, active = schedule.active(friday(23, 0).Add(6 * time.Hour))
+		assert.True(t, active)
+		_, active = schedule.active(friday(23, 0).Add(7 * time.Hour))
+		assert.False(t, active)
+		// the window starts on fridays only
+		_, active = schedule.active(friday(5, 0))
+		assert.False(t, active)
+	})
+	t.Run("days", func(t *testing.T) {
+		schedule, err := parseWindowSchedule("Sat,Sun 00:00-23:59 UTC; Mon-Thu 12:00-13:00 UTC")
+		assert.NoError(t, err)
+		_, active := schedule.active(friday(12, 30))
+		assert.False(t, active)
+		window, active := schedule.active(friday(12, 30).Add(24 * time.Hour))
+		assert.True(t, active)
+		assert.Equal(t, "Sat,Sun 00:00-23:59 UTC", window.String())
+		_, active = schedule.active(friday(12, 30).Add(-24 * time.Hour))
+		assert.True(t, active)
+	})
+	t.Run("days wrap around the week", func(t *testing.T) {
+		days, err := parseWeekdays("fri-mon")
+		assert.NoError(t, err)
+		assert.Equal(t, [7]bool{true, true, false, false, false, true, true}, days)
+	})
+	t.Run("timezone", func(t *testing.T) {
+		schedule, err := parseWindowSchedule("Fri 17:00-23:59 America/Chicago")
+		assert.NoError(t, err)
+		_, active := schedule.active(time.Date(2024, 1, 5, 18, 0, 0, 0, chicago))
+		assert.True(t, active)
+		_, active = schedule.active(friday(18, 0))
+		assert.False(t, active)
+	})
+	t.Run("cron", func(t *testing.T) {
+		schedule, err := parseWindowSchedule("CRON_TZ=UTC 0 18 * * FRI for 62h")
+		assert.NoError(t, err)
+		_, active := schedule.active(friday(18, 0))
+		assert.True(t, active)
+		_, active = schedule.active(friday(18, 0).Add(61 * time.Hour))
+		assert.True(t, active)
+		_, active = schedule.active(friday(18, 0).Add(62 * time.Hour))
+		assert.False(t, active)
+		_, active = schedule.active(friday(17, 59))
+		assert.False(t, active)
+	})
+	t.Run("empty", func(t *testing.T) {
+		var schedule windowSchedule
+		_, active := schedule.active(friday(12, 0))
+		assert.False(t, active)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		for _, value := range []string{
+			"tomorrow",
+			"Someday 09:00-17:00",
+			"09:00-17:00 Mars/Olympus",
+			"9-17",
+			"25:00-26:00",
+			"09:00-09:00",
+			"Mon 09:00-17:00 UTC extra",
+			"0 18 * * FRI for soon",
+			"0 18 * * FRI for -1h",
+			"not cron for 1h",
+		} {
+			_, err := parseWindowSchedule(value)
+			assert.Error(t, err, value)
+		}
+	})
+}
+
+func TestBlackoutCycle(t *testing.T) {
+	schedule, err := parseWindowSchedule("00:00-23:59; 23:59-00:00")
+	assert.NoError(t, err)
+	opts := minimalOptions("1.0")
+	opts.blackout = schedule
+	reaper := createTestReaper(opts, createTestPod("pod", "default", nil))
+
+	assert.NoError(t, reaper.scytheCycle())
+
+	pods, _ := reaper.clientSet.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+	assert.Len(t, pods.Items, 1)
+}
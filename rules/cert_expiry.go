@@ -0,0 +1,58 @@
+package rules
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+)
+
+const envCertExpiryWindow = "CERT_EXPIRY_WINDOW"
+const envCertExpiryAnnotation = "CERT_EXPIRY_ANNOTATION"
+
+const defaultCertExpiryAnnotation = "cert-expiry"
+
+var _ Rule = (*certExpiry)(nil)
+
+type certExpiry struct {
+	window     time.Duration
+	annotation string
+}
+
+func (rule *certExpiry) Load(lookup LookupFunc) (bool, string, error) {
+	value, active := lookup(envCertExpiryWindow)
+	if !active {
+		return false, "", nil
+	}
+	window, err := parseDuration(value)
+	if err != nil {
+		return false, "", fmt.Errorf("invalid cert expiry window: %s", err)
+	}
+	rule.window = window
+	rule.annotation = defaultCertExpiryAnnotation
+	if annotation, exists := lookup(envCertExpiryAnnotation); exists && annotation != "" {
+		rule.annotation = annotation
+	}
+	return true, fmt.Sprintf("certificate expiry in %s annotation within %s", rule.annotation, value), nil
+}
+
+func (rule *certExpiry) ShouldReap(pod v1.Pod) (bool, string) {
+	value, exists := pod.Annotations[rule.annotation]
+	if !exists {
+		return false, ""
+	}
+	expiry, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"pod":        pod.Name,
+			"annotation": rule.annotation,
+		}).WithError(err).Warn("invalid certificate expiry annotation")
+		return false, ""
+	}
+	remaining := expiry.Sub(time.Now())
+	if remaining <= 0 {
+		return true, fmt.Sprintf("has a certificate that expired %s ago", (-remaining).Truncate(time.Second))
+	}
+	return remaining <= rule.window, fmt.Sprintf("has a certificate expiring in %s", remaining.Truncate(time.Second))
+}
@@ -0,0 +1,83 @@
+package rules
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testCertExpiryPod(annotation string, expiry string) v1.Pod {
+	return v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test",
+			Annotations: map[string]string{annotation: expiry},
+		},
+	}
+}
+
+func TestCertExpiryLoad(t *testing.T) {
+	t.Run("load", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envCertExpiryWindow, "1h")
+		rule := certExpiry{}
+		loaded, message, err := rule.Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.True(t, loaded)
+		assert.Equal(t, "certificate expiry in cert-expiry annotation within 1h", message)
+	})
+	t.Run("custom annotation", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envCertExpiryWindow, "1h")
+		os.Setenv(envCertExpiryAnnotation, "example.com/cert-expiry")
+		rule := certExpiry{}
+		_, _, err := rule.Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.Equal(t, "example.com/cert-expiry", rule.annotation)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envCertExpiryWindow, "soon")
+		_, _, err := (&certExpiry{}).Load(os.LookupEnv)
+		assert.Error(t, err)
+	})
+	t.Run("no load", func(t *testing.T) {
+		os.Clearenv()
+		loaded, message, err := (&certExpiry{}).Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.Equal(t, "", message)
+		assert.False(t, loaded)
+	})
+}
+
+func TestCertExpiryShouldReap(t *testing.T) {
+	rule := certExpiry{window: time.Hour, annotation: defaultCertExpiryAnnotation}
+	t.Run("within window", func(t *testing.T) {
+		pod := testCertExpiryPod(defaultCertExpiryAnnotation, time.Now().Add(30*time.Minute).Format(time.RFC3339))
+		shouldReap, reason := rule.ShouldReap(pod)
+		assert.True(t, shouldReap)
+		assert.Regexp(t, "^has a certificate expiring in (29|30)m", reason)
+	})
+	t.Run("expired", func(t *testing.T) {
+		pod := testCertExpiryPod(defaultCertExpiryAnnotation, time.Now().Add(-time.Minute).Format(time.RFC3339))
+		shouldReap, reason := rule.ShouldReap(pod)
+		assert.True(t, shouldReap)
+		assert.Contains(t, reason, "has a certificate that expired")
+	})
+	t.Run("outside window", func(t *testing.T) {
+		pod := testCertExpiryPod(defaultCertExpiryAnnotation, time.Now().Add(48*time.Hour).Format(time.RFC3339))
+		shouldReap, _ := rule.ShouldReap(pod)
+		assert.False(t, shouldReap)
+	})
+	t.Run("invalid annotation", func(t *testing.T) {
+		shouldReap, _ := rule.ShouldReap(testCertExpiryPod(defaultCertExpiryAnnotation, "tomorrow"))
+		assert.False(t, shouldReap)
+	})
+	t.Run("no annotation", func(t *testing.T) {
+		shouldReap, _ := rule.ShouldReap(testCertExpiryPod("other", time.Now().Format(time.RFC3339)))
+		assert.False(t, shouldReap)
+	})
+}
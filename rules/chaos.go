@@ -3,33 +3,139 @@ package rules
 import (
 	"fmt"
 	"math/rand"
-	"os"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 const envChaosChance = "CHAOS_CHANCE"
+const envChaosOwnerChance = "CHAOS_OWNER_CHANCE"
+const envChaosNodeChance = "CHAOS_NODE_CHANCE"
+
+// the decision to flag every pod of an owner or a node is kept this long, so that all of its pods evaluated in the
+// same reap cycle share it, and is then made again
+const groupChaosTTL = 30 * time.Second
 
 var _ Rule = (*chaos)(nil)
 
 type chaos struct {
 	chance float64
+	// ownerChance is the chance that every pod of a controller owner is flagged at once
+	ownerChance float64
+	owners      chaosGroups
+	// nodeChance is the chance that every pod on a node is flagged at once, simulating the failure of the node
+	nodeChance float64
+	nodes      chaosGroups
 }
 
-func (rule *chaos) load() (bool, string, error) {
-	value, active := os.LookupEnv(envChaosChance)
-	if !active {
+func (rule *chaos) Load(lookup LookupFunc) (bool, string, error) {
+	var messages []string
+	value, active := lookup(envChaosChance)
+	if active {
+		chance, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return false, "", fmt.Errorf("invalid chaos chance %s", err)
+		}
+		rule.chance = chance
+		messages = append(messages, fmt.Sprintf("chaos chance %s", value))
+	}
+	for _, group := range []struct {
+		key    string
+		name   string
+		chance *float64
+	}{
+		{envChaosOwnerChance, "owner", &rule.ownerChance},
+		{envChaosNodeChance, "node", &rule.nodeChance},
+	} {
+		value, exists := lookup(group.key)
+		if !exists {
+			continue
+		}
+		chance, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return false, "", fmt.Errorf("invalid %s: %s", group.key, err)
+		}
+		*group.chance = chance
+		messages = append(messages, fmt.Sprintf("%s chaos chance %s", group.name, value))
+	}
+	// a single node fails at a time
+	rule.nodes.single = true
+	if len(messages) == 0 {
 		return false, "", nil
 	}
-	chance, err := strconv.ParseFloat(value, 64)
-	if err != nil {
-		return false, "", fmt.Errorf("invalid chaos chance %s", err)
+	return true, strings.Join(messages, ", "), nil
+}
+
+func (rule *chaos) sweeping() string {
+	if rule.chance < 1 {
+		return ""
 	}
-	rule.chance = chance
-	return true, fmt.Sprintf("chaos chance %s", value), nil
+	return fmt.Sprintf("chaos chance %s flags every pod", strconv.FormatFloat(rule.chance, 'f', -1, 64))
 }
 
 func (rule *chaos) ShouldReap(pod v1.Pod) (bool, string) {
+	if owner := metav1.GetControllerOf(&pod); owner != nil && rule.ownerChance > 0 {
+		description := fmt.Sprintf("%s/%s", owner.Kind, owner.Name)
+		if rule.owners.flagged(pod.Namespace+"/"+description, rule.ownerChance, time.Now()) {
+			return true, fmt.Sprintf("was flagged for chaos with every pod of %s", description)
+		}
+	}
+	if pod.Spec.NodeName != "" && rule.nodeChance > 0 {
+		if rule.nodes.flagged(pod.Spec.NodeName, rule.nodeChance, time.Now()) {
+			return true, fmt.Sprintf("was flagged for chaos with every pod on node %s", pod.Spec.NodeName)
+		}
+	}
 	return rand.Float64() < rule.chance, "was flagged for chaos"
 }
+
+// chaosGroups remembers, for a short while, whether every pod of each group, such as the pods of an owner or on a
+// node, is flagged. The zero value is ready to use.
+type chaosGroups struct {
+	// single allows only one group to be flagged at a time
+	single    bool
+	mutex     sync.Mutex
+	decisions map[string]groupDecision
+	pruned    time.Time
+	// lastFlagged is when a group was last decided to be flagged
+	lastFlagged time.Time
+}
+
+type groupDecision struct {
+	flagged bool
+	decided time.Time
+}
+
+// flagged returns whether every pod of the group is flagged, deciding again with the chance once the previous
+// decision has expired.
+func (groups *chaosGroups) flagged(group string, chance float64, now time.Time) bool {
+	groups.mutex.Lock()
+	defer groups.mutex.Unlock()
+	if groups.decisions == nil {
+		groups.decisions = map[string]groupDecision{}
+	}
+	decision, exists := groups.decisions[group]
+	if exists && now.Sub(decision.decided) < groupChaosTTL {
+		return decision.flagged
+	}
+	if now.Sub(groups.pruned) >= observationPruneInterval {
+		groups.pruned = now
+		for key, expired := range groups.decisions {
+			if now.Sub(expired.decided) >= groupChaosTTL {
+				delete(groups.decisions, key)
+			}
+		}
+	}
+	decision = groupDecision{decided: now}
+	if !groups.single || now.Sub(groups.lastFlagged) >= groupChaosTTL {
+		decision.flagged = rand.Float64() < chance
+	}
+	if decision.flagged {
+		groups.lastFlagged = now
+	}
+	groups.decisions[group] = decision
+	return decision.flagged
+}
@@ -4,23 +4,34 @@ import (
 	"math"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+func testChaosPod(name string, owner string) v1.Pod {
+	controller := true
+	return v1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:            name,
+		Namespace:       "default",
+		OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: owner, Controller: &controller}},
+	}}
+}
+
 func TestChaosLoad(t *testing.T) {
 	t.Run("load", func(t *testing.T) {
 		os.Clearenv()
 		os.Setenv(envChaosChance, "0.5")
-		loaded, message, err := (&chaos{}).load()
+		loaded, message, err := (&chaos{}).Load(os.LookupEnv)
 		assert.NoError(t, err)
 		assert.Equal(t, "chaos chance 0.5", message)
 		assert.True(t, loaded)
 	})
 	t.Run("no load", func(t *testing.T) {
 		os.Clearenv()
-		loaded, message, err := (&chaos{}).load()
+		loaded, message, err := (&chaos{}).Load(os.LookupEnv)
 		assert.NoError(t, err)
 		assert.Equal(t, "", message)
 		assert.False(t, loaded)
@@ -28,7 +39,7 @@ func TestChaosLoad(t *testing.T) {
 	t.Run("invalid chance", func(t *testing.T) {
 		os.Clearenv()
 		os.Setenv(envChaosChance, "not-a-number")
-		loaded, message, err := (&chaos{}).load()
+		loaded, message, err := (&chaos{}).Load(os.LookupEnv)
 		assert.Error(t, err)
 		assert.Equal(t, "", message)
 		assert.False(t, loaded)
@@ -37,7 +48,7 @@ func TestChaosLoad(t *testing.T) {
 		os.Clearenv()
 		os.Setenv(envChaosChance, "-0.5")
 		c := chaos{}
-		loaded, message, err := c.load()
+		loaded, message, err := c.Load(os.LookupEnv)
 		assert.NoError(t, err)
 		assert.True(t, loaded)
 		assert.Equal(t, "chaos chance -0.5", message)
@@ -47,16 +58,62 @@ func TestChaosLoad(t *testing.T) {
 		os.Clearenv()
 		os.Setenv(envChaosChance, "2.0")
 		c := chaos{}
-		loaded, message, err := c.load()
+		loaded, message, err := c.Load(os.LookupEnv)
 		assert.NoError(t, err)
 		assert.True(t, loaded)
 		assert.Equal(t, "chaos chance 2.0", message)
 		assert.Equal(t, 2.0, c.chance)
 	})
+	t.Run("owner chance", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envChaosChance, "0.01")
+		os.Setenv(envChaosOwnerChance, "0.001")
+		c := chaos{}
+		loaded, message, err := c.Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.True(t, loaded)
+		assert.Equal(t, "chaos chance 0.01, owner chaos chance 0.001", message)
+		assert.Equal(t, 0.001, c.ownerChance)
+	})
+	t.Run("owner chance only", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envChaosOwnerChance, "0.001")
+		c := chaos{}
+		loaded, message, err := c.Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.True(t, loaded)
+		assert.Equal(t, "owner chaos chance 0.001", message)
+		assert.Equal(t, 0.0, c.chance)
+	})
+	t.Run("node chance", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envChaosNodeChance, "0.01")
+		c := chaos{}
+		loaded, message, err := c.Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.True(t, loaded)
+		assert.Equal(t, "node chaos chance 0.01", message)
+		assert.Equal(t, 0.01, c.nodeChance)
+		assert.True(t, c.nodes.single)
+	})
+	t.Run("invalid node chance", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envChaosNodeChance, "sometimes")
+		loaded, _, err := (&chaos{}).Load(os.LookupEnv)
+		assert.Error(t, err)
+		assert.False(t, loaded)
+	})
+	t.Run("invalid owner chance", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envChaosOwnerChance, "rarely")
+		loaded, _, err := (&chaos{}).Load(os.LookupEnv)
+		assert.Error(t, err)
+		assert.False(t, loaded)
+	})
 	t.Run("whitespace causes parse error", func(t *testing.T) {
 		os.Clearenv()
 		os.Setenv(envChaosChance, " 0.5 ")
-		loaded, message, err := (&chaos{}).load()
+		loaded, message, err := (&chaos{}).Load(os.LookupEnv)
 		assert.Error(t, err)
 		assert.Equal(t, "", message)
 		assert.False(t, loaded)
@@ -68,7 +125,7 @@ func TestChaosShouldReap(t *testing.T) {
 		os.Clearenv()
 		os.Setenv(envChaosChance, "1.0") // always
 		chaos := chaos{}
-		chaos.load()
+		chaos.Load(os.LookupEnv)
 		shouldReap, message := chaos.ShouldReap(v1.Pod{})
 		assert.True(t, shouldReap)
 		assert.Equal(t, "was flagged for chaos", message)
@@ -77,7 +134,7 @@ func TestChaosShouldReap(t *testing.T) {
 		os.Clearenv()
 		os.Setenv(envChaosChance, "0.0") // never
 		chaos := chaos{}
-		chaos.load()
+		chaos.Load(os.LookupEnv)
 		shouldReap, _ := chaos.ShouldReap(v1.Pod{})
 		assert.False(t, shouldReap)
 	})
@@ -121,4 +178,59 @@ func TestChaosShouldReap(t *testing.T) {
 			assert.False(t, shouldReap)
 		}
 	})
+	t.Run("owner chaos flags every pod of the owner", func(t *testing.T) {
+		c := chaos{ownerChance: 1}
+		for _, name := range []string{"web-1", "web-2", "web-3"} {
+			shouldReap, reason := c.ShouldReap(testChaosPod(name, "web"))
+			assert.True(t, shouldReap)
+			assert.Equal(t, "was flagged for chaos with every pod of ReplicaSet/web", reason)
+		}
+		shouldReap, _ := c.ShouldReap(v1.Pod{})
+		assert.False(t, shouldReap, "pods without an owner only use the chaos chance")
+	})
+	t.Run("owner decision is shared until it expires", func(t *testing.T) {
+		c := chaos{ownerChance: 0.5}
+		now := time.Now()
+		flagged := c.owners.flagged("default/ReplicaSet/web", c.ownerChance, now)
+		for i := 0; i < 100; i++ {
+			assert.Equal(t, flagged, c.owners.flagged("default/ReplicaSet/web", c.ownerChance, now.Add(time.Second)))
+		}
+		c.owners.flagged("default/ReplicaSet/api", 0, now.Add(time.Hour))
+		assert.NotContains(t, c.owners.decisions, "default/ReplicaSet/web")
+		assert.False(t, c.owners.flagged("default/ReplicaSet/web", 0, now.Add(time.Hour)))
+	})
+	t.Run("owner not flagged falls back to the chaos chance", func(t *testing.T) {
+		c := chaos{chance: 1, ownerChance: -1}
+		shouldReap, reason := c.ShouldReap(testChaosPod("web-1", "web"))
+		assert.True(t, shouldReap)
+		assert.Equal(t, "was flagged for chaos", reason)
+	})
+	t.Run("node chaos flags every pod on a single node", func(t *testing.T) {
+		c := chaos{nodeChance: 1, nodes: chaosGroups{single: true}}
+		first := testChaosPod("web-1", "web")
+		first.Spec.NodeName = "node-a"
+		shouldReap, reason := c.ShouldReap(first)
+		assert.True(t, shouldReap)
+		assert.Equal(t, "was flagged for chaos with every pod on node node-a", reason)
+
+		second := testChaosPod("api-1", "api")
+		second.Spec.NodeName = "node-a"
+		shouldReap, _ = c.ShouldReap(second)
+		assert.True(t, shouldReap)
+
+		other := testChaosPod("web-2", "web")
+		other.Spec.NodeName = "node-b"
+		shouldReap, _ = c.ShouldReap(other)
+		assert.False(t, shouldReap, "only one node fails at a time")
+
+		shouldReap, _ = c.ShouldReap(testChaosPod("unscheduled", "web"))
+		assert.False(t, shouldReap)
+	})
+	t.Run("another node can fail once the decision expires", func(t *testing.T) {
+		groups := chaosGroups{single: true}
+		now := time.Now()
+		assert.True(t, groups.flagged("node-a", 1, now))
+		assert.False(t, groups.flagged("node-b", 1, now.Add(time.Second)))
+		assert.True(t, groups.flagged("node-b", 1, now.Add(time.Minute)))
+	})
 }
@@ -0,0 +1,104 @@
+package rules
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// contextRule is implemented by rules that make kubernetes API requests. Rules evaluate them with the context set by
+// WithContext, so that their requests are abandoned with the reap cycle.
+type contextRule interface {
+	shouldReapContext(ctx context.Context, pod v1.Pod) (bool, string)
+}
+
+// Cluster holds the clients that rules use to look up objects other than the pod being evaluated, such as nodes,
+// services, or container logs, along with the caches of those objects. Each reaper creates the cluster whose pods it
+// reaps and passes it to its rules with NewContext, so that reapers of different clusters never share clients or
+// cached objects.
+type Cluster struct {
+	client  kubernetes.Interface
+	dynamic dynamic.Interface
+	// apiTimeout bounds each kubernetes API request made by rules, zero for no timeout
+	apiTimeout time.Duration
+	nodes      *nodeCache
+	endpoints  *serviceEndpoints
+	reports    *vulnerabilityReports
+	metrics    *podMetrics
+	jobs       *jobDeadlines
+}
+
+// NewCluster creates the clients of the rules from the kubernetes configuration of the cluster whose pods are reaped.
+// The timeout bounds each kubernetes API request that rules make, so that a hung API server cannot stall the
+// evaluation of a pod. Zero disables the timeout.
+func NewCluster(config *rest.Config, apiTimeout time.Duration) (*Cluster, error) {
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return newCluster(client, dynamicClient, client.Discovery().RESTClient(), apiTimeout), nil
+}
+
+func newCluster(client kubernetes.Interface, dynamicClient dynamic.Interface, metrics rest.Interface, apiTimeout time.Duration) *Cluster {
+	return &Cluster{
+		client:     client,
+		dynamic:    dynamicClient,
+		apiTimeout: apiTimeout,
+		nodes:      newNodeCache(client),
+		endpoints:  newServiceEndpoints(client),
+		reports:    newVulnerabilityReports(dynamicClient),
+		metrics:    newPodMetrics(metrics),
+		jobs:       newJobDeadlines(client),
+	}
+}
+
+type clusterKey struct{}
+
+// NewContext returns a copy of ctx that carries the cluster. Rules evaluated with the context set by WithContext look
+// up objects in that cluster; without one, the rules that look up objects do not flag any pod.
+func NewContext(ctx context.Context, cluster *Cluster) context.Context {
+	return context.WithValue(ctx, clusterKey{}, cluster)
+}
+
+// errNoCluster is returned by the clients of rules evaluated with a context that carries no cluster
+var errNoCluster = errors.New("no kubernetes cluster was set for rules")
+
+// clusterFromContext returns the cluster carried by ctx.
+func clusterFromContext(ctx context.Context) (*Cluster, error) {
+	cluster, _ := ctx.Value(clusterKey{}).(*Cluster)
+	if cluster == nil {
+		return nil, errNoCluster
+	}
+	return cluster, nil
+}
+
+// apiContext returns the context of a kubernetes API request made by a rule, which is cancelled with ctx or after the
+// api timeout of the cluster carried by ctx. The cancel function must be called once the request completes.
+func apiContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	cluster, _ := ctx.Value(clusterKey{}).(*Cluster)
+	if cluster == nil || cluster.apiTimeout == 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, cluster.apiTimeout)
+}
+
+// kubernetesClient returns client, or the client of the cluster carried by ctx when client is nil.
+func kubernetesClient(ctx context.Context, client kubernetes.Interface) (kubernetes.Interface, error) {
+	if client != nil {
+		return client, nil
+	}
+	cluster, err := clusterFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return cluster.client, nil
+}
@@ -0,0 +1,69 @@
+package rules
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+func TestCluster(t *testing.T) {
+	pod := testAffinityPod("node", testNodeSelectorTerm("zone", v1.NodeSelectorOpIn, "a"))
+	t.Run("without cluster", func(t *testing.T) {
+		_, err := kubernetesClient(context.Background(), nil)
+		assert.Equal(t, errNoCluster, err)
+		_, err = kubernetesClient(NewContext(context.Background(), nil), nil)
+		assert.Equal(t, errNoCluster, err)
+		shouldReap, _ := (&nodeAffinity{}).ShouldReap(pod)
+		assert.False(t, shouldReap)
+	})
+	t.Run("config", func(t *testing.T) {
+		cluster, err := NewCluster(&rest.Config{Host: "https://remote.example.com:6443"}, 0)
+		assert.NoError(t, err)
+		client, err := kubernetesClient(NewContext(context.Background(), cluster), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "remote.example.com:6443", client.Discovery().RESTClient().Get().URL().Host)
+		assert.NotNil(t, cluster.dynamic)
+	})
+	t.Run("explicit client", func(t *testing.T) {
+		explicit := fake.NewSimpleClientset()
+		client, err := kubernetesClient(context.Background(), explicit)
+		assert.NoError(t, err)
+		assert.Same(t, explicit, client)
+	})
+	t.Run("rules look up the cluster of their context", func(t *testing.T) {
+		node := v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node", Labels: map[string]string{"zone": "b"}}}
+		first := newCluster(fake.NewSimpleClientset(&node), nil, nil, 0)
+		second := newCluster(fake.NewSimpleClientset(), nil, nil, 0)
+		rules := Rules{LoadedRules: []Rule{&nodeAffinity{}}}
+		shouldReap, _ := rules.WithContext(NewContext(context.Background(), first)).ShouldReap(pod)
+		assert.True(t, shouldReap)
+		// the node cached for the first cluster is not used for the second
+		shouldReap, _ = rules.WithContext(NewContext(context.Background(), second)).ShouldReap(pod)
+		assert.False(t, shouldReap)
+	})
+}
+
+func TestAPIContext(t *testing.T) {
+	ctx, cancel := apiContext(context.Background())
+	_, hasDeadline := ctx.Deadline()
+	assert.False(t, hasDeadline)
+	cancel()
+
+	ctx, cancel = apiContext(NewContext(context.Background(), newCluster(fake.NewSimpleClientset(), nil, nil, 0)))
+	_, hasDeadline = ctx.Deadline()
+	assert.False(t, hasDeadline)
+	cancel()
+
+	cluster := newCluster(fake.NewSimpleClientset(), nil, nil, time.Minute)
+	ctx, cancel = apiContext(NewContext(context.Background(), cluster))
+	defer cancel()
+	deadline, hasDeadline := ctx.Deadline()
+	assert.True(t, hasDeadline)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, time.Second)
+}
@@ -0,0 +1,59 @@
+package rules
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/api/core/v1"
+)
+
+const envMaxCompletedAge = "MAX_COMPLETED_AGE"
+
+var _ Rule = (*completedAge)(nil)
+
+type completedAge struct {
+	duration time.Duration
+}
+
+func (rule *completedAge) Load(lookup LookupFunc) (bool, string, error) {
+	value, active := lookup(envMaxCompletedAge)
+	if !active {
+		return false, "", nil
+	}
+	duration, err := parseDuration(value)
+	if err != nil {
+		return false, "", fmt.Errorf("invalid max completed age: %s", err)
+	}
+	rule.duration = duration
+	return true, fmt.Sprintf("maximum completed age %s", value), nil
+}
+
+func (rule *completedAge) ShouldReap(pod v1.Pod) (bool, string) {
+	if pod.Status.Phase != v1.PodSucceeded {
+		return false, ""
+	}
+	completedAt := completionTime(pod)
+	if completedAt.IsZero() {
+		return false, ""
+	}
+	completedDuration := time.Now().Sub(completedAt)
+	message := fmt.Sprintf("has been completed for %s", completedDuration.String())
+	return completedDuration > rule.duration, message
+}
+
+// completionTime returns the time the last of the pod's containers terminated, or the zero time if any container has
+// not terminated.
+func completionTime(pod v1.Pod) time.Time {
+	var completedAt time.Time
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		terminated := containerStatus.State.Terminated
+		if terminated == nil || terminated.FinishedAt.IsZero() {
+			return time.Time{}
+		}
+		finishedAt := time.Unix(terminated.FinishedAt.Unix(), 0) // convert to standard go time
+		if finishedAt.After(completedAt) {
+			completedAt = finishedAt
+		}
+	}
+	return completedAt
+}
@@ -0,0 +1,79 @@
+package rules
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testCompletedPod(phase v1.PodPhase, finishedAt ...time.Time) v1.Pod {
+	pod := v1.Pod{Status: v1.PodStatus{Phase: phase}}
+	for _, finished := range finishedAt {
+		pod.Status.ContainerStatuses = append(pod.Status.ContainerStatuses, v1.ContainerStatus{
+			State: v1.ContainerState{
+				Terminated: &v1.ContainerStateTerminated{
+					FinishedAt: metav1.NewTime(finished),
+				},
+			},
+		})
+	}
+	return pod
+}
+
+func TestCompletedAgeLoad(t *testing.T) {
+	t.Run("load", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envMaxCompletedAge, "1h")
+		loaded, message, err := (&completedAge{}).Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.Equal(t, "maximum completed age 1h", message)
+		assert.True(t, loaded)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envMaxCompletedAge, "not-a-duration")
+		_, _, err := (&completedAge{}).Load(os.LookupEnv)
+		assert.Error(t, err)
+	})
+	t.Run("no load", func(t *testing.T) {
+		os.Clearenv()
+		loaded, message, err := (&completedAge{}).Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.Equal(t, "", message)
+		assert.False(t, loaded)
+	})
+}
+
+func TestCompletedAgeShouldReap(t *testing.T) {
+	rule := completedAge{duration: time.Hour}
+	t.Run("reap", func(t *testing.T) {
+		pod := testCompletedPod(v1.PodSucceeded, time.Now().Add(-3*time.Hour), time.Now().Add(-2*time.Hour))
+		shouldReap, reason := rule.ShouldReap(pod)
+		assert.True(t, shouldReap)
+		assert.Regexp(t, "^has been completed for 2h", reason)
+	})
+	t.Run("recently completed", func(t *testing.T) {
+		pod := testCompletedPod(v1.PodSucceeded, time.Now().Add(-3*time.Hour), time.Now().Add(-time.Minute))
+		shouldReap, _ := rule.ShouldReap(pod)
+		assert.False(t, shouldReap)
+	})
+	t.Run("not succeeded", func(t *testing.T) {
+		pod := testCompletedPod(v1.PodFailed, time.Now().Add(-3*time.Hour))
+		shouldReap, _ := rule.ShouldReap(pod)
+		assert.False(t, shouldReap)
+	})
+	t.Run("container not terminated", func(t *testing.T) {
+		pod := testCompletedPod(v1.PodSucceeded, time.Now().Add(-3*time.Hour))
+		pod.Status.ContainerStatuses = append(pod.Status.ContainerStatuses, v1.ContainerStatus{})
+		shouldReap, _ := rule.ShouldReap(pod)
+		assert.False(t, shouldReap)
+	})
+	t.Run("no containers", func(t *testing.T) {
+		shouldReap, _ := rule.ShouldReap(testCompletedPod(v1.PodSucceeded))
+		assert.False(t, shouldReap)
+	})
+}
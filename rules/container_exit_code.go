@@ -0,0 +1,61 @@
+package rules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"k8s.io/api/core/v1"
+)
+
+const envContainerExitCodes = "CONTAINER_EXIT_CODES"
+
+var _ Rule = (*containerExitCode)(nil)
+
+type containerExitCode struct {
+	reapExitCodes map[int32]bool
+}
+
+func (rule *containerExitCode) Load(lookup LookupFunc) (bool, string, error) {
+	value, active := lookup(envContainerExitCodes)
+	if !active {
+		return false, "", nil
+	}
+	rule.reapExitCodes = map[int32]bool{}
+	for _, code := range strings.Split(value, ",") {
+		exitCode, err := strconv.ParseInt(strings.TrimSpace(code), 10, 32)
+		if err != nil {
+			return false, "", fmt.Errorf("invalid %s: %s", envContainerExitCodes, err)
+		}
+		rule.reapExitCodes[int32(exitCode)] = true
+	}
+	return true, fmt.Sprintf("container exit code in [%s]", value), nil
+}
+
+func (rule *containerExitCode) ShouldReap(pod v1.Pod) (bool, string) {
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if exitCode, ok := rule.reapExitCode(containerStatus); ok {
+			return true, fmt.Sprintf("has container %s with exit code %d", containerStatus.Name, exitCode)
+		}
+	}
+	for _, initContainerStatus := range pod.Status.InitContainerStatuses {
+		if exitCode, ok := rule.reapExitCode(initContainerStatus); ok {
+			return true, fmt.Sprintf("has init container %s with exit code %d", initContainerStatus.Name, exitCode)
+		}
+	}
+	return false, ""
+}
+
+// reapExitCode returns the exit code of the container's current termination, or its last termination when the
+// container has since been restarted, if that exit code is one of the configured exit codes.
+func (rule *containerExitCode) reapExitCode(containerStatus v1.ContainerStatus) (int32, bool) {
+	for _, terminated := range []*v1.ContainerStateTerminated{
+		containerStatus.State.Terminated,
+		containerStatus.LastTerminationState.Terminated,
+	} {
+		if terminated != nil && rule.reapExitCodes[terminated.ExitCode] {
+			return terminated.ExitCode, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,109 @@
+package rules
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/api/core/v1"
+)
+
+func testExitCodePod(name string, exitCode int32) v1.Pod {
+	return v1.Pod{
+		Status: v1.PodStatus{
+			ContainerStatuses: []v1.ContainerStatus{
+				{
+					Name: name,
+					State: v1.ContainerState{
+						Terminated: &v1.ContainerStateTerminated{
+							ExitCode: exitCode,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestContainerExitCodeLoad(t *testing.T) {
+	t.Run("load", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envContainerExitCodes, "137,143")
+		rule := containerExitCode{}
+		loaded, message, err := rule.Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.Equal(t, "container exit code in [137,143]", message)
+		assert.True(t, loaded)
+		assert.Equal(t, 2, len(rule.reapExitCodes))
+	})
+	t.Run("invalid", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envContainerExitCodes, "137,oom")
+		_, _, err := (&containerExitCode{}).Load(os.LookupEnv)
+		assert.Error(t, err)
+	})
+	t.Run("no load", func(t *testing.T) {
+		os.Clearenv()
+		loaded, message, err := (&containerExitCode{}).Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.Equal(t, "", message)
+		assert.False(t, loaded)
+	})
+}
+
+func TestContainerExitCodeShouldReap(t *testing.T) {
+	t.Run("reap", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envContainerExitCodes, "137, 143")
+		rule := containerExitCode{}
+		rule.Load(os.LookupEnv)
+		shouldReap, reason := rule.ShouldReap(testExitCodePod("app", 143))
+		assert.True(t, shouldReap)
+		assert.Equal(t, "has container app with exit code 143", reason)
+	})
+	t.Run("no reap", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envContainerExitCodes, "137")
+		rule := containerExitCode{}
+		rule.Load(os.LookupEnv)
+		shouldReap, _ := rule.ShouldReap(testExitCodePod("app", 1))
+		assert.False(t, shouldReap)
+		shouldReap, _ = rule.ShouldReap(testStatusPod(testWaitContainerState("CrashLoopBackOff")))
+		assert.False(t, shouldReap)
+	})
+	t.Run("last termination state", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envContainerExitCodes, "137")
+		rule := containerExitCode{}
+		rule.Load(os.LookupEnv)
+		pod := testStatusPod(testWaitContainerState("CrashLoopBackOff"))
+		pod.Status.ContainerStatuses[0].Name = "app"
+		pod.Status.ContainerStatuses[0].LastTerminationState = v1.ContainerState{
+			Terminated: &v1.ContainerStateTerminated{ExitCode: 137},
+		}
+		shouldReap, reason := rule.ShouldReap(pod)
+		assert.True(t, shouldReap)
+		assert.Equal(t, "has container app with exit code 137", reason)
+	})
+	t.Run("init container", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envContainerExitCodes, "1")
+		rule := containerExitCode{}
+		rule.Load(os.LookupEnv)
+		pod := v1.Pod{
+			Status: v1.PodStatus{
+				InitContainerStatuses: []v1.ContainerStatus{
+					{
+						Name: "init",
+						State: v1.ContainerState{
+							Terminated: &v1.ContainerStateTerminated{ExitCode: 1},
+						},
+					},
+				},
+			},
+		}
+		shouldReap, reason := rule.ShouldReap(pod)
+		assert.True(t, shouldReap)
+		assert.Equal(t, "has init container init with exit code 1", reason)
+	})
+}
@@ -2,49 +2,105 @@ package rules
 
 import (
 	"fmt"
-	"os"
+	"regexp"
 	"strings"
 
 	"k8s.io/api/core/v1"
 )
 
 const envContainerStatus = "CONTAINER_STATUSES"
+const envContainerStatusRegex = "CONTAINER_STATUS_REGEX"
 
 var _ Rule = (*containerStatus)(nil)
 
 type containerStatus struct {
 	reapStatuses []string
+	// reapPattern matches statuses in addition to reapStatuses, when set
+	reapPattern     *regexp.Regexp
+	caseInsensitive bool
 }
 
-func (rule *containerStatus) load() (bool, string, error) {
-	value, active := os.LookupEnv(envContainerStatus)
-	if !active {
+func (rule *containerStatus) Load(lookup LookupFunc) (bool, string, error) {
+	value, active := lookup(envContainerStatus)
+	pattern, patternActive := lookup(envContainerStatusRegex)
+	if !active && !patternActive {
 		return false, "", nil
 	}
-	rule.reapStatuses = strings.Split(value, ",")
-	return true, fmt.Sprintf("container status in [%s]", value), nil
+	insensitive, err := caseInsensitive(lookup)
+	if err != nil {
+		return false, "", err
+	}
+	rule.caseInsensitive = insensitive
+	var messages []string
+	if active {
+		statuses, err := loadValues(envContainerStatus, value)
+		if err != nil {
+			return false, "", err
+		}
+		rule.reapStatuses = statuses
+		messages = append(messages, fmt.Sprintf("container status in [%s]", strings.Join(statuses, ",")))
+	}
+	if patternActive {
+		expression := pattern
+		if insensitive {
+			expression = "(?i)" + pattern
+		}
+		compiled, err := regexp.Compile(expression)
+		if err != nil {
+			return false, "", fmt.Errorf("invalid %s: %s", envContainerStatusRegex, err)
+		}
+		rule.reapPattern = compiled
+		messages = append(messages, fmt.Sprintf("container status matching %s", pattern))
+	}
+	return true, strings.Join(messages, " or "), nil
 }
 
 func (rule *containerStatus) ShouldReap(pod v1.Pod) (bool, string) {
 	for _, reapStatus := range rule.reapStatuses {
-		for _, containerStatus := range pod.Status.ContainerStatuses {
-			state := containerStatus.State
-			// check both waiting and terminated conditions
-			if (state.Waiting != nil && state.Waiting.Reason == reapStatus) ||
-				(state.Terminated != nil && state.Terminated.Reason == reapStatus) {
-				return true, fmt.Sprintf("has container status %s", reapStatus)
-			}
+		if status, init := podContainerStatus(pod, func(reason string) bool {
+			return equalValues(reason, reapStatus, rule.caseInsensitive)
+		}); status != "" {
+			return true, containerStatusMessage(status, init)
 		}
-
-		// Check init containers
-		for _, initContainerStatus := range pod.Status.InitContainerStatuses {
-			state := initContainerStatus.State
-			// Check both waiting and terminated conditions for init containers
-			if (state.Waiting != nil && state.Waiting.Reason == reapStatus) ||
-				(state.Terminated != nil && state.Terminated.Reason == reapStatus) {
-				return true, fmt.Sprintf("has init container status %s", reapStatus)
-			}
+	}
+	if rule.reapPattern != nil {
+		if status, init := podContainerStatus(pod, rule.reapPattern.MatchString); status != "" {
+			return true, containerStatusMessage(status, init)
 		}
 	}
 	return false, ""
 }
+
+// podContainerStatus returns the first waiting or terminated reason of the pod's containers, and then of its init
+// containers, that matches, and whether it is the reason of an init container.
+func podContainerStatus(pod v1.Pod, matches func(reason string) bool) (string, bool) {
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if reason := containerStateReason(containerStatus.State, matches); reason != "" {
+			return reason, false
+		}
+	}
+	for _, initContainerStatus := range pod.Status.InitContainerStatuses {
+		if reason := containerStateReason(initContainerStatus.State, matches); reason != "" {
+			return reason, true
+		}
+	}
+	return "", false
+}
+
+// containerStateReason checks both waiting and terminated states.
+func containerStateReason(state v1.ContainerState, matches func(reason string) bool) string {
+	if state.Waiting != nil && state.Waiting.Reason != "" && matches(state.Waiting.Reason) {
+		return state.Waiting.Reason
+	}
+	if state.Terminated != nil && state.Terminated.Reason != "" && matches(state.Terminated.Reason) {
+		return state.Terminated.Reason
+	}
+	return ""
+}
+
+func containerStatusMessage(status string, init bool) string {
+	if init {
+		return fmt.Sprintf("has init container status %s", status)
+	}
+	return fmt.Sprintf("has container status %s", status)
+}
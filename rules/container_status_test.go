@@ -2,6 +2,7 @@ package rules
 
 import (
 	"os"
+	"regexp"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -41,7 +42,7 @@ func TestContainerStatusLoad(t *testing.T) {
 	t.Run("load", func(t *testing.T) {
 		os.Clearenv()
 		os.Setenv(envContainerStatus, "test-status")
-		loaded, message, err := (&containerStatus{}).load()
+		loaded, message, err := (&containerStatus{}).Load(os.LookupEnv)
 		assert.NoError(t, err)
 		assert.Equal(t, "container status in [test-status]", message)
 		assert.True(t, loaded)
@@ -50,7 +51,7 @@ func TestContainerStatusLoad(t *testing.T) {
 		os.Clearenv()
 		os.Setenv(envContainerStatus, "test-status,another-status")
 		containerStatus := containerStatus{}
-		loaded, message, err := containerStatus.load()
+		loaded, message, err := containerStatus.Load(os.LookupEnv)
 		assert.NoError(t, err)
 		assert.Equal(t, "container status in [test-status,another-status]", message)
 		assert.True(t, loaded)
@@ -58,9 +59,35 @@ func TestContainerStatusLoad(t *testing.T) {
 		assert.Equal(t, "test-status", containerStatus.reapStatuses[0])
 		assert.Equal(t, "another-status", containerStatus.reapStatuses[1])
 	})
+	t.Run("load regex", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envContainerStatusRegex, "^Err")
+		rule := containerStatus{}
+		loaded, message, err := rule.Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.Equal(t, "container status matching ^Err", message)
+		assert.True(t, loaded)
+		assert.Empty(t, rule.reapStatuses)
+	})
+	t.Run("load statuses and regex", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envContainerStatus, "Error")
+		os.Setenv(envContainerStatusRegex, "BackOff$")
+		loaded, message, err := (&containerStatus{}).Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.Equal(t, "container status in [Error] or container status matching BackOff$", message)
+		assert.True(t, loaded)
+	})
+	t.Run("invalid regex", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envContainerStatusRegex, "Err(")
+		loaded, _, err := (&containerStatus{}).Load(os.LookupEnv)
+		assert.Error(t, err)
+		assert.False(t, loaded)
+	})
 	t.Run("no load", func(t *testing.T) {
 		os.Clearenv()
-		loaded, message, err := (&containerStatus{}).load()
+		loaded, message, err := (&containerStatus{}).Load(os.LookupEnv)
 		assert.NoError(t, err)
 		assert.Equal(t, "", message)
 		assert.False(t, loaded)
@@ -72,7 +99,7 @@ func TestContainerStatusShouldReap(t *testing.T) {
 		os.Clearenv()
 		os.Setenv(envContainerStatus, "test-status,another-status")
 		containerStatus := containerStatus{}
-		containerStatus.load()
+		containerStatus.Load(os.LookupEnv)
 		pod := testStatusPod(testWaitContainerState("another-status"))
 		shouldReap, reason := containerStatus.ShouldReap(pod)
 		assert.True(t, shouldReap)
@@ -82,7 +109,7 @@ func TestContainerStatusShouldReap(t *testing.T) {
 		os.Clearenv()
 		os.Setenv(envContainerStatus, "test-status,another-status")
 		containerStatus := containerStatus{}
-		containerStatus.load()
+		containerStatus.Load(os.LookupEnv)
 		pod := testStatusPod(testWaitContainerState("not-present"))
 		shouldReap, _ := containerStatus.ShouldReap(pod)
 		assert.False(t, shouldReap)
@@ -91,7 +118,7 @@ func TestContainerStatusShouldReap(t *testing.T) {
 		os.Clearenv()
 		os.Setenv(envContainerStatus, "Error")
 		cs := containerStatus{}
-		cs.load()
+		cs.Load(os.LookupEnv)
 		pod := testStatusPod(testTerminatedContainerState("Error"))
 		shouldReap, reason := cs.ShouldReap(pod)
 		assert.True(t, shouldReap)
@@ -101,7 +128,7 @@ func TestContainerStatusShouldReap(t *testing.T) {
 		os.Clearenv()
 		os.Setenv(envContainerStatus, "CrashLoopBackOff")
 		cs := containerStatus{}
-		cs.load()
+		cs.Load(os.LookupEnv)
 		pod := v1.Pod{
 			Status: v1.PodStatus{
 				ContainerStatuses: []v1.ContainerStatus{}, // no regular containers
@@ -120,7 +147,7 @@ func TestContainerStatusShouldReap(t *testing.T) {
 		os.Clearenv()
 		os.Setenv(envContainerStatus, "CrashLoopBackOff")
 		cs := containerStatus{}
-		cs.load()
+		cs.Load(os.LookupEnv)
 		pod := v1.Pod{
 			Status: v1.PodStatus{
 				ContainerStatuses:     []v1.ContainerStatus{},
@@ -134,7 +161,7 @@ func TestContainerStatusShouldReap(t *testing.T) {
 		os.Clearenv()
 		os.Setenv(envContainerStatus, "Running")
 		cs := containerStatus{}
-		cs.load()
+		cs.Load(os.LookupEnv)
 		pod := v1.Pod{
 			Status: v1.PodStatus{
 				ContainerStatuses: []v1.ContainerStatus{
@@ -149,16 +176,64 @@ func TestContainerStatusShouldReap(t *testing.T) {
 		shouldReap, _ := cs.ShouldReap(pod)
 		assert.False(t, shouldReap) // Running state has no Reason field to match
 	})
-	t.Run("whitespace in values not trimmed", func(t *testing.T) {
+	t.Run("whitespace in values is trimmed", func(t *testing.T) {
 		os.Clearenv()
 		os.Setenv(envContainerStatus, "Status1, Status2")
 		cs := containerStatus{}
-		cs.load()
-		// The second value is " Status2" with leading space
-		assert.Equal(t, " Status2", cs.reapStatuses[1])
-		// Pod with "Status2" (no space) won't match " Status2"
+		cs.Load(os.LookupEnv)
+		assert.Equal(t, "Status2", cs.reapStatuses[1])
 		pod := testStatusPod(testWaitContainerState("Status2"))
 		shouldReap, _ := cs.ShouldReap(pod)
+		assert.True(t, shouldReap)
+	})
+	t.Run("case sensitive by default", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envContainerStatus, "crashloopbackoff")
+		cs := containerStatus{}
+		cs.Load(os.LookupEnv)
+		shouldReap, _ := cs.ShouldReap(testStatusPod(testWaitContainerState("CrashLoopBackOff")))
+		assert.False(t, shouldReap)
+	})
+	t.Run("case insensitive", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envContainerStatus, "crashloopbackoff")
+		os.Setenv(envContainerStatusRegex, "^err")
+		os.Setenv(envCaseInsensitive, "true")
+		cs := containerStatus{}
+		cs.Load(os.LookupEnv)
+		shouldReap, reason := cs.ShouldReap(testStatusPod(testWaitContainerState("CrashLoopBackOff")))
+		assert.True(t, shouldReap)
+		assert.Equal(t, "has container status CrashLoopBackOff", reason)
+		shouldReap, _ = cs.ShouldReap(testStatusPod(testWaitContainerState("ErrImagePull")))
+		assert.True(t, shouldReap)
+	})
+	t.Run("regex", func(t *testing.T) {
+		rule := containerStatus{reapPattern: regexp.MustCompile("BackOff$")}
+		for reason, expected := range map[string]bool{
+			"CrashLoopBackOff": true,
+			"ImagePullBackOff": true,
+			"BackOffPulling":   false,
+			"Error":            false,
+		} {
+			shouldReap, message := rule.ShouldReap(testStatusPod(testWaitContainerState(reason)))
+			assert.Equal(t, expected, shouldReap, reason)
+			if expected {
+				assert.Equal(t, "has container status "+reason, message)
+			}
+		}
+	})
+	t.Run("regex matches init containers", func(t *testing.T) {
+		rule := containerStatus{reapPattern: regexp.MustCompile("^Err")}
+		pod := v1.Pod{Status: v1.PodStatus{
+			InitContainerStatuses: []v1.ContainerStatus{{State: testTerminatedContainerState("ErrImagePull")}},
+		}}
+		shouldReap, message := rule.ShouldReap(pod)
+		assert.True(t, shouldReap)
+		assert.Equal(t, "has init container status ErrImagePull", message)
+	})
+	t.Run("regex does not match empty reasons", func(t *testing.T) {
+		rule := containerStatus{reapPattern: regexp.MustCompile(".*")}
+		shouldReap, _ := rule.ShouldReap(testStatusPod(v1.ContainerState{Running: &v1.ContainerStateRunning{}}))
 		assert.False(t, shouldReap)
 	})
 }
@@ -2,41 +2,86 @@ package rules
 
 import (
 	"fmt"
-	"os"
+	"hash/fnv"
+	"math"
+	"strconv"
+	"strings"
 	"time"
 
 	"k8s.io/api/core/v1"
 )
 
 const envMaxDuration = "MAX_DURATION"
+const envMaxDurationJitter = "MAX_DURATION_JITTER"
+
+// a maximum duration below sweepingDuration flags nearly every pod, since few pods have been running for less
+const sweepingDuration = time.Hour
 
 var _ Rule = (*duration)(nil)
 
 type duration struct {
 	duration time.Duration
+	// jitter is the maximum fraction of the duration a pod's deadline is moved earlier or later.
+	jitter  float64
+	missing missingStartTime
 }
 
-func (rule *duration) load() (bool, string, error) {
-	value, active := os.LookupEnv(envMaxDuration)
+func (rule *duration) Load(lookup LookupFunc) (bool, string, error) {
+	value, active := lookup(envMaxDuration)
 	if !active {
 		return false, "", nil
 	}
-	duration, err := time.ParseDuration(value)
+	duration, err := parseDuration(value)
 	if err != nil {
 		return false, "", fmt.Errorf("invalid max duration: %s", err)
 	}
 	rule.duration = duration
-	return true, fmt.Sprintf("maximum run duration %s", value), nil
+	rule.missing, err = loadMissingStartTime(lookup)
+	if err != nil {
+		return false, "", err
+	}
+	jitterValue, jitterActive := lookup(envMaxDurationJitter)
+	if !jitterActive {
+		return true, fmt.Sprintf("maximum run duration %s", value), nil
+	}
+	percent, err := strconv.ParseFloat(strings.TrimSuffix(jitterValue, "%"), 64)
+	if err != nil {
+		return false, "", fmt.Errorf("invalid max duration jitter: %s", err)
+	}
+	if percent < 0 || percent >= 100 {
+		return false, "", fmt.Errorf("invalid max duration jitter: %s must be at least 0 and less than 100", jitterValue)
+	}
+	rule.jitter = percent / 100
+	return true, fmt.Sprintf("maximum run duration %s with %s%% jitter", value, strconv.FormatFloat(percent, 'f', -1, 64)), nil
+}
+
+// podDuration returns the pod's maximum duration after applying jitter. The jitter is derived from a hash of the
+// pod's uid, so it is stable across reap cycles but spread out across pods created at the same time.
+func (rule *duration) podDuration(pod v1.Pod) time.Duration {
+	if rule.jitter == 0 {
+		return rule.duration
+	}
+	hash := fnv.New64a()
+	hash.Write([]byte(pod.UID))
+	offset := float64(hash.Sum64())/float64(math.MaxUint64)*2 - 1 // in range [-1, 1]
+	return rule.duration + time.Duration(float64(rule.duration)*rule.jitter*offset)
+}
+
+func (rule *duration) sweeping() string {
+	// with jitter, some pods are flagged once they run for the shortest jittered duration
+	shortest := rule.duration - time.Duration(float64(rule.duration)*rule.jitter)
+	if shortest >= sweepingDuration {
+		return ""
+	}
+	return fmt.Sprintf("maximum run duration %s flags nearly every pod", rule.duration)
 }
 
 func (rule *duration) ShouldReap(pod v1.Pod) (bool, string) {
-	podStartTime := pod.Status.StartTime
-	if podStartTime == nil {
-		return false, ""
+	startTime, ok := rule.missing.startTime(pod)
+	if !ok {
+		return rule.missing.shouldReap(pod)
 	}
-	startTime := time.Unix(podStartTime.Unix(), 0) // convert to standard go time
-	cutoffTime := time.Now().Add(-1 * rule.duration)
+	cutoffTime := time.Now().Add(-1 * rule.podDuration(pod))
 	runningDuration := time.Now().Sub(startTime)
-	message := fmt.Sprintf("has been running for %s", runningDuration.String())
-	return startTime.Before(cutoffTime), message
+	return startTime.Before(cutoffTime), runningMessage(pod, runningDuration)
 }
@@ -1,6 +1,7 @@
 package rules
 
 import (
+	"fmt"
 	"os"
 	"testing"
 	"time"
@@ -8,6 +9,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 func testDurationPod(startTime *time.Time) v1.Pod {
@@ -23,7 +25,7 @@ func TestDurationLoad(t *testing.T) {
 	t.Run("load", func(t *testing.T) {
 		os.Clearenv()
 		os.Setenv(envMaxDuration, "30m")
-		loaded, message, err := (&duration{}).load()
+		loaded, message, err := (&duration{}).Load(os.LookupEnv)
 		assert.NoError(t, err)
 		assert.Equal(t, "maximum run duration 30m", message)
 		assert.True(t, loaded)
@@ -31,14 +33,59 @@ func TestDurationLoad(t *testing.T) {
 	t.Run("invalid duration", func(t *testing.T) {
 		os.Clearenv()
 		os.Setenv(envMaxDuration, "not-a-duration")
-		loaded, message, err := (&duration{}).load()
+		loaded, message, err := (&duration{}).Load(os.LookupEnv)
 		assert.Error(t, err)
 		assert.Equal(t, "", message)
 		assert.False(t, loaded)
 	})
+	t.Run("load with jitter", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envMaxDuration, "30m")
+		os.Setenv(envMaxDurationJitter, "10")
+		d := duration{}
+		loaded, message, err := d.Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.Equal(t, "maximum run duration 30m with 10% jitter", message)
+		assert.True(t, loaded)
+		assert.Equal(t, 0.1, d.jitter)
+	})
+	t.Run("load with percent sign jitter", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envMaxDuration, "30m")
+		os.Setenv(envMaxDurationJitter, "12.5%")
+		d := duration{}
+		_, message, err := d.Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.Equal(t, "maximum run duration 30m with 12.5% jitter", message)
+		assert.Equal(t, 0.125, d.jitter)
+	})
+	t.Run("invalid jitter", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envMaxDuration, "30m")
+		os.Setenv(envMaxDurationJitter, "not-a-number")
+		loaded, _, err := (&duration{}).Load(os.LookupEnv)
+		assert.Error(t, err)
+		assert.False(t, loaded)
+	})
+	t.Run("jitter out of range", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envMaxDuration, "30m")
+		for _, jitter := range []string{"-1", "100", "150"} {
+			os.Setenv(envMaxDurationJitter, jitter)
+			_, _, err := (&duration{}).Load(os.LookupEnv)
+			assert.Error(t, err, jitter)
+		}
+	})
+	t.Run("jitter without max duration", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envMaxDurationJitter, "10")
+		loaded, _, err := (&duration{}).Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.False(t, loaded)
+	})
 	t.Run("no load", func(t *testing.T) {
 		os.Clearenv()
-		loaded, message, err := (&duration{}).load()
+		loaded, message, err := (&duration{}).Load(os.LookupEnv)
 		assert.NoError(t, err)
 		assert.Equal(t, "", message)
 		assert.False(t, loaded)
@@ -50,16 +97,52 @@ func TestDurationShouldReap(t *testing.T) {
 		os.Clearenv()
 		os.Setenv(envMaxDuration, "2m")
 		duration := duration{}
-		duration.load()
+		duration.Load(os.LookupEnv)
 		pod := testDurationPod(nil) // no start time can happen during pod creation
 		shouldReap, _ := duration.ShouldReap(pod)
 		assert.False(t, shouldReap)
 	})
+	t.Run("no start time measured from creation", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envMaxDuration, "2m")
+		os.Setenv(envMissingStartTime, "creation")
+		duration := duration{}
+		_, _, err := duration.Load(os.LookupEnv)
+		assert.NoError(t, err)
+		pod := testDurationPod(nil)
+		pod.CreationTimestamp = metav1.NewTime(time.Now().Add(-3 * time.Minute))
+		shouldReap, reason := duration.ShouldReap(pod)
+		assert.True(t, shouldReap)
+		assert.Regexp(t, "^has not started in 3m", reason)
+	})
+	t.Run("no start time eligible after", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envMaxDuration, "2m")
+		os.Setenv(envMissingStartTime, "1h")
+		duration := duration{}
+		_, _, err := duration.Load(os.LookupEnv)
+		assert.NoError(t, err)
+		pod := testDurationPod(nil)
+		pod.CreationTimestamp = metav1.NewTime(time.Now().Add(-3 * time.Minute))
+		shouldReap, _ := duration.ShouldReap(pod)
+		assert.False(t, shouldReap)
+		pod.CreationTimestamp = metav1.NewTime(time.Now().Add(-61 * time.Minute))
+		shouldReap, _ = duration.ShouldReap(pod)
+		assert.True(t, shouldReap)
+	})
+	t.Run("invalid missing start time", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envMaxDuration, "2m")
+		os.Setenv(envMissingStartTime, "never")
+		loaded, _, err := (&duration{}).Load(os.LookupEnv)
+		assert.Error(t, err)
+		assert.False(t, loaded)
+	})
 	t.Run("reap", func(t *testing.T) {
 		os.Clearenv()
 		os.Setenv(envMaxDuration, "1m59s")
 		duration := duration{}
-		duration.load()
+		duration.Load(os.LookupEnv)
 		startTime := time.Now().Add(-2 * time.Minute)
 		pod := testDurationPod(&startTime)
 		shouldReap, reason := duration.ShouldReap(pod)
@@ -70,7 +153,7 @@ func TestDurationShouldReap(t *testing.T) {
 		os.Clearenv()
 		os.Setenv(envMaxDuration, "2m1s")
 		duration := duration{}
-		duration.load()
+		duration.Load(os.LookupEnv)
 		startTime := time.Now().Add(-2 * time.Minute)
 		pod := testDurationPod(&startTime)
 		shouldReap, _ := duration.ShouldReap(pod)
@@ -80,7 +163,7 @@ func TestDurationShouldReap(t *testing.T) {
 		os.Clearenv()
 		os.Setenv(envMaxDuration, "-5m")
 		d := duration{}
-		loaded, _, err := d.load()
+		loaded, _, err := d.Load(os.LookupEnv)
 		assert.NoError(t, err)
 		assert.True(t, loaded)
 		// cutoffTime = now - (-5m) = now + 5m = future
@@ -98,7 +181,7 @@ func TestDurationShouldReap(t *testing.T) {
 		os.Clearenv()
 		os.Setenv(envMaxDuration, "0s")
 		d := duration{}
-		loaded, _, err := d.load()
+		loaded, _, err := d.Load(os.LookupEnv)
 		assert.NoError(t, err)
 		assert.True(t, loaded)
 		// cutoffTime = now - 0 = now
@@ -109,3 +192,48 @@ func TestDurationShouldReap(t *testing.T) {
 		assert.True(t, shouldReap)
 	})
 }
+
+func TestDurationJitter(t *testing.T) {
+	pod := func(uid string) v1.Pod {
+		pod := v1.Pod{}
+		pod.UID = types.UID(uid)
+		return pod
+	}
+	t.Run("no jitter", func(t *testing.T) {
+		d := duration{duration: time.Hour}
+		assert.Equal(t, time.Hour, d.podDuration(pod("a")))
+	})
+	t.Run("deterministic and bounded", func(t *testing.T) {
+		d := duration{duration: time.Hour, jitter: 0.1}
+		distinct := map[time.Duration]bool{}
+		for i := 0; i < 100; i++ {
+			uid := fmt.Sprintf("uid-%d", i)
+			podDuration := d.podDuration(pod(uid))
+			assert.Equal(t, podDuration, d.podDuration(pod(uid)))
+			assert.True(t, podDuration >= 54*time.Minute && podDuration <= 66*time.Minute, podDuration.String())
+			distinct[podDuration] = true
+		}
+		assert.True(t, len(distinct) > 90, "jitter should spread pods out")
+	})
+	t.Run("applied to should reap", func(t *testing.T) {
+		d := duration{duration: time.Hour, jitter: 0.5}
+		earlier, later := "", ""
+		for i := 0; earlier == "" || later == ""; i++ {
+			uid := fmt.Sprintf("uid-%d", i)
+			if podDuration := d.podDuration(pod(uid)); podDuration < 50*time.Minute {
+				earlier = uid
+			} else if podDuration > 70*time.Minute {
+				later = uid
+			}
+		}
+		startTime := time.Now().Add(-time.Hour)
+		earlierPod := testDurationPod(&startTime)
+		earlierPod.UID = types.UID(earlier)
+		laterPod := testDurationPod(&startTime)
+		laterPod.UID = types.UID(later)
+		shouldReap, _ := d.ShouldReap(earlierPod)
+		assert.True(t, shouldReap)
+		shouldReap, _ = d.ShouldReap(laterPod)
+		assert.False(t, shouldReap)
+	})
+}
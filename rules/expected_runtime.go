@@ -0,0 +1,165 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const envExpectedRuntimeFactor = "EXPECTED_RUNTIME_FACTOR"
+const envExpectedRuntimeAnnotation = "EXPECTED_RUNTIME_ANNOTATION"
+const defaultExpectedRuntimeAnnotation = "pod-reaper/expected-runtime"
+
+// jobs are cached per namespace so each namespace is listed at most once per ttl
+const jobDeadlinesTTL = time.Minute
+
+var _ Rule = (*expectedRuntime)(nil)
+var _ contextRule = (*expectedRuntime)(nil)
+
+// expectedRuntime flags pods that have been running for a factor longer than the runtime declared for them, either
+// with an annotation or with the activeDeadlineSeconds of the job controlling them: runaway batch work that its
+// controller has not cleaned up.
+type expectedRuntime struct {
+	factor     float64
+	annotation string
+	jobs       *jobDeadlines
+}
+
+func (rule *expectedRuntime) Load(lookup LookupFunc) (bool, string, error) {
+	value, active := lookup(envExpectedRuntimeFactor)
+	if !active {
+		return false, "", nil
+	}
+	factor, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return false, "", fmt.Errorf("invalid %s: %s", envExpectedRuntimeFactor, err)
+	}
+	// a factor below 1 would reap pods before they reach the runtime declared for them
+	if factor < 1 {
+		return false, "", fmt.Errorf("invalid %s: must be at least 1", envExpectedRuntimeFactor)
+	}
+	annotation := defaultExpectedRuntimeAnnotation
+	if value, exists := lookup(envExpectedRuntimeAnnotation); exists && value != "" {
+		annotation = value
+	}
+	rule.factor = factor
+	rule.annotation = annotation
+	return true, fmt.Sprintf("expected runtime factor %s", value), nil
+}
+
+func (rule *expectedRuntime) ShouldReap(pod v1.Pod) (bool, string) {
+	return rule.shouldReapContext(context.Background(), pod)
+}
+
+func (rule *expectedRuntime) shouldReapContext(ctx context.Context, pod v1.Pod) (bool, string) {
+	if pod.Status.Phase != v1.PodRunning || pod.Status.StartTime == nil {
+		return false, ""
+	}
+	expected, source := rule.expected(ctx, pod)
+	if expected <= 0 {
+		return false, ""
+	}
+	running := time.Since(pod.Status.StartTime.Time)
+	if running <= time.Duration(float64(expected)*rule.factor) {
+		return false, ""
+	}
+	return true, fmt.Sprintf("has been running for %s, more than %s times its expected runtime of %s from %s",
+		running.Truncate(time.Second), strconv.FormatFloat(rule.factor, 'f', -1, 64), expected, source)
+}
+
+// expected returns the runtime declared for the pod and where it was declared, preferring the annotation over the
+// activeDeadlineSeconds of the job controlling the pod. It returns zero when no runtime is declared.
+func (rule *expectedRuntime) expected(ctx context.Context, pod v1.Pod) (time.Duration, string) {
+	if value, exists := pod.Annotations[rule.annotation]; exists {
+		expected, err := parseDuration(value)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{"pod": pod.Name, "namespace": pod.Namespace}).WithError(err).Warn("invalid expected runtime annotation")
+			return 0, ""
+		}
+		return expected, "annotation " + rule.annotation
+	}
+	owner := metav1.GetControllerOf(&pod)
+	if owner == nil || owner.Kind != "Job" {
+		return 0, ""
+	}
+	deadlines, err := listJobDeadlines(ctx, rule.jobs, pod.Namespace)
+	if err != nil {
+		logrus.WithField("namespace", pod.Namespace).WithError(err).Warn("unable to list jobs")
+		return 0, ""
+	}
+	seconds, exists := deadlines[owner.Name]
+	if !exists {
+		return 0, ""
+	}
+	return time.Duration(seconds) * time.Second, "job " + owner.Name + " activeDeadlineSeconds"
+}
+
+// listJobDeadlines lists the activeDeadlineSeconds of the namespace's jobs from jobs, or from the job deadline cache of
+// the cluster carried by ctx when jobs is nil.
+func listJobDeadlines(ctx context.Context, jobs *jobDeadlines, namespace string) (map[string]int64, error) {
+	if jobs == nil {
+		cluster, err := clusterFromContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		jobs = cluster.jobs
+	}
+	return jobs.list(ctx, namespace)
+}
+
+// jobDeadlines lists the activeDeadlineSeconds of jobs, caching them per namespace.
+type jobDeadlines struct {
+	client kubernetes.Interface
+	mutex  sync.Mutex
+	cache  map[string]cachedJobDeadlines
+}
+
+type cachedJobDeadlines struct {
+	listed time.Time
+	// deadlines holds the activeDeadlineSeconds of the jobs that set one, by job name
+	deadlines map[string]int64
+}
+
+func newJobDeadlines(client kubernetes.Interface) *jobDeadlines {
+	return &jobDeadlines{
+		client: client,
+		cache:  map[string]cachedJobDeadlines{},
+	}
+}
+
+func (jobs *jobDeadlines) list(ctx context.Context, namespace string) (map[string]int64, error) {
+	jobs.mutex.Lock()
+	defer jobs.mutex.Unlock()
+	if cached, ok := jobs.cache[namespace]; ok && time.Since(cached.listed) < jobDeadlinesTTL {
+		return cached.deadlines, nil
+	}
+	ctx, cancel := apiContext(ctx)
+	defer cancel()
+	list, err := jobs.client.BatchV1().Jobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	deadlines := map[string]int64{}
+	for _, job := range list.Items {
+		if seconds := activeDeadline(job); seconds > 0 {
+			deadlines[job.Name] = seconds
+		}
+	}
+	jobs.cache[namespace] = cachedJobDeadlines{listed: time.Now(), deadlines: deadlines}
+	return deadlines, nil
+}
+
+func activeDeadline(job batchv1.Job) int64 {
+	if job.Spec.ActiveDeadlineSeconds == nil {
+		return 0
+	}
+	return *job.Spec.ActiveDeadlineSeconds
+}
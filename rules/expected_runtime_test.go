@@ -0,0 +1,139 @@
+package rules
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func testJobDeadlines(objects ...runtime.Object) *jobDeadlines {
+	return newJobDeadlines(fake.NewSimpleClientset(objects...))
+}
+
+func testDeadlineJob(name string, deadline *int64) *batchv1.Job {
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       batchv1.JobSpec{ActiveDeadlineSeconds: deadline},
+	}
+}
+
+func testRuntimePod(running time.Duration, annotations map[string]string, job string) v1.Pod {
+	startTime := metav1.NewTime(time.Now().Add(-running))
+	pod := v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default", Annotations: annotations},
+		Status:     v1.PodStatus{Phase: v1.PodRunning, StartTime: &startTime},
+	}
+	if job != "" {
+		controller := true
+		pod.OwnerReferences = []metav1.OwnerReference{{Kind: "Job", Name: job, Controller: &controller}}
+	}
+	return pod
+}
+
+func TestExpectedRuntimeLoad(t *testing.T) {
+	t.Run("load", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envExpectedRuntimeFactor, "1.5")
+		rule := expectedRuntime{}
+		loaded, message, err := rule.Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.True(t, loaded)
+		assert.Equal(t, "expected runtime factor 1.5", message)
+		assert.Equal(t, 1.5, rule.factor)
+		assert.Equal(t, defaultExpectedRuntimeAnnotation, rule.annotation)
+	})
+	t.Run("annotation", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envExpectedRuntimeFactor, "2")
+		os.Setenv(envExpectedRuntimeAnnotation, "example.com/runtime")
+		rule := expectedRuntime{}
+		_, _, err := rule.Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.Equal(t, "example.com/runtime", rule.annotation)
+	})
+	t.Run("invalid factor", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envExpectedRuntimeFactor, "twice")
+		_, _, err := (&expectedRuntime{}).Load(os.LookupEnv)
+		assert.Error(t, err)
+	})
+	t.Run("factor below 1", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envExpectedRuntimeFactor, "0.5")
+		_, _, err := (&expectedRuntime{}).Load(os.LookupEnv)
+		assert.Error(t, err)
+	})
+	t.Run("no load", func(t *testing.T) {
+		os.Clearenv()
+		loaded, message, err := (&expectedRuntime{}).Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.Equal(t, "", message)
+		assert.False(t, loaded)
+	})
+}
+
+func TestExpectedRuntimeShouldReap(t *testing.T) {
+	deadline := int64(3600)
+	jobs := testJobDeadlines(
+		testDeadlineJob("nightly", &deadline),
+		testDeadlineJob("unbounded", nil),
+	)
+	rule := expectedRuntime{factor: 2, annotation: defaultExpectedRuntimeAnnotation, jobs: jobs}
+	annotated := map[string]string{defaultExpectedRuntimeAnnotation: "30m"}
+
+	t.Run("annotation exceeded", func(t *testing.T) {
+		shouldReap, reason := rule.ShouldReap(testRuntimePod(61*time.Minute, annotated, ""))
+		assert.True(t, shouldReap)
+		assert.Contains(t, reason, "more than 2 times its expected runtime of 30m0s from annotation pod-reaper/expected-runtime")
+	})
+	t.Run("annotation within factor", func(t *testing.T) {
+		shouldReap, _ := rule.ShouldReap(testRuntimePod(45*time.Minute, annotated, ""))
+		assert.False(t, shouldReap)
+	})
+	t.Run("annotation in days", func(t *testing.T) {
+		shouldReap, _ := rule.ShouldReap(testRuntimePod(3*day, map[string]string{defaultExpectedRuntimeAnnotation: "1d"}, ""))
+		assert.True(t, shouldReap)
+	})
+	t.Run("annotation preferred over job", func(t *testing.T) {
+		shouldReap, _ := rule.ShouldReap(testRuntimePod(90*time.Minute, map[string]string{defaultExpectedRuntimeAnnotation: "1h"}, "nightly"))
+		assert.False(t, shouldReap)
+	})
+	t.Run("invalid annotation", func(t *testing.T) {
+		shouldReap, _ := rule.ShouldReap(testRuntimePod(90*time.Minute, map[string]string{defaultExpectedRuntimeAnnotation: "a while"}, ""))
+		assert.False(t, shouldReap)
+	})
+	t.Run("job deadline exceeded", func(t *testing.T) {
+		shouldReap, reason := rule.ShouldReap(testRuntimePod(3*time.Hour, nil, "nightly"))
+		assert.True(t, shouldReap)
+		assert.Contains(t, reason, "from job nightly activeDeadlineSeconds")
+	})
+	t.Run("job deadline within factor", func(t *testing.T) {
+		shouldReap, _ := rule.ShouldReap(testRuntimePod(90*time.Minute, nil, "nightly"))
+		assert.False(t, shouldReap)
+	})
+	t.Run("job without deadline", func(t *testing.T) {
+		shouldReap, _ := rule.ShouldReap(testRuntimePod(30*day, nil, "unbounded"))
+		assert.False(t, shouldReap)
+	})
+	t.Run("missing job", func(t *testing.T) {
+		shouldReap, _ := rule.ShouldReap(testRuntimePod(30*day, nil, "deleted"))
+		assert.False(t, shouldReap)
+	})
+	t.Run("no expected runtime", func(t *testing.T) {
+		shouldReap, _ := rule.ShouldReap(testRuntimePod(30*day, nil, ""))
+		assert.False(t, shouldReap)
+	})
+	t.Run("not running", func(t *testing.T) {
+		pod := testRuntimePod(61*time.Minute, annotated, "")
+		pod.Status.Phase = v1.PodSucceeded
+		shouldReap, _ := rule.ShouldReap(pod)
+		assert.False(t, shouldReap)
+	})
+}
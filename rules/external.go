@@ -0,0 +1,99 @@
+package rules
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+)
+
+const envExternalRuleURL = "EXTERNAL_RULE_URL"
+const envExternalRuleTimeout = "EXTERNAL_RULE_TIMEOUT"
+
+// externalDefaultReason is the reason of pods flagged by an external rule that did not give one
+const externalDefaultReason = "was flagged by the external rule"
+
+var _ Rule = (*external)(nil)
+
+// external flags the pods that an external service decides to reap, so that policies specific to an organization do
+// not need a rule of their own. Each pod is posted to the service, which answers with its decision and a reason. Pods
+// are never flagged while the service cannot be reached or answers with an error.
+type external struct {
+	endpoint string
+	client   *http.Client
+}
+
+// externalDecision is the answer of the external service for a pod.
+type externalDecision struct {
+	Reap   bool   `json:"reap"`
+	Reason string `json:"reason"`
+}
+
+func (rule *external) Load(lookup LookupFunc) (bool, string, error) {
+	endpoint, active := lookup(envExternalRuleURL)
+	if !active {
+		return false, "", nil
+	}
+	parsed, err := url.Parse(endpoint)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return false, "", fmt.Errorf("invalid %s: %q is not an absolute http or https url", envExternalRuleURL, endpoint)
+	}
+	timeoutValue, exists := lookup(envExternalRuleTimeout)
+	if !exists {
+		timeoutValue = "5s"
+	}
+	timeout, err := time.ParseDuration(timeoutValue)
+	if err != nil {
+		return false, "", fmt.Errorf("invalid %s: %s", envExternalRuleTimeout, err)
+	}
+	if timeout <= 0 {
+		return false, "", fmt.Errorf("invalid %s: must be positive", envExternalRuleTimeout)
+	}
+	rule.endpoint = endpoint
+	rule.client = &http.Client{Timeout: timeout}
+	return true, fmt.Sprintf("external rule %s", parsed.Redacted()), nil
+}
+
+func (rule *external) ShouldReap(pod v1.Pod) (bool, string) {
+	decision, err := rule.decide(pod)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{"pod": pod.Name, "namespace": pod.Namespace}).WithError(err).Warn("unable to get the decision of the external rule")
+		return false, ""
+	}
+	if !decision.Reap {
+		return false, decision.Reason
+	}
+	if decision.Reason == "" {
+		return true, externalDefaultReason
+	}
+	return true, decision.Reason
+}
+
+// decide posts the pod to the external service and returns its decision.
+func (rule *external) decide(pod v1.Pod) (externalDecision, error) {
+	// managed fields are bookkeeping of the API server that can be larger than the rest of the pod
+	pod.ManagedFields = nil
+	body, err := json.Marshal(pod)
+	if err != nil {
+		return externalDecision{}, err
+	}
+	response, err := rule.client.Post(rule.endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return externalDecision{}, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return externalDecision{}, fmt.Errorf("unexpected response status %s", response.Status)
+	}
+	var decision externalDecision
+	if err := json.NewDecoder(io.LimitReader(response.Body, 1<<20)).Decode(&decision); err != nil {
+		return externalDecision{}, fmt.Errorf("invalid decision: %s", err)
+	}
+	return decision, nil
+}
@@ -0,0 +1,101 @@
+package rules
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+)
+
+const envMaxImageAge = "MAX_IMAGE_AGE"
+const envImageCreatedAnnotation = "IMAGE_CREATED_ANNOTATION"
+const envImageRegistryLookup = "IMAGE_REGISTRY_LOOKUP"
+
+const defaultImageCreatedAnnotation = "pod-reaper/image-created"
+
+// imageRegistry is shared by every load of the rule so that image creation timestamps stay cached across reap cycles
+// and namespaces.
+var imageRegistry = newRegistryClient()
+
+var _ Rule = (*imageAge)(nil)
+
+type imageAge struct {
+	maxAge     time.Duration
+	annotation string
+	registry   *registryClient
+}
+
+func (rule *imageAge) Load(lookup LookupFunc) (bool, string, error) {
+	value, active := lookup(envMaxImageAge)
+	if !active {
+		return false, "", nil
+	}
+	maxAge, err := parseDuration(value)
+	if err != nil {
+		return false, "", fmt.Errorf("invalid max image age: %s", err)
+	}
+	rule.maxAge = maxAge
+	rule.annotation = defaultImageCreatedAnnotation
+	if annotation, exists := lookup(envImageCreatedAnnotation); exists {
+		rule.annotation = annotation
+	}
+	message := fmt.Sprintf("maximum image age %s", value)
+	if registryLookup, exists := lookup(envImageRegistryLookup); exists {
+		enabled, err := strconv.ParseBool(registryLookup)
+		if err != nil {
+			return false, "", fmt.Errorf("invalid %s: %s", envImageRegistryLookup, err)
+		}
+		if enabled {
+			rule.registry = imageRegistry
+			message += " (with registry lookup)"
+		}
+	}
+	return true, message, nil
+}
+
+func (rule *imageAge) ShouldReap(pod v1.Pod) (bool, string) {
+	if created, ok := rule.annotatedCreated(pod); ok {
+		age := time.Since(created)
+		return age > rule.maxAge, fmt.Sprintf("is running an image built %s ago", age.Truncate(time.Second))
+	}
+	if rule.registry == nil {
+		return false, ""
+	}
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		image := containerStatus.ImageID
+		if image == "" {
+			image = containerStatus.Image
+		}
+		created, err := rule.registry.imageCreated(image)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"pod":   pod.Name,
+				"image": image,
+			}).WithError(err).Debug("unable to determine image age")
+			continue
+		}
+		if age := time.Since(created); age > rule.maxAge {
+			return true, fmt.Sprintf("has container %s running image %s built %s ago", containerStatus.Name, containerStatus.Image, age.Truncate(time.Second))
+		}
+	}
+	return false, ""
+}
+
+// annotatedCreated returns the image creation time recorded on the pod by the deployment tooling, in RFC 3339 format.
+func (rule *imageAge) annotatedCreated(pod v1.Pod) (time.Time, bool) {
+	value, exists := pod.Annotations[rule.annotation]
+	if !exists || rule.annotation == "" {
+		return time.Time{}, false
+	}
+	created, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"pod":        pod.Name,
+			"annotation": rule.annotation,
+		}).WithError(err).Debug("invalid image creation annotation")
+		return time.Time{}, false
+	}
+	return created, true
+}
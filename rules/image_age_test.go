@@ -0,0 +1,110 @@
+package rules
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testImageAnnotatedPod(created string) v1.Pod {
+	return v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test",
+			Annotations: map[string]string{defaultImageCreatedAnnotation: created},
+		},
+	}
+}
+
+func TestImageAgeLoad(t *testing.T) {
+	t.Run("load", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envMaxImageAge, "720h")
+		rule := imageAge{}
+		loaded, message, err := rule.Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.True(t, loaded)
+		assert.Equal(t, "maximum image age 720h", message)
+		assert.Equal(t, defaultImageCreatedAnnotation, rule.annotation)
+		assert.Nil(t, rule.registry)
+	})
+	t.Run("registry lookup", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envMaxImageAge, "720h")
+		os.Setenv(envImageRegistryLookup, "true")
+		os.Setenv(envImageCreatedAnnotation, "example.com/built")
+		rule := imageAge{}
+		loaded, message, err := rule.Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.True(t, loaded)
+		assert.Equal(t, "maximum image age 720h (with registry lookup)", message)
+		assert.Equal(t, "example.com/built", rule.annotation)
+		assert.NotNil(t, rule.registry)
+	})
+	t.Run("invalid age", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envMaxImageAge, "old")
+		_, _, err := (&imageAge{}).Load(os.LookupEnv)
+		assert.Error(t, err)
+	})
+	t.Run("invalid registry lookup", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envMaxImageAge, "720h")
+		os.Setenv(envImageRegistryLookup, "sometimes")
+		_, _, err := (&imageAge{}).Load(os.LookupEnv)
+		assert.Error(t, err)
+	})
+	t.Run("no load", func(t *testing.T) {
+		os.Clearenv()
+		loaded, message, err := (&imageAge{}).Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.Equal(t, "", message)
+		assert.False(t, loaded)
+	})
+}
+
+func TestImageAgeShouldReap(t *testing.T) {
+	rule := imageAge{maxAge: 24 * time.Hour, annotation: defaultImageCreatedAnnotation}
+	t.Run("old image", func(t *testing.T) {
+		pod := testImageAnnotatedPod(time.Now().Add(-48 * time.Hour).Format(time.RFC3339))
+		shouldReap, reason := rule.ShouldReap(pod)
+		assert.True(t, shouldReap)
+		assert.Contains(t, reason, "is running an image built 4")
+	})
+	t.Run("new image", func(t *testing.T) {
+		pod := testImageAnnotatedPod(time.Now().Add(-1 * time.Hour).Format(time.RFC3339))
+		shouldReap, _ := rule.ShouldReap(pod)
+		assert.False(t, shouldReap)
+	})
+	t.Run("invalid annotation", func(t *testing.T) {
+		shouldReap, _ := rule.ShouldReap(testImageAnnotatedPod("last tuesday"))
+		assert.False(t, shouldReap)
+	})
+	t.Run("no annotation", func(t *testing.T) {
+		shouldReap, _ := rule.ShouldReap(v1.Pod{})
+		assert.False(t, shouldReap)
+	})
+	t.Run("registry", func(t *testing.T) {
+		registry := newRegistryClient()
+		registry.cache["example.com/app:old"] = time.Now().Add(-48 * time.Hour)
+		registry.cache["example.com/app:new"] = time.Now()
+		rule := imageAge{maxAge: 24 * time.Hour, annotation: defaultImageCreatedAnnotation, registry: registry}
+		pod := v1.Pod{
+			Status: v1.PodStatus{
+				ContainerStatuses: []v1.ContainerStatus{
+					{Name: "sidecar", Image: "example.com/app:new"},
+					{Name: "app", Image: "example.com/app:old"},
+				},
+			},
+		}
+		shouldReap, reason := rule.ShouldReap(pod)
+		assert.True(t, shouldReap)
+		assert.Contains(t, reason, "has container app running image example.com/app:old built 4")
+		pod.Status.ContainerStatuses = pod.Status.ContainerStatuses[:1]
+		shouldReap, _ = rule.ShouldReap(pod)
+		assert.False(t, shouldReap)
+	})
+}
@@ -0,0 +1,127 @@
+package rules
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const envLogPattern = "LOG_PATTERN"
+const envLogTailLines = "LOG_TAIL_LINES"
+
+// logLimitBytes bounds the logs read from each container, whatever the length of its lines
+const logLimitBytes = 256 * 1024
+
+// matched log lines are truncated in reasons so a single long line does not flood logs and notifications
+const maxLogLineLength = 200
+
+var _ Rule = (*logPattern)(nil)
+var _ contextRule = (*logPattern)(nil)
+
+// logPattern flags running pods with a container whose recent logs match a pattern: pods that look alive to
+// kubernetes but that their own logs show are dead, such as a deadlocked or out of memory process.
+type logPattern struct {
+	pattern   *regexp.Regexp
+	tailLines int64
+	// client is nil to use the client of the cluster the rules are evaluated against
+	client kubernetes.Interface
+}
+
+func (rule *logPattern) Load(lookup LookupFunc) (bool, string, error) {
+	pattern, active := lookup(envLogPattern)
+	if !active {
+		return false, "", nil
+	}
+	insensitive, err := caseInsensitive(lookup)
+	if err != nil {
+		return false, "", err
+	}
+	expression := pattern
+	if insensitive {
+		expression = "(?i)" + pattern
+	}
+	compiled, err := regexp.Compile(expression)
+	if err != nil {
+		return false, "", fmt.Errorf("invalid %s: %s", envLogPattern, err)
+	}
+	tailLines := int64(100)
+	if value, exists := lookup(envLogTailLines); exists {
+		tailLines, err = strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return false, "", fmt.Errorf("invalid %s: %s", envLogTailLines, err)
+		}
+		if tailLines <= 0 {
+			return false, "", fmt.Errorf("invalid %s: must be positive", envLogTailLines)
+		}
+	}
+	rule.pattern = compiled
+	rule.tailLines = tailLines
+	return true, fmt.Sprintf("last %d log lines matching %s", tailLines, pattern), nil
+}
+
+func (rule *logPattern) ShouldReap(pod v1.Pod) (bool, string) {
+	return rule.shouldReapContext(context.Background(), pod)
+}
+
+func (rule *logPattern) shouldReapContext(ctx context.Context, pod v1.Pod) (bool, string) {
+	if pod.Status.Phase != v1.PodRunning {
+		return false, ""
+	}
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if containerStatus.State.Running == nil {
+			continue
+		}
+		line, err := rule.match(ctx, pod, containerStatus.Name)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"pod":       pod.Name,
+				"namespace": pod.Namespace,
+				"container": containerStatus.Name,
+			}).WithError(err).Warn("unable to get container logs")
+			continue
+		}
+		if line != "" {
+			return true, fmt.Sprintf("has container %s logging %q", containerStatus.Name, line)
+		}
+	}
+	return false, ""
+}
+
+// match returns the last of the container's recent log lines that matches the pattern, or the empty string if none
+// match.
+func (rule *logPattern) match(ctx context.Context, pod v1.Pod, container string) (string, error) {
+	ctx, cancel := apiContext(ctx)
+	defer cancel()
+	client, err := kubernetesClient(ctx, rule.client)
+	if err != nil {
+		return "", err
+	}
+	limitBytes := int64(logLimitBytes)
+	logs, err := client.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &v1.PodLogOptions{
+		Container:  container,
+		TailLines:  &rule.tailLines,
+		LimitBytes: &limitBytes,
+	}).DoRaw(ctx)
+	if err != nil {
+		return "", err
+	}
+	matched := ""
+	scanner := bufio.NewScanner(bytes.NewReader(logs))
+	scanner.Buffer(make([]byte, 0, 64*1024), logLimitBytes)
+	for scanner.Scan() {
+		if rule.pattern.Match(scanner.Bytes()) {
+			matched = scanner.Text()
+		}
+	}
+	if len(matched) > maxLogLineLength {
+		matched = matched[:maxLogLineLength] + "..."
+	}
+	return matched, scanner.Err()
+}
@@ -0,0 +1,142 @@
+package rules
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// testLogServer serves the logs of the containers of the pod named "web" by container name.
+func testLogServer(t *testing.T, logs map[string]string) kubernetes.Interface {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/namespaces/default/pods/web/log", r.URL.Path)
+		assert.Equal(t, "50", r.URL.Query().Get("tailLines"))
+		container := r.URL.Query().Get("container")
+		if _, exists := logs[container]; !exists {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(logs[container]))
+	}))
+	t.Cleanup(server.Close)
+	client, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+	assert.NoError(t, err)
+	return client
+}
+
+func testLoggingPod(containers ...string) v1.Pod {
+	pod := v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Status:     v1.PodStatus{Phase: v1.PodRunning},
+	}
+	for _, container := range containers {
+		pod.Status.ContainerStatuses = append(pod.Status.ContainerStatuses, v1.ContainerStatus{
+			Name:  container,
+			State: v1.ContainerState{Running: &v1.ContainerStateRunning{}},
+		})
+	}
+	return pod
+}
+
+func TestLogPatternLoad(t *testing.T) {
+	t.Run("load", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envLogPattern, "OutOfMemoryError|deadlock detected")
+		rule := logPattern{}
+		loaded, message, err := rule.Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.True(t, loaded)
+		assert.Equal(t, "last 100 log lines matching OutOfMemoryError|deadlock detected", message)
+		assert.Equal(t, int64(100), rule.tailLines)
+		// the logs are read with the client of the cluster the rules are evaluated against
+		assert.Nil(t, rule.client)
+	})
+	t.Run("tail lines", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envLogPattern, "fatal")
+		os.Setenv(envLogTailLines, "20")
+		rule := logPattern{}
+		_, _, err := rule.Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(20), rule.tailLines)
+	})
+	t.Run("case insensitive", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envLogPattern, "fatal")
+		os.Setenv(envCaseInsensitive, "true")
+		rule := logPattern{}
+		_, _, err := rule.Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.True(t, rule.pattern.MatchString("FATAL: shutting down"))
+	})
+	for name, env := range map[string]map[string]string{
+		"invalid pattern":         {envLogPattern: "fatal("},
+		"invalid tail lines":      {envLogPattern: "fatal", envLogTailLines: "all"},
+		"non-positive tail lines": {envLogPattern: "fatal", envLogTailLines: "0"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			os.Clearenv()
+			for key, value := range env {
+				os.Setenv(key, value)
+			}
+			loaded, _, err := (&logPattern{}).Load(os.LookupEnv)
+			assert.Error(t, err)
+			assert.False(t, loaded)
+		})
+	}
+	t.Run("no load", func(t *testing.T) {
+		os.Clearenv()
+		loaded, message, err := (&logPattern{}).Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.Equal(t, "", message)
+		assert.False(t, loaded)
+	})
+}
+
+func TestLogPatternShouldReap(t *testing.T) {
+	client := testLogServer(t, map[string]string{
+		"app":     "starting\nException in thread \"main\" java.lang.OutOfMemoryError: Java heap space\n",
+		"sidecar": "ready\nserving\n",
+		"long":    "deadlock detected " + strings.Repeat("x", 300) + "\n",
+	})
+	rule := logPattern{pattern: regexp.MustCompile("OutOfMemoryError|deadlock detected"), tailLines: 50, client: client}
+	t.Run("matching container", func(t *testing.T) {
+		shouldReap, reason := rule.ShouldReap(testLoggingPod("sidecar", "app"))
+		assert.True(t, shouldReap)
+		assert.Equal(t, `has container app logging "Exception in thread \"main\" java.lang.OutOfMemoryError: Java heap space"`, reason)
+	})
+	t.Run("no match", func(t *testing.T) {
+		shouldReap, _ := rule.ShouldReap(testLoggingPod("sidecar"))
+		assert.False(t, shouldReap)
+	})
+	t.Run("long line is truncated", func(t *testing.T) {
+		shouldReap, reason := rule.ShouldReap(testLoggingPod("long"))
+		assert.True(t, shouldReap)
+		assert.Len(t, reason, len(`has container long logging ""`)+maxLogLineLength+len("..."))
+	})
+	t.Run("logs unavailable", func(t *testing.T) {
+		shouldReap, _ := rule.ShouldReap(testLoggingPod("missing"))
+		assert.False(t, shouldReap)
+	})
+	t.Run("container not running", func(t *testing.T) {
+		pod := testLoggingPod("app")
+		pod.Status.ContainerStatuses[0].State = v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}
+		shouldReap, _ := rule.ShouldReap(pod)
+		assert.False(t, shouldReap)
+	})
+	t.Run("pod not running", func(t *testing.T) {
+		pod := testLoggingPod("app")
+		pod.Status.Phase = v1.PodPending
+		shouldReap, _ := rule.ShouldReap(pod)
+		assert.False(t, shouldReap)
+	})
+}
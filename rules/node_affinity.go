@@ -0,0 +1,192 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/client-go/kubernetes"
+)
+
+const envNodeAffinityMismatch = "NODE_AFFINITY_MISMATCH"
+
+// nodes are cached so the cluster's nodes are listed at most once per ttl
+const nodeCacheTTL = time.Minute
+
+var _ Rule = (*nodeAffinity)(nil)
+var _ contextRule = (*nodeAffinity)(nil)
+
+// nodeAffinity flags pods running on a node that no longer satisfies the pod's node selector or required node
+// affinity, which the scheduler only checks when the pod is scheduled. This happens when node labels change after
+// scheduling.
+type nodeAffinity struct {
+	nodes *nodeCache
+}
+
+func (rule *nodeAffinity) Load(lookup LookupFunc) (bool, string, error) {
+	value, exists := lookup(envNodeAffinityMismatch)
+	if !exists {
+		return false, "", nil
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, "", fmt.Errorf("invalid %s: %s", envNodeAffinityMismatch, err)
+	}
+	if !enabled {
+		return false, "", nil
+	}
+	return true, "node affinity mismatch", nil
+}
+
+func (rule *nodeAffinity) ShouldReap(pod v1.Pod) (bool, string) {
+	return rule.shouldReapContext(context.Background(), pod)
+}
+
+func (rule *nodeAffinity) shouldReapContext(ctx context.Context, pod v1.Pod) (bool, string) {
+	if pod.Spec.NodeName == "" || !hasNodeConstraints(pod) {
+		return false, ""
+	}
+	node, err := getNode(ctx, rule.nodes, pod.Spec.NodeName)
+	if err != nil {
+		logrus.WithField("node", pod.Spec.NodeName).WithError(err).Warn("unable to get node")
+		return false, ""
+	}
+	// pods on deleted nodes are cleaned up by the pod garbage collector
+	if node == nil {
+		return false, ""
+	}
+	if mismatch := nodeMismatch(pod, *node); mismatch != "" {
+		return true, fmt.Sprintf("is running on node %s which %s", node.Name, mismatch)
+	}
+	return false, ""
+}
+
+func hasNodeConstraints(pod v1.Pod) bool {
+	if len(pod.Spec.NodeSelector) > 0 {
+		return true
+	}
+	affinity := pod.Spec.Affinity
+	return affinity != nil && affinity.NodeAffinity != nil &&
+		affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution != nil
+}
+
+// nodeMismatch describes how the node fails the pod's scheduling constraints, or returns the empty string if the node
+// satisfies them.
+func nodeMismatch(pod v1.Pod, node v1.Node) string {
+	if len(pod.Spec.NodeSelector) > 0 && !labels.SelectorFromSet(pod.Spec.NodeSelector).Matches(labels.Set(node.Labels)) {
+		return fmt.Sprintf("does not match the node selector %s", labels.Set(pod.Spec.NodeSelector))
+	}
+	affinity := pod.Spec.Affinity
+	if affinity == nil || affinity.NodeAffinity == nil || affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return ""
+	}
+	// like the scheduler, the terms are ORed and an empty list of terms matches no node
+	for _, term := range affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		if nodeSelectorTermMatches(term, node) {
+			return ""
+		}
+	}
+	return "does not match the required node affinity"
+}
+
+// nodeSelectorTermMatches returns whether the node satisfies every requirement of the term. A term without
+// requirements matches no node.
+func nodeSelectorTermMatches(term v1.NodeSelectorTerm, node v1.Node) bool {
+	if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+		return false
+	}
+	for _, requirement := range term.MatchExpressions {
+		if !nodeSelectorRequirementMatches(requirement, labels.Set(node.Labels)) {
+			return false
+		}
+	}
+	// metadata.name is the only field supported by node affinity
+	fields := labels.Set{"metadata.name": node.Name}
+	for _, requirement := range term.MatchFields {
+		if requirement.Key != "metadata.name" || !nodeSelectorRequirementMatches(requirement, fields) {
+			return false
+		}
+	}
+	return true
+}
+
+func nodeSelectorRequirementMatches(requirement v1.NodeSelectorRequirement, values labels.Set) bool {
+	var operator selection.Operator
+	switch requirement.Operator {
+	case v1.NodeSelectorOpIn:
+		operator = selection.In
+	case v1.NodeSelectorOpNotIn:
+		operator = selection.NotIn
+	case v1.NodeSelectorOpExists:
+		operator = selection.Exists
+	case v1.NodeSelectorOpDoesNotExist:
+		operator = selection.DoesNotExist
+	case v1.NodeSelectorOpGt:
+		operator = selection.GreaterThan
+	case v1.NodeSelectorOpLt:
+		operator = selection.LessThan
+	default:
+		return false
+	}
+	parsed, err := labels.NewRequirement(requirement.Key, operator, requirement.Values)
+	if err != nil {
+		// the api server rejects invalid requirements, so this only guards against values it accepts but labels do not
+		return false
+	}
+	return parsed.Matches(values)
+}
+
+// getNode returns the named node from nodes, or from the node cache of the cluster carried by ctx when nodes is nil.
+func getNode(ctx context.Context, nodes *nodeCache, name string) (*v1.Node, error) {
+	if nodes == nil {
+		cluster, err := clusterFromContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		nodes = cluster.nodes
+	}
+	return nodes.get(ctx, name)
+}
+
+// nodeCache lists the cluster's nodes, caching them by name.
+type nodeCache struct {
+	client kubernetes.Interface
+	mutex  sync.Mutex
+	listed time.Time
+	nodes  map[string]v1.Node
+}
+
+func newNodeCache(client kubernetes.Interface) *nodeCache {
+	return &nodeCache{client: client}
+}
+
+// get returns the named node, or nil if the node does not exist.
+func (cache *nodeCache) get(ctx context.Context, name string) (*v1.Node, error) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if cache.nodes == nil || time.Since(cache.listed) >= nodeCacheTTL {
+		ctx, cancel := apiContext(ctx)
+		defer cancel()
+		nodes, err := cache.client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		cache.nodes = map[string]v1.Node{}
+		for _, node := range nodes.Items {
+			cache.nodes[node.Name] = node
+		}
+		cache.listed = time.Now()
+	}
+	node, exists := cache.nodes[name]
+	if !exists {
+		return nil, nil
+	}
+	return &node, nil
+}
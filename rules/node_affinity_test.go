@@ -0,0 +1,134 @@
+package rules
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func testNode(name string, labels map[string]string) *v1.Node {
+	return &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+}
+
+func testAffinityPod(node string, terms ...v1.NodeSelectorTerm) v1.Pod {
+	pod := v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default"},
+		Spec:       v1.PodSpec{NodeName: node},
+	}
+	if terms != nil {
+		pod.Spec.Affinity = &v1.Affinity{NodeAffinity: &v1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{NodeSelectorTerms: terms},
+		}}
+	}
+	return pod
+}
+
+func testNodeSelectorTerm(key string, operator v1.NodeSelectorOperator, values ...string) v1.NodeSelectorTerm {
+	return v1.NodeSelectorTerm{MatchExpressions: []v1.NodeSelectorRequirement{{Key: key, Operator: operator, Values: values}}}
+}
+
+func TestNodeAffinityLoad(t *testing.T) {
+	t.Run("load", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envNodeAffinityMismatch, "true")
+		rule := nodeAffinity{}
+		loaded, message, err := rule.Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.True(t, loaded)
+		assert.Equal(t, "node affinity mismatch", message)
+		// the nodes are looked up in the node cache of the cluster the rules are evaluated against
+		assert.Nil(t, rule.nodes)
+	})
+	t.Run("disabled", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envNodeAffinityMismatch, "false")
+		loaded, _, err := (&nodeAffinity{}).Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.False(t, loaded)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envNodeAffinityMismatch, "sometimes")
+		_, _, err := (&nodeAffinity{}).Load(os.LookupEnv)
+		assert.Error(t, err)
+	})
+	t.Run("no load", func(t *testing.T) {
+		os.Clearenv()
+		loaded, message, err := (&nodeAffinity{}).Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.Equal(t, "", message)
+		assert.False(t, loaded)
+	})
+}
+
+func TestNodeAffinityShouldReap(t *testing.T) {
+	rule := nodeAffinity{nodes: newNodeCache(fake.NewSimpleClientset(
+		testNode("gpu", map[string]string{"accelerator": "gpu", "cores": "64"}),
+		testNode("cpu", map[string]string{"cores": "8"}),
+	))}
+
+	t.Run("node selector matches", func(t *testing.T) {
+		pod := testAffinityPod("gpu")
+		pod.Spec.NodeSelector = map[string]string{"accelerator": "gpu"}
+		shouldReap, _ := rule.ShouldReap(pod)
+		assert.False(t, shouldReap)
+	})
+	t.Run("node selector mismatch", func(t *testing.T) {
+		pod := testAffinityPod("cpu")
+		pod.Spec.NodeSelector = map[string]string{"accelerator": "gpu"}
+		shouldReap, reason := rule.ShouldReap(pod)
+		assert.True(t, shouldReap)
+		assert.Equal(t, "is running on node cpu which does not match the node selector accelerator=gpu", reason)
+	})
+	t.Run("affinity matches", func(t *testing.T) {
+		shouldReap, _ := rule.ShouldReap(testAffinityPod("gpu", testNodeSelectorTerm("accelerator", v1.NodeSelectorOpExists)))
+		assert.False(t, shouldReap)
+	})
+	t.Run("affinity mismatch", func(t *testing.T) {
+		shouldReap, reason := rule.ShouldReap(testAffinityPod("cpu", testNodeSelectorTerm("accelerator", v1.NodeSelectorOpIn, "gpu")))
+		assert.True(t, shouldReap)
+		assert.Equal(t, "is running on node cpu which does not match the required node affinity", reason)
+	})
+	t.Run("any term matches", func(t *testing.T) {
+		pod := testAffinityPod("cpu",
+			testNodeSelectorTerm("accelerator", v1.NodeSelectorOpIn, "gpu"),
+			testNodeSelectorTerm("cores", v1.NodeSelectorOpLt, "16"))
+		shouldReap, _ := rule.ShouldReap(pod)
+		assert.False(t, shouldReap)
+	})
+	t.Run("numeric comparison", func(t *testing.T) {
+		shouldReap, _ := rule.ShouldReap(testAffinityPod("cpu", testNodeSelectorTerm("cores", v1.NodeSelectorOpGt, "16")))
+		assert.True(t, shouldReap)
+	})
+	t.Run("does not exist", func(t *testing.T) {
+		shouldReap, _ := rule.ShouldReap(testAffinityPod("gpu", testNodeSelectorTerm("accelerator", v1.NodeSelectorOpDoesNotExist)))
+		assert.True(t, shouldReap)
+	})
+	t.Run("match fields", func(t *testing.T) {
+		term := v1.NodeSelectorTerm{MatchFields: []v1.NodeSelectorRequirement{{Key: "metadata.name", Operator: v1.NodeSelectorOpNotIn, Values: []string{"cpu"}}}}
+		shouldReap, _ := rule.ShouldReap(testAffinityPod("cpu", term))
+		assert.True(t, shouldReap)
+		shouldReap, _ = rule.ShouldReap(testAffinityPod("gpu", term))
+		assert.False(t, shouldReap)
+	})
+	t.Run("no constraints", func(t *testing.T) {
+		shouldReap, _ := rule.ShouldReap(testAffinityPod("cpu"))
+		assert.False(t, shouldReap)
+	})
+	t.Run("not scheduled", func(t *testing.T) {
+		pod := testAffinityPod("")
+		pod.Spec.NodeSelector = map[string]string{"accelerator": "gpu"}
+		shouldReap, _ := rule.ShouldReap(pod)
+		assert.False(t, shouldReap)
+	})
+	t.Run("deleted node", func(t *testing.T) {
+		pod := testAffinityPod("gone")
+		pod.Spec.NodeSelector = map[string]string{"accelerator": "gpu"}
+		shouldReap, _ := rule.ShouldReap(pod)
+		assert.False(t, shouldReap)
+	})
+}
@@ -0,0 +1,104 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+)
+
+const envReapOnNodeConditions = "REAP_ON_NODE_CONDITIONS"
+const envNodeConditionDuration = "NODE_CONDITION_DURATION"
+
+// nodeNotReady is the node condition for a node whose Ready condition is not true
+const nodeNotReady = "NotReady"
+
+var _ Rule = (*nodeConditions)(nil)
+var _ contextRule = (*nodeConditions)(nil)
+
+// nodeConditions flags pods scheduled onto nodes that have had any of a set of conditions for a duration, so that
+// their controllers recreate them on healthy nodes. NotReady matches nodes whose Ready condition is false or unknown,
+// and any other condition type matches nodes where the condition is true.
+type nodeConditions struct {
+	conditions []string
+	duration   time.Duration
+	nodes      *nodeCache
+}
+
+func (rule *nodeConditions) Load(lookup LookupFunc) (bool, string, error) {
+	value, active := lookup(envReapOnNodeConditions)
+	if !active {
+		return false, "", nil
+	}
+	var conditions []string
+	for _, condition := range strings.Split(value, ",") {
+		if condition = strings.TrimSpace(condition); condition != "" {
+			conditions = append(conditions, condition)
+		}
+	}
+	if len(conditions) == 0 {
+		return false, "", fmt.Errorf("invalid %s: at least one node condition is required", envReapOnNodeConditions)
+	}
+	durationValue, exists := lookup(envNodeConditionDuration)
+	if !exists {
+		durationValue = "5m"
+	}
+	duration, err := parseDuration(durationValue)
+	if err != nil {
+		return false, "", fmt.Errorf("invalid %s: %s", envNodeConditionDuration, err)
+	}
+	rule.conditions = conditions
+	rule.duration = duration
+	return true, fmt.Sprintf("node conditions in [%s] for %s", strings.Join(conditions, ","), durationValue), nil
+}
+
+func (rule *nodeConditions) ShouldReap(pod v1.Pod) (bool, string) {
+	return rule.shouldReapContext(context.Background(), pod)
+}
+
+func (rule *nodeConditions) shouldReapContext(ctx context.Context, pod v1.Pod) (bool, string) {
+	if pod.Spec.NodeName == "" {
+		return false, ""
+	}
+	node, err := getNode(ctx, rule.nodes, pod.Spec.NodeName)
+	if err != nil {
+		logrus.WithField("node", pod.Spec.NodeName).WithError(err).Warn("unable to get node")
+		return false, ""
+	}
+	if node == nil {
+		return false, ""
+	}
+	for _, condition := range rule.conditions {
+		since, ok := nodeConditionSince(*node, condition)
+		if !ok {
+			continue
+		}
+		if lasted := time.Since(since); lasted >= rule.duration {
+			return true, fmt.Sprintf("is scheduled on node %s which has been %s for %s", node.Name, condition, lasted.Truncate(time.Second))
+		}
+	}
+	return false, ""
+}
+
+// nodeConditionSince returns when the node entered the condition, if it currently has it. Conditions without a
+// transition time are ignored, since how long they lasted is unknown.
+func nodeConditionSince(node v1.Node, condition string) (time.Time, bool) {
+	conditionType := v1.NodeConditionType(condition)
+	if condition == nodeNotReady {
+		conditionType = v1.NodeReady
+	}
+	for _, nodeCondition := range node.Status.Conditions {
+		if nodeCondition.Type != conditionType || nodeCondition.LastTransitionTime.IsZero() {
+			continue
+		}
+		active := nodeCondition.Status == v1.ConditionTrue
+		if condition == nodeNotReady {
+			active = nodeCondition.Status != v1.ConditionTrue
+		}
+		return nodeCondition.LastTransitionTime.Time, active
+	}
+	return time.Time{}, false
+}
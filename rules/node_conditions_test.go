@@ -0,0 +1,104 @@
+package rules
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func testConditionNode(name string, conditionType v1.NodeConditionType, status v1.ConditionStatus, since time.Duration) *v1.Node {
+	node := testNode(name, nil)
+	node.Status.Conditions = []v1.NodeCondition{{
+		Type:               conditionType,
+		Status:             status,
+		LastTransitionTime: metav1.NewTime(time.Now().Add(-since)),
+	}}
+	return node
+}
+
+func TestNodeConditionsLoad(t *testing.T) {
+	t.Run("load", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envReapOnNodeConditions, "NotReady, DiskPressure")
+		rule := nodeConditions{}
+		loaded, message, err := rule.Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.True(t, loaded)
+		assert.Equal(t, "node conditions in [NotReady,DiskPressure] for 5m", message)
+		assert.Equal(t, []string{"NotReady", "DiskPressure"}, rule.conditions)
+		assert.Equal(t, 5*time.Minute, rule.duration)
+		// the nodes are looked up in the node cache of the cluster the rules are evaluated against
+		assert.Nil(t, rule.nodes)
+	})
+	t.Run("duration", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envReapOnNodeConditions, "MemoryPressure")
+		os.Setenv(envNodeConditionDuration, "10m")
+		rule := nodeConditions{}
+		loaded, _, err := rule.Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.True(t, loaded)
+		assert.Equal(t, 10*time.Minute, rule.duration)
+	})
+	t.Run("invalid duration", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envReapOnNodeConditions, "NotReady")
+		os.Setenv(envNodeConditionDuration, "soon")
+		_, _, err := (&nodeConditions{}).Load(os.LookupEnv)
+		assert.Error(t, err)
+	})
+	t.Run("no conditions", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envReapOnNodeConditions, " , ")
+		_, _, err := (&nodeConditions{}).Load(os.LookupEnv)
+		assert.Error(t, err)
+	})
+	t.Run("no load", func(t *testing.T) {
+		os.Clearenv()
+		loaded, message, err := (&nodeConditions{}).Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.Equal(t, "", message)
+		assert.False(t, loaded)
+	})
+}
+
+func TestNodeConditionsShouldReap(t *testing.T) {
+	rule := nodeConditions{
+		conditions: []string{"NotReady", "DiskPressure"},
+		duration:   5 * time.Minute,
+		nodes: newNodeCache(fake.NewSimpleClientset(
+			testConditionNode("not-ready", v1.NodeReady, v1.ConditionFalse, 10*time.Minute),
+			testConditionNode("unknown", v1.NodeReady, v1.ConditionUnknown, 10*time.Minute),
+			testConditionNode("recently-not-ready", v1.NodeReady, v1.ConditionFalse, time.Minute),
+			testConditionNode("ready", v1.NodeReady, v1.ConditionTrue, 10*time.Minute),
+			testConditionNode("disk-pressure", v1.NodeDiskPressure, v1.ConditionTrue, 10*time.Minute),
+			testConditionNode("no-disk-pressure", v1.NodeDiskPressure, v1.ConditionFalse, 10*time.Minute),
+			testConditionNode("memory-pressure", v1.NodeMemoryPressure, v1.ConditionTrue, 10*time.Minute),
+		)),
+	}
+	for node, expected := range map[string]bool{
+		"not-ready":          true,
+		"unknown":            true,
+		"recently-not-ready": false,
+		"ready":              false,
+		"disk-pressure":      true,
+		"no-disk-pressure":   false,
+		"memory-pressure":    false,
+		"deleted":            false,
+		"":                   false,
+	} {
+		t.Run(node, func(t *testing.T) {
+			pod := testAffinityPod(node)
+			shouldReap, reason := rule.ShouldReap(pod)
+			assert.Equal(t, expected, shouldReap)
+			if expected {
+				assert.Contains(t, reason, "is scheduled on node "+node+" which has been")
+			}
+		})
+	}
+}
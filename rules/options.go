@@ -0,0 +1,66 @@
+package rules
+
+// Option describes an environment variable that configures the built in rules, for help output.
+type Option struct {
+	// Name is the environment variable
+	Name string
+	// Usage describes the rule the variable enables or configures, and the format of its value
+	Usage string
+	// Boolean is whether the value is "true" or "false"
+	Boolean bool
+}
+
+// Options returns the environment variables of the built in rules, grouped by rule in the order they are evaluated.
+// Rules added with Register are not included.
+func Options() []Option {
+	return []Option{
+		{Name: envChaosChance, Usage: "reap pods at random with this chance, between 0 and 1 (example: 0.01)"},
+		{Name: envChaosOwnerChance, Usage: "reap every pod of a controller owner such as a ReplicaSet at once with this chance, between 0 and 1 (example: 0.001)"},
+		{Name: envChaosNodeChance, Usage: "reap every pod on a node at once with this chance, simulating the failure of one node at a time (example: 0.001)"},
+		{Name: envContainerStatus, Usage: "reap pods with a container in one of these comma-separated waiting or terminated reasons (example: CrashLoopBackOff,ImagePullBackOff)"},
+		{Name: envContainerStatusRegex, Usage: "reap pods with a container waiting or terminated reason matching this regular expression"},
+		{Name: envContainerExitCodes, Usage: "reap pods with a container terminated with one of these comma-separated exit codes (example: 137,143)"},
+		{Name: envResourcePressureWindow, Usage: "reap pods evicted for ephemeral storage, or with a container evicted or OOM killed, within this duration (example: 1h)"},
+		{Name: envLogPattern, Usage: "reap running pods with a container whose recent logs match this regular expression"},
+		{Name: envLogTailLines, Usage: "number of log lines " + envLogPattern + " is matched against (default: 100)"},
+		{Name: envMaxDuration, Usage: "reap pods that have been running for longer than this duration (example: 2h, 7d)"},
+		{Name: envMaxDurationJitter, Usage: "move each pod's " + envMaxDuration + " deadline by up to this percentage (example: 10%)"},
+		{Name: envSoftTTL, Usage: "start reaping pods that have been running for longer than this duration, with a chance growing until " + envSoftTTLMax},
+		{Name: envSoftTTLMax, Usage: "duration after which every pod is reaped by " + envSoftTTL},
+		{Name: envMissingStartTime, Usage: "how " + envMaxDuration + " and " + envSoftTTL + " treat pods that never started: ignore, creation to measure from when they were created, or a duration after which they are reaped (default: ignore)"},
+		{Name: envMaxUnready, Usage: "reap pods that have been unready for longer than this duration (example: 10m)"},
+		{Name: envPodConditions, Usage: "reap pods where a condition has had a status for longer than a duration, as comma-separated type=status:duration (example: PodScheduled=False:15m)"},
+		{Name: envPodStatus, Usage: "reap pods with one of these comma-separated status reasons (example: Evicted)"},
+		{Name: envPodStatusPhase, Usage: "reap pods in one of these comma-separated phases (example: Failed,Unknown)"},
+		{Name: envCaseInsensitive, Usage: "compare statuses, phases, and patterns without regard to case", Boolean: true},
+		{Name: envMaxImageAge, Usage: "reap pods running a container image built longer ago than this duration (example: 30d)"},
+		{Name: envImageCreatedAnnotation, Usage: "pod annotation holding the image creation time (default: pod-reaper/image-created)"},
+		{Name: envImageRegistryLookup, Usage: "look up image creation times in the image registry when the annotation is missing", Boolean: true},
+		{Name: envMaxCompletedAge, Usage: "reap succeeded pods that completed longer ago than this duration (example: 24h)"},
+		{Name: envMaxVulnerabilitySeverity, Usage: "reap pods whose images have a vulnerability of at least this severity: LOW, MEDIUM, HIGH, or CRITICAL"},
+		{Name: envVulnerabilityGracePeriod, Usage: "only reap pods for vulnerabilities reported longer ago than this duration"},
+		{Name: envCertExpiryWindow, Usage: "reap pods whose injected certificate expires within this duration (example: 24h)"},
+		{Name: envCertExpiryAnnotation, Usage: "pod annotation holding the certificate expiry time in RFC 3339 format (default: cert-expiry)"},
+		{Name: envMaxServiceAccountTokenAge, Usage: "reap pods whose projected service account token is older than this duration"},
+		{Name: envTokenRefreshAnnotation, Usage: "pod annotation marking pods that refresh their service account token"},
+		{Name: envMaxOutOfRotation, Usage: "reap running pods that have been out of rotation of every service they back for longer than this duration"},
+		{Name: envNodeAffinityMismatch, Usage: "reap pods whose node no longer satisfies their required node affinity", Boolean: true},
+		{Name: envReapOnNodeConditions, Usage: "reap pods on nodes with one of these comma-separated conditions (example: MemoryPressure,DiskPressure)"},
+		{Name: envNodeConditionDuration, Usage: "how long a node condition must have lasted before its pods are reaped"},
+		{Name: envMaxMemoryUsage, Usage: "reap running pods using more than this memory quantity (example: 2Gi)"},
+		{Name: envMaxCPUUsage, Usage: "reap running pods using more than this cpu quantity (example: 500m)"},
+		{Name: envResourceUsageDuration, Usage: "how long usage must stay above " + envMaxMemoryUsage + " or " + envMaxCPUUsage + " (default: 5m)"},
+		{Name: envPrometheusQuery, Usage: "reap pods for which this PromQL query, a go template of the pod's .Namespace, .Name, and .Labels, crosses " + envPrometheusThreshold},
+		{Name: envPrometheusURL, Usage: "base url of the prometheus HTTP API queried by " + envPrometheusQuery},
+		{Name: envPrometheusThreshold, Usage: "comparison the query result is checked with (example: > 0.05)"},
+		{Name: envPrometheusDuration, Usage: "how long the query result must cross the threshold (default: 5m)"},
+		{Name: envExpectedRuntimeFactor, Usage: "reap running pods that have been running for this factor longer than their expected runtime annotation or job activeDeadlineSeconds (example: 2)"},
+		{Name: envExpectedRuntimeAnnotation, Usage: "pod annotation holding the expected runtime (default: pod-reaper/expected-runtime)"},
+		{Name: envMaxTerminating, Usage: "reap pods that have been terminating for longer than this duration (example: 15m)"},
+		{Name: envExternalRuleURL, Usage: "reap pods that the service at this url decides to reap, when posted the pod as JSON"},
+		{Name: envExternalRuleTimeout, Usage: "how long the service of " + envExternalRuleURL + " has to decide on a pod (default: 5s)"},
+		{Name: envRuleSelectors, Usage: "limit rules to the pods matching a label selector, as semicolon-separated rule:selector (example: chaos:chaos=enabled)"},
+		{Name: envRuleNamespaces, Usage: "limit rules to namespaces, as semicolon-separated rule:namespace,namespace (example: chaos:staging)"},
+		{Name: envRulePriorities, Usage: "order rules and the pods they flag by priority, as semicolon-separated rule:priority (example: unready:10)"},
+	}
+}
@@ -0,0 +1,85 @@
+package rules
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+const day = 24 * time.Hour
+const week = 7 * day
+
+// parseDuration parses a duration like time.ParseDuration, but also accepts the units "d" (24 hours) and "w" (7
+// days), for example "7d" or "1w3d12h". Days and weeks do not account for daylight saving time.
+func parseDuration(value string) (time.Duration, error) {
+	invalid := fmt.Errorf("time: invalid duration %q", value)
+	remaining := value
+	sign := ""
+	if remaining != "" && (remaining[0] == '-' || remaining[0] == '+') {
+		sign, remaining = remaining[:1], remaining[1:]
+	}
+	var long float64
+	hasLong := false
+	standard := ""
+	for remaining != "" {
+		number := leadingNumber(remaining)
+		remaining = remaining[len(number):]
+		unit := remaining[:len(remaining)-len(trimUnit(remaining))]
+		remaining = remaining[len(unit):]
+		switch unit {
+		case "d", "w":
+			count, err := strconv.ParseFloat(number, 64)
+			if err != nil {
+				return 0, invalid
+			}
+			if unit == "w" {
+				count *= float64(week)
+			} else {
+				count *= float64(day)
+			}
+			long += count
+			hasLong = true
+		default:
+			standard += number + unit
+		}
+	}
+	if !hasLong {
+		return time.ParseDuration(value)
+	}
+	var duration time.Duration
+	if standard != "" {
+		parsed, err := time.ParseDuration(standard)
+		if err != nil {
+			return 0, invalid
+		}
+		duration = parsed
+	}
+	if long+float64(duration) > math.MaxInt64 {
+		return 0, invalid
+	}
+	duration += time.Duration(long)
+	if sign == "-" {
+		duration = -duration
+	}
+	return duration, nil
+}
+
+// leadingNumber returns the digits and decimal points at the start of value.
+func leadingNumber(value string) string {
+	end := 0
+	for end < len(value) && (value[end] == '.' || '0' <= value[end] && value[end] <= '9') {
+		end++
+	}
+	return value[:end]
+}
+
+// trimUnit removes the unit at the start of value, up to the next number.
+func trimUnit(value string) string {
+	for i := 0; i < len(value); i++ {
+		if value[i] == '.' || '0' <= value[i] && value[i] <= '9' {
+			return value[i:]
+		}
+	}
+	return ""
+}
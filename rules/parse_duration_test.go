@@ -0,0 +1,40 @@
+package rules
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDuration(t *testing.T) {
+	for value, expected := range map[string]time.Duration{
+		"30m":      30 * time.Minute,
+		"1h30m":    90 * time.Minute,
+		"7d":       7 * 24 * time.Hour,
+		"2w":       14 * 24 * time.Hour,
+		"1.5d":     36 * time.Hour,
+		"1w3d12h":  (7+3)*24*time.Hour + 12*time.Hour,
+		"1d30s":    24*time.Hour + 30*time.Second,
+		"-1d":      -24 * time.Hour,
+		"+1d":      24 * time.Hour,
+		"0":        0,
+		"1d0.5h1s": 24*time.Hour + 30*time.Minute + time.Second,
+	} {
+		t.Run(value, func(t *testing.T) {
+			duration, err := parseDuration(value)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, duration)
+		})
+	}
+	for _, value := range []string{"", "d", "7days", "1d1x", "1..5d", "1d-1h", "100000000w", "forever"} {
+		t.Run("invalid "+value, func(t *testing.T) {
+			_, err := parseDuration(value)
+			assert.Error(t, err)
+		})
+	}
+	t.Run("error message", func(t *testing.T) {
+		_, err := parseDuration("1d1x")
+		assert.EqualError(t, err, `time: invalid duration "1d1x"`)
+	})
+}
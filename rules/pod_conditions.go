@@ -0,0 +1,93 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/api/core/v1"
+)
+
+const envPodConditions = "POD_CONDITIONS"
+
+var _ Rule = (*podConditions)(nil)
+
+// podConditions flags pods where any of a set of conditions has had a status for longer than a duration, for example
+// pods that have not been scheduled for 15 minutes with "PodScheduled=False:15m".
+type podConditions struct {
+	conditions []podConditionExpression
+}
+
+// podConditionExpression is a parsed condition of the form type=status:duration.
+type podConditionExpression struct {
+	conditionType v1.PodConditionType
+	status        v1.ConditionStatus
+	duration      time.Duration
+}
+
+func (expression podConditionExpression) String() string {
+	return fmt.Sprintf("%s=%s for %s", expression.conditionType, expression.status, expression.duration)
+}
+
+func (rule *podConditions) Load(lookup LookupFunc) (bool, string, error) {
+	value, active := lookup(envPodConditions)
+	if !active {
+		return false, "", nil
+	}
+	values, err := loadValues(envPodConditions, value)
+	if err != nil {
+		return false, "", err
+	}
+	var descriptions []string
+	for _, item := range values {
+		expression, err := parsePodCondition(item)
+		if err != nil {
+			return false, "", fmt.Errorf("invalid %s: %s", envPodConditions, err)
+		}
+		rule.conditions = append(rule.conditions, expression)
+		descriptions = append(descriptions, expression.String())
+	}
+	return true, fmt.Sprintf("pod conditions in [%s]", strings.Join(descriptions, ", ")), nil
+}
+
+// parsePodCondition parses an expression of the form type=status:duration. The status is one of True, False, or
+// Unknown, in any case.
+func parsePodCondition(value string) (podConditionExpression, error) {
+	typeAndStatus, durationValue, found := strings.Cut(value, ":")
+	if !found {
+		return podConditionExpression{}, fmt.Errorf("%q is not of the form type=status:duration", value)
+	}
+	conditionType, statusValue, found := strings.Cut(typeAndStatus, "=")
+	conditionType = strings.TrimSpace(conditionType)
+	if !found || conditionType == "" {
+		return podConditionExpression{}, fmt.Errorf("%q is not of the form type=status:duration", value)
+	}
+	var status v1.ConditionStatus
+	for _, known := range []v1.ConditionStatus{v1.ConditionTrue, v1.ConditionFalse, v1.ConditionUnknown} {
+		if strings.EqualFold(strings.TrimSpace(statusValue), string(known)) {
+			status = known
+		}
+	}
+	if status == "" {
+		return podConditionExpression{}, fmt.Errorf("%q has status %q, which is not True, False, or Unknown", value, statusValue)
+	}
+	duration, err := parseDuration(strings.TrimSpace(durationValue))
+	if err != nil {
+		return podConditionExpression{}, fmt.Errorf("%q: %s", value, err)
+	}
+	return podConditionExpression{conditionType: v1.PodConditionType(conditionType), status: status, duration: duration}, nil
+}
+
+func (rule *podConditions) ShouldReap(pod v1.Pod) (bool, string) {
+	for _, expression := range rule.conditions {
+		condition := getCondition(pod, expression.conditionType)
+		// like the unready rule, conditions without a transition time are skipped since how long they lasted is unknown
+		if condition == nil || condition.Status != expression.status || condition.LastTransitionTime.IsZero() {
+			continue
+		}
+		if lasted := time.Since(condition.LastTransitionTime.Time); lasted >= expression.duration {
+			return true, fmt.Sprintf("has had condition %s=%s for %s", condition.Type, condition.Status, lasted.Truncate(time.Second))
+		}
+	}
+	return false, ""
+}
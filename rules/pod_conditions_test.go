@@ -0,0 +1,92 @@
+package rules
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testConditionPod(conditionType v1.PodConditionType, status v1.ConditionStatus, since time.Duration) v1.Pod {
+	return v1.Pod{Status: v1.PodStatus{Conditions: []v1.PodCondition{{
+		Type:               conditionType,
+		Status:             status,
+		LastTransitionTime: metav1.NewTime(time.Now().Add(-since)),
+	}}}}
+}
+
+func TestPodConditionsLoad(t *testing.T) {
+	t.Run("load", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envPodConditions, "PodScheduled=False:15m, Ready=unknown:1d")
+		rule := podConditions{}
+		loaded, message, err := rule.Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.True(t, loaded)
+		assert.Equal(t, "pod conditions in [PodScheduled=False for 15m0s, Ready=Unknown for 24h0m0s]", message)
+		assert.Equal(t, []podConditionExpression{
+			{conditionType: v1.PodScheduled, status: v1.ConditionFalse, duration: 15 * time.Minute},
+			{conditionType: v1.PodReady, status: v1.ConditionUnknown, duration: 24 * time.Hour},
+		}, rule.conditions)
+	})
+	for name, value := range map[string]string{
+		"no values":        " , ",
+		"missing duration": "PodScheduled=False",
+		"missing status":   "PodScheduled:15m",
+		"missing type":     "=False:15m",
+		"invalid status":   "PodScheduled=No:15m",
+		"invalid duration": "PodScheduled=False:soon",
+	} {
+		t.Run(name, func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(envPodConditions, value)
+			loaded, _, err := (&podConditions{}).Load(os.LookupEnv)
+			assert.Error(t, err)
+			assert.False(t, loaded)
+		})
+	}
+	t.Run("no load", func(t *testing.T) {
+		os.Clearenv()
+		loaded, message, err := (&podConditions{}).Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.Equal(t, "", message)
+		assert.False(t, loaded)
+	})
+}
+
+func TestPodConditionsShouldReap(t *testing.T) {
+	rule := podConditions{conditions: []podConditionExpression{
+		{conditionType: v1.PodScheduled, status: v1.ConditionFalse, duration: 15 * time.Minute},
+		{conditionType: "example.com/Healthy", status: v1.ConditionFalse, duration: time.Hour},
+	}}
+	t.Run("unscheduled", func(t *testing.T) {
+		shouldReap, reason := rule.ShouldReap(testConditionPod(v1.PodScheduled, v1.ConditionFalse, 20*time.Minute))
+		assert.True(t, shouldReap)
+		assert.Equal(t, "has had condition PodScheduled=False for 20m0s", reason)
+	})
+	t.Run("recently unscheduled", func(t *testing.T) {
+		shouldReap, _ := rule.ShouldReap(testConditionPod(v1.PodScheduled, v1.ConditionFalse, 5*time.Minute))
+		assert.False(t, shouldReap)
+	})
+	t.Run("other status", func(t *testing.T) {
+		shouldReap, _ := rule.ShouldReap(testConditionPod(v1.PodScheduled, v1.ConditionTrue, 20*time.Minute))
+		assert.False(t, shouldReap)
+	})
+	t.Run("readiness gate condition", func(t *testing.T) {
+		shouldReap, _ := rule.ShouldReap(testConditionPod("example.com/Healthy", v1.ConditionFalse, 2*time.Hour))
+		assert.True(t, shouldReap)
+	})
+	t.Run("no transition time", func(t *testing.T) {
+		pod := testConditionPod(v1.PodScheduled, v1.ConditionFalse, 0)
+		pod.Status.Conditions[0].LastTransitionTime = metav1.Time{}
+		shouldReap, _ := rule.ShouldReap(pod)
+		assert.False(t, shouldReap)
+	})
+	t.Run("no conditions", func(t *testing.T) {
+		shouldReap, _ := rule.ShouldReap(v1.Pod{})
+		assert.False(t, shouldReap)
+	})
+}
@@ -2,7 +2,6 @@ package rules
 
 import (
 	"fmt"
-	"os"
 	"strings"
 
 	"k8s.io/api/core/v1"
@@ -13,23 +12,33 @@ const envPodStatus = "POD_STATUSES"
 var _ Rule = (*podStatus)(nil)
 
 type podStatus struct {
-	reapStatuses []string
+	reapStatuses    []string
+	caseInsensitive bool
 }
 
-func (rule *podStatus) load() (bool, string, error) {
-	value, active := os.LookupEnv(envPodStatus)
+func (rule *podStatus) Load(lookup LookupFunc) (bool, string, error) {
+	value, active := lookup(envPodStatus)
 	if !active {
 		return false, "", nil
 	}
-	rule.reapStatuses = strings.Split(value, ",")
-	return true, fmt.Sprintf("pod status in [%s]", value), nil
+	statuses, err := loadValues(envPodStatus, value)
+	if err != nil {
+		return false, "", err
+	}
+	insensitive, err := caseInsensitive(lookup)
+	if err != nil {
+		return false, "", err
+	}
+	rule.reapStatuses = statuses
+	rule.caseInsensitive = insensitive
+	return true, fmt.Sprintf("pod status in [%s]", strings.Join(statuses, ",")), nil
 }
 
 func (rule *podStatus) ShouldReap(pod v1.Pod) (bool, string) {
 	status := pod.Status.Reason
 	for _, reapStatus := range rule.reapStatuses {
-		if status == reapStatus {
-			return true, fmt.Sprintf("has pod status %s", reapStatus)
+		if equalValues(status, reapStatus, rule.caseInsensitive) {
+			return true, fmt.Sprintf("has pod status %s", status)
 		}
 	}
 	return false, ""
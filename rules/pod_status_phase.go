@@ -2,7 +2,6 @@ package rules
 
 import (
 	"fmt"
-	"os"
 	"strings"
 
 	v1 "k8s.io/api/core/v1"
@@ -14,22 +13,32 @@ var _ Rule = (*podStatusPhase)(nil)
 
 type podStatusPhase struct {
 	reapStatusPhases []string
+	caseInsensitive  bool
 }
 
-func (rule *podStatusPhase) load() (bool, string, error) {
-	value, active := os.LookupEnv(envPodStatusPhase)
+func (rule *podStatusPhase) Load(lookup LookupFunc) (bool, string, error) {
+	value, active := lookup(envPodStatusPhase)
 	if !active {
 		return false, "", nil
 	}
-	rule.reapStatusPhases = strings.Split(value, ",")
-	return true, fmt.Sprintf("pod status phase in [%s]", value), nil
+	phases, err := loadValues(envPodStatusPhase, value)
+	if err != nil {
+		return false, "", err
+	}
+	insensitive, err := caseInsensitive(lookup)
+	if err != nil {
+		return false, "", err
+	}
+	rule.reapStatusPhases = phases
+	rule.caseInsensitive = insensitive
+	return true, fmt.Sprintf("pod status phase in [%s]", strings.Join(phases, ",")), nil
 }
 
 func (rule *podStatusPhase) ShouldReap(pod v1.Pod) (bool, string) {
 	status := string(pod.Status.Phase)
 	for _, reapStatusPhase := range rule.reapStatusPhases {
-		if status == reapStatusPhase {
-			return true, fmt.Sprintf("has pod status phase %s", reapStatusPhase)
+		if equalValues(status, reapStatusPhase, rule.caseInsensitive) {
+			return true, fmt.Sprintf("has pod status phase %s", status)
 		}
 	}
 	return false, ""
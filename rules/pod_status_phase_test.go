@@ -20,7 +20,7 @@ func TestPodPhaseLoad(t *testing.T) {
 	t.Run("load", func(t *testing.T) {
 		os.Clearenv()
 		os.Setenv(envPodStatusPhase, "test-phase")
-		loaded, message, err := (&podStatusPhase{}).load()
+		loaded, message, err := (&podStatusPhase{}).Load(os.LookupEnv)
 		assert.NoError(t, err)
 		assert.Equal(t, "pod status phase in [test-phase]", message)
 		assert.True(t, loaded)
@@ -29,7 +29,7 @@ func TestPodPhaseLoad(t *testing.T) {
 		os.Clearenv()
 		os.Setenv(envPodStatusPhase, "test-phase,another-phase")
 		podStatusPhase := podStatusPhase{}
-		loaded, message, err := podStatusPhase.load()
+		loaded, message, err := podStatusPhase.Load(os.LookupEnv)
 		assert.NoError(t, err)
 		assert.Equal(t, "pod status phase in [test-phase,another-phase]", message)
 		assert.True(t, loaded)
@@ -39,7 +39,7 @@ func TestPodPhaseLoad(t *testing.T) {
 	})
 	t.Run("no load", func(t *testing.T) {
 		os.Clearenv()
-		loaded, message, err := (&podStatusPhase{}).load()
+		loaded, message, err := (&podStatusPhase{}).Load(os.LookupEnv)
 		assert.NoError(t, err)
 		assert.Equal(t, "", message)
 		assert.False(t, loaded)
@@ -51,7 +51,7 @@ func TestPodStatusPhaseShouldReap(t *testing.T) {
 		os.Clearenv()
 		os.Setenv(envPodStatusPhase, "test-phase,another-phase")
 		podStatusPhase := podStatusPhase{}
-		podStatusPhase.load()
+		podStatusPhase.Load(os.LookupEnv)
 		pod := testPodFromPhase("another-phase")
 		shouldReap, reason := podStatusPhase.ShouldReap(pod)
 		assert.True(t, shouldReap)
@@ -61,28 +61,45 @@ func TestPodStatusPhaseShouldReap(t *testing.T) {
 		os.Clearenv()
 		os.Setenv(envPodStatusPhase, "test-phase,another-phase")
 		podStatusPhase := podStatusPhase{}
-		podStatusPhase.load()
+		podStatusPhase.Load(os.LookupEnv)
 		pod := testPodFromPhase("not-present")
 		shouldReap, _ := podStatusPhase.ShouldReap(pod)
 		assert.False(t, shouldReap)
 	})
-	t.Run("whitespace in values not trimmed", func(t *testing.T) {
+	t.Run("whitespace in values is trimmed", func(t *testing.T) {
 		os.Clearenv()
-		os.Setenv(envPodStatusPhase, "Failed, Unknown")
+		os.Setenv(envPodStatusPhase, " Failed, Unknown ,")
 		psp := podStatusPhase{}
-		psp.load()
-		// The second value is " Unknown" with leading space
-		assert.Equal(t, " Unknown", psp.reapStatusPhases[1])
-		// Pod with "Unknown" (no space) won't match " Unknown"
+		_, message, err := psp.Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.Equal(t, "pod status phase in [Failed,Unknown]", message)
+		assert.Equal(t, []string{"Failed", "Unknown"}, psp.reapStatusPhases)
 		pod := testPodFromPhase(v1.PodUnknown)
 		shouldReap, _ := psp.ShouldReap(pod)
-		assert.False(t, shouldReap)
+		assert.True(t, shouldReap)
+	})
+	t.Run("no values", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envPodStatusPhase, " , ")
+		loaded, _, err := (&podStatusPhase{}).Load(os.LookupEnv)
+		assert.Error(t, err)
+		assert.False(t, loaded)
+	})
+	t.Run("case insensitive", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envPodStatusPhase, "failed")
+		os.Setenv(envCaseInsensitive, "true")
+		psp := podStatusPhase{}
+		psp.Load(os.LookupEnv)
+		shouldReap, reason := psp.ShouldReap(testPodFromPhase(v1.PodFailed))
+		assert.True(t, shouldReap)
+		assert.Equal(t, "has pod status phase Failed", reason)
 	})
 	t.Run("case sensitivity - lowercase fails to match", func(t *testing.T) {
 		os.Clearenv()
 		os.Setenv(envPodStatusPhase, "failed")
 		psp := podStatusPhase{}
-		psp.load()
+		psp.Load(os.LookupEnv)
 		pod := testPodFromPhase(v1.PodFailed) // "Failed" in K8s
 		shouldReap, _ := psp.ShouldReap(pod)
 		assert.False(t, shouldReap) // "failed" != "Failed"
@@ -100,7 +117,7 @@ func TestPodStatusPhaseShouldReap(t *testing.T) {
 				os.Clearenv()
 				os.Setenv(envPodStatusPhase, string(phase))
 				psp := podStatusPhase{}
-				psp.load()
+				psp.Load(os.LookupEnv)
 				pod := testPodFromPhase(phase)
 				shouldReap, reason := psp.ShouldReap(pod)
 				assert.True(t, shouldReap)
@@ -20,7 +20,7 @@ func TestPodStatusLoad(t *testing.T) {
 	t.Run("load", func(t *testing.T) {
 		os.Clearenv()
 		os.Setenv(envPodStatus, "test-status")
-		loaded, message, err := (&podStatus{}).load()
+		loaded, message, err := (&podStatus{}).Load(os.LookupEnv)
 		assert.NoError(t, err)
 		assert.Equal(t, "pod status in [test-status]", message)
 		assert.True(t, loaded)
@@ -29,7 +29,7 @@ func TestPodStatusLoad(t *testing.T) {
 		os.Clearenv()
 		os.Setenv(envPodStatus, "test-status,another-status")
 		podStatus := podStatus{}
-		loaded, message, err := podStatus.load()
+		loaded, message, err := podStatus.Load(os.LookupEnv)
 		assert.NoError(t, err)
 		assert.Equal(t, "pod status in [test-status,another-status]", message)
 		assert.True(t, loaded)
@@ -39,7 +39,7 @@ func TestPodStatusLoad(t *testing.T) {
 	})
 	t.Run("no load", func(t *testing.T) {
 		os.Clearenv()
-		loaded, message, err := (&podStatus{}).load()
+		loaded, message, err := (&podStatus{}).Load(os.LookupEnv)
 		assert.NoError(t, err)
 		assert.Equal(t, "", message)
 		assert.False(t, loaded)
@@ -51,7 +51,7 @@ func TestPodStatusShouldReap(t *testing.T) {
 		os.Clearenv()
 		os.Setenv(envPodStatus, "test-status,another-status")
 		podStatus := podStatus{}
-		podStatus.load()
+		podStatus.Load(os.LookupEnv)
 		pod := testPodFromReason("another-status")
 		shouldReap, reason := podStatus.ShouldReap(pod)
 		assert.True(t, shouldReap)
@@ -61,41 +61,53 @@ func TestPodStatusShouldReap(t *testing.T) {
 		os.Clearenv()
 		os.Setenv(envPodStatus, "test-status,another-status")
 		podStatus := podStatus{}
-		podStatus.load()
+		podStatus.Load(os.LookupEnv)
 		pod := testPodFromReason("not-present")
 		shouldReap, _ := podStatus.ShouldReap(pod)
 		assert.False(t, shouldReap)
 	})
-	t.Run("whitespace in values not trimmed", func(t *testing.T) {
+	t.Run("whitespace in values is trimmed", func(t *testing.T) {
 		os.Clearenv()
 		os.Setenv(envPodStatus, "Evicted, Unknown")
 		ps := podStatus{}
-		ps.load()
-		// The second value is " Unknown" with leading space
-		assert.Equal(t, " Unknown", ps.reapStatuses[1])
-		// Pod with "Unknown" (no space) won't match " Unknown"
+		ps.Load(os.LookupEnv)
+		assert.Equal(t, []string{"Evicted", "Unknown"}, ps.reapStatuses)
 		pod := testPodFromReason("Unknown")
 		shouldReap, _ := ps.ShouldReap(pod)
-		assert.False(t, shouldReap)
+		assert.True(t, shouldReap)
 	})
 	t.Run("case sensitivity - no match", func(t *testing.T) {
 		os.Clearenv()
 		os.Setenv(envPodStatus, "evicted")
 		ps := podStatus{}
-		ps.load()
+		ps.Load(os.LookupEnv)
 		pod := testPodFromReason("Evicted")
 		shouldReap, _ := ps.ShouldReap(pod)
 		assert.False(t, shouldReap) // "evicted" != "Evicted"
 	})
-	t.Run("empty reason matches empty status", func(t *testing.T) {
+	t.Run("empty value is an error", func(t *testing.T) {
+		// an empty value used to match every pod without a status reason
 		os.Clearenv()
 		os.Setenv(envPodStatus, "")
+		loaded, _, err := (&podStatus{}).Load(os.LookupEnv)
+		assert.Error(t, err)
+		assert.False(t, loaded)
+	})
+	t.Run("case insensitive", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envPodStatus, "evicted")
+		os.Setenv(envCaseInsensitive, "true")
 		ps := podStatus{}
-		ps.load()
-		// reapStatuses will be [""] (single empty string)
-		assert.Equal(t, []string{""}, ps.reapStatuses)
-		pod := testPodFromReason("")
-		shouldReap, _ := ps.ShouldReap(pod)
-		assert.True(t, shouldReap) // "" == ""
+		ps.Load(os.LookupEnv)
+		shouldReap, reason := ps.ShouldReap(testPodFromReason("Evicted"))
+		assert.True(t, shouldReap)
+		assert.Equal(t, "has pod status Evicted", reason)
+	})
+	t.Run("invalid case insensitive", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envPodStatus, "Evicted")
+		os.Setenv(envCaseInsensitive, "sometimes")
+		_, _, err := (&podStatus{}).Load(os.LookupEnv)
+		assert.Error(t, err)
 	})
 }
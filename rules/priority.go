@@ -0,0 +1,56 @@
+package rules
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"k8s.io/api/core/v1"
+)
+
+const envRulePriorities = "RULE_PRIORITIES"
+
+// loadPriorities loads the priorities of the loaded rules from RULE_PRIORITIES, which lists entries of the form
+// rule:priority separated by semicolons. Rules that are not listed have priority 0.
+func loadPriorities(lookup LookupFunc, loadedRules []Rule) (map[string]int, error) {
+	loaded := map[string]bool{}
+	for _, rule := range loadedRules {
+		loaded[ruleName(rule)] = true
+	}
+	entries, err := scopeEntries(lookup, envRulePriorities, loaded)
+	if err != nil {
+		return nil, err
+	}
+	priorities := map[string]int{}
+	for name, value := range entries {
+		priority, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s for rule %s: %s", envRulePriorities, name, err)
+		}
+		priorities[name] = priority
+	}
+	return priorities, nil
+}
+
+// sortByPriority orders the rules from the highest priority to the lowest, keeping the registry order between rules
+// of the same priority.
+func sortByPriority(loadedRules []Rule, priorities map[string]int) {
+	sort.SliceStable(loadedRules, func(i, j int) bool {
+		return priorities[ruleName(loadedRules[i])] > priorities[ruleName(loadedRules[j])]
+	})
+}
+
+// Priority returns the highest priority of the rules that apply to the pod, so that pods flagged by higher priority
+// rules can be reaped first. It returns 0 when no priorities are set or no rule applies to the pod.
+func (rules Rules) Priority(pod v1.Pod) int {
+	priority, found := 0, false
+	for _, rule := range rules.LoadedRules {
+		if !rules.inScope(rule, pod) {
+			continue
+		}
+		if rulePriority := rules.priorities[ruleName(rule)]; !found || rulePriority > priority {
+			priority, found = rulePriority, true
+		}
+	}
+	return priority
+}
@@ -0,0 +1,66 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadPriorities(t *testing.T) {
+	config := map[string]string{envChaosChance: "1.0", envMaxDuration: "1m", envPodStatusPhase: "Running"}
+	load := func(priorities string) (Rules, error) {
+		return LoadRulesFromMap(map[string]string{
+			envChaosChance:    config[envChaosChance],
+			envMaxDuration:    config[envMaxDuration],
+			envPodStatusPhase: config[envPodStatusPhase],
+			envRulePriorities: priorities,
+		})
+	}
+	t.Run("registry order", func(t *testing.T) {
+		loaded, err := LoadRulesFromMap(config)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"chaos", "duration", "podStatusPhase"}, loaded.Names())
+	})
+	t.Run("ordered by priority", func(t *testing.T) {
+		loaded, err := load("podStatusPhase: 10; chaos:-1")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"podStatusPhase", "duration", "chaos"}, loaded.Names())
+		assert.Equal(t, map[string]int{"podStatusPhase": 10, "chaos": -1}, loaded.priorities)
+	})
+	t.Run("reasons ordered by priority", func(t *testing.T) {
+		loaded, err := load("podStatusPhase:10")
+		assert.NoError(t, err)
+		pod := testPod()
+		pod.Status.Phase = "Running"
+		shouldReap, reasons := loaded.ShouldReap(pod)
+		assert.True(t, shouldReap)
+		if assert.Len(t, reasons, 3) {
+			assert.Contains(t, reasons[0], "Running")
+			assert.Contains(t, reasons[2], "has been running")
+		}
+	})
+	t.Run("invalid priority", func(t *testing.T) {
+		_, err := load("chaos:high")
+		assert.Error(t, err)
+	})
+	t.Run("unknown rule", func(t *testing.T) {
+		_, err := load("unready:10")
+		assert.EqualError(t, err, "invalid RULE_PRIORITIES: unready is not a loaded rule")
+	})
+}
+
+func TestPriority(t *testing.T) {
+	loaded, err := LoadRulesFromMap(map[string]string{
+		envChaosChance:    "1.0",
+		envMaxDuration:    "1m",
+		envRuleSelectors:  "chaos:urgent=true",
+		envRulePriorities: "chaos:10;duration:-5",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 10, loaded.Priority(testScopedPod("default", map[string]string{"urgent": "true"})))
+	assert.Equal(t, -5, loaded.Priority(testScopedPod("default", nil)))
+
+	unprioritized, err := LoadRulesFromMap(map[string]string{envChaosChance: "1.0"})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, unprioritized.Priority(testPod()))
+}
@@ -0,0 +1,221 @@
+package rules
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+)
+
+const envPrometheusURL = "PROMETHEUS_URL"
+const envPrometheusQuery = "PROMETHEUS_QUERY"
+const envPrometheusThreshold = "PROMETHEUS_THRESHOLD"
+const envPrometheusDuration = "PROMETHEUS_DURATION"
+
+var _ Rule = (*prometheusQuery)(nil)
+
+// prometheusQuery flags pods for which the result of a PromQL query, templated with the pod's namespace, name, and
+// labels, has crossed a threshold at every evaluation for a duration. Pods are never flagged while prometheus cannot
+// be queried.
+type prometheusQuery struct {
+	query     *template.Template
+	threshold threshold
+	duration  time.Duration
+	client    *prometheusClient
+	// sustained tracks the pods whose query result crosses the threshold
+	sustained
+}
+
+// prometheusQueryData is the data the query template is executed with.
+type prometheusQueryData struct {
+	Namespace string
+	Name      string
+	Labels    map[string]string
+}
+
+func (rule *prometheusQuery) Load(lookup LookupFunc) (bool, string, error) {
+	queryValue, active := lookup(envPrometheusQuery)
+	if !active {
+		return false, "", nil
+	}
+	query, err := template.New(envPrometheusQuery).Option("missingkey=error").Parse(queryValue)
+	if err != nil {
+		return false, "", fmt.Errorf("invalid %s: %s", envPrometheusQuery, err)
+	}
+	endpoint, exists := lookup(envPrometheusURL)
+	if !exists {
+		return false, "", fmt.Errorf("specified %s but not %s", envPrometheusQuery, envPrometheusURL)
+	}
+	parsed, err := url.Parse(endpoint)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return false, "", fmt.Errorf("invalid %s: %q is not an absolute url", envPrometheusURL, endpoint)
+	}
+	thresholdValue, exists := lookup(envPrometheusThreshold)
+	if !exists {
+		return false, "", fmt.Errorf("specified %s but not %s", envPrometheusQuery, envPrometheusThreshold)
+	}
+	rule.threshold, err = parseThreshold(thresholdValue)
+	if err != nil {
+		return false, "", fmt.Errorf("invalid %s: %s", envPrometheusThreshold, err)
+	}
+	durationValue, exists := lookup(envPrometheusDuration)
+	if !exists {
+		durationValue = "5m"
+	}
+	rule.duration, err = parseDuration(durationValue)
+	if err != nil {
+		return false, "", fmt.Errorf("invalid %s: %s", envPrometheusDuration, err)
+	}
+	rule.query = query
+	rule.client = newPrometheusClient(strings.TrimSuffix(endpoint, "/"))
+	return true, fmt.Sprintf("prometheus query %s %s for %s", queryValue, rule.threshold, durationValue), nil
+}
+
+func (rule *prometheusQuery) ShouldReap(pod v1.Pod) (bool, string) {
+	var query bytes.Buffer
+	if err := rule.query.Execute(&query, prometheusQueryData{Namespace: pod.Namespace, Name: pod.Name, Labels: pod.Labels}); err != nil {
+		logrus.WithField("pod", pod.Name).WithError(err).Warn("unable to template prometheus query")
+		return false, ""
+	}
+	results, err := rule.client.query(query.String())
+	if err != nil {
+		logrus.WithField("query", query.String()).WithError(err).Warn("unable to query prometheus")
+		return false, ""
+	}
+	crossed, met := 0.0, false
+	for _, result := range results {
+		if rule.threshold.crossed(result) {
+			crossed, met = result, true
+			break
+		}
+	}
+	if lasted, met := rule.observe(pod.UID, met, time.Now()); met && lasted >= rule.duration {
+		return true, fmt.Sprintf("has had prometheus query result %s %s for %s", strconv.FormatFloat(crossed, 'g', -1, 64), rule.threshold, lasted.Truncate(time.Second))
+	}
+	return false, ""
+}
+
+// threshold compares query results with a value, for example "> 0.5".
+type threshold struct {
+	operator string
+	value    float64
+}
+
+// thresholdOperators are the supported comparisons, with the operators that prefix others first.
+var thresholdOperators = []string{">=", "<=", "==", "!=", ">", "<"}
+
+func parseThreshold(value string) (threshold, error) {
+	value = strings.TrimSpace(value)
+	for _, operator := range thresholdOperators {
+		if operand, found := strings.CutPrefix(value, operator); found {
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(operand), 64)
+			if err != nil {
+				return threshold{}, err
+			}
+			return threshold{operator: operator, value: parsed}, nil
+		}
+	}
+	return threshold{}, fmt.Errorf("%q does not start with one of %s", value, strings.Join(thresholdOperators, " "))
+}
+
+func (threshold threshold) crossed(result float64) bool {
+	switch threshold.operator {
+	case ">=":
+		return result >= threshold.value
+	case "<=":
+		return result <= threshold.value
+	case "==":
+		return result == threshold.value
+	case "!=":
+		return result != threshold.value
+	case ">":
+		return result > threshold.value
+	default:
+		return result < threshold.value
+	}
+}
+
+func (threshold threshold) String() string {
+	return threshold.operator + " " + strconv.FormatFloat(threshold.value, 'g', -1, 64)
+}
+
+// prometheusClient runs instant queries with the prometheus HTTP API.
+type prometheusClient struct {
+	client   *http.Client
+	endpoint string
+}
+
+func newPrometheusClient(endpoint string) *prometheusClient {
+	return &prometheusClient{client: &http.Client{Timeout: 10 * time.Second}, endpoint: endpoint}
+}
+
+// query returns the value of every sample of an instant vector or scalar query. A query without samples returns no
+// values.
+func (prometheus *prometheusClient) query(query string) ([]float64, error) {
+	response, err := prometheus.client.Get(prometheus.endpoint + "/api/v1/query?" + url.Values{"query": {query}}.Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	var body struct {
+		Status string `json:"status"`
+		Error  string `json:"error"`
+		Data   struct {
+			ResultType string          `json:"resultType"`
+			Result     json.RawMessage `json:"result"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(io.LimitReader(response.Body, 1<<20)).Decode(&body); err != nil {
+		return nil, fmt.Errorf("unexpected status %s from prometheus: %s", response.Status, err)
+	}
+	if body.Status != "success" {
+		return nil, fmt.Errorf("query failed with status %s: %s", response.Status, body.Error)
+	}
+	var samples [][]interface{}
+	switch body.Data.ResultType {
+	case "vector":
+		var vector []struct {
+			Value []interface{} `json:"value"`
+		}
+		if err := json.Unmarshal(body.Data.Result, &vector); err != nil {
+			return nil, err
+		}
+		for _, sample := range vector {
+			samples = append(samples, sample.Value)
+		}
+	case "scalar":
+		var scalar []interface{}
+		if err := json.Unmarshal(body.Data.Result, &scalar); err != nil {
+			return nil, err
+		}
+		samples = append(samples, scalar)
+	default:
+		return nil, fmt.Errorf("query returned a %s, not an instant vector or scalar", body.Data.ResultType)
+	}
+	values := make([]float64, 0, len(samples))
+	for _, sample := range samples {
+		// samples are [timestamp, "value"]
+		if len(sample) != 2 {
+			return nil, fmt.Errorf("invalid sample %v", sample)
+		}
+		text, ok := sample[1].(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid sample value %v", sample[1])
+		}
+		value, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
@@ -0,0 +1,160 @@
+package rules
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// testPrometheus serves query responses by query.
+func testPrometheus(t *testing.T, responses map[string]string) *prometheusClient {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/query", r.URL.Path)
+		response, exists := responses[r.URL.Query().Get("query")]
+		if !exists {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status":"error","errorType":"bad_data","error":"parse error"}`))
+			return
+		}
+		w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+	return newPrometheusClient(server.URL)
+}
+
+func testVector(values ...string) string {
+	result := ""
+	for i, value := range values {
+		if i > 0 {
+			result += ","
+		}
+		result += `{"metric":{},"value":[1700000000.123,"` + value + `"]}`
+	}
+	return `{"status":"success","data":{"resultType":"vector","result":[` + result + `]}}`
+}
+
+func testQueryPod(name string) v1.Pod {
+	return v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID(name), Labels: map[string]string{"app": "web"}}}
+}
+
+func TestPrometheusQueryLoad(t *testing.T) {
+	t.Run("load", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envPrometheusURL, "http://prometheus.monitoring:9090/")
+		os.Setenv(envPrometheusQuery, `errors{pod="{{.Name}}"}`)
+		os.Setenv(envPrometheusThreshold, ">=0.5")
+		rule := prometheusQuery{}
+		loaded, message, err := rule.Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.True(t, loaded)
+		assert.Equal(t, `prometheus query errors{pod="{{.Name}}"} >= 0.5 for 5m`, message)
+		assert.Equal(t, threshold{operator: ">=", value: 0.5}, rule.threshold)
+		assert.Equal(t, 5*time.Minute, rule.duration)
+		assert.Equal(t, "http://prometheus.monitoring:9090", rule.client.endpoint)
+	})
+	for name, env := range map[string]map[string]string{
+		"missing url":       {envPrometheusQuery: "up", envPrometheusThreshold: "< 1"},
+		"relative url":      {envPrometheusURL: "prometheus", envPrometheusQuery: "up", envPrometheusThreshold: "< 1"},
+		"missing threshold": {envPrometheusURL: "http://prometheus", envPrometheusQuery: "up"},
+		"invalid threshold": {envPrometheusURL: "http://prometheus", envPrometheusQuery: "up", envPrometheusThreshold: "1"},
+		"invalid template":  {envPrometheusURL: "http://prometheus", envPrometheusQuery: "up{pod={{.Name}", envPrometheusThreshold: "< 1"},
+		"invalid duration":  {envPrometheusURL: "http://prometheus", envPrometheusQuery: "up", envPrometheusThreshold: "< 1", envPrometheusDuration: "soon"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			os.Clearenv()
+			for key, value := range env {
+				os.Setenv(key, value)
+			}
+			loaded, _, err := (&prometheusQuery{}).Load(os.LookupEnv)
+			assert.Error(t, err)
+			assert.False(t, loaded)
+		})
+	}
+	t.Run("no load", func(t *testing.T) {
+		os.Clearenv()
+		loaded, message, err := (&prometheusQuery{}).Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.Equal(t, "", message)
+		assert.False(t, loaded)
+	})
+}
+
+func TestParseThreshold(t *testing.T) {
+	for value, expected := range map[string]threshold{
+		"> 0.9":  {operator: ">", value: 0.9},
+		">=1":    {operator: ">=", value: 1},
+		" < -2 ": {operator: "<", value: -2},
+		"<=0":    {operator: "<=", value: 0},
+		"== 1":   {operator: "==", value: 1},
+		"!=0":    {operator: "!=", value: 0},
+	} {
+		parsed, err := parseThreshold(value)
+		assert.NoError(t, err, value)
+		assert.Equal(t, expected, parsed, value)
+	}
+	for _, value := range []string{"", "0.9", "> high", "=> 1"} {
+		_, err := parseThreshold(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestPrometheusQueryShouldReap(t *testing.T) {
+	query := template.Must(template.New("").Parse(`errors{namespace="{{.Namespace}}",pod="{{.Name}}",app="{{.Labels.app}}"}`))
+	client := testPrometheus(t, map[string]string{
+		`errors{namespace="default",pod="failing",app="web"}`: testVector("0.2", "0.75"),
+		`errors{namespace="default",pod="healthy",app="web"}`: testVector("0.01"),
+		`errors{namespace="default",pod="new",app="web"}`:     testVector(),
+		`scalar(vector(1))`: `{"status":"success","data":{"resultType":"scalar","result":[1700000000,"1"]}}`,
+		`errors`:            `{"status":"success","data":{"resultType":"matrix","result":[]}}`,
+	})
+	t.Run("crossed", func(t *testing.T) {
+		rule := prometheusQuery{query: query, threshold: threshold{operator: ">", value: 0.5}, client: client}
+		shouldReap, reason := rule.ShouldReap(testQueryPod("failing"))
+		assert.True(t, shouldReap)
+		assert.Equal(t, "has had prometheus query result 0.75 > 0.5 for 0s", reason)
+	})
+	t.Run("not crossed", func(t *testing.T) {
+		rule := prometheusQuery{query: query, threshold: threshold{operator: ">", value: 0.5}, client: client}
+		for _, pod := range []string{"healthy", "new"} {
+			shouldReap, _ := rule.ShouldReap(testQueryPod(pod))
+			assert.False(t, shouldReap, pod)
+		}
+	})
+	t.Run("sustained", func(t *testing.T) {
+		rule := prometheusQuery{query: query, threshold: threshold{operator: ">", value: 0.5}, duration: 5 * time.Minute, client: client}
+		shouldReap, _ := rule.ShouldReap(testQueryPod("failing"))
+		assert.False(t, shouldReap)
+		rule.observations["failing"] = observation{since: time.Now().Add(-6 * time.Minute), seen: time.Now()}
+		shouldReap, reason := rule.ShouldReap(testQueryPod("failing"))
+		assert.True(t, shouldReap)
+		assert.Contains(t, reason, "for 6m")
+	})
+	t.Run("scalar", func(t *testing.T) {
+		rule := prometheusQuery{query: template.Must(template.New("").Parse("scalar(vector(1))")), threshold: threshold{operator: "==", value: 1}, client: client}
+		shouldReap, _ := rule.ShouldReap(testQueryPod("any"))
+		assert.True(t, shouldReap)
+	})
+	t.Run("range query", func(t *testing.T) {
+		rule := prometheusQuery{query: template.Must(template.New("").Parse("errors")), threshold: threshold{operator: ">", value: 0}, client: client}
+		shouldReap, _ := rule.ShouldReap(testQueryPod("any"))
+		assert.False(t, shouldReap)
+	})
+	t.Run("query error", func(t *testing.T) {
+		rule := prometheusQuery{query: template.Must(template.New("").Parse("errors{")), threshold: threshold{operator: ">", value: 0}, client: client}
+		shouldReap, _ := rule.ShouldReap(testQueryPod("any"))
+		assert.False(t, shouldReap)
+	})
+	t.Run("prometheus unreachable", func(t *testing.T) {
+		rule := prometheusQuery{query: query, threshold: threshold{operator: ">", value: 0.5}, client: newPrometheusClient("http://127.0.0.1:1")}
+		shouldReap, _ := rule.ShouldReap(testQueryPod("failing"))
+		assert.False(t, shouldReap)
+	})
+}
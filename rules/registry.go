@@ -0,0 +1,224 @@
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultRegistry = "registry-1.docker.io"
+
+// manifest media types accepted from registries, image indexes are resolved to their linux/amd64 (or first) image
+var manifestMediaTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// imageReference is a parsed container image reference, where reference is either a tag or a digest.
+type imageReference struct {
+	registry   string
+	repository string
+	reference  string
+}
+
+// parseImageReference parses image references as they appear in pod specs and container statuses, including the
+// "docker-pullable://" prefix container runtimes add to image ids.
+func parseImageReference(image string) (imageReference, error) {
+	if i := strings.Index(image, "://"); i >= 0 {
+		image = image[i+3:]
+	}
+	if image == "" {
+		return imageReference{}, fmt.Errorf("empty image reference")
+	}
+	ref := imageReference{registry: defaultRegistry, reference: "latest"}
+	name := image
+	if i := strings.Index(name, "@"); i >= 0 {
+		name, ref.reference = name[:i], name[i+1:]
+	} else if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, ref.reference = name[:i], name[i+1:]
+	}
+	parts := strings.SplitN(name, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		ref.registry, name = parts[0], parts[1]
+	}
+	if ref.registry == "docker.io" || ref.registry == "index.docker.io" {
+		ref.registry = defaultRegistry
+	}
+	if ref.registry == defaultRegistry && !strings.Contains(name, "/") {
+		name = "library/" + name
+	}
+	ref.repository = name
+	return ref, nil
+}
+
+// registryClient looks up image creation timestamps from image configs using the OCI distribution API, caching the
+// results by image reference. Only anonymous pulls (including anonymous bearer tokens) are supported.
+type registryClient struct {
+	client *http.Client
+	scheme string
+	mutex  sync.Mutex
+	cache  map[string]time.Time
+}
+
+func newRegistryClient() *registryClient {
+	return &registryClient{
+		client: &http.Client{Timeout: 10 * time.Second},
+		scheme: "https",
+		cache:  map[string]time.Time{},
+	}
+}
+
+// imageCreated returns the creation timestamp recorded in the image's config.
+func (registry *registryClient) imageCreated(image string) (time.Time, error) {
+	registry.mutex.Lock()
+	created, cached := registry.cache[image]
+	registry.mutex.Unlock()
+	if cached {
+		return created, nil
+	}
+	ref, err := parseImageReference(image)
+	if err != nil {
+		return time.Time{}, err
+	}
+	created, err = registry.lookup(ref)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unable to look up image %s: %s", image, err)
+	}
+	registry.mutex.Lock()
+	registry.cache[image] = created
+	registry.mutex.Unlock()
+	return created, nil
+}
+
+func (registry *registryClient) lookup(ref imageReference) (time.Time, error) {
+	token := ""
+	var manifest struct {
+		MediaType string `json:"mediaType"`
+		Config    struct {
+			Digest string `json:"digest"`
+		} `json:"config"`
+		Manifests []struct {
+			Digest   string `json:"digest"`
+			Platform struct {
+				Architecture string `json:"architecture"`
+				OS           string `json:"os"`
+			} `json:"platform"`
+		} `json:"manifests"`
+	}
+	reference := ref.reference
+	for attempt := 0; attempt < 2; attempt++ {
+		manifest.Manifests = nil
+		if err := registry.get(ref, "manifests/"+reference, strings.Join(manifestMediaTypes, ", "), &token, &manifest); err != nil {
+			return time.Time{}, err
+		}
+		if len(manifest.Manifests) == 0 {
+			break
+		}
+		reference = manifest.Manifests[0].Digest
+		for _, m := range manifest.Manifests {
+			if m.Platform.OS == "linux" && m.Platform.Architecture == "amd64" {
+				reference = m.Digest
+				break
+			}
+		}
+	}
+	if manifest.Config.Digest == "" {
+		return time.Time{}, fmt.Errorf("manifest has no image config")
+	}
+	var config struct {
+		Created time.Time `json:"created"`
+	}
+	if err := registry.get(ref, "blobs/"+manifest.Config.Digest, "", &token, &config); err != nil {
+		return time.Time{}, err
+	}
+	if config.Created.IsZero() {
+		return time.Time{}, fmt.Errorf("image config has no creation timestamp")
+	}
+	return config.Created, nil
+}
+
+// get decodes a JSON response from the registry, fetching an anonymous bearer token when the registry asks for one.
+func (registry *registryClient) get(ref imageReference, path string, accept string, token *string, v interface{}) error {
+	endpoint := fmt.Sprintf("%s://%s/v2/%s/%s", registry.scheme, ref.registry, ref.repository, path)
+	for {
+		request, err := http.NewRequest(http.MethodGet, endpoint, nil)
+		if err != nil {
+			return err
+		}
+		if accept != "" {
+			request.Header.Set("Accept", accept)
+		}
+		if *token != "" {
+			request.Header.Set("Authorization", "Bearer "+*token)
+		}
+		response, err := registry.client.Do(request)
+		if err != nil {
+			return err
+		}
+		if response.StatusCode == http.StatusUnauthorized && *token == "" {
+			challenge := response.Header.Get("WWW-Authenticate")
+			response.Body.Close()
+			if *token, err = registry.token(challenge, ref); err != nil {
+				return err
+			}
+			continue
+		}
+		defer response.Body.Close()
+		if response.StatusCode != http.StatusOK {
+			return fmt.Errorf("unexpected status %s from %s", response.Status, endpoint)
+		}
+		return json.NewDecoder(io.LimitReader(response.Body, 1<<20)).Decode(v)
+	}
+}
+
+// token requests an anonymous pull token from the realm in a bearer WWW-Authenticate challenge.
+func (registry *registryClient) token(challenge string, ref imageReference) (string, error) {
+	if !strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+		return "", fmt.Errorf("registry requires unsupported authentication %q", challenge)
+	}
+	params := map[string]string{}
+	for _, param := range strings.Split(challenge[len("bearer "):], ",") {
+		if kv := strings.SplitN(strings.TrimSpace(param), "=", 2); len(kv) == 2 {
+			params[kv[0]] = strings.Trim(kv[1], `"`)
+		}
+	}
+	realm, err := url.Parse(params["realm"])
+	if err != nil || params["realm"] == "" {
+		return "", fmt.Errorf("invalid registry authentication realm %q", params["realm"])
+	}
+	query := realm.Query()
+	if service, ok := params["service"]; ok {
+		query.Set("service", service)
+	}
+	query.Set("scope", fmt.Sprintf("repository:%s:pull", ref.repository))
+	realm.RawQuery = query.Encode()
+	response, err := registry.client.Get(realm.String())
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %s from %s", response.Status, realm.Host)
+	}
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&body); err != nil {
+		return "", err
+	}
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	if body.AccessToken != "" {
+		return body.AccessToken, nil
+	}
+	return "", fmt.Errorf("registry returned an empty token")
+}
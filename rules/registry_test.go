@@ -0,0 +1,100 @@
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseImageReference(t *testing.T) {
+	tests := []struct {
+		image    string
+		expected imageReference
+	}{
+		{"nginx", imageReference{defaultRegistry, "library/nginx", "latest"}},
+		{"nginx:1.25", imageReference{defaultRegistry, "library/nginx", "1.25"}},
+		{"docker.io/bitnami/redis:7", imageReference{defaultRegistry, "bitnami/redis", "7"}},
+		{"quay.io/org/app@sha256:abc", imageReference{"quay.io", "org/app", "sha256:abc"}},
+		{"localhost:5000/app", imageReference{"localhost:5000", "app", "latest"}},
+		{"docker-pullable://ghcr.io/org/app@sha256:abc", imageReference{"ghcr.io", "org/app", "sha256:abc"}},
+	}
+	for _, test := range tests {
+		t.Run(test.image, func(t *testing.T) {
+			ref, err := parseImageReference(test.image)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, ref)
+		})
+	}
+	t.Run("empty", func(t *testing.T) {
+		_, err := parseImageReference("")
+		assert.Error(t, err)
+	})
+}
+
+func testRegistryServer(t *testing.T, created time.Time) (*httptest.Server, *int) {
+	requests := 0
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path == "/token" {
+			assert.Equal(t, "repository:org/app:pull", r.URL.Query().Get("scope"))
+			json.NewEncoder(w).Encode(map[string]string{"token": "anonymous"})
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer anonymous" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="test"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v2/org/app/manifests/1.0":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"manifests": []map[string]interface{}{
+					{"digest": "sha256:arm", "platform": map[string]string{"os": "linux", "architecture": "arm64"}},
+					{"digest": "sha256:amd", "platform": map[string]string{"os": "linux", "architecture": "amd64"}},
+				},
+			})
+		case "/v2/org/app/manifests/sha256:amd":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"config": map[string]string{"digest": "sha256:config"},
+			})
+		case "/v2/org/app/blobs/sha256:config":
+			json.NewEncoder(w).Encode(map[string]interface{}{"created": created})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return server, &requests
+}
+
+func TestRegistryImageCreated(t *testing.T) {
+	created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	server, requests := testRegistryServer(t, created)
+	defer server.Close()
+	registry := newRegistryClient()
+	registry.scheme = "http"
+	image := strings.TrimPrefix(server.URL, "http://") + "/org/app:1.0"
+
+	t.Run("lookup", func(t *testing.T) {
+		actual, err := registry.imageCreated(image)
+		assert.NoError(t, err)
+		assert.True(t, created.Equal(actual))
+	})
+	t.Run("cached", func(t *testing.T) {
+		before := *requests
+		actual, err := registry.imageCreated(image)
+		assert.NoError(t, err)
+		assert.True(t, created.Equal(actual))
+		assert.Equal(t, before, *requests)
+	})
+	t.Run("not found", func(t *testing.T) {
+		_, err := registry.imageCreated(strings.TrimPrefix(server.URL, "http://") + "/org/app:2.0")
+		assert.Error(t, err)
+	})
+}
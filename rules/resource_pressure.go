@@ -0,0 +1,89 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/api/core/v1"
+)
+
+const envResourcePressureWindow = "RESOURCE_PRESSURE_WINDOW"
+
+// the pod and container reasons set by the kubelet when it kills containers or evicts pods under resource pressure
+const reasonEvicted = "Evicted"
+const reasonOOMKilled = "OOMKilled"
+
+var _ Rule = (*resourcePressure)(nil)
+
+// resourcePressure flags pods that were evicted for their ephemeral storage usage, or that have a container that was
+// evicted or killed for running out of memory, within a lookback window.
+type resourcePressure struct {
+	window time.Duration
+}
+
+func (rule *resourcePressure) Load(lookup LookupFunc) (bool, string, error) {
+	value, active := lookup(envResourcePressureWindow)
+	if !active {
+		return false, "", nil
+	}
+	window, err := parseDuration(value)
+	if err != nil {
+		return false, "", fmt.Errorf("invalid %s: %s", envResourcePressureWindow, err)
+	}
+	rule.window = window
+	return true, fmt.Sprintf("resource pressure within %s", value), nil
+}
+
+func (rule *resourcePressure) ShouldReap(pod v1.Pod) (bool, string) {
+	now := time.Now()
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if terminated := rule.pressureTermination(containerStatus, now); terminated != nil {
+			return true, fmt.Sprintf("has container %s terminated with reason %s %s ago", containerStatus.Name, terminated.Reason, now.Sub(terminated.FinishedAt.Time).Truncate(time.Second))
+		}
+	}
+	for _, initContainerStatus := range pod.Status.InitContainerStatuses {
+		if terminated := rule.pressureTermination(initContainerStatus, now); terminated != nil {
+			return true, fmt.Sprintf("has init container %s terminated with reason %s %s ago", initContainerStatus.Name, terminated.Reason, now.Sub(terminated.FinishedAt.Time).Truncate(time.Second))
+		}
+	}
+	if pod.Status.Reason == reasonEvicted && strings.Contains(pod.Status.Message, "ephemeral") {
+		evicted, known := evictionTime(pod)
+		if !known || now.Sub(evicted) <= rule.window {
+			return true, fmt.Sprintf("was evicted for ephemeral storage usage: %s", pod.Status.Message)
+		}
+	}
+	return false, ""
+}
+
+// pressureTermination returns the current termination of the container, or its last termination when the container
+// has since been restarted, if the container was evicted or killed for running out of memory within the window.
+func (rule *resourcePressure) pressureTermination(containerStatus v1.ContainerStatus, now time.Time) *v1.ContainerStateTerminated {
+	for _, terminated := range []*v1.ContainerStateTerminated{
+		containerStatus.State.Terminated,
+		containerStatus.LastTerminationState.Terminated,
+	} {
+		if terminated == nil || (terminated.Reason != reasonEvicted && terminated.Reason != reasonOOMKilled) {
+			continue
+		}
+		if !terminated.FinishedAt.IsZero() && now.Sub(terminated.FinishedAt.Time) <= rule.window {
+			return terminated
+		}
+	}
+	return nil
+}
+
+// evictionTime returns when the pod was evicted: the transition of its DisruptionTarget condition when the kubelet
+// set one, otherwise the latest time one of its containers finished.
+func evictionTime(pod v1.Pod) (time.Time, bool) {
+	if condition := getCondition(pod, v1.DisruptionTarget); condition != nil && !condition.LastTransitionTime.IsZero() {
+		return condition.LastTransitionTime.Time, true
+	}
+	var latest time.Time
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if terminated := containerStatus.State.Terminated; terminated != nil && terminated.FinishedAt.After(latest) {
+			latest = terminated.FinishedAt.Time
+		}
+	}
+	return latest, !latest.IsZero()
+}
@@ -0,0 +1,108 @@
+package rules
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testTerminated(reason string, ago time.Duration) *v1.ContainerStateTerminated {
+	return &v1.ContainerStateTerminated{Reason: reason, FinishedAt: metav1.NewTime(time.Now().Add(-ago))}
+}
+
+func testEvictedPod(message string) v1.Pod {
+	return v1.Pod{Status: v1.PodStatus{Phase: v1.PodFailed, Reason: reasonEvicted, Message: message}}
+}
+
+func TestResourcePressureLoad(t *testing.T) {
+	t.Run("load", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envResourcePressureWindow, "1h")
+		rule := resourcePressure{}
+		loaded, message, err := rule.Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.True(t, loaded)
+		assert.Equal(t, "resource pressure within 1h", message)
+		assert.Equal(t, time.Hour, rule.window)
+	})
+	t.Run("invalid window", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envResourcePressureWindow, "recently")
+		loaded, _, err := (&resourcePressure{}).Load(os.LookupEnv)
+		assert.Error(t, err)
+		assert.False(t, loaded)
+	})
+	t.Run("no load", func(t *testing.T) {
+		os.Clearenv()
+		loaded, message, err := (&resourcePressure{}).Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.Equal(t, "", message)
+		assert.False(t, loaded)
+	})
+}
+
+func TestResourcePressureShouldReap(t *testing.T) {
+	rule := resourcePressure{window: time.Hour}
+	t.Run("oom killed before restart", func(t *testing.T) {
+		pod := v1.Pod{Status: v1.PodStatus{ContainerStatuses: []v1.ContainerStatus{
+			{Name: "app", State: v1.ContainerState{Running: &v1.ContainerStateRunning{}}},
+			{Name: "sidecar", LastTerminationState: v1.ContainerState{Terminated: testTerminated(reasonOOMKilled, 10*time.Minute)}},
+		}}}
+		shouldReap, reason := rule.ShouldReap(pod)
+		assert.True(t, shouldReap)
+		assert.Equal(t, "has container sidecar terminated with reason OOMKilled 10m0s ago", reason)
+	})
+	t.Run("evicted init container", func(t *testing.T) {
+		pod := v1.Pod{Status: v1.PodStatus{InitContainerStatuses: []v1.ContainerStatus{
+			{Name: "setup", State: v1.ContainerState{Terminated: testTerminated(reasonEvicted, time.Minute)}},
+		}}}
+		shouldReap, reason := rule.ShouldReap(pod)
+		assert.True(t, shouldReap)
+		assert.Equal(t, "has init container setup terminated with reason Evicted 1m0s ago", reason)
+	})
+	t.Run("outside the window", func(t *testing.T) {
+		pod := v1.Pod{Status: v1.PodStatus{ContainerStatuses: []v1.ContainerStatus{
+			{Name: "app", LastTerminationState: v1.ContainerState{Terminated: testTerminated(reasonOOMKilled, 2*time.Hour)}},
+		}}}
+		shouldReap, _ := rule.ShouldReap(pod)
+		assert.False(t, shouldReap)
+	})
+	t.Run("other termination reason", func(t *testing.T) {
+		pod := v1.Pod{Status: v1.PodStatus{ContainerStatuses: []v1.ContainerStatus{
+			{Name: "app", State: v1.ContainerState{Terminated: testTerminated("Error", time.Minute)}},
+		}}}
+		shouldReap, _ := rule.ShouldReap(pod)
+		assert.False(t, shouldReap)
+	})
+	t.Run("evicted for ephemeral storage", func(t *testing.T) {
+		pod := testEvictedPod("Pod ephemeral local storage usage exceeds the total limit of containers 1Gi.")
+		pod.Status.Conditions = []v1.PodCondition{{
+			Type:               v1.DisruptionTarget,
+			Status:             v1.ConditionTrue,
+			LastTransitionTime: metav1.NewTime(time.Now().Add(-time.Minute)),
+		}}
+		shouldReap, reason := rule.ShouldReap(pod)
+		assert.True(t, shouldReap)
+		assert.Equal(t, "was evicted for ephemeral storage usage: Pod ephemeral local storage usage exceeds the total limit of containers 1Gi.", reason)
+	})
+	t.Run("evicted for ephemeral storage outside the window", func(t *testing.T) {
+		pod := testEvictedPod("Container app exceeded its local ephemeral storage limit \"1Gi\".")
+		pod.Status.ContainerStatuses = []v1.ContainerStatus{
+			{Name: "app", State: v1.ContainerState{Terminated: testTerminated("Error", 3*time.Hour)}},
+		}
+		shouldReap, _ := rule.ShouldReap(pod)
+		assert.False(t, shouldReap)
+	})
+	t.Run("evicted at an unknown time", func(t *testing.T) {
+		shouldReap, _ := rule.ShouldReap(testEvictedPod("Container app exceeded its local ephemeral storage limit \"1Gi\"."))
+		assert.True(t, shouldReap)
+	})
+	t.Run("evicted for memory", func(t *testing.T) {
+		shouldReap, _ := rule.ShouldReap(testEvictedPod("The node was low on resource: memory."))
+		assert.False(t, shouldReap)
+	})
+}
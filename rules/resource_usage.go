@@ -0,0 +1,204 @@
+package rules
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+const envMaxMemoryUsage = "MAX_MEMORY_USAGE"
+const envMaxCPUUsage = "MAX_CPU_USAGE"
+const envResourceUsageDuration = "RESOURCE_USAGE_DURATION"
+
+// pod metrics are cached per namespace; metrics-server itself only scrapes the kubelets every 15 seconds by default
+const podMetricsTTL = 30 * time.Second
+
+var _ Rule = (*resourceUsage)(nil)
+var _ contextRule = (*resourceUsage)(nil)
+
+// resourceUsage flags running pods whose memory or cpu usage, as reported by metrics-server, has been above a
+// threshold at every evaluation for a duration. Pods are never flagged while metrics-server is unavailable.
+type resourceUsage struct {
+	maxMemory *resource.Quantity
+	maxCPU    *resource.Quantity
+	duration  time.Duration
+	metrics   *podMetrics
+	// sustained tracks the pods whose usage is above the thresholds
+	sustained
+}
+
+func (rule *resourceUsage) Load(lookup LookupFunc) (bool, string, error) {
+	var limits []string
+	for _, threshold := range []struct {
+		key      string
+		name     string
+		quantity **resource.Quantity
+	}{
+		{envMaxMemoryUsage, "memory", &rule.maxMemory},
+		{envMaxCPUUsage, "cpu", &rule.maxCPU},
+	} {
+		value, exists := lookup(threshold.key)
+		if !exists {
+			continue
+		}
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return false, "", fmt.Errorf("invalid %s: %s", threshold.key, err)
+		}
+		*threshold.quantity = &quantity
+		limits = append(limits, fmt.Sprintf("%s usage %s", threshold.name, quantity.String()))
+	}
+	if len(limits) == 0 {
+		return false, "", nil
+	}
+	durationValue, exists := lookup(envResourceUsageDuration)
+	if !exists {
+		durationValue = "5m"
+	}
+	duration, err := parseDuration(durationValue)
+	if err != nil {
+		return false, "", fmt.Errorf("invalid %s: %s", envResourceUsageDuration, err)
+	}
+	rule.duration = duration
+	return true, fmt.Sprintf("maximum %s for %s", strings.Join(limits, " or "), durationValue), nil
+}
+
+func (rule *resourceUsage) ShouldReap(pod v1.Pod) (bool, string) {
+	return rule.shouldReapContext(context.Background(), pod)
+}
+
+func (rule *resourceUsage) shouldReapContext(ctx context.Context, pod v1.Pod) (bool, string) {
+	if pod.Status.Phase != v1.PodRunning {
+		return false, ""
+	}
+	usage, err := podUsage(ctx, rule.metrics, pod.Namespace, pod.Name)
+	if err != nil {
+		logrus.WithField("namespace", pod.Namespace).WithError(err).Warn("unable to get pod metrics")
+		return false, ""
+	}
+	exceeded := rule.exceeded(usage)
+	if lasted, met := rule.observe(pod.UID, exceeded != "", time.Now()); met && lasted >= rule.duration {
+		return true, fmt.Sprintf("has had %s for %s", exceeded, lasted.Truncate(time.Second))
+	}
+	return false, ""
+}
+
+// exceeded describes the usage above the thresholds, or returns the empty string if the usage is within them or
+// unknown.
+func (rule *resourceUsage) exceeded(usage v1.ResourceList) string {
+	var exceeded []string
+	if memory, exists := usage[v1.ResourceMemory]; exists && rule.maxMemory != nil && memory.Cmp(*rule.maxMemory) > 0 {
+		exceeded = append(exceeded, fmt.Sprintf("memory usage %s above %s", memory.String(), rule.maxMemory.String()))
+	}
+	if cpu, exists := usage[v1.ResourceCPU]; exists && rule.maxCPU != nil && cpu.Cmp(*rule.maxCPU) > 0 {
+		exceeded = append(exceeded, fmt.Sprintf("cpu usage %s above %s", cpu.String(), rule.maxCPU.String()))
+	}
+	return strings.Join(exceeded, " and ")
+}
+
+// podUsage returns the total usage of the pod's containers from metrics, or from the pod metrics of the cluster carried
+// by ctx when metrics is nil.
+func podUsage(ctx context.Context, metrics *podMetrics, namespace string, name string) (v1.ResourceList, error) {
+	if metrics == nil {
+		cluster, err := clusterFromContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		metrics = cluster.metrics
+	}
+	return metrics.usage(ctx, namespace, name)
+}
+
+// podMetricsList is the subset of the metrics.k8s.io/v1beta1 PodMetricsList used by pod-reaper.
+type podMetricsList struct {
+	Items []struct {
+		metav1.ObjectMeta `json:"metadata"`
+		Containers        []struct {
+			Usage v1.ResourceList `json:"usage"`
+		} `json:"containers"`
+	} `json:"items"`
+}
+
+// podMetrics lists pod usage from the metrics.k8s.io api served by metrics-server, caching it per namespace.
+type podMetrics struct {
+	client rest.Interface
+	mutex  sync.Mutex
+	cache  map[string]cachedPodMetrics
+	// unavailable is set while metrics-server is not installed or not serving, so the warning is only logged once
+	unavailable bool
+}
+
+type cachedPodMetrics struct {
+	listed time.Time
+	// usage is the total usage of each pod's containers by pod name
+	usage map[string]v1.ResourceList
+}
+
+func newPodMetrics(client rest.Interface) *podMetrics {
+	return &podMetrics{client: client, cache: map[string]cachedPodMetrics{}}
+}
+
+// usage returns the total usage of the pod's containers, or nil if metrics-server has no metrics for the pod or is
+// unavailable.
+func (metrics *podMetrics) usage(ctx context.Context, namespace string, name string) (v1.ResourceList, error) {
+	metrics.mutex.Lock()
+	defer metrics.mutex.Unlock()
+	cached, exists := metrics.cache[namespace]
+	if !exists || time.Since(cached.listed) >= podMetricsTTL {
+		usage, err := metrics.list(ctx, namespace)
+		if err != nil {
+			return nil, err
+		}
+		cached = cachedPodMetrics{listed: time.Now(), usage: usage}
+		metrics.cache[namespace] = cached
+	}
+	return cached.usage[name], nil
+}
+
+func (metrics *podMetrics) list(ctx context.Context, namespace string) (map[string]v1.ResourceList, error) {
+	ctx, cancel := apiContext(ctx)
+	defer cancel()
+	body, err := metrics.client.Get().
+		AbsPath("/apis/metrics.k8s.io/v1beta1/namespaces", namespace, "pods").
+		DoRaw(ctx)
+	if apierrors.IsNotFound(err) || apierrors.IsServiceUnavailable(err) {
+		if !metrics.unavailable {
+			logrus.WithError(err).Warn("metrics-server is unavailable, no pods are reaped for their resource usage")
+		}
+		metrics.unavailable = true
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if metrics.unavailable {
+		logrus.Info("metrics-server is available")
+	}
+	metrics.unavailable = false
+	var list podMetricsList
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, fmt.Errorf("invalid pod metrics: %s", err)
+	}
+	usage := map[string]v1.ResourceList{}
+	for _, item := range list.Items {
+		total := v1.ResourceList{}
+		for _, container := range item.Containers {
+			for name, quantity := range container.Usage {
+				sum := total[name]
+				sum.Add(quantity)
+				total[name] = sum
+			}
+		}
+		usage[item.Name] = total
+	}
+	return usage, nil
+}
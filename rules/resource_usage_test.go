@@ -0,0 +1,162 @@
+package rules
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+const testPodMetrics = `{"kind":"PodMetricsList","apiVersion":"metrics.k8s.io/v1beta1","items":[
+	{"metadata":{"name":"hungry","namespace":"default"},"containers":[
+		{"name":"app","usage":{"cpu":"250m","memory":"900Mi"}},
+		{"name":"sidecar","usage":{"cpu":"10m","memory":"200Mi"}}]},
+	{"metadata":{"name":"busy","namespace":"default"},"containers":[{"name":"app","usage":{"cpu":"2","memory":"64Mi"}}]},
+	{"metadata":{"name":"idle","namespace":"default"},"containers":[{"name":"app","usage":{"cpu":"1m","memory":"16Mi"}}]}]}`
+
+func testMetricsServer(t *testing.T, status int, body string) (*podMetrics, *int) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "/apis/metrics.k8s.io/v1beta1/namespaces/default/pods", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	client, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+	assert.NoError(t, err)
+	return newPodMetrics(client.Discovery().RESTClient()), &requests
+}
+
+func testUsagePod(name string) v1.Pod {
+	return v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID(name)},
+		Status:     v1.PodStatus{Phase: v1.PodRunning},
+	}
+}
+
+func TestResourceUsageLoad(t *testing.T) {
+	t.Run("load", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envMaxMemoryUsage, "1Gi")
+		os.Setenv(envMaxCPUUsage, "500m")
+		rule := resourceUsage{}
+		loaded, message, err := rule.Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.True(t, loaded)
+		assert.Equal(t, "maximum memory usage 1Gi or cpu usage 500m for 5m", message)
+		assert.Equal(t, resource.MustParse("1Gi"), *rule.maxMemory)
+		assert.Equal(t, 5*time.Minute, rule.duration)
+		// the usage is looked up in the pod metrics of the cluster the rules are evaluated against
+		assert.Nil(t, rule.metrics)
+	})
+	t.Run("cpu only", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envMaxCPUUsage, "1")
+		os.Setenv(envResourceUsageDuration, "10m")
+		rule := resourceUsage{}
+		loaded, message, err := rule.Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.True(t, loaded)
+		assert.Equal(t, "maximum cpu usage 1 for 10m", message)
+		assert.Nil(t, rule.maxMemory)
+	})
+	t.Run("invalid quantity", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envMaxMemoryUsage, "lots")
+		_, _, err := (&resourceUsage{}).Load(os.LookupEnv)
+		assert.Error(t, err)
+	})
+	t.Run("invalid duration", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envMaxMemoryUsage, "1Gi")
+		os.Setenv(envResourceUsageDuration, "soon")
+		_, _, err := (&resourceUsage{}).Load(os.LookupEnv)
+		assert.Error(t, err)
+	})
+	t.Run("no load", func(t *testing.T) {
+		os.Clearenv()
+		loaded, message, err := (&resourceUsage{}).Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.Equal(t, "", message)
+		assert.False(t, loaded)
+	})
+}
+
+func TestResourceUsageShouldReap(t *testing.T) {
+	maxMemory := resource.MustParse("1Gi")
+	maxCPU := resource.MustParse("1")
+	t.Run("thresholds", func(t *testing.T) {
+		metrics, _ := testMetricsServer(t, http.StatusOK, testPodMetrics)
+		rule := resourceUsage{maxMemory: &maxMemory, maxCPU: &maxCPU, metrics: metrics}
+		for pod, expected := range map[string]string{
+			"hungry":  "has had memory usage 1100Mi above 1Gi for 0s",
+			"busy":    "has had cpu usage 2 above 1 for 0s",
+			"idle":    "",
+			"missing": "",
+		} {
+			shouldReap, reason := rule.ShouldReap(testUsagePod(pod))
+			assert.Equal(t, expected != "", shouldReap, pod)
+			assert.Equal(t, expected, reason, pod)
+		}
+	})
+	t.Run("sustained", func(t *testing.T) {
+		metrics, requests := testMetricsServer(t, http.StatusOK, testPodMetrics)
+		rule := resourceUsage{maxMemory: &maxMemory, duration: 5 * time.Minute, metrics: metrics}
+		shouldReap, _ := rule.ShouldReap(testUsagePod("hungry"))
+		assert.False(t, shouldReap)
+		assert.Contains(t, rule.observations, types.UID("hungry"))
+
+		rule.observations["hungry"] = observation{since: time.Now().Add(-6 * time.Minute), seen: time.Now()}
+		shouldReap, reason := rule.ShouldReap(testUsagePod("hungry"))
+		assert.True(t, shouldReap)
+		assert.Contains(t, reason, "for 6m")
+		assert.Equal(t, 1, *requests)
+	})
+	t.Run("usage drops", func(t *testing.T) {
+		metrics, _ := testMetricsServer(t, http.StatusOK, testPodMetrics)
+		rule := resourceUsage{maxCPU: &maxCPU, metrics: metrics, sustained: sustained{observations: map[types.UID]observation{
+			"idle": {since: time.Now().Add(-time.Hour), seen: time.Now()},
+			"gone": {since: time.Now().Add(-3 * time.Hour), seen: time.Now().Add(-2 * time.Hour)},
+		}}}
+		shouldReap, _ := rule.ShouldReap(testUsagePod("idle"))
+		assert.False(t, shouldReap)
+		assert.Empty(t, rule.observations)
+	})
+	t.Run("not running", func(t *testing.T) {
+		metrics, requests := testMetricsServer(t, http.StatusOK, testPodMetrics)
+		rule := resourceUsage{maxCPU: &maxCPU, metrics: metrics}
+		pod := testUsagePod("busy")
+		pod.Status.Phase = v1.PodPending
+		shouldReap, _ := rule.ShouldReap(pod)
+		assert.False(t, shouldReap)
+		assert.Equal(t, 0, *requests)
+	})
+	t.Run("metrics-server absent", func(t *testing.T) {
+		metrics, requests := testMetricsServer(t, http.StatusNotFound, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404}`)
+		rule := resourceUsage{maxCPU: &maxCPU, metrics: metrics}
+		shouldReap, _ := rule.ShouldReap(testUsagePod("busy"))
+		assert.False(t, shouldReap)
+		assert.True(t, metrics.unavailable)
+		shouldReap, _ = rule.ShouldReap(testUsagePod("busy"))
+		assert.False(t, shouldReap)
+		assert.Equal(t, 1, *requests)
+	})
+	t.Run("error", func(t *testing.T) {
+		metrics, _ := testMetricsServer(t, http.StatusInternalServerError, `{}`)
+		rule := resourceUsage{maxCPU: &maxCPU, metrics: metrics}
+		shouldReap, _ := rule.ShouldReap(testUsagePod("busy"))
+		assert.False(t, shouldReap)
+		assert.False(t, metrics.unavailable)
+	})
+}
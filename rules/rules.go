@@ -1,7 +1,12 @@
 package rules
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sync"
 
 	"github.com/sirupsen/logrus"
 	"k8s.io/api/core/v1"
@@ -10,39 +15,145 @@ import (
 // Rule is an interface defining the two functions needed for pod reaper to use the rule.
 type Rule interface {
 
-	// load attempts to load the load and returns whether the rule was loaded, a message that will be logged
-	// when the rule is loaded, and any error that may have occurred during the load.
-	load() (bool, string, error)
+	// Load attempts to load the rule from the configuration values returned by lookup and returns whether the rule
+	// was loaded, a message that will be logged when the rule is loaded, and any error that may have occurred during
+	// the load.
+	Load(lookup LookupFunc) (bool, string, error)
 
 	// ShouldReap takes a pod and returns whether the pod should be reaped based on this rule and a message that
 	// will be logged when the pod is selected for reaping.
 	ShouldReap(pod v1.Pod) (bool, string)
 }
 
+// sweepingRule is implemented by rules that can flag nearly every pod on their own, such as a chaos chance of 1.
+type sweepingRule interface {
+	// sweeping describes why the rule flags nearly every pod, or returns the empty string if it does not.
+	sweeping() string
+}
+
+// LookupFunc resolves a configuration value by key, with the same semantics as os.LookupEnv.
+type LookupFunc func(key string) (string, bool)
+
+var registryMutex sync.Mutex
+
+// registry holds a constructor for every rule that LoadRules attempts to load, in the order they are evaluated.
+var registry = []func() Rule{
+	func() Rule { return &chaos{} },
+	func() Rule { return &containerStatus{} },
+	func() Rule { return &containerExitCode{} },
+	func() Rule { return &resourcePressure{} },
+	func() Rule { return &duration{} },
+	func() Rule { return &softTTL{} },
+	func() Rule { return &unready{} },
+	func() Rule { return &podConditions{} },
+	func() Rule { return &podStatus{} },
+	func() Rule { return &podStatusPhase{} },
+	func() Rule { return &imageAge{} },
+	func() Rule { return &completedAge{} },
+	func() Rule { return &certExpiry{} },
+	func() Rule { return &serviceAccountToken{} },
+	func() Rule { return &staleEndpoint{} },
+	func() Rule { return &nodeAffinity{} },
+	func() Rule { return &nodeConditions{} },
+	func() Rule { return &resourceUsage{} },
+	func() Rule { return &prometheusQuery{} },
+	func() Rule { return &expectedRuntime{} },
+	func() Rule { return &terminating{} },
+	// rules that fetch vulnerability reports and pod logs are evaluated after the cheaper rules, and the external rule
+	// last, so that only the pods every other rule flags are looked up or posted
+	func() Rule { return &vulnerability{} },
+	func() Rule { return &logPattern{} },
+	func() Rule { return &external{} },
+}
+
+// Register adds a rule to the rules that LoadRules attempts to load, after the built in rules. newRule must return a
+// new, unloaded instance of the rule each time it is called, since rules may be loaded more than once with different
+// configurations (for example per namespace). Register is typically called from an init function.
+func Register(newRule func() Rule) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	registry = append(registry, newRule)
+}
+
+func registeredRules() []Rule {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	rules := make([]Rule, len(registry))
+	for i, newRule := range registry {
+		rules[i] = newRule()
+	}
+	return rules
+}
+
 // Rules is a collection of loaded pod reaper rules.
 type Rules struct {
 	LoadedRules []Rule
+	// scopes restricts rules to some pods by rule name, rules without a scope apply to every pod
+	scopes map[string]ruleScope
+	// priorities orders the rules by rule name, rules without a priority have priority 0
+	priorities map[string]int
+	// ctx is the context of the kubernetes API requests made by the rules, set by WithContext
+	ctx context.Context
+}
+
+// WithContext returns a copy of the rules that make their kubernetes API requests with ctx, so that the requests are
+// abandoned when ctx is cancelled. A nil ctx is never cancelled.
+func (rules Rules) WithContext(ctx context.Context) Rules {
+	rules.ctx = ctx
+	return rules
+}
+
+// evaluate returns whether the rule flags the pod, and why.
+func (rules Rules) evaluate(rule Rule, pod v1.Pod) (bool, string) {
+	contextual, ok := rule.(contextRule)
+	if !ok {
+		return rule.ShouldReap(pod)
+	}
+	ctx := rules.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return contextual.shouldReapContext(ctx, pod)
 }
 
 // LoadRules load all the rules based on their own implementations
 func LoadRules() (Rules, error) {
-	// load all possible rules
-	rules := []Rule{
-		&chaos{},
-		&containerStatus{},
-		&duration{},
-		&unready{},
-		&podStatus{},
-		&podStatusPhase{},
+	return loadRules(os.LookupEnv, logrus.Info)
+}
+
+// LoadRulesWithOverrides loads all the rules from the environment, except that any key present in overrides takes
+// precedence over the environment variable of the same name.
+func LoadRulesWithOverrides(overrides map[string]string) (Rules, error) {
+	lookup := func(key string) (string, bool) {
+		if value, exists := overrides[key]; exists {
+			return value, true
+		}
+		return os.LookupEnv(key)
 	}
+	return loadRules(lookup, logrus.Debug)
+}
+
+// LoadRulesFromMap loads the rules only from config, ignoring the environment, for rule configurations that are
+// complete on their own such as reaper policies.
+func LoadRulesFromMap(config map[string]string) (Rules, error) {
+	lookup := func(key string) (string, bool) {
+		value, exists := config[key]
+		return value, exists
+	}
+	return loadRules(lookup, logrus.Debug)
+}
+
+func loadRules(lookup LookupFunc, logLoaded func(args ...interface{})) (Rules, error) {
+	// load all possible rules
+	rules := registeredRules()
 	// return only the active rules
 	loadedRules := []Rule{}
 	for _, rule := range rules {
-		load, message, err := rule.load()
+		load, message, err := rule.Load(lookup)
 		if err != nil {
 			return Rules{LoadedRules: loadedRules}, err
 		} else if load {
-			logrus.Info("loaded rule: " + message)
+			logLoaded("loaded rule: " + message)
 			loadedRules = append(loadedRules, rule)
 		}
 	}
@@ -50,19 +161,109 @@ func LoadRules() (Rules, error) {
 	if len(loadedRules) == 0 {
 		return Rules{LoadedRules: loadedRules}, errors.New("no rules were loaded")
 	}
-	return Rules{LoadedRules: loadedRules}, nil
+	scopes, err := loadScopes(lookup, loadedRules)
+	if err != nil {
+		return Rules{LoadedRules: loadedRules}, err
+	}
+	priorities, err := loadPriorities(lookup, loadedRules)
+	if err != nil {
+		return Rules{LoadedRules: loadedRules}, err
+	}
+	sortByPriority(loadedRules, priorities)
+	for _, rule := range loadedRules {
+		if scope, scoped := scopes[ruleName(rule)]; scoped {
+			logLoaded("scoped rule " + ruleName(rule) + " to " + scope.String())
+		}
+		if priority, prioritized := priorities[ruleName(rule)]; prioritized {
+			logLoaded(fmt.Sprintf("prioritized rule %s with priority %d", ruleName(rule), priority))
+		}
+	}
+	return Rules{LoadedRules: loadedRules, scopes: scopes, priorities: priorities}, nil
+}
+
+// inScope returns whether the rule applies to the pod.
+func (rules Rules) inScope(rule Rule, pod v1.Pod) bool {
+	scope, scoped := rules.scopes[ruleName(rule)]
+	return !scoped || scope.matches(pod)
 }
 
 // ShouldReap takes a pod and return whether the pod should be reaped based on this rule.
-// Also includes a message describing why the pod was flagged for reaping.
+// Also includes a message describing why the pod was flagged for reaping, with the reasons of higher priority rules
+// first. Rules whose scope does not include the pod are ignored, and a pod outside the scope of every rule is never
+// reaped.
 func (rules Rules) ShouldReap(pod v1.Pod) (bool, []string) {
 	var reasons []string
 	for _, rule := range rules.LoadedRules {
-		reap, reason := rule.ShouldReap(pod)
+		if !rules.inScope(rule, pod) {
+			continue
+		}
+		reap, reason := rules.evaluate(rule, pod)
 		if !reap {
 			return false, []string{}
 		}
 		reasons = append(reasons, reason)
 	}
+	if reasons == nil {
+		return false, []string{}
+	}
 	return true, reasons
 }
+
+// Names returns the names of the loaded rules, in the order they are evaluated.
+func (rules Rules) Names() []string {
+	names := []string{}
+	for _, rule := range rules.LoadedRules {
+		names = append(names, ruleName(rule))
+	}
+	return names
+}
+
+// Sweeping describes the loaded rules when, together, they flag nearly every pod they see: every loaded rule is
+// sweeping on its own and none is scoped. It returns nil when at least one rule restricts which pods are flagged.
+func (rules Rules) Sweeping() []string {
+	var descriptions []string
+	for _, rule := range rules.LoadedRules {
+		sweeping, ok := rule.(sweepingRule)
+		if !ok || sweeping.sweeping() == "" {
+			return nil
+		}
+		if _, scoped := rules.scopes[ruleName(rule)]; scoped {
+			return nil
+		}
+		descriptions = append(descriptions, sweeping.sweeping())
+	}
+	return descriptions
+}
+
+// ruleName is the name of the rule's type, for example "duration".
+func ruleName(rule Rule) string {
+	return reflect.Indirect(reflect.ValueOf(rule)).Type().Name()
+}
+
+// RuleVerdict is the result of evaluating a single rule against a pod.
+type RuleVerdict struct {
+	Rule   string `json:"rule"`
+	Reap   bool   `json:"reap"`
+	Reason string `json:"reason,omitempty"`
+	// OutOfScope is set when the rule's scope does not include the pod, so the rule does not affect the verdict
+	OutOfScope bool `json:"outOfScope,omitempty"`
+}
+
+// Explain evaluates every rule against the pod, unlike ShouldReap which stops at the first rule that does not match,
+// so that it can be shown which rules are keeping a pod alive.
+func (rules Rules) Explain(pod v1.Pod) []RuleVerdict {
+	verdicts := []RuleVerdict{}
+	for _, rule := range rules.LoadedRules {
+		if !rules.inScope(rule, pod) {
+			verdicts = append(verdicts, RuleVerdict{Rule: ruleName(rule), Reason: outOfScopeReason, OutOfScope: true})
+			continue
+		}
+		reap, reason := rules.evaluate(rule, pod)
+		verdicts = append(verdicts, RuleVerdict{
+			Rule:   ruleName(rule),
+			Reap:   reap,
+			Reason: reason,
+		})
+	}
+	return verdicts
+}
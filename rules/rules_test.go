@@ -1,6 +1,7 @@
 package rules
 
 import (
+	"context"
 	"os"
 	"testing"
 	"time"
@@ -53,6 +54,104 @@ func TestRules(t *testing.T) {
 	})
 }
 
+func TestLoadRulesFromMap(t *testing.T) {
+	t.Run("loads rules from the map", func(t *testing.T) {
+		os.Clearenv()
+		rules, err := LoadRulesFromMap(map[string]string{envMaxDuration: "1m", envContainerStatus: "test-status"})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(rules.LoadedRules))
+	})
+	t.Run("ignores environment", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envMaxDuration, "1m")
+		_, err := LoadRulesFromMap(map[string]string{})
+		assert.Error(t, err)
+	})
+}
+
+func TestLoadRulesWithOverrides(t *testing.T) {
+	t.Run("no overrides uses environment", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envMaxDuration, "2m")
+		rules, err := LoadRulesWithOverrides(nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(rules.LoadedRules))
+	})
+	t.Run("overrides add rules", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envMaxDuration, "2m")
+		rules, err := LoadRulesWithOverrides(map[string]string{envContainerStatus: "test-status"})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(rules.LoadedRules))
+	})
+	t.Run("overrides replace environment", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envMaxDuration, "1m")
+		rules, err := LoadRulesWithOverrides(map[string]string{envMaxDuration: "3m"})
+		assert.NoError(t, err)
+		shouldReap, _ := rules.ShouldReap(testPod()) // pod has been running for 2 minutes
+		assert.False(t, shouldReap)
+	})
+	t.Run("invalid override", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envMaxDuration, "1m")
+		_, err := LoadRulesWithOverrides(map[string]string{envChaosChance: "not-a-number"})
+		assert.Error(t, err)
+	})
+	t.Run("no rules", func(t *testing.T) {
+		os.Clearenv()
+		_, err := LoadRulesWithOverrides(map[string]string{"unrelated": "value"})
+		assert.Error(t, err)
+	})
+}
+
+type registeredRule struct{}
+
+func (rule *registeredRule) Load(lookup LookupFunc) (bool, string, error) {
+	_, active := lookup("REGISTERED_RULE")
+	return active, "registered rule", nil
+}
+
+func (rule *registeredRule) ShouldReap(pod v1.Pod) (bool, string) {
+	return pod.Name == "reap-me", "registered rule matched"
+}
+
+func TestRegister(t *testing.T) {
+	original := registry
+	defer func() { registry = original }()
+	Register(func() Rule { return &registeredRule{} })
+
+	t.Run("not configured", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envMaxDuration, "1m")
+		rules, err := LoadRules()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(rules.LoadedRules))
+	})
+	t.Run("configured", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envMaxDuration, "1m")
+		os.Setenv("REGISTERED_RULE", "true")
+		rules, err := LoadRules()
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(rules.LoadedRules))
+		pod := testPod()
+		pod.Name = "reap-me"
+		shouldReap, reasons := rules.ShouldReap(pod)
+		assert.True(t, shouldReap)
+		assert.Contains(t, reasons, "registered rule matched")
+		pod.Name = "keep-me"
+		shouldReap, _ = rules.ShouldReap(pod)
+		assert.False(t, shouldReap)
+	})
+	t.Run("only registered rule", func(t *testing.T) {
+		os.Clearenv()
+		rules, err := LoadRulesWithOverrides(map[string]string{"REGISTERED_RULE": "true"})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(rules.LoadedRules))
+	})
+}
+
 func TestShouldReap(t *testing.T) {
 	t.Run("reap", func(t *testing.T) {
 		os.Clearenv()
@@ -148,3 +247,74 @@ func TestShouldReap(t *testing.T) {
 		assert.Contains(t, reasons[0], "init container")
 	})
 }
+
+func TestExplain(t *testing.T) {
+	os.Clearenv()
+	os.Setenv(envChaosChance, "0.0")       // never
+	os.Setenv(envContainerStatus, "Error") // does not match
+	os.Setenv(envMaxDuration, "1m")        // matches
+	loaded, err := LoadRules()
+	assert.NoError(t, err)
+	verdicts := loaded.Explain(testPod())
+	if assert.Equal(t, 3, len(verdicts)) {
+		assert.Equal(t, "chaos", verdicts[0].Rule)
+		assert.False(t, verdicts[0].Reap)
+		assert.Equal(t, "containerStatus", verdicts[1].Rule)
+		assert.False(t, verdicts[1].Reap)
+		assert.Equal(t, "duration", verdicts[2].Rule)
+		assert.True(t, verdicts[2].Reap)
+		assert.Regexp(t, ".*has been running.*", verdicts[2].Reason)
+	}
+}
+
+// contextualRule records the context it is evaluated with.
+type contextualRule struct {
+	ctx context.Context
+}
+
+func (rule *contextualRule) Load(lookup LookupFunc) (bool, string, error) {
+	return true, "contextual rule", nil
+}
+
+func (rule *contextualRule) ShouldReap(pod v1.Pod) (bool, string) {
+	return rule.shouldReapContext(context.Background(), pod)
+}
+
+func (rule *contextualRule) shouldReapContext(ctx context.Context, pod v1.Pod) (bool, string) {
+	rule.ctx = ctx
+	return true, "contextual rule matched"
+}
+
+func TestWithContext(t *testing.T) {
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "cycle")
+	rule := &contextualRule{}
+	loaded := Rules{LoadedRules: []Rule{rule}}
+
+	loaded.ShouldReap(testPod())
+	assert.Equal(t, context.Background(), rule.ctx)
+	loaded.WithContext(ctx).ShouldReap(testPod())
+	assert.Equal(t, ctx, rule.ctx)
+	loaded.WithContext(ctx).Explain(testPod())
+	assert.Equal(t, ctx, rule.ctx)
+	assert.Nil(t, loaded.ctx)
+}
+
+func TestNames(t *testing.T) {
+	os.Clearenv()
+	os.Setenv(envChaosChance, "1.0")
+	os.Setenv(envMaxDuration, "1m")
+	loaded, err := LoadRules()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"chaos", "duration"}, loaded.Names())
+	assert.Equal(t, []string{}, Rules{}.Names())
+}
+
+func TestOptions(t *testing.T) {
+	names := map[string]bool{}
+	for _, option := range Options() {
+		assert.NotEmpty(t, option.Usage, option.Name)
+		assert.False(t, names[option.Name], "%s is listed twice", option.Name)
+		names[option.Name] = true
+	}
+}
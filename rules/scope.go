@@ -0,0 +1,116 @@
+package rules
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const envRuleSelectors = "RULE_SELECTORS"
+const envRuleNamespaces = "RULE_NAMESPACES"
+
+// outOfScopeReason explains the verdict of a rule that does not apply to a pod
+const outOfScopeReason = "pod is outside the rule's scope"
+
+// ruleScope restricts a rule to the pods that match a label selector and are in one of a set of namespaces. A nil
+// selector or namespaces does not restrict the rule.
+type ruleScope struct {
+	selector   labels.Selector
+	namespaces map[string]bool
+}
+
+func (scope ruleScope) matches(pod v1.Pod) bool {
+	if scope.selector != nil && !scope.selector.Matches(labels.Set(pod.Labels)) {
+		return false
+	}
+	return scope.namespaces == nil || scope.namespaces[pod.Namespace]
+}
+
+func (scope ruleScope) String() string {
+	var restrictions []string
+	if scope.selector != nil {
+		restrictions = append(restrictions, "pods matching "+scope.selector.String())
+	}
+	if scope.namespaces != nil {
+		var namespaces []string
+		for namespace := range scope.namespaces {
+			namespaces = append(namespaces, namespace)
+		}
+		sort.Strings(namespaces)
+		restrictions = append(restrictions, "namespaces "+strings.Join(namespaces, ","))
+	}
+	return strings.Join(restrictions, " in ")
+}
+
+// loadScopes loads the scopes of the loaded rules from RULE_SELECTORS and RULE_NAMESPACES. Both list entries of the
+// form rule:value separated by semicolons, where rule is the name of a loaded rule as shown in logs (for example
+// chaos or containerStatus) and value is a label selector or a comma separated list of namespaces.
+func loadScopes(lookup LookupFunc, loadedRules []Rule) (map[string]ruleScope, error) {
+	loaded := map[string]bool{}
+	for _, rule := range loadedRules {
+		loaded[ruleName(rule)] = true
+	}
+	scopes := map[string]ruleScope{}
+	selectors, err := scopeEntries(lookup, envRuleSelectors, loaded)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range selectors {
+		selector, err := labels.Parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s for rule %s: %s", envRuleSelectors, name, err)
+		}
+		scope := scopes[name]
+		scope.selector = selector
+		scopes[name] = scope
+	}
+	namespaces, err := scopeEntries(lookup, envRuleNamespaces, loaded)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range namespaces {
+		scope := scopes[name]
+		scope.namespaces = map[string]bool{}
+		for _, namespace := range strings.Split(value, ",") {
+			if namespace = strings.TrimSpace(namespace); namespace != "" {
+				scope.namespaces[namespace] = true
+			}
+		}
+		if len(scope.namespaces) == 0 {
+			return nil, fmt.Errorf("invalid %s for rule %s: at least one namespace is required", envRuleNamespaces, name)
+		}
+		scopes[name] = scope
+	}
+	return scopes, nil
+}
+
+// scopeEntries splits the rule:value entries of the key by rule name. Naming a rule that is not loaded is an error,
+// since a misspelled name would leave the intended rule unrestricted.
+func scopeEntries(lookup LookupFunc, key string, loaded map[string]bool) (map[string]string, error) {
+	value, exists := lookup(key)
+	if !exists {
+		return nil, nil
+	}
+	entries := map[string]string{}
+	for _, entry := range strings.Split(value, ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid %s: %q is not of the form rule:value", key, entry)
+		}
+		name := strings.TrimSpace(parts[0])
+		if !loaded[name] {
+			return nil, fmt.Errorf("invalid %s: %s is not a loaded rule", key, name)
+		}
+		if _, duplicate := entries[name]; duplicate {
+			return nil, fmt.Errorf("invalid %s: rule %s is listed more than once", key, name)
+		}
+		entries[name] = strings.TrimSpace(parts[1])
+	}
+	return entries, nil
+}
@@ -0,0 +1,92 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+)
+
+func testScopedPod(namespace string, labels map[string]string) v1.Pod {
+	pod := testPod()
+	pod.Namespace = namespace
+	pod.Labels = labels
+	return pod
+}
+
+func TestLoadScopes(t *testing.T) {
+	config := map[string]string{envChaosChance: "1.0", envMaxDuration: "1m"}
+	load := func(scopes map[string]string) (Rules, error) {
+		merged := map[string]string{}
+		for key, value := range config {
+			merged[key] = value
+		}
+		for key, value := range scopes {
+			merged[key] = value
+		}
+		return LoadRulesFromMap(merged)
+	}
+	t.Run("unscoped", func(t *testing.T) {
+		loaded, err := load(nil)
+		assert.NoError(t, err)
+		assert.Empty(t, loaded.scopes)
+	})
+	t.Run("selectors and namespaces", func(t *testing.T) {
+		loaded, err := load(map[string]string{
+			envRuleSelectors:  "chaos: chaos=enabled, tier notin (critical); duration:app",
+			envRuleNamespaces: "chaos:staging, dev;",
+		})
+		assert.NoError(t, err)
+		if assert.Len(t, loaded.scopes, 2) {
+			assert.Equal(t, "pods matching chaos=enabled,tier notin (critical) in namespaces dev,staging", loaded.scopes["chaos"].String())
+			assert.Equal(t, "pods matching app", loaded.scopes["duration"].String())
+		}
+	})
+	for name, scopes := range map[string]map[string]string{
+		"rule not loaded":     {envRuleSelectors: "unready:app=web"},
+		"missing separator":   {envRuleSelectors: "chaos=enabled"},
+		"invalid selector":    {envRuleSelectors: "chaos:chaos in ("},
+		"duplicate rule":      {envRuleSelectors: "chaos:a=b;chaos:c=d"},
+		"no namespaces":       {envRuleNamespaces: "chaos: , "},
+		"namespace rule typo": {envRuleNamespaces: "choas:dev"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := load(scopes)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestScopedShouldReap(t *testing.T) {
+	loaded, err := LoadRulesFromMap(map[string]string{
+		envChaosChance:    "1.0",
+		envMaxDuration:    "1m",
+		envRuleSelectors:  "chaos:chaos=enabled",
+		envRuleNamespaces: "duration:batch",
+	})
+	assert.NoError(t, err)
+	t.Run("every rule in scope", func(t *testing.T) {
+		shouldReap, reasons := loaded.ShouldReap(testScopedPod("batch", map[string]string{"chaos": "enabled"}))
+		assert.True(t, shouldReap)
+		assert.Len(t, reasons, 2)
+	})
+	t.Run("out of scope rules are ignored", func(t *testing.T) {
+		shouldReap, reasons := loaded.ShouldReap(testScopedPod("batch", nil))
+		assert.True(t, shouldReap)
+		if assert.Len(t, reasons, 1) {
+			assert.Contains(t, reasons[0], "has been running")
+		}
+	})
+	t.Run("outside every scope", func(t *testing.T) {
+		shouldReap, reasons := loaded.ShouldReap(testScopedPod("web", nil))
+		assert.False(t, shouldReap)
+		assert.Empty(t, reasons)
+	})
+	t.Run("explain", func(t *testing.T) {
+		verdicts := loaded.Explain(testScopedPod("web", map[string]string{"chaos": "enabled"}))
+		if assert.Len(t, verdicts, 2) {
+			assert.True(t, verdicts[0].Reap)
+			assert.Equal(t, RuleVerdict{Rule: "duration", Reason: outOfScopeReason, OutOfScope: true}, verdicts[1])
+		}
+	})
+}
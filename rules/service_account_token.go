@@ -0,0 +1,72 @@
+package rules
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"k8s.io/api/core/v1"
+)
+
+const envMaxServiceAccountTokenAge = "MAX_SERVICE_ACCOUNT_TOKEN_AGE"
+const envTokenRefreshAnnotation = "TOKEN_REFRESH_ANNOTATION"
+
+const defaultTokenRefreshAnnotation = "pod-reaper/token-refresh"
+
+var _ Rule = (*serviceAccountToken)(nil)
+
+// serviceAccountToken flags pods that mount a projected service account token and have been running longer than the
+// token rotation boundary. Pods annotated as refreshing their token in place are never flagged.
+type serviceAccountToken struct {
+	duration   time.Duration
+	annotation string
+}
+
+func (rule *serviceAccountToken) Load(lookup LookupFunc) (bool, string, error) {
+	value, active := lookup(envMaxServiceAccountTokenAge)
+	if !active {
+		return false, "", nil
+	}
+	duration, err := parseDuration(value)
+	if err != nil {
+		return false, "", fmt.Errorf("invalid max service account token age: %s", err)
+	}
+	rule.duration = duration
+	rule.annotation = defaultTokenRefreshAnnotation
+	if annotation, exists := lookup(envTokenRefreshAnnotation); exists && annotation != "" {
+		rule.annotation = annotation
+	}
+	return true, fmt.Sprintf("maximum service account token age %s", value), nil
+}
+
+func (rule *serviceAccountToken) ShouldReap(pod v1.Pod) (bool, string) {
+	if !hasProjectedServiceAccountToken(pod) {
+		return false, ""
+	}
+	// without the annotation, assume the pod does not refresh its token and rely on age alone
+	if refresh, err := strconv.ParseBool(pod.Annotations[rule.annotation]); err == nil && refresh {
+		return false, ""
+	}
+	podStartTime := pod.Status.StartTime
+	if podStartTime == nil {
+		return false, ""
+	}
+	startTime := time.Unix(podStartTime.Unix(), 0) // convert to standard go time
+	runningDuration := time.Now().Sub(startTime)
+	message := fmt.Sprintf("has had the same service account token for %s", runningDuration.String())
+	return runningDuration > rule.duration, message
+}
+
+func hasProjectedServiceAccountToken(pod v1.Pod) bool {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.Projected == nil {
+			continue
+		}
+		for _, source := range volume.Projected.Sources {
+			if source.ServiceAccountToken != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
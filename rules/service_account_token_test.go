@@ -0,0 +1,103 @@
+package rules
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testTokenPod(running time.Duration, annotations map[string]string) v1.Pod {
+	startTime := metav1.NewTime(time.Now().Add(-running))
+	return v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Annotations: annotations},
+		Spec: v1.PodSpec{
+			Volumes: []v1.Volume{
+				{
+					Name: "kube-api-access-abcde",
+					VolumeSource: v1.VolumeSource{
+						Projected: &v1.ProjectedVolumeSource{
+							Sources: []v1.VolumeProjection{
+								{ServiceAccountToken: &v1.ServiceAccountTokenProjection{Path: "token"}},
+							},
+						},
+					},
+				},
+			},
+		},
+		Status: v1.PodStatus{StartTime: &startTime},
+	}
+}
+
+func TestServiceAccountTokenLoad(t *testing.T) {
+	t.Run("load", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envMaxServiceAccountTokenAge, "24h")
+		rule := serviceAccountToken{}
+		loaded, message, err := rule.Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.True(t, loaded)
+		assert.Equal(t, "maximum service account token age 24h", message)
+		assert.Equal(t, defaultTokenRefreshAnnotation, rule.annotation)
+	})
+	t.Run("custom annotation", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envMaxServiceAccountTokenAge, "24h")
+		os.Setenv(envTokenRefreshAnnotation, "example.com/refreshes-token")
+		rule := serviceAccountToken{}
+		_, _, err := rule.Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.Equal(t, "example.com/refreshes-token", rule.annotation)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envMaxServiceAccountTokenAge, "a year")
+		_, _, err := (&serviceAccountToken{}).Load(os.LookupEnv)
+		assert.Error(t, err)
+	})
+	t.Run("no load", func(t *testing.T) {
+		os.Clearenv()
+		loaded, message, err := (&serviceAccountToken{}).Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.Equal(t, "", message)
+		assert.False(t, loaded)
+	})
+}
+
+func TestServiceAccountTokenShouldReap(t *testing.T) {
+	rule := serviceAccountToken{duration: 24 * time.Hour, annotation: defaultTokenRefreshAnnotation}
+	t.Run("stale token", func(t *testing.T) {
+		shouldReap, reason := rule.ShouldReap(testTokenPod(48*time.Hour, nil))
+		assert.True(t, shouldReap)
+		assert.Regexp(t, "^has had the same service account token for 48h", reason)
+	})
+	t.Run("known not to refresh", func(t *testing.T) {
+		pod := testTokenPod(48*time.Hour, map[string]string{defaultTokenRefreshAnnotation: "false"})
+		shouldReap, _ := rule.ShouldReap(pod)
+		assert.True(t, shouldReap)
+	})
+	t.Run("refreshes in place", func(t *testing.T) {
+		pod := testTokenPod(48*time.Hour, map[string]string{defaultTokenRefreshAnnotation: "true"})
+		shouldReap, _ := rule.ShouldReap(pod)
+		assert.False(t, shouldReap)
+	})
+	t.Run("fresh token", func(t *testing.T) {
+		shouldReap, _ := rule.ShouldReap(testTokenPod(time.Hour, nil))
+		assert.False(t, shouldReap)
+	})
+	t.Run("no projected token", func(t *testing.T) {
+		pod := testTokenPod(48*time.Hour, nil)
+		pod.Spec.Volumes = nil
+		shouldReap, _ := rule.ShouldReap(pod)
+		assert.False(t, shouldReap)
+	})
+	t.Run("not started", func(t *testing.T) {
+		pod := testTokenPod(48*time.Hour, nil)
+		pod.Status.StartTime = nil
+		shouldReap, _ := rule.ShouldReap(pod)
+		assert.False(t, shouldReap)
+	})
+}
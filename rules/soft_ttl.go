@@ -0,0 +1,79 @@
+package rules
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+const envSoftTTL = "SOFT_TTL"
+const envSoftTTLMax = "SOFT_TTL_MAX"
+
+// softTTLCurve controls how steeply the reap chance grows between the soft ttl and the max. Larger values keep the
+// chance low for longer before it climbs towards 1.
+const softTTLCurve = 3.0
+
+var _ Rule = (*softTTL)(nil)
+
+type softTTL struct {
+	start   time.Duration
+	end     time.Duration
+	missing missingStartTime
+}
+
+func (rule *softTTL) Load(lookup LookupFunc) (bool, string, error) {
+	startValue, startActive := lookup(envSoftTTL)
+	endValue, endActive := lookup(envSoftTTLMax)
+	if !startActive && !endActive {
+		return false, "", nil
+	} else if !endActive {
+		return false, "", fmt.Errorf("specified %s but not %s", envSoftTTL, envSoftTTLMax)
+	} else if !startActive {
+		return false, "", fmt.Errorf("specified %s but not %s", envSoftTTLMax, envSoftTTL)
+	}
+	start, err := parseDuration(startValue)
+	if err != nil {
+		return false, "", fmt.Errorf("invalid soft ttl: %s", err)
+	}
+	end, err := parseDuration(endValue)
+	if err != nil {
+		return false, "", fmt.Errorf("invalid soft ttl max: %s", err)
+	}
+	if end <= start {
+		return false, "", fmt.Errorf("%s must be greater than %s", envSoftTTLMax, envSoftTTL)
+	}
+	rule.start = start
+	rule.end = end
+	rule.missing, err = loadMissingStartTime(lookup)
+	if err != nil {
+		return false, "", err
+	}
+	return true, fmt.Sprintf("soft ttl from %s to %s", startValue, endValue), nil
+}
+
+// reapChance returns the probability of reaping a pod that has been running for the duration. The chance is 0 up to
+// the soft ttl, grows exponentially until the max, and is 1 afterwards.
+func (rule *softTTL) reapChance(running time.Duration) float64 {
+	if running <= rule.start {
+		return 0
+	}
+	if running >= rule.end {
+		return 1
+	}
+	progress := float64(running-rule.start) / float64(rule.end-rule.start)
+	return math.Expm1(softTTLCurve*progress) / math.Expm1(softTTLCurve)
+}
+
+func (rule *softTTL) ShouldReap(pod v1.Pod) (bool, string) {
+	startTime, ok := rule.missing.startTime(pod)
+	if !ok {
+		return rule.missing.shouldReap(pod)
+	}
+	runningDuration := time.Now().Sub(startTime)
+	chance := rule.reapChance(runningDuration)
+	message := fmt.Sprintf("%s (soft ttl reap chance %.1f%%)", runningMessage(pod, runningDuration), chance*100)
+	return rand.Float64() < chance, message
+}
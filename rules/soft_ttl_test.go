@@ -0,0 +1,107 @@
+package rules
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSoftTTLLoad(t *testing.T) {
+	t.Run("load", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envSoftTTL, "24h")
+		os.Setenv(envSoftTTLMax, "72h")
+		rule := softTTL{}
+		loaded, message, err := rule.Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.Equal(t, "soft ttl from 24h to 72h", message)
+		assert.True(t, loaded)
+		assert.Equal(t, 24*time.Hour, rule.start)
+		assert.Equal(t, 72*time.Hour, rule.end)
+	})
+	t.Run("no load", func(t *testing.T) {
+		os.Clearenv()
+		loaded, message, err := (&softTTL{}).Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.Equal(t, "", message)
+		assert.False(t, loaded)
+	})
+	t.Run("only soft ttl", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envSoftTTL, "24h")
+		loaded, _, err := (&softTTL{}).Load(os.LookupEnv)
+		assert.Error(t, err)
+		assert.False(t, loaded)
+	})
+	t.Run("only soft ttl max", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envSoftTTLMax, "72h")
+		loaded, _, err := (&softTTL{}).Load(os.LookupEnv)
+		assert.Error(t, err)
+		assert.False(t, loaded)
+	})
+	t.Run("invalid soft ttl", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envSoftTTL, "not-a-duration")
+		os.Setenv(envSoftTTLMax, "72h")
+		_, _, err := (&softTTL{}).Load(os.LookupEnv)
+		assert.Error(t, err)
+	})
+	t.Run("invalid soft ttl max", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envSoftTTL, "24h")
+		os.Setenv(envSoftTTLMax, "not-a-duration")
+		_, _, err := (&softTTL{}).Load(os.LookupEnv)
+		assert.Error(t, err)
+	})
+	t.Run("max not greater than soft ttl", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envSoftTTL, "24h")
+		os.Setenv(envSoftTTLMax, "24h")
+		_, _, err := (&softTTL{}).Load(os.LookupEnv)
+		assert.Error(t, err)
+	})
+}
+
+func TestSoftTTLReapChance(t *testing.T) {
+	rule := softTTL{start: 24 * time.Hour, end: 72 * time.Hour}
+	assert.Equal(t, 0.0, rule.reapChance(time.Hour))
+	assert.Equal(t, 0.0, rule.reapChance(24*time.Hour))
+	assert.Equal(t, 1.0, rule.reapChance(72*time.Hour))
+	assert.Equal(t, 1.0, rule.reapChance(100*time.Hour))
+	quarter := rule.reapChance(36 * time.Hour)
+	half := rule.reapChance(48 * time.Hour)
+	threeQuarters := rule.reapChance(60 * time.Hour)
+	assert.True(t, 0 < quarter && quarter < half && half < threeQuarters && threeQuarters < 1)
+	assert.True(t, half < 0.5, "chance should grow exponentially rather than linearly")
+}
+
+func TestSoftTTLShouldReap(t *testing.T) {
+	rule := softTTL{start: time.Hour, end: 2 * time.Hour}
+	t.Run("no start time", func(t *testing.T) {
+		shouldReap, _ := rule.ShouldReap(testDurationPod(nil))
+		assert.False(t, shouldReap)
+	})
+	t.Run("before soft ttl", func(t *testing.T) {
+		startTime := time.Now().Add(-30 * time.Minute)
+		shouldReap, _ := rule.ShouldReap(testDurationPod(&startTime))
+		assert.False(t, shouldReap)
+	})
+	t.Run("after max", func(t *testing.T) {
+		startTime := time.Now().Add(-3 * time.Hour)
+		shouldReap, reason := rule.ShouldReap(testDurationPod(&startTime))
+		assert.True(t, shouldReap)
+		assert.Regexp(t, "has been running for .* \\(soft ttl reap chance 100.0%\\)", reason)
+	})
+	t.Run("no start time measured from creation", func(t *testing.T) {
+		rule := softTTL{start: time.Hour, end: 2 * time.Hour, missing: missingStartTime{useCreation: true}}
+		pod := testDurationPod(nil)
+		pod.CreationTimestamp = metav1.NewTime(time.Now().Add(-3 * time.Hour))
+		shouldReap, reason := rule.ShouldReap(pod)
+		assert.True(t, shouldReap)
+		assert.Regexp(t, "has not started in .* \\(soft ttl reap chance 100.0%\\)", reason)
+	})
+}
@@ -0,0 +1,158 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+)
+
+const envMaxOutOfRotation = "MAX_OUT_OF_ROTATION"
+
+// services and endpoint slices are cached per namespace so each namespace is listed at most once per ttl
+const serviceEndpointsTTL = time.Minute
+
+var _ Rule = (*staleEndpoint)(nil)
+var _ contextRule = (*staleEndpoint)(nil)
+
+// staleEndpoint flags running pods that back a service but have been out of rotation in every one of the service's
+// endpoint slices for a duration: alive, but not serving traffic.
+type staleEndpoint struct {
+	duration  time.Duration
+	endpoints *serviceEndpoints
+}
+
+func (rule *staleEndpoint) Load(lookup LookupFunc) (bool, string, error) {
+	value, active := lookup(envMaxOutOfRotation)
+	if !active {
+		return false, "", nil
+	}
+	duration, err := parseDuration(value)
+	if err != nil {
+		return false, "", fmt.Errorf("invalid %s: %s", envMaxOutOfRotation, err)
+	}
+	rule.duration = duration
+	return true, fmt.Sprintf("maximum out of service rotation %s", value), nil
+}
+
+func (rule *staleEndpoint) ShouldReap(pod v1.Pod) (bool, string) {
+	return rule.shouldReapContext(context.Background(), pod)
+}
+
+func (rule *staleEndpoint) shouldReapContext(ctx context.Context, pod v1.Pod) (bool, string) {
+	if pod.Status.Phase != v1.PodRunning {
+		return false, ""
+	}
+	// the ready condition tells how long the pod has been out of rotation, since endpoints do not record it
+	condition := getCondition(pod, v1.PodReady)
+	if condition == nil || condition.Status == v1.ConditionTrue || condition.LastTransitionTime.IsZero() {
+		return false, ""
+	}
+	outOfRotation := time.Since(condition.LastTransitionTime.Time)
+	if outOfRotation < rule.duration {
+		return false, ""
+	}
+	cached, err := listServiceEndpoints(ctx, rule.endpoints, pod.Namespace)
+	if err != nil {
+		logrus.WithField("namespace", pod.Namespace).WithError(err).Warn("unable to list service endpoints")
+		return false, ""
+	}
+	var backed []string
+	for _, service := range cached.services {
+		if len(service.Spec.Selector) == 0 || !labels.SelectorFromSet(service.Spec.Selector).Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		if cached.serving(service.Name, pod.Name) {
+			return false, ""
+		}
+		backed = append(backed, service.Name)
+	}
+	if len(backed) == 0 {
+		return false, ""
+	}
+	return true, fmt.Sprintf("has been out of rotation for services %v for %s", backed, outOfRotation.Truncate(time.Second))
+}
+
+// listServiceEndpoints lists the namespace's services and endpoint slices from endpoints, or from the service endpoint
+// cache of the cluster carried by ctx when endpoints is nil.
+func listServiceEndpoints(ctx context.Context, endpoints *serviceEndpoints, namespace string) (cachedServiceEndpoints, error) {
+	if endpoints == nil {
+		cluster, err := clusterFromContext(ctx)
+		if err != nil {
+			return cachedServiceEndpoints{}, err
+		}
+		endpoints = cluster.endpoints
+	}
+	return endpoints.list(ctx, namespace)
+}
+
+// serviceEndpoints lists services and their endpoint slices, caching them per namespace.
+type serviceEndpoints struct {
+	client kubernetes.Interface
+	mutex  sync.Mutex
+	cache  map[string]cachedServiceEndpoints
+}
+
+type cachedServiceEndpoints struct {
+	listed   time.Time
+	services []v1.Service
+	slices   []discoveryv1.EndpointSlice
+}
+
+func newServiceEndpoints(client kubernetes.Interface) *serviceEndpoints {
+	return &serviceEndpoints{
+		client: client,
+		cache:  map[string]cachedServiceEndpoints{},
+	}
+}
+
+func (endpoints *serviceEndpoints) list(ctx context.Context, namespace string) (cachedServiceEndpoints, error) {
+	endpoints.mutex.Lock()
+	defer endpoints.mutex.Unlock()
+	if cached, ok := endpoints.cache[namespace]; ok && time.Since(cached.listed) < serviceEndpointsTTL {
+		return cached, nil
+	}
+	ctx, cancel := apiContext(ctx)
+	defer cancel()
+	services, err := endpoints.client.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return cachedServiceEndpoints{}, err
+	}
+	slices, err := endpoints.client.DiscoveryV1().EndpointSlices(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return cachedServiceEndpoints{}, err
+	}
+	cached := cachedServiceEndpoints{listed: time.Now(), services: services.Items, slices: slices.Items}
+	endpoints.cache[namespace] = cached
+	return cached, nil
+}
+
+// serving returns whether the pod is a serving endpoint in any of the service's endpoint slices.
+func (cached cachedServiceEndpoints) serving(service string, pod string) bool {
+	for _, slice := range cached.slices {
+		if slice.Labels[discoveryv1.LabelServiceName] != service {
+			continue
+		}
+		for _, endpoint := range slice.Endpoints {
+			if endpoint.TargetRef == nil || endpoint.TargetRef.Kind != "Pod" || endpoint.TargetRef.Name != pod {
+				continue
+			}
+			// serving is only reported by newer clusters, where it is ready ignoring termination
+			serving := endpoint.Conditions.Serving
+			if serving == nil {
+				serving = endpoint.Conditions.Ready
+			}
+			if serving == nil || *serving {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package rules
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func testService(name string, selector map[string]string) *v1.Service {
+	return &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       v1.ServiceSpec{Selector: selector},
+	}
+}
+
+func testEndpointSlice(service string, pod string, ready bool, serving *bool) *discoveryv1.EndpointSlice {
+	return &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      service + "-" + pod,
+			Namespace: "default",
+			Labels:    map[string]string{discoveryv1.LabelServiceName: service},
+		},
+		Endpoints: []discoveryv1.Endpoint{{
+			TargetRef:  &v1.ObjectReference{Kind: "Pod", Name: pod},
+			Conditions: discoveryv1.EndpointConditions{Ready: &ready, Serving: serving},
+		}},
+	}
+}
+
+func testServiceEndpoints(objects ...runtime.Object) *serviceEndpoints {
+	return newServiceEndpoints(fake.NewSimpleClientset(objects...))
+}
+
+func testOutOfRotationPod(name string, unreadyFor time.Duration) v1.Pod {
+	return v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"app": "web"}},
+		Status: v1.PodStatus{
+			Phase: v1.PodRunning,
+			Conditions: []v1.PodCondition{{
+				Type:               v1.PodReady,
+				Status:             v1.ConditionFalse,
+				LastTransitionTime: metav1.NewTime(time.Now().Add(-unreadyFor)),
+			}},
+		},
+	}
+}
+
+func TestStaleEndpointLoad(t *testing.T) {
+	t.Run("load", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envMaxOutOfRotation, "10m")
+		rule := staleEndpoint{}
+		loaded, message, err := rule.Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.True(t, loaded)
+		assert.Equal(t, "maximum out of service rotation 10m", message)
+		assert.Equal(t, 10*time.Minute, rule.duration)
+	})
+	t.Run("invalid duration", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envMaxOutOfRotation, "a while")
+		_, _, err := (&staleEndpoint{}).Load(os.LookupEnv)
+		assert.Error(t, err)
+	})
+	t.Run("no load", func(t *testing.T) {
+		os.Clearenv()
+		loaded, message, err := (&staleEndpoint{}).Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.Equal(t, "", message)
+		assert.False(t, loaded)
+	})
+}
+
+func TestStaleEndpointShouldReap(t *testing.T) {
+	serving := true
+	notServing := false
+	endpoints := testServiceEndpoints(
+		testService("web", map[string]string{"app": "web"}),
+		testService("headless", nil),
+		testEndpointSlice("web", "removed", false, &notServing),
+		testEndpointSlice("web", "terminating", false, &serving),
+	)
+	rule := staleEndpoint{duration: 10 * time.Minute, endpoints: endpoints}
+
+	t.Run("out of rotation", func(t *testing.T) {
+		shouldReap, reason := rule.ShouldReap(testOutOfRotationPod("removed", time.Hour))
+		assert.True(t, shouldReap)
+		assert.Contains(t, reason, "has been out of rotation for services [web] for 1h")
+	})
+	t.Run("absent from endpoints", func(t *testing.T) {
+		shouldReap, _ := rule.ShouldReap(testOutOfRotationPod("absent", time.Hour))
+		assert.True(t, shouldReap)
+	})
+	t.Run("recently out of rotation", func(t *testing.T) {
+		shouldReap, _ := rule.ShouldReap(testOutOfRotationPod("removed", time.Minute))
+		assert.False(t, shouldReap)
+	})
+	t.Run("still serving", func(t *testing.T) {
+		shouldReap, _ := rule.ShouldReap(testOutOfRotationPod("terminating", time.Hour))
+		assert.False(t, shouldReap)
+	})
+	t.Run("ready", func(t *testing.T) {
+		pod := testOutOfRotationPod("removed", time.Hour)
+		pod.Status.Conditions[0].Status = v1.ConditionTrue
+		shouldReap, _ := rule.ShouldReap(pod)
+		assert.False(t, shouldReap)
+	})
+	t.Run("not running", func(t *testing.T) {
+		pod := testOutOfRotationPod("removed", time.Hour)
+		pod.Status.Phase = v1.PodPending
+		shouldReap, _ := rule.ShouldReap(pod)
+		assert.False(t, shouldReap)
+	})
+	t.Run("not backing a service", func(t *testing.T) {
+		pod := testOutOfRotationPod("removed", time.Hour)
+		pod.Labels = map[string]string{"app": "worker"}
+		shouldReap, _ := rule.ShouldReap(pod)
+		assert.False(t, shouldReap)
+	})
+}
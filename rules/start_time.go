@@ -0,0 +1,71 @@
+package rules
+
+import (
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+const envMissingStartTime = "MISSING_START_TIME"
+
+// treatments of pods without a start time
+const missingStartTimeIgnore = "ignore"
+const missingStartTimeCreation = "creation"
+
+// missingStartTime is how the run duration rules treat pods without a start time, which never started running: for
+// example pods that cannot be scheduled, or whose sandbox cannot be created. By default they are never flagged.
+type missingStartTime struct {
+	// useCreation measures the run duration of the pod from when it was created
+	useCreation bool
+	// eligibleAfter flags the pod once it has existed for this long without starting, zero to never flag it
+	eligibleAfter time.Duration
+}
+
+func loadMissingStartTime(lookup LookupFunc) (missingStartTime, error) {
+	value, exists := lookup(envMissingStartTime)
+	if !exists || value == missingStartTimeIgnore {
+		return missingStartTime{}, nil
+	}
+	if value == missingStartTimeCreation {
+		return missingStartTime{useCreation: true}, nil
+	}
+	eligibleAfter, err := parseDuration(value)
+	if err != nil {
+		return missingStartTime{}, fmt.Errorf("invalid %s: must be %q, %q, or a duration", envMissingStartTime, missingStartTimeIgnore, missingStartTimeCreation)
+	}
+	if eligibleAfter <= 0 {
+		return missingStartTime{}, fmt.Errorf("invalid %s: must be a positive duration", envMissingStartTime)
+	}
+	return missingStartTime{eligibleAfter: eligibleAfter}, nil
+}
+
+// startTime returns when the pod started running, or when it was created if the pod never started and its creation
+// is used instead. It returns false when the run duration of the pod is unknown.
+func (missing missingStartTime) startTime(pod v1.Pod) (time.Time, bool) {
+	if pod.Status.StartTime != nil {
+		return time.Unix(pod.Status.StartTime.Unix(), 0), true // convert to standard go time
+	}
+	if missing.useCreation && !pod.CreationTimestamp.IsZero() {
+		return time.Unix(pod.CreationTimestamp.Unix(), 0), true
+	}
+	return time.Time{}, false
+}
+
+// shouldReap flags a pod whose run duration is unknown once it has existed for eligibleAfter without starting.
+func (missing missingStartTime) shouldReap(pod v1.Pod) (bool, string) {
+	if missing.eligibleAfter == 0 || pod.CreationTimestamp.IsZero() {
+		return false, ""
+	}
+	existing := time.Since(pod.CreationTimestamp.Time)
+	return existing > missing.eligibleAfter, runningMessage(pod, existing)
+}
+
+// runningMessage describes how long the pod has been running, or for a pod without a start time how long it has
+// existed without starting.
+func runningMessage(pod v1.Pod, running time.Duration) string {
+	if pod.Status.StartTime == nil {
+		return fmt.Sprintf("has not started in %s", running.Truncate(time.Second))
+	}
+	return fmt.Sprintf("has been running for %s", running.String())
+}
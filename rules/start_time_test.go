@@ -0,0 +1,83 @@
+package rules
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestLoadMissingStartTime(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		os.Clearenv()
+		missing, err := loadMissingStartTime(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.Equal(t, missingStartTime{}, missing)
+	})
+	t.Run("ignore", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envMissingStartTime, "ignore")
+		missing, err := loadMissingStartTime(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.Equal(t, missingStartTime{}, missing)
+	})
+	t.Run("creation", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envMissingStartTime, "creation")
+		missing, err := loadMissingStartTime(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.Equal(t, missingStartTime{useCreation: true}, missing)
+	})
+	t.Run("eligible after", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envMissingStartTime, "1d")
+		missing, err := loadMissingStartTime(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.Equal(t, missingStartTime{eligibleAfter: 24 * time.Hour}, missing)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		os.Clearenv()
+		for _, value := range []string{"created", "0s", "-1h"} {
+			os.Setenv(envMissingStartTime, value)
+			_, err := loadMissingStartTime(os.LookupEnv)
+			assert.Error(t, err, value)
+		}
+	})
+}
+
+func TestMissingStartTime(t *testing.T) {
+	created := time.Now().Add(-2 * time.Hour)
+	notStarted := testDurationPod(nil)
+	notStarted.CreationTimestamp = metav1.NewTime(created)
+
+	t.Run("ignored", func(t *testing.T) {
+		_, ok := missingStartTime{}.startTime(notStarted)
+		assert.False(t, ok)
+		shouldReap, _ := missingStartTime{}.shouldReap(notStarted)
+		assert.False(t, shouldReap)
+	})
+	t.Run("started", func(t *testing.T) {
+		startTime := time.Now().Add(-time.Hour)
+		pod := testDurationPod(&startTime)
+		pod.CreationTimestamp = metav1.NewTime(created)
+		start, ok := missingStartTime{useCreation: true}.startTime(pod)
+		assert.True(t, ok)
+		assert.Equal(t, startTime.Unix(), start.Unix())
+	})
+	t.Run("creation", func(t *testing.T) {
+		start, ok := missingStartTime{useCreation: true}.startTime(notStarted)
+		assert.True(t, ok)
+		assert.Equal(t, created.Unix(), start.Unix())
+		_, ok = missingStartTime{useCreation: true}.startTime(testDurationPod(nil))
+		assert.False(t, ok)
+	})
+	t.Run("eligible after", func(t *testing.T) {
+		shouldReap, reason := missingStartTime{eligibleAfter: time.Hour}.shouldReap(notStarted)
+		assert.True(t, shouldReap)
+		assert.Regexp(t, "^has not started in 2h0m[0-9]+s$", reason)
+		shouldReap, _ = missingStartTime{eligibleAfter: 3 * time.Hour}.shouldReap(notStarted)
+		assert.False(t, shouldReap)
+	})
+}
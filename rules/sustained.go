@@ -0,0 +1,65 @@
+package rules
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// observations of pods that were not evaluated for this long are forgotten, since the pods are most likely gone
+const observationTTL = time.Hour
+
+// forgotten observations are pruned at most this often
+const observationPruneInterval = 30 * time.Second
+
+// sustained tracks, across evaluations, since when each pod has continuously met a condition such as its usage being
+// above a threshold. The zero value is ready to use.
+type sustained struct {
+	mutex        sync.Mutex
+	observations map[types.UID]observation
+	pruned       time.Time
+}
+
+// observation tracks a pod that meets the condition.
+type observation struct {
+	// since is when the pod was first seen meeting the condition
+	since time.Time
+	// seen is when the pod was last seen meeting the condition
+	seen time.Time
+}
+
+// observe records whether the pod meets the condition and returns how long it has continuously met it, or false if it
+// does not meet it.
+func (tracker *sustained) observe(uid types.UID, met bool, now time.Time) (time.Duration, bool) {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+	tracker.prune(now)
+	if !met {
+		delete(tracker.observations, uid)
+		return 0, false
+	}
+	observed, exists := tracker.observations[uid]
+	if !exists {
+		observed.since = now
+	}
+	observed.seen = now
+	tracker.observations[uid] = observed
+	return now.Sub(observed.since), true
+}
+
+// prune forgets pods that have not been seen meeting the condition for a while.
+func (tracker *sustained) prune(now time.Time) {
+	if tracker.observations == nil {
+		tracker.observations = map[types.UID]observation{}
+	}
+	if now.Sub(tracker.pruned) < observationPruneInterval {
+		return
+	}
+	tracker.pruned = now
+	for uid, observed := range tracker.observations {
+		if now.Sub(observed.seen) >= observationTTL {
+			delete(tracker.observations, uid)
+		}
+	}
+}
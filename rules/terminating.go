@@ -0,0 +1,43 @@
+package rules
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/api/core/v1"
+)
+
+const envMaxTerminating = "MAX_TERMINATING"
+
+var _ Rule = (*terminating)(nil)
+
+// terminating flags pods that are still terminating a duration after their deletion grace period ended, usually
+// because a finalizer is never cleared or the kubelet of their node is gone.
+type terminating struct {
+	duration time.Duration
+}
+
+func (rule *terminating) Load(lookup LookupFunc) (bool, string, error) {
+	value, active := lookup(envMaxTerminating)
+	if !active {
+		return false, "", nil
+	}
+	duration, err := parseDuration(value)
+	if err != nil {
+		return false, "", fmt.Errorf("invalid %s: %s", envMaxTerminating, err)
+	}
+	rule.duration = duration
+	return true, fmt.Sprintf("maximum terminating duration %s", value), nil
+}
+
+func (rule *terminating) ShouldReap(pod v1.Pod) (bool, string) {
+	// the deletion timestamp is when the grace period ends, so the pod is overdue from then on
+	if pod.DeletionTimestamp == nil {
+		return false, ""
+	}
+	overdue := time.Since(pod.DeletionTimestamp.Time)
+	if overdue < rule.duration {
+		return false, ""
+	}
+	return true, fmt.Sprintf("has been stuck terminating for %s", overdue.Truncate(time.Second))
+}
@@ -0,0 +1,71 @@
+package rules
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testTerminatingPod(overdue *time.Duration) v1.Pod {
+	pod := v1.Pod{}
+	if overdue != nil {
+		deletion := metav1.NewTime(time.Now().Add(-*overdue))
+		pod.DeletionTimestamp = &deletion
+	}
+	return pod
+}
+
+func TestTerminatingLoad(t *testing.T) {
+	t.Run("load", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envMaxTerminating, "10m")
+		rule := terminating{}
+		loaded, message, err := rule.Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.True(t, loaded)
+		assert.Equal(t, "maximum terminating duration 10m", message)
+		assert.Equal(t, 10*time.Minute, rule.duration)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envMaxTerminating, "forever")
+		loaded, _, err := (&terminating{}).Load(os.LookupEnv)
+		assert.Error(t, err)
+		assert.False(t, loaded)
+	})
+	t.Run("no load", func(t *testing.T) {
+		os.Clearenv()
+		loaded, message, err := (&terminating{}).Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.Equal(t, "", message)
+		assert.False(t, loaded)
+	})
+}
+
+func TestTerminatingShouldReap(t *testing.T) {
+	rule := terminating{duration: 10 * time.Minute}
+	t.Run("not terminating", func(t *testing.T) {
+		shouldReap, _ := rule.ShouldReap(testTerminatingPod(nil))
+		assert.False(t, shouldReap)
+	})
+	t.Run("within grace period", func(t *testing.T) {
+		overdue := -time.Minute
+		shouldReap, _ := rule.ShouldReap(testTerminatingPod(&overdue))
+		assert.False(t, shouldReap)
+	})
+	t.Run("recently overdue", func(t *testing.T) {
+		overdue := 5 * time.Minute
+		shouldReap, _ := rule.ShouldReap(testTerminatingPod(&overdue))
+		assert.False(t, shouldReap)
+	})
+	t.Run("stuck", func(t *testing.T) {
+		overdue := time.Hour
+		shouldReap, reason := rule.ShouldReap(testTerminatingPod(&overdue))
+		assert.True(t, shouldReap)
+		assert.Equal(t, "has been stuck terminating for 1h0m0s", reason)
+	})
+}
@@ -2,7 +2,6 @@ package rules
 
 import (
 	"fmt"
-	"os"
 	"time"
 
 	"k8s.io/api/core/v1"
@@ -16,12 +15,12 @@ type unready struct {
 	duration time.Duration
 }
 
-func (rule *unready) load() (bool, string, error) {
-	value, active := os.LookupEnv(envMaxUnready)
+func (rule *unready) Load(lookup LookupFunc) (bool, string, error) {
+	value, active := lookup(envMaxUnready)
 	if !active {
 		return false, "", nil
 	}
-	duration, err := time.ParseDuration(value)
+	duration, err := parseDuration(value)
 	if err != nil {
 		return false, "", fmt.Errorf("invalid max unready duration: %s", err)
 	}
@@ -30,7 +30,7 @@ func TestUnreadyLoad(t *testing.T) {
 	t.Run("load", func(t *testing.T) {
 		os.Clearenv()
 		os.Setenv(envMaxUnready, "30m")
-		loaded, message, err := (&unready{}).load()
+		loaded, message, err := (&unready{}).Load(os.LookupEnv)
 		assert.NoError(t, err)
 		assert.Equal(t, "maximum unready 30m", message)
 		assert.True(t, loaded)
@@ -38,14 +38,14 @@ func TestUnreadyLoad(t *testing.T) {
 	t.Run("invalid time", func(t *testing.T) {
 		os.Clearenv()
 		os.Setenv(envMaxUnready, "not-a-time")
-		loaded, message, err := (&unready{}).load()
+		loaded, message, err := (&unready{}).Load(os.LookupEnv)
 		assert.Error(t, err)
 		assert.Equal(t, "", message)
 		assert.False(t, loaded)
 	})
 	t.Run("no load", func(t *testing.T) {
 		os.Clearenv()
-		loaded, message, err := (&unready{}).load()
+		loaded, message, err := (&unready{}).Load(os.LookupEnv)
 		assert.NoError(t, err)
 		assert.Equal(t, "", message)
 		assert.False(t, loaded)
@@ -57,7 +57,7 @@ func TestUnreadyShouldReap(t *testing.T) {
 		os.Clearenv()
 		os.Setenv(envMaxUnready, "10m")
 		unready := unready{}
-		unready.load()
+		unready.Load(os.LookupEnv)
 		pod := testUnreadyPod(nil)
 		shouldReap, _ := unready.ShouldReap(pod)
 		assert.False(t, shouldReap)
@@ -66,7 +66,7 @@ func TestUnreadyShouldReap(t *testing.T) {
 		os.Clearenv()
 		os.Setenv(envMaxUnready, "9m59s")
 		unready := unready{}
-		unready.load()
+		unready.Load(os.LookupEnv)
 		lastTransitionTime := time.Now().Add(-10 * time.Minute)
 		pod := testUnreadyPod(&lastTransitionTime)
 		shouldReap, reason := unready.ShouldReap(pod)
@@ -77,7 +77,7 @@ func TestUnreadyShouldReap(t *testing.T) {
 		os.Clearenv()
 		os.Setenv(envMaxUnready, "10m1s")
 		unready := unready{}
-		unready.load()
+		unready.Load(os.LookupEnv)
 		lastTransitionTime := time.Now().Add(-10 * time.Minute)
 		pod := testUnreadyPod(&lastTransitionTime)
 		shouldReap, _ := unready.ShouldReap(pod)
@@ -87,7 +87,7 @@ func TestUnreadyShouldReap(t *testing.T) {
 		os.Clearenv()
 		os.Setenv(envMaxUnready, "1m")
 		u := unready{}
-		u.load()
+		u.Load(os.LookupEnv)
 		// Create pod with condition but zero-value LastTransitionTime
 		pod := v1.Pod{
 			Status: v1.PodStatus{
@@ -109,7 +109,7 @@ func TestUnreadyShouldReap(t *testing.T) {
 		os.Clearenv()
 		os.Setenv(envMaxUnready, "1m")
 		u := unready{}
-		u.load()
+		u.Load(os.LookupEnv)
 		lastTransitionTime := time.Now().Add(-10 * time.Minute)
 		setTime := metav1.NewTime(lastTransitionTime)
 		pod := v1.Pod{
@@ -131,7 +131,7 @@ func TestUnreadyShouldReap(t *testing.T) {
 		os.Clearenv()
 		os.Setenv(envMaxUnready, "1m")
 		u := unready{}
-		u.load()
+		u.Load(os.LookupEnv)
 		lastTransitionTime := time.Now().Add(-10 * time.Minute)
 		setTime := metav1.NewTime(lastTransitionTime)
 		pod := v1.Pod{
@@ -153,7 +153,7 @@ func TestUnreadyShouldReap(t *testing.T) {
 		os.Clearenv()
 		os.Setenv(envMaxUnready, "1m")
 		u := unready{}
-		u.load()
+		u.Load(os.LookupEnv)
 		lastTransitionTime := time.Now().Add(-10 * time.Minute)
 		setTime := metav1.NewTime(lastTransitionTime)
 		pod := v1.Pod{
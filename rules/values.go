@@ -0,0 +1,50 @@
+package rules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const envCaseInsensitive = "CASE_INSENSITIVE"
+
+// splitValues splits a comma separated rule value, trimming whitespace around each value and dropping empty values.
+func splitValues(value string) []string {
+	var values []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+	return values
+}
+
+// loadValues loads the comma separated values of key, which must contain at least one value.
+func loadValues(key string, value string) ([]string, error) {
+	values := splitValues(value)
+	if len(values) == 0 {
+		return nil, fmt.Errorf("invalid %s: at least one value is required", key)
+	}
+	return values, nil
+}
+
+// caseInsensitive returns whether CASE_INSENSITIVE is set, to compare status values without regard to case.
+func caseInsensitive(lookup LookupFunc) (bool, error) {
+	value, exists := lookup(envCaseInsensitive)
+	if !exists {
+		return false, nil
+	}
+	insensitive, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %s", envCaseInsensitive, err)
+	}
+	return insensitive, nil
+}
+
+// equalValues compares a value from the pod with a configured value.
+func equalValues(actual string, configured string, insensitive bool) bool {
+	if insensitive {
+		return strings.EqualFold(actual, configured)
+	}
+	return actual == configured
+}
@@ -0,0 +1,171 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+const envMaxVulnerabilitySeverity = "MAX_VULNERABILITY_SEVERITY"
+const envVulnerabilityGracePeriod = "VULNERABILITY_GRACE_PERIOD"
+
+// vulnerability reports are cached per namespace so each namespace is listed at most once per ttl
+const vulnerabilityReportTTL = time.Minute
+
+// trivy operator labels linking a vulnerability report to the scanned workload and container
+const labelTrivyResourceKind = "trivy-operator.resource.kind"
+const labelTrivyResourceName = "trivy-operator.resource.name"
+const labelTrivyContainerName = "trivy-operator.container.name"
+
+var vulnerabilityReportResource = schema.GroupVersionResource{
+	Group:    "aquasecurity.github.io",
+	Version:  "v1alpha1",
+	Resource: "vulnerabilityreports",
+}
+
+// severities from least to most severe, with the vulnerability report summary field counting each
+var severities = []struct {
+	name  string
+	field string
+}{
+	{"LOW", "lowCount"},
+	{"MEDIUM", "mediumCount"},
+	{"HIGH", "highCount"},
+	{"CRITICAL", "criticalCount"},
+}
+
+var _ Rule = (*vulnerability)(nil)
+var _ contextRule = (*vulnerability)(nil)
+
+type vulnerability struct {
+	// severity is the index in severities of the least severe finding that flags a pod
+	severity    int
+	gracePeriod time.Duration
+	reports     *vulnerabilityReports
+}
+
+func (rule *vulnerability) Load(lookup LookupFunc) (bool, string, error) {
+	value, active := lookup(envMaxVulnerabilitySeverity)
+	if !active {
+		return false, "", nil
+	}
+	rule.severity = -1
+	var names []string
+	for i, severity := range severities {
+		names = append(names, severity.name)
+		if strings.EqualFold(value, severity.name) {
+			rule.severity = i
+		}
+	}
+	if rule.severity < 0 {
+		return false, "", fmt.Errorf("invalid %s %q, must be one of: %s", envMaxVulnerabilitySeverity, value, strings.Join(names, ", "))
+	}
+	message := fmt.Sprintf("vulnerabilities of severity %s or higher", severities[rule.severity].name)
+	if grace, exists := lookup(envVulnerabilityGracePeriod); exists {
+		duration, err := parseDuration(grace)
+		if err != nil {
+			return false, "", fmt.Errorf("invalid %s: %s", envVulnerabilityGracePeriod, err)
+		}
+		rule.gracePeriod = duration
+		message += fmt.Sprintf(" reported for %s", grace)
+	}
+	return true, message, nil
+}
+
+func (rule *vulnerability) ShouldReap(pod v1.Pod) (bool, string) {
+	return rule.shouldReapContext(context.Background(), pod)
+}
+
+func (rule *vulnerability) shouldReapContext(ctx context.Context, pod v1.Pod) (bool, string) {
+	kind, name := "Pod", pod.Name
+	if owner := metav1.GetControllerOf(&pod); owner != nil {
+		kind, name = owner.Kind, owner.Name
+	}
+	reports, err := listVulnerabilityReports(ctx, rule.reports, pod.Namespace)
+	if err != nil {
+		logrus.WithField("namespace", pod.Namespace).WithError(err).Warn("unable to list vulnerability reports")
+		return false, ""
+	}
+	for _, report := range reports {
+		labels := report.GetLabels()
+		if labels[labelTrivyResourceKind] != kind || labels[labelTrivyResourceName] != name {
+			continue
+		}
+		reportedFor := time.Since(report.GetCreationTimestamp().Time)
+		if reportedFor < rule.gracePeriod {
+			continue
+		}
+		if findings := rule.findings(report); findings > 0 {
+			return true, fmt.Sprintf("has container %s with %d vulnerabilities of severity %s or higher reported %s ago",
+				labels[labelTrivyContainerName], findings, severities[rule.severity].name, reportedFor.Truncate(time.Second))
+		}
+	}
+	return false, ""
+}
+
+// findings returns the number of findings in the report at or above the rule's severity.
+func (rule *vulnerability) findings(report unstructured.Unstructured) int64 {
+	var findings int64
+	for _, severity := range severities[rule.severity:] {
+		count, _, _ := unstructured.NestedInt64(report.Object, "report", "summary", severity.field)
+		findings += count
+	}
+	return findings
+}
+
+// listVulnerabilityReports lists the namespace's vulnerability reports from reports, or from the vulnerability report
+// cache of the cluster carried by ctx when reports is nil.
+func listVulnerabilityReports(ctx context.Context, reports *vulnerabilityReports, namespace string) ([]unstructured.Unstructured, error) {
+	if reports == nil {
+		cluster, err := clusterFromContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		reports = cluster.reports
+	}
+	return reports.list(ctx, namespace)
+}
+
+// vulnerabilityReports lists trivy operator vulnerability reports, caching them per namespace.
+type vulnerabilityReports struct {
+	client dynamic.Interface
+	mutex  sync.Mutex
+	cache  map[string]cachedVulnerabilityReports
+}
+
+type cachedVulnerabilityReports struct {
+	listed  time.Time
+	reports []unstructured.Unstructured
+}
+
+func newVulnerabilityReports(client dynamic.Interface) *vulnerabilityReports {
+	return &vulnerabilityReports{
+		client: client,
+		cache:  map[string]cachedVulnerabilityReports{},
+	}
+}
+
+func (reports *vulnerabilityReports) list(ctx context.Context, namespace string) ([]unstructured.Unstructured, error) {
+	reports.mutex.Lock()
+	defer reports.mutex.Unlock()
+	if cached, ok := reports.cache[namespace]; ok && time.Since(cached.listed) < vulnerabilityReportTTL {
+		return cached.reports, nil
+	}
+	ctx, cancel := apiContext(ctx)
+	defer cancel()
+	list, err := reports.client.Resource(vulnerabilityReportResource).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	reports.cache[namespace] = cachedVulnerabilityReports{listed: time.Now(), reports: list.Items}
+	return list.Items, nil
+}
@@ -0,0 +1,123 @@
+package rules
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func testVulnerabilityReport(name string, kind string, owner string, created time.Time, critical int64, high int64) runtime.Object {
+	report := &unstructured.Unstructured{}
+	report.SetAPIVersion("aquasecurity.github.io/v1alpha1")
+	report.SetKind("VulnerabilityReport")
+	report.SetNamespace("default")
+	report.SetName(name)
+	report.SetCreationTimestamp(metav1.NewTime(created))
+	report.SetLabels(map[string]string{
+		labelTrivyResourceKind:  kind,
+		labelTrivyResourceName:  owner,
+		labelTrivyContainerName: "app",
+	})
+	unstructured.SetNestedField(report.Object, critical, "report", "summary", "criticalCount")
+	unstructured.SetNestedField(report.Object, high, "report", "summary", "highCount")
+	return report
+}
+
+func testVulnerabilityReports(objects ...runtime.Object) *vulnerabilityReports {
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{vulnerabilityReportResource: "VulnerabilityReportList"}, objects...)
+	return newVulnerabilityReports(client)
+}
+
+func TestVulnerabilityLoad(t *testing.T) {
+	t.Run("load", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envMaxVulnerabilitySeverity, "high")
+		os.Setenv(envVulnerabilityGracePeriod, "24h")
+		rule := vulnerability{}
+		loaded, message, err := rule.Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.True(t, loaded)
+		assert.Equal(t, "vulnerabilities of severity HIGH or higher reported for 24h", message)
+		assert.Equal(t, 2, rule.severity)
+		assert.Equal(t, 24*time.Hour, rule.gracePeriod)
+	})
+	t.Run("invalid severity", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envMaxVulnerabilitySeverity, "scary")
+		_, _, err := (&vulnerability{}).Load(os.LookupEnv)
+		assert.Error(t, err)
+	})
+	t.Run("invalid grace period", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv(envMaxVulnerabilitySeverity, "CRITICAL")
+		os.Setenv(envVulnerabilityGracePeriod, "a while")
+		_, _, err := (&vulnerability{}).Load(os.LookupEnv)
+		assert.Error(t, err)
+	})
+	t.Run("no load", func(t *testing.T) {
+		os.Clearenv()
+		loaded, message, err := (&vulnerability{}).Load(os.LookupEnv)
+		assert.NoError(t, err)
+		assert.Equal(t, "", message)
+		assert.False(t, loaded)
+	})
+}
+
+func TestVulnerabilityShouldReap(t *testing.T) {
+	now := time.Now()
+	reports := testVulnerabilityReports(
+		testVulnerabilityReport("replicaset-web-app", "ReplicaSet", "web-1234", now.Add(-48*time.Hour), 0, 3),
+		testVulnerabilityReport("pod-fresh-app", "Pod", "fresh", now.Add(-time.Hour), 1, 0),
+		testVulnerabilityReport("pod-clean-app", "Pod", "clean", now.Add(-48*time.Hour), 0, 0),
+	)
+	controller := true
+	ownedPod := v1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:      "web-1234-abcde",
+		Namespace: "default",
+		OwnerReferences: []metav1.OwnerReference{
+			{Kind: "ReplicaSet", Name: "web-1234", Controller: &controller},
+		},
+	}}
+	barePod := func(name string) v1.Pod {
+		return v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+	}
+
+	t.Run("owner report above severity", func(t *testing.T) {
+		rule := vulnerability{severity: 2, gracePeriod: 24 * time.Hour, reports: reports}
+		shouldReap, reason := rule.ShouldReap(ownedPod)
+		assert.True(t, shouldReap)
+		assert.Contains(t, reason, "has container app with 3 vulnerabilities of severity HIGH or higher reported 48h")
+	})
+	t.Run("below severity", func(t *testing.T) {
+		rule := vulnerability{severity: 3, reports: reports}
+		shouldReap, _ := rule.ShouldReap(ownedPod)
+		assert.False(t, shouldReap)
+	})
+	t.Run("within grace period", func(t *testing.T) {
+		rule := vulnerability{severity: 3, gracePeriod: 24 * time.Hour, reports: reports}
+		shouldReap, _ := rule.ShouldReap(barePod("fresh"))
+		assert.False(t, shouldReap)
+		rule.gracePeriod = 0
+		shouldReap, _ = rule.ShouldReap(barePod("fresh"))
+		assert.True(t, shouldReap)
+	})
+	t.Run("no findings", func(t *testing.T) {
+		rule := vulnerability{severity: 0, reports: reports}
+		shouldReap, _ := rule.ShouldReap(barePod("clean"))
+		assert.False(t, shouldReap)
+	})
+	t.Run("no report", func(t *testing.T) {
+		rule := vulnerability{severity: 0, reports: reports}
+		shouldReap, _ := rule.ShouldReap(barePod("unscanned"))
+		assert.False(t, shouldReap)
+	})
+}